@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/git/gitattr"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+// autoInstallSkipCommands are the commands before which Git LFS is never set
+// up automatically: those which set it up or remove it themselves, and those
+// which Git only runs once it has been set up.
+var autoInstallSkipCommands = map[string]bool{
+	"install":         true,
+	"uninstall":       true,
+	"update":          true,
+	"clean":           true,
+	"smudge":          true,
+	"filter-process":  true,
+	"pre-push":        true,
+	"pre-commit":      true,
+	"post-checkout":   true,
+	"post-commit":     true,
+	"post-merge":      true,
+	"standalone-file": true,
+	"serve":           true,
+}
+
+// autoInstallPromptedName is the file, relative to the LFS storage directory,
+// which records that the user has been asked whether to set up Git LFS for
+// the repository, or warned that it is not, so that it only happens once.
+const autoInstallPromptedName = "autoinstall-prompted"
+
+// autoInstall sets up Git LFS for the current repository, as `git lfs install
+// --local` does, if no "lfs" filter is configured for it although it tracks
+// files with Git LFS, as in a repository cloned before Git LFS was installed.
+// Depending on lfs.autoinstall, it does so without asking ("true"), never
+// ("false"), or asks once ("prompt", the default). If it cannot ask, because
+// it is not run from a terminal, it warns once instead.
+func autoInstall(cmd *cobra.Command) {
+	for c := cmd; c != nil; c = c.Parent() {
+		if autoInstallSkipCommands[c.Name()] {
+			return
+		}
+	}
+	if !cfg.InRepo() || len(cfg.LocalWorkingDir()) == 0 {
+		return
+	}
+	for _, key := range []string{"filter.lfs.process", "filter.lfs.smudge", "filter.lfs.clean"} {
+		if value, _ := cfg.Git.Get(key); len(value) > 0 {
+			return
+		}
+	}
+
+	mode, _ := cfg.Git.Get("lfs.autoinstall")
+	mode = strings.ToLower(mode)
+	if mode == "false" || len(repoTrackedPatterns()) == 0 {
+		return
+	}
+
+	if mode == "true" {
+		autoInstallSetup()
+		return
+	}
+
+	stamp := filepath.Join(cfg.LFSStorageDir(), autoInstallPromptedName)
+	if _, err := os.Stat(stamp); err == nil {
+		return
+	}
+	if err := tools.MkdirAll(cfg.LFSStorageDir(), cfg); err == nil {
+		ioutil.WriteFile(stamp, []byte(time.Now().Format(time.RFC3339)+"\n"), cfg.RepositoryPermissions(false))
+	}
+
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stderr) {
+		ErrorMsg("autoinstall.not-set-up")
+		ErrorMsg("autoinstall.how-to-set-up")
+		return
+	}
+
+	fmt.Fprint(os.Stderr, tr.Tr("autoinstall.prompt"))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "", "y", "yes":
+		autoInstallSetup()
+	default:
+		ErrorMsg("autoinstall.set-up-later")
+	}
+}
+
+// autoInstallSetup sets up the "lfs" filter in the local Git config, and the
+// hooks of the current repository.
+func autoInstallSetup() {
+	opts := &lfs.FilterOptions{GitConfig: cfg.GitConfig(), Local: true, BinaryPath: installBinaryPath()}
+	if err := opts.Install(); err != nil {
+		ErrorMsg("autoinstall.setup-failed", tr.Args{"Err": err})
+		return
+	}
+	if err := installHooks(false); err != nil {
+		ErrorMsg("autoinstall.hooks-failed", tr.Args{"Err": err})
+	}
+	ErrorMsg("autoinstall.set-up")
+}
+
+// repoTrackedPatterns returns the patterns in the .gitattributes files of the
+// current repository, including its .git/info/attributes file, which are
+// tracked by Git LFS. System and global attributes are only read for the
+// macros they define.
+func repoTrackedPatterns() []string {
+	mp := gitattr.NewMacroProcessor()
+	git.GetSystemAttributePaths(mp, cfg.Os)
+	git.GetRootAttributePaths(mp, cfg.Git)
+
+	var patterns []string
+	for _, p := range git.GetAttributePaths(mp, cfg.LocalWorkingDir(), cfg.LocalGitDir()) {
+		if p.Tracked {
+			patterns = append(patterns, p.Path)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/subprocess"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditLogSince   string
+	auditLogUntil   string
+	auditLogUser    string
+	auditLogType    string
+	auditLogFormat  string
+	auditLogSummary bool
+)
+
+// operationLogName is the name of the log of Git LFS operations, relative to
+// the LFS storage directory. Each line is one JSON-encoded operationLogEntry.
+const operationLogName = "operation.log"
+
+// operationLogEntry is one operation recorded in the operation log.
+type operationLogEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Type    string    `json:"type"`
+	Ref     string    `json:"ref,omitempty"`
+	Objects int       `json:"objects"`
+	Bytes   int64     `json:"bytes"`
+}
+
+// operationLogSummary is the number of operations of one type, and the
+// objects and bytes they transferred, as reported by `git lfs audit-log
+// --summary`.
+type operationLogSummary struct {
+	Type       string `json:"type"`
+	Operations int    `json:"operations"`
+	Objects    int    `json:"objects"`
+	Bytes      int64  `json:"bytes"`
+}
+
+// auditLogCommand prints the entries of the operation log which match the
+// given filters, or a summary of them by operation type. It only ever reads
+// the log.
+func auditLogCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	switch auditLogFormat {
+	case "table", "json", "csv":
+	default:
+		ExitMsg("audit-log.invalid-format", tr.Args{"Format": auditLogFormat})
+	}
+
+	now := time.Now()
+	var since, until time.Time
+	var err error
+	if len(auditLogSince) > 0 {
+		if since, err = parseSince(auditLogSince, now); err != nil {
+			ExitMsg("audit-log.invalid-since", tr.Args{"Err": err})
+		}
+	}
+	if len(auditLogUntil) > 0 {
+		if until, err = parseSince(auditLogUntil, now); err != nil {
+			ExitMsg("audit-log.invalid-until", tr.Args{"Err": err})
+		}
+	}
+
+	entries, err := readOperationLog(filepath.Join(cfg.LFSStorageDir(), operationLogName), func(e *operationLogEntry) bool {
+		return (since.IsZero() || !e.Time.Before(since)) &&
+			(until.IsZero() || e.Time.Before(until)) &&
+			(len(auditLogUser) == 0 || e.User == auditLogUser) &&
+			(len(auditLogType) == 0 || e.Type == auditLogType)
+	})
+	if err != nil {
+		ExitMsg("audit-log.read-failed", tr.Args{"Err": err})
+	}
+
+	w, wait := startPager()
+	if auditLogSummary {
+		err = writeOperationLogSummary(w, summarizeOperationLog(entries))
+	} else {
+		err = writeOperationLog(w, entries)
+	}
+	wait()
+	if err != nil {
+		ExitWithError(err)
+	}
+}
+
+// readOperationLog returns the entries of the operation log at "path" for
+// which "include" returns true, oldest first. A missing log has no entries,
+// and malformed lines are reported and skipped.
+func readOperationLog(path string, include func(*operationLogEntry) bool) ([]*operationLogEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []*operationLogEntry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		e := &operationLogEntry{}
+		if err := json.Unmarshal([]byte(line), e); err != nil || len(e.Type) == 0 {
+			ErrorMsg("audit-log.malformed-line", tr.Args{"Line": n, "Path": path})
+			continue
+		}
+		if include(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries, nil
+}
+
+func summarizeOperationLog(entries []*operationLogEntry) []*operationLogSummary {
+	byType := make(map[string]*operationLogSummary)
+	for _, e := range entries {
+		s := byType[e.Type]
+		if s == nil {
+			s = &operationLogSummary{Type: e.Type}
+			byType[e.Type] = s
+		}
+		s.Operations++
+		s.Objects += e.Objects
+		s.Bytes += e.Bytes
+	}
+
+	summary := make([]*operationLogSummary, 0, len(byType))
+	for _, s := range byType {
+		summary = append(summary, s)
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].Type < summary[j].Type
+	})
+	return summary
+}
+
+func writeOperationLog(w io.Writer, entries []*operationLogEntry) error {
+	switch auditLogFormat {
+	case "json":
+		if entries == nil {
+			entries = []*operationLogEntry{}
+		}
+		return writeAuditLogJSON(w, entries)
+	case "csv":
+		rows := [][]string{{"time", "user", "type", "ref", "objects", "bytes"}}
+		for _, e := range entries {
+			rows = append(rows, []string{e.Time.Format(time.RFC3339), e.User, e.Type, e.Ref,
+				strconv.Itoa(e.Objects), strconv.FormatInt(e.Bytes, 10)})
+		}
+		return writeAuditLogCSV(w, rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, tr.Tr("audit-log.table-header"))
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"),
+			e.User, e.Type, e.Objects, humanize.FormatBytes(uint64(e.Bytes)), e.Ref)
+	}
+	return tw.Flush()
+}
+
+func writeOperationLogSummary(w io.Writer, summary []*operationLogSummary) error {
+	switch auditLogFormat {
+	case "json":
+		return writeAuditLogJSON(w, summary)
+	case "csv":
+		rows := [][]string{{"type", "operations", "objects", "bytes"}}
+		for _, s := range summary {
+			rows = append(rows, []string{s.Type, strconv.Itoa(s.Operations),
+				strconv.Itoa(s.Objects), strconv.FormatInt(s.Bytes, 10)})
+		}
+		return writeAuditLogCSV(w, rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, tr.Tr("audit-log.summary-header"))
+	for _, s := range summary {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", s.Type, s.Operations, s.Objects,
+			humanize.FormatBytes(uint64(s.Bytes)))
+	}
+	return tw.Flush()
+}
+
+func writeAuditLogJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func writeAuditLogCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return errors.Wrap(err, "could not write CSV")
+	}
+	return nil
+}
+
+// startPager returns a writer which pipes into the pager named by GIT_PAGER,
+// core.pager or PAGER, in that order, if standard output is a terminal, and a
+// function which waits for the pager to exit once everything is written.
+// Otherwise, or if the pager cannot be started, it returns standard output.
+func startPager() (io.Writer, func()) {
+	if !isTerminal(os.Stdout) {
+		return os.Stdout, func() {}
+	}
+
+	pager, ok := os.LookupEnv("GIT_PAGER")
+	if !ok {
+		pager, ok = cfg.Git.Get("core.pager")
+	}
+	if !ok {
+		pager = os.Getenv("PAGER")
+	}
+	if len(pager) == 0 || pager == "cat" {
+		return os.Stdout, func() {}
+	}
+
+	cmd := subprocess.ExecCommand("sh", "-c", pager)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		ErrorMsg("audit-log.pager-failed", tr.Args{"Pager": pager, "Err": err})
+		return os.Stdout, func() {}
+	}
+	return stdin, func() {
+		stdin.Close()
+		cmd.Wait()
+	}
+}
+
+func init() {
+	RegisterCommand("audit-log", auditLogCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVar(&auditLogSince, "since", "", "Only show operations at or after this date or age")
+		cmd.Flags().StringVar(&auditLogUntil, "until", "", "Only show operations before this date or age")
+		cmd.Flags().StringVar(&auditLogUser, "user", "", "Only show operations by this user")
+		cmd.Flags().StringVar(&auditLogType, "type", "", "Only show operations of this type")
+		cmd.Flags().StringVar(&auditLogFormat, "format", "table", "Print as a table, json or csv")
+		cmd.Flags().BoolVar(&auditLogSummary, "summary", false, "Show the count and total size of operations by type")
+	})
+}
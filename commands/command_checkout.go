@@ -2,78 +2,123 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
-	"github.com/git-lfs/git-lfs/tasklog"
+	"github.com/git-lfs/git-lfs/tools/humanize"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+	isatty "github.com/mattn/go-isatty"
+	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
 
 var (
-	checkoutTo     string
-	checkoutBase   bool
-	checkoutOurs   bool
-	checkoutTheirs bool
+	checkoutTo       string
+	checkoutBase     bool
+	checkoutOurs     bool
+	checkoutTheirs   bool
+	checkoutProgress bool
+	checkoutIndex    bool
+
+	// checkoutSourceArg is the remote from whose Git LFS endpoint objects
+	// which are not in the local object store are downloaded before they
+	// are checked out, given by --source.
+	checkoutSourceArg string
 )
 
 func checkoutCommand(cmd *cobra.Command, args []string) {
 	requireInRepo()
+	requireTempDir()
 
 	stage, err := whichCheckout()
 	if err != nil {
-		Exit("Error parsing args: %v", err)
+		ExitMsg("checkout.parse-args", tr.Args{"Err": err})
 	}
 
 	if checkoutTo != "" && stage != git.IndexStageDefault {
 		checkoutConflict(rootedPaths(args)[0], stage)
 		return
 	} else if checkoutTo != "" || stage != git.IndexStageDefault {
-		Exit("--to and exactly one of --theirs, --ours, and --base must be used together")
+		ExitMsg("checkout.to-needs-stage")
 	}
 
-	ref, err := git.CurrentRef()
-	if err != nil {
-		Panic(err, "Could not checkout")
+	var ref *git.Ref
+	if !checkoutIndex {
+		ref, err = git.CurrentRef()
+		if err != nil {
+			PanicMsg(err, "checkout.failed")
+		}
 	}
 
 	singleCheckout := newSingleCheckout(cfg.Git, "")
 	if singleCheckout.Skip() {
-		fmt.Println("Cannot checkout LFS objects, Git LFS is not installed.")
+		PrintMsg("checkout.not-installed")
 		return
 	}
 
 	var totalBytes int64
 	var pointers []*lfs.WrappedPointer
-	logger := tasklog.NewLogger(os.Stdout,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
 	meter := tq.NewMeter(cfg)
 	meter.Direction = tq.Checkout
 	meter.Logger = meter.LoggerFromEnv(cfg.Os)
+	meter.Events = progressEvents()
+
+	// If GIT_LFS_PROGRESS is set, the meter logs the progress of each file
+	// there instead. Otherwise, the meter is shown on standard error when it
+	// is a terminal, and with --progress, the progress of each file is
+	// printed on its own line in place of the meter's summary.
+	var progress *checkoutProgressLogger
+	var sink io.Writer = os.Stdout
+	if meter.Logger == nil && meter.Events == nil {
+		if isTerminal(os.Stderr) {
+			sink = os.Stderr
+		} else if checkoutProgress {
+			progress = newCheckoutProgressLogger(os.Stderr)
+			sink = nil
+		}
+	}
+	logger := newLogger(sink)
 	logger.Enqueue(meter)
 	chgitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			LoggedError(err, "Scanner error: %s", err)
+			LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
 			return
 		}
 
-		totalBytes += p.Size
-		meter.Add(p.Size)
-		meter.StartTransfer(p.Name)
 		pointers = append(pointers, p)
 	})
 
 	chgitscanner.Filter = filepathfilter.New(rootedPaths(args), nil)
 
-	if err := chgitscanner.ScanTree(ref.Sha); err != nil {
+	// With --index, check out the versions staged in the index, such as
+	// after a `git reset --soft`, rather than those committed in HEAD.
+	if checkoutIndex {
+		err = chgitscanner.ScanIndexTree()
+	} else {
+		err = chgitscanner.ScanTree(ref.Sha)
+	}
+	if err != nil {
 		ExitWithError(err)
 	}
 	chgitscanner.Close()
 
+	fetched := true
+	if len(checkoutSourceArg) > 0 {
+		fetched = checkoutFetchFromSource(cmd, pointers)
+	}
+
+	for _, p := range pointers {
+		totalBytes += p.Size
+		meter.Add(p.Size)
+		meter.StartTransfer(p.Oid, p.Name, p.Size)
+	}
+
 	meter.Start()
+	progress.Start(len(pointers), totalBytes)
 	for _, p := range pointers {
 		singleCheckout.Run(p)
 
@@ -81,42 +126,152 @@ func checkoutCommand(cmd *cobra.Command, args []string) {
 		// plus only 1 slot in channel so it'll block & be close
 		meter.TransferBytes("checkout", p.Name, p.Size, totalBytes, int(p.Size))
 		meter.FinishTransfer(p.Name)
+		progress.Checkout(p.Size)
 	}
 
 	meter.Finish()
 	singleCheckout.Close()
+
+	if !fetched {
+		e := getAPIClient().Endpoints.Endpoint("download", checkoutSourceArg)
+		ExitMsg("common.failed-to-fetch", tr.Args{"URL": e.Url})
+	}
+}
+
+// checkoutFetchFromSource downloads the objects of "pointers" which are not in
+// the local object store, and whose paths are allowed by --include and
+// --exclude, from the Git LFS endpoint of the remote given by --source, even
+// if lfs.url is set. It returns whether every object was downloaded.
+func checkoutFetchFromSource(cmd *cobra.Command, pointers []*lfs.WrappedPointer) bool {
+	remote := checkoutSourceArg
+	if err := cfg.SetValidRemote(remote); err != nil {
+		ExitMsg("common.invalid-remote", tr.Args{"Remote": remote, "Err": err})
+	}
+	getAPIClient().Endpoints.SetPreferredRemote(remote)
+
+	include, exclude := getIncludeExcludeArgs(cmd)
+	filter := buildFilepathFilter(cfg, include, exclude, false)
+
+	seen := make(map[string]bool, len(pointers))
+	missing := make([]*lfs.WrappedPointer, 0, len(pointers))
+	for _, p := range pointers {
+		if seen[p.Oid] || !filter.Allows(p.Name) {
+			continue
+		}
+		seen[p.Oid] = true
+
+		lfs.LinkOrCopyFromReference(cfg, p.Oid, p.Size)
+		if !cfg.LFSObjectExists(p.Oid, p.Size) {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) == 0 {
+		return true
+	}
+
+	if err := checkFreeSpace(missing, false); err != nil {
+		Exit("%s", err)
+	}
+
+	logger := newLogger(os.Stdout)
+	meter := buildProgressMeter(false, tq.Download)
+	logger.Enqueue(meter)
+
+	q := newDownloadQueue(
+		getTransferManifestOperationRemote("download", remote),
+		remote, tq.WithProgress(meter),
+	)
+	for _, p := range missing {
+		tracerx.Printf("checkout: fetch %v [%v] from %v", p.Name, p.Oid, remote)
+		meter.Add(p.Size)
+		q.Add(downloadTransfer(p))
+	}
+	q.Wait()
+	meter.Finish()
+	logger.Close()
+
+	// Files whose objects could not be downloaded are left as pointers,
+	// as for any other object which is not in the local object store, so
+	// the rest are still checked out before exiting.
+	for _, err := range q.Errors() {
+		FullError(err)
+	}
+	return len(q.Errors()) == 0
+}
+
+// checkoutProgressLogger reports the number of files and bytes written to the
+// working tree by `git lfs checkout`, writing each update on its own line.
+type checkoutProgressLogger struct {
+	sink io.Writer
+
+	files, totalFiles int
+	bytes, totalBytes int64
+}
+
+func newCheckoutProgressLogger(sink io.Writer) *checkoutProgressLogger {
+	return &checkoutProgressLogger{sink: sink}
+}
+
+// Start begins reporting the checkout of "files" files, totalling "bytes"
+// bytes.
+func (l *checkoutProgressLogger) Start(files int, bytes int64) {
+	if l == nil {
+		return
+	}
+	l.totalFiles, l.totalBytes = files, bytes
+}
+
+// Checkout records that a file of "size" bytes was written to the working tree.
+func (l *checkoutProgressLogger) Checkout(size int64) {
+	if l == nil {
+		return
+	}
+	l.files++
+	l.bytes += size
+
+	fmt.Fprintln(l.sink, tr.Tr("checkout.progress", tr.Args{
+		"Done":       l.files,
+		"Total":      l.totalFiles,
+		"DoneBytes":  humanize.FormatBytes(uint64(l.bytes)),
+		"TotalBytes": humanize.FormatBytes(uint64(l.totalBytes)),
+	}))
+}
+
+// isTerminal returns whether "f" is connected to a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
 
 func checkoutConflict(file string, stage git.IndexStage) {
 	singleCheckout := newSingleCheckout(cfg.Git, "")
 	if singleCheckout.Skip() {
-		fmt.Println("Cannot checkout LFS objects, Git LFS is not installed.")
+		PrintMsg("checkout.not-installed")
 		return
 	}
 
 	ref, err := git.ResolveRef(fmt.Sprintf(":%d:%s", stage, file))
 	if err != nil {
-		Exit("Could not checkout (are you not in the middle of a merge?): %v", err)
+		ExitMsg("checkout.not-merging", tr.Args{"Err": err})
 	}
 
 	scanner, err := git.NewObjectScanner(cfg.OSEnv())
 	if err != nil {
-		Exit("Could not create object scanner: %v", err)
+		ExitMsg("checkout.object-scanner", tr.Args{"Err": err})
 	}
 
 	if !scanner.Scan(ref.Sha) {
-		Exit("Could not find object %q", ref.Sha)
+		ExitMsg("checkout.object-not-found", tr.Args{"Sha": ref.Sha})
 	}
 
 	ptr, err := lfs.DecodePointer(scanner.Contents())
 	if err != nil {
-		Exit("Could not find decoder pointer for object %q: %v", ref.Sha, err)
+		ExitMsg("checkout.decode-pointer", tr.Args{"Sha": ref.Sha, "Err": err})
 	}
 
 	p := &lfs.WrappedPointer{Name: file, Pointer: ptr}
 
 	if err := singleCheckout.RunToPath(p, checkoutTo); err != nil {
-		Exit("Error checking out %v to %q: %v", ref.Sha, checkoutTo, err)
+		ExitMsg("checkout.to-failed", tr.Args{"Sha": ref.Sha, "Path": checkoutTo, "Err": err})
 	}
 	singleCheckout.Close()
 }
@@ -150,7 +305,7 @@ func whichCheckout() (stage git.IndexStage, err error) {
 func rootedPaths(args []string) []string {
 	pathConverter, err := lfs.NewCurrentToRepoPathConverter(cfg)
 	if err != nil {
-		Panic(err, "Could not checkout")
+		PanicMsg(err, "checkout.failed")
 	}
 
 	rootedpaths := make([]string, 0, len(args))
@@ -166,5 +321,10 @@ func init() {
 		cmd.Flags().BoolVar(&checkoutOurs, "ours", false, "Checkout our version of a conflicted file")
 		cmd.Flags().BoolVar(&checkoutTheirs, "theirs", false, "Checkout their version of a conflicted file")
 		cmd.Flags().BoolVar(&checkoutBase, "base", false, "Checkout the base version of a conflicted file")
+		cmd.Flags().BoolVar(&checkoutIndex, "index", false, "Checkout the versions of files staged in the index, rather than in HEAD")
+		cmd.Flags().BoolVar(&checkoutProgress, "progress", false, "Show the progress of each file checked out, even when not on a terminal")
+		cmd.Flags().StringVar(&checkoutSourceArg, "source", "", "Download objects which are not present locally from this remote")
+		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths with --source")
+		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths with --source")
 	})
 }
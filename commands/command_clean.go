@@ -7,9 +7,15 @@ import (
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
+var (
+	cleanStdinFilename string
+)
+
 // clean cleans an object read from the given `io.Reader`, "from", and writes
 // out a corresponding pointer to the `io.Writer`, "to". If there were any
 // errors encountered along the way, they will be returned immediately if the
@@ -66,19 +72,36 @@ func clean(gf *lfs.GitFilter, to io.Writer, from io.Reader, fileName string, fil
 	}
 
 	tmpfile := cleaned.Filename
-	mediafile, err := gf.ObjectPath(cleaned.Oid)
+
+	var mediafile string
+	err = gf.RetryClean("create object directory", func() error {
+		mediafile, err = gf.ObjectPath(cleaned.Oid)
+		return err
+	})
 	if err != nil {
-		Panic(err, "Unable to get local media path.")
+		cleaned.Teardown()
+		PanicMsg(err, "clean.media-path")
 	}
 
 	if stat, _ := os.Stat(mediafile); stat != nil {
 		if stat.Size() != cleaned.Size && len(cleaned.Pointer.Extensions) == 0 {
-			Exit("Files don't match:\n%s\n%s", mediafile, tmpfile)
+			ExitMsg("clean.files-differ", tr.Args{"Object": mediafile, "Temp": tmpfile})
 		}
 		Debug("%s exists", mediafile)
 	} else {
-		if err := os.Rename(tmpfile, mediafile); err != nil {
-			Panic(err, "Unable to move %s to %s\n", tmpfile, mediafile)
+		err := gf.RetryClean("move object into place", func() error {
+			return tools.RenameAcrossDevices(tmpfile, mediafile)
+		})
+		if err == nil {
+			cfg.Filesystem().RecordObjectAdded(cleaned.Size)
+		} else if cfg.LFSObjectExists(cleaned.Oid, cleaned.Size) {
+			// Another process, perhaps in another repository sharing
+			// the same lfs.storage, stored the object first.
+			err = nil
+		}
+		if err != nil {
+			cleaned.Teardown()
+			PanicMsg(err, "clean.move-failed", tr.Args{"From": tmpfile, "To": mediafile})
 		}
 
 		Debug("Writing %s", mediafile)
@@ -88,26 +111,76 @@ func clean(gf *lfs.GitFilter, to io.Writer, from io.Reader, fileName string, fil
 	return cleaned.Pointer, err
 }
 
+// newCleanGitFilter returns a *lfs.GitFilter for cleaning files, which reports
+// its retries on Stderr if --verbose was given.
+func newCleanGitFilter() *lfs.GitFilter {
+	gf := lfs.NewGitFilter(cfg)
+	if isVerbose() {
+		gf.RetryLog = os.Stderr
+	}
+	return gf
+}
+
+// cleanFilter cleans the file "fileName" for the clean filter, as clean does,
+// unless it may not be stored in Git LFS. A file whose name is on the blocklist,
+// such as .gitattributes or .lfsconfig, is written out as it is, since Git LFS
+// could not read it as a pointer. A file larger than lfs.maxobjectsize is
+// reported when it is added, rather than only when the pre-commit hook rejects
+// it. Without a file name, neither check can be made.
+func cleanFilter(gf *lfs.GitFilter, to io.Writer, from io.Reader, fileName string) (*lfs.Pointer, error) {
+	if len(fileName) == 0 {
+		return clean(gf, to, from, fileName, -1)
+	}
+
+	if forbidden := blocklistItem(fileName); forbidden != "" {
+		ErrorMsg("clean.forbidden-file", tr.Args{"File": fileName, "Prefix": forbidden})
+		_, err := io.Copy(to, from)
+		return nil, err
+	}
+
+	ptr, err := clean(gf, to, from, fileName, -1)
+	if err == nil && ptr != nil {
+		// An invalid value is reported by the pre-commit hook, which
+		// can abort the commit; the filter cannot fail "git add" over it.
+		value, _ := cfg.Git.Get("lfs.maxobjectsize")
+		maxObject, perr := humanize.ParseBytes(value)
+		if len(value) > 0 && perr == nil && uint64(ptr.Size) > maxObject {
+			ErrorMsg("clean.larger-than-max-object-size", tr.Args{
+				"File": fileName,
+				"Size": humanize.FormatBytes(uint64(ptr.Size)),
+				"Max":  humanize.FormatBytes(maxObject),
+			})
+		}
+	}
+	return ptr, err
+}
+
 func cleanCommand(cmd *cobra.Command, args []string) {
 	requireStdin("This command should be run by the Git 'clean' filter")
-	installHooks(false)
-
-	var fileName string
-	if len(args) > 0 {
+	upgradeHooks()
+	requireTempDir()
+
+	// Git passes the file name as an argument through %f in the filter
+	// configuration, but other callers may only have it to give with
+	// --stdin-filename.
+	fileName := cleanStdinFilename
+	if len(fileName) == 0 && len(args) > 0 {
 		fileName = args[0]
 	}
 
-	gitfilter := lfs.NewGitFilter(cfg)
-	ptr, err := clean(gitfilter, os.Stdout, os.Stdin, fileName, -1)
+	gitfilter := newCleanGitFilter()
+	ptr, err := cleanFilter(gitfilter, os.Stdout, os.Stdin, fileName)
 	if err != nil {
 		Error(err.Error())
 	}
 
 	if ptr != nil && possiblyMalformedObjectSize(ptr.Size) {
-		Error("Possibly malformed conversion on Windows, see `git lfs help smudge` for more details.")
+		ErrorMsg("clean.malformed-on-windows")
 	}
 }
 
 func init() {
-	RegisterCommand("clean", cleanCommand, nil)
+	RegisterCommand("clean", cleanCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&cleanStdinFilename, "stdin-filename", "", "", "the name of the file whose contents are read from standard input")
+	})
 }
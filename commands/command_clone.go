@@ -11,6 +11,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +24,14 @@ var (
 func cloneCommand(cmd *cobra.Command, args []string) {
 	requireGitVersion()
 
+	// --quiet and --verbose are passed on to git clone, and also set the
+	// verbosity of Git LFS itself.
+	quietArg = cloneFlags.Quiet
+	if cloneFlags.Verbose {
+		verbosityArg = 1
+	}
+	setVerbosity()
+
 	if git.IsGitVersionAtLeast("2.15.0") {
 		msg := []string{
 			"WARNING: 'git lfs clone' is deprecated and will not be updated",
@@ -38,13 +47,13 @@ func cloneCommand(cmd *cobra.Command, args []string) {
 	// We pass all args to git clone
 	err := git.CloneWithoutFilters(cloneFlags, args)
 	if err != nil {
-		Exit("Error(s) during clone:\n%v", err)
+		ExitMsg("clone.errors", tr.Args{"Err": err})
 	}
 
 	// now execute pull (need to be inside dir)
 	cwd, err := tools.Getwd()
 	if err != nil {
-		Exit("Unable to derive current working dir: %v", err)
+		ExitMsg("clone.working-dir", tr.Args{"Err": err})
 	}
 
 	// Either the last argument was a relative or local dir, or we have to
@@ -58,46 +67,89 @@ func cloneCommand(cmd *cobra.Command, args []string) {
 		}
 		clonedir, _ = filepath.Abs(base)
 		if !tools.DirExists(clonedir) {
-			Exit("Unable to find clone dir at %q", clonedir)
+			ExitMsg("clone.dir-not-found", tr.Args{"Dir": clonedir})
 		}
 	}
 
 	err = os.Chdir(clonedir)
 	if err != nil {
-		Exit("Unable to change directory to clone dir %q: %v", clonedir, err)
+		ExitMsg("clone.chdir-failed", tr.Args{"Dir": clonedir, "Err": err})
 	}
 
 	// Make sure we pop back to dir we started in at the end
 	defer os.Chdir(cwd)
 
 	requireInRepo()
+	checkMinClientVersion()
 
 	// Support --origin option to clone
 	if len(cloneFlags.Origin) > 0 {
 		cfg.SetRemote(cloneFlags.Origin)
 	}
 
+	if !cloneSkipRepoInstall {
+		// If --skip-repo wasn't given, install repo-level hooks while
+		// we're still in the checkout directory. Do so before
+		// downloading anything, so that the clone is usable even if
+		// that fails.
+
+		if err := installHooks(false); err != nil {
+			ExitWithError(err)
+		}
+	}
+
+	if isShallowClone() {
+		disableFetchRecent()
+	}
+
 	if ref, err := git.CurrentRef(); err == nil {
 		includeArg, excludeArg := getIncludeExcludeArgs(cmd)
 		filter := buildFilepathFilter(cfg, includeArg, excludeArg, true)
 		if cloneFlags.NoCheckout || cloneFlags.Bare {
 			// If --no-checkout or --bare then we shouldn't check out, just fetch instead
-			fetchRef(ref.Name, filter)
+			if !fetchRef(ref.Name, filter) {
+				cloneIncomplete(clonedir, "git lfs fetch")
+			}
 		} else {
-			pull(filter)
+			if err := pull(filter); err != nil {
+				FullError(err)
+				cloneIncomplete(clonedir, "git lfs pull")
+			}
 			err := postCloneSubmodules(args)
 			if err != nil {
-				Exit("Error performing 'git lfs pull' for submodules: %v", err)
+				ErrorMsg("clone.submodules-pull-failed", tr.Args{"Err": err})
+				cloneIncomplete(clonedir, "git submodule foreach --recursive git lfs pull")
 			}
 		}
 	}
+}
 
-	if !cloneSkipRepoInstall {
-		// If --skip-repo wasn't given, install repo-level hooks while
-		// we're still in the checkout directory.
+// cloneIncomplete exits after a clone for which not every Git LFS object could
+// be downloaded, explaining how to finish it. The clone is left in place, since
+// everything else was cloned, and any files missing their objects are left as
+// pointers, so it can be used as it is.
+func cloneIncomplete(clonedir, retry string) {
+	ExitMsg("clone.incomplete", tr.Args{"Dir": clonedir, "Command": retry})
+}
 
-		if err := installHooks(false); err != nil {
-			ExitWithError(err)
+// isShallowClone returns whether the repository was cloned with --depth,
+// --shallow-since or --shallow-exclude, in which case Git records the commits
+// at which history was cut off in the "shallow" file.
+func isShallowClone() bool {
+	return tools.FileExists(filepath.Join(cfg.LocalGitStorageDir(), "shallow"))
+}
+
+// disableFetchRecent sets lfs.fetchrecentrefsdays and lfs.fetchrecentcommitsdays
+// to 0 in a shallow clone, unless they were given with --config, so that
+// fetching recent refs and commits, which needs the history that a shallow
+// clone lacks, is skipped.
+func disableFetchRecent() {
+	for _, key := range []string{"lfs.fetchrecentrefsdays", "lfs.fetchrecentcommitsdays"} {
+		if len(cfg.GitConfig().FindLocal(key)) > 0 {
+			continue
+		}
+		if _, err := cfg.SetGitLocalKey(key, "0"); err != nil {
+			ErrorMsg("clone.shallow-config-failed", tr.Args{"Key": key, "Err": err})
 		}
 	}
 }
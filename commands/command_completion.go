@@ -0,0 +1,326 @@
+package commands
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/git/gitattr"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// completionFunc returns the candidates for a word being completed, before
+// they are matched against what has been typed so far. It returns nothing,
+// rather than an error, if they cannot be found, such as outside a
+// repository, since a completion must never print anything else.
+type completionFunc func() []string
+
+var (
+	// completionArgs are the completers of the arguments of each command,
+	// by its name below git-lfs. The last completer is used for every
+	// argument after it. Arguments of other commands are completed as
+	// file names by the shell.
+	completionArgs = map[string][]completionFunc{
+		"fetch":   {completeRemotes, completeRefs},
+		"lock":    {completeLockable},
+		"pull":    {completeRemotes, nil},
+		"push":    {completeRemotes, completeRefs},
+		"size":    {completeRefs},
+		"unlock":  {completeLockable},
+		"untrack": {completeTracked},
+	}
+
+	// completionFlags are the completers of the values of flags, by the
+	// flag's name, for every command.
+	completionFlags = map[string]completionFunc{
+		"remote": completeRemotes,
+		"source": completeRemotes,
+	}
+)
+
+// completionCommand prints the script which completes Git LFS commands in the
+// given shell.
+func completionCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		ExitMsg("completion.usage")
+	}
+
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		ExitMsg("completion.unknown-shell", tr.Args{"Shell": args[0]})
+	}
+	os.Stdout.WriteString(script)
+}
+
+// completeCommand prints the candidates for the last of "args", which are the
+// words of a git-lfs command line after 'git lfs', one per line. It is run by
+// the scripts printed by completionCommand as 'git lfs __complete <args>'.
+func completeCommand(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		return
+	}
+
+	for _, c := range completions(cmd.Root(), args[:len(args)-1], args[len(args)-1]) {
+		os.Stdout.WriteString(c + "\n")
+	}
+}
+
+// completions returns the candidates for the word "cur", which follows the
+// words "prev" of a command line after 'git lfs'.
+func completions(root *cobra.Command, prev []string, cur string) []string {
+	cmd := root
+	var positional []string
+
+	for i := 0; i < len(prev); i++ {
+		word := prev[i]
+		if word == "--" {
+			positional = append(positional, prev[i+1:]...)
+			break
+		}
+
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			if f := completionFlag(cmd, word); f != nil && flagNeedsValue(f) && !strings.Contains(word, "=") {
+				// Skip the flag's value.
+				i++
+			}
+			continue
+		}
+
+		if len(positional) == 0 {
+			if sub := findSubcommand(cmd, word); sub != nil {
+				cmd = sub
+				continue
+			}
+		}
+		positional = append(positional, word)
+	}
+
+	// The value of a flag, such as 'git lfs fetch --remote <cur>'.
+	if n := len(prev); n > 0 && strings.HasPrefix(prev[n-1], "-") && !strings.Contains(prev[n-1], "=") {
+		if f := completionFlag(cmd, prev[n-1]); f != nil && flagNeedsValue(f) {
+			return filterCompletions(completionFlags[f.Name], "", cur)
+		}
+	}
+
+	if strings.HasPrefix(cur, "--") {
+		if i := strings.Index(cur, "="); i > 0 {
+			f := completionFlag(cmd, cur[:i])
+			if f == nil {
+				return nil
+			}
+			return filterCompletions(completionFlags[f.Name], cur[:i+1], cur[i+1:])
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		return filterCompletions(func() []string {
+			return flagNames(cmd)
+		}, "", cur)
+	}
+
+	if len(positional) == 0 && cmd.HasSubCommands() {
+		return filterCompletions(func() []string {
+			return subcommandNames(cmd)
+		}, "", cur)
+	}
+
+	fns, ok := completionArgs[cmd.Name()]
+	if !ok || cmd.Parent() != root || len(fns) == 0 {
+		return nil
+	}
+	if len(positional) < len(fns) {
+		return filterCompletions(fns[len(positional)], "", cur)
+	}
+	return filterCompletions(fns[len(fns)-1], "", cur)
+}
+
+// filterCompletions returns the candidates of "fn" which begin with "cur", each
+// with the given prefix.
+func filterCompletions(fn completionFunc, prefix, cur string) []string {
+	if fn == nil {
+		return nil
+	}
+
+	var matched []string
+	seen := make(map[string]bool)
+	for _, c := range fn() {
+		if strings.HasPrefix(c, cur) && !seen[c] {
+			seen[c] = true
+			matched = append(matched, prefix+c)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+func findSubcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, sub := range cmd.Commands() {
+		if sub.Name() == name || sub.HasAlias(name) {
+			return sub
+		}
+	}
+	return nil
+}
+
+func subcommandNames(cmd *cobra.Command) []string {
+	var names []string
+	for _, sub := range cmd.Commands() {
+		if !sub.Hidden {
+			names = append(names, sub.Name())
+		}
+	}
+	return names
+}
+
+// completionFlag returns the flag of "cmd", including those it inherits, named
+// by "word", such as "--remote", "--remote=origin", or "-r".
+func completionFlag(cmd *cobra.Command, word string) *pflag.Flag {
+	if strings.HasPrefix(word, "--") {
+		name := strings.TrimPrefix(word, "--")
+		if i := strings.Index(name, "="); i >= 0 {
+			name = name[:i]
+		}
+		if f := cmd.Flags().Lookup(name); f != nil {
+			return f
+		}
+		return cmd.InheritedFlags().Lookup(name)
+	}
+
+	if len(word) != 2 {
+		// A group of short flags, such as "-vv", or a short flag
+		// followed by its value.
+		return nil
+	}
+	if f := cmd.Flags().ShorthandLookup(word[1:]); f != nil {
+		return f
+	}
+	return cmd.InheritedFlags().ShorthandLookup(word[1:])
+}
+
+func flagNeedsValue(f *pflag.Flag) bool {
+	return len(f.NoOptDefVal) == 0 && f.Value.Type() != "bool"
+}
+
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	add := func(f *pflag.Flag) {
+		if !f.Hidden {
+			names = append(names, "--"+f.Name)
+		}
+	}
+	cmd.Flags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	return names
+}
+
+// completeRemotes returns the names of the remotes of the repository.
+func completeRemotes() []string {
+	return cfg.Remotes()
+}
+
+// completeRefs returns the names of the local branches and tags, and of the
+// branches of every remote, without the name of the remote.
+func completeRefs() []string {
+	refs, err := git.AllRefs()
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, ref := range refs {
+		switch ref.Type {
+		case git.RefTypeLocalBranch, git.RefTypeLocalTag:
+			names = append(names, ref.Name)
+		case git.RefTypeRemoteBranch:
+			if i := strings.Index(ref.Name, "/"); i >= 0 && ref.Name[i+1:] != "HEAD" {
+				names = append(names, ref.Name[i+1:])
+			}
+		}
+	}
+	return names
+}
+
+// completeTracked returns the patterns tracked by the .gitattributes file in
+// the current directory, which are those that 'git lfs untrack' can remove.
+func completeTracked() []string {
+	f, err := os.Open(".gitattributes")
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, "filter=lfs") {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			patterns = append(patterns, unescapeAttrPattern(fields[0]))
+		}
+	}
+	return patterns
+}
+
+// completeLockable returns the files in the working tree which match a
+// lockable pattern, relative to the current directory.
+func completeLockable() []string {
+	root := cfg.LocalWorkingDir()
+	if len(root) == 0 {
+		return nil
+	}
+
+	var lockable []string
+	for _, p := range git.GetAttributePaths(gitattr.NewMacroProcessor(), root, cfg.LocalGitDir()) {
+		if p.Lockable {
+			lockable = append(lockable, filepath.ToSlash(p.Path))
+		}
+	}
+	if len(lockable) == 0 {
+		return nil
+	}
+	filter := filepathfilter.New(lockable, nil, filepathfilter.DefaultValue(false))
+
+	files, err := git.NewLsFiles(root, true)
+	if err != nil {
+		return nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for name := range files.Files {
+		if !filter.Allows(name) {
+			continue
+		}
+		rel, err := filepath.Rel(wd, filepath.Join(root, name))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func init() {
+	RegisterCommand("completion", completionCommand, func(cmd *cobra.Command) {
+		cmd.PreRun = nil
+	})
+	RegisterCommand("__complete", completeCommand, func(cmd *cobra.Command) {
+		cmd.PreRun = nil
+		cmd.Hidden = true
+		// Every word is passed on to be completed, including those
+		// which are flags of the command being completed.
+		cmd.DisableFlagParsing = true
+	})
+}
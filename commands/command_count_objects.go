@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+// countObjectsTally is the number and total size of a set of files.
+type countObjectsTally struct {
+	count int64
+	size  int64
+}
+
+func (t *countObjectsTally) add(size int64) {
+	t.count++
+	t.size += size
+}
+
+// countObjectsCommand reports how many objects are in the local object store
+// and how much space they use, much as git-count-objects(1) does for Git's
+// loose objects. It only reads the store, so it never fails.
+func countObjectsCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	var total countObjectsTally
+	buckets := make(map[string]*countObjectsTally)
+
+	err := cfg.EachLFSObject(func(obj fs.Object) error {
+		total.add(obj.Size)
+
+		bucket := filepath.Join(obj.Oid[0:2], obj.Oid[2:4])
+		if buckets[bucket] == nil {
+			buckets[bucket] = &countObjectsTally{}
+		}
+		buckets[bucket].add(obj.Size)
+		return nil
+	})
+	if err != nil {
+		ErrorMsg("common.read-objects-failed", tr.Args{"Err": err})
+	}
+
+	Print("%d objects, %d bytes", total.count, total.size)
+	if !isVerbose() {
+		return
+	}
+
+	incomplete := countObjectsFiles(filepath.Join(cfg.LFSStorageDir(), "incomplete"), func(name string) bool {
+		return strings.HasSuffix(name, ".part")
+	})
+	tmp := countObjectsFiles(cfg.TempDir(), func(string) bool { return true })
+
+	Print("incomplete: %d files, %d bytes", incomplete.count, incomplete.size)
+	Print("tmp: %d files, %d bytes", tmp.count, tmp.size)
+
+	names := make([]string, 0, len(buckets))
+	for name := range buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		Print("%s: %d objects, %d bytes", filepath.ToSlash(filepath.Join("objects", name)), buckets[name].count, buckets[name].size)
+	}
+}
+
+// countObjectsFiles returns the number and total size of the files in "dir"
+// whose names match "fn". A directory which does not exist has no files.
+func countObjectsFiles(dir string, fn func(name string) bool) countObjectsTally {
+	var tally countObjectsTally
+	tools.FastWalkDir(dir, func(parentDir string, info os.FileInfo, err error) {
+		if err != nil {
+			if !os.IsNotExist(err) {
+				ErrorMsg("count-objects.read-failed", tr.Args{"Dir": dir, "Err": err})
+			}
+			return
+		}
+		if !info.IsDir() && fn(info.Name()) {
+			tally.add(info.Size())
+		}
+	})
+	return tally
+}
+
+func init() {
+	RegisterCommand("count-objects", countObjectsCommand, func(cmd *cobra.Command) {
+	})
+}
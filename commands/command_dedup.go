@@ -10,6 +10,7 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -30,14 +31,14 @@ func dedupTestCommand(*cobra.Command, []string) {
 		if err == nil {
 			err = errors.New("Unknown reason.")
 		}
-		Exit("This system does not support deduplication. %s", err)
+		ExitMsg("dedup.unsupported-reason", tr.Args{"Err": err})
 	}
 
 	if len(cfg.Extensions()) > 0 {
-		Exit("This platform supports file de-duplication, however, Git LFS extensions are configured and therefore de-duplication can not be used.")
+		ExitMsg("dedup.extensions-configured")
 	}
 
-	Print("OK: This platform and repository support file de-duplication.")
+	PrintMsg("dedup.ok")
 }
 
 func dedupCommand(cmd *cobra.Command, args []string) {
@@ -47,35 +48,39 @@ func dedupCommand(cmd *cobra.Command, args []string) {
 	}
 
 	requireInRepo()
+	if !cfg.Git.Bool("lfs.dedup", true) {
+		ExitMsg("dedup.disabled")
+	}
+
 	if gitDir, err := git.GitDir(); err != nil {
 		ExitWithError(err)
 	} else if supported, err := tools.CheckCloneFileSupported(gitDir); err != nil || !supported {
-		Exit("This system does not support deduplication.")
+		ExitMsg("dedup.unsupported")
 	}
 
 	if len(cfg.Extensions()) > 0 {
-		Exit("This platform supports file de-duplication, however, Git LFS extensions are configured and therefore de-duplication can not be used.")
+		ExitMsg("dedup.extensions-configured")
 	}
 
 	if dirty, err := git.IsWorkingCopyDirty(); err != nil {
 		ExitWithError(err)
 	} else if dirty {
-		Exit("Working tree is dirty. Please commit or reset your change.")
+		ExitMsg("dedup.dirty")
 	}
 
 	// We assume working tree is clean.
 	gitScanner := lfs.NewGitScanner(config.New(), func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			Exit("Could not scan for Git LFS tree: %s", err)
+			ExitMsg("common.scan-tree-failed", tr.Args{"Err": err})
 			return
 		}
 
 		if success, err := dedup(p); err != nil {
-			Error("Skipped: %s (Size: %d)\n          %s", p.Name, p.Size, err)
+			ErrorMsg("dedup.skipped-error", tr.Args{"Name": p.Name, "Size": p.Size, "Err": err})
 		} else if !success {
-			Error("Skipped: %s (Size: %d)", p.Name, p.Size)
+			ErrorMsg("dedup.skipped", tr.Args{"Name": p.Name, "Size": p.Size})
 		} else if success {
-			Print("Success: %s (Size: %d)", p.Name, p.Size)
+			PrintMsg("dedup.success", tr.Args{"Name": p.Name, "Size": p.Size})
 
 			atomic.AddInt64(&dedupStats.totalProcessedCount, 1)
 			atomic.AddInt64(&dedupStats.totalProcessedSize, p.Size)
@@ -87,11 +92,10 @@ func dedupCommand(cmd *cobra.Command, args []string) {
 		ExitWithError(err)
 	}
 
-	Print("\n\nSuccessfully finished.\n"+
-		"  De-duplicated  size: %d bytes\n"+
-		"                count: %d",
-		dedupStats.totalProcessedSize,
-		dedupStats.totalProcessedCount)
+	PrintMsg("dedup.finished", tr.Args{
+		"Size":  dedupStats.totalProcessedSize,
+		"Count": dedupStats.totalProcessedCount,
+	})
 }
 
 // dedup executes
@@ -109,6 +113,11 @@ func dedup(p *lfs.WrappedPointer) (success bool, err error) {
 	if err != nil {
 		return false, err
 	}
+	if originalStat.Size() != p.Size || len(p.Extensions) > 0 {
+		// An object transformed by an extension, such as the built-in
+		// compress extension, is not the file's contents either.
+		return false, errors.New("file does not match its object")
+	}
 
 	// Do clone
 	srcFile := cfg.Filesystem().ObjectPathname(p.Oid)
@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffBinary  bool
+	diffContext int
+)
+
+const (
+	// defaultBinaryDiffMaxSize is the largest object shown by "git lfs
+	// diff --binary" unless lfs.binarydiffmaxsize says otherwise.
+	defaultBinaryDiffMaxSize = "10MB"
+)
+
+// diffSide is one version of a file being compared by "git lfs diff".
+type diffSide struct {
+	// ptr is the pointer to this version, or nil if the file does not
+	// exist on this side.
+	ptr *lfs.Pointer
+	// worktree is the path to the working copy of this version, if it is
+	// read from disk rather than from the object store.
+	worktree string
+}
+
+func diffCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	revs, paths := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		revs, paths = args[:dash], args[dash:]
+	}
+	if len(revs) > 2 {
+		ExitMsg("diff.usage")
+	}
+
+	from, to := "HEAD", ""
+	if len(revs) > 0 {
+		from = revs[0]
+	}
+	if len(revs) > 1 {
+		to = revs[1]
+	}
+
+	var filter *filepathfilter.Filter
+	if len(paths) > 0 {
+		filter = filepathfilter.New(paths, nil)
+	}
+
+	olds, err := diffPointersAt(from, filter)
+	if err != nil {
+		ExitWithError(errors.Wrapf(err, "could not scan %s", from))
+	}
+
+	var news map[string]*diffSide
+	if len(to) > 0 {
+		news, err = diffPointersAt(to, filter)
+	} else {
+		news, err = diffWorkingCopy(olds, paths, filter)
+	}
+	if err != nil {
+		ExitWithError(errors.Wrap(err, "could not scan for Git LFS files"))
+	}
+
+	names := make([]string, 0, len(olds)+len(news))
+	for name := range olds {
+		names = append(names, name)
+	}
+	for name := range news {
+		if _, ok := olds[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	maxSize := binaryDiffMaxSize()
+	gf := lfs.NewGitFilter(cfg)
+	manifest := getTransferManifestOperationRemote("download", cfg.Remote())
+
+	for _, name := range names {
+		old, cur := olds[name], news[name]
+		if old == nil {
+			old = &diffSide{}
+		}
+		if cur == nil {
+			cur = &diffSide{}
+		}
+		if old.ptr != nil && cur.ptr != nil && old.ptr.Oid == cur.ptr.Oid {
+			continue
+		}
+
+		fromName, toName := "a/"+name, "b/"+name
+		if old.ptr == nil {
+			fromName = "/dev/null"
+		}
+		if cur.ptr == nil {
+			toName = "/dev/null"
+		}
+
+		Print("diff --git a/%s b/%s", name, name)
+
+		if !diffBinary {
+			err = lfs.UnifiedDiff(os.Stdout, fromName, toName,
+				diffPointerLines(old.ptr), diffPointerLines(cur.ptr), diffContext)
+			if err != nil {
+				ExitWithError(err)
+			}
+			continue
+		}
+
+		if old.size() > maxSize || cur.size() > maxSize {
+			PrintMsg("diff.too-large", tr.Args{"Name": name, "Max": humanize.FormatBytes(uint64(maxSize))})
+			continue
+		}
+
+		var oldData, newData bytes.Buffer
+		if err := old.contents(gf, manifest, name, &oldData); err != nil {
+			ExitWithError(errors.Wrapf(err, "could not read %s", fromName))
+		}
+		if err := cur.contents(gf, manifest, name, &newData); err != nil {
+			ExitWithError(errors.Wrapf(err, "could not read %s", toName))
+		}
+
+		err := lfs.HexDiff(os.Stdout, fromName, toName, &oldData, &newData, diffContext)
+		if err == lfs.ErrDiffTooLarge {
+			Print("Binary files %s and %s differ", fromName, toName)
+		} else if err != nil {
+			ExitWithError(err)
+		}
+	}
+}
+
+// diffPointersAt returns the Git LFS files in the tree at "ref" which match
+// "filter", keyed by their path.
+func diffPointersAt(ref string, filter *filepathfilter.Filter) (map[string]*diffSide, error) {
+	sides := make(map[string]*diffSide)
+
+	var scanErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+		sides[p.Name] = &diffSide{ptr: p.Pointer}
+	})
+	defer gitscanner.Close()
+
+	gitscanner.Filter = filter
+	if err := gitscanner.ScanTree(ref); err != nil {
+		return nil, err
+	}
+	return sides, scanErr
+}
+
+// diffWorkingCopy returns the working copies of the files named in "olds" and
+// "paths", and of the Git LFS files in the index which match "filter", so that
+// new files are shown once they are added. They are keyed by their path. Files
+// which are not present on disk are omitted.
+func diffWorkingCopy(olds map[string]*diffSide, paths []string, filter *filepathfilter.Filter) (map[string]*diffSide, error) {
+	staged, err := diffPointersInIndex(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		staged[filepath.ToSlash(path)] = true
+	}
+
+	names := make([]string, 0, len(olds)+len(staged))
+	for name := range olds {
+		names = append(names, name)
+	}
+	for name := range staged {
+		if _, ok := olds[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sides := make(map[string]*diffSide)
+	for _, name := range names {
+		path := filepath.Join(cfg.LocalWorkingDir(), filepath.FromSlash(name))
+
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// A file which was never smudged is still a pointer, in which
+		// case its contents must come from the object store.
+		if ptr, err := lfs.DecodePointer(io.LimitReader(f, 1024)); err == nil {
+			f.Close()
+			sides[name] = &diffSide{ptr: ptr}
+			continue
+		}
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+
+		h := sha256.New()
+		size, err := io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		sides[name] = &diffSide{
+			ptr:      lfs.NewPointer(hex.EncodeToString(h.Sum(nil)), size, nil),
+			worktree: path,
+		}
+	}
+	return sides, nil
+}
+
+// diffPointersInIndex returns the names of the Git LFS files in the index
+// which match "filter".
+func diffPointersInIndex(filter *filepathfilter.Filter) (map[string]bool, error) {
+	names := make(map[string]bool)
+
+	var scanErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+		names[p.Name] = true
+	})
+	defer gitscanner.Close()
+
+	gitscanner.Filter = filter
+	if err := gitscanner.ScanIndexTree(); err != nil {
+		return nil, err
+	}
+	return names, scanErr
+}
+
+// diffPointerLines returns the lines of the encoded pointer "p", or none if it
+// is nil.
+func diffPointerLines(p *lfs.Pointer) []string {
+	if p == nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(p.Encoded(), "\n"), "\n")
+}
+
+// size returns the size of this version, or zero if it does not exist.
+func (s *diffSide) size() int64 {
+	if s.ptr == nil {
+		return 0
+	}
+	return s.ptr.Size
+}
+
+// contents writes the contents of this version to "w", downloading it first
+// if it is not present in the local object store.
+func (s *diffSide) contents(gf *lfs.GitFilter, manifest *tq.Manifest, name string, w io.Writer) error {
+	if s.ptr == nil {
+		return nil
+	}
+
+	if len(s.worktree) > 0 {
+		f, err := os.Open(s.worktree)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	_, err := gf.Smudge(w, s.ptr, name, true, manifest, nil)
+	return err
+}
+
+// binaryDiffMaxSize returns the size above which objects are not shown by
+// "git lfs diff --binary", as configured by lfs.binarydiffmaxsize.
+func binaryDiffMaxSize() int64 {
+	value, ok := cfg.Git.Get("lfs.binarydiffmaxsize")
+	if !ok || len(value) == 0 {
+		value = defaultBinaryDiffMaxSize
+	}
+
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		ExitMsg("diff.invalid-max-size", tr.Args{"Value": value})
+	}
+	return int64(size)
+}
+
+func init() {
+	RegisterCommand("diff", diffCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVar(&diffBinary, "binary", false, "Show a diff of the hex dumps of each file's contents")
+		cmd.Flags().IntVarP(&diffContext, "context", "U", lfs.DefaultDiffContext, "Number of lines of context to show")
+	})
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/spf13/cobra"
+)
+
+// pointerDiff is the output of `git lfs diff-pointer` for two valid pointers.
+type pointerDiff struct {
+	OidBefore  string `json:"oid_before"`
+	OidAfter   string `json:"oid_after"`
+	SizeBefore int64  `json:"size_before"`
+	SizeAfter  int64  `json:"size_after"`
+	SizeDelta  int64  `json:"size_delta"`
+}
+
+// pointerDiffError is the output of `git lfs diff-pointer` when one of the
+// files cannot be read as a pointer.
+type pointerDiffError struct {
+	Error string `json:"error"`
+	File  string `json:"file"`
+}
+
+// diffPointerCommand compares two pointer files, and prints the change in
+// their OID and size as JSON, for scripts which would otherwise have to parse
+// the output of `git diff`.
+func diffPointerCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		ExitMsg("diff-pointer.usage")
+	}
+
+	before, err := diffPointerDecode(args[0])
+	if err != nil {
+		diffPointerFail(args[0], err)
+	}
+	after, err := diffPointerDecode(args[1])
+	if err != nil {
+		diffPointerFail(args[1], err)
+	}
+
+	diffPointerWrite(&pointerDiff{
+		OidBefore:  before.Oid,
+		OidAfter:   after.Oid,
+		SizeBefore: before.Size,
+		SizeAfter:  after.Size,
+		SizeDelta:  after.Size - before.Size,
+	})
+}
+
+func diffPointerDecode(path string) (*lfs.Pointer, error) {
+	p, err := lfs.DecodePointerFromFile(path)
+	if err != nil && !os.IsNotExist(err) && !os.IsPermission(err) {
+		// Anything which was read, but could not be parsed, is
+		// reported the same way, so that scripts can match on it.
+		return nil, errors.NewNotAPointerError(err)
+	}
+	return p, err
+}
+
+// diffPointerFail prints why "path" could not be read as a pointer, and exits.
+func diffPointerFail(path string, err error) {
+	msg := "not a pointer"
+	if !errors.IsNotAPointerError(err) {
+		msg = err.Error()
+	}
+
+	diffPointerWrite(&pointerDiffError{Error: msg, File: path})
+	os.Exit(exitCodeFailure)
+}
+
+func diffPointerWrite(v interface{}) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		ExitWithError(err)
+	}
+}
+
+func init() {
+	RegisterCommand("diff-pointer", diffPointerCommand, func(cmd *cobra.Command) {
+		cmd.PreRun = nil
+	})
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	duBytesArg       bool
+	duJSONArg        bool
+	duByExtensionArg bool
+	duByDirArg       int
+	duByAccessAgeArg bool
+)
+
+// duAccessAges are the buckets into which `git lfs du --by-access-age` groups
+// objects by how long ago they were last read.
+var duAccessAges = []struct {
+	name string
+	age  time.Duration
+}{
+	{"< 1 day", 24 * time.Hour},
+	{"< 1 week", 7 * 24 * time.Hour},
+	{"< 30 days", 30 * 24 * time.Hour},
+	{"< 180 days", 180 * 24 * time.Hour},
+	{"< 1 year", 365 * 24 * time.Hour},
+}
+
+// duEntry is the number and total size of a group of objects, as reported by
+// `git lfs du`.
+type duEntry struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// duEntriesBySize sorts entries by decreasing size, and then by name.
+type duEntriesBySize []*duEntry
+
+func (e duEntriesBySize) Len() int      { return len(e) }
+func (e duEntriesBySize) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e duEntriesBySize) Less(i, j int) bool {
+	if e[i].Size != e[j].Size {
+		return e[i].Size > e[j].Size
+	}
+	return e[i].Name < e[j].Name
+}
+
+// duCommand summarizes the space used by the local object store: how much of
+// it the current checkout uses, how much prune would keep for recent refs and
+// unpushed commits, and how much prune would delete.
+func duCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	if duByDirArg < 0 {
+		ExitMsg("du.invalid-depth", tr.Args{"Depth": duByDirArg})
+	}
+
+	localObjects := make(map[string]int64)
+	if err := cfg.EachLFSObject(func(obj fs.Object) error {
+		localObjects[obj.Oid] = obj.Size
+		return nil
+	}); err != nil {
+		ErrorMsg("common.read-objects-failed", tr.Args{"Err": err})
+	}
+
+	checkout, pointers := duScanCheckout()
+	retained := duRetainedObjects()
+
+	var total, inCheckout, recent, prunable countObjectsTally
+	for oid, size := range localObjects {
+		total.add(size)
+		switch {
+		case checkout.Contains(oid):
+			inCheckout.add(size)
+		case retained.Contains(oid):
+			recent.add(size)
+		default:
+			prunable.add(size)
+		}
+	}
+
+	incomplete := countObjectsFiles(filepath.Join(cfg.LFSStorageDir(), "incomplete"), func(name string) bool {
+		return strings.HasSuffix(name, ".part")
+	})
+	tmp := countObjectsFiles(cfg.TempDir(), func(string) bool { return true })
+
+	summary := []*duEntry{
+		{"objects", total.count, total.size},
+		{"checkout", inCheckout.count, inCheckout.size},
+		{"recent", recent.count, recent.size},
+		{"prunable", prunable.count, prunable.size},
+		{"incomplete", incomplete.count, incomplete.size},
+		{"tmp", tmp.count, tmp.size},
+	}
+
+	var byExtension, byDir, byAccessAge []*duEntry
+	if duByExtensionArg {
+		byExtension = duBreakdown(pointers, localObjects, duExtension)
+	}
+	if duByDirArg > 0 {
+		byDir = duBreakdown(pointers, localObjects, func(name string) string {
+			return duDir(name, duByDirArg)
+		})
+	}
+
+	if duByAccessAgeArg {
+		byAccessAge = duAccessAgeBreakdown(localObjects)
+	}
+
+	if duJSONArg {
+		out := struct {
+			Summary     []*duEntry `json:"summary"`
+			ByExtension []*duEntry `json:"by_extension,omitempty"`
+			ByDir       []*duEntry `json:"by_dir,omitempty"`
+			ByAccessAge []*duEntry `json:"by_access_age,omitempty"`
+		}{summary, byExtension, byDir, byAccessAge}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(out); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	duPrint(summary)
+	if duByExtensionArg {
+		PrintMsg("du.by-extension")
+		duPrint(byExtension)
+	}
+	if duByDirArg > 0 {
+		PrintMsg("du.by-directory")
+		duPrint(byDir)
+	}
+	if duByAccessAgeArg {
+		PrintMsg("du.by-last-access")
+		duPrint(byAccessAge)
+	}
+}
+
+// duScanCheckout returns the set of objects in the tree at HEAD, and the
+// pointers of every file in it, since the same object may be at more than
+// one path.
+func duScanCheckout() (tools.StringSet, []*lfs.WrappedPointer) {
+	checkout := tools.NewStringSet()
+	var pointers []*lfs.WrappedPointer
+
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
+			return
+		}
+		checkout.Add(p.Oid)
+		pointers = append(pointers, p)
+	})
+	defer gitscanner.Close()
+
+	if err := gitscanner.ScanTree("HEAD"); err != nil {
+		ExitWithError(err)
+	}
+	return checkout, pointers
+}
+
+// duRetainedObjects returns the objects which `git lfs prune` would keep under
+// the current settings.
+func duRetainedObjects() tools.StringSet {
+	retained := tools.NewStringSet()
+	fetchPruneConfig := lfs.NewFetchPruneConfig(cfg.Git)
+
+	errorChan := make(chan error, 10)
+	var errorwait sync.WaitGroup
+	errorwait.Add(1)
+	var taskErrors []error
+	go pruneTaskCollectErrors(&taskErrors, errorChan, &errorwait)
+
+	retainChan := make(chan string, 100)
+	var retainwait sync.WaitGroup
+	retainwait.Add(1)
+	go func() {
+		defer retainwait.Done()
+		for oid := range retainChan {
+			retained.Add(oid)
+		}
+	}()
+
+	gitscanner := lfs.NewGitScanner(cfg, nil)
+	gitscanner.Filter = filepathfilter.New(nil, cfg.FetchExcludePaths())
+
+	sem := semaphore.NewWeighted(int64(runtime.NumCPU() * 2))
+
+	var taskwait sync.WaitGroup
+	taskwait.Add(3)
+	go pruneTaskGetRetainedCurrentAndRecentRefs(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
+	go pruneTaskGetRetainedUnpushed(gitscanner, fetchPruneConfig, retainChan, errorChan, &taskwait, sem)
+	go pruneTaskGetRetainedWorktree(gitscanner, retainChan, errorChan, &taskwait, sem)
+
+	taskwait.Wait()
+	gitscanner.Close()
+	close(retainChan)
+	retainwait.Wait()
+
+	close(errorChan)
+	errorwait.Wait()
+	if len(taskErrors) > 0 {
+		for _, err := range taskErrors {
+			LoggedErrorMsg(err, "du.error", tr.Args{"Err": err})
+		}
+		ExitMsg("du.kept-objects-failed")
+	}
+	return retained
+}
+
+// duBreakdown groups the files in "pointers" whose objects are present
+// locally by the name "group" gives their path. A file counts towards its
+// group even if its object is also at another path.
+func duBreakdown(pointers []*lfs.WrappedPointer, localObjects map[string]int64, group func(name string) string) []*duEntry {
+	groups := make(map[string]*duEntry)
+	for _, p := range pointers {
+		size, ok := localObjects[p.Oid]
+		if !ok {
+			continue
+		}
+
+		name := group(p.Name)
+		if groups[name] == nil {
+			groups[name] = &duEntry{Name: name}
+		}
+		groups[name].Count++
+		groups[name].Size += size
+	}
+
+	entries := make([]*duEntry, 0, len(groups))
+	for _, entry := range groups {
+		entries = append(entries, entry)
+	}
+	sort.Sort(duEntriesBySize(entries))
+	return entries
+}
+
+// duAccessAgeBreakdown groups all local objects by how long ago they were
+// last read, from the most to the least recently read. Objects which have not
+// been read since they were downloaded or added count from then.
+func duAccessAgeBreakdown(localObjects map[string]int64) []*duEntry {
+	accessTimes, err := cfg.Filesystem().AccessTimes()
+	if err != nil {
+		ExitMsg("common.access-times-failed", tr.Args{"Err": err})
+	}
+
+	entries := make([]*duEntry, len(duAccessAges)+1)
+	for i, bucket := range duAccessAges {
+		entries[i] = &duEntry{Name: bucket.name}
+	}
+	entries[len(duAccessAges)] = &duEntry{Name: ">= 1 year"}
+
+	for oid, size := range localObjects {
+		t, err := cfg.Filesystem().LastAccessed(accessTimes, oid)
+		if err != nil {
+			LoggedErrorMsg(err, "du.access-time-failed", tr.Args{"Oid": oid, "Err": err})
+			continue
+		}
+
+		i := 0
+		for i < len(duAccessAges) && time.Since(t) >= duAccessAges[i].age {
+			i++
+		}
+		entries[i].Count++
+		entries[i].Size += size
+	}
+	return entries
+}
+
+// duExtension returns the extension of the file at "name", or "(none)".
+func duExtension(name string) string {
+	if ext := path.Ext(path.Base(name)); len(ext) > 0 {
+		return ext
+	}
+	return "(none)"
+}
+
+// duDir returns the first "depth" directories of the file at "name", or "."
+// for a file at the root of the repository.
+func duDir(name string, depth int) string {
+	dirs := strings.Split(path.Dir(name), "/")
+	if dirs[0] == "." {
+		return "."
+	}
+	if len(dirs) > depth {
+		dirs = dirs[:depth]
+	}
+	return strings.Join(dirs, "/")
+}
+
+func duPrint(entries []*duEntry) {
+	width := 0
+	for _, entry := range entries {
+		width = tools.MaxInt(width, len(entry.Name))
+	}
+	for _, entry := range entries {
+		Print("%-*s  %s", width+1, entry.Name+":", tr.Tr("du.size-in-files", tr.Args{
+			"Size":  duFormatSize(entry.Size),
+			"Count": entry.Count,
+		}))
+	}
+}
+
+func duFormatSize(size int64) string {
+	if duBytesArg {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	return humanize.FormatBytes(uint64(size))
+}
+
+func init() {
+	RegisterCommand("du", duCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVar(&duBytesArg, "bytes", false, "Print sizes in bytes")
+		cmd.Flags().BoolVar(&duJSONArg, "json", false, "Print usage as JSON")
+		cmd.Flags().BoolVar(&duByExtensionArg, "by-extension", false, "Break down the objects of the checkout by file extension")
+		cmd.Flags().IntVar(&duByDirArg, "by-dir", 0, "Break down the objects of the checkout by directory, to the given depth")
+		cmd.Flags().BoolVar(&duByAccessAgeArg, "by-access-age", false, "Break down all local objects by when they were last read")
+	})
+}
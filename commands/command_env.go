@@ -7,9 +7,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	envMetrics bool
+)
+
 func envCommand(cmd *cobra.Command, args []string) {
 	config.ShowConfigWarnings = true
 
+	if envMetrics {
+		requireInRepo()
+
+		// Walking the store is cheap next to what was done to fill
+		// it, and corrects anything which changed it without being
+		// recorded.
+		if err := cfg.Filesystem().RecountObjects(); err != nil {
+			ExitWithError(err)
+		}
+
+		metrics, err := lfs.NewStorageMetrics(cfg.Filesystem())
+		if err != nil {
+			ExitWithError(err)
+		}
+		for _, env := range metrics.Environ() {
+			Print(env)
+		}
+		return
+	}
+
 	gitV, err := git.Version()
 	if err != nil {
 		gitV = "Error getting git version: " + err.Error()
@@ -50,10 +74,16 @@ func envCommand(cmd *cobra.Command, args []string) {
 
 	for _, key := range []string{"filter.lfs.process", "filter.lfs.smudge", "filter.lfs.clean"} {
 		value, _ := cfg.Git.Get(key)
-		Print("git config %s = %q", key, value)
+		if scope := cfg.GitConfig().FindScope(key); len(value) > 0 && len(scope) > 0 {
+			Print("git config %s = %q (%s)", key, value, scope)
+		} else {
+			Print("git config %s = %q", key, value)
+		}
 	}
 }
 
 func init() {
-	RegisterCommand("env", envCommand, nil)
+	RegisterCommand("env", envCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&envMetrics, "metrics", "", false, "Show local object store metrics")
+	})
 }
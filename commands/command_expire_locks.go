@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/locking"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	expireLocksCmdFlags expireLocksFlags
+)
+
+// expireLocksFlags holds the flags given to the `git lfs expire-locks` command
+type expireLocksFlags struct {
+	// OlderThan is the minimum age of a lock to be expired, such as "30d".
+	OlderThan string
+	// Owner, if given, restricts expiry to locks owned by this user.
+	Owner string
+	// DryRun specifies whether or not the locks should only be listed,
+	// rather than released.
+	DryRun bool
+}
+
+func expireLocksCommand(cmd *cobra.Command, args []string) {
+	age, err := parseAge(expireLocksCmdFlags.OlderThan)
+	if err != nil {
+		ExitMsg("expire-locks.invalid-older-than", tr.Args{"Err": err})
+	}
+
+	if len(lockRemote) > 0 {
+		cfg.SetRemote(lockRemote)
+	}
+
+	refUpdate := git.NewRefUpdate(cfg.Git, cfg.PushRemote(), cfg.CurrentRef(), nil)
+	lockClient := newLockClient()
+	lockClient.RemoteRef = refUpdate.Right()
+	defer lockClient.Close()
+
+	locks, err := lockClient.SearchLocks(nil, 0, false, false)
+	if err != nil {
+		ExitMsg("locks.retrieve-failed", tr.Args{"Err": errors.Cause(err)})
+	}
+
+	cutoff := time.Now().Add(-age)
+
+	var expired, failed int
+	for _, lock := range locks {
+		if !lock.LockedAt.Before(cutoff) {
+			continue
+		}
+		if len(expireLocksCmdFlags.Owner) > 0 && lockOwner(lock) != expireLocksCmdFlags.Owner {
+			continue
+		}
+
+		description := describeExpiredLock(lock)
+		if expireLocksCmdFlags.DryRun {
+			PrintMsg("expire-locks.would-expire", tr.Args{"Lock": description})
+			expired++
+			continue
+		}
+
+		if err := lockClient.UnlockFileById(lock.Id, true); err != nil {
+			ErrorMsg("expire-locks.failed", tr.Args{"Lock": description, "Err": errors.Cause(err)})
+			failed++
+			continue
+		}
+
+		InfoMsg("expire-locks.expired", tr.Args{"Lock": description})
+		expired++
+	}
+
+	summary := tr.Args{
+		"Count":     expired,
+		"Total":     len(locks),
+		"OlderThan": expireLocksCmdFlags.OlderThan,
+	}
+	if expireLocksCmdFlags.DryRun {
+		InfoMsg("expire-locks.would-expire-count", summary)
+	} else {
+		InfoMsg("expire-locks.expired-count", summary)
+	}
+
+	if failed > 0 {
+		ExitMsg("expire-locks.failed-count", tr.Args{"Count": failed})
+	}
+}
+
+// describeExpiredLock returns a human-readable description of "lock" for
+// the output of `git lfs expire-locks`.
+func describeExpiredLock(lock locking.Lock) string {
+	return fmt.Sprintf("lock %s on %s (owner: %s, locked at %s)",
+		lock.Id, lock.Path, lockOwner(lock), lock.LockedAt.Format(time.RFC3339))
+}
+
+// lockOwner returns the name of the owner of "lock", or an empty string if it
+// has none.
+func lockOwner(lock locking.Lock) string {
+	if lock.Owner == nil {
+		return ""
+	}
+	return lock.Owner.Name
+}
+
+// parseAge parses an age given to an option such as --older-than or prune's
+// --not-accessed-in. In addition to the units understood by
+// time.ParseDuration, it accepts a number of days ("30d") or weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+		if err != nil || n < 0 {
+			return 0, errors.Errorf("expected a number of days or weeks, got %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, errors.Errorf("expected a positive duration, got %q", s)
+	}
+	return d, nil
+}
+
+func init() {
+	RegisterCommand("expire-locks", expireLocksCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&lockRemote, "remote", "r", "", lockRemoteHelp)
+		cmd.Flags().StringVar(&expireLocksCmdFlags.OlderThan, "older-than", "30d", "expire locks older than this age, such as \"30d\" or \"12h\"")
+		cmd.Flags().StringVar(&expireLocksCmdFlags.Owner, "owner", "", "only expire locks owned by this user")
+		cmd.Flags().BoolVar(&expireLocksCmdFlags.DryRun, "dry-run", false, "list the locks that would be expired without releasing them")
+	})
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// objectsManifestName is the name of the tar entry which lists the
+	// objects in an archive written by `git lfs export-objects`, one
+	// "<oid> <size>" line per object. It is written before the objects.
+	objectsManifestName = "manifest"
+
+	// objectsArchiveDir is the directory in an archive which holds the
+	// objects, laid out as in the local object store.
+	objectsArchiveDir = "objects"
+)
+
+var (
+	exportObjectsAll      bool
+	exportObjectsOutput   string
+	exportObjectsCompress string
+	exportObjectsSince    string
+	exportObjectsLink     bool
+)
+
+// exportObjectsCommand writes the objects referenced by the given refs to a
+// tar archive, which `git lfs import-objects` unpacks into another repository's
+// object store, such as one in an air-gapped network. With --output, it instead
+// copies every cached object into a flat directory, for backups.
+func exportObjectsCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	if len(exportObjectsOutput) > 0 {
+		if len(args) > 0 || exportObjectsAll {
+			ExitMsg("export-objects.output-with-archive")
+		}
+		exportObjectsToDirectory(exportObjectsOutput)
+		return
+	}
+	if len(exportObjectsCompress) > 0 || len(exportObjectsSince) > 0 || exportObjectsLink {
+		ExitMsg("export-objects.requires-output")
+	}
+
+	if len(args) == 0 {
+		ExitMsg("export-objects.usage")
+	}
+	name, refs := args[0], args[1:]
+	if exportObjectsAll && len(refs) > 0 {
+		ExitMsg("export-objects.all-with-refs")
+	}
+
+	pointers, err := exportObjectsPointers(refs)
+	if err != nil {
+		ExitWithError(errors.Wrap(err, "Could not scan for Git LFS objects"))
+	}
+
+	var objects []*lfs.WrappedPointer
+	var missing int
+	for _, p := range pointers {
+		if !cfg.LFSObjectExists(p.Oid, p.Size) {
+			ErrorMsg("export-objects.missing-object", tr.Args{"Oid": p.Oid, "Name": p.Name})
+			missing++
+			continue
+		}
+		objects = append(objects, p)
+	}
+
+	var w io.Writer = os.Stdout
+	report := Print
+	if name != "-" {
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			ExitMsg("common.create-failed", tr.Args{"Path": name, "Err": err})
+		}
+		defer f.Close()
+		w = f
+	} else {
+		// Keep the archive on stdout clean.
+		report = Error
+	}
+
+	bw := bufio.NewWriter(w)
+	size, err := writeObjectsArchive(bw, objects)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		if name != "-" {
+			os.Remove(name)
+		}
+		ExitMsg("common.write-failed", tr.Args{"Path": name, "Err": err})
+	}
+
+	report("Exported %d object(s), %s", len(objects), humanize.FormatBytes(uint64(size)))
+	if missing > 0 {
+		ExitMsg("export-objects.missing-count", tr.Args{"Count": missing})
+	}
+}
+
+// exportObjectsPointers returns the pointers referenced by every commit
+// reachable from "refs", or from every ref with --all, once per object, in
+// order of OID.
+func exportObjectsPointers(refs []string) ([]*lfs.WrappedPointer, error) {
+	seen := make(map[string]*lfs.WrappedPointer)
+	var multiErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			if multiErr != nil {
+				multiErr = fmt.Errorf("%v\n%v", multiErr, err)
+			} else {
+				multiErr = err
+			}
+			return
+		}
+		if _, ok := seen[p.Oid]; !ok {
+			seen[p.Oid] = p
+		}
+	})
+	defer gitscanner.Close()
+
+	var err error
+	if exportObjectsAll {
+		err = gitscanner.ScanAll(nil)
+	} else {
+		if len(refs) == 0 {
+			ref, err := git.CurrentRef()
+			if err != nil {
+				return nil, err
+			}
+			refs = []string{ref.Sha}
+		}
+		err = gitscanner.ScanRefs(refs, nil, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if multiErr != nil {
+		return nil, multiErr
+	}
+
+	pointers := make([]*lfs.WrappedPointer, 0, len(seen))
+	for _, p := range seen {
+		pointers = append(pointers, p)
+	}
+	sort.Slice(pointers, func(i, j int) bool { return pointers[i].Oid < pointers[j].Oid })
+	return pointers, nil
+}
+
+// writeObjectsArchive writes a manifest of "objects" and then the objects
+// themselves to a tar archive on "w", a file at a time, and returns the total
+// size of the objects.
+func writeObjectsArchive(w io.Writer, objects []*lfs.WrappedPointer) (int64, error) {
+	tw := tar.NewWriter(w)
+
+	var manifest []byte
+	for _, p := range objects {
+		manifest = append(manifest, fmt.Sprintf("%s %d\n", p.Oid, p.Size)...)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     objectsManifestName,
+		Mode:     0644,
+		Size:     int64(len(manifest)),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return 0, err
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, p := range objects {
+		if err := writeArchiveObject(tw, p.Oid, p.Size); err != nil {
+			return total, errors.Wrapf(err, "could not export %s", p.Oid)
+		}
+		total += p.Size
+	}
+	return total, tw.Close()
+}
+
+// writeArchiveObject streams the object "oid" from the local object store into
+// "tw".
+func writeArchiveObject(tw *tar.Writer, oid string, size int64) error {
+	f, err := os.Open(cfg.Filesystem().ObjectPathname(oid))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     objectsArchivePath(oid),
+		Mode:     0644,
+		Size:     size,
+		ModTime:  info.ModTime(),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tw, f, size)
+	return err
+}
+
+// objectsArchivePath returns the name of the entry for the object "oid" in an
+// archive, such as "objects/ab/cd/abcd...".
+func objectsArchivePath(oid string) string {
+	return path.Join(objectsArchiveDir, oid[0:2], oid[2:4], oid)
+}
+
+func init() {
+	RegisterCommand("export-objects", exportObjectsCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&exportObjectsAll, "all", "a", false, "Export the objects referenced by every ref")
+		cmd.Flags().StringVarP(&exportObjectsOutput, "output", "o", "", "Copy every cached object into this directory")
+		cmd.Flags().StringVarP(&exportObjectsCompress, "compress", "", "", "Compress each object copied with --output")
+		cmd.Flags().Lookup("compress").NoOptDefVal = "gzip"
+		cmd.Flags().StringVarP(&exportObjectsSince, "since", "", "", "Only copy objects cached after this date")
+		cmd.Flags().BoolVarP(&exportObjectsLink, "link", "", false, "Symlink objects rather than copying them")
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +19,22 @@ var (
 	fetchRecentArg bool
 	fetchAllArg    bool
 	fetchPruneArg  bool
+	fetchVerifyArg bool
+	fetchForceArg  bool
+
+	// fetchLimitBytesArg is the --limit-bytes argument, and fetchLimitBytes
+	// the limit it gives, if any.
+	fetchLimitBytesArg string
+	fetchLimitBytes    *fetchLimit
+
+	// fetchCheckpointArg is the --checkpoint argument, and
+	// fetchCheckpointFile the checkpoint it names, if any.
+	fetchCheckpointArg  string
+	fetchCheckpointFile *fetchCheckpoint
+
+	// fetchNoSmudgeArg is accepted only to make the intent of a fetch
+	// explicit: unlike pull, fetch never updates the working tree.
+	fetchNoSmudgeArg bool
 )
 
 func getIncludeExcludeArgs(cmd *cobra.Command) (include, exclude *string) {
@@ -35,30 +52,43 @@ func getIncludeExcludeArgs(cmd *cobra.Command) (include, exclude *string) {
 
 func fetchCommand(cmd *cobra.Command, args []string) {
 	requireInRepo()
+	requireTempDir()
+
+	if cmd.Flag("limit-bytes").Changed {
+		limit, err := newFetchLimit(fetchLimitBytesArg)
+		if err != nil {
+			ExitMsg("fetch.invalid-limit-bytes", tr.Args{"Value": fetchLimitBytesArg, "Err": err})
+		}
+		fetchLimitBytes = limit
+	}
 
 	var refs []*git.Ref
 
 	if len(args) > 0 {
 		// Remote is first arg
 		if err := cfg.SetValidRemote(args[0]); err != nil {
-			Exit("Invalid remote name %q: %s", args[0], err)
+			ExitMsg("common.invalid-remote", tr.Args{"Remote": args[0], "Err": err})
 		}
 	}
 
 	if len(args) > 1 {
 		resolvedrefs, err := git.ResolveRefs(args[1:])
 		if err != nil {
-			Panic(err, "Invalid ref argument: %v", args[1:])
+			PanicMsg(err, "fetch.invalid-ref", tr.Args{"Refs": args[1:]})
 		}
 		refs = resolvedrefs
 	} else if !fetchAllArg {
 		ref, err := git.CurrentRef()
 		if err != nil {
-			Panic(err, "Could not fetch")
+			PanicMsg(err, "fetch.could-not-fetch")
 		}
 		refs = []*git.Ref{ref}
 	}
 
+	if len(fetchCheckpointArg) > 0 {
+		fetchCheckpointFile = openFetchCheckpoint(args, refs)
+	}
+
 	success := true
 	gitscanner := lfs.NewGitScanner(cfg, nil)
 	defer gitscanner.Close()
@@ -68,13 +98,13 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 
 	if fetchAllArg {
 		if fetchRecentArg {
-			Exit("Cannot combine --all with --recent")
+			ExitMsg("fetch.all-with-recent")
 		}
 		if include != nil || exclude != nil {
-			Exit("Cannot combine --all with --include or --exclude")
+			ExitMsg("fetch.all-with-include-exclude")
 		}
 		if len(cfg.FetchIncludePaths()) > 0 || len(cfg.FetchExcludePaths()) > 0 {
-			Print("Ignoring global include / exclude paths to fulfil --all")
+			InfoMsg("fetch.ignoring-global-include-exclude")
 		}
 
 		if len(args) > 1 {
@@ -92,7 +122,7 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 
 		// Fetch refs sequentially per arg order; duplicates in later refs will be ignored
 		for _, ref := range refs {
-			Print("fetch: Fetching reference %s", ref.Refspec())
+			InfoMsg("fetch.fetching-ref", tr.Args{"Ref": ref.Refspec()})
 			s := fetchRef(ref.Sha, filter)
 			success = success && s
 		}
@@ -104,18 +134,62 @@ func fetchCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if fetchPruneArg {
-		verify := fetchPruneCfg.PruneVerifyRemoteAlways
-		// no dry-run or verbose options in fetch, assume false
-		prune(fetchPruneCfg, verify, false, false)
+		// The objects have been fetched, so a shared object store only
+		// means that they cannot also be pruned, which is not worth
+		// failing for.
+		if cfg.Filesystem().IsSharedStorage() {
+			ErrorMsg("prune.shared-storage-skipped", tr.Args{"Dir": cfg.LFSStorageDir()})
+		} else {
+			verify := fetchPruneCfg.PruneVerifyRemoteAlways
+			// no dry-run or verbose options in fetch, assume false
+			prune(fetchPruneCfg, verify, false, false)
+		}
+	}
+
+	if fetchLimitBytes != nil {
+		fetchLimitBytes.report()
+	}
+	if fetchCheckpointFile != nil {
+		fetchCheckpointFile.close(success)
 	}
 
 	if !success {
 		c := getAPIClient()
 		e := c.Endpoints.Endpoint("download", cfg.Remote())
-		Exit("error: failed to fetch some objects from '%s'", e.Url)
+		ExitMsg("common.failed-to-fetch", tr.Args{"URL": e.Url})
 	}
 }
 
+// openFetchCheckpoint opens the checkpoint given by --checkpoint, for a fetch of
+// "refs" from the remote given in "args", if any.
+func openFetchCheckpoint(args []string, refs []*git.Ref) *fetchCheckpoint {
+	path := fetchCheckpointArg
+	auto := path == "auto"
+	if auto {
+		names := []string{"--all"}
+		if len(refs) > 0 {
+			names = make([]string, 0, len(refs))
+			for _, ref := range refs {
+				names = append(names, ref.Name)
+			}
+		}
+
+		e := getAPIClient().Endpoints.Endpoint("download", cfg.Remote())
+		p, err := autoFetchCheckpointPath(e.Url, names)
+		if err != nil {
+			ExitWithError(err)
+		}
+		path = p
+	}
+
+	checkpoint, err := newFetchCheckpoint(path)
+	if err != nil {
+		ExitWithError(err)
+	}
+	checkpoint.auto = auto
+	return checkpoint
+}
+
 func pointersToFetchForRef(ref string, filter *filepathfilter.Filter) ([]*lfs.WrappedPointer, error) {
 	var pointers []*lfs.WrappedPointer
 	var multiErr error
@@ -146,7 +220,7 @@ func pointersToFetchForRef(ref string, filter *filepathfilter.Filter) ([]*lfs.Wr
 func fetchRef(ref string, filter *filepathfilter.Filter) bool {
 	pointers, err := pointersToFetchForRef(ref, filter)
 	if err != nil {
-		Panic(err, "Could not scan for Git LFS files")
+		PanicMsg(err, "common.could-not-scan")
 	}
 	return fetchAndReportToChan(pointers, filter, nil)
 }
@@ -156,9 +230,7 @@ func pointersToFetchForRefs(refs []string) ([]*lfs.WrappedPointer, error) {
 	task := tasklog.NewSimpleTask()
 	defer task.Complete()
 
-	logger := tasklog.NewLogger(OutputWriter,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	logger := newLogger(OutputWriter)
 	logger.Enqueue(task)
 	var numObjs int64
 
@@ -191,7 +263,7 @@ func pointersToFetchForRefs(refs []string) ([]*lfs.WrappedPointer, error) {
 func fetchRefs(refs []string) bool {
 	pointers, err := pointersToFetchForRefs(refs)
 	if err != nil {
-		Panic(err, "Could not scan for Git LFS files")
+		PanicMsg(err, "common.could-not-scan")
 	}
 	return fetchAndReportToChan(pointers, nil, nil)
 }
@@ -203,7 +275,7 @@ func fetchPreviousVersions(ref string, since time.Time, filter *filepathfilter.F
 
 	tempgitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			Panic(err, "Could not scan for Git LFS previous versions")
+			PanicMsg(err, "fetch.could-not-scan-previous")
 			return
 		}
 
@@ -234,11 +306,11 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 	}
 	// First find any other recent refs
 	if fetchconf.FetchRecentRefsDays > 0 {
-		Print("fetch: Fetching recent branches within %v days", fetchconf.FetchRecentRefsDays)
+		InfoMsg("fetch.fetching-recent-branches", tr.Args{"Days": fetchconf.FetchRecentRefsDays})
 		refsSince := time.Now().AddDate(0, 0, -fetchconf.FetchRecentRefsDays)
 		refs, err := git.RecentBranches(refsSince, fetchconf.FetchRecentRefsIncludeRemotes, cfg.Remote())
 		if err != nil {
-			Panic(err, "Could not scan for recent refs")
+			PanicMsg(err, "fetch.could-not-scan-recent")
 		}
 		for _, ref := range refs {
 			// Don't fetch for the same SHA twice
@@ -248,7 +320,7 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 				}
 			} else {
 				uniqueRefShas[ref.Sha] = ref.Name
-				Print("fetch: Fetching reference %s", ref.Name)
+				InfoMsg("fetch.fetching-ref", tr.Args{"Ref": ref.Name})
 				k := fetchRef(ref.Sha, filter)
 				ok = ok && k
 			}
@@ -260,10 +332,10 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 			// We measure from the last commit at the ref
 			summ, err := git.GetCommitSummary(commit)
 			if err != nil {
-				Error("Couldn't scan commits at %v: %v", refName, err)
+				ErrorMsg("fetch.could-not-scan-commits", tr.Args{"Ref": refName, "Err": err})
 				continue
 			}
-			Print("fetch: Fetching changes within %v days of %v", fetchconf.FetchRecentCommitsDays, refName)
+			InfoMsg("fetch.fetching-recent-changes", tr.Args{"Days": fetchconf.FetchRecentCommitsDays, "Ref": refName})
 			commitsSince := summ.CommitDate.AddDate(0, 0, -fetchconf.FetchRecentCommitsDays)
 			k := fetchPreviousVersions(commit, commitsSince, filter)
 			ok = ok && k
@@ -275,7 +347,7 @@ func fetchRecent(fetchconf lfs.FetchPruneConfig, alreadyFetchedRefs []*git.Ref,
 
 func fetchAll() bool {
 	pointers := scanAll()
-	Print("fetch: Fetching all references...")
+	InfoMsg("fetch.fetching-all")
 	return fetchAndReportToChan(pointers, nil, nil)
 }
 
@@ -284,9 +356,7 @@ func scanAll() []*lfs.WrappedPointer {
 	task := tasklog.NewSimpleTask()
 	defer task.Complete()
 
-	logger := tasklog.NewLogger(OutputWriter,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	logger := newLogger(OutputWriter)
 	logger.Enqueue(task)
 	var numObjs int64
 
@@ -309,13 +379,13 @@ func scanAll() []*lfs.WrappedPointer {
 	})
 
 	if err := tempgitscanner.ScanAll(nil); err != nil {
-		Panic(err, "Could not scan for Git LFS files")
+		PanicMsg(err, "common.could-not-scan")
 	}
 
 	tempgitscanner.Close()
 
 	if multiErr != nil {
-		Panic(multiErr, "Could not scan for Git LFS files")
+		PanicMsg(multiErr, "common.could-not-scan")
 	}
 
 	return pointers
@@ -325,10 +395,27 @@ func scanAll() []*lfs.WrappedPointer {
 // Returns true if all completed with no errors, false if errors were written to stderr/log
 func fetchAndReportToChan(allpointers []*lfs.WrappedPointer, filter *filepathfilter.Filter, out chan<- *lfs.WrappedPointer) bool {
 	ready, pointers, meter := readyAndMissingPointers(allpointers, filter)
+	if fetchCheckpointFile != nil {
+		pointers = fetchCheckpointFile.skip(pointers)
+	}
+	if fetchLimitBytes != nil {
+		pointers = fetchLimitBytes.take(pointers)
+	}
+	for _, p := range pointers {
+		meter.Add(p.Size)
+	}
+	if err := checkFreeSpace(pointers, fetchForceArg); err != nil {
+		Exit("%s", err)
+	}
+
 	q := newDownloadQueue(
 		getTransferManifestOperationRemote("download", cfg.Remote()),
 		cfg.Remote(), tq.WithProgress(meter),
+		tq.WithVerifyDownloads(fetchVerifyArg),
 	)
+	if fetchCheckpointFile != nil {
+		fetchCheckpointFile.watch(q)
+	}
 
 	if out != nil {
 		// If we already have it, or it won't be fetched
@@ -370,6 +457,9 @@ func fetchAndReportToChan(allpointers []*lfs.WrappedPointer, filter *filepathfil
 	processQueue := time.Now()
 	q.Wait()
 	tracerx.PerformanceSince("process queue", processQueue)
+	if fetchCheckpointFile != nil {
+		fetchCheckpointFile.wait()
+	}
 
 	ok := true
 	for _, err := range q.Errors() {
@@ -380,9 +470,7 @@ func fetchAndReportToChan(allpointers []*lfs.WrappedPointer, filter *filepathfil
 }
 
 func readyAndMissingPointers(allpointers []*lfs.WrappedPointer, filter *filepathfilter.Filter) ([]*lfs.WrappedPointer, []*lfs.WrappedPointer, *tq.Meter) {
-	logger := tasklog.NewLogger(os.Stdout,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	logger := newLogger(os.Stdout)
 	meter := buildProgressMeter(false, tq.Download)
 	logger.Enqueue(meter)
 
@@ -401,12 +489,13 @@ func readyAndMissingPointers(allpointers []*lfs.WrappedPointer, filter *filepath
 		// no need to download objects that exist locally already
 		lfs.LinkOrCopyFromReference(cfg, p.Oid, p.Size)
 		if cfg.LFSObjectExists(p.Oid, p.Size) {
+			cfg.Filesystem().RecordCacheHit(p.Size)
 			ready = append(ready, p)
 			continue
 		}
 
+		cfg.Filesystem().RecordCacheMiss()
 		missing = append(missing, p)
-		meter.Add(p.Size)
 	}
 
 	return ready, missing, meter
@@ -419,5 +508,10 @@ func init() {
 		cmd.Flags().BoolVarP(&fetchRecentArg, "recent", "r", false, "Fetch recent refs & commits")
 		cmd.Flags().BoolVarP(&fetchAllArg, "all", "a", false, "Fetch all LFS files ever referenced")
 		cmd.Flags().BoolVarP(&fetchPruneArg, "prune", "p", false, "After fetching, prune old data")
+		cmd.Flags().BoolVar(&fetchVerifyArg, "verify", false, "Hash each downloaded object again before storing it")
+		cmd.Flags().BoolVar(&fetchForceArg, "force", false, "Download even if it would leave less than lfs.minfreespace free")
+		cmd.Flags().StringVar(&fetchLimitBytesArg, "limit-bytes", "", "Stop before downloading more than this many bytes")
+		cmd.Flags().StringVar(&fetchCheckpointArg, "checkpoint", "", "Record fetched objects in this file, and skip those already recorded (\"auto\" to name it after the remote and refs)")
+		cmd.Flags().BoolVarP(&fetchNoSmudgeArg, "no-smudge", "", false, "Only download objects to the local cache, without updating the working tree (the default)")
 	})
 }
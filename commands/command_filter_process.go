@@ -9,10 +9,10 @@ import (
 	"sync"
 
 	"github.com/git-lfs/git-lfs/errors"
-	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -34,11 +34,25 @@ const (
 // in the working tree.
 var filterSmudgeSkip bool
 
+// filterDebugProtocol is a command-line flag owned by the `filter-process`
+// command dictating whether or not to log each packet exchanged with Git, to
+// lfs.filterprotocoldebugfile or standard error.
+var filterDebugProtocol bool
+
 func filterCommand(cmd *cobra.Command, args []string) {
 	requireStdin("This command should be run by the Git filter process")
-	installHooks(false)
+	upgradeHooks()
+	requireTempDir()
+
+	var stdin io.Reader = os.Stdin
+	var stdout io.Writer = os.Stdout
+	if filterDebugProtocol {
+		logger := git.NewPktlineLogger(filterProtocolDebugOutput())
+		stdin = logger.Reader(stdin)
+		stdout = logger.Writer(stdout)
+	}
 
-	s := git.NewFilterProcessScanner(os.Stdin, os.Stdout)
+	s := git.NewFilterProcessScanner(stdin, stdout)
 
 	if err := s.Init(); err != nil {
 		ExitWithError(err)
@@ -58,7 +72,7 @@ func filterCommand(cmd *cobra.Command, args []string) {
 	}
 
 	skip := filterSmudgeSkip || cfg.Os.Bool("GIT_LFS_SKIP_SMUDGE", false)
-	filter := filepathfilter.New(cfg.FetchIncludePaths(), cfg.FetchExcludePaths())
+	filter := buildFilepathFilter(cfg, nil, nil, true)
 
 	ptrs := make(map[string]*lfs.Pointer)
 
@@ -67,7 +81,7 @@ func filterCommand(cmd *cobra.Command, args []string) {
 	var malformedOnWindows []string
 	var closeOnce *sync.Once
 	var available chan *tq.Transfer
-	gitfilter := lfs.NewGitFilter(cfg)
+	gitfilter := newCleanGitFilter()
 	for s.Scan() {
 		var n int64
 		var err error
@@ -79,10 +93,10 @@ func filterCommand(cmd *cobra.Command, args []string) {
 		switch req.Header["command"] {
 		case "clean":
 			s.WriteStatus(statusFromErr(nil))
-			w = git.NewPktlineWriter(os.Stdout, cleanFilterBufferCapacity)
+			w = git.NewPktlineWriter(stdout, cleanFilterBufferCapacity)
 
 			var ptr *lfs.Pointer
-			ptr, err = clean(gitfilter, w, req.Payload, req.Header["pathname"], -1)
+			ptr, err = cleanFilter(gitfilter, w, req.Payload, req.Header["pathname"])
 
 			if ptr != nil {
 				n = ptr.Size
@@ -101,7 +115,7 @@ func filterCommand(cmd *cobra.Command, args []string) {
 				go infiniteTransferBuffer(q, available)
 			}
 
-			w = git.NewPktlineWriter(os.Stdout, smudgeFilterBufferCapacity)
+			w = git.NewPktlineWriter(stdout, smudgeFilterBufferCapacity)
 			if req.Header["can-delay"] == "1" {
 				var ptr *lfs.Pointer
 
@@ -202,21 +216,23 @@ func filterCommand(cmd *cobra.Command, args []string) {
 		s.WriteStatus(status)
 	}
 
+	reportQuarantined(gitfilter)
+
 	if len(malformed) > 0 {
-		fmt.Fprintf(os.Stderr, "Encountered %d file(s) that should have been pointers, but weren't:\n", len(malformed))
+		fmt.Fprintln(os.Stderr, tr.Tr("filter-process.not-pointers", tr.Args{"Count": len(malformed)}))
 		for _, m := range malformed {
 			fmt.Fprintf(os.Stderr, "\t%s\n", m)
 		}
 	}
 
 	if len(malformedOnWindows) > 0 && cfg.Git.Bool("lfs.largefilewarning", true) {
-		fmt.Fprintf(os.Stderr, "Encountered %d file(s) that may not have been copied correctly on Windows:\n", len(malformedOnWindows))
+		fmt.Fprintln(os.Stderr, tr.Tr("filter-process.malformed-on-windows", tr.Args{"Count": len(malformedOnWindows)}))
 
 		for _, m := range malformedOnWindows {
 			fmt.Fprintf(os.Stderr, "\t%s\n", m)
 		}
 
-		fmt.Fprintf(os.Stderr, "\nSee: `git lfs help smudge` for more details.\n")
+		fmt.Fprintln(os.Stderr, tr.Tr("filter-process.see-help-smudge"))
 	}
 
 	if err := s.Err(); err != nil && err != io.EOF {
@@ -377,5 +393,23 @@ func delayedStatusFromErr(err error) git.FilterProcessStatus {
 func init() {
 	RegisterCommand("filter-process", filterCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&filterSmudgeSkip, "skip", "s", false, "")
+		cmd.Flags().BoolVarP(&filterDebugProtocol, "debug-protocol", "", false, "Log each packet exchanged with Git")
 	})
 }
+
+// filterProtocolDebugOutput returns where `filter-process --debug-protocol`
+// logs packets: the file named by lfs.filterprotocoldebugfile, which is
+// appended to, or standard error.
+func filterProtocolDebugOutput() io.Writer {
+	name, ok := cfg.Git.Get("lfs.filterprotocoldebugfile")
+	if !ok || len(name) == 0 {
+		return os.Stderr
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		ErrorMsg("filter-process.debug-file-failed", tr.Args{"Path": name, "Err": err})
+		return os.Stderr
+	}
+	return f
+}
@@ -6,16 +6,57 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/fs"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
 var (
-	fsckDryRun bool
+	fsckDryRun   bool
+	fsckAll      bool
+	fsckObjects  bool
+	fsckPointers bool
+	fsckFix      bool
+
+	fsckIncremental bool
+	fsckFull        bool
+
+	fsckVerifyRemote bool
+	fsckRemoteName   string
+)
+
+const (
+	// fsckRemoteBatchSize is the number of objects checked by each batch
+	// request made by `git lfs fsck --verify-remote`.
+	fsckRemoteBatchSize = 100
+
+	// fsckLayoutGracePeriod is how old a file in the object store must be
+	// before fsck considers it, so that objects which another process is
+	// still writing are left alone.
+	fsckLayoutGracePeriod = 5 * time.Second
+
+	// fsckEmptyOid is the OID of the empty object, which is the only one
+	// allowed to be zero bytes long.
+	fsckEmptyOid = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var (
+	// fsckOidRE matches the name of a file in the object store.
+	fsckOidRE = regexp.MustCompile(`\A[0-9a-f]{64}\z`)
+
+	// fsckMoved is whether any file in the object store has been moved.
+	fsckMoved bool
 )
 
 // TODO(zeroshirts): 'git fsck' reports status (percentage, current#/total) as
@@ -25,68 +66,709 @@ var (
 // NOTE(zeroshirts): Ideally git would have hooks for fsck such that we could
 // chain a lfs-fsck, but I don't think it does.
 func fsckCommand(cmd *cobra.Command, args []string) {
-	installHooks(false)
+	upgradeHooks()
 	requireInRepo()
 
-	ref, err := git.CurrentRef()
+	if fsckVerifyRemote {
+		if len(args) > 1 {
+			ExitMsg("fsck.verify-remote-usage")
+		}
+		if !fsckCheckRemote(cmd, args) {
+			os.Exit(1)
+		}
+		PrintMsg("fsck.ok")
+		return
+	}
+
+	// Without either flag, run both halves of the check.
+	if !fsckObjects && !fsckPointers {
+		fsckObjects, fsckPointers = true, true
+	}
+
+	var ok bool = true
+	if fsckObjects {
+		ok = fsckCheckObjects() && ok
+	}
+	if fsckPointers {
+		ok = fsckCheckPointers() && ok
+	}
+
+	// The files moved may never have been counted as objects, or may
+	// have been counted wherever they were, so rather than recording
+	// each move, the objects are counted again.
+	if fsckMoved {
+		if err := cfg.Filesystem().RecountObjects(); err != nil {
+			LoggedErrorMsg(err, "fsck.count-failed", tr.Args{"Err": err})
+		}
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+	PrintMsg("fsck.ok")
+}
+
+// fsckCheckObjects verifies the objects referenced by the pointers reachable
+// from HEAD (or all refs, with --all) and the index, as well as every other
+// object in the local store. Referenced objects which are absent are
+// reported, and corrupt objects are moved to .git/lfs/bad. With --fix, the
+// missing and corrupt objects are downloaded again. It returns whether no
+// problems were found, or all of them were fixed.
+func fsckCheckObjects() bool {
+	pointers, err := fsckScanPointers()
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	ok, ignored := fsckCheckLayout()
+	ok = fsckCheckPermissions() && ok
+
+	local := make(map[string]int64)
+	err = cfg.Filesystem().EachObject(func(obj fs.Object) error {
+		if _, ok := ignored[obj.Oid]; !ok {
+			local[obj.Oid] = obj.Size
+		}
+		return nil
+	})
 	if err != nil {
 		ExitWithError(err)
 	}
 
+	verifier := newFsckVerifier(fsckIncremental && !fsckFull)
+
 	var corruptOids []string
-	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
-		if err == nil {
-			var pointerOk bool
-			pointerOk, err = fsckPointer(p.Name, p.Oid)
-			if !pointerOk {
+	var broken []*lfs.WrappedPointer
+	for _, p := range pointers {
+		size, exists := local[p.Oid]
+		if !exists {
+			PrintMsg("fsck.missing", tr.Args{"Name": p.Name, "Oid": p.Oid})
+			broken = append(broken, p)
+			continue
+		}
+		delete(local, p.Oid)
+
+		if size != p.Size {
+			objectOk, err := fsckSize(p, size)
+			if err != nil {
+				PanicMsg(err, "fsck.check-failed")
+			}
+			if objectOk {
+				ok = false
+			} else {
 				corruptOids = append(corruptOids, p.Oid)
+				broken = append(broken, p)
 			}
+			continue
+		}
+
+		if !verifier.Verify(p.Oid) {
+			continue
+		}
+
+		pointerOk, err := fsckPointer(p.Name, p.Oid)
+		if err != nil {
+			PanicMsg(err, "fsck.check-failed")
+		}
+		if pointerOk {
+			verifier.Verified(p.Oid)
+		} else {
+			corruptOids = append(corruptOids, p.Oid)
+			broken = append(broken, p)
+		}
+	}
+
+	// Check the objects which are not referenced by any pointer we
+	// scanned, in a stable order. Since nothing refers to them, moving
+	// them aside is all that --fix can do.
+	unreferenced := make([]string, 0, len(local))
+	for oid := range local {
+		unreferenced = append(unreferenced, oid)
+	}
+	sort.Strings(unreferenced)
+
+	for _, oid := range unreferenced {
+		if !verifier.Verify(oid) {
+			continue
 		}
 
+		objectOk, err := fsckObject(oid)
+		if err != nil {
+			PanicMsg(err, "fsck.check-failed")
+		}
+		if objectOk {
+			verifier.Verified(oid)
+		} else {
+			PrintMsg("fsck.corrupt-oid", tr.Args{"Oid": oid})
+			fsckLinkHint(cfg.Filesystem().ObjectPathname(oid))
+			corruptOids = append(corruptOids, oid)
+			ok = ok && fsckFix
+		}
+	}
+
+	if skipped := verifier.Skipped(); skipped > 0 {
+		PrintMsg("fsck.skipped", tr.Args{"Count": skipped})
+	}
+
+	if fsckDryRun {
+		if fsckFix {
+			for _, p := range broken {
+				PrintMsg("fsck.would-download", tr.Args{"Name": p.Name, "Oid": p.Oid})
+			}
+		}
+		return ok && len(broken) == 0 && len(corruptOids) == 0
+	}
+
+	if err := verifier.Save(); err != nil {
+		LoggedErrorMsg(err, "fsck.save-state-failed", tr.Args{"Err": err})
+	}
+
+	if len(corruptOids) > 0 {
+		badDir := filepath.Join(cfg.LFSStorageDir(), "bad")
+		PrintMsg("fsck.moving-corrupt", tr.Args{"Dir": badDir})
+
+		if err := tools.MkdirAll(badDir, cfg); err != nil {
+			ExitWithError(err)
+		}
+
+		for _, oid := range corruptOids {
+			badFile := filepath.Join(badDir, oid)
+			fsckMove(cfg.Filesystem().ObjectPathname(oid), badFile)
+		}
+	}
+
+	if len(broken) == 0 {
+		return ok
+	}
+	if !fsckFix {
+		return false
+	}
+	return fsckDownload(broken) && ok
+}
+
+// fsckCheckLayout walks the local object store looking for files which are
+// not where an object of their name belongs, which are not named after an OID
+// (such as leaked temporary files), or which are empty. With --fix, misplaced
+// objects whose contents match their name are moved to where they belong,
+// the other files are moved to .git/lfs/bad, and empty directories are
+// removed. Files younger than fsckLayoutGracePeriod are skipped, since they
+// may still be being written.
+//
+// It returns whether no problems were found, or all of them were fixed, along
+// with the names of the files which the rest of the object check should
+// ignore.
+func fsckCheckLayout() (bool, map[string]struct{}) {
+	root := cfg.Filesystem().LFSObjectDir()
+	cutoff := time.Now().Add(-fsckLayoutGracePeriod)
+
+	var misplaced, invalid, dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			Panic(err, "Error checking Git LFS files")
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		young := info.ModTime().After(cutoff)
+		if info.IsDir() {
+			if path != root && !young {
+				dirs = append(dirs, path)
+			}
+			return nil
 		}
+		if young || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		name := info.Name()
+		rel, _ := filepath.Rel(root, path)
+
+		switch {
+		case !fsckOidRE.MatchString(name):
+			PrintMsg("fsck.not-an-object", tr.Args{"Path": rel})
+			invalid = append(invalid, path)
+		case info.Size() == 0 && name != fsckEmptyOid:
+			PrintMsg("fsck.empty", tr.Args{"Oid": name})
+			invalid = append(invalid, path)
+		case path != cfg.Filesystem().ObjectPathname(name):
+			PrintMsg("fsck.misplaced", tr.Args{"Oid": name, "Path": rel})
+			misplaced = append(misplaced, path)
+		}
+		return nil
 	})
+	if err != nil {
+		ExitWithError(err)
+	}
 
-	// If 'lfs.fetchexclude' is set and 'git lfs fsck' is run after the
-	// initial fetch (i.e., has elected to fetch a subset of Git LFS
-	// objects), the "missing" ones will fail the fsck.
-	//
-	// Attach a filepathfilter to avoid _only_ the excluded paths.
-	gitscanner.Filter = filepathfilter.New(nil, cfg.FetchExcludePaths())
+	ignored := make(map[string]struct{})
+	for _, path := range append(invalid, misplaced...) {
+		ignored[filepath.Base(path)] = struct{}{}
+	}
+
+	if len(ignored) > 0 && !fsckFix {
+		return false, ignored
+	}
+	if fsckDryRun {
+		for _, path := range misplaced {
+			PrintMsg("fsck.would-move", tr.Args{"From": path, "To": cfg.Filesystem().ObjectPathname(filepath.Base(path))})
+		}
+		for _, path := range invalid {
+			PrintMsg("fsck.would-move", tr.Args{"From": path, "To": filepath.Join(cfg.LFSStorageDir(), "bad")})
+		}
+		return len(ignored) == 0, ignored
+	}
+
+	for _, path := range misplaced {
+		oid := filepath.Base(path)
+		dest := cfg.Filesystem().ObjectPathname(oid)
+
+		recalculatedOid, err := fsckHashFile(path, -1)
+		if err != nil {
+			ExitWithError(err)
+		}
+
+		// A copy which is corrupt, or which duplicates an object
+		// already in place, is only kept aside.
+		if recalculatedOid != oid || tools.FileExists(dest) {
+			invalid = append(invalid, path)
+			continue
+		}
+
+		if err := tools.MkdirAll(filepath.Dir(dest), cfg); err != nil {
+			ExitWithError(err)
+		}
+		fsckMove(path, dest)
+		PrintMsg("fsck.moved", tr.Args{"From": path, "To": dest})
+		delete(ignored, oid)
+	}
+
+	if len(invalid) > 0 {
+		badDir := filepath.Join(cfg.LFSStorageDir(), "bad")
+		PrintMsg("fsck.moving-invalid", tr.Args{"Dir": badDir})
 
-	if err := gitscanner.ScanRef(ref.Sha, nil); err != nil {
+		if err := tools.MkdirAll(badDir, cfg); err != nil {
+			ExitWithError(err)
+		}
+		for _, path := range invalid {
+			fsckMove(path, fsckQuarantinePath(badDir, filepath.Base(path)))
+		}
+	}
+
+	// Remove the deepest directories first, so that their parents may
+	// become empty too. Removing a directory which is not empty fails,
+	// and is ignored.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Remove(dirs[i]); err == nil {
+			Debug("fsck: removed empty directory %s", dirs[i])
+		}
+	}
+
+	return true, ignored
+}
+
+// fsckCheckPermissions reports the files and directories in the LFS storage
+// directory which lack the permissions that core.sharedRepository requires,
+// such as objects written by another user before it was set, and adds them
+// with --fix. Without core.sharedRepository, permissions are left to each
+// user's umask, so there is nothing to check. It returns whether no problems
+// were found, or all of them were fixed.
+func fsckCheckPermissions() bool {
+	if !cfg.RepositoryShared() {
+		return true
+	}
+
+	root := cfg.LFSStorageDir()
+	var fixed, unfixed int
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+
+		perms, missing := tools.MissingSharedPermissions(info, cfg)
+		if !missing {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		switch {
+		case !fsckFix:
+			PrintMsg("fsck.bad-permissions", tr.Args{
+				"Path":     rel,
+				"Mode":     info.Mode() & (os.ModePerm | os.ModeSetgid),
+				"Required": perms,
+			})
+			unfixed++
+		case fsckDryRun:
+			PrintMsg("fsck.would-fix-permissions", tr.Args{"Path": rel, "Mode": perms})
+			unfixed++
+		default:
+			if err := os.Chmod(path, perms); err != nil {
+				PrintMsg("fsck.fix-permissions-failed", tr.Args{"Path": rel, "Err": err})
+				unfixed++
+			} else {
+				fixed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		ExitWithError(err)
 	}
 
-	if err := gitscanner.ScanIndex("HEAD", nil); err != nil {
+	if fixed > 0 {
+		PrintMsg("fsck.fixed-permissions", tr.Args{"Count": fixed})
+	}
+	return unfixed == 0
+}
+
+// fsckSize reports an object of "size" bytes whose pointer "p" records another
+// size. Only as many bytes as the pointer records are hashed, so that an object
+// with trailing data appended can be told apart from one with other contents.
+// It returns whether the object itself is intact, in which case it is the
+// pointer which is wrong.
+func fsckSize(p *lfs.WrappedPointer, size int64) (bool, error) {
+	path := cfg.Filesystem().ObjectPathname(p.Oid)
+	recalculatedOid, err := fsckHashFile(path, p.Size)
+	if err != nil {
+		return false, err
+	}
+
+	PrintMsg("fsck.size-mismatch", tr.Args{"Name": p.Name, "Oid": p.Oid, "Size": size, "PointerSize": p.Size})
+
+	if recalculatedOid != p.Oid {
+		fsckLinkHint(path)
+		return false, nil
+	}
+	if size > p.Size {
+		PrintMsg("fsck.trailing-data", tr.Args{"PointerSize": p.Size, "Extra": size - p.Size})
+		fsckLinkHint(path)
+		return false, nil
+	}
+
+	// The whole object was hashed, and matches.
+	PrintMsg("fsck.wrong-size")
+	return true, nil
+}
+
+// fsckQuarantinePath returns a path in "badDir" for the file "name" which does
+// not yet exist.
+func fsckQuarantinePath(badDir, name string) string {
+	path := filepath.Join(badDir, name)
+	for i := 1; tools.FileExists(path); i++ {
+		path = filepath.Join(badDir, name+"."+strconv.Itoa(i))
+	}
+	return path
+}
+
+// fsckMove moves the file at "path" in the object store to "dest", either
+// within the store or out of it.
+func fsckMove(path, dest string) {
+	if err := os.Rename(path, dest); err != nil {
 		ExitWithError(err)
 	}
+	fsckMoved = true
+}
+
+// fsckDownload downloads the objects of "pointers" again from the remote,
+// verifying each as it arrives. Objects which cannot be downloaded are listed
+// along with the commits which refer to them. It returns whether all of the
+// objects were downloaded.
+func fsckDownload(pointers []*lfs.WrappedPointer) bool {
+	remote := cfg.Remote()
+	PrintMsg("fsck.downloading", tr.Args{"Count": len(pointers), "Remote": remote})
+
+	manifest := getTransferManifestOperationRemote("download", remote)
+	q := newDownloadQueue(manifest, remote)
+	for _, p := range pointers {
+		q.Add(downloadTransfer(p))
+	}
+	q.Wait()
+
+	for _, err := range q.Errors() {
+		Debug("fsck: %s", err)
+	}
+
+	var unrecoverable []*lfs.WrappedPointer
+	for _, p := range pointers {
+		if meta, err := lfs.StatObject(cfg, p.Oid); err != nil || !meta.HasSize(p.Size) {
+			unrecoverable = append(unrecoverable, p)
+		}
+	}
+
+	if fixed := len(pointers) - len(unrecoverable); fixed > 0 {
+		PrintMsg("fsck.downloaded", tr.Args{"Count": fixed})
+	}
+	if len(unrecoverable) == 0 {
+		return true
+	}
+
+	PrintMsg("fsck.unrecoverable", tr.Args{"Remote": remote})
+	for _, p := range unrecoverable {
+		commits, err := fsckReferencingCommits(p.Oid, fsckRevs())
+		if err != nil {
+			ExitWithError(err)
+		}
+
+		Print("\t%s (%s)", p.Name, p.Oid)
+		if len(commits) > 0 {
+			PrintMsg("fsck.referenced-by", tr.Args{"Commits": strings.Join(commits, ", ")})
+		}
+	}
+	return false
+}
 
+// fsckRevs returns the revisions which fsck checks: HEAD, or all refs with
+// --all.
+func fsckRevs() []string {
+	if fsckAll {
+		return []string{"--all"}
+	}
+	return []string{"HEAD"}
+}
+
+// fsckReferencingCommits returns the abbreviated SHAs of the commits reachable
+// from "revs" which add or remove a pointer to "oid".
+func fsckReferencingCommits(oid string, revs []string) ([]string, error) {
+	args := append([]string{"--format=%h", "-S" + "sha256:" + oid}, revs...)
+
+	cmd, err := git.Log(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []string
+	for {
+		line, err := cmd.Stdout.ReadString('\n')
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			commits = append(commits, line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	return commits, cmd.Wait()
+}
+
+// fsckCheckRemote checks that the remote has the objects of all the pointers
+// reachable from the ref given in "args", or HEAD. Missing objects are listed
+// with their paths and the commits which introduced them. It returns whether
+// no objects are missing.
+func fsckCheckRemote(cmd *cobra.Command, args []string) bool {
+	if len(fsckRemoteName) > 0 {
+		if err := cfg.SetValidRemote(fsckRemoteName); err != nil {
+			ExitMsg("common.invalid-remote", tr.Args{"Remote": fsckRemoteName, "Err": err})
+		}
+	}
+	remote := cfg.Remote()
+
+	refName := "HEAD"
+	if len(args) > 0 {
+		refName = args[0]
+	}
+	ref, err := git.ResolveRef(refName)
+	if err != nil {
+		ExitMsg("fetch.invalid-ref", tr.Args{"Refs": refName})
+	}
+
+	include, exclude := getIncludeExcludeArgs(cmd)
+
+	var pointers []*lfs.WrappedPointer
+	seen := make(map[string]struct{})
+	var scanErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+		if _, ok := seen[p.Oid]; ok {
+			return
+		}
+		seen[p.Oid] = struct{}{}
+		pointers = append(pointers, p)
+	})
+	gitscanner.Filter = buildFilepathFilter(cfg, include, exclude, false)
+
+	err = gitscanner.ScanRef(ref.Sha, nil)
 	gitscanner.Close()
+	if err == nil {
+		err = scanErr
+	}
+	if err != nil {
+		ExitWithError(err)
+	}
 
-	if len(corruptOids) == 0 {
-		Print("Git LFS fsck OK")
-		return
+	PrintMsg("fsck.checking-remote", tr.Args{"Count": len(pointers), "Remote": remote})
+
+	manifest := getTransferManifestOperationRemote("download", remote)
+	remoteRef := git.NewRefUpdate(cfg.Git, remote, ref, nil).Right()
+
+	var missing []*lfs.WrappedPointer
+	for start := 0; start < len(pointers); start += fsckRemoteBatchSize {
+		end := start + fsckRemoteBatchSize
+		if end > len(pointers) {
+			end = len(pointers)
+		}
+
+		byOid := make(map[string]*lfs.WrappedPointer, end-start)
+		transfers := make([]*tq.Transfer, 0, end-start)
+		for _, p := range pointers[start:end] {
+			byOid[p.Oid] = p
+			transfers = append(transfers, &tq.Transfer{Oid: p.Oid, Size: p.Size})
+		}
+
+		res, err := tq.Batch(manifest, tq.Download, remote, remoteRef, transfers)
+		if err != nil {
+			ExitWithError(errors.Wrap(err, "could not check objects on remote"))
+		}
+
+		for _, t := range res.Objects {
+			p, ok := byOid[t.Oid]
+			if !ok {
+				continue
+			}
+			delete(byOid, t.Oid)
+
+			if t.Error == nil {
+				continue
+			}
+			if t.Error.Code != 404 {
+				ExitWithError(errors.Errorf("could not check %s (%s): %s", p.Name, p.Oid, t.Error))
+			}
+			missing = append(missing, p)
+		}
+
+		// Objects omitted from the response cannot be downloaded
+		// either.
+		for _, p := range pointers[start:end] {
+			if _, ok := byOid[p.Oid]; ok {
+				missing = append(missing, p)
+			}
+		}
 	}
 
-	if fsckDryRun {
-		return
+	if len(missing) == 0 {
+		return true
+	}
+
+	PrintMsg("fsck.missing-from-remote", tr.Args{"Remote": remote})
+	for _, p := range missing {
+		commits, err := fsckReferencingCommits(p.Oid, []string{ref.Sha})
+		if err != nil {
+			ExitWithError(err)
+		}
+
+		Print("\t%s (%s)", p.Name, p.Oid)
+		if len(commits) > 0 {
+			PrintMsg("fsck.introduced-by", tr.Args{"Commit": commits[len(commits)-1]})
+		}
+	}
+	return false
+}
+
+// fsckScanPointers returns the pointers reachable from HEAD (or all refs, with
+// --all) and the index, with one pointer for each distinct object.
+func fsckScanPointers() ([]*lfs.WrappedPointer, error) {
+	var pointers []*lfs.WrappedPointer
+	seen := make(map[string]struct{})
+
+	var scanErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+
+		if _, ok := seen[p.Oid]; ok {
+			return
+		}
+		seen[p.Oid] = struct{}{}
+		pointers = append(pointers, p)
+	})
+	defer gitscanner.Close()
+
+	// If 'lfs.fetchinclude' or 'lfs.fetchexclude' are set and 'git lfs
+	// fsck' is run after the initial fetch (i.e., has elected to fetch a
+	// subset of Git LFS objects), the "missing" ones will fail the fsck.
+	//
+	// Attach a filepathfilter to avoid _only_ the excluded paths, and those
+	// with lfs-fetch=false.
+	gitscanner.Filter = buildFilepathFilter(cfg, nil, nil, true)
+
+	if fsckAll {
+		if err := gitscanner.ScanAll(nil); err != nil {
+			return nil, err
+		}
+	} else {
+		ref, err := git.CurrentRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := gitscanner.ScanRef(ref.Sha, nil); err != nil {
+			return nil, err
+		}
 	}
 
-	badDir := filepath.Join(cfg.LFSStorageDir(), "bad")
-	Print("Moving corrupt objects to %s", badDir)
+	if err := gitscanner.ScanIndex("HEAD", nil); err != nil {
+		return nil, err
+	}
+	return pointers, scanErr
+}
+
+// fsckCheckPointers reports files in the index which match a pattern tracked
+// by Git LFS in .gitattributes, but which were committed to Git directly
+// rather than as a pointer. It returns whether no such files were found.
+func fsckCheckPointers() bool {
+	filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
+	if len(filter.Include()) == 0 {
+		return true
+	}
 
-	if err := tools.MkdirAll(badDir, cfg); err != nil {
+	// Compare the index against the empty tree, so that every file in the
+	// index is listed as an addition.
+	scanner, err := lfs.NewDiffIndexScanner(git.RefBeforeFirstCommit, true, false)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	pointers, err := lfs.NewPointerScanner(cfg.OSEnv())
+	if err != nil {
 		ExitWithError(err)
 	}
+	defer pointers.Close()
 
-	for _, oid := range corruptOids {
-		badFile := filepath.Join(badDir, oid)
-		if err := os.Rename(cfg.Filesystem().ObjectPathname(oid), badFile); err != nil {
+	ok := true
+	for scanner.Scan() {
+		entry := scanner.Entry()
+
+		// Only regular files are run through the clean filter.
+		if !strings.HasPrefix(entry.DstMode, "100") || !filter.Allows(entry.SrcName) {
+			continue
+		}
+
+		pointers.Scan(entry.DstSha)
+		if err := pointers.Err(); err != nil {
 			ExitWithError(err)
 		}
+
+		// Empty files are never converted to pointers.
+		if pointers.Pointer() != nil || pointers.BlobSize() == 0 {
+			continue
+		}
+
+		PrintMsg("fsck.not-a-pointer", tr.Args{"Path": entry.SrcName})
+		ok = false
+	}
+
+	if err := scanner.Err(); err != nil {
+		ExitWithError(err)
 	}
+	return ok
 }
 
 func fsckPointer(name, oid string) (bool, error) {
@@ -96,7 +778,7 @@ func fsckPointer(name, oid string) (bool, error) {
 
 	f, err := os.Open(path)
 	if pErr, pOk := err.(*os.PathError); pOk {
-		Print("Object %s (%s) could not be checked: %s", name, oid, pErr.Err)
+		PrintMsg("fsck.check-object-failed", tr.Args{"Name": name, "Oid": oid, "Err": pErr.Err})
 		return false, nil
 	}
 
@@ -116,12 +798,66 @@ func fsckPointer(name, oid string) (bool, error) {
 		return true, nil
 	}
 
-	Print("Object %s (%s) is corrupt", name, oid)
+	PrintMsg("fsck.corrupt", tr.Args{"Name": name, "Oid": oid})
+	fsckLinkHint(path)
 	return false, nil
 }
 
+// fsckLinkHint explains how the damaged object at "path" may have been
+// modified, if it has hard links such as those made by lfs.link=hardlink.
+func fsckLinkHint(path string) {
+	if n, err := tools.HardLinkCount(path); err == nil && n > 1 {
+		PrintMsg("fsck.hard-links", tr.Args{"Count": n})
+	}
+}
+
+// fsckObject returns whether the contents of the local object "oid" hash to
+// its OID.
+func fsckObject(oid string) (bool, error) {
+	path := cfg.Filesystem().ObjectPathname(oid)
+
+	Debug("Examining %v", path)
+
+	recalculatedOid, err := fsckHashFile(path, -1)
+	if err != nil {
+		return false, err
+	}
+	return recalculatedOid == oid, nil
+}
+
+// fsckHashFile returns the OID of the first "limit" bytes of the file at
+// "path", or of all of it if "limit" is negative.
+func fsckHashFile(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+
+	oidHash := sha256.New()
+	if _, err := io.Copy(oidHash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(oidHash.Sum(nil)), nil
+}
+
 func init() {
 	RegisterCommand("fsck", fsckCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&fsckDryRun, "dry-run", "d", false, "List corrupt objects without deleting them.")
+		cmd.Flags().BoolVarP(&fsckAll, "all", "a", false, "Check the objects referenced by all refs, not just HEAD.")
+		cmd.Flags().BoolVarP(&fsckObjects, "objects", "", false, "Check only the local objects.")
+		cmd.Flags().BoolVarP(&fsckPointers, "pointers", "", false, "Check only that tracked files are pointers.")
+		cmd.Flags().BoolVarP(&fsckFix, "fix", "", false, "Download missing and corrupt objects again from the remote.")
+		cmd.Flags().BoolVarP(&fsckIncremental, "incremental", "", false, "Only hash objects which changed since they were last verified.")
+		cmd.Flags().BoolVarP(&fsckFull, "full", "", false, "Hash every object, even with --incremental.")
+		cmd.Flags().BoolVarP(&fsckVerifyRemote, "verify-remote", "", false, "Check that the remote has every object reachable from a ref.")
+		cmd.Flags().StringVarP(&fsckRemoteName, "remote", "r", "", "The remote to check with --verify-remote.")
+		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths with --verify-remote")
+		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths with --verify-remote")
 	})
 }
@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importObjectsOidRE  = regexp.MustCompile(`\A[0-9a-f]{64}\z`)
+	importObjectsDryRun bool
+)
+
+// importObjectsCommand unpacks an archive written by `git lfs export-objects`,
+// or a directory of files named by their OIDs, into the local object store,
+// verifying each object as it is read.
+func importObjectsCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	if len(args) != 1 {
+		ExitMsg("import-objects.usage")
+	}
+	name := args[0]
+
+	var stats *importObjectsStats
+	var err error
+	if fi, serr := os.Stat(name); serr == nil && fi.IsDir() {
+		stats, err = readObjectsDirectory(name)
+	} else {
+		var r io.Reader = os.Stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				ExitMsg("common.open-failed", tr.Args{"Path": name, "Err": err})
+			}
+			defer f.Close()
+			r = f
+		}
+		stats, err = readObjectsArchive(bufio.NewReader(r))
+	}
+	if err != nil {
+		ExitMsg("common.read-failed", tr.Args{"Path": name, "Err": err})
+	}
+
+	summary := tr.Args{
+		"Count":   stats.imported,
+		"Size":    humanize.FormatBytes(uint64(stats.size)),
+		"Present": stats.skipped,
+		"Invalid": stats.failed,
+	}
+	if importObjectsDryRun {
+		PrintMsg("import-objects.would-import", summary)
+	} else {
+		PrintMsg("import-objects.imported", summary)
+	}
+	if stats.failed > 0 {
+		ExitMsg("import-objects.failed-count", tr.Args{"Count": stats.failed})
+	}
+}
+
+type importObjectsStats struct {
+	imported int
+	skipped  int
+	failed   int
+	size     int64
+}
+
+// readObjectsDirectory walks "dir" for files named by the OID of their
+// contents, in any layout, and stores each one which is valid and not already
+// present. Files named "<oid>.gz" are decompressed, and symbolic links are
+// followed, so that the output of `git lfs export-objects --output` can be
+// imported. The files themselves are copied, not moved.
+func readObjectsDirectory(dir string) (*importObjectsStats, error) {
+	stats := &importObjectsStats{}
+
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || file == filepath.Join(dir, objectsDirManifestName) {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(file); err == nil {
+				info = target
+			}
+		}
+
+		oid := strings.TrimSuffix(info.Name(), ".gz")
+		compressed := oid != info.Name()
+		if !info.Mode().IsRegular() || !importObjectsOidRE.MatchString(oid) {
+			ErrorMsg("import-objects.not-named-by-oid", tr.Args{"Path": file})
+			stats.failed++
+			return nil
+		}
+
+		// The size of a compressed object is only known once it has
+		// been read.
+		size := info.Size()
+		if compressed {
+			size = -1
+			if tools.FileExists(cfg.Filesystem().ObjectPathname(oid)) {
+				stats.skipped++
+				return nil
+			}
+		} else if cfg.LFSObjectExists(oid, size) {
+			stats.skipped++
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if compressed {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				ErrorMsg("import-objects.invalid", tr.Args{"Path": file, "Err": err})
+				stats.failed++
+				return nil
+			}
+			defer gz.Close()
+			r = gz
+		}
+
+		written, err := importArchiveObject(r, oid, size)
+		if err != nil {
+			ErrorMsg("import-objects.invalid", tr.Args{"Path": file, "Err": err})
+			stats.failed++
+			return nil
+		}
+		stats.imported++
+		stats.size += written
+		return nil
+	})
+	return stats, err
+}
+
+// readObjectsArchive reads the entries of an objects archive from "r" one at a
+// time, storing each object which is valid and not already present. Objects
+// which fail verification are reported and counted, but do not stop the
+// import; an error is only returned if the archive itself is unreadable.
+func readObjectsArchive(r io.Reader) (*importObjectsStats, error) {
+	stats := &importObjectsStats{}
+	manifest := make(map[string]int64)
+	seen := make(map[string]bool)
+
+	archive := tar.NewReader(r)
+	for {
+		hdr, err := archive.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return stats, err
+		}
+
+		if hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		if hdr.Name == objectsManifestName {
+			if err := readObjectsManifest(archive, manifest); err != nil {
+				return stats, errors.Wrap(err, "invalid manifest")
+			}
+			continue
+		}
+
+		oid := path.Base(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !importObjectsOidRE.MatchString(oid) || hdr.Name != objectsArchivePath(oid) {
+			ErrorMsg("import-objects.unexpected-entry", tr.Args{"Name": hdr.Name})
+			continue
+		}
+		seen[oid] = true
+
+		if size, ok := manifest[oid]; ok && size != hdr.Size {
+			ErrorMsg("import-objects.size-mismatch", tr.Args{"Oid": oid, "Size": hdr.Size, "Expected": size})
+			stats.failed++
+			continue
+		}
+
+		if cfg.LFSObjectExists(oid, hdr.Size) {
+			stats.skipped++
+			continue
+		}
+
+		if _, err := importArchiveObject(archive, oid, hdr.Size); err != nil {
+			ErrorMsg("import-objects.failed", tr.Args{"Oid": oid, "Err": err})
+			stats.failed++
+			continue
+		}
+		stats.imported++
+		stats.size += hdr.Size
+	}
+
+	var missing []string
+	for oid := range manifest {
+		if !seen[oid] {
+			missing = append(missing, oid)
+		}
+	}
+	sort.Strings(missing)
+	for _, oid := range missing {
+		ErrorMsg("import-objects.missing-from-archive", tr.Args{"Oid": oid})
+		stats.failed++
+	}
+
+	return stats, nil
+}
+
+// readObjectsManifest parses "<oid> <size>" lines from "r" into "manifest".
+func readObjectsManifest(r io.Reader, manifest map[string]int64) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		fields := bytes.Fields(line)
+		if len(fields) != 2 || !importObjectsOidRE.Match(fields[0]) {
+			return fmt.Errorf("malformed line %q", line)
+		}
+		size, err := strconv.ParseInt(string(fields[1]), 10, 64)
+		if err != nil || size < 0 {
+			return fmt.Errorf("malformed size in line %q", line)
+		}
+		manifest[string(fields[0])] = size
+	}
+	return scanner.Err()
+}
+
+// importArchiveObject streams the contents of "r" into a temporary file, and
+// moves it into the local object store only if it hashes to "oid" and, unless
+// "size" is negative, is "size" bytes long. It returns the size of the object.
+// With --dry-run, the contents are only verified.
+func importArchiveObject(r io.Reader, oid string, size int64) (int64, error) {
+	hr := tools.NewHashingReader(r)
+	if importObjectsDryRun {
+		written, err := io.Copy(ioutil.Discard, hr)
+		if err != nil {
+			return 0, err
+		}
+		return written, verifyImportedObject(hr, oid, size, written)
+	}
+
+	tmp, err := lfs.TempFile(cfg, "import-objects")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, hr)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := verifyImportedObject(hr, oid, size, written); err != nil {
+		return 0, err
+	}
+
+	objPath, err := cfg.Filesystem().ObjectPath(oid)
+	if err != nil {
+		return 0, err
+	}
+	_, statErr := os.Stat(objPath)
+	if err := tools.RenameAcrossDevices(tmp.Name(), objPath); err != nil {
+		return written, err
+	}
+	if os.IsNotExist(statErr) {
+		cfg.Filesystem().RecordObjectAdded(written)
+	}
+	return written, nil
+}
+
+func verifyImportedObject(hr *tools.HashingReader, oid string, size, written int64) error {
+	if size >= 0 && written != size {
+		return fmt.Errorf("expected %d bytes, got %d", size, written)
+	}
+	if actual := hr.Hash(); actual != oid {
+		return fmt.Errorf("contents hash to %s", actual)
+	}
+	return nil
+}
+
+func init() {
+	RegisterCommand("import-objects", importObjectsCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&importObjectsDryRun, "dry-run", "d", false, "Verify objects without importing them")
+	})
+}
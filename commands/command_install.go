@@ -2,34 +2,93 @@ package commands
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/subprocess"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
 var (
 	forceInstall      = false
 	localInstall      = false
+	globalInstall     = false
 	worktreeInstall   = false
+	worktreeConfig    = false
 	manualInstall     = false
 	systemInstall     = false
 	skipSmudgeInstall = false
 	skipRepoInstall   = false
+	sizeGuardInstall  = false
+	checkInstall      = false
 )
 
 func installCommand(cmd *cobra.Command, args []string) {
-	if err := cmdInstallOptions().Install(); err != nil {
-		Print("WARNING: %s", err.Error())
-		Print("Run `git lfs install --force` to reset git config.")
+	if checkInstall {
+		installCheckCommand()
+		return
+	}
+
+	opts := cmdInstallOptions()
+	if worktreeInstall {
+		if err := checkWorktreeConfig(); err != nil {
+			ExitMsg("common.error", tr.Args{"Err": err})
+		}
+	}
+	opts.BinaryPath = installBinaryPath()
+	if err := opts.Install(); err != nil {
+		PrintMsg("common.warning", tr.Args{"Err": err.Error()})
+		PrintMsg("install.use-force")
 		os.Exit(2)
 	}
 
+	if sizeGuardInstall {
+		requireInRepo()
+		if _, err := cfg.SetGitLocalKey("lfs.precommitsizecheck", "true"); err != nil {
+			ExitWithError(err)
+		}
+	}
+
 	if !skipRepoInstall && (localInstall || worktreeInstall || cfg.InRepo()) {
 		installHooksCommand(cmd, args)
 	}
 
-	Print("Git LFS initialized.")
+	checkGitCanRunLFS()
+
+	InfoMsg("install.initialized")
+}
+
+// checkGitCanRunLFS warns if Git cannot run "git-lfs" the way it runs the
+// filters and hooks just installed: through the shell, with the PATH Git
+// gives it. This catches a git-lfs binary which was run by its full path from
+// a directory which is not on PATH.
+func checkGitCanRunLFS() {
+	cmd := subprocess.ExecCommand("git", "-c", "alias.lfs-install-check=!git-lfs version", "lfs-install-check")
+	out, err := subprocess.Output(cmd)
+	if err == nil && strings.HasPrefix(out, "git-lfs/") {
+		return
+	}
+
+	PrintMsg("install.not-on-path")
+	if exe, err := os.Executable(); err == nil {
+		PrintMsg("install.add-to-path", tr.Args{"Dir": filepath.Dir(exe)})
+	}
+	PrintMsg("install.macos-path")
+}
+
+// installBinaryPath returns the path of the running git-lfs program, to be
+// recorded in lfs.binarypath, or an empty string if it cannot be found. It
+// uses forward slashes, which Git for Windows' sh understands.
+func installBinaryPath() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(exe)
 }
 
 func cmdInstallOptions() *lfs.FilterOptions {
@@ -41,18 +100,20 @@ func cmdInstallOptions() *lfs.FilterOptions {
 
 	switch {
 	case localInstall && worktreeInstall:
-		Exit("Only one of --local and --worktree options can be specified.")
+		ExitMsg("install.local-with-worktree")
 	case localInstall && systemInstall:
-		Exit("Only one of --local and --system options can be specified.")
+		ExitMsg("install.local-with-system")
 	case worktreeInstall && systemInstall:
-		Exit("Only one of --worktree and --system options can be specified.")
+		ExitMsg("install.worktree-with-system")
+	case globalInstall && (localInstall || worktreeInstall || systemInstall):
+		ExitMsg("install.global-with-others")
 	}
 
 	// This call will return -1 on Windows; don't warn about this there,
 	// since we can't detect it correctly.
 	uid := os.Geteuid()
 	if systemInstall && uid != 0 && uid != -1 {
-		Print("WARNING: current user is not root/admin, system install is likely to fail.")
+		PrintMsg("install.not-root")
 	}
 
 	return &lfs.FilterOptions{
@@ -65,6 +126,41 @@ func cmdInstallOptions() *lfs.FilterOptions {
 	}
 }
 
+// checkWorktreeConfig returns an error unless the worktree scope can be written
+// to: Git must support the worktreeConfig extension, and if the repository has
+// multiple working trees, the extension must be enabled, which is only done
+// here with --enable-worktree-config.
+func checkWorktreeConfig() error {
+	if !git.IsGitVersionAtLeast("2.20.0") {
+		return errors.New("--worktree requires Git 2.20.0 or later, which supports the worktreeConfig extension.")
+	}
+
+	gitConfig := cfg.GitConfig()
+	if gitConfig.WorktreeConfigEnabled() {
+		return nil
+	}
+
+	// With a single working tree, worktree scope is the same as local
+	// scope.
+	worktrees, err := git.GetAllWorkTreeHEADs(cfg.LocalGitStorageDir())
+	if err != nil || len(worktrees) < 2 {
+		return nil
+	}
+
+	if !worktreeConfig {
+		return errors.New("--worktree requires the worktreeConfig extension, since this repository has multiple working trees.\n" +
+			"Run the command again with --enable-worktree-config to enable it, or run:\n\n" +
+			"  git config core.repositoryformatversion 1\n" +
+			"  git config extensions.worktreeConfig true")
+	}
+
+	if err := gitConfig.EnableWorktreeConfig(); err != nil {
+		return err
+	}
+	InfoMsg("install.worktree-config-enabled")
+	return nil
+}
+
 func installHooksCommand(cmd *cobra.Command, args []string) {
 	updateForce = forceInstall
 
@@ -84,13 +180,14 @@ func init() {
 	RegisterCommand("install", installCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&forceInstall, "force", "f", false, "Set the Git LFS global config, overwriting previous values.")
 		cmd.Flags().BoolVarP(&localInstall, "local", "l", false, "Set the Git LFS config for the local Git repository only.")
-		if git.IsGitVersionAtLeast("2.20.0") {
-			cmd.Flags().BoolVarP(&worktreeInstall, "worktree", "w", false, "Set the Git LFS config for the current Git working tree, if multiple working trees are configured; otherwise, the same as --local.")
-		}
+		cmd.Flags().BoolVarP(&worktreeInstall, "worktree", "w", false, "Set the Git LFS config for the current Git working tree, if multiple working trees are configured; otherwise, the same as --local.")
+		cmd.Flags().BoolVarP(&worktreeConfig, "enable-worktree-config", "", false, "Enable the worktreeConfig extension if --worktree needs it.")
 		cmd.Flags().BoolVarP(&systemInstall, "system", "", false, "Set the Git LFS config in system-wide scope.")
 		cmd.Flags().BoolVarP(&skipSmudgeInstall, "skip-smudge", "s", false, "Skip automatic downloading of objects on clone or pull.")
 		cmd.Flags().BoolVarP(&skipRepoInstall, "skip-repo", "", false, "Skip repo setup, just install global filters.")
+		cmd.Flags().BoolVarP(&sizeGuardInstall, "size-guard", "", false, "Install a pre-commit hook that rejects large files not tracked by Git LFS.")
 		cmd.Flags().BoolVarP(&manualInstall, "manual", "m", false, "Print instructions for manual install.")
+		cmd.Flags().BoolVarP(&checkInstall, "check", "", false, "Report whether Git LFS is set up, as JSON, without changing anything.")
 		cmd.AddCommand(NewCommand("hooks", installHooksCommand))
 	})
 }
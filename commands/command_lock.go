@@ -9,6 +9,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +20,7 @@ var (
 
 func lockCommand(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		Print("Usage: git lfs lock <path>")
+		PrintMsg("lock.usage")
 		return
 	}
 
@@ -39,7 +40,7 @@ func lockCommand(cmd *cobra.Command, args []string) {
 
 	lock, err := lockClient.LockFile(path)
 	if err != nil {
-		Exit("Lock failed: %v", errors.Cause(err))
+		ExitWithErrorMsg(err, "lock.failed", tr.Args{"Err": errors.Cause(err)})
 	}
 
 	if locksCmdFlags.JSON {
@@ -49,7 +50,7 @@ func lockCommand(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	Print("Locked %s", path)
+	InfoMsg("lock.locked", tr.Args{"Path": path})
 }
 
 // lockPaths relativizes the given filepath such that it is relative to the root
@@ -10,6 +10,7 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/locking"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +21,7 @@ var (
 func locksCommand(cmd *cobra.Command, args []string) {
 	filters, err := locksCmdFlags.Filters()
 	if err != nil {
-		Exit("Error building filters: %v", err)
+		ExitMsg("locks.filters", tr.Args{"Err": err})
 	}
 
 	if len(lockRemote) > 0 {
@@ -34,22 +35,22 @@ func locksCommand(cmd *cobra.Command, args []string) {
 
 	if locksCmdFlags.Cached {
 		if locksCmdFlags.Limit > 0 {
-			Exit("--cached option can't be combined with --limit")
+			ExitMsg("locks.cached-limit")
 		}
 		if len(filters) > 0 {
-			Exit("--cached option can't be combined with filters")
+			ExitMsg("locks.cached-filters")
 		}
 		if locksCmdFlags.Local {
-			Exit("--cached option can't be combined with --local")
+			ExitMsg("locks.cached-local")
 		}
 	}
 
 	if locksCmdFlags.Verify {
 		if len(filters) > 0 {
-			Exit("--verify option can't be combined with filters")
+			ExitMsg("locks.verify-filters")
 		}
 		if locksCmdFlags.Local {
-			Exit("--verify option can't be combined with --local")
+			ExitMsg("locks.verify-local")
 		}
 	}
 
@@ -123,7 +124,7 @@ func locksCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if err != nil {
-		Exit("Error while retrieving locks: %v", errors.Cause(err))
+		ExitWithErrorMsg(err, "locks.retrieve-failed", tr.Args{"Err": errors.Cause(err)})
 	}
 }
 
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -18,7 +19,7 @@ func logsCommand(cmd *cobra.Command, args []string) {
 func logsLastCommand(cmd *cobra.Command, args []string) {
 	logs := sortedLogs()
 	if len(logs) < 1 {
-		Print("No logs to show")
+		PrintMsg("logs.none")
 		return
 	}
 
@@ -27,14 +28,14 @@ func logsLastCommand(cmd *cobra.Command, args []string) {
 
 func logsShowCommand(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		Print("Supply a log name.")
+		PrintMsg("logs.supply-name")
 		return
 	}
 
 	name := args[0]
 	by, err := ioutil.ReadFile(filepath.Join(cfg.LocalLogDir(), name))
 	if err != nil {
-		Exit("Error reading log: %s", name)
+		ExitMsg("logs.read-failed", tr.Args{"Name": name})
 	}
 
 	Debug("Reading log: %s", name)
@@ -44,17 +45,16 @@ func logsShowCommand(cmd *cobra.Command, args []string) {
 func logsClearCommand(cmd *cobra.Command, args []string) {
 	err := os.RemoveAll(cfg.LocalLogDir())
 	if err != nil {
-		Panic(err, "Error clearing %s", cfg.LocalLogDir())
+		PanicMsg(err, "logs.clear-failed", tr.Args{"Dir": cfg.LocalLogDir()})
 	}
 
-	Print("Cleared %s", cfg.LocalLogDir())
+	PrintMsg("logs.cleared", tr.Args{"Dir": cfg.LocalLogDir()})
 }
 
 func logsBoomtownCommand(cmd *cobra.Command, args []string) {
 	Debug("Debug message")
-	err := errors.Wrapf(errors.New("Inner error message!"), "Error")
-	Panic(err, "Welcome to Boomtown")
-	Debug("Never seen")
+	err := errors.Wrapf(errors.New("Inner error message!"), "Welcome to Boomtown")
+	panic(err)
 }
 
 func sortedLogs() []string {
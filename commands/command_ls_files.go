@@ -7,6 +7,7 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -27,7 +28,7 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 	var scanRange = false
 	if len(args) > 0 {
 		if lsFilesScanAll {
-			Exit("fatal: cannot use --all with explicit reference")
+			ExitMsg("ls-files.all-with-ref")
 		} else if args[0] == "--all" {
 			// Since --all is a valid argument to "git rev-parse",
 			// if we try to give it to git.ResolveRef below, we'll
@@ -35,13 +36,13 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 			//
 			// So, let's check early that the caller invoked the
 			// command correctly.
-			Exit("fatal: did you mean \"git lfs ls-files --all --\" ?")
+			ExitMsg("ls-files.did-you-mean-all")
 		}
 
 		ref = args[0]
 		if len(args) > 1 {
 			if lsFilesScanDeleted {
-				Exit("fatal: cannot use --deleted with reference range")
+				ExitMsg("ls-files.deleted-with-range")
 			}
 			otherRef = args[1]
 			scanRange = true
@@ -64,7 +65,7 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			Exit("Could not scan for Git LFS tree: %s", err)
+			ExitMsg("common.scan-tree-failed", tr.Args{"Err": err})
 			return
 		}
 
@@ -75,6 +76,10 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 		}
 
 		if debug {
+			meta, err := lfs.StatObject(cfg, p.Oid)
+			if err != nil {
+				LoggedErrorMsg(err, "ls-files.examine-failed", tr.Args{"Name": p.Name, "Err": err})
+			}
 			Print(
 				"filepath: %s\n"+
 					"    size: %d\n"+
@@ -85,7 +90,7 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 				p.Name,
 				p.Size,
 				fileExistsOfSize(p),
-				cfg.LFSObjectExists(p.Oid, p.Size),
+				meta.HasSize(p.Size),
 				p.OidType,
 				p.Oid,
 				p.Version)
@@ -116,12 +121,12 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 		// Do so to avoid showing "mixed" results, e.g., ls-files output
 		// from a specific historical revision, and the index.
 		if err := gitscanner.ScanIndex(ref, nil); err != nil {
-			Exit("Could not scan for Git LFS index: %s", err)
+			ExitMsg("ls-files.scan-index-failed", tr.Args{"Err": err})
 		}
 	}
 	if lsFilesScanAll {
 		if err := gitscanner.ScanAll(nil); err != nil {
-			Exit("Could not scan for Git LFS history: %s", err)
+			ExitMsg("ls-files.scan-history-failed", tr.Args{"Err": err})
 		}
 	} else {
 		var err error
@@ -134,7 +139,7 @@ func lsFilesCommand(cmd *cobra.Command, args []string) {
 		}
 
 		if err != nil {
-			Exit("Could not scan for Git LFS tree: %s", err)
+			ExitMsg("common.scan-tree-failed", tr.Args{"Err": err})
 		}
 	}
 }
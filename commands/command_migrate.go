@@ -2,15 +2,19 @@ package commands
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
 
 	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/git/githistory"
 	"github.com/git-lfs/git-lfs/tasklog"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/git-lfs/gitobj"
 	"github.com/spf13/cobra"
 )
@@ -36,9 +40,6 @@ var (
 	// and instructs 'git lfs migrate' to migrate all local references.
 	migrateEverything bool
 
-	// migrateVerbose enables verbose logging
-	migrateVerbose bool
-
 	// objectMapFile is the path to the map of old sha1 to new sha1
 	// commits
 	objectMapFilePath string
@@ -49,6 +50,16 @@ var (
 	// migrateCommitMessage is the message to use with the commit generated
 	// by the migrate command
 	migrateCommitMessage string
+	// migrateEditMessage is the flag indicating whether or not the commit
+	// message generated with --no-rewrite should be opened in an editor.
+	migrateEditMessage bool
+
+	// migrateSquash is the flag indicating whether or not the migrated
+	// range of commits should be collapsed into a single commit.
+	migrateSquash bool
+	// migrateSquashMessage is the message to use with the commit generated
+	// by --squash.
+	migrateSquashMessage string
 
 	// exportRemote is the remote from which to download objects when
 	// performing an export
@@ -57,6 +68,13 @@ var (
 	// migrateFixup is the flag indicating whether or not to infer the
 	// included and excluded filepath patterns.
 	migrateFixup bool
+
+	// migrateMaxCommitSizeFmt is the --max-commit-size flag given to 'git
+	// lfs migrate import'.
+	migrateMaxCommitSizeFmt string
+	// migrateMaxCommitSize is the number of bytes parsed from
+	// migrateMaxCommitSizeFmt. Files of at most this size are left in Git.
+	migrateMaxCommitSize uint64
 )
 
 // migrate takes the given command and arguments, *gitobj.ObjectDatabase, as well
@@ -105,6 +123,17 @@ func rewriteOptions(args []string, opts *githistory.RewriteOptions, l *tasklog.L
 		return nil, err
 	}
 
+	// Record the progress of rewrites which update references, so that
+	// they may be resumed if interrupted. The checkpoint is only resumed
+	// by a migration with the same mode, filters, and references.
+	var checkpointFilePath, checkpointKey string
+	if opts.UpdateRefs && !opts.Squash && len(opts.CheckpointKey) > 0 {
+		// The checkpoint maps this repository's commits, so it is
+		// kept out of lfs.storage, which other clones may share.
+		checkpointFilePath = filepath.Join(cfg.Filesystem().GitStorageDir, "lfs", "migrate", "checkpoint")
+		checkpointKey = migrateCheckpointKey(opts.CheckpointKey, include, exclude)
+	}
+
 	return &githistory.RewriteOptions{
 		Include: include,
 		Exclude: exclude,
@@ -112,6 +141,12 @@ func rewriteOptions(args []string, opts *githistory.RewriteOptions, l *tasklog.L
 		UpdateRefs:        opts.UpdateRefs,
 		Verbose:           opts.Verbose,
 		ObjectMapFilePath: opts.ObjectMapFilePath,
+		Squash:            opts.Squash,
+		SquashMessage:     opts.SquashMessage,
+
+		CheckpointFilePath: checkpointFilePath,
+		CheckpointKey:      checkpointKey,
+		ResumeFn:           opts.ResumeFn,
 
 		BlobFn:            opts.BlobFn,
 		TreePreCallbackFn: opts.TreePreCallbackFn,
@@ -119,6 +154,25 @@ func rewriteOptions(args []string, opts *githistory.RewriteOptions, l *tasklog.L
 	}, nil
 }
 
+// migrateCheckpointKey returns a key identifying a migration described by
+// "desc" of the references "include", excluding "exclude".
+func migrateCheckpointKey(desc string, include, exclude []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", desc)
+	fmt.Fprintf(h, "include %s\x00", strings.Join(include, "\x00"))
+	fmt.Fprintf(h, "exclude %s\x00", strings.Join(exclude, "\x00"))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// migrateCheckpointDesc returns a description of a migration in the given
+// "mode" using the filepath patterns of "filter", for use with
+// migrateCheckpointKey.
+func migrateCheckpointDesc(mode string, filter *filepathfilter.Filter) string {
+	return fmt.Sprintf("%s include=%s exclude=%s", mode,
+		strings.Join(filter.Include(), ","), strings.Join(filter.Exclude(), ","))
+}
+
 // includeExcludeRefs returns fully-qualified sets of references to include, and
 // exclude, or an error if those could not be determined.
 //
@@ -327,7 +381,7 @@ func ensureWorkingCopyClean(in io.Reader, out io.Writer) {
 		answer := bufio.NewReader(in)
 	L:
 		for {
-			fmt.Fprintf(out, "migrate: override changes in your working copy? [Y/n] ")
+			fmt.Fprint(out, tr.Tr("migrate.override-prompt"))
 			s, err := answer.ReadString('\n')
 			if err != nil {
 				if err == io.EOF {
@@ -353,9 +407,9 @@ func ensureWorkingCopyClean(in io.Reader, out io.Writer) {
 	}
 
 	if proceed {
-		fmt.Fprintf(out, "migrate: changes in your working copy will be overridden ...\n")
+		fmt.Fprintln(out, tr.Tr("migrate.overriding"))
 	} else {
-		Exit("migrate: working copy must not be dirty")
+		ExitMsg("migrate.dirty")
 	}
 }
 
@@ -364,16 +418,20 @@ func init() {
 	info.Flags().IntVar(&migrateInfoTopN, "top", 5, "--top=<n>")
 	info.Flags().StringVar(&migrateInfoAboveFmt, "above", "", "--above=<n>")
 	info.Flags().StringVar(&migrateInfoUnitFmt, "unit", "", "--unit=<unit>")
+	info.Flags().BoolVar(&migrateInfoPathspec, "pathspec", false, "Group entries by directory")
+	info.Flags().BoolVar(&migrateInfoJSON, "json", false, "Print the aggregation as JSON")
 
 	importCmd := NewCommand("import", migrateImportCommand)
-	importCmd.Flags().BoolVar(&migrateVerbose, "verbose", false, "Verbose logging")
 	importCmd.Flags().StringVar(&objectMapFilePath, "object-map", "", "Object map file")
 	importCmd.Flags().BoolVar(&migrateNoRewrite, "no-rewrite", false, "Add new history without rewriting previous")
 	importCmd.Flags().StringVarP(&migrateCommitMessage, "message", "m", "", "With --no-rewrite, an optional commit message")
+	importCmd.Flags().BoolVarP(&migrateEditMessage, "edit", "e", false, "With --no-rewrite, edit the commit message in an editor")
+	importCmd.Flags().BoolVar(&migrateSquash, "squash", false, "Collapse the migrated commits into a single commit")
+	importCmd.Flags().StringVar(&migrateSquashMessage, "squash-message", "", "With --squash, the message for the squashed commit")
 	importCmd.Flags().BoolVar(&migrateFixup, "fixup", false, "Infer filepaths based on .gitattributes")
+	importCmd.Flags().StringVar(&migrateMaxCommitSizeFmt, "max-commit-size", "", "Leave files of at most this size in Git")
 
 	exportCmd := NewCommand("export", migrateExportCommand)
-	exportCmd.Flags().BoolVar(&migrateVerbose, "verbose", false, "Verbose logging")
 	exportCmd.Flags().StringVar(&objectMapFilePath, "object-map", "", "Object map file")
 	exportCmd.Flags().StringVar(&exportRemote, "remote", "", "Remote from which to download objects")
 
@@ -4,14 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/git/githistory"
 	"github.com/git-lfs/git-lfs/lfs"
-	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/git-lfs/gitobj"
 	"github.com/spf13/cobra"
 )
@@ -19,9 +20,7 @@ import (
 func migrateExportCommand(cmd *cobra.Command, args []string) {
 	ensureWorkingCopyClean(os.Stdin, os.Stderr)
 
-	l := tasklog.NewLogger(os.Stderr,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	l := newLogger(os.Stderr)
 	defer l.Close()
 
 	db, err := getObjectDatabase()
@@ -41,8 +40,9 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 	gitfilter := lfs.NewGitFilter(cfg)
 
 	opts := &githistory.RewriteOptions{
-		Verbose:           migrateVerbose,
+		Verbose:           isVerbose(),
 		ObjectMapFilePath: objectMapFilePath,
+		CheckpointKey:     migrateCheckpointDesc("export", filter),
 		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
 			if filepath.Base(path) == ".gitattributes" {
 				return b, nil
@@ -76,6 +76,11 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 				return nil, err
 			}
 
+			// Remove the lines tracking the exported patterns
+			// with Git LFS, so that only the lines in "ours"
+			// mention them.
+			theirs = untrackedFromAttrs(theirs, filter)
+
 			// Create a blob of the attributes that are optionally
 			// present in the "t" tree's .gitattributes blob, and
 			// union in the patterns that we've tracked.
@@ -118,38 +123,60 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 		ExitWithError(errors.Errorf("fatal: invalid remote %s provided", remote))
 	}
 
-	// If we have a valid remote, pre-download all objects using the Transfer Queue
-	if remoteURL != "" {
-		q := newDownloadQueue(getTransferManifestOperationRemote("Download", remote), remote)
-		gs := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
-			if err != nil {
-				return
-			}
+	// Find all objects which need to be exported, but which are not present
+	// in the local cache.
+	var missing []*lfs.WrappedPointer
+	gs := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			return
+		}
 
-			if !filter.Allows(p.Name) {
-				return
-			}
+		if !filter.Allows(p.Name) {
+			return
+		}
 
+		if !cfg.Filesystem().ObjectExists(p.Oid, p.Size) {
+			missing = append(missing, p)
+		}
+	})
+	if err := gs.ScanRefs(opts.Include, opts.Exclude, nil); err != nil {
+		ExitWithError(err)
+	}
+	gs.Close()
+
+	// If we have a valid remote, pre-download all objects using the Transfer Queue
+	if remoteURL != "" && len(missing) > 0 {
+		q := newDownloadQueue(getTransferManifestOperationRemote("Download", remote), remote)
+		for _, p := range missing {
 			downloadPath, err := gitfilter.ObjectPath(p.Oid)
 			if err != nil {
-				return
-			}
-
-			if _, err := os.Stat(downloadPath); os.IsNotExist(err) {
-				q.Add(p.Name, downloadPath, p.Oid, p.Size, false, nil)
+				ExitWithError(err)
 			}
-		})
-		gs.ScanRefs(opts.Include, opts.Exclude, nil)
 
+			q.Add(p.Name, downloadPath, p.Oid, p.Size, false, nil)
+		}
 		q.Wait()
 
 		for _, err := range q.Errors() {
 			if err != nil {
-				ExitWithError(err)
+				Debug("migrate: %s", err)
 			}
 		}
 	}
 
+	// Refuse to rewrite any history if there are objects which could be
+	// found neither locally nor on the remote, since their pointers could
+	// not be replaced.
+	var unavailable []string
+	for _, p := range missing {
+		if !cfg.Filesystem().ObjectExists(p.Oid, p.Size) {
+			unavailable = append(unavailable, fmt.Sprintf("  %s (%s)", p.Name, p.Oid))
+		}
+	}
+	if len(unavailable) > 0 {
+		ExitMsg("migrate.objects-not-found", tr.Args{"Count": len(unavailable), "Objects": strings.Join(unavailable, "\n")})
+	}
+
 	// Perform the rewrite
 	if _, err := rewriter.Rewrite(opts); err != nil {
 		ExitWithError(err)
@@ -174,10 +201,42 @@ func migrateExportCommand(cmd *cobra.Command, args []string) {
 	// and thus will not be pruned from the cache.
 	fetchPruneCfg.FetchRecentRefsDays = 0
 
-	// Prune our cache
+	// Prune our cache, unless other repositories may share it, in which
+	// case the export has still succeeded.
+	if cfg.Filesystem().IsSharedStorage() {
+		ErrorMsg("prune.shared-storage-skipped", tr.Args{"Dir": cfg.LFSStorageDir()})
+		return
+	}
 	prune(fetchPruneCfg, false, false, true)
 }
 
+// untrackedFromAttrs returns a copy of the ordered set of .gitattributes lines
+// "attrs" without any lines that track one of the patterns included in the
+// given filter with Git LFS.
+func untrackedFromAttrs(attrs *tools.OrderedSet, filter *filepathfilter.Filter) *tools.OrderedSet {
+	included := tools.NewOrderedSet()
+	for _, include := range filter.Include() {
+		included.Add(escapeAttrPattern(include))
+	}
+
+	untracked := tools.NewOrderedSet()
+	for line := range attrs.Iter() {
+		fields := strings.Fields(line)
+		if len(fields) > 1 && included.Contains(fields[0]) {
+			var tracked bool
+			for _, attr := range fields[1:] {
+				tracked = tracked || attr == "filter=lfs"
+			}
+
+			if tracked {
+				continue
+			}
+		}
+		untracked.Add(line)
+	}
+	return untracked
+}
+
 // trackedFromExportFilter returns an ordered set of strings where each entry
 // is a line we intend to place in the .gitattributes file. It adds/removes the
 // filter/diff/merge=lfs attributes based on patterns included/excluded in the
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,18 +16,41 @@ import (
 	"github.com/git-lfs/git-lfs/git/gitattr"
 	"github.com/git-lfs/git-lfs/git/githistory"
 	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/subprocess"
 	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/git-lfs/gitobj"
 	"github.com/spf13/cobra"
 )
 
 func migrateImportCommand(cmd *cobra.Command, args []string) {
+	if cmd.Flag("squash-message").Changed && !migrateSquash {
+		ExitWithError(errors.Errorf("fatal: --squash-message requires --squash"))
+	}
+
+	// bySize is whether small files are left in Git, and only the files
+	// above --max-commit-size are converted.
+	bySize := cmd.Flag("max-commit-size").Changed
+	if bySize {
+		size, err := humanize.ParseBytes(migrateMaxCommitSizeFmt)
+		if err != nil {
+			ExitWithError(errors.Wrap(err, "fatal: cannot parse --max-commit-size=<n>"))
+		}
+		migrateMaxCommitSize = size
+
+		if migrateNoRewrite {
+			ExitWithError(errors.Errorf("fatal: --no-rewrite and --max-commit-size cannot be combined"))
+		}
+		if migrateFixup {
+			ExitWithError(errors.Errorf("fatal: --fixup and --max-commit-size cannot be combined"))
+		}
+	}
+
 	ensureWorkingCopyClean(os.Stdin, os.Stderr)
 
-	l := tasklog.NewLogger(os.Stderr,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	l := newLogger(os.Stderr)
 	defer l.Close()
 
 	db, err := getObjectDatabase()
@@ -38,9 +62,13 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 	// To avoid confusion later, let's make sure that we've installed the
 	// necessary hooks so that a newly migrated repository is `git
 	// push`-able immediately following a `git lfs migrate import`.
-	installHooks(false)
+	upgradeHooks()
 
 	if migrateNoRewrite {
+		if migrateSquash {
+			ExitWithError(errors.Errorf("fatal: --no-rewrite and --squash cannot be combined"))
+		}
+
 		if migrateFixup {
 			ExitWithError(errors.Errorf("fatal: --no-rewrite and --fixup cannot be combined"))
 		}
@@ -62,24 +90,71 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 
 		root := commit.TreeID
 
+		// If the caller gave explicit --include, --exclude arguments,
+		// convert files matching those patterns and track them in the
+		// root .gitattributes. Otherwise, fall back to the patterns
+		// already tracked by .gitattributes.
 		filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
-		if len(filter.Include()) == 0 {
-			ExitWithError(errors.Errorf("fatal: no Git LFS filters found in .gitattributes"))
-		}
+		include, exclude := getIncludeExcludeArgs(cmd)
+		if include != nil || exclude != nil {
+			filter = buildFilepathFilter(cfg, include, exclude, false)
+			if len(filter.Include()) == 0 {
+				ExitWithError(errors.Errorf("fatal: expected one or more --include patterns with --no-rewrite"))
+			}
 
-		gf := lfs.NewGitFilter(cfg)
+			for _, file := range args {
+				if !filter.Allows(file) {
+					ExitWithError(errors.Errorf("fatal: file %s did not match any --include arguments", file))
+				}
+			}
+		} else {
+			if len(filter.Include()) == 0 {
+				ExitWithError(errors.Errorf("fatal: no Git LFS filters found in .gitattributes"))
+			}
 
-		for _, file := range args {
-			if !filter.Allows(file) {
-				ExitWithError(errors.Errorf("fatal: file %s did not match any Git LFS filters in .gitattributes", file))
+			for _, file := range args {
+				if !filter.Allows(file) {
+					ExitWithError(errors.Errorf("fatal: file %s did not match any Git LFS filters in .gitattributes", file))
+				}
 			}
 		}
 
+		gf := newCleanGitFilter()
+
+		converted := make([]string, 0, len(args))
 		for _, file := range args {
-			root, err = rewriteTree(gf, db, root, file)
+			rewritten, err := rewriteTree(gf, db, root, file)
 			if err != nil {
 				ExitWithError(errors.Wrapf(err, "fatal: could not rewrite %q", file))
 			}
+
+			if bytes.Equal(rewritten, root) {
+				InfoMsg("migrate.already-pointer", tr.Args{"File": file})
+				continue
+			}
+
+			root = rewritten
+			converted = append(converted, file)
+		}
+
+		if include != nil || exclude != nil {
+			root, err = trackInRootTree(db, root, trackedFromFilter(filter))
+			if err != nil {
+				ExitWithError(errors.Wrap(err, "fatal: could not update .gitattributes"))
+			}
+		}
+
+		if bytes.Equal(root, commit.TreeID) {
+			InfoMsg("migrate.nothing-to-convert")
+			return
+		}
+
+		message := generateMigrateCommitMessage(cmd, strings.Join(converted, ","))
+		if migrateEditMessage {
+			message, err = editMigrateCommitMessage(message)
+			if err != nil {
+				ExitWithError(errors.Wrap(err, "fatal: could not edit commit message"))
+			}
 		}
 
 		name, email := cfg.CurrentAuthor()
@@ -100,7 +175,7 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			Author:    author.String(),
 			Committer: committer.String(),
 			ParentIDs: [][]byte{sha},
-			Message:   generateMigrateCommitMessage(cmd, strings.Join(args, ",")),
+			Message:   message,
 			TreeID:    root,
 		})
 
@@ -130,13 +205,46 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 
 	tracked := trackedFromFilter(rewriter.Filter())
 	exts := tools.NewOrderedSet()
-	gitfilter := lfs.NewGitFilter(cfg)
+	gitfilter := newCleanGitFilter()
+
+	// skipped holds the paths of blobs which were left as they were
+	// because they are already Git LFS pointers.
+	skipped := tools.NewOrderedSet()
+
+	// paths holds a .gitattributes line for each file converted with
+	// --max-commit-size. Files are tracked by their own path, rather than
+	// by extension or by --include, so that the smaller files which share
+	// them are not smudged.
+	paths := tools.NewOrderedSet()
 
 	var fixups *gitattr.Tree
 
+	mode := "import"
+	if migrateFixup {
+		mode = "import --fixup"
+	} else if bySize {
+		mode = fmt.Sprintf("import --max-commit-size=%d", migrateMaxCommitSize)
+	}
+
 	migrate(args, rewriter, l, &githistory.RewriteOptions{
-		Verbose:           migrateVerbose,
+		Verbose:           isVerbose(),
 		ObjectMapFilePath: objectMapFilePath,
+		Squash:            migrateSquash,
+		SquashMessage:     migrateSquashMessage,
+		CheckpointKey:     migrateCheckpointDesc(mode, rewriter.Filter()),
+		ResumeFn: func(original, rewritten []byte) error {
+			// The files converted before the previous migration
+			// was interrupted are no longer seen, so recover the
+			// lines which track them from the .gitattributes of
+			// the last commit it rewrote.
+			if bySize {
+				return seedTracked(db, rewritten, "/", paths)
+			}
+			if migrateFixup || tracked.Cardinality() > 0 {
+				return nil
+			}
+			return seedTracked(db, rewritten, "*.", exts)
+		},
 		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
 			if filepath.Base(path) == ".gitattributes" {
 				return b, nil
@@ -156,14 +264,32 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 				}
 			}
 
-			var buf bytes.Buffer
+			if bySize {
+				if b.Size <= int64(migrateMaxCommitSize) {
+					return b, nil
+				}
+				paths.Add(fmt.Sprintf("/%s filter=lfs diff=lfs merge=lfs -text", escapeGlobCharacters(path)))
+			} else if ext := filepath.Ext(path); len(ext) > 0 {
+				exts.Add(fmt.Sprintf("*%s filter=lfs diff=lfs merge=lfs -text", ext))
+			}
 
-			if _, err := clean(gitfilter, &buf, b.Contents, path, b.Size); err != nil {
-				return nil, err
+			// Leave blobs which are already Git LFS pointers, such
+			// as those migrated by a previous run, as they are,
+			// rather than cleaning them again.
+			_, contents, err := lfs.DecodeFrom(b.Contents)
+			if err == nil {
+				skipped.Add(path)
+
+				// Returning the same blob tells the rewriter
+				// that it is unchanged.
+				b.Contents = contents
+				return b, nil
 			}
 
-			if ext := filepath.Ext(path); len(ext) > 0 {
-				exts.Add(fmt.Sprintf("*%s filter=lfs diff=lfs merge=lfs -text", ext))
+			var buf bytes.Buffer
+
+			if _, err := clean(gitfilter, &buf, contents, path, b.Size); err != nil {
+				return nil, err
 			}
 
 			return &gitobj.Blob{
@@ -192,7 +318,15 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 			}
 
 			ours := tracked
-			if ours.Cardinality() == 0 {
+			if bySize {
+				// Leave the trees of commits before the first
+				// large file as they were, so that those
+				// commits are not rewritten.
+				ours = paths
+				if ours.Cardinality() == 0 {
+					return t, nil
+				}
+			} else if ours.Cardinality() == 0 {
 				// If there were no explicitly tracked
 				// --include, --exclude filters, assume that the
 				// include set is the wildcard filepath
@@ -212,21 +346,27 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 				}
 			}
 
-			theirs, err := trackedFromAttrs(db, t)
+			theirs, err := attrsFromTree(db, t)
 			if err != nil {
 				return nil, err
 			}
 
-			// Create a blob of the attributes that are optionally
-			// present in the "t" tree's .gitattributes blob, and
-			// union in the patterns that we've tracked.
+			// Merge the patterns that we've tracked into the lines
+			// of the "t" tree's own .gitattributes blob, if it has
+			// one, leaving its unrelated lines as they are.
 			//
-			// Perform this Union() operation each time we visit a
-			// root tree such that if the underlying .gitattributes
-			// is present and has a diff between commits in the
-			// range of commits to migrate, those changes are
-			// preserved.
-			blob, err := trackedToBlob(db, theirs.Clone().Union(ours))
+			// Perform this merge each time we visit a root tree
+			// such that if the underlying .gitattributes is present
+			// and has a diff between commits in the range of
+			// commits to migrate, those changes are preserved, and
+			// that checking out any rewritten commit smudges the
+			// migrated files.
+			merged, ok := theirs.merge(ours)
+			if !ok {
+				return t, nil
+			}
+
+			blob, err := merged.write(db)
 			if err != nil {
 				return nil, err
 			}
@@ -243,11 +383,48 @@ func migrateImportCommand(cmd *cobra.Command, args []string) {
 		UpdateRefs: true,
 	})
 
+	if isVerbose() && skipped.Cardinality() > 0 {
+		t := l.List("migrate: Skipped existing Git LFS pointers")
+		for path := range skipped.Iter() {
+			t.Entry(fmt.Sprintf("migrate: %s: already a Git LFS pointer, skipping", path))
+		}
+		t.Complete()
+	}
+
 	if err := checkoutNonBare(l); err != nil {
 		ExitWithError(errors.Wrap(err, "fatal: could not checkout"))
 	}
 }
 
+// seedTracked adds to "tracked" the lines of the root .gitattributes file of
+// the commit "sha" which track a pattern beginning with "prefix" with Git LFS,
+// as written by `git lfs migrate import` for a file extension ("*.") when no
+// --include or --exclude is given, or for a path ("/") with --max-commit-size.
+func seedTracked(db *gitobj.ObjectDatabase, sha []byte, prefix string, tracked *tools.OrderedSet) error {
+	commit, err := db.Commit(sha)
+	if err != nil {
+		return err
+	}
+	root, err := db.Tree(commit.TreeID)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := attrsFromTree(db, root)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range attrs.lines {
+		fields := strings.Fields(line)
+		if len(fields) == 5 && strings.HasPrefix(fields[0], prefix) &&
+			strings.Join(fields[1:], " ") == "filter=lfs diff=lfs merge=lfs -text" {
+			tracked.Add(strings.Join(fields, " "))
+		}
+	}
+	return nil
+}
+
 // generateMigrateCommitMessage generates a commit message used with
 // --no-rewrite, using --message (if given) or generating one if it isn't.
 func generateMigrateCommitMessage(cmd *cobra.Command, patterns string) string {
@@ -257,6 +434,83 @@ func generateMigrateCommitMessage(cmd *cobra.Command, patterns string) string {
 	return fmt.Sprintf("%s: convert to Git LFS", patterns)
 }
 
+// editMigrateCommitMessage opens the user's configured editor on the given
+// commit message, in the same fashion as "git commit --edit", and returns the
+// edited message with comment lines stripped.
+func editMigrateCommitMessage(message string) (string, error) {
+	editor, err := git.Editor()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(cfg.LocalGitDir(), "LFS_MIGRATE_EDITMSG")
+	contents := fmt.Sprintf("%s\n\n"+
+		"# Please enter the commit message for the files converted to Git LFS.\n"+
+		"# Lines starting with '#' will be ignored.\n", message)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+
+	name, args := subprocess.FormatForShellQuotedArgs(editor, []string{path})
+	editCmd := subprocess.ExecCommand(name, args...)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "editor %q failed", editor)
+	}
+
+	edited, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(edited), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	message = strings.TrimSpace(strings.Join(lines, "\n"))
+	if len(message) == 0 {
+		return "", errors.New("aborting due to empty commit message")
+	}
+	return message, nil
+}
+
+// trackInRootTree returns the OID of a copy of the tree "root" whose
+// .gitattributes file contains the given patterns in addition to any it
+// already had. If no patterns are missing, "root" is returned unchanged.
+func trackInRootTree(db *gitobj.ObjectDatabase, root []byte, patterns *tools.OrderedSet) ([]byte, error) {
+	tree, err := db.Tree(root)
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := attrsFromTree(db, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, ok := theirs.merge(patterns)
+	if !ok {
+		return root, nil
+	}
+
+	blob, err := merged.write(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.WriteTree(tree.Merge(&gitobj.TreeEntry{
+		Name:     ".gitattributes",
+		Filemode: 0100644,
+		Oid:      blob,
+	}))
+}
+
 // checkoutNonBare forces a checkout of the current reference, so long as the
 // repository is non-bare.
 //
@@ -343,6 +597,123 @@ func trackedFromAttrs(db *gitobj.ObjectDatabase, t *gitobj.Tree) (*tools.Ordered
 	return attrsCache[sha1], nil
 }
 
+// attrsFile is the contents of a .gitattributes blob, split into lines.
+type attrsFile struct {
+	// lines are the lines of the file, without line endings.
+	lines []string
+	// lineEnd is the line ending used by the file.
+	lineEnd string
+}
+
+var (
+	// attrsFileCache maintains a cache from the hex-encoded SHA1 of a
+	// .gitattributes blob to its parsed contents.
+	attrsFileCache = make(map[string]*attrsFile)
+)
+
+// attrsFromTree returns the contents of the .gitattributes blob in the tree
+// "t", or an empty file if there is none.
+func attrsFromTree(db *gitobj.ObjectDatabase, t *gitobj.Tree) (*attrsFile, error) {
+	var oid []byte
+
+	for _, e := range t.Entries {
+		if strings.ToLower(e.Name) == ".gitattributes" && e.Type() == gitobj.BlobObjectType {
+			oid = e.Oid
+			break
+		}
+	}
+
+	if oid == nil {
+		return &attrsFile{lineEnd: "\n"}, nil
+	}
+
+	sha1 := hex.EncodeToString(oid)
+	if f, ok := attrsFileCache[sha1]; ok {
+		return f, nil
+	}
+
+	blob, err := db.Blob(oid)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadAll(blob.Contents)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &attrsFile{lineEnd: "\n"}
+	if bytes.Contains(contents, []byte("\r\n")) {
+		f.lineEnd = "\r\n"
+	}
+
+	text := strings.TrimSuffix(strings.Replace(string(contents), "\r\n", "\n", -1), "\n")
+	if len(text) > 0 {
+		f.lines = strings.Split(text, "\n")
+	}
+
+	attrsFileCache[sha1] = f
+	return f, nil
+}
+
+// merge returns a copy of this file with each of the given tracking lines
+// appended, unless the file already tracks that line's pattern with Git LFS.
+// Existing lines are left as they are.
+//
+// It returns false if no lines needed to be added.
+func (f *attrsFile) merge(tracked *tools.OrderedSet) (*attrsFile, bool) {
+	lfsPatterns := make(map[string]struct{})
+	existing := make(map[string]struct{})
+	for _, line := range f.lines {
+		existing[line] = struct{}{}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "filter=lfs" {
+				lfsPatterns[fields[0]] = struct{}{}
+			}
+		}
+	}
+
+	merged := &attrsFile{
+		lines:   append([]string(nil), f.lines...),
+		lineEnd: f.lineEnd,
+	}
+
+	for line := range tracked.Iter() {
+		if _, ok := existing[line]; ok {
+			continue
+		}
+		if fields := strings.Fields(line); len(fields) > 0 {
+			if _, ok := lfsPatterns[fields[0]]; ok && strings.Contains(line, "filter=lfs") {
+				continue
+			}
+		}
+
+		merged.lines = append(merged.lines, line)
+		existing[line] = struct{}{}
+	}
+
+	return merged, len(merged.lines) > len(f.lines)
+}
+
+// write writes this file to the object database "db" and returns its OID.
+func (f *attrsFile) write(db *gitobj.ObjectDatabase) ([]byte, error) {
+	var attrs bytes.Buffer
+
+	for _, line := range f.lines {
+		fmt.Fprintf(&attrs, "%s%s", line, f.lineEnd)
+	}
+
+	return db.WriteBlob(&gitobj.Blob{
+		Contents: &attrs,
+		Size:     int64(attrs.Len()),
+	})
+}
+
 // trackedToBlob writes and returns the OID of a .gitattributes blob based on
 // the patterns given in the ordered set of patterns, "patterns".
 func trackedToBlob(db *gitobj.ObjectDatabase, patterns *tools.OrderedSet) ([]byte, error) {
@@ -384,6 +755,19 @@ func rewriteTree(gf *lfs.GitFilter, db *gitobj.ObjectDatabase, root []byte, path
 			return nil, err
 		}
 
+		if _, err := lfs.DecodePointerFromBlob(blob); err == nil {
+			// The blob is already a Git LFS pointer, so leave
+			// the tree as-is.
+			return root, nil
+		}
+
+		// DecodePointerFromBlob may have consumed part of the
+		// blob's contents, so reload it before cleaning.
+		blob, err = db.Blob(blobEntry.Oid)
+		if err != nil {
+			return nil, err
+		}
+
 		var buf bytes.Buffer
 
 		if _, err := clean(gf, &buf, blob.Contents, blobEntry.Name, blob.Size); err != nil {
@@ -426,6 +810,10 @@ func rewriteTree(gf *lfs.GitFilter, db *gitobj.ObjectDatabase, root []byte, path
 			return nil, err
 		}
 
+		if bytes.Equal(rewrittenSubtree, subtreeEntry.Oid) {
+			return root, nil
+		}
+
 		tree = tree.Merge(&gitobj.TreeEntry{
 			Filemode: subtreeEntry.Filemode,
 			Name:     subtreeEntry.Name,
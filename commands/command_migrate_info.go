@@ -1,16 +1,18 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/git/githistory"
-	"github.com/git-lfs/git-lfs/tasklog"
+	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
 	"github.com/git-lfs/git-lfs/tools/humanize"
 	"github.com/git-lfs/gitobj"
@@ -37,12 +39,19 @@ var (
 	// migrateInfoUnit is the number of bytes in the unit given as
 	// migrateInfoUnitFmt.
 	migrateInfoUnit uint64
+
+	// migrateInfoPathspec is a flag given to the git-lfs-migrate(1)
+	// subcommand 'info' specifying that entries should be grouped by the
+	// directory containing each file in addition to its extension.
+	migrateInfoPathspec bool
+
+	// migrateInfoJSON is a flag given to the git-lfs-migrate(1) subcommand
+	// 'info' specifying that the raw aggregation should be printed as JSON.
+	migrateInfoJSON bool
 )
 
 func migrateInfoCommand(cmd *cobra.Command, args []string) {
-	l := tasklog.NewLogger(os.Stderr,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	l := newLogger(os.Stderr)
 
 	db, err := getObjectDatabase()
 	if err != nil {
@@ -72,16 +81,7 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 
 	migrate(args, rewriter, l, &githistory.RewriteOptions{
 		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
-			ext := fmt.Sprintf("*%s", filepath.Ext(path))
-
-			// If extension exists, group all items under extension,
-			// else just use the file name.
-			var groupName string
-			if len(ext) > 1 {
-				groupName = ext
-			} else {
-				groupName = filepath.Base(path)
-			}
+			groupName := migrateInfoGroupName(path)
 
 			entry := exts[groupName]
 			if entry == nil {
@@ -96,6 +96,14 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 				entry.BytesAbove += b.Size
 			}
 
+			// Only blobs small enough to be pointers are read, so
+			// that the contents of large blobs are never loaded.
+			if p, err := lfs.DecodePointerFromBlob(b); err == nil {
+				entry.TotalLFS++
+				entry.BytesLFS += p.Size
+				entry.bytesPointers += b.Size
+			}
+
 			exts[groupName] = entry
 
 			return b, nil
@@ -111,23 +119,78 @@ func migrateInfoCommand(cmd *cobra.Command, args []string) {
 
 	entries = entries[:tools.MaxInt(0, migrateInfoTopN)]
 
+	if migrateInfoJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(entries); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
 	entries.Print(os.Stdout)
 }
 
+// migrateInfoGroupName returns the name of the group under which the file at
+// "path" is counted. Files are grouped by their extension, or by their base
+// name if they have none. If --pathspec was given, the name is qualified by
+// the directory containing the file.
+func migrateInfoGroupName(p string) string {
+	ext := fmt.Sprintf("*%s", filepath.Ext(p))
+
+	// If extension exists, group all items under extension,
+	// else just use the file name.
+	var groupName string
+	if len(ext) > 1 {
+		groupName = ext
+	} else {
+		groupName = filepath.Base(p)
+	}
+
+	if migrateInfoPathspec {
+		if dir := path.Dir(strings.TrimPrefix(p, "/")); dir != "." {
+			groupName = path.Join(dir, groupName)
+		}
+	}
+	return groupName
+}
+
 // MigrateInfoEntry represents a tuple of filetype to bytes and entry count
 // above and below a threshold.
 type MigrateInfoEntry struct {
 	// Qualifier is the filepath's extension.
-	Qualifier string
+	Qualifier string `json:"qualifier"`
 
 	// BytesAbove is total size of all files above a given threshold.
-	BytesAbove int64
+	BytesAbove int64 `json:"bytes_above"`
 	// TotalAbove is the count of all files above a given size threshold.
-	TotalAbove int64
+	TotalAbove int64 `json:"total_above"`
 	// BytesTotal is the number of bytes of all files
-	BytesTotal int64
+	BytesTotal int64 `json:"bytes_total"`
 	// Total is the count of all files.
-	Total int64
+	Total int64 `json:"total"`
+	// BytesLFS is the number of bytes referenced by all files which are
+	// already Git LFS pointers.
+	BytesLFS int64 `json:"bytes_lfs"`
+	// TotalLFS is the count of all files which are already Git LFS
+	// pointers.
+	TotalLFS int64 `json:"total_lfs"`
+	// PercentLFS is the percentage of the contents of all files, by size,
+	// which is stored in Git LFS, as given by percentLFS.
+	PercentLFS float64 `json:"percent_lfs"`
+
+	// bytesPointers is the number of bytes of the pointers counted in
+	// BytesTotal, rather than of the objects they refer to.
+	bytesPointers int64
+}
+
+// percentLFS returns the percentage of the contents of the entry's files, by
+// size, which is stored in Git LFS: the size of the objects referred to by
+// pointers, out of that and the size of every other file.
+func (e *MigrateInfoEntry) percentLFS() float64 {
+	total := e.BytesLFS + e.BytesTotal - e.bytesPointers
+	if total <= 0 {
+		return 0
+	}
+	return 100 * (float64(e.BytesLFS) / float64(total))
 }
 
 // MapToEntries creates a set of `*MigrateInfoEntry`'s for a given map of
@@ -135,6 +198,7 @@ type MigrateInfoEntry struct {
 func MapToEntries(exts map[string]*MigrateInfoEntry) []*MigrateInfoEntry {
 	entries := make([]*MigrateInfoEntry, 0, len(exts))
 	for _, entry := range exts {
+		entry.PercentLFS = entry.percentLFS()
 		entries = append(entries, entry)
 	}
 
@@ -180,6 +244,15 @@ func (e EntriesBySize) Print(to io.Writer) (int, error) {
 	sizes := make([]string, 0, len(e))
 	stats := make([]string, 0, len(e))
 	percentages := make([]string, 0, len(e))
+	lfsPercentages := make([]string, 0, len(e))
+
+	// Only show how much of each entry is already in Git LFS if any of
+	// them contain pointers, so as not to clutter the output for
+	// repositories which do not use Git LFS yet.
+	var anyLFS bool
+	for _, entry := range e {
+		anyLFS = anyLFS || entry.TotalLFS > 0
+	}
 
 	for _, entry := range e {
 		bytesAbove := uint64(entry.BytesAbove)
@@ -203,12 +276,16 @@ func (e EntriesBySize) Print(to io.Writer) (int, error) {
 		sizes = append(sizes, size)
 		stats = append(stats, stat)
 		percentages = append(percentages, percentage)
+
+		lfsPercentages = append(lfsPercentages,
+			fmt.Sprintf("%.0f%% in LFS", entry.PercentLFS))
 	}
 
 	extensions = tools.Ljust(extensions)
 	sizes = tools.Ljust(sizes)
 	stats = tools.Rjust(stats)
 	percentages = tools.Rjust(percentages)
+	lfsPercentages = tools.Rjust(lfsPercentages)
 
 	output := make([]string, 0, len(e))
 	for i := 0; i < len(e); i++ {
@@ -217,7 +294,12 @@ func (e EntriesBySize) Print(to io.Writer) (int, error) {
 		stat := stats[i]
 		percentage := percentages[i]
 
-		line := strings.Join([]string{extension, size, stat, percentage}, "\t")
+		columns := []string{extension, size, stat, percentage}
+		if anyLFS {
+			columns = append(columns, lfsPercentages[i])
+		}
+
+		line := strings.Join(columns, "\t")
 
 		output = append(output, line)
 	}
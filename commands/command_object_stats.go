@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	objectStatsSortByArg string
+	objectStatsJSONArg   bool
+)
+
+// objectStatsUnreferenced is the extension under which objects which are not
+// referenced by any local branch, tag, or the index are grouped.
+const objectStatsUnreferenced = "(unreferenced)"
+
+// objectStatsEntry is the number and size of the local objects referenced by
+// files with one extension, as reported by `git lfs object-stats`.
+type objectStatsEntry struct {
+	Extension string `json:"extension"`
+	Count     int64  `json:"count"`
+	Size      int64  `json:"size"`
+	Average   int64  `json:"average"`
+	Refs      int    `json:"refs"`
+
+	refs tools.StringSet
+}
+
+// objectStatsRefs records the paths at which each object is found, and the
+// refs in which it is found at them.
+type objectStatsRefs struct {
+	paths map[string]tools.StringSet
+	refs  map[string]tools.StringSet
+}
+
+// objectStatsCommand breaks down the local object store by the extensions of
+// the files which reference each object in the local branches and tags, and
+// the index.
+func objectStatsCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	less, ok := objectStatsOrders[objectStatsSortByArg]
+	if !ok {
+		ExitMsg("object-stats.invalid-sort-by", tr.Args{"Value": objectStatsSortByArg})
+	}
+
+	localObjects := make(map[string]int64)
+	if err := cfg.EachLFSObject(func(obj fs.Object) error {
+		localObjects[obj.Oid] = obj.Size
+		return nil
+	}); err != nil {
+		ErrorMsg("common.read-objects-failed", tr.Args{"Err": err})
+	}
+
+	entries := objectStatsBreakdown(localObjects, objectStatsScan())
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i], entries[j])
+	})
+
+	if objectStatsJSONArg {
+		out := struct {
+			Extensions []*objectStatsEntry `json:"extensions"`
+		}{entries}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(out); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	objectStatsPrint(entries)
+}
+
+// objectStatsOrders are the orders in which `--sort-by` may list extensions.
+var objectStatsOrders = map[string]func(a, b *objectStatsEntry) bool{
+	"size": func(a, b *objectStatsEntry) bool {
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Extension < b.Extension
+	},
+	"count": func(a, b *objectStatsEntry) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Extension < b.Extension
+	},
+	"extension": func(a, b *objectStatsEntry) bool {
+		return a.Extension < b.Extension
+	},
+}
+
+// objectStatsScan finds the paths and refs at which each object is found, in
+// the trees of every local branch and tag, and in the index.
+func objectStatsScan() *objectStatsRefs {
+	found := &objectStatsRefs{
+		paths: make(map[string]tools.StringSet),
+		refs:  make(map[string]tools.StringSet),
+	}
+
+	scan := func(ref string, fn func(*lfs.GitScanner) error) {
+		gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+			if err != nil {
+				LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
+				return
+			}
+
+			if found.paths[p.Oid] == nil {
+				found.paths[p.Oid] = tools.NewStringSet()
+				found.refs[p.Oid] = tools.NewStringSet()
+			}
+			found.paths[p.Oid].Add(p.Name)
+			if len(ref) > 0 {
+				found.refs[p.Oid].Add(ref)
+			}
+		})
+		defer gitscanner.Close()
+
+		if err := fn(gitscanner); err != nil {
+			ExitWithError(err)
+		}
+	}
+
+	refs, err := git.LocalRefs()
+	if err != nil {
+		ExitMsg("object-stats.list-refs-failed", tr.Args{"Err": err})
+	}
+	for _, ref := range refs {
+		scan(ref.Refspec(), func(s *lfs.GitScanner) error {
+			return s.ScanTree(ref.Sha)
+		})
+	}
+
+	scan("", func(s *lfs.GitScanner) error {
+		return s.ScanIndexTree()
+	})
+
+	return found
+}
+
+// objectStatsBreakdown groups the objects in "localObjects" by the extensions
+// of the paths at which they are found. An object found at paths with more
+// than one extension counts towards each of them.
+func objectStatsBreakdown(localObjects map[string]int64, found *objectStatsRefs) []*objectStatsEntry {
+	groups := make(map[string]*objectStatsEntry)
+	add := func(ext, oid string, size int64) {
+		entry := groups[ext]
+		if entry == nil {
+			entry = &objectStatsEntry{Extension: ext, refs: tools.NewStringSet()}
+			groups[ext] = entry
+		}
+
+		entry.Count++
+		entry.Size += size
+		if refs := found.refs[oid]; refs != nil {
+			for ref := range refs {
+				entry.refs.Add(ref)
+			}
+		}
+	}
+
+	for oid, size := range localObjects {
+		paths := found.paths[oid]
+		if paths == nil {
+			add(objectStatsUnreferenced, oid, size)
+			continue
+		}
+
+		exts := tools.NewStringSet()
+		for name := range paths {
+			exts.Add(duExtension(name))
+		}
+		for ext := range exts {
+			add(ext, oid, size)
+		}
+	}
+
+	entries := make([]*objectStatsEntry, 0, len(groups))
+	for _, entry := range groups {
+		entry.Average = entry.Size / entry.Count
+		entry.Refs = entry.refs.Cardinality()
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func objectStatsPrint(entries []*objectStatsEntry) {
+	extension := tr.Tr("object-stats.extension")
+	width := len(extension)
+	for _, entry := range entries {
+		width = tools.MaxInt(width, len(entry.Extension))
+	}
+
+	Print("%-*s  %7s  %10s  %10s  %s", width, extension,
+		tr.Tr("object-stats.count"),
+		tr.Tr("object-stats.size"),
+		tr.Tr("object-stats.average"),
+		tr.Tr("object-stats.referenced-in"))
+	for _, entry := range entries {
+		Print("%-*s  %7d  %10s  %10s  %s", width, entry.Extension, entry.Count,
+			humanize.FormatBytes(uint64(entry.Size)),
+			humanize.FormatBytes(uint64(entry.Average)),
+			tr.Tr("object-stats.refs", tr.Args{"Count": entry.Refs}))
+	}
+}
+
+func init() {
+	RegisterCommand("object-stats", objectStatsCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVar(&objectStatsSortByArg, "sort-by", "size", "Sort extensions by size, count, or extension")
+		cmd.Flags().BoolVar(&objectStatsJSONArg, "json", false, "Print statistics as JSON")
+	})
+}
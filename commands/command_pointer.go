@@ -12,6 +12,7 @@ import (
 
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -80,7 +81,7 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 		}
 
 		ptr := lfs.NewPointer(hex.EncodeToString(oidHash.Sum(nil)), size, nil)
-		fmt.Fprintf(os.Stderr, "Git LFS pointer for %s\n\n", pointerFile)
+		fmt.Fprintln(os.Stderr, tr.Tr("pointer.for-file", tr.Args{"File": pointerFile}))
 		buf := &bytes.Buffer{}
 		lfs.EncodePointer(io.MultiWriter(os.Stdout, buf), ptr)
 
@@ -90,7 +91,7 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 				Error(err.Error())
 				os.Exit(1)
 			}
-			fmt.Fprintf(os.Stderr, "\nGit blob OID: %s\n\n", buildOid)
+			fmt.Fprintf(os.Stderr, "%s\n\n", tr.Tr("pointer.blob-oid", tr.Args{"Oid": buildOid}))
 		}
 	} else {
 		comparing = false
@@ -113,7 +114,7 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 		if !pointerStdin {
 			pointerName = pointerCompare
 		}
-		fmt.Fprintf(os.Stderr, "Pointer from %s\n\n", pointerName)
+		fmt.Fprintln(os.Stderr, tr.Tr("pointer.from-file", tr.Args{"File": pointerName}))
 
 		if err != nil {
 			Error(err.Error())
@@ -127,17 +128,17 @@ func pointerCommand(cmd *cobra.Command, args []string) {
 				Error(err.Error())
 				os.Exit(1)
 			}
-			fmt.Fprintf(os.Stderr, "\nGit blob OID: %s\n", compareOid)
+			fmt.Fprintln(os.Stderr, tr.Tr("pointer.blob-oid", tr.Args{"Oid": compareOid}))
 		}
 	}
 
 	if comparing && buildOid != compareOid {
-		fmt.Fprintf(os.Stderr, "\nPointers do not match\n")
+		fmt.Fprintln(os.Stderr, tr.Tr("pointer.mismatch"))
 		os.Exit(1)
 	}
 
 	if !something {
-		Error("Nothing to do!")
+		ErrorMsg("pointer.nothing-to-do")
 		os.Exit(1)
 	}
 }
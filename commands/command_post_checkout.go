@@ -1,10 +1,17 @@
 package commands
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/locking"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -18,33 +25,42 @@ import (
 //      In the case of a file being checked out, the pre/post SHA are the same
 //
 // This hook checks that files which are lockable and not locked are made read-only,
-// optimising that as best it can based on the available information.
+// optimising that as best it can based on the available information. With
+// lfs.verifyaftercheckout, it also checks that the files which were checked out
+// have the contents their pointers record.
 func postCheckoutCommand(cmd *cobra.Command, args []string) {
 	if len(args) != 3 {
-		Print("This should be run through Git's post-checkout hook.  Run `git lfs update` to install it.")
+		PrintMsg("hooks.post-checkout-usage")
 		os.Exit(1)
 	}
 
+	verify := cfg.Git.Bool("lfs.verifyaftercheckout", false)
+
 	// Skip entire hook if lockable read only feature is disabled
-	if !cfg.SetLockableFilesReadOnly() {
+	if !cfg.SetLockableFilesReadOnly() && !verify {
 		os.Exit(0)
 	}
 
 	requireGitVersion()
 
-	lockClient := newLockClient()
+	revChange := args[2] == "1" && args[0] != "0000000000000000000000000000000000000000"
 
-	// Skip this hook if no lockable patterns have been configured
-	if len(lockClient.GetLockablePatterns()) == 0 {
-		os.Exit(0)
-	}
+	if cfg.SetLockableFilesReadOnly() {
+		lockClient := newLockClient()
 
-	if args[2] == "1" && args[0] != "0000000000000000000000000000000000000000" {
-		postCheckoutRevChange(lockClient, args[0], args[1])
-	} else {
-		postCheckoutFileChange(lockClient)
+		// Skip this step if no lockable patterns have been configured
+		if len(lockClient.GetLockablePatterns()) > 0 {
+			if revChange {
+				postCheckoutRevChange(lockClient, args[0], args[1])
+			} else {
+				postCheckoutFileChange(lockClient)
+			}
+		}
 	}
 
+	if verify && !postCheckoutVerify(args[0], args[1], revChange) {
+		os.Exit(exitCodeCorrupt)
+	}
 }
 
 func postCheckoutRevChange(client *locking.Client, pre, post string) {
@@ -54,13 +70,13 @@ func postCheckoutRevChange(client *locking.Client, pre, post string) {
 	files, err := git.GetFilesChanged(pre, post)
 
 	if err != nil {
-		LoggedError(err, "Warning: post-checkout rev diff %v:%v failed: %v\nFalling back on full scan.", pre, post, err)
+		LoggedErrorMsg(err, "post-checkout.diff-failed", tr.Args{"Pre": pre, "Post": post, "Err": err})
 		postCheckoutFileChange(client)
 	}
 	tracerx.Printf("post-checkout: checking write flags on %v", files)
 	err = client.FixLockableFileWriteFlags(files)
 	if err != nil {
-		LoggedError(err, "Warning: post-checkout locked file check failed: %v", err)
+		LoggedErrorMsg(err, "post-checkout.locks-failed", tr.Args{"Err": err})
 	}
 
 }
@@ -71,8 +87,100 @@ func postCheckoutFileChange(client *locking.Client) {
 	// so we have to check the entire repo
 	err := client.FixAllLockableFileWriteFlags()
 	if err != nil {
-		LoggedError(err, "Warning: post-checkout locked file check failed: %v", err)
+		LoggedErrorMsg(err, "post-checkout.locks-failed", tr.Args{"Err": err})
+	}
+}
+
+// postCheckoutVerify checks that the Git LFS files of the commit "post" which
+// are in the working tree hash to the OIDs their pointers record, reporting
+// those which do not. After a branch is checked out, only the files which
+// changed since "pre" are checked; otherwise Git does not say which files it
+// wrote, so all of them are. Files which are still pointers, as when their
+// objects were not downloaded, are skipped. It returns whether every file
+// matched.
+func postCheckoutVerify(pre, post string, revChange bool) bool {
+	var changed tools.StringSet
+	if revChange {
+		files, err := git.GetFilesChanged(pre, post)
+		if err != nil {
+			LoggedErrorMsg(err, "post-checkout.diff-failed", tr.Args{"Pre": pre, "Post": post, "Err": err})
+		} else if len(files) == 0 {
+			return true
+		} else {
+			changed = tools.NewStringSetFromSlice(files)
+		}
+	}
+
+	var pointers []*lfs.WrappedPointer
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
+			return
+		}
+		if changed == nil || changed.Contains(p.Name) {
+			pointers = append(pointers, p)
+		}
+	})
+	if err := gitscanner.ScanTree(post); err != nil {
+		ExitWithError(err)
+	}
+	gitscanner.Close()
+
+	tracerx.Printf("post-checkout: verifying %d files", len(pointers))
+
+	ok := true
+	for _, p := range pointers {
+		matched, err := postCheckoutVerifyFile(p)
+		if err != nil {
+			LoggedErrorMsg(err, "post-checkout.verify-failed", tr.Args{"Name": p.Name, "Err": err})
+			ok = false
+		} else if !matched {
+			ErrorMsg("post-checkout.mismatch", tr.Args{"Name": p.Name, "Oid": p.Oid})
+			ok = false
+		}
+	}
+	if !ok {
+		ErrorMsg("post-checkout.some-failed")
+	}
+	return ok
+}
+
+// postCheckoutVerifyFile returns whether the working tree file of "p" has the
+// contents its pointer records, or is missing or still that pointer.
+func postCheckoutVerifyFile(p *lfs.WrappedPointer) (bool, error) {
+	f, err := os.Open(filepath.Join(cfg.LocalWorkingDir(), p.Name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	ptr, contents, err := lfs.DecodeFrom(f)
+	if err == nil && ptr.Oid == p.Oid {
+		return true, nil
+	}
+
+	oidHash := sha256.New()
+	if _, err := io.Copy(oidHash, contents); err != nil {
+		return false, err
+	}
+	return hex.EncodeToString(oidHash.Sum(nil)) == postCheckoutFileOid(p.Pointer), nil
+}
+
+// postCheckoutFileOid returns the OID of the contents of the working tree file
+// of "ptr", which is that of its object, unless extensions cleaned the file, in
+// which case the first extension recorded the OID of the file as it was.
+func postCheckoutFileOid(ptr *lfs.Pointer) string {
+	oid := ptr.Oid
+	priority := -1
+	for _, ext := range ptr.Extensions {
+		if priority < 0 || ext.Priority < priority {
+			oid, priority = ext.Oid, ext.Priority
+		}
 	}
+	return oid
 }
 
 func init() {
@@ -4,6 +4,7 @@ import (
 	"os"
 
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -37,13 +38,13 @@ func postCommitCommand(cmd *cobra.Command, args []string) {
 	files, err := git.GetFilesChanged("HEAD", "")
 
 	if err != nil {
-		LoggedError(err, "Warning: post-commit failed: %v", err)
+		LoggedErrorMsg(err, "post-commit.failed", tr.Args{"Err": err})
 		os.Exit(1)
 	}
 	tracerx.Printf("post-commit: checking write flags on %v", files)
 	err = lockClient.FixLockableFileWriteFlags(files)
 	if err != nil {
-		LoggedError(err, "Warning: post-commit locked file check failed: %v", err)
+		LoggedErrorMsg(err, "post-commit.locks-failed", tr.Args{"Err": err})
 	}
 
 }
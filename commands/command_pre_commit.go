@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/rubyist/tracerx"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// defaultMaxCommitSize is the size above which staged files must be
+	// tracked by Git LFS, unless lfs.maxcommitsize says otherwise.
+	defaultMaxCommitSize = "50MB"
+)
+
+// preCommitCommand is run through Git's pre-commit hook. The hook passes no
+// arguments.
+//
+// If lfs.precommitsizecheck is enabled, this hook aborts the commit if any
+// staged file is larger than lfs.maxcommitsize and does not match any pattern
+// tracked by Git LFS, since such a file was most likely added by accident.
+// Only the sizes of the staged blobs are read, so the check is cheap even for
+// very large files.
+//
+// If lfs.maxobjectsize is set, it also aborts the commit if any staged Git LFS
+// pointer in a tracked path refers to an object larger than that, which the
+// server would most likely reject on push.
+//
+// As with any pre-commit hook, it is skipped by `git commit --no-verify`.
+func preCommitCommand(cmd *cobra.Command, args []string) {
+	sizeCheck := cfg.Git.Bool("lfs.precommitsizecheck", false)
+	maxObject, hasMaxObject := maxObjectSize()
+	if !sizeCheck && !hasMaxObject {
+		os.Exit(0)
+	}
+
+	requireGitVersion()
+
+	var limit int64
+	if sizeCheck {
+		limit = maxCommitSize()
+	}
+
+	// tolerate errors getting ref so this works before first commit
+	ref, _ := git.CurrentRef()
+	scanIndexAt := "HEAD"
+	if ref == nil {
+		scanIndexAt = git.RefBeforeFirstCommit
+	}
+
+	scanner, err := lfs.NewDiffIndexScanner(scanIndexAt, true, false)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	objects, err := git.NewObjectScanner(cfg.OSEnv())
+	if err != nil {
+		ExitWithError(err)
+	}
+	defer objects.Close()
+
+	filter := git.GetAttributeFilter(cfg.LocalWorkingDir(), cfg.LocalGitDir())
+	tracked := func(name string) bool {
+		return len(filter.Include()) > 0 && filter.Allows(name)
+	}
+
+	var large, oversized []string
+	for scanner.Scan() {
+		entry := scanner.Entry()
+
+		switch entry.Status {
+		case lfs.StatusAddition, lfs.StatusCopy, lfs.StatusModification,
+			lfs.StatusRename, lfs.StatusTypeChange:
+		default:
+			continue
+		}
+
+		// Only regular files can be large; symbolic links and
+		// submodules cannot.
+		if !strings.HasPrefix(entry.DstMode, "100") {
+			continue
+		}
+
+		// DstName is only given for copies and renames.
+		name := entry.DstName
+		if len(name) == 0 {
+			name = entry.SrcName
+		}
+
+		if !objects.Scan(entry.DstSha) {
+			ExitWithError(objects.Err())
+		}
+
+		size := objects.Size()
+		tracerx.Printf("pre-commit: %s is %d bytes", name, size)
+
+		if tracked(name) {
+			if !hasMaxObject || size >= 1024 {
+				continue
+			}
+			ptr, err := lfs.DecodePointer(objects.Contents())
+			if err == nil && ptr.Size > maxObject {
+				oversized = append(oversized, fmt.Sprintf("  %s (%s)", name, humanize.FormatBytes(uint64(ptr.Size))))
+			}
+			continue
+		}
+
+		if sizeCheck && size > limit {
+			large = append(large, name)
+			Error("  %s (%s)", name, humanize.FormatBytes(uint64(size)))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		ExitWithError(err)
+	}
+
+	if len(large) > 0 {
+		ErrorMsg("pre-commit.too-large", tr.Args{"Max": humanize.FormatBytes(uint64(limit))})
+		ErrorMsg("pre-commit.track-them")
+		ErrorMsg("pre-commit.track-example", tr.Args{"Pattern": trackSuggestion(large[0])})
+		ErrorMsg("pre-commit.or-no-verify")
+	}
+
+	if len(oversized) > 0 {
+		if len(large) > 0 {
+			Error("")
+		}
+		Error(strings.Join(oversized, "\n"))
+		ErrorMsg("pre-commit.larger-than-max-object-size", tr.Args{"Max": humanize.FormatBytes(uint64(maxObject))})
+		ErrorMsg("pre-commit.remove-or-no-verify")
+	}
+
+	if len(large) > 0 || len(oversized) > 0 {
+		os.Exit(1)
+	}
+}
+
+// maxObjectSize returns the largest Git LFS object which may be committed, as
+// configured by lfs.maxobjectsize, and whether or not it is configured.
+func maxObjectSize() (int64, bool) {
+	value, ok := cfg.Git.Get("lfs.maxobjectsize")
+	if !ok || len(value) == 0 {
+		return 0, false
+	}
+
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		ExitMsg("common.invalid-max-object-size", tr.Args{"Value": value})
+	}
+	return int64(size), true
+}
+
+// maxCommitSize returns the size above which staged files must be tracked by
+// Git LFS, as configured by lfs.maxcommitsize.
+func maxCommitSize() int64 {
+	value, ok := cfg.Git.Get("lfs.maxcommitsize")
+	if !ok || len(value) == 0 {
+		value = defaultMaxCommitSize
+	}
+
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		ExitMsg("pre-commit.invalid-max-commit-size", tr.Args{"Value": value})
+	}
+	return int64(size)
+}
+
+// trackSuggestion returns a pattern to suggest tracking "name" with, which is
+// its extension if it has one, or otherwise the file itself.
+func trackSuggestion(name string) string {
+	if ext := path.Ext(name); len(ext) > 0 {
+		return "*" + ext
+	}
+	return name
+}
+
+func init() {
+	RegisterCommand("pre-commit", preCommitCommand, nil)
+}
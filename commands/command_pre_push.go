@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -40,7 +41,7 @@ var (
 // made.
 func prePushCommand(cmd *cobra.Command, args []string) {
 	if len(args) == 0 {
-		Print("This should be run through Git's pre-push hook.  Run `git lfs update` to install it.")
+		PrintMsg("hooks.pre-push-usage")
 		os.Exit(1)
 	}
 
@@ -49,7 +50,7 @@ func prePushCommand(cmd *cobra.Command, args []string) {
 	// Remote is first arg
 	remote, _ := git.MapRemoteURL(args[0], true)
 	if err := cfg.SetValidPushRemote(remote); err != nil {
-		Exit("Invalid remote name %q: %s", args[0], err)
+		ExitMsg("common.invalid-remote", tr.Args{"Remote": args[0], "Err": err})
 	}
 
 	ctx := newUploadContext(prePushDryRun)
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/git-lfs/git-lfs/lfsproxy"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyListen string
+	proxyToken  string
+)
+
+// proxyCommand runs an HTTP proxy which adds a Bearer token to Batch API
+// requests, until it is interrupted or the process which started it exits.
+func proxyCommand(cmd *cobra.Command, args []string) {
+	token := proxyToken
+	if len(token) == 0 {
+		token = os.Getenv("GIT_LFS_PROXY_TOKEN")
+	}
+
+	l, err := net.Listen("tcp", proxyListen)
+	if err != nil {
+		ExitMsg("proxy.listen-failed", tr.Args{"Address": proxyListen, "Err": err})
+	}
+
+	go exitWithParent()
+
+	ErrorMsg("proxy.proxying", tr.Args{"Address": l.Addr()})
+	if err := http.Serve(l, &lfsproxy.Proxy{Token: token}); err != nil {
+		ExitWithError(err)
+	}
+}
+
+func init() {
+	RegisterCommand("proxy", proxyCommand, func(cmd *cobra.Command) {
+		cmd.PreRun = nil
+		cmd.Flags().StringVar(&proxyListen, "listen", "127.0.0.1:8081", "The address to listen on")
+		cmd.Flags().StringVar(&proxyToken, "token", "", "The Bearer token to add to Batch API requests")
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/git-lfs/git-lfs/tools"
 	"github.com/git-lfs/git-lfs/tools/humanize"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/semaphore"
@@ -24,21 +25,38 @@ import (
 
 var (
 	pruneDryRunArg      bool
-	pruneVerboseArg     bool
 	pruneVerifyArg      bool
 	pruneDoNotVerifyArg bool
+	pruneForceSharedArg bool
+	pruneNotAccessedIn  string
 )
 
 func pruneCommand(cmd *cobra.Command, args []string) {
 	// Guts of this must be re-usable from fetch --prune so just parse & dispatch
 	if pruneVerifyArg && pruneDoNotVerifyArg {
-		Exit("Cannot specify both --verify-remote and --no-verify-remote")
+		ExitMsg("prune.verify-remote-conflict")
 	}
-
 	fetchPruneConfig := lfs.NewFetchPruneConfig(cfg.Git)
+	if len(pruneNotAccessedIn) > 0 {
+		d, err := parseAge(pruneNotAccessedIn)
+		if err != nil {
+			ExitMsg("prune.invalid-not-accessed-in", tr.Args{"Err": err})
+		}
+		fetchPruneConfig.PruneNotAccessedIn = d
+	}
 	verify := !pruneDoNotVerifyArg &&
 		(fetchPruneConfig.PruneVerifyRemoteAlways || pruneVerifyArg)
-	prune(fetchPruneConfig, verify, pruneDryRunArg, pruneVerboseArg)
+	prune(fetchPruneConfig, verify, pruneDryRunArg, isVerbose())
+}
+
+// pruneCheckSharedStorage exits if the object store may be shared with other
+// repositories through lfs.storage, since prune only knows which objects this
+// repository refers to, and would delete those the others still need.
+func pruneCheckSharedStorage() {
+	if !cfg.Filesystem().IsSharedStorage() {
+		return
+	}
+	ExitMsg("prune.shared-storage", tr.Args{"Dir": cfg.LFSStorageDir()})
 }
 
 type PruneProgressType int
@@ -56,13 +74,18 @@ type PruneProgress struct {
 }
 type PruneProgressChan chan PruneProgress
 
+// prune deletes the local objects which are not retained by fetchPruneConfig.
+// It exits without deleting anything if the object store may be shared with
+// other repositories, unless --force-shared was given to git lfs prune.
 func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, dryRun, verbose bool) {
+	if !pruneForceSharedArg && !dryRun {
+		pruneCheckSharedStorage()
+	}
+
 	localObjects := make([]fs.Object, 0, 100)
 	retainedObjects := tools.NewStringSetWithCapacity(100)
 
-	logger := tasklog.NewLogger(OutputWriter,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	logger := newLogger(OutputWriter)
 	defer logger.Close()
 
 	var reachableObjects tools.StringSet
@@ -153,8 +176,16 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, dryRun, verbose
 		}()
 	}
 
+	var accessTimes map[string]time.Time
+	if fetchPruneConfig.PruneNotAccessedIn > 0 {
+		var err error
+		if accessTimes, err = cfg.Filesystem().AccessTimes(); err != nil {
+			ExitMsg("common.access-times-failed", tr.Args{"Err": err})
+		}
+	}
+
 	for _, file := range localObjects {
-		if !retainedObjects.Contains(file.Oid) {
+		if !retainedObjects.Contains(file.Oid) && !pruneRecentlyAccessed(accessTimes, file.Oid, fetchPruneConfig.PruneNotAccessedIn) {
 			prunableObjects = append(prunableObjects, file.Oid)
 			totalSize += file.Size
 			if verbose {
@@ -208,6 +239,26 @@ func prune(fetchPruneConfig lfs.FetchPruneConfig, verifyRemote, dryRun, verbose
 	}
 }
 
+// pruneRecentlyAccessed returns whether the object "oid" was read within the
+// given duration, and so should be kept by `prune --not-accessed-in`. Objects
+// whose last access cannot be determined are kept.
+func pruneRecentlyAccessed(accessTimes map[string]time.Time, oid string, notAccessedIn time.Duration) bool {
+	if notAccessedIn <= 0 {
+		return false
+	}
+
+	t, err := cfg.Filesystem().LastAccessed(accessTimes, oid)
+	if err != nil {
+		tracerx.Printf("prune: could not determine when %s was last accessed: %s", oid, err)
+		return true
+	}
+	if time.Since(t) < notAccessedIn {
+		tracerx.Printf("RECENTLY ACCESSED: %v", oid)
+		return true
+	}
+	return false
+}
+
 func pruneCheckVerified(prunableObjects []string, reachableObjects, verifiedObjects tools.StringSet) {
 	// There's no issue if an object is not reachable and missing, only if reachable & missing
 	var problems bytes.Buffer
@@ -227,16 +278,16 @@ func pruneCheckVerified(prunableObjects []string, reachableObjects, verifiedObje
 	// deleted but that's incorrect; bad state has occurred somehow, might need
 	// push --all to resolve
 	if problems.Len() > 0 {
-		Exit("Abort: these objects to be pruned are missing on remote:\n%v", problems.String())
+		ExitMsg("prune.missing-on-remote", tr.Args{"Objects": problems.String()})
 	}
 }
 
 func pruneCheckErrors(taskErrors []error) {
 	if len(taskErrors) > 0 {
 		for _, err := range taskErrors {
-			LoggedError(err, "Prune error: %v", err)
+			LoggedErrorMsg(err, "prune.error", tr.Args{"Err": err})
 		}
-		Exit("Prune sub-tasks failed, cannot continue")
+		ExitMsg("prune.sub-tasks-failed")
 	}
 }
 
@@ -296,23 +347,32 @@ func pruneDeleteFiles(prunableObjects []string, logger *tasklog.Logger) {
 	var problems bytes.Buffer
 	// In case we fail to delete some
 	var deletedFiles int
+	deleted := make([]string, 0, len(prunableObjects))
 	for _, oid := range prunableObjects {
 		mediaFile, err := cfg.Filesystem().ObjectPath(oid)
 		if err != nil {
 			problems.WriteString(fmt.Sprintf("Unable to find media path for %v: %v\n", oid, err))
 			continue
 		}
+		info, _ := os.Stat(mediaFile)
 		err = os.Remove(mediaFile)
 		if err != nil {
 			problems.WriteString(fmt.Sprintf("Failed to remove file %v: %v\n", mediaFile, err))
 			continue
 		}
+		if info != nil {
+			cfg.Filesystem().RecordObjectRemoved(info.Size())
+		}
 		deletedFiles++
+		deleted = append(deleted, oid)
 		task.Count(1)
 	}
+	if err := cfg.Filesystem().ForgetAccesses(deleted); err != nil {
+		tracerx.Printf("prune: could not update the access index: %s", err)
+	}
 	if problems.Len() > 0 {
 		LoggedError(fmt.Errorf("failed to delete some files"), problems.String())
-		Exit("Prune failed, see errors above")
+		ExitMsg("prune.failed")
 	}
 }
 
@@ -397,7 +457,7 @@ func pruneTaskGetRetainedCurrentAndRecentRefs(gitscanner *lfs.GitScanner, fetchc
 		// Keep all recent refs including any recent remote branches
 		refs, err := git.RecentBranches(refsSince, fetchconf.FetchRecentRefsIncludeRemotes, "")
 		if err != nil {
-			Panic(err, "Could not scan for recent refs")
+			PanicMsg(err, "fetch.could-not-scan-recent")
 		}
 		for _, ref := range refs {
 			if commits.Add(ref.Sha) {
@@ -499,8 +559,9 @@ func pruneTaskGetReachableObjects(gitscanner *lfs.GitScanner, outObjectSet *tool
 func init() {
 	RegisterCommand("prune", pruneCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&pruneDryRunArg, "dry-run", "d", false, "Don't delete anything, just report")
-		cmd.Flags().BoolVarP(&pruneVerboseArg, "verbose", "v", false, "Print full details of what is/would be deleted")
 		cmd.Flags().BoolVarP(&pruneVerifyArg, "verify-remote", "c", false, "Verify that remote has LFS files before deleting")
 		cmd.Flags().BoolVar(&pruneDoNotVerifyArg, "no-verify-remote", false, "Override lfs.pruneverifyremotealways and don't verify")
+		cmd.Flags().BoolVar(&pruneForceSharedArg, "force-shared", false, "Prune even if the object store is shared through lfs.storage")
+		cmd.Flags().StringVar(&pruneNotAccessedIn, "not-accessed-in", "", "Only prune objects which have not been read for this long, such as \"180d\"")
 	})
 }
@@ -6,50 +6,60 @@ import (
 	"sync"
 	"time"
 
+	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
-	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
 
+var pullForceArg bool
+
 func pullCommand(cmd *cobra.Command, args []string) {
 	requireGitVersion()
 	requireInRepo()
+	requireTempDir()
 
 	if len(args) > 0 {
 		// Remote is first arg
 		if err := cfg.SetValidRemote(args[0]); err != nil {
-			Exit("Invalid remote name %q: %s", args[0], err)
+			ExitMsg("common.invalid-remote", tr.Args{"Remote": args[0], "Err": err})
 		}
 	}
 
 	includeArg, excludeArg := getIncludeExcludeArgs(cmd)
 	filter := buildFilepathFilter(cfg, includeArg, excludeArg, true)
-	pull(filter)
+	if err := pull(filter); err != nil {
+		ExitWithError(err)
+	}
 }
 
-func pull(filter *filepathfilter.Filter) {
+// pull downloads the objects of the Git LFS files matching "filter" in the
+// current ref, in parallel, and checks each one out as it arrives. It returns
+// an error if not every object could be downloaded, after reporting the error
+// for each object, so that the caller can decide how to exit.
+func pull(filter *filepathfilter.Filter) error {
 	ref, err := git.CurrentRef()
 	if err != nil {
-		Panic(err, "Could not pull")
+		PanicMsg(err, "pull.failed")
 	}
 
 	pointers := newPointerMap()
-	logger := tasklog.NewLogger(os.Stdout,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	logger := newLogger(os.Stdout)
 	meter := tq.NewMeter(cfg)
 	meter.Logger = meter.LoggerFromEnv(cfg.Os)
+	meter.Events = progressEvents()
 	logger.Enqueue(meter)
 	remote := cfg.Remote()
 	singleCheckout := newSingleCheckout(cfg.Git, remote)
 	q := newDownloadQueue(singleCheckout.Manifest(), remote, tq.WithProgress(meter))
+	var missing []*lfs.WrappedPointer
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			LoggedError(err, "Scanner error: %s", err)
+			LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
 			return
 		}
 
@@ -64,10 +74,8 @@ func pull(filter *filepathfilter.Filter) {
 			return
 		}
 
-		meter.Add(p.Size)
-		tracerx.Printf("fetch %v [%v]", p.Name, p.Oid)
 		pointers.Add(p)
-		q.Add(downloadTransfer(p))
+		missing = append(missing, p)
 	})
 
 	gitscanner.Filter = filter
@@ -88,7 +96,19 @@ func pull(filter *filepathfilter.Filter) {
 	processQueue := time.Now()
 	if err := gitscanner.ScanTree(ref.Sha); err != nil {
 		singleCheckout.Close()
-		ExitWithError(err)
+		return err
+	}
+
+	// Only start downloading once the whole tree has been scanned, so
+	// that there is room for all of the objects it needs.
+	if err := checkFreeSpace(missing, pullForceArg); err != nil {
+		singleCheckout.Close()
+		return err
+	}
+	for _, p := range missing {
+		meter.Add(p.Size)
+		tracerx.Printf("fetch %v [%v]", p.Name, p.Oid)
+		q.Add(downloadTransfer(p))
 	}
 
 	meter.Start()
@@ -108,12 +128,13 @@ func pull(filter *filepathfilter.Filter) {
 	if !success {
 		c := getAPIClient()
 		e := c.Endpoints.Endpoint("download", remote)
-		Exit("error: failed to fetch some objects from '%s'", e.Url)
+		return errors.Errorf("error: failed to fetch some objects from '%s'", e.Url)
 	}
 
 	if singleCheckout.Skip() {
-		fmt.Println("Skipping object checkout, Git LFS is not installed.")
+		fmt.Println(tr.Tr("pull.not-installed"))
 	}
+	return nil
 }
 
 // tracks LFS objects being downloaded, according to their unique OIDs.
@@ -154,5 +175,6 @@ func init() {
 	RegisterCommand("pull", pullCommand, func(cmd *cobra.Command) {
 		cmd.Flags().StringVarP(&includeArg, "include", "I", "", "Include a list of paths")
 		cmd.Flags().StringVarP(&excludeArg, "exclude", "X", "", "Exclude a list of paths")
+		cmd.Flags().BoolVar(&pullForceArg, "force", false, "Download even if it would leave less than lfs.minfreespace free")
 	})
 }
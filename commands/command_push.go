@@ -2,11 +2,15 @@ package commands
 
 import (
 	"os"
+	"sort"
+	"sync"
 
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 	"github.com/spf13/cobra"
 )
@@ -15,8 +19,13 @@ var (
 	pushDryRun    = false
 	pushObjectIDs = false
 	pushAll       = false
+	pushRemote    = ""
 	useStdin      = false
 
+	// pushValidateOnly is whether to only check that the remote has every
+	// object, rather than uploading the ones it does not.
+	pushValidateOnly = false
+
 	// shares some global vars and functions with command_pre_push.go
 )
 
@@ -25,38 +34,50 @@ var (
 //
 //   `<remote> <remote ref>`
 //
-// Remote must be a remote name, not a URL
+// Remote must be a remote name, not a URL. It may instead be given with
+// --remote, in which case the remote's own Git LFS endpoint is preferred over
+// lfs.url and lfs.pushurl.
 //
 // pushCommand calculates the git objects to send by comparing the range
 // of commits between the local and remote git servers.
 func pushCommand(cmd *cobra.Command, args []string) {
-	if len(args) == 0 {
-		Print("Specify a remote and a remote branch name (`git lfs push origin master`)")
-		os.Exit(1)
+	remote := pushRemote
+	if len(remote) == 0 {
+		if len(args) == 0 {
+			PrintMsg("push.specify-remote")
+			os.Exit(exitCodeUsage)
+		}
+		remote, args = args[0], args[1:]
 	}
 
 	requireGitVersion()
 
-	// Remote is first arg
-	if err := cfg.SetValidPushRemote(args[0]); err != nil {
-		Exit("Invalid remote name %q: %s", args[0], err)
+	if err := cfg.SetValidPushRemote(remote); err != nil {
+		ExitMsg("common.invalid-remote", tr.Args{"Remote": remote, "Err": err})
+	}
+	if len(pushRemote) > 0 {
+		getAPIClient().Endpoints.SetPreferredRemote(cfg.PushRemote())
 	}
 
-	ctx := newUploadContext(pushDryRun)
-	if pushObjectIDs {
-		if len(args) < 2 {
-			Print("Usage: git lfs push --object-id <remote> <lfs-object-id> [lfs-object-id] ...")
-			return
+	if pushValidateOnly {
+		if pushObjectIDs {
+			ExitMsg("push.validate-only-with-object-id")
 		}
 
-		uploadsWithObjectIDs(ctx, args[1:])
-	} else {
+		validateRemoteObjects(args)
+		return
+	}
+
+	ctx := newUploadContext(pushDryRun)
+	if pushObjectIDs {
 		if len(args) < 1 {
-			Print("Usage: git lfs push --dry-run <remote> [ref]")
+			PrintMsg("push.object-id-usage")
 			return
 		}
 
-		uploadsBetweenRefAndRemote(ctx, args[1:])
+		uploadsWithObjectIDs(ctx, args)
+	} else {
+		uploadsBetweenRefAndRemote(ctx, args)
 	}
 }
 
@@ -66,7 +87,7 @@ func uploadsBetweenRefAndRemote(ctx *uploadContext, refnames []string) {
 	updates, err := lfsPushRefs(refnames, pushAll)
 	if err != nil {
 		Error(err.Error())
-		Exit("Error getting local refs.")
+		ExitMsg("push.local-refs-error")
 	}
 
 	if err := uploadForRefUpdates(ctx, updates, pushAll); err != nil {
@@ -77,21 +98,19 @@ func uploadsBetweenRefAndRemote(ctx *uploadContext, refnames []string) {
 func uploadsWithObjectIDs(ctx *uploadContext, oids []string) {
 	pointers := make([]*lfs.WrappedPointer, len(oids))
 	for i, oid := range oids {
-		mp, err := ctx.gitfilter.ObjectPath(oid)
-		if err != nil {
-			ExitWithError(errors.Wrap(err, "Unable to find local media path:"))
-		}
-
-		stat, err := os.Stat(mp)
+		meta, err := lfs.StatObject(cfg, oid)
 		if err != nil {
 			ExitWithError(errors.Wrap(err, "Unable to stat local media path"))
 		}
+		if !meta.Exists {
+			ExitWithError(errors.Errorf("Unable to stat local media path: %s does not exist", meta.Path))
+		}
 
 		pointers[i] = &lfs.WrappedPointer{
-			Name: mp,
+			Name: meta.Path,
 			Pointer: &lfs.Pointer{
 				Oid:  oid,
-				Size: stat.Size(),
+				Size: meta.Size,
 			},
 		}
 	}
@@ -102,6 +121,108 @@ func uploadsWithObjectIDs(ctx *uploadContext, oids []string) {
 	ctx.ReportErrors()
 }
 
+// validateRemoteObjects checks that the push remote has every object
+// referenced by any commit reachable from the given refs, from every local
+// ref with --all, or from the current branch otherwise, without uploading
+// anything. It lists the objects which the remote does not have, and exits if
+// there are any.
+func validateRemoteObjects(refnames []string) {
+	remote := cfg.PushRemote()
+	if len(refnames) == 0 && !pushAll {
+		ref := cfg.CurrentRef()
+		if len(ref.Sha) == 0 {
+			ExitMsg("push.validate-specify-ref")
+		}
+		refnames = []string{ref.Name}
+	}
+	tracerx.Printf("Validate refs %v on remote %v", refnames, remote)
+
+	updates, err := lfsPushRefs(refnames, pushAll)
+	if err != nil {
+		Error(err.Error())
+		ExitMsg("push.local-refs-error")
+	}
+
+	include := make([]string, 0, len(updates))
+	for _, update := range updates {
+		include = append(include, update.Left().Sha)
+	}
+
+	pointers := make(map[string]*lfs.WrappedPointer)
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			ExitWithError(err)
+		}
+		if _, ok := pointers[p.Oid]; !ok {
+			pointers[p.Oid] = p
+		}
+	})
+	if len(include) > 0 {
+		if err := gitscanner.ScanRefs(include, nil, nil); err != nil {
+			ExitWithError(err)
+		}
+	}
+	gitscanner.Close()
+
+	q := newDownloadCheckQueue(
+		getTransferManifestOperationRemote("download", remote), remote)
+	watch := q.Watch()
+
+	present := tools.NewStringSetWithCapacity(len(pointers))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for t := range watch {
+			present.Add(t.Oid)
+		}
+	}()
+
+	for _, p := range pointers {
+		q.Add(downloadTransfer(p))
+	}
+	q.Wait()
+	wg.Wait()
+
+	// An object which the remote does not have is reported as an error
+	// for it, and listed below, but any other error means that whether
+	// the remote has the objects is not known.
+	failed := false
+	for _, err := range q.Errors() {
+		if exitCodeFor(err) != exitCodeNotFound {
+			failed = true
+			FullError(err)
+		}
+	}
+	if failed {
+		ExitMsg("push.validate-check-failed", tr.Args{"Remote": remote})
+	}
+
+	missing := make([]*lfs.WrappedPointer, 0)
+	for oid, p := range pointers {
+		if !present.Contains(oid) {
+			missing = append(missing, p)
+		}
+	}
+
+	if len(missing) == 0 {
+		PrintMsg("push.validate-all-present", tr.Args{"Count": len(pointers)})
+		return
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].Name < missing[j].Name
+	})
+
+	PrintMsg("push.validate-missing")
+	for _, p := range missing {
+		PrintMsg("push.validate-missing-object", tr.Args{"Name": p.Name, "Oid": p.Oid})
+	}
+
+	recordExitCode(exitCodeNotFound)
+	ExitMsg("push.validate-missing-count", tr.Args{"Missing": len(missing), "Count": len(pointers)})
+}
+
 // lfsPushRefs returns valid ref updates from the given ref and --all arguments.
 // Either one or more refs can be explicitly specified, or --all indicates all
 // local refs are pushed.
@@ -142,5 +263,7 @@ func init() {
 		cmd.Flags().BoolVarP(&pushDryRun, "dry-run", "d", false, "Do everything except actually send the updates")
 		cmd.Flags().BoolVarP(&pushObjectIDs, "object-id", "o", false, "Push LFS object ID(s)")
 		cmd.Flags().BoolVarP(&pushAll, "all", "a", false, "Push all objects for the current ref to the remote.")
+		cmd.Flags().StringVarP(&pushRemote, "remote", "r", "", "Push to the Git LFS endpoint of this remote, even if lfs.url is set.")
+		cmd.Flags().BoolVar(&pushValidateOnly, "validate-only", false, "Check that the remote has every object, without uploading any.")
 	})
 }
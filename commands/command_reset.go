@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"os"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resetHard  bool
+	resetAll   bool
+	resetForce bool
+)
+
+// resetCommand removes the local copies of the Git LFS objects in HEAD at the
+// given paths (or of every object, with --all), so that they are downloaded
+// again the next time they are checked out. Objects which the remote does not
+// have are kept, unless --force is given. With --hard, they are downloaded
+// and checked out into the working tree immediately.
+func resetCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	if len(args) == 0 && !resetAll {
+		ExitMsg("reset.usage")
+	} else if len(args) > 0 && resetAll {
+		ExitMsg("reset.all-with-paths")
+	}
+
+	// Other repositories may need objects which HEAD does not refer to,
+	// and cannot download them again if the remote of this one lacks them.
+	if cfg.Filesystem().IsSharedStorage() {
+		ExitMsg("reset.shared-storage", tr.Args{"Dir": cfg.LFSStorageDir()})
+	}
+
+	var filter *filepathfilter.Filter
+	if !resetAll {
+		filter = filepathfilter.New(rootedPaths(args), nil)
+	}
+
+	pointers, err := resetPointersAt("HEAD", filter)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	if !resetAll && len(pointers) == 0 {
+		ExitMsg("reset.no-match")
+	}
+
+	pathConverter, err := lfs.NewRepoToCurrentPathConverter(cfg)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	// Whether each file may be replaced is decided before its object is
+	// removed, as its contents are compared with those of the object.
+	modified := make(map[string]bool)
+	if resetHard && !resetForce {
+		for _, p := range pointers {
+			unmodified, err := resetIsUnmodified(pathConverter.Convert(p.Name), p.Pointer)
+			if err != nil {
+				ExitWithError(err)
+			}
+			modified[p.Name] = !unmodified
+		}
+	}
+
+	var objects []fs.Object
+	if resetAll {
+		objects, err = resetAllObjects()
+	} else {
+		objects = resetObjectsOf(pointers)
+	}
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	// Objects are only removed if they can be downloaded again.
+	if !resetForce {
+		objects = resetOnRemote(objects)
+	}
+
+	removed, size, err := resetObjects(objects)
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	InfoMsg("reset.removed", tr.Args{"Count": len(removed), "Size": humanize.FormatBytes(uint64(size))})
+
+	if !resetHard {
+		return
+	}
+
+	// Replace each file whose object was removed with its pointer, so that
+	// checking it out downloads the object again. Files with changes of
+	// their own are left alone, and so are not checked out either.
+	for _, p := range pointers {
+		if _, ok := removed[p.Oid]; !ok {
+			continue
+		}
+		if modified[p.Name] {
+			ErrorMsg("reset.modified", tr.Args{"Name": p.Name})
+			continue
+		}
+		if err := resetWorkingCopy(pathConverter.Convert(p.Name), p.Pointer); err != nil {
+			ExitWithError(err)
+		}
+	}
+
+	if err := pull(filter); err != nil {
+		ExitWithError(err)
+	}
+}
+
+// resetPointersAt returns the Git LFS pointers in the tree at "ref" which match
+// "filter".
+func resetPointersAt(ref string, filter *filepathfilter.Filter) ([]*lfs.WrappedPointer, error) {
+	var pointers []*lfs.WrappedPointer
+	var scanErr error
+
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+		pointers = append(pointers, p)
+	})
+	defer gitscanner.Close()
+
+	gitscanner.Filter = filter
+
+	// tolerate errors getting ref so this works before first commit
+	if current, _ := git.CurrentRef(); current == nil {
+		return nil, nil
+	}
+
+	if err := gitscanner.ScanTree(ref); err != nil {
+		return nil, err
+	}
+	return pointers, scanErr
+}
+
+// resetObjectsOf returns the objects of "pointers", each once.
+func resetObjectsOf(pointers []*lfs.WrappedPointer) []fs.Object {
+	objects := make([]fs.Object, 0, len(pointers))
+	seen := make(map[string]struct{})
+	for _, p := range pointers {
+		if _, ok := seen[p.Oid]; ok {
+			continue
+		}
+		seen[p.Oid] = struct{}{}
+		objects = append(objects, fs.Object{Oid: p.Oid, Size: p.Size})
+	}
+	return objects
+}
+
+// resetAllObjects returns every object in the local store.
+func resetAllObjects() ([]fs.Object, error) {
+	var objects []fs.Object
+	err := cfg.Filesystem().EachObject(func(obj fs.Object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	return objects, err
+}
+
+// resetOnRemote returns those of "objects" which the remote has, warning
+// about the others, which are the only copies and so are kept.
+func resetOnRemote(objects []fs.Object) []fs.Object {
+	if len(objects) == 0 {
+		return objects
+	}
+
+	remote := cfg.Remote()
+	q := newDownloadCheckQueue(getTransferManifestOperationRemote("download", remote), remote)
+
+	// The transfers for which Check() succeeded are those of objects which
+	// the remote has.
+	watch := q.Watch()
+	onRemote := tools.NewStringSet()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		for t := range watch {
+			onRemote.Add(t.Oid)
+		}
+		wg.Done()
+	}()
+
+	for _, obj := range objects {
+		q.Add(downloadTransfer(&lfs.WrappedPointer{
+			Pointer: lfs.NewPointer(obj.Oid, obj.Size, nil),
+		}))
+	}
+	q.Wait()
+	wg.Wait()
+
+	kept := 0
+	verified := objects[:0]
+	for _, obj := range objects {
+		if onRemote.Contains(obj.Oid) {
+			verified = append(verified, obj)
+			continue
+		}
+		Debug("Keeping %s, which %s does not have", obj.Oid, remote)
+		kept++
+	}
+	if kept > 0 {
+		ErrorMsg("reset.not-on-remote", tr.Args{"Count": kept, "Remote": remote})
+	}
+	return verified
+}
+
+// resetObjects removes "objects" from the local store, returning the OIDs and
+// total size of those removed.
+func resetObjects(objects []fs.Object) (removed map[string]struct{}, size int64, err error) {
+	removed = make(map[string]struct{})
+	for _, obj := range objects {
+		ok, err := resetObject(obj.Oid)
+		if err != nil {
+			return removed, size, err
+		}
+		if ok {
+			removed[obj.Oid] = struct{}{}
+			size += obj.Size
+		}
+	}
+	return removed, size, nil
+}
+
+func resetObject(oid string) (bool, error) {
+	Debug("Removing %s", cfg.Filesystem().ObjectPathname(oid))
+
+	return cfg.Filesystem().RemoveObject(oid)
+}
+
+// resetIsUnmodified returns whether the file at "path" may be replaced by the
+// pointer "p" without losing changes: that is, whether it is missing, is not a
+// regular file, or holds "p", its contents, or those of its cached object,
+// which may be corrupt.
+func resetIsUnmodified(path string, p *lfs.Pointer) (bool, error) {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	if !fi.Mode().IsRegular() {
+		// Neither symbolic links nor what they point to are replaced.
+		return true, nil
+	}
+
+	if ptr, err := lfs.DecodePointerFromFile(path); err == nil {
+		return ptr.Oid == p.Oid, nil
+	}
+
+	oid, err := fsckHashFile(path, -1)
+	if err != nil {
+		return false, err
+	}
+	if oid == resetContentOid(p) {
+		return true, nil
+	}
+	if len(p.Extensions) > 0 {
+		// The cached object is not the file's contents.
+		return false, nil
+	}
+
+	cachedOid, err := fsckHashFile(cfg.Filesystem().ObjectPathname(p.Oid), -1)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return oid == cachedOid, nil
+}
+
+// resetContentOid returns the OID of the contents of the file which "p" was
+// cleaned from: the input of its first extension, if it has any.
+func resetContentOid(p *lfs.Pointer) string {
+	oid := p.Oid
+	priority := -1
+	for _, ext := range p.Extensions {
+		if priority < 0 || ext.Priority < priority {
+			oid, priority = ext.Oid, ext.Priority
+		}
+	}
+	return oid
+}
+
+// resetWorkingCopy replaces the file at "path" with the pointer "p". Missing
+// files and those which are not regular files are left alone.
+func resetWorkingCopy(path string, p *lfs.Pointer) error {
+	if fi, err := os.Lstat(path); os.IsNotExist(err) {
+		// Checking out restores missing files anyway.
+		return nil
+	} else if err != nil {
+		return err
+	} else if !fi.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = lfs.EncodePointer(f, p)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func init() {
+	RegisterCommand("reset", resetCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVar(&resetHard, "hard", false, "Download the removed objects and check them out again immediately.")
+		cmd.Flags().BoolVarP(&resetAll, "all", "a", false, "Remove every object from the local cache.")
+		cmd.Flags().BoolVarP(&resetForce, "force", "f", false, "Remove objects which the remote does not have, and with --hard, overwrite files which have been modified.")
+	})
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"github.com/git-lfs/git-lfs/lfsserver"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen  string
+	serveStorage string
+	serveAuth    string
+)
+
+// serveCommand runs a Git LFS server, which stores the objects of each
+// repository in a directory of the same name within the storage directory,
+// until it is killed.
+func serveCommand(cmd *cobra.Command, args []string) {
+	storage, err := filepath.Abs(serveStorage)
+	if err != nil {
+		ExitMsg("serve.invalid-storage", tr.Args{"Storage": serveStorage, "Err": err})
+	}
+
+	srv := &lfsserver.Server{Storage: storage}
+	if len(serveAuth) > 0 {
+		if srv.Auth, err = lfsserver.ReadHtpasswdFile(serveAuth); err != nil {
+			ExitMsg("serve.read-auth-failed", tr.Args{"Err": err})
+		}
+	}
+
+	ErrorMsg("serve.serving", tr.Args{"Dir": storage, "Address": serveListen})
+	if err := http.ListenAndServe(serveListen, srv); err != nil {
+		ExitWithError(err)
+	}
+}
+
+func init() {
+	RegisterCommand("serve", serveCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVar(&serveListen, "listen", "localhost:8080", "The address to listen on")
+		cmd.Flags().StringVar(&serveStorage, "storage", ".", "The directory in which to store objects")
+		cmd.Flags().StringVar(&serveAuth, "auth", "", "An htpasswd file of the users who may use the server")
+	})
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sizeRangeArg string
+	sizeJSONArg  bool
+)
+
+// sizeCommand reports the number and total size of the Git LFS objects in the
+// history of a ref, or those added by a range of commits. An object at more
+// than one path, or in more than one commit, is counted once.
+func sizeCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+
+	if len(args) > 1 {
+		ExitMsg("size.usage")
+	}
+	if len(args) > 0 && len(sizeRangeArg) > 0 {
+		ExitMsg("size.ref-with-range")
+	}
+
+	var include, exclude []string
+	if len(sizeRangeArg) > 0 {
+		parts := strings.SplitN(sizeRangeArg, "..", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 || strings.HasPrefix(parts[1], ".") {
+			ExitMsg("size.invalid-range", tr.Args{"Range": sizeRangeArg})
+		}
+		include = []string{sizeResolveRef(parts[1])}
+		exclude = []string{sizeResolveRef(parts[0])}
+	} else {
+		refName := "HEAD"
+		if len(args) > 0 {
+			refName = args[0]
+		}
+		include = []string{sizeResolveRef(refName)}
+	}
+
+	// Objects which are already in the history of <from> are not new,
+	// even if a commit in the range adds them again.
+	seen := make(map[string]bool)
+	if len(exclude) > 0 {
+		sizeScan(exclude, nil, func(p *lfs.WrappedPointer) {
+			seen[p.Oid] = true
+		})
+	}
+
+	var tally countObjectsTally
+	sizeScan(include, exclude, func(p *lfs.WrappedPointer) {
+		if !seen[p.Oid] {
+			seen[p.Oid] = true
+			tally.add(p.Size)
+		}
+	})
+
+	if sizeJSONArg {
+		out := struct {
+			Objects int64 `json:"objects"`
+			Size    int64 `json:"size"`
+		}{tally.count, tally.size}
+		if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	PrintMsg("size.total", tr.Args{
+		"Count":     tally.count,
+		"Size":      tally.size,
+		"HumanSize": humanize.FormatBytes(uint64(tally.size)),
+	})
+}
+
+// sizeScan calls "fn" with each pointer in the commits reachable from
+// "include" but not from "exclude", or exits if they cannot be scanned.
+func sizeScan(include, exclude []string, fn func(p *lfs.WrappedPointer)) {
+	var scanErr error
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			scanErr = err
+			return
+		}
+		fn(p)
+	})
+	defer gitscanner.Close()
+
+	if err := gitscanner.ScanRefs(include, exclude, nil); err != nil {
+		ExitWithError(err)
+	}
+	if scanErr != nil {
+		ExitWithError(scanErr)
+	}
+}
+
+// sizeResolveRef returns the SHA-1 of the commit which "name" refers to, or
+// exits if it does not refer to one.
+func sizeResolveRef(name string) string {
+	ref, err := git.ResolveRef(name)
+	if err != nil {
+		ExitMsg("fetch.invalid-ref", tr.Args{"Refs": name})
+	}
+	return ref.Sha
+}
+
+func init() {
+	RegisterCommand("size", sizeCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVar(&sizeRangeArg, "range", "", "Count only the objects added between two refs, as <from>..<to>")
+		cmd.Flags().BoolVarP(&sizeJSONArg, "json", "j", false, "Print the counts as JSON")
+	})
+}
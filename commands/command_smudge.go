@@ -12,6 +12,7 @@ import (
 	"github.com/git-lfs/git-lfs/tools"
 	"github.com/git-lfs/git-lfs/tools/humanize"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,13 @@ var (
 	// smudgeSkip is a command-line flag belonging to the "git-lfs smudge"
 	// command specifying whether to skip the smudge process.
 	smudgeSkip = false
+	// smudgeTo is the path of the file to which "git-lfs smudge" writes
+	// the smudged contents, rather than standard output, if set.
+	smudgeTo = ""
+	// smudgeForce is a command-line flag belonging to the "git-lfs smudge"
+	// command specifying whether to overwrite the file given by "--to" if
+	// it already exists.
+	smudgeForce = false
 )
 
 // delayedSmudge performs a 'delayed' smudge, adding the LFS pointer to the
@@ -58,19 +66,26 @@ func delayedSmudge(gf *lfs.GitFilter, s *git.FilterProcessScanner, to io.Writer,
 	}
 
 	if !skip && filter.Allows(filename) {
-		if _, statErr := os.Stat(path); statErr != nil {
+		// An object which cannot be smudged is not downloaded, but
+		// fails below instead.
+		extErr := gf.CheckExtensions(ptr, filename)
+		if _, statErr := os.Stat(path); statErr != nil && extErr == nil {
 			q.Add(filename, path, ptr.Oid, ptr.Size, false, err)
 			return 0, true, ptr, nil
 		}
 
 		// Write 'statusFromErr(nil)', since the object is already
-		// present in the local cache, we will write the object's
-		// contents without delaying.
+		// present in the local cache, or cannot be smudged at all, we
+		// will write the object's contents without delaying.
 		if err := s.WriteStatus(statusFromErr(nil)); err != nil {
 			return 0, false, nil, err
 		}
 
 		n, err := gf.Smudge(to, ptr, filename, false, nil, nil)
+		if extErr != nil {
+			// Git reports only that the filter failed, so say why.
+			Error(extErr.Error())
+		}
 		return n, false, ptr, err
 	}
 
@@ -113,6 +128,13 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 		return 0, nil
 	}
 
+	// An object which cannot be smudged is not downloaded, and its pointer
+	// is written instead.
+	if err := gf.CheckExtensions(ptr, filename); err != nil {
+		ptr.Encode(to)
+		return 0, errors.NewSmudgeError(err, ptr.Oid, filename)
+	}
+
 	lfs.LinkOrCopyFromReference(cfg, ptr.Oid, ptr.Size)
 	cb, file, err := gf.CopyCallbackFile("download", filename, 1, 1)
 	if err != nil {
@@ -131,6 +153,10 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 
 	if err != nil {
 		ptr.Encode(to)
+		if err == lfs.ErrTooManyQuarantined {
+			reportQuarantined(gf)
+			ExitWithError(err)
+		}
 		// Download declined error is ok to skip if we weren't requesting download
 		if !(errors.IsDownloadDeclinedError(err) && !download) {
 			var oid string = ptr.Oid
@@ -138,7 +164,7 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 				oid = oid[:7]
 			}
 
-			LoggedError(err, "Error downloading object: %s (%s): %s", filename, oid, err)
+			LoggedErrorMsg(err, "smudge.download-failed", tr.Args{"Name": filename, "Oid": oid, "Err": err})
 			if !cfg.SkipDownloadErrors() {
 				os.Exit(2)
 			}
@@ -150,23 +176,67 @@ func smudge(gf *lfs.GitFilter, to io.Writer, from io.Reader, filename string, sk
 
 func smudgeCommand(cmd *cobra.Command, args []string) {
 	requireStdin("This command should be run by the Git 'smudge' filter")
-	installHooks(false)
+	upgradeHooks()
+	requireTempDir()
 
 	if !smudgeSkip && cfg.Os.Bool("GIT_LFS_SKIP_SMUDGE", false) {
 		smudgeSkip = true
 	}
-	filter := filepathfilter.New(cfg.FetchIncludePaths(), cfg.FetchExcludePaths())
+	filter := buildFilepathFilter(cfg, nil, nil, true)
 	gitfilter := lfs.NewGitFilter(cfg)
 
-	if n, err := smudge(gitfilter, os.Stdout, os.Stdin, smudgeFilename(args), smudgeSkip, filter); err != nil {
+	filename := smudgeFilename(args)
+	var to io.Writer = os.Stdout
+	if len(smudgeTo) > 0 {
+		if len(args) == 0 {
+			filename = smudgeTo
+		}
+
+		file, err := openSmudgeTo(smudgeTo, smudgeForce)
+		if err != nil {
+			Exit("%s", err)
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				ExitMsg("smudge.write-failed", tr.Args{"Path": smudgeTo, "Err": err})
+			}
+		}()
+		to = file
+	}
+
+	if n, err := smudge(gitfilter, to, os.Stdin, filename, smudgeSkip, filter); err != nil {
 		if errors.IsNotAPointerError(err) {
 			fmt.Fprintln(os.Stderr, err.Error())
-		} else {
+		} else if errors.IsSmudgeError(err) {
+			// Git reports only that the filter failed, so say why.
 			Error(err.Error())
+			os.Exit(2)
+		} else {
+			ExitWithError(err)
 		}
 	} else if possiblyMalformedObjectSize(n) {
-		fmt.Fprintln(os.Stderr, "Possibly malformed smudge on Windows: see `git lfs help smudge` for more info.")
+		fmt.Fprintln(os.Stderr, tr.Tr("smudge.malformed-on-windows"))
+	}
+	reportQuarantined(gitfilter)
+}
+
+// openSmudgeTo creates the file at "path" for the smudged contents to be
+// written to. It returns an error if the file already exists, unless "force" is
+// set, in which case it is truncated.
+func openSmudgeTo(path string, force bool) (*os.File, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	file, err := os.OpenFile(path, flags, 0666)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errors.Errorf("%q already exists: use --force to overwrite it", path)
+		}
+		return nil, errors.Wrapf(err, "Could not create %q", path)
 	}
+	return file, nil
 }
 
 func smudgeFilename(args []string) string {
@@ -183,5 +253,7 @@ func possiblyMalformedObjectSize(n int64) bool {
 func init() {
 	RegisterCommand("smudge", smudgeCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&smudgeSkip, "skip", "s", false, "")
+		cmd.Flags().StringVar(&smudgeTo, "to", "", "")
+		cmd.Flags().BoolVarP(&smudgeForce, "force", "f", false, "")
 	})
 }
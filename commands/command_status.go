@@ -13,18 +13,24 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
 var (
-	porcelain  = false
-	statusJson = false
+	porcelain   = false
+	statusJson  = false
+	statusSizes = false
 )
 
 func statusCommand(cmd *cobra.Command, args []string) {
 	requireInRepo()
 	requireWorkingCopy()
 
+	if statusSizes && (porcelain || statusJson) {
+		ExitMsg("status.sizes-with-format")
+	}
+
 	// tolerate errors getting ref so this works before first commit
 	ref, _ := git.CurrentRef()
 
@@ -58,13 +64,29 @@ func statusCommand(cmd *cobra.Command, args []string) {
 
 	wd = tools.ResolveSymlinks(wd)
 
-	Print("\nObjects to be committed:\n")
+	var pointerTotal, sizeTotal int64
+
+	PrintMsg("status.to-be-committed")
 	for _, entry := range staged {
 		// Find a path from the current working directory to the
 		// absolute path of each side of the entry.
 		src := relativize(wd, filepath.Join(repo, entry.SrcName))
 		dst := relativize(wd, filepath.Join(repo, entry.DstName))
 
+		if statusSizes {
+			if pointerSize, size, ok := stagedPointerSizes(scanner, entry); ok {
+				switch entry.Status {
+				case lfs.StatusRename, lfs.StatusCopy:
+					Print("\t%s -> %s (%s)", src, dst, formatPointerSizes(pointerSize, size))
+				default:
+					Print("\t%s (%s)", src, formatPointerSizes(pointerSize, size))
+				}
+				pointerTotal += pointerSize
+				sizeTotal += size
+				continue
+			}
+		}
+
 		switch entry.Status {
 		case lfs.StatusRename, lfs.StatusCopy:
 			Print("\t%s -> %s (%s)", src, dst, formatBlobInfo(scanner, entry))
@@ -73,7 +95,11 @@ func statusCommand(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	Print("\nObjects not staged for commit:\n")
+	if statusSizes {
+		PrintMsg("status.total", tr.Args{"Total": formatPointerSizes(pointerTotal, sizeTotal)})
+	}
+
+	PrintMsg("status.not-staged")
 	for _, entry := range unstaged {
 		src := relativize(wd, filepath.Join(repo, entry.SrcName))
 
@@ -89,6 +115,34 @@ func statusCommand(cmd *cobra.Command, args []string) {
 
 var z40 = regexp.MustCompile(`\^?0{40}`)
 
+// stagedPointerSizes returns the size of the pointer staged by "entry", and the
+// size of the object it points to. If "entry" does not stage a Git LFS
+// pointer, "ok" is false.
+func stagedPointerSizes(s *lfs.PointerScanner, entry *lfs.DiffIndexEntry) (pointerSize, size int64, ok bool) {
+	if entry.Status == lfs.StatusDeletion || z40.MatchString(entry.DstSha) {
+		return 0, 0, false
+	}
+
+	s.Scan(entry.DstSha)
+	if err := s.Err(); err != nil {
+		if git.IsMissingObject(err) {
+			return 0, 0, false
+		}
+		ExitWithError(err)
+	}
+
+	if s.Pointer() == nil {
+		return 0, 0, false
+	}
+	return s.BlobSize(), s.Pointer().Size, true
+}
+
+// formatPointerSizes formats the size in bytes of a pointer and of the object
+// it points to, which are exact so that they may be compared and summed.
+func formatPointerSizes(pointerSize, size int64) string {
+	return fmt.Sprintf("%d pointer -> %d actual", pointerSize, size)
+}
+
 func formatBlobInfo(s *lfs.PointerScanner, entry *lfs.DiffIndexEntry) string {
 	fromSha, fromSrc, err := blobInfoFrom(s, entry)
 	if err != nil {
@@ -229,7 +283,7 @@ func statusScanRefRange(ref *git.Ref) {
 		return
 	}
 
-	Print("On branch %s", ref.Name)
+	PrintMsg("status.on-branch", tr.Args{"Branch": ref.Name})
 
 	remoteRef, err := cfg.GitConfig().CurrentRemoteRef()
 	if err != nil {
@@ -238,7 +292,7 @@ func statusScanRefRange(ref *git.Ref) {
 
 	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
 		if err != nil {
-			Panic(err, "Could not scan for Git LFS objects")
+			PanicMsg(err, "status.scan-failed")
 			return
 		}
 
@@ -246,9 +300,9 @@ func statusScanRefRange(ref *git.Ref) {
 	})
 	defer gitscanner.Close()
 
-	Print("Objects to be pushed to %s:\n", remoteRef.Name)
+	PrintMsg("status.to-be-pushed", tr.Args{"Remote": remoteRef.Name})
 	if err := gitscanner.ScanRefRange(ref.Sha, remoteRef.Sha, nil); err != nil {
-		Panic(err, "Could not scan for Git LFS objects")
+		PanicMsg(err, "status.scan-failed")
 	}
 
 }
@@ -369,5 +423,6 @@ func init() {
 	RegisterCommand("status", statusCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&porcelain, "porcelain", "p", false, "Give the output in an easy-to-parse format for scripts.")
 		cmd.Flags().BoolVarP(&statusJson, "json", "j", false, "Give the output in a stable json format for scripts.")
+		cmd.Flags().BoolVarP(&statusSizes, "sizes", "", false, "Show the size of each staged pointer and of the object it points to.")
 	})
 }
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncRemoteArg string
+)
+
+// syncCommand pushes the objects of every local branch which the remote may
+// not have, and then fetches the objects of the current and recent refs which
+// are missing locally, as `git lfs push` and `git lfs fetch --recent` would.
+func syncCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+	requireGitVersion()
+
+	if len(syncRemoteArg) > 0 {
+		if err := cfg.SetValidRemote(syncRemoteArg); err != nil {
+			ExitMsg("common.invalid-remote", tr.Args{"Remote": syncRemoteArg, "Err": err})
+		}
+		if err := cfg.SetValidPushRemote(syncRemoteArg); err != nil {
+			ExitMsg("common.invalid-remote", tr.Args{"Remote": syncRemoteArg, "Err": err})
+		}
+	}
+
+	before := syncCountLocalObjects()
+
+	InfoMsg("sync.checking-push", tr.Args{"Remote": cfg.PushRemote()})
+	pushed := syncPush()
+
+	InfoMsg("sync.fetching", tr.Args{"Remote": cfg.Remote()})
+	_, missesBefore, _ := cfg.Filesystem().CacheCounters()
+	if !syncFetch() {
+		ExitMsg("sync.fetch-failed", tr.Args{"Remote": cfg.Remote()})
+	}
+	_, missesAfter, _ := cfg.Filesystem().CacheCounters()
+
+	InfoMsg("sync.summary", tr.Args{"Pushed": pushed, "Fetched": missesAfter - missesBefore})
+	InfoMsg("sync.local-objects", tr.Args{"Before": before, "After": syncCountLocalObjects()})
+}
+
+// syncPush uploads the objects of every local branch which are not reachable
+// from the remote's branches, and returns how many the remote did not
+// already have.
+func syncPush() int {
+	localRefs, err := git.LocalRefs()
+	if err != nil {
+		ExitWithError(err)
+	}
+
+	var updates []*git.RefUpdate
+	for _, ref := range localRefs {
+		if ref.Type == git.RefTypeLocalBranch {
+			updates = append(updates, git.NewRefUpdate(cfg.Git, cfg.PushRemote(), ref, nil))
+		}
+	}
+
+	ctx := newUploadContext(false)
+	gitscanner, err := ctx.buildGitScanner()
+	if err != nil {
+		ExitWithError(err)
+	}
+	defer gitscanner.Close()
+
+	verifyLocksForUpdates(ctx.lockVerifier, updates)
+
+	// Find the objects to push as `git lfs push --dry-run` would, before
+	// pushing any of them.
+	bases := make([]string, 0, len(updates))
+	for _, update := range updates {
+		if right := update.Right().Sha; update.LeftCommitish() != right {
+			bases = append(bases, right)
+		}
+	}
+
+	seen := tools.NewStringSet()
+	var pointers []*lfs.WrappedPointer
+	for _, update := range updates {
+		err := gitscanner.ScanMultiRangeToRemote(update.LeftCommitish(), bases, func(p *lfs.WrappedPointer, err error) {
+			if err != nil {
+				ctx.addScannerError(err)
+			} else if seen.Add(p.Oid) {
+				pointers = append(pointers, p)
+			}
+		})
+		if err == nil {
+			err = ctx.scannerError()
+		}
+		if err != nil {
+			ExitWithError(err)
+		}
+	}
+
+	InfoMsg("sync.may-push", tr.Args{"Count": len(pointers)})
+	if len(pointers) == 0 {
+		return 0
+	}
+
+	// Objects which the remote already has are skipped by the queue, so
+	// only count those which were actually sent.
+	q := ctx.NewQueue(tq.RemoteRef(currentRemoteRef()))
+	pushed := tools.NewStringSet()
+	watch := q.Watch()
+	done := make(chan struct{})
+	go func() {
+		for t := range watch {
+			pushed.Add(t.Oid)
+		}
+		close(done)
+	}()
+
+	ctx.UploadPointers(q, pointers...)
+	ctx.CollectErrors(q)
+	<-done
+	ctx.ReportErrors()
+
+	return pushed.Cardinality()
+}
+
+// syncFetch downloads the missing objects of the current ref and of recent
+// refs and commits, as `git lfs fetch --recent` would.
+func syncFetch() bool {
+	ref, err := git.CurrentRef()
+	if err != nil {
+		PanicMsg(err, "fetch.could-not-fetch")
+	}
+
+	filter := buildFilepathFilter(cfg, nil, nil, true)
+
+	InfoMsg("fetch.fetching-ref", tr.Args{"Ref": ref.Refspec()})
+	ok := fetchRef(ref.Sha, filter)
+
+	recentOk := fetchRecent(lfs.NewFetchPruneConfig(cfg.Git), []*git.Ref{ref}, filter)
+	return ok && recentOk
+}
+
+func syncCountLocalObjects() int {
+	var n int
+	cfg.EachLFSObject(func(fs.Object) error {
+		n++
+		return nil
+	})
+	return n
+}
+
+func init() {
+	RegisterCommand("sync", syncCommand, func(cmd *cobra.Command) {
+		cmd.Flags().StringVarP(&syncRemoteArg, "remote", "r", "", "Push to and fetch from the given remote")
+	})
+}
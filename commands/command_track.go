@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/git/gitattr"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -21,30 +23,44 @@ var (
 		".git", ".lfs",
 	}
 
-	trackLockableFlag       bool
-	trackNotLockableFlag    bool
-	trackVerboseLoggingFlag bool
-	trackDryRunFlag         bool
-	trackNoModifyAttrsFlag  bool
-	trackNoExcludedFlag     bool
-	trackFilenameFlag       bool
+	trackLockableFlag      bool
+	trackNotLockableFlag   bool
+	trackDryRunFlag        bool
+	trackNoModifyAttrsFlag bool
+	trackNoExcludedFlag    bool
+	trackFilenameFlag      bool
+	trackRootFlag          bool
+	trackAttrsFlag         []string
+	trackAttributeFlag     []string
+
+	// trackReservedAttrs are the attributes which are always written for
+	// tracked patterns, and so cannot be given with --attr.
+	trackReservedAttrs = []string{
+		git.FilterAttrib, "diff", "merge", "text", git.LockableAttrib,
+	}
+
+	// trackLFSAttrs are the per-pattern Git LFS attributes, the only ones
+	// starting with "lfs-" which can be given with --attr.
+	trackLFSAttrs = []string{
+		git.CompressAttrib, git.FetchAttrib, git.VerifyAttrib, git.ExtAttrib,
+	}
 )
 
 func trackCommand(cmd *cobra.Command, args []string) {
 	requireGitVersion()
 
 	if cfg.LocalGitDir() == "" {
-		Print("Not a git repository.")
+		PrintMsg("track.not-a-repo")
 		os.Exit(128)
 	}
 
 	if cfg.LocalWorkingDir() == "" {
-		Print("This operation must be run in a work tree.")
+		PrintMsg("common.not-in-work-tree")
 		os.Exit(128)
 	}
 
 	if !cfg.Os.Bool("GIT_LFS_TRACK_NO_INSTALL_HOOKS", false) {
-		installHooks(false)
+		upgradeHooks()
 	}
 
 	if len(args) == 0 {
@@ -68,15 +84,58 @@ func trackCommand(cmd *cobra.Command, args []string) {
 	wd = tools.ResolveSymlinks(wd)
 	relpath, err := filepath.Rel(cfg.LocalWorkingDir(), wd)
 	if err != nil {
-		Exit("Current directory %q outside of git working directory %q.", wd, cfg.LocalWorkingDir())
+		ExitMsg("track.outside-work-tree", tr.Args{"Dir": wd, "WorkTree": cfg.LocalWorkingDir()})
+	}
+
+	extraAttrs, err := trackExtraAttrs(append(trackAttrsFlag, trackAttributeFlag...))
+	if err != nil {
+		Exit(err.Error())
+	}
+
+	// Patterns are written to the .gitattributes file in the current
+	// directory, as they are given, unless --root is given.
+	attributesPath := ".gitattributes"
+	if trackRootFlag {
+		attributesPath = filepath.Join(cfg.LocalWorkingDir(), ".gitattributes")
+	}
+
+	// Check that the file can be written before reporting any changes to
+	// it, or truncating it.
+	if !trackNoModifyAttrsFlag {
+		// A directory can't be written to, but the error from doing
+		// so would wrongly suggest checking file permissions.
+		if fi, err := os.Stat(attributesPath); err == nil && fi.IsDir() {
+			ExitMsg("track.attributes-is-dir")
+		}
+		if err := checkAttributesWritable(attributesPath); err != nil {
+			ExitMsg("track.attributes-not-writable", tr.Args{"Err": err})
+		}
 	}
 
 	changedAttribLines := make(map[string]string)
+	// cwdPatterns maps the patterns written to .gitattributes to the same
+	// patterns relative to the current directory, which differ with --root.
+	cwdPatterns := make(map[string]string)
 	var readOnlyPatterns []string
 	var writeablePatterns []string
+	// Git does not expand braces in .gitattributes patterns, so a pattern
+	// such as "*.{jpg,png}", quoted to keep the shell from expanding it,
+	// is written as one line for each alternative.
+	var patterns []string
+	for _, arg := range args {
+		if trackFilenameFlag {
+			patterns = append(patterns, arg)
+		} else {
+			patterns = append(patterns, expandBraces(arg)...)
+		}
+	}
+
 ArgsLoop:
-	for _, unsanitizedPattern := range args {
-		pattern := trimCurrentPrefix(cleanRootPath(unsanitizedPattern))
+	for _, unsanitizedPattern := range patterns {
+		// Patterns are written with forward slashes, which Git matches
+		// on every platform, so they are compared with the patterns
+		// already written, and searched for, with them too.
+		pattern := toSlashPattern(trimCurrentPrefix(cleanRootPath(unsanitizedPattern)))
 
 		// Generate the new / changed attrib line for merging
 		var encodedArg string
@@ -87,13 +146,31 @@ ArgsLoop:
 			encodedArg = escapeAttrPattern(pattern)
 		}
 
-		if !trackNoModifyAttrsFlag {
+		cwdPattern := pattern
+		existing := path.Join(filepath.ToSlash(relpath), pattern)
+		if trackRootFlag {
+			rel := filepath.ToSlash(relpath)
+			if trackFilenameFlag {
+				rel = escapeGlobCharacters(rel)
+			}
+			pattern = trackRootPattern(rel, pattern)
+			existing = pattern
+			if trackFilenameFlag {
+				encodedArg = pattern
+			} else {
+				encodedArg = escapeAttrPattern(pattern)
+			}
+		}
+
+		// Extra attributes always rewrite the line, since they may
+		// differ from those already written for the pattern.
+		if !trackNoModifyAttrsFlag && len(extraAttrs) == 0 {
 			for _, known := range knownPatterns {
-				if unescapeAttrPattern(known.Path) == filepath.Join(relpath, pattern) &&
+				if unescapeAttrPattern(known.Path) == existing &&
 					((trackLockableFlag && known.Lockable) || // enabling lockable & already lockable (no change)
 						(trackNotLockableFlag && !known.Lockable) || // disabling lockable & not lockable (no change)
 						(!trackLockableFlag && !trackNotLockableFlag)) { // leave lockable as-is in all cases
-					Print("%q already supported", pattern)
+					InfoMsg("track.already-supported", tr.Args{"Pattern": pattern})
 					continue ArgsLoop
 				}
 			}
@@ -104,15 +181,16 @@ ArgsLoop:
 			lockableArg = " " + git.LockableAttrib
 		}
 
-		changedAttribLines[pattern] = fmt.Sprintf("%s filter=lfs diff=lfs merge=lfs -text%v%s", encodedArg, lockableArg, lineEnd)
+		cwdPatterns[pattern] = cwdPattern
+		changedAttribLines[pattern] = fmt.Sprintf("%s filter=lfs diff=lfs merge=lfs -text%v%s%s", encodedArg, lockableArg, extraAttrs, lineEnd)
 
 		if trackLockableFlag {
-			readOnlyPatterns = append(readOnlyPatterns, pattern)
+			readOnlyPatterns = append(readOnlyPatterns, cwdPattern)
 		} else {
-			writeablePatterns = append(writeablePatterns, pattern)
+			writeablePatterns = append(writeablePatterns, cwdPattern)
 		}
 
-		Print("Tracking %q", unescapeAttrPattern(encodedArg))
+		InfoMsg("track.tracking", tr.Args{"Pattern": unescapeAttrPattern(encodedArg)})
 	}
 
 	// Now read the whole local attributes file and iterate over the contents,
@@ -124,16 +202,16 @@ ArgsLoop:
 		attributesFile *os.File
 	)
 	if !trackNoModifyAttrsFlag {
-		attribContents, err = ioutil.ReadFile(".gitattributes")
+		attribContents, err = ioutil.ReadFile(attributesPath)
 		// it's fine for file to not exist
 		if err != nil && !os.IsNotExist(err) {
-			Print("Error reading .gitattributes file")
+			PrintMsg("track.attributes-read-error")
 			return
 		}
 		// Re-generate the file with merge of old contents and new (to deal with changes)
-		attributesFile, err = os.OpenFile(".gitattributes", os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0660)
+		attributesFile, err = os.OpenFile(attributesPath, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 0660)
 		if err != nil {
-			Print("Error opening .gitattributes file")
+			PrintMsg("track.attributes-open-error")
 			return
 		}
 		defer attributesFile.Close()
@@ -181,23 +259,24 @@ ArgsLoop:
 		// Since all `git-lfs track` calls are relative to the root of
 		// the repository, the leading slash is simply removed for its
 		// implicit counterpart.
-		if trackVerboseLoggingFlag {
-			Print("Searching for files matching pattern: %s", pattern)
-		}
+		VerboseMsg("track.searching", tr.Args{"Pattern": pattern})
 
-		gittracked, err := git.GetTrackedFiles(pattern)
+		gittracked, err := git.GetTrackedFiles(cwdPatterns[pattern])
 		if err != nil {
-			Exit("Error getting tracked files for %q: %s", pattern, err)
+			ExitMsg("track.tracked-files-error", tr.Args{"Pattern": pattern, "Err": err})
 		}
 
-		if trackVerboseLoggingFlag {
-			Print("Found %d files previously added to Git matching pattern: %s", len(gittracked), pattern)
-		}
+		VerboseMsg("track.found", tr.Args{"Count": len(gittracked), "Pattern": pattern})
+
+		// Setting the times of a symbolic link would set those of its
+		// target, and Git stores the link itself, which is never
+		// cleaned, so links are left alone.
+		gittracked = trackSkipSymlinks(gittracked)
 
 		var matchedBlocklist bool
 		for _, f := range gittracked {
 			if forbidden := blocklistItem(f); forbidden != "" {
-				Print("Pattern %s matches forbidden file %s. If you would like to track %s, modify .gitattributes manually.", pattern, f, f)
+				PrintMsg("track.forbidden-file", tr.Args{"Pattern": pattern, "File": f})
 				matchedBlocklist = true
 			}
 		}
@@ -206,15 +285,15 @@ ArgsLoop:
 		}
 
 		for _, f := range gittracked {
-			if trackVerboseLoggingFlag || trackDryRunFlag {
-				Print("Git LFS: touching %q", f)
+			if isVerbose() || trackDryRunFlag {
+				PrintMsg("track.touching", tr.Args{"File": f})
 			}
 
 			if !trackDryRunFlag {
 				now := time.Now()
 				err := os.Chtimes(f, now, now)
 				if err != nil {
-					LoggedError(err, "Error marking %q modified: %s", f, err)
+					LoggedErrorMsg(err, "track.mark-modified-error", tr.Args{"File": f, "Err": err})
 					continue
 				}
 			}
@@ -225,7 +304,7 @@ ArgsLoop:
 	lockClient := newLockClient()
 	err = lockClient.FixFileWriteFlagsInDir(relpath, readOnlyPatterns, writeablePatterns)
 	if err != nil {
-		LoggedError(err, "Error changing lockable file permissions: %s", err)
+		LoggedErrorMsg(err, "track.lockable-permissions-error", tr.Args{"Err": err})
 	}
 }
 
@@ -235,10 +314,10 @@ func listPatterns() {
 		return
 	}
 
-	Print("Listing tracked patterns")
+	PrintMsg("track.listing-tracked")
 	for _, t := range knownPatterns {
 		if t.Lockable {
-			Print("    %s [lockable] (%s)", t.Path, t.Source)
+			PrintMsg("track.listing-lockable-pattern", tr.Args{"Pattern": t.Path, "Source": t.Source})
 		} else if t.Tracked {
 			Print("    %s (%s)", t.Path, t.Source)
 		}
@@ -248,7 +327,7 @@ func listPatterns() {
 		return
 	}
 
-	Print("Listing excluded patterns")
+	PrintMsg("track.listing-excluded")
 	for _, t := range knownPatterns {
 		if !t.Tracked && !t.Lockable {
 			Print("    %s (%s)", t.Path, t.Source)
@@ -280,6 +359,147 @@ func getAttributeLineEnding(attribs []git.AttributePath) string {
 	return ""
 }
 
+// trackExtraAttrs validates the "<key>=<value>" attributes given with --attr,
+// returning them in the form they are appended to a .gitattributes line.
+func trackExtraAttrs(attrs []string) (string, error) {
+	var extra string
+	for _, attr := range attrs {
+		parts := strings.SplitN(attr, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || strings.ContainsAny(attr, " \t") {
+			return "", fmt.Errorf("Invalid attribute %q: expected <key>=<value>", attr)
+		}
+
+		for _, reserved := range trackReservedAttrs {
+			if parts[0] == reserved {
+				return "", fmt.Errorf("Cannot set attribute %q: it is always written by Git LFS", parts[0])
+			}
+		}
+
+		if strings.HasPrefix(parts[0], "lfs-") && !trackIsLFSAttr(parts[0]) {
+			return "", fmt.Errorf("Unknown Git LFS attribute %q: expected one of %s", parts[0], strings.Join(trackLFSAttrs, ", "))
+		}
+
+		extra += " " + attr
+	}
+	return extra, nil
+}
+
+func trackIsLFSAttr(name string) bool {
+	for _, attr := range trackLFSAttrs {
+		if name == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAttributesWritable returns why the .gitattributes file at "path" cannot
+// be written by the current user, if it cannot, without modifying it. If it
+// does not exist, its directory must allow it to be created.
+func checkAttributesWritable(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		f, err = ioutil.TempFile(filepath.Dir(path), ".gitattributes")
+		if err == nil {
+			defer os.Remove(f.Name())
+		}
+	}
+	if err != nil {
+		if perr, ok := err.(*os.PathError); ok {
+			return perr.Err
+		}
+		return err
+	}
+	return f.Close()
+}
+
+// trackSkipSymlinks returns the files in "files" which are not symbolic links.
+func trackSkipSymlinks(files []string) []string {
+	regular := make([]string, 0, len(files))
+	for _, f := range files {
+		if fi, err := os.Lstat(f); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		regular = append(regular, f)
+	}
+	return regular
+}
+
+// trackRootPattern returns the pattern which matches, in the .gitattributes
+// file at the root of the repository, the files "pattern" matches in the
+// .gitattributes file of the directory "relpath". A pattern with no slash
+// matches at any depth, so "*.psd" in "src/assets" becomes
+// "src/assets/**/*.psd".
+func trackRootPattern(relpath, pattern string) string {
+	if relpath == "." || len(relpath) == 0 {
+		return pattern
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return relpath + pattern
+	}
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		return relpath + "/**/" + pattern
+	}
+	return relpath + "/" + pattern
+}
+
+// expandBraces expands the brace expressions in "pattern", as the shell does,
+// returning one pattern for each alternative, in order and without duplicates:
+// "*.{jpg,png}" becomes "*.jpg" and "*.png", and "{a,b{c,d}}" becomes "a",
+// "bc" and "bd". Braces which are unmatched, or which contain no comma, are
+// left as they are.
+func expandBraces(pattern string) []string {
+	expanded := make([]string, 0, 1)
+	seen := tools.NewStringSet()
+	for _, p := range expandBracesOnce(pattern) {
+		if seen.Add(p) {
+			expanded = append(expanded, p)
+		}
+	}
+	return expanded
+}
+
+func expandBracesOnce(pattern string) []string {
+	for open := 0; open < len(pattern); open++ {
+		if pattern[open] != '{' {
+			continue
+		}
+
+		// Find the matching closing brace, and the commas which
+		// separate the alternatives at this level.
+		depth, end := 0, -1
+		var commas []int
+		for i := open; i < len(pattern) && end < 0; i++ {
+			switch pattern[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 {
+					end = i
+				}
+			case ',':
+				if depth == 1 {
+					commas = append(commas, i)
+				}
+			}
+		}
+		if end < 0 || len(commas) == 0 {
+			continue
+		}
+
+		var expanded []string
+		start := open + 1
+		for _, sep := range append(commas, end) {
+			alt := pattern[:open] + pattern[start:sep] + pattern[end+1:]
+			expanded = append(expanded, expandBracesOnce(alt)...)
+			start = sep + 1
+		}
+		return expanded
+	}
+	return []string{pattern}
+}
+
 // blocklistItem returns the name of the blocklist item preventing the given
 // file-name from being tracked, or an empty string, if there is none.
 func blocklistItem(name string) string {
@@ -303,7 +523,7 @@ var (
 )
 
 func escapeGlobCharacters(s string) string {
-	var escaped string = strings.Replace(s, `\`, "/", -1)
+	var escaped string = toSlashPattern(s)
 
 	for _, ch := range trackEscapeStrings {
 		escaped = strings.Replace(escaped, ch, fmt.Sprintf("\\%s", ch), -1)
@@ -315,8 +535,15 @@ func escapeGlobCharacters(s string) string {
 	return escaped
 }
 
+// toSlashPattern returns "pattern" with its backslashes, which are path
+// separators on Windows, replaced by forward slashes, as they are written to
+// .gitattributes.
+func toSlashPattern(pattern string) string {
+	return strings.Replace(pattern, `\`, "/", -1)
+}
+
 func escapeAttrPattern(unescaped string) string {
-	var escaped string = strings.Replace(unescaped, `\`, "/", -1)
+	var escaped string = toSlashPattern(unescaped)
 
 	for from, to := range trackEscapePatterns {
 		escaped = strings.Replace(escaped, from, to, -1)
@@ -339,10 +566,12 @@ func init() {
 	RegisterCommand("track", trackCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&trackLockableFlag, "lockable", "l", false, "make pattern lockable, i.e. read-only unless locked")
 		cmd.Flags().BoolVarP(&trackNotLockableFlag, "not-lockable", "", false, "remove lockable attribute from pattern")
-		cmd.Flags().BoolVarP(&trackVerboseLoggingFlag, "verbose", "v", false, "log which files are being tracked and modified")
 		cmd.Flags().BoolVarP(&trackDryRunFlag, "dry-run", "d", false, "preview results of running `git lfs track`")
 		cmd.Flags().BoolVarP(&trackNoModifyAttrsFlag, "no-modify-attrs", "", false, "skip modifying .gitattributes file")
 		cmd.Flags().BoolVarP(&trackNoExcludedFlag, "no-excluded", "", false, "skip listing excluded paths")
 		cmd.Flags().BoolVarP(&trackFilenameFlag, "filename", "", false, "treat this pattern as a literal filename")
+		cmd.Flags().BoolVarP(&trackRootFlag, "root", "", false, "write the pattern to the .gitattributes file at the root of the repository")
+		cmd.Flags().StringArrayVarP(&trackAttrsFlag, "attr", "", nil, "write an extra <key>=<value> attribute for the pattern")
+		cmd.Flags().StringArrayVarP(&trackAttributeFlag, "attribute", "", nil, "write an extra <key>=<value> attribute for the pattern")
 	})
 }
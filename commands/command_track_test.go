@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandBracesSplitsAlternatives(t *testing.T) {
+	assert.Equal(t, []string{"*.jpg", "*.jpeg", "*.png"}, expandBraces("*.{jpg,jpeg,png}"))
+	assert.Equal(t, []string{"a/x.bin", "a/y.bin", "b/x.bin", "b/y.bin"}, expandBraces("{a,b}/{x,y}.bin"))
+	assert.Equal(t, []string{"*.", "*.bin"}, expandBraces("*.{,bin}"))
+}
+
+func TestExpandBracesHandlesNestedBraces(t *testing.T) {
+	assert.Equal(t, []string{"*.tif", "*.tiff", "*.psd"}, expandBraces("*.{tif{,f},psd}"))
+	assert.Equal(t, []string{"a", "bc", "bd"}, expandBraces("{a,b{c,d}}"))
+	assert.Equal(t, []string{"{a}b", "{a}c"}, expandBraces("{a}{b,c}"))
+}
+
+func TestExpandBracesRemovesDuplicates(t *testing.T) {
+	assert.Equal(t, []string{"*.jpg", "*.png"}, expandBraces("*.{jpg,png,jpg}"))
+}
+
+func TestExpandBracesLeavesLiteralBraces(t *testing.T) {
+	assert.Equal(t, []string{"*.bin"}, expandBraces("*.bin"))
+	assert.Equal(t, []string{"{a}.bin"}, expandBraces("{a}.bin"))
+	assert.Equal(t, []string{"{}.bin"}, expandBraces("{}.bin"))
+	assert.Equal(t, []string{"{a,b.bin"}, expandBraces("{a,b.bin"))
+	assert.Equal(t, []string{"{ab", "{ac"}, expandBraces("{a{b,c}"))
+}
+
+func TestToSlashPatternReplacesBackslashes(t *testing.T) {
+	assert.Equal(t, "assets/models/*.fbx", toSlashPattern(`assets\models\*.fbx`))
+	assert.Equal(t, "assets/models/*.fbx", toSlashPattern("assets/models/*.fbx"))
+	assert.Equal(t, "foo[[:space:]]bar/*", escapeAttrPattern(`foo bar\*`))
+}
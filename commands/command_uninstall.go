@@ -1,14 +1,30 @@
 package commands
 
 import (
-	"github.com/git-lfs/git-lfs/git"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
 // uninstallCmd removes any configuration and hooks set by Git LFS.
 func uninstallCommand(cmd *cobra.Command, args []string) {
-	if err := cmdInstallOptions().Uninstall(); err != nil {
-		Print("WARNING: %s", err.Error())
+	opts := cmdInstallOptions()
+
+	// The worktreeConfig extension is only enabled when asked to be.
+	// Otherwise, failing to remove the config from a worktree scope which
+	// Git cannot write to is only worth a warning, as it always was.
+	if worktreeInstall && worktreeConfig {
+		if err := checkWorktreeConfig(); err != nil {
+			ExitMsg("common.error", tr.Args{"Err": err})
+		}
+	}
+
+	removed, err := opts.Uninstall()
+	if err != nil {
+		PrintMsg("common.warning", tr.Args{"Err": err.Error()})
+	} else if len(removed) > 0 {
+		InfoMsg("uninstall.removed", tr.Args{"Keys": strings.Join(removed, ", "), "Scope": opts.Scope()})
 	}
 
 	if !skipRepoInstall && (localInstall || worktreeInstall || cfg.InRepo()) {
@@ -16,9 +32,13 @@ func uninstallCommand(cmd *cobra.Command, args []string) {
 	}
 
 	if systemInstall {
-		Print("System Git LFS configuration has been removed.")
+		InfoMsg("uninstall.system-removed")
 	} else if !(localInstall || worktreeInstall) {
-		Print("Global Git LFS configuration has been removed.")
+		InfoMsg("uninstall.global-removed")
+	}
+
+	if len(removed) > 0 {
+		PrintMsg("uninstall.pointers-warning")
 	}
 }
 
@@ -28,15 +48,15 @@ func uninstallHooksCommand(cmd *cobra.Command, args []string) {
 		Error(err.Error())
 	}
 
-	Print("Hooks for this repository have been removed.")
+	InfoMsg("uninstall.hooks-removed")
 }
 
 func init() {
 	RegisterCommand("uninstall", uninstallCommand, func(cmd *cobra.Command) {
 		cmd.Flags().BoolVarP(&localInstall, "local", "l", false, "Remove the Git LFS config for the local Git repository only.")
-		if git.IsGitVersionAtLeast("2.20.0") {
-			cmd.Flags().BoolVarP(&worktreeInstall, "worktree", "w", false, "Remove the Git LFS config for the current Git working tree, if multiple working trees are configured; otherwise, the same as --local.")
-		}
+		cmd.Flags().BoolVarP(&globalInstall, "global", "", false, "Remove the Git LFS config for the current user (the default).")
+		cmd.Flags().BoolVarP(&worktreeInstall, "worktree", "w", false, "Remove the Git LFS config for the current Git working tree, if multiple working trees are configured; otherwise, the same as --local.")
+		cmd.Flags().BoolVarP(&worktreeConfig, "enable-worktree-config", "", false, "Enable the worktreeConfig extension if --worktree needs it.")
 		cmd.Flags().BoolVarP(&systemInstall, "system", "", false, "Remove the Git LFS config in system-wide scope.")
 		cmd.Flags().BoolVarP(&skipRepoInstall, "skip-repo", "", false, "Skip repo setup, just uninstall global filters.")
 		cmd.AddCommand(NewCommand("hooks", uninstallHooksCommand))
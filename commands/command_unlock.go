@@ -1,12 +1,17 @@
 package commands
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"os"
+	"strings"
 
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/locking"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/git-lfs/gitobj"
 	"github.com/spf13/cobra"
 )
 
@@ -18,21 +23,29 @@ var (
 type unlockFlags struct {
 	// Id is the Id of the lock that is being unlocked.
 	Id string
+	// Oid is the OID of the Git LFS object of the file whose lock is being
+	// unlocked.
+	Oid string
 	// Force specifies whether or not the `lfs unlock` command was invoked
 	// with "--force", signifying the user's intent to break another
 	// individual's lock(s).
 	Force bool
 }
 
-var unlockUsage = "Usage: git lfs unlock (--id my-lock-id | <path>)"
-
 func unlockCommand(cmd *cobra.Command, args []string) {
 	hasPath := len(args) > 0
 	hasId := len(unlockCmdFlags.Id) > 0
-	if hasPath == hasId {
-		// If there is both an `--id` AND a `<path>`, or there is
-		// neither, print the usage and quit.
-		Exit(unlockUsage)
+	hasOid := len(unlockCmdFlags.Oid) > 0
+	given := 0
+	for _, has := range []bool{hasPath, hasId, hasOid} {
+		if has {
+			given++
+		}
+	}
+	if given != 1 {
+		// If more than one of `--id`, `--oid` and `<path>` are
+		// given, or none of them are, print the usage and quit.
+		ExitMsg("unlock.usage")
 	}
 
 	if len(lockRemote) > 0 {
@@ -48,7 +61,7 @@ func unlockCommand(cmd *cobra.Command, args []string) {
 		path, err := lockPath(args[0])
 		if err != nil {
 			if !unlockCmdFlags.Force {
-				Exit("Unable to determine path: %v", err.Error())
+				ExitMsg("unlock.determine-path", tr.Args{"Err": err})
 			}
 			path = args[0]
 		}
@@ -58,11 +71,33 @@ func unlockCommand(cmd *cobra.Command, args []string) {
 
 		err = lockClient.UnlockFile(path, unlockCmdFlags.Force)
 		if err != nil {
-			Exit("%s", errors.Cause(err))
+			ExitWithErrorf(err, "%s", errors.Cause(err))
+		}
+
+		if !locksCmdFlags.JSON {
+			InfoMsg("unlock.unlocked", tr.Args{"Path": path})
+			return
+		}
+	} else if hasOid {
+		lock, err := unlockFindLockByOid(unlockCmdFlags.Oid, lockClient)
+		if err != nil {
+			ExitWithErrorMsg(err, "unlock.failed", tr.Args{"Path": unlockCmdFlags.Oid, "Err": err})
+		}
+
+		// The file may have been renamed since it was locked, in which
+		// case there is nothing left at the locked path to check.
+		if _, err := os.Stat(lock.Path); err == nil {
+			// This call can early-out
+			unlockAbortIfFileModified(lock.Path)
+		}
+
+		err = lockClient.UnlockFileById(lock.Id, unlockCmdFlags.Force)
+		if err != nil {
+			ExitWithErrorMsg(err, "unlock.failed", tr.Args{"Path": lock.Path, "Err": errors.Cause(err)})
 		}
 
 		if !locksCmdFlags.JSON {
-			Print("Unlocked %s", path)
+			InfoMsg("unlock.unlocked", tr.Args{"Path": lock.Path})
 			return
 		}
 	} else if unlockCmdFlags.Id != "" {
@@ -71,15 +106,15 @@ func unlockCommand(cmd *cobra.Command, args []string) {
 
 		err := lockClient.UnlockFileById(unlockCmdFlags.Id, unlockCmdFlags.Force)
 		if err != nil {
-			Exit("Unable to unlock %v: %v", unlockCmdFlags.Id, errors.Cause(err))
+			ExitWithErrorMsg(err, "unlock.failed", tr.Args{"Path": unlockCmdFlags.Id, "Err": errors.Cause(err)})
 		}
 
 		if !locksCmdFlags.JSON {
-			Print("Unlocked Lock %s", unlockCmdFlags.Id)
+			InfoMsg("unlock.unlocked-id", tr.Args{"ID": unlockCmdFlags.Id})
 			return
 		}
 	} else {
-		Error(unlockUsage)
+		ErrorMsg("unlock.usage")
 	}
 
 	if err := json.NewEncoder(os.Stdout).Encode(struct {
@@ -109,9 +144,9 @@ func unlockAbortIfFileModified(path string) {
 	if modified {
 		if unlockCmdFlags.Force {
 			// Only a warning
-			Error("Warning: unlocking with uncommitted changes because --force")
+			ErrorMsg("unlock.force-uncommitted")
 		} else {
-			Exit("Cannot unlock file with uncommitted changes")
+			ExitMsg("unlock.uncommitted")
 		}
 
 	}
@@ -135,10 +170,73 @@ func unlockAbortIfFileModifiedById(id string, lockClient *locking.Client) {
 	unlockAbortIfFileModified(locks[0].Path)
 }
 
+// unlockFindLockByOid returns the lock on the file whose Git LFS object is
+// "oid". Locks record only paths, so the object of each locked path is looked
+// up in the history of HEAD, which finds files that have been renamed since
+// they were locked.
+func unlockFindLockByOid(oid string, lockClient *locking.Client) (*locking.Lock, error) {
+	oid = strings.TrimPrefix(oid, "sha256:")
+	if !fsckOidRE.MatchString(oid) {
+		return nil, errors.Errorf("invalid OID %q: expected sha256:<64 hexadecimal digits>", oid)
+	}
+
+	locks, err := lockClient.SearchLocks(nil, 0, false, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list locks")
+	}
+
+	db, err := getObjectDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var found []locking.Lock
+	for _, lock := range locks {
+		if unlockLockedOid(db, lock.Path) == oid {
+			found = append(found, lock)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, errors.Errorf("no lock is held on a file with object %s", oid)
+	case 1:
+		return &found[0], nil
+	default:
+		return nil, errors.Errorf("%d locks are held on files with object %s, so unlock them with --id instead", len(found), oid)
+	}
+}
+
+// unlockLockedOid returns the OID of the Git LFS object last committed at
+// "path", or an empty string if there is none.
+func unlockLockedOid(db *gitobj.ObjectDatabase, path string) string {
+	sha, err := git.LastBlobAtPath("HEAD", path)
+	if err != nil {
+		return ""
+	}
+	oid, err := hex.DecodeString(sha)
+	if err != nil {
+		return ""
+	}
+	blob, err := db.Blob(oid)
+	if err != nil {
+		return ""
+	}
+	defer blob.Close()
+
+	p, err := lfs.DecodePointerFromBlob(blob)
+	if err != nil {
+		return ""
+	}
+	return p.Oid
+}
+
 func init() {
 	RegisterCommand("unlock", unlockCommand, func(cmd *cobra.Command) {
 		cmd.Flags().StringVarP(&lockRemote, "remote", "r", "", lockRemoteHelp)
 		cmd.Flags().StringVarP(&unlockCmdFlags.Id, "id", "i", "", "unlock a lock by its ID")
+		cmd.Flags().StringVarP(&unlockCmdFlags.Oid, "oid", "", "", "unlock the lock on the file with the given Git LFS object")
 		cmd.Flags().BoolVarP(&unlockCmdFlags.Force, "force", "f", false, "forcibly break another user's lock(s)")
 		cmd.Flags().BoolVarP(&locksCmdFlags.JSON, "json", "", false, "print output in json")
 	})
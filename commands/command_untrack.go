@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -13,18 +14,18 @@ import (
 // default attributes file (.gitattributes), if it exists.
 func untrackCommand(cmd *cobra.Command, args []string) {
 	if cfg.LocalGitDir() == "" {
-		Print("Not a git repository.")
+		PrintMsg("track.not-a-repo")
 		os.Exit(128)
 	}
 	if cfg.LocalWorkingDir() == "" {
-		Print("This operation must be run in a work tree.")
+		PrintMsg("common.not-in-work-tree")
 		os.Exit(128)
 	}
 
-	installHooks(false)
+	upgradeHooks()
 
 	if len(args) < 1 {
-		Print("git lfs untrack <path> [path]*")
+		PrintMsg("untrack.usage")
 		return
 	}
 
@@ -37,7 +38,7 @@ func untrackCommand(cmd *cobra.Command, args []string) {
 
 	attributesFile, err := os.Create(".gitattributes")
 	if err != nil {
-		Print("Error opening .gitattributes for writing")
+		PrintMsg("untrack.attributes-open-error")
 		return
 	}
 	defer attributesFile.Close()
@@ -55,7 +56,7 @@ func untrackCommand(cmd *cobra.Command, args []string) {
 
 		path := strings.Fields(line)[0]
 		if removePath(path, args) {
-			Print("Untracking %q", unescapeAttrPattern(path))
+			InfoMsg("untrack.untracking", tr.Args{"Pattern": unescapeAttrPattern(path)})
 		} else {
 			attributesFile.WriteString(line + "\n")
 		}
@@ -3,6 +3,7 @@ package commands
 import (
 	"regexp"
 
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -30,25 +31,33 @@ func updateCommand(cmd *cobra.Command, args []string) {
 		case "basic":
 		case "private":
 			cfg.SetGitLocalKey(key, "basic")
-			Print("Updated %s access from %s to %s.", matches[1], value, "basic")
+			InfoMsg("update.access-updated", tr.Args{"Key": matches[1], "From": value, "To": "basic"})
 		default:
 			cfg.UnsetGitLocalKey(key)
-			Print("Removed invalid %s access of %s.", matches[1], value)
+			InfoMsg("update.access-removed", tr.Args{"Key": matches[1], "Value": value})
 		}
 	}
 
 	if updateForce && updateManual {
-		Exit("You cannot use --force and --manual options together")
+		ExitMsg("update.force-with-manual")
 	}
 
 	if updateManual {
 		Print(getHookInstallSteps())
 	} else {
 		if err := installHooks(updateForce); err != nil {
+			if _, ok := err.(*hooksNotWritableError); ok {
+				// A shared hooks directory is most likely
+				// managed by an administrator, who needs to add
+				// the hooks instead.
+				ErrorMsg("common.warning", tr.Args{"Err": err})
+				ErrorMsg("update.pushes-need-hooks", tr.Args{"Steps": getHookInstallSteps()})
+				return
+			}
 			Error(err.Error())
-			Exit("To resolve this, either:\n  1: run `git lfs update --manual` for instructions on how to merge hooks.\n  2: run `git lfs update --force` to overwrite your hook.")
+			ExitMsg("update.how-to-resolve")
 		} else {
-			Print("Updated git hooks.")
+			InfoMsg("update.updated")
 		}
 	}
 
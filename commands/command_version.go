@@ -1,25 +1,97 @@
 package commands
 
 import (
+	"encoding/json"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/lfshttp"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
 var (
 	lovesComics bool
+	versionJSON bool
 )
 
+// versionInfo describes the running build of Git LFS, as printed by
+// `git lfs version --json`.
+type versionInfo struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Vendor    string `json:"vendor"`
+	UserAgent string `json:"user_agent"`
+}
+
+func currentVersionInfo() *versionInfo {
+	return &versionInfo{
+		Version:   config.Version,
+		GitCommit: config.GitCommit,
+		GoVersion: strings.TrimPrefix(runtime.Version(), "go"),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Vendor:    config.Vendor,
+		UserAgent: lfshttp.UserAgent,
+	}
+}
+
 func versionCommand(cmd *cobra.Command, args []string) {
-	Print(lfshttp.UserAgent)
+	info := currentVersionInfo()
+
+	if versionJSON {
+		if err := json.NewEncoder(os.Stdout).Encode(info); err != nil {
+			ExitWithError(err)
+		}
+		return
+	}
+
+	Print(info.UserAgent)
+	VerboseMsg("version.version", tr.Args{"Version": info.Version})
+	if len(info.GitCommit) > 0 {
+		VerboseMsg("version.git-commit", tr.Args{"Commit": info.GitCommit})
+	}
+	VerboseMsg("version.go-version", tr.Args{"Version": info.GoVersion})
+	VerboseMsg("version.os-arch", tr.Args{"OS": info.OS, "Arch": info.Arch})
+	VerboseMsg("version.vendor", tr.Args{"Vendor": info.Vendor})
 
 	if lovesComics {
-		Print("Nothing may see Gah Lak Tus and survive!")
+		PrintMsg("version.galactus")
+	}
+}
+
+// checkMinClientVersion warns, or exits if lfs.enforceminclientversion is set,
+// if this version of Git LFS is older than lfs.minclientversion.
+func checkMinClientVersion() {
+	min := cfg.MinClientVersion()
+	if len(min) == 0 {
+		return
+	}
+
+	cmp, err := config.CompareVersions(config.Version, min)
+	if err != nil {
+		ErrorMsg("version.min-version-ignored", tr.Args{"Err": err})
+		return
+	}
+	if cmp >= 0 {
+		return
+	}
+
+	if cfg.EnforceMinClientVersion() {
+		ExitMsg("version.too-old-error", tr.Args{"Minimum": min, "Version": config.Version})
 	}
+	ErrorMsg("version.too-old-warning", tr.Args{"Minimum": min, "Version": config.Version})
 }
 
 func init() {
 	RegisterCommand("version", versionCommand, func(cmd *cobra.Command) {
 		cmd.PreRun = nil
 		cmd.Flags().BoolVarP(&lovesComics, "comics", "c", false, "easter egg")
+		cmd.Flags().BoolVarP(&versionJSON, "json", "j", false, "print the version and build details as JSON")
 	})
 }
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/spf13/cobra"
+)
+
+var (
+	worktreePruneDryRun bool
+)
+
+// worktreePruneCommand deletes the local objects which nothing in the
+// repository uses any more, such as those only used by the commits of a linked
+// working tree which has since been removed. Unlike prune, it keeps every
+// object reachable from any ref, whether recent or not.
+func worktreePruneCommand(cmd *cobra.Command, args []string) {
+	requireInRepo()
+	if !worktreePruneDryRun {
+		pruneCheckSharedStorage()
+	}
+
+	retained := worktreePruneRetainedObjects()
+
+	var prunable []string
+	var size int64
+	if err := cfg.EachLFSObject(func(obj fs.Object) error {
+		if !retained.Contains(obj.Oid) {
+			prunable = append(prunable, obj.Oid)
+			size += obj.Size
+		}
+		return nil
+	}); err != nil {
+		ExitMsg("common.read-objects-failed", tr.Args{"Err": err})
+	}
+
+	if worktreePruneDryRun {
+		PrintMsg("worktree-prune.would-prune", tr.Args{"Count": len(prunable), "Size": humanize.FormatBytes(uint64(size))})
+		for _, oid := range prunable {
+			Print(" * %s", oid)
+		}
+		return
+	}
+	if len(prunable) == 0 {
+		return
+	}
+
+	logger := newLogger(OutputWriter)
+	pruneDeleteFiles(prunable, logger)
+	logger.Close()
+	InfoMsg("worktree-prune.pruned", tr.Args{"Count": len(prunable), "Size": humanize.FormatBytes(uint64(size))})
+}
+
+// worktreePruneRetainedObjects returns the objects used by any ref, or by the
+// HEAD or index of any working tree which still exists. It exits if any of
+// them cannot be scanned, rather than risk deleting an object which is still
+// in use.
+func worktreePruneRetainedObjects() tools.StringSet {
+	retained := tools.NewStringSet()
+	failed := false
+	gitscanner := lfs.NewGitScanner(cfg, func(p *lfs.WrappedPointer, err error) {
+		if err != nil {
+			LoggedErrorMsg(err, "common.scanner-error", tr.Args{"Err": err})
+			failed = true
+			return
+		}
+		retained.Add(p.Oid)
+	})
+	defer gitscanner.Close()
+
+	if err := gitscanner.ScanAll(nil); err != nil {
+		ExitWithError(err)
+	}
+
+	heads, err := git.GetAllWorkTreeHEADs(cfg.LocalGitStorageDir())
+	if err != nil {
+		ExitWithError(err)
+	}
+	commits := tools.NewStringSet()
+	for _, ref := range heads {
+		if commits.Add(ref.Sha) {
+			if err := gitscanner.ScanRef(ref.Sha, nil); err != nil {
+				ExitWithError(err)
+			}
+		}
+	}
+
+	workTrees, err := git.GetAllWorkTreeDirs()
+	if err != nil {
+		ExitWithError(err)
+	}
+	for _, dir := range workTrees {
+		if err := gitscanner.ScanWorkTreeIndexTree(dir); err != nil {
+			ExitWithError(err)
+		}
+	}
+
+	if failed {
+		ExitMsg("worktree-prune.in-use-failed")
+	}
+	return retained
+}
+
+func init() {
+	RegisterCommand("worktree-prune", worktreePruneCommand, func(cmd *cobra.Command) {
+		cmd.Flags().BoolVarP(&worktreePruneDryRun, "dry-run", "d", false, "Don't delete anything, just report")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net"
 	"os"
@@ -11,17 +12,23 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/git/gitattr"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/lfsapi"
 	"github.com/git-lfs/git-lfs/locking"
+	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/perf"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/rubyist/tracerx"
 )
 
 // Populate man pages
@@ -39,6 +46,13 @@ var (
 	apiClient *lfsapi.Client
 	global    sync.Mutex
 
+	// quietArg is whether --quiet was given, in which case only errors and
+	// the data a command was asked for are printed.
+	quietArg bool
+	// verbosityArg is the number of times --verbose was given. Once prints
+	// more detail, and twice also prints debugging messages.
+	verbosityArg int
+
 	includeArg string
 	excludeArg string
 )
@@ -97,7 +111,7 @@ func newLockClient() *locking.Client {
 	}
 
 	if err != nil {
-		Exit("Unable to create lock system: %v", err.Error())
+		ExitMsg("common.lock-system", tr.Args{"Err": err})
 	}
 
 	// Configure dirs
@@ -128,7 +142,60 @@ func currentRemoteRef() *git.Ref {
 
 func buildFilepathFilter(config *config.Configuration, includeArg, excludeArg *string, useFetchOptions bool) *filepathfilter.Filter {
 	inc, exc := determineIncludeExcludePaths(config, includeArg, excludeArg, useFetchOptions)
-	return filepathfilter.New(inc, exc)
+	if !useFetchOptions || includeArg != nil || excludeArg != nil {
+		return filepathfilter.New(inc, exc)
+	}
+
+	// Files with lfs-fetch=false are only fetched when they are asked for
+	// with --include or --exclude.
+	include := make([]filepathfilter.Pattern, 0, len(inc))
+	for _, p := range inc {
+		include = append(include, filepathfilter.NewPattern(p))
+	}
+	exclude := make([]filepathfilter.Pattern, 0, len(exc)+1)
+	for _, p := range exc {
+		exclude = append(exclude, filepathfilter.NewPattern(p))
+	}
+	exclude = append(exclude, newNoFetchPattern(config))
+	return filepathfilter.NewFromPatterns(include, exclude)
+}
+
+// noFetchPattern is a filepathfilter.Pattern which matches the files with the
+// lfs-fetch attribute unset, as in "lfs-fetch=false" or "-lfs-fetch".
+type noFetchPattern struct {
+	values *git.AttributeValues
+}
+
+func newNoFetchPattern(config *config.Configuration) *noFetchPattern {
+	var paths []git.AttributePath
+	if workingDir := config.LocalWorkingDir(); len(workingDir) > 0 {
+		paths = git.GetAttributePaths(gitattr.NewMacroProcessor(), workingDir, config.LocalGitDir())
+	}
+	return &noFetchPattern{
+		values: git.NewAttributeValues(paths, func(p git.AttributePath) string { return p.Fetch }),
+	}
+}
+
+func (p *noFetchPattern) Match(filename string) bool {
+	return p.values.Get(filename) == "false"
+}
+
+func (p *noFetchPattern) String() string {
+	return git.FetchAttrib + "=false"
+}
+
+// reportQuarantined lists the files which lfs.postsmudgecheck rejected while
+// "gf" smudged them, at the end of an operation.
+func reportQuarantined(gf *lfs.GitFilter) {
+	quarantined := gf.Quarantined()
+	if len(quarantined) == 0 {
+		return
+	}
+
+	ErrorMsg("common.quarantined", tr.Args{"Count": len(quarantined)})
+	for _, q := range quarantined {
+		Error("%s", tools.Indent(q.String()))
+	}
 }
 
 func downloadTransfer(p *lfs.WrappedPointer) (name, path, oid string, size int64, missing bool, err error) {
@@ -146,21 +213,51 @@ func getHookInstallSteps() string {
 	steps := make([]string, 0, len(hooks))
 	for _, h := range hooks {
 		steps = append(steps, fmt.Sprintf(
-			"Add the following to .git/hooks/%s:\n\n%s",
-			h.Type, tools.Indent(h.Contents)))
+			"Add the following to %s:\n\n%s",
+			hookDisplayPath(h), tools.Indent(h.Contents)))
 	}
 
 	return strings.Join(steps, "\n\n")
 }
 
+// hookDisplayPath returns the path of a hook relative to the working tree,
+// such as ".git/hooks/pre-push", or its absolute path if it is elsewhere, as
+// when core.hooksPath is set.
+func hookDisplayPath(h *lfs.Hook) string {
+	if wd := cfg.LocalWorkingDir(); len(wd) > 0 {
+		if rel, err := filepath.Rel(wd, h.Path()); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return h.Path()
+}
+
+// hooksNotWritableError is returned by installHooks when the hooks directory,
+// which core.hooksPath may place outside of the repository, cannot be written
+// to by the current user.
+type hooksNotWritableError struct {
+	dir string
+	err error
+}
+
+func (e *hooksNotWritableError) Error() string {
+	return fmt.Sprintf("Git LFS could not install its hooks, because the hooks directory %s is not writable by the current user: %v", e.dir, e.err)
+}
+
 func installHooks(force bool) error {
 	hookDir, err := cfg.HookDir()
 	if err != nil {
 		return err
 	}
 	hooks := lfs.LoadHooks(hookDir, cfg)
+	if sizeGuardInstall && !lfs.PreCommitHookEnabled(cfg) {
+		hooks = append(hooks, lfs.NewPreCommitHook(hookDir, cfg))
+	}
 	for _, h := range hooks {
 		if err := h.Install(force); err != nil {
+			if isNotWritableError(err) {
+				return &hooksNotWritableError{dir: hookDir, err: err}
+			}
 			return err
 		}
 	}
@@ -168,6 +265,69 @@ func installHooks(force bool) error {
 	return nil
 }
 
+// upgradeHooks installs any missing hooks and silently upgrades those written
+// by older versions of Git LFS, as commands which run in a repository do
+// without being asked. Unlike installHooks, it leaves any other existing hook
+// alone. If an outdated hook cannot be upgraded, a warning suggesting `git lfs
+// update` is printed, at most once a day per repository.
+func upgradeHooks() {
+	hookDir, err := cfg.HookDir()
+	if err != nil {
+		return
+	}
+
+	var outdated []string
+	for _, h := range lfs.LoadHooks(hookDir, cfg) {
+		switch h.State() {
+		case lfs.HookMissing:
+			h.Install(false)
+		case lfs.HookOutdated:
+			if err := h.Upgrade(); err != nil {
+				tracerx.Printf("Could not upgrade %s hook: %s", h.Type, err)
+				outdated = append(outdated, string(h.Type))
+			}
+		}
+	}
+
+	if len(outdated) > 0 && shouldWarnOutdatedHooks() {
+		ErrorMsg("hooks.outdated", tr.Args{"Hooks": strings.Join(outdated, ", "), "Dir": hookDir})
+	}
+}
+
+// outdatedHooksWarningName is the file, relative to the LFS storage directory,
+// whose modification time records when upgradeHooks last warned about hooks
+// it could not upgrade.
+const outdatedHooksWarningName = "outdated-hooks-warning"
+
+// shouldWarnOutdatedHooks returns whether or not a day has passed since the
+// last warning about outdated hooks, and if so, records that one is being
+// given now.
+func shouldWarnOutdatedHooks() bool {
+	stamp := filepath.Join(cfg.LFSStorageDir(), outdatedHooksWarningName)
+	if fi, err := os.Stat(stamp); err == nil && time.Since(fi.ModTime()) < 24*time.Hour {
+		return false
+	}
+
+	if err := tools.MkdirAll(cfg.LFSStorageDir(), cfg); err == nil {
+		ioutil.WriteFile(stamp, []byte(time.Now().Format(time.RFC3339)+"\n"), cfg.RepositoryPermissions(false))
+	}
+	return true
+}
+
+func isNotWritableError(err error) bool {
+	err = errors.Cause(err)
+	if os.IsPermission(err) {
+		return true
+	}
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	}
+	return err == syscall.EROFS
+}
+
 // uninstallHooks removes all hooks in range of the `hooks` var.
 func uninstallHooks() error {
 	if !cfg.InRepo() {
@@ -179,10 +339,29 @@ func uninstallHooks() error {
 		return err
 	}
 	hooks := lfs.LoadHooks(hookDir, cfg)
+	if !lfs.PreCommitHookEnabled(cfg) {
+		// The pre-commit hook may have been installed before its
+		// checks were disabled.
+		hooks = append(hooks, lfs.NewPreCommitHook(hookDir, cfg))
+	}
 	for _, h := range hooks {
+		if !h.Exists() {
+			continue
+		}
+
+		installed, chained := h.Installed(), h.Chained()
 		if err := h.Uninstall(); err != nil {
 			return err
 		}
+
+		switch {
+		case !installed:
+			InfoMsg("hooks.kept", tr.Args{"Hook": h.Type})
+		case chained:
+			InfoMsg("hooks.removed-chained", tr.Args{"Hook": h.Type})
+		default:
+			InfoMsg("hooks.removed", tr.Args{"Hook": h.Type})
+		}
 	}
 
 	return nil
@@ -192,37 +371,50 @@ func uninstallHooks() error {
 // panic log if one is created for this command.
 func Error(format string, args ...interface{}) {
 	if len(args) == 0 {
-		fmt.Fprintln(ErrorWriter, format)
+		tasklog.Write(ErrorWriter, []byte(format+"\n"))
 		return
 	}
-	fmt.Fprintf(ErrorWriter, format+"\n", args...)
+	tasklog.Write(ErrorWriter, []byte(fmt.Sprintf(format+"\n", args...)))
 }
 
 // Print prints a formatted message to Stdout.  It also gets printed to the
 // panic log if one is created for this command.
 func Print(format string, args ...interface{}) {
 	if len(args) == 0 {
-		fmt.Fprintln(OutputWriter, format)
+		tasklog.Write(OutputWriter, []byte(format+"\n"))
 		return
 	}
-	fmt.Fprintf(OutputWriter, format+"\n", args...)
+	tasklog.Write(OutputWriter, []byte(fmt.Sprintf(format+"\n", args...)))
 }
 
-// Exit prints a formatted message and exits.
+// Exit prints a formatted message and exits, with the exit code for the first
+// failure reported by the command, or exitCodeUsage if there was none.
 func Exit(format string, args ...interface{}) {
 	Error(format, args...)
-	os.Exit(2)
+	writePerf()
+	os.Exit(exitCode())
 }
 
 // ExitWithError either panics with a full stack trace for fatal errors, or
-// simply prints the error message and exits immediately.
+// simply prints the error message and exits immediately, with the exit code
+// for "err".
 func ExitWithError(err error) {
+	recordExitCode(exitCodeFor(err))
 	errorWith(err, Panic, Exit)
 }
 
+// ExitWithErrorf prints a formatted message and exits, with the exit code for
+// "err".
+func ExitWithErrorf(err error, format string, args ...interface{}) {
+	recordExitCode(exitCodeFor(err))
+	Exit(format, args...)
+}
+
 // FullError prints either a full stack trace for fatal errors, or just the
-// error message.
+// error message. The command exits with the exit code for "err" if it later
+// fails.
 func FullError(err error) {
+	recordExitCode(exitCodeFor(err))
 	errorWith(err, LoggedError, Error)
 }
 
@@ -235,6 +427,40 @@ func errorWith(err error, fatalErrFn func(error, string, ...interface{}), errFn
 	errFn("%s", err)
 }
 
+// Info prints a formatted message to Stdout, like Print, unless --quiet was
+// given. It is for messages which report what a command did, rather than the
+// data it was asked for.
+func Info(format string, args ...interface{}) {
+	if quietArg {
+		return
+	}
+	Print(format, args...)
+}
+
+// Verbose prints a formatted message to Stdout, like Print, if --verbose was
+// given.
+func Verbose(format string, args ...interface{}) {
+	if !isVerbose() {
+		return
+	}
+	Print(format, args...)
+}
+
+// isVerbose returns whether --verbose was given.
+func isVerbose() bool {
+	return verbosityArg > 0
+}
+
+// setVerbosity checks and applies the global --quiet and --verbose flags.
+func setVerbosity() {
+	if quietArg && verbosityArg > 0 {
+		ExitMsg("common.quiet-with-verbose")
+	}
+	if verbosityArg > 1 {
+		Debugging = true
+	}
+}
+
 // Debug prints a formatted message if debugging is enabled.  The formatted
 // message also shows up in the panic log, if created.
 func Debug(format string, args ...interface{}) {
@@ -250,14 +476,16 @@ func Debug(format string, args ...interface{}) {
 // omitted.
 //
 // It also writes a stack trace for the error to a log file without exiting.
+// The command exits with the exit code for "err" if it later fails.
 func LoggedError(err error, format string, args ...interface{}) {
+	recordExitCode(exitCodeFor(err))
 	if len(format) > 0 {
 		Error(format, args...)
 	}
 	file := handlePanic(err)
 
 	if len(file) > 0 {
-		fmt.Fprintf(os.Stderr, "\nErrors logged to %s\nUse `git lfs logs last` to view the log.\n", file)
+		fmt.Fprintln(os.Stderr, tr.Tr("common.errors-logged", tr.Args{"Path": file}))
 	}
 }
 
@@ -265,12 +493,13 @@ func LoggedError(err error, format string, args ...interface{}) {
 // a log file before exiting.
 func Panic(err error, format string, args ...interface{}) {
 	LoggedError(err, format, args...)
-	os.Exit(2)
+	writePerf()
+	os.Exit(exitCode())
 }
 
 func Cleanup() {
 	if err := cfg.Cleanup(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error clearing old temp files: %s\n", err)
+		fmt.Fprintln(os.Stderr, tr.Tr("common.cleanup-failed", tr.Args{"Err": err}))
 	}
 }
 
@@ -304,8 +533,19 @@ func requireStdin(msg string) {
 
 func requireInRepo() {
 	if !cfg.InRepo() {
-		Print("Not in a git repository.")
-		os.Exit(128)
+		PrintMsg("common.not-in-repo")
+		os.Exit(exitCodeNotARepo)
+	}
+}
+
+// requireTempDir exits with an error naming the directory for temporary files
+// if one cannot be created in it, rather than failing on every object later.
+func requireTempDir() {
+	if !cfg.InRepo() {
+		return
+	}
+	if err := cfg.Filesystem().CheckTempDir(); err != nil {
+		ExitMsg("common.temp-dir", tr.Args{"Err": err, "Dir": cfg.LFSStorageDir()})
 	}
 }
 
@@ -320,8 +560,8 @@ func requireWorkingCopy() {
 	}
 
 	if bare {
-		Print("This operation must be run in a work tree.")
-		os.Exit(128)
+		PrintMsg("common.not-in-work-tree")
+		os.Exit(exitCodeNotARepo)
 	}
 }
 
@@ -345,12 +585,12 @@ func logPanic(loggedError error) string {
 
 	if err := tools.MkdirAll(cfg.LocalLogDir(), cfg); err != nil {
 		full = ""
-		fmt.Fprintf(fmtWriter, "Unable to log panic to %s: %s\n\n", cfg.LocalLogDir(), err.Error())
+		fmt.Fprintln(fmtWriter, tr.Tr("common.panic-log-failed", tr.Args{"Dir": cfg.LocalLogDir(), "Err": err.Error()}))
 	} else if file, err := os.Create(full); err != nil {
 		filename := full
 		full = ""
 		defer func() {
-			fmt.Fprintf(fmtWriter, "Unable to log panic to %s\n\n", filename)
+			fmt.Fprintln(fmtWriter, tr.Tr("common.panic-log-file-failed", tr.Args{"Path": filename}))
 			logPanicToWriter(fmtWriter, err, lineEnding)
 		}()
 	} else {
@@ -418,12 +658,12 @@ func logPanicToWriter(w io.Writer, loggedError error, le string) {
 	fmt.Fprint(w, le)
 	fmt.Fprintf(w, "$ %s", filepath.Base(os.Args[0]))
 	if len(os.Args) > 0 {
-		fmt.Fprintf(w, " %s", strings.Join(os.Args[1:], " "))
+		fmt.Fprintf(w, " %s", traceRedact(strings.Join(os.Args[1:], " ")))
 	}
 	fmt.Fprint(w, le)
 
 	// log the error message and stack trace
-	w.Write(ErrorBuffer.Bytes())
+	fmt.Fprint(w, traceRedact(ErrorBuffer.String()))
 	fmt.Fprint(w, le)
 
 	fmt.Fprintf(w, "%+v"+le, loggedError)
@@ -432,6 +672,9 @@ func logPanicToWriter(w io.Writer, loggedError error, le string) {
 		fmt.Fprintf(w, "%s=%v"+le, key, val)
 	}
 
+	logRecentTraceToWriter(w, le)
+	logPerfToWriter(w, le)
+
 	fmt.Fprint(w, le+"Current time in UTC: "+le)
 	fmt.Fprint(w, time.Now().UTC().Format("2006-01-02 15:04:05")+le)
 
@@ -449,6 +692,49 @@ func logPanicToWriter(w io.Writer, loggedError error, le string) {
 	}
 }
 
+// recentTraceLines is the number of trailing lines of the trace output which
+// are included in a log file.
+const recentTraceLines = 50
+
+// logRecentTraceToWriter writes the last few lines of trace output to "w", if
+// tracing has been sent to a file with GIT_TRACE. Trace output written to
+// stderr or a file descriptor cannot be read back, so is not included.
+func logRecentTraceToWriter(w io.Writer, le string) {
+	fmt.Fprint(w, le+"Recent trace events:"+le)
+
+	trace := os.Getenv("GIT_TRACE")
+	if !filepath.IsAbs(trace) {
+		fmt.Fprint(w, "Not available, set GIT_TRACE to an absolute path to include them."+le)
+		return
+	}
+
+	data, err := ioutil.ReadFile(trace)
+	if err != nil {
+		fmt.Fprintf(w, "Error reading %s: %s"+le, trace, err)
+		return
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > recentTraceLines {
+		lines = lines[len(lines)-recentTraceLines:]
+	}
+	for _, line := range lines {
+		fmt.Fprint(w, traceRedact(line)+le)
+	}
+}
+
+// logPerfToWriter writes the timings of the phases of the command so far to
+// "w", if GIT_LFS_PERF is set.
+func logPerfToWriter(w io.Writer, le string) {
+	fmt.Fprint(w, le+"Phase timings:"+le)
+
+	if !perf.Enabled() {
+		fmt.Fprint(w, "Not available, set GIT_LFS_PERF=1 to include them."+le)
+		return
+	}
+	perf.WriteSummary(w, le)
+}
+
 func determineIncludeExcludePaths(config *config.Configuration, includeArg, excludeArg *string, useFetchOptions bool) (include, exclude []string) {
 	if includeArg == nil {
 		if useFetchOptions {
@@ -471,22 +757,57 @@ func determineIncludeExcludePaths(config *config.Configuration, includeArg, excl
 	return
 }
 
+// newLogger returns a *tasklog.Logger which logs the progress of tasks to
+// "sink", as configured by GIT_LFS_FORCE_PROGRESS, lfs.forceprogress, and
+// lfs.progress. Progress is discarded when it is reported as JSON.
+func newLogger(sink io.Writer) *tasklog.Logger {
+	if quietArg || progressEvents() != nil {
+		sink = nil
+	}
+	return tasklog.NewLogger(sink,
+		tasklog.ForceProgress(cfg.ForceProgress()),
+		tasklog.PlainProgress(cfg.PlainProgress()),
+	)
+}
+
 func buildProgressMeter(dryRun bool, d tq.Direction) *tq.Meter {
 	m := tq.NewMeter(cfg)
 	m.Logger = m.LoggerFromEnv(cfg.Os)
 	m.DryRun = dryRun
 	m.Direction = d
+	m.Events = progressEvents()
 	return m
 }
 
-func requireGitVersion() {
-	minimumGit := "1.8.2"
+// progressEvents returns the writer to which progress events should be
+// written as JSON lines, or nil if progress is reported for people, as given
+// by --progress-format or GIT_LFS_PROGRESS_FORMAT.
+func progressEvents() io.Writer {
+	format := progressFormatArg
+	if len(format) == 0 {
+		format, _ = cfg.Os.Get("GIT_LFS_PROGRESS_FORMAT")
+	}
 
-	if !git.IsGitVersionAtLeast(minimumGit) {
+	switch format {
+	case "", "text":
+		return nil
+	case "json":
+		return os.Stderr
+	default:
+		ExitMsg("common.progress-format", tr.Args{"Format": format})
+		return nil
+	}
+}
+
+// minimumGitVersion is the oldest version of Git which Git LFS supports.
+const minimumGitVersion = "1.8.2"
+
+func requireGitVersion() {
+	if !git.IsGitVersionAtLeast(minimumGitVersion) {
 		gitver, err := git.Version()
 		if err != nil {
-			Exit("Error getting git version: %s", err)
+			ExitMsg("common.git-version-error", tr.Args{"Err": err})
 		}
-		Exit("git version >= %s is required for Git LFS, your version: %s", minimumGit, gitver)
+		ExitMsg("common.git-version-too-old", tr.Args{"Minimum": minimumGitVersion, "Version": gitver})
 	}
 }
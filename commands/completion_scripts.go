@@ -0,0 +1,135 @@
+package commands
+
+// completionScripts are the scripts printed by 'git lfs completion', by the
+// name of the shell. They complete every word by running 'git lfs __complete'
+// with the words typed after 'git lfs', so that they need not change when a
+// command or flag is added.
+var completionScripts = map[string]string{
+	"bash": completionBash,
+	"zsh":  completionZsh,
+	"fish": completionFish,
+}
+
+const completionBash = `# bash completion for Git LFS
+#
+# To load it in the current shell, run:
+#
+#     source <(git lfs completion bash)
+#
+# Both 'git lfs <TAB>', with Git's own completion loaded, and 'git-lfs <TAB>'
+# are completed.
+
+# __git_lfs_complete completes the last of its arguments, which are the words
+# after 'git lfs'.
+__git_lfs_complete ()
+{
+	local cur="${@: -1}" c i
+	COMPREPLY=()
+	while IFS= read -r c; do
+		COMPREPLY+=("$c")
+	done < <(git lfs __complete "$@" 2>/dev/null)
+
+	# The shell only replaces the part of "--flag=value" after the "=".
+	if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+		COMPREPLY=("${COMPREPLY[@]#*=}")
+	fi
+
+	# Quote file names and patterns, such as "a b.psd" or "*.psd".
+	for i in "${!COMPREPLY[@]}"; do
+		printf -v "COMPREPLY[i]" %q "${COMPREPLY[i]}"
+	done
+}
+
+# _git_lfs is called by Git's own completion to complete 'git lfs', with the
+# words of the command line in "words".
+_git_lfs ()
+{
+	local i
+	for ((i = 1; i < cword; i++)); do
+		[ "${words[i]}" = lfs ] && break
+	done
+	__git_lfs_complete "${words[@]:i+1:cword-i}"
+
+	# Git's completion does not add a space after each word itself.
+	if [ ${#COMPREPLY[@]} -gt 0 ]; then
+		COMPREPLY=("${COMPREPLY[@]/%/ }")
+	fi
+}
+
+__git_lfs_main ()
+{
+	local i words=()
+
+	# Join the words which the shell split at each "=", so that
+	# "--flag=value" is a single word.
+	for ((i = 0; i <= COMP_CWORD; i++)); do
+		if [ $i -gt 1 ] && [[ "${COMP_WORDS[i]}" == = || "${COMP_WORDS[i-1]}" == = ]]; then
+			words[${#words[@]}-1]+="${COMP_WORDS[i]}"
+		else
+			words+=("${COMP_WORDS[i]}")
+		fi
+	done
+	__git_lfs_complete "${words[@]:1}"
+}
+
+complete -o bashdefault -o default -F __git_lfs_main git-lfs
+`
+
+const completionZsh = `#compdef git-lfs
+#
+# zsh completion for Git LFS
+#
+# To load it in the current shell, run:
+#
+#     source <(git lfs completion zsh)
+#
+# or save it as _git-lfs in a directory in $fpath. Both 'git lfs <TAB>', which
+# the Git completion of zsh passes to _git-lfs, and 'git-lfs <TAB>' are
+# completed.
+
+_git-lfs () {
+	local -a candidates
+	candidates=("${(@f)$(git lfs __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	candidates=("${(@)candidates:#}")
+
+	if (( ${#candidates} )); then
+		compadd -- "${(@)candidates}"
+	else
+		_files
+	fi
+}
+
+if [[ "$zsh_eval_context[-1]" == loadautofunc ]]; then
+	_git-lfs "$@"
+elif (( $+functions[compdef] )); then
+	compdef _git-lfs git-lfs
+fi
+`
+
+const completionFish = `# fish completion for Git LFS
+#
+# To load it in the current shell, run:
+#
+#     git lfs completion fish | source
+#
+# or save it as git-lfs.fish in ~/.config/fish/completions. Both 'git lfs
+# <TAB>' and 'git-lfs <TAB>' are completed.
+
+function __git_lfs_complete
+    set -l tokens (commandline -opc)
+    if test "$tokens[1]" = git-lfs
+        set -e tokens[1]
+    else if set -l lfs (contains -i -- lfs $tokens)
+        set -e tokens[1..$lfs]
+    end
+    set -l candidates (git lfs __complete $tokens (commandline -ct) 2>/dev/null)
+    if test (count $candidates) -gt 0
+        printf '%s\n' $candidates
+    else
+        __fish_complete_path (commandline -ct)
+    end
+end
+
+complete -c git-lfs -f -a '(__git_lfs_complete)'
+complete -c git -n '__fish_seen_subcommand_from lfs' -f -a '(__git_lfs_complete)'
+`
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCompletionTestRoot() *cobra.Command {
+	root := &cobra.Command{Use: "git-lfs"}
+	root.PersistentFlags().BoolP("quiet", "q", false, "")
+
+	fetch := &cobra.Command{Use: "fetch"}
+	fetch.Flags().BoolP("all", "a", false, "")
+	fetch.Flags().StringP("include", "I", "", "")
+	fetch.Flags().String("remote", "", "")
+
+	migrate := &cobra.Command{Use: "migrate"}
+	migrate.AddCommand(&cobra.Command{Use: "import"}, &cobra.Command{Use: "info"})
+
+	root.AddCommand(fetch, migrate, &cobra.Command{Use: "hidden", Hidden: true})
+	return root
+}
+
+func withCompletionFuncs(args map[string][]completionFunc, flags map[string]completionFunc, fn func()) {
+	oldArgs, oldFlags := completionArgs, completionFlags
+	completionArgs, completionFlags = args, flags
+	defer func() {
+		completionArgs, completionFlags = oldArgs, oldFlags
+	}()
+
+	fn()
+}
+
+func TestCompletionsSubcommands(t *testing.T) {
+	root := newCompletionTestRoot()
+
+	assert.Equal(t, []string{"fetch", "migrate"}, completions(root, nil, ""))
+	assert.Equal(t, []string{"migrate"}, completions(root, nil, "mi"))
+	assert.Equal(t, []string{"import", "info"}, completions(root, []string{"migrate"}, "i"))
+	assert.Empty(t, completions(root, nil, "hid"))
+}
+
+func TestCompletionsFlags(t *testing.T) {
+	root := newCompletionTestRoot()
+
+	assert.Equal(t, []string{"--all", "--include", "--quiet", "--remote"},
+		completions(root, []string{"fetch"}, "-"))
+	assert.Equal(t, []string{"--include"}, completions(root, []string{"-q", "fetch"}, "--in"))
+}
+
+func TestCompletionsArguments(t *testing.T) {
+	root := newCompletionTestRoot()
+	remotes := func() []string { return []string{"origin", "upstream"} }
+	refs := func() []string { return []string{"main", "maint", "v1"} }
+
+	withCompletionFuncs(map[string][]completionFunc{
+		"fetch": {remotes, refs},
+	}, map[string]completionFunc{
+		"remote": remotes,
+	}, func() {
+		assert.Equal(t, []string{"origin", "upstream"}, completions(root, []string{"fetch"}, ""))
+		assert.Equal(t, []string{"main", "maint"}, completions(root, []string{"fetch", "origin"}, "ma"))
+		assert.Equal(t, []string{"v1"}, completions(root, []string{"fetch", "origin", "main"}, "v"))
+
+		// The value of a flag is not an argument.
+		assert.Equal(t, []string{"origin"}, completions(root, []string{"fetch", "-I", "*.psd"}, "o"))
+		assert.Equal(t, []string{"origin"}, completions(root, []string{"fetch", "--include=*.psd"}, "o"))
+		assert.Equal(t, []string{"origin"}, completions(root, []string{"fetch", "-a"}, "o"))
+
+		// Flag values.
+		assert.Equal(t, []string{"upstream"}, completions(root, []string{"fetch", "--remote"}, "u"))
+		assert.Equal(t, []string{"--remote=upstream"}, completions(root, []string{"fetch"}, "--remote=u"))
+		assert.Empty(t, completions(root, []string{"fetch", "--include"}, ""))
+
+		// Commands without completers are completed by the shell.
+		assert.Empty(t, completions(root, []string{"migrate", "import"}, ""))
+	})
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+)
+
+// Exit codes with which commands report common classes of failure, so that
+// scripts can tell them apart without matching error messages. Each one is
+// described in exitCodes().
+const (
+	exitCodeFailure  = 1
+	exitCodeUsage    = 2
+	exitCodeAuth     = 3
+	exitCodeNetwork  = 4
+	exitCodeNotFound = 5
+	exitCodeCorrupt  = 6
+	exitCodeNotARepo = 128
+)
+
+// exitCodes returns the table of exit codes printed by `git lfs help
+// exit-codes`, and listed in git-lfs(1), with their descriptions translated.
+func exitCodes() []exitCodeEntry {
+	return []exitCodeEntry{
+		{0, tr.Tr("exit-codes.success")},
+		{exitCodeFailure, tr.Tr("exit-codes.failure")},
+		{exitCodeUsage, tr.Tr("exit-codes.usage")},
+		{exitCodeAuth, tr.Tr("exit-codes.auth")},
+		{exitCodeNetwork, tr.Tr("exit-codes.network")},
+		{exitCodeNotFound, tr.Tr("exit-codes.not-found")},
+		{exitCodeCorrupt, tr.Tr("exit-codes.corrupt")},
+		{exitCodeNotARepo, tr.Tr("exit-codes.not-a-repo")},
+	}
+}
+
+// exitCodeEntry is an exit code, and a description of the failures for which
+// commands exit with it.
+type exitCodeEntry struct {
+	Code        int
+	Description string
+}
+
+var (
+	// failureCode is the exit code for the first failure reported by the
+	// command which has one, or zero if there has not been one.
+	failureCode   int
+	failureCodeMu sync.Mutex
+)
+
+// exitCodeFor returns the exit code for a command which failed with "err",
+// or zero if "err" is not one of the failures which has its own exit code.
+func exitCodeFor(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.IsAuthError(err) {
+		return exitCodeAuth
+	}
+
+	switch cause := errors.Cause(err).(type) {
+	case interface{ HTTPResponse() *http.Response }:
+		return exitCodeForStatus(cause.HTTPResponse().StatusCode)
+	case *tq.ObjectError:
+		return exitCodeForStatus(cause.Code)
+	case interface{ Missing() bool }:
+		if cause.Missing() {
+			return exitCodeNotFound
+		}
+	case net.Error:
+		return exitCodeNetwork
+	}
+
+	if corrupt, ok := errors.Cause(err).(interface{ Corrupt() bool }); ok && corrupt.Corrupt() {
+		return exitCodeCorrupt
+	}
+	return 0
+}
+
+// exitCodeForStatus returns the exit code for a failed request to the Git LFS
+// API, or for a single object in one, with the given HTTP status code.
+func exitCodeForStatus(status int) int {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return exitCodeAuth
+	case http.StatusNotFound, http.StatusGone:
+		return exitCodeNotFound
+	}
+	return 0
+}
+
+// recordExitCode makes "code" the code with which the command exits if it
+// fails, unless an earlier failure has already set one. A zero code is
+// ignored.
+func recordExitCode(code int) {
+	if code == 0 {
+		return
+	}
+
+	failureCodeMu.Lock()
+	defer failureCodeMu.Unlock()
+
+	if failureCode == 0 {
+		failureCode = code
+	}
+}
+
+// exitCode returns the code with which a failing command exits: that of its
+// first failure which has its own exit code, or exitCodeUsage otherwise.
+func exitCode() int {
+	failureCodeMu.Lock()
+	defer failureCodeMu.Unlock()
+
+	if failureCode == 0 {
+		return exitCodeUsage
+	}
+	return failureCode
+}
+
+func printExitCodes() {
+	fmt.Fprintln(os.Stdout, tr.Tr("exit-codes.header"))
+	fmt.Fprintln(os.Stdout)
+	for _, c := range exitCodes() {
+		fmt.Fprintf(os.Stdout, "  %-5d %s\n", c.Code, c.Description)
+	}
+	fmt.Fprintln(os.Stdout)
+	fmt.Fprintln(os.Stdout, tr.Tr("exit-codes.footer"))
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusError int
+
+func (e statusError) Error() string { return fmt.Sprintf("HTTP %d", int(e)) }
+
+func (e statusError) HTTPResponse() *http.Response {
+	return &http.Response{StatusCode: int(e)}
+}
+
+func TestExitCodeFor(t *testing.T) {
+	network := &url.Error{Op: "Post", URL: "http://example.com", Err: &net.OpError{
+		Op: "dial", Net: "tcp", Err: errors.New("connection refused"),
+	}}
+
+	for desc, c := range map[string]struct {
+		Err  error
+		Code int
+	}{
+		"nil":             {nil, 0},
+		"other":           {errors.New("boom"), 0},
+		"auth":            {errors.NewAuthError(errors.New("boom")), exitCodeAuth},
+		"http forbidden":  {errors.Wrap(statusError(403), "batch"), exitCodeAuth},
+		"http not found":  {errors.Wrap(statusError(404), "batch"), exitCodeNotFound},
+		"http server":     {statusError(500), 0},
+		"object missing":  {errors.Wrapf(&tq.ObjectError{Code: 404, Message: "Object does not exist"}, "[oid]"), exitCodeNotFound},
+		"object rejected": {errors.Wrapf(&tq.ObjectError{Code: 422, Message: "Invalid object"}, "[oid]"), 0},
+		"network":         {errors.Wrap(network, "batch"), exitCodeNetwork},
+		"corrupt":         {&tq.MalformedObjectError{Name: "a.dat", Oid: "oid"}, exitCodeCorrupt},
+	} {
+		assert.Equal(t, c.Code, exitCodeFor(c.Err), desc)
+	}
+}
+
+func TestExitCodesAreDocumented(t *testing.T) {
+	doc, err := ioutil.ReadFile("../docs/man/git-lfs.1.ronn")
+	require.Nil(t, err)
+
+	for _, c := range exitCodes() {
+		entry := fmt.Sprintf("* `%d`:\n  %s\n", c.Code, c.Description)
+		assert.True(t, strings.Contains(string(doc), entry),
+			"git-lfs.1.ronn should list exit code %d as %q", c.Code, c.Description)
+	}
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+)
+
+// objectsDirManifestName is the name of the file which lists the objects
+// written to a directory by `git lfs export-objects --output`.
+const objectsDirManifestName = "manifest.json"
+
+// exportedObject is an entry in the manifest of an exported directory.
+type exportedObject struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+	// Name is the name of the file holding the object, relative to the
+	// directory, such as "<oid>" or "<oid>.gz".
+	Name string `json:"name"`
+}
+
+type exportedManifest struct {
+	Compression string            `json:"compression,omitempty"`
+	Objects     []*exportedObject `json:"objects"`
+}
+
+// exportObjectsToDirectory copies, compresses or links every object in the
+// local object store which was written after --since into "dir", named by
+// OID alone, and writes a manifest of them alongside.
+func exportObjectsToDirectory(dir string) {
+	switch exportObjectsCompress {
+	case "", "gzip":
+	default:
+		ExitMsg("export-objects.unsupported-compress", tr.Args{"Format": exportObjectsCompress})
+	}
+	if exportObjectsLink && len(exportObjectsCompress) > 0 {
+		ExitMsg("export-objects.link-with-compress")
+	}
+
+	var since time.Time
+	if len(exportObjectsSince) > 0 {
+		var err error
+		if since, err = parseSince(exportObjectsSince, time.Now()); err != nil {
+			ExitMsg("export-objects.invalid-since", tr.Args{"Err": err})
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		ExitMsg("common.create-failed", tr.Args{"Path": dir, "Err": err})
+	}
+
+	var objects []fs.Object
+	err := cfg.Filesystem().EachObject(func(obj fs.Object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	if err != nil {
+		ExitWithError(errors.Wrap(err, "Could not scan the local object store"))
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Oid < objects[j].Oid })
+
+	manifest := &exportedManifest{
+		Compression: exportObjectsCompress,
+		Objects:     make([]*exportedObject, 0, len(objects)),
+	}
+	var size int64
+	var failed int
+	for _, obj := range objects {
+		src := cfg.Filesystem().ObjectPathname(obj.Oid)
+		if !since.IsZero() {
+			info, err := os.Stat(src)
+			if err != nil || !info.ModTime().After(since) {
+				continue
+			}
+		}
+
+		name := obj.Oid
+		if exportObjectsCompress == "gzip" {
+			name += ".gz"
+		}
+		if err := exportObjectFile(src, filepath.Join(dir, name)); err != nil {
+			ErrorMsg("export-objects.failed", tr.Args{"Oid": obj.Oid, "Err": err})
+			failed++
+			continue
+		}
+
+		manifest.Objects = append(manifest.Objects, &exportedObject{
+			Oid:  obj.Oid,
+			Size: obj.Size,
+			Name: name,
+		})
+		size += obj.Size
+	}
+
+	if err := writeExportedManifest(filepath.Join(dir, objectsDirManifestName), manifest); err != nil {
+		ExitMsg("export-objects.manifest-failed", tr.Args{"Err": err})
+	}
+
+	PrintMsg("export-objects.exported", tr.Args{
+		"Count": len(manifest.Objects),
+		"Size":  humanize.FormatBytes(uint64(size)),
+		"Dir":   dir,
+	})
+	if failed > 0 {
+		ExitMsg("export-objects.failed-count", tr.Args{"Count": failed})
+	}
+}
+
+// exportObjectFile writes the object at "src" to "dest", replacing any file
+// which is already there.
+func exportObjectFile(src, dest string) error {
+	if exportObjectsLink {
+		abs, err := filepath.Abs(src)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(dest); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return os.Symlink(abs, dest)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := tools.TempFile(filepath.Dir(dest), filepath.Base(dest), cfg)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	var w io.WriteCloser = tmp
+	if exportObjectsCompress == "gzip" {
+		w = gzip.NewWriter(tmp)
+	}
+	_, err = io.Copy(w, in)
+	if w != tmp {
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+func writeExportedManifest(path string, manifest *exportedManifest) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	err = enc.Encode(manifest)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// parseSince parses a date given to --since, either as "2006-01-02", an RFC
+// 3339 timestamp, or an age relative to "now" such as "30d", "2w" or "12h".
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	age, err := parseAge(s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("expected a date such as 2006-01-02 or an age such as 30d, got %q", s)
+	}
+	return now.Add(-age), nil
+}
@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
+)
+
+// fetchCheckpoint records the objects downloaded by 'git lfs fetch
+// --checkpoint', so that a fetch which is interrupted can skip them when it is
+// run again.
+//
+// The file holds one JSON object per line, each naming an object which was
+// downloaded, and is only ever appended to. A line which was cut short by a
+// crash is ignored when the file is read back.
+type fetchCheckpoint struct {
+	path string
+	// auto is whether the path was chosen by --checkpoint=auto, in which
+	// case the file is removed once the fetch succeeds.
+	auto bool
+
+	mu   sync.Mutex
+	done map[string]bool
+	file *os.File
+	wg   sync.WaitGroup
+
+	skipped int
+}
+
+// fetchCheckpointEntry is a single line of a checkpoint file.
+type fetchCheckpointEntry struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// newFetchCheckpoint opens the checkpoint file at "path", creating it if it
+// does not exist, and reads the objects already recorded in it.
+func newFetchCheckpoint(path string) (*fetchCheckpoint, error) {
+	c := &fetchCheckpoint{path: path, done: make(map[string]bool)}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrapf(err, "read checkpoint %s", path)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		var entry fetchCheckpointEntry
+		if err := json.Unmarshal(line, &entry); err != nil || len(entry.Oid) == 0 {
+			continue
+		}
+		c.done[entry.Oid] = true
+	}
+
+	c.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open checkpoint %s", path)
+	}
+
+	// If the last line was cut short, start a new one so that the next
+	// entry can be read back.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err := c.file.Write([]byte("\n")); err != nil {
+			c.file.Close()
+			return nil, errors.Wrapf(err, "write checkpoint %s", path)
+		}
+	}
+	return c, nil
+}
+
+// autoFetchCheckpointPath returns the checkpoint file used by
+// --checkpoint=auto for a fetch of "refs" from the Git LFS server at "url". It
+// is named after both, so that different fetches do not share a checkpoint.
+func autoFetchCheckpointPath(url string, refs []string) (string, error) {
+	dir := filepath.Join(cfg.LFSStorageDir(), "checkpoints")
+	if err := tools.MkdirAll(dir, cfg); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(url + "\n" + strings.Join(refs, "\n")))
+	return filepath.Join(dir, "fetch-"+hex.EncodeToString(sum[:8])+".jsonl"), nil
+}
+
+// skip returns the objects in "pointers" which are not recorded in the
+// checkpoint.
+func (c *fetchCheckpoint) skip(pointers []*lfs.WrappedPointer) []*lfs.WrappedPointer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	remaining := make([]*lfs.WrappedPointer, 0, len(pointers))
+	for _, p := range pointers {
+		if c.done[p.Oid] {
+			c.skipped++
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	return remaining
+}
+
+// record appends the object "oid" to the checkpoint, and flushes it to disk,
+// once it has been downloaded.
+func (c *fetchCheckpoint) record(oid string, size int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.done[oid] {
+		return nil
+	}
+
+	line, err := json.Marshal(&fetchCheckpointEntry{Oid: oid, Size: size})
+	if err != nil {
+		return err
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	c.done[oid] = true
+	return c.file.Sync()
+}
+
+// watch records each object downloaded by "q" in the checkpoint, until wait is
+// called after the queue has finished.
+func (c *fetchCheckpoint) watch(q *tq.TransferQueue) {
+	watch := q.Watch()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for t := range watch {
+			if err := c.record(t.Oid, t.Size); err != nil {
+				ErrorMsg("fetch.checkpoint-update-error", tr.Args{"Path": c.path, "Err": err})
+			}
+		}
+	}()
+}
+
+// wait waits until every object downloaded by the queues passed to watch has
+// been recorded.
+func (c *fetchCheckpoint) wait() {
+	c.wg.Wait()
+}
+
+// close closes the checkpoint file, and removes it if it was chosen by
+// --checkpoint=auto and the fetch succeeded, so that a later fetch does not
+// skip objects which have since been pruned.
+func (c *fetchCheckpoint) close(success bool) {
+	c.file.Close()
+
+	if c.skipped > 0 {
+		InfoMsg("fetch.checkpoint-skipped", tr.Args{"Count": c.skipped, "Path": c.path})
+	}
+	if c.auto && success {
+		os.Remove(c.path)
+	}
+}
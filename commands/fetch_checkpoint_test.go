@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchCheckpointSkipsRecordedObjects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fetch-checkpoint")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint.jsonl")
+
+	checkpoint, err := newFetchCheckpoint(path)
+	require.Nil(t, err)
+	require.Nil(t, checkpoint.record("a", 1))
+	require.Nil(t, checkpoint.record("b", 2))
+	require.Nil(t, checkpoint.record("a", 1))
+	checkpoint.file.Close()
+
+	// Simulate a crash part of the way through writing a line.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.Nil(t, err)
+	_, err = f.Write([]byte(`{"oid":"c","si`))
+	require.Nil(t, err)
+	f.Close()
+
+	checkpoint, err = newFetchCheckpoint(path)
+	require.Nil(t, err)
+	remaining := checkpoint.skip([]*lfs.WrappedPointer{
+		{Name: "a.dat", Pointer: &lfs.Pointer{Oid: "a", Size: 1}},
+		{Name: "b.dat", Pointer: &lfs.Pointer{Oid: "b", Size: 2}},
+		{Name: "c.dat", Pointer: &lfs.Pointer{Oid: "c", Size: 3}},
+	})
+	if assert.Len(t, remaining, 1) {
+		assert.Equal(t, "c.dat", remaining[0].Name)
+	}
+	assert.Equal(t, 2, checkpoint.skipped)
+
+	require.Nil(t, checkpoint.record("c", 3))
+	checkpoint.file.Close()
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, `{"oid":"a","size":1}`+"\n"+
+		`{"oid":"b","size":2}`+"\n"+
+		`{"oid":"c","si`+"\n"+
+		`{"oid":"c","size":3}`+"\n", string(data))
+}
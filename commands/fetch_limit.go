@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"sort"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+)
+
+// fetchLimit bounds the total size of the objects downloaded by one run of
+// 'git lfs fetch --limit-bytes', across every ref it fetches.
+type fetchLimit struct {
+	limit uint64
+
+	fetchedCount int
+	fetchedBytes uint64
+	totalCount   int
+	totalBytes   uint64
+	reached      bool
+}
+
+func newFetchLimit(value string) (*fetchLimit, error) {
+	limit, err := humanize.ParseBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchLimit{limit: limit}, nil
+}
+
+// take returns as many of the missing objects in "pointers" as can be
+// downloaded without the running total exceeding the limit. The smallest
+// objects are taken first, so that as many as possible are downloaded.
+func (l *fetchLimit) take(pointers []*lfs.WrappedPointer) []*lfs.WrappedPointer {
+	sorted := make([]*lfs.WrappedPointer, len(pointers))
+	copy(sorted, pointers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Size < sorted[j].Size
+	})
+
+	taken := make([]*lfs.WrappedPointer, 0, len(sorted))
+	for _, p := range sorted {
+		l.totalCount++
+		l.totalBytes += uint64(p.Size)
+
+		if l.reached || l.fetchedBytes+uint64(p.Size) > l.limit {
+			l.reached = true
+			continue
+		}
+
+		l.fetchedCount++
+		l.fetchedBytes += uint64(p.Size)
+		taken = append(taken, p)
+	}
+	return taken
+}
+
+// report tells the user how much was fetched, if the limit stopped any
+// objects from being fetched.
+func (l *fetchLimit) report() {
+	if !l.reached {
+		return
+	}
+
+	PrintMsg("fetch.limit-reached", tr.Args{
+		"Fetched":      l.fetchedCount,
+		"Total":        l.totalCount,
+		"FetchedBytes": humanize.FormatBytes(l.fetchedBytes),
+		"TotalBytes":   humanize.FormatBytes(l.totalBytes),
+	})
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchLimitTakesSmallestObjectsUpToLimit(t *testing.T) {
+	limit, err := newFetchLimit("10")
+	assert.Nil(t, err)
+
+	taken := limit.take([]*lfs.WrappedPointer{
+		{Name: "large.dat", Pointer: &lfs.Pointer{Oid: "c", Size: 6}},
+		{Name: "small.dat", Pointer: &lfs.Pointer{Oid: "a", Size: 1}},
+		{Name: "medium.dat", Pointer: &lfs.Pointer{Oid: "b", Size: 3}},
+		{Name: "other.dat", Pointer: &lfs.Pointer{Oid: "d", Size: 6}},
+	})
+
+	// 1 + 3 + 6 fills the limit exactly; the second 6-byte object would
+	// exceed it.
+	if assert.Len(t, taken, 3) {
+		assert.Equal(t, "small.dat", taken[0].Name)
+		assert.Equal(t, "medium.dat", taken[1].Name)
+		assert.Equal(t, "large.dat", taken[2].Name)
+	}
+	assert.True(t, limit.reached)
+	assert.Equal(t, 3, limit.fetchedCount)
+	assert.Equal(t, uint64(10), limit.fetchedBytes)
+	assert.Equal(t, 4, limit.totalCount)
+	assert.Equal(t, uint64(16), limit.totalBytes)
+
+	// Once reached, the limit applies to later refs too.
+	assert.Empty(t, limit.take([]*lfs.WrappedPointer{
+		{Name: "tiny.dat", Pointer: &lfs.Pointer{Oid: "e", Size: 0}},
+	}))
+	assert.Equal(t, 5, limit.totalCount)
+}
+
+func TestFetchLimitNotReached(t *testing.T) {
+	limit, err := newFetchLimit("1KB")
+	assert.Nil(t, err)
+
+	taken := limit.take([]*lfs.WrappedPointer{
+		{Name: "a.dat", Pointer: &lfs.Pointer{Oid: "a", Size: 1000}},
+	})
+	assert.Len(t, taken, 1)
+	assert.False(t, limit.reached)
+}
+
+func TestFetchLimitRejectsInvalidSizes(t *testing.T) {
+	_, err := newFetchLimit("lots")
+	assert.NotNil(t, err)
+}
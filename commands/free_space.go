@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/rubyist/tracerx"
+)
+
+const defaultMinFreeSpace = "1GB"
+
+// minFreeSpace returns how much space must be left free on the volume holding
+// the object store after downloading objects, as configured by
+// lfs.minfreespace. A value of zero disables the check.
+func minFreeSpace() uint64 {
+	value, ok := cfg.Git.Get("lfs.minfreespace")
+	if !ok || len(value) == 0 {
+		value = defaultMinFreeSpace
+	}
+
+	size, err := humanize.ParseBytes(value)
+	if err != nil {
+		ExitMsg("free-space.invalid-min", tr.Args{"Value": value})
+	}
+	return size
+}
+
+// checkFreeSpace returns an error unless downloading the objects for
+// "pointers" would leave at least lfs.minfreespace free on the volume holding
+// the object store. It always succeeds if "force" is set, or if the free space
+// on the volume can't be determined.
+func checkFreeSpace(pointers []*lfs.WrappedPointer, force bool) error {
+	if force || len(pointers) == 0 {
+		return nil
+	}
+	min := minFreeSpace()
+	if min == 0 {
+		return nil
+	}
+
+	var size uint64
+	seen := make(map[string]bool, len(pointers))
+	for _, p := range pointers {
+		if !seen[p.Oid] && p.Size > 0 {
+			seen[p.Oid] = true
+			size += uint64(p.Size)
+		}
+	}
+
+	dir := existingParent(cfg.LFSObjectDir())
+	free, err := tools.FreeSpace(dir)
+	if err != nil {
+		tracerx.Printf("free space: unable to determine free space in %s: %s", dir, err)
+		return nil
+	}
+	tracerx.Printf("free space: %d byte(s) free in %s, downloading %d byte(s)", free, dir, size)
+
+	if free >= size && free-size >= min {
+		return nil
+	}
+
+	return errors.Errorf("Not enough free space in %s to download %s: %s is free, and lfs.minfreespace is %s.\n"+
+		"Free up %s, or run again with --force to download anyway.",
+		dir, humanize.FormatBytes(size), humanize.FormatBytes(free), humanize.FormatBytes(min),
+		humanize.FormatBytes(size+min-free))
+}
+
+// existingParent returns "dir", or its closest ancestor which exists, since
+// the object store may not have been created yet.
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
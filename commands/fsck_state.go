@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// fsckStateVersion is the version of the format of the fsck state
+	// file. A state file of any other version is discarded.
+	fsckStateVersion = 1
+
+	// defaultFsckSamplePercent is the percentage of unchanged objects which
+	// `git lfs fsck --incremental` hashes again anyway, unless
+	// lfs.fsck.samplepercent says otherwise.
+	defaultFsckSamplePercent = 1
+)
+
+// fsckState records when each object in the local store was last verified,
+// along with the size and modification time it had then, so that `git lfs
+// fsck --incremental` need only hash the objects which have changed since.
+type fsckState struct {
+	Version    int                        `json:"version"`
+	VerifiedAt time.Time                  `json:"verified_at"`
+	Objects    map[string]fsckStateObject `json:"objects"`
+}
+
+type fsckStateObject struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"`
+}
+
+func newFsckState() *fsckState {
+	return &fsckState{
+		Version: fsckStateVersion,
+		Objects: make(map[string]fsckStateObject),
+	}
+}
+
+func fsckStatePath() string {
+	return filepath.Join(cfg.LFSStorageDir(), "fsck-state.json")
+}
+
+// loadFsckState reads the state recorded by the last run of fsck. It returns
+// nil, so that every object is verified, if there is no state, or if it cannot
+// be read or is of another version.
+func loadFsckState() *fsckState {
+	data, err := ioutil.ReadFile(fsckStatePath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		PrintMsg("fsck.state-unreadable", tr.Args{"Err": err})
+		return nil
+	}
+
+	state := &fsckState{}
+	if err := json.Unmarshal(data, state); err != nil || state.Objects == nil {
+		PrintMsg("fsck.state-invalid")
+		return nil
+	}
+	if state.Version != fsckStateVersion {
+		tracerx.Printf("fsck: discarding state of version %d", state.Version)
+		return nil
+	}
+	return state
+}
+
+// save writes the state atomically, so that an interrupted write cannot
+// leave a truncated state behind.
+func (s *fsckState) save() error {
+	s.VerifiedAt = time.Now()
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(cfg.TempDir(), "fsck-state")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), cfg.RepositoryPermissions(false)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fsckStatePath())
+}
+
+// fsckVerifier decides which objects fsck must hash, and records those which
+// were found to be intact.
+type fsckVerifier struct {
+	// last is the state of the last run, or nil if every object must be
+	// hashed.
+	last *fsckState
+	// next is the state to record for this run.
+	next *fsckState
+
+	sample  float64
+	random  *rand.Rand
+	skipped int
+}
+
+// newFsckVerifier returns a verifier which hashes every object, unless
+// "incremental" is set, in which case only new and changed objects are hashed,
+// along with a sample of the others.
+func newFsckVerifier(incremental bool) *fsckVerifier {
+	v := &fsckVerifier{
+		next:   newFsckState(),
+		sample: float64(cfg.Git.Int("lfs.fsck.samplepercent", defaultFsckSamplePercent)) / 100,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if incremental {
+		v.last = loadFsckState()
+	}
+	return v
+}
+
+// Verify returns whether the object "oid" must be hashed. Objects which have
+// not changed since they were last verified are skipped, and carried over to
+// the next state as they were.
+func (v *fsckVerifier) Verify(oid string) bool {
+	if v.last == nil {
+		return true
+	}
+
+	last, ok := v.last.Objects[oid]
+	if !ok {
+		return true
+	}
+
+	meta, err := lfs.StatObject(cfg, oid)
+	if err != nil || !meta.HasSize(last.Size) || meta.ModTime.UnixNano() != last.ModTime {
+		return true
+	}
+
+	if v.random.Float64() < v.sample {
+		return true
+	}
+
+	v.next.Objects[oid] = last
+	v.skipped++
+	return false
+}
+
+// Verified records that the object "oid" was hashed and found to be intact.
+func (v *fsckVerifier) Verified(oid string) {
+	meta, err := lfs.StatObject(cfg, oid)
+	if err != nil || !meta.Exists {
+		return
+	}
+	v.next.Objects[oid] = fsckStateObject{
+		Size:    meta.Size,
+		ModTime: meta.ModTime.UnixNano(),
+	}
+}
+
+// Skipped returns the number of objects which were not hashed.
+func (v *fsckVerifier) Skipped() int {
+	return v.skipped
+}
+
+// Save records the objects found to be intact by this run.
+func (v *fsckVerifier) Save() error {
+	return v.next.save()
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/lfs"
+)
+
+// installCheck is the state of the Git LFS setup reported by `git lfs install
+// --check`.
+type installCheck struct {
+	// Ready is whether Git LFS is fully set up: Git is new enough, the
+	// filters are configured and, in a repository, every hook is
+	// installed.
+	Ready           bool                `json:"ready"`
+	Git             installCheckGit     `json:"git"`
+	Filter          installCheckFilter  `json:"filter"`
+	InRepo          bool                `json:"in_repo"`
+	Hooks           []*installCheckHook `json:"hooks"`
+	TrackedPatterns []string            `json:"tracked_patterns"`
+}
+
+type installCheckGit struct {
+	Version   string `json:"version"`
+	Minimum   string `json:"minimum"`
+	Supported bool   `json:"supported"`
+}
+
+type installCheckFilter struct {
+	Configured bool              `json:"configured"`
+	Scope      string            `json:"scope,omitempty"`
+	Values     map[string]string `json:"values"`
+}
+
+type installCheckHook struct {
+	Type  string        `json:"type"`
+	Path  string        `json:"path"`
+	State lfs.HookState `json:"state"`
+}
+
+// installCheckCommand reports whether Git LFS is set up for the current user
+// and repository as JSON, without changing anything, and exits with a non-zero
+// status if it is not fully set up.
+func installCheckCommand() {
+	check := &installCheck{
+		Git: installCheckGit{
+			Minimum:   minimumGitVersion,
+			Supported: git.IsGitVersionAtLeast(minimumGitVersion),
+		},
+		Filter: installCheckFilter{
+			Values: make(map[string]string),
+		},
+		InRepo:          cfg.InRepo(),
+		Hooks:           make([]*installCheckHook, 0),
+		TrackedPatterns: make([]string, 0),
+	}
+	if v, err := git.Version(); err == nil {
+		check.Git.Version = strings.TrimPrefix(v, "git version ")
+	}
+
+	gitConfig := cfg.GitConfig()
+	opts := &lfs.FilterOptions{GitConfig: gitConfig}
+	check.Filter.Configured = opts.Installed()
+	check.Filter.Scope = gitConfig.FindScope("filter.lfs.process")
+	for _, key := range []string{"process", "smudge", "clean", "required"} {
+		if value := gitConfig.Find("filter.lfs." + key); len(value) > 0 {
+			check.Filter.Values[key] = value
+		}
+	}
+
+	check.Ready = check.Git.Supported && check.Filter.Configured
+	if check.InRepo {
+		hookDir, err := cfg.HookDir()
+		if err != nil {
+			ExitWithError(err)
+		}
+		for _, h := range lfs.LoadHooks(hookDir, cfg) {
+			state := h.State()
+			check.Hooks = append(check.Hooks, &installCheckHook{
+				Type:  string(h.Type),
+				Path:  h.Path(),
+				State: state,
+			})
+			check.Ready = check.Ready && state == lfs.HookInstalled
+		}
+
+		check.TrackedPatterns = append(check.TrackedPatterns, repoTrackedPatterns()...)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(check); err != nil {
+		ExitWithError(err)
+	}
+	if !check.Ready {
+		os.Exit(1)
+	}
+}
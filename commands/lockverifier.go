@@ -12,6 +12,7 @@ import (
 	"github.com/git-lfs/git-lfs/lfshttp"
 	"github.com/git-lfs/git-lfs/locking"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 )
 
 type verifyState byte
@@ -63,21 +64,21 @@ func (lv *lockVerifier) Verify(ref *git.Ref) {
 		} else if lv.verifyState == verifyStateUnknown || lv.verifyState == verifyStateEnabled {
 			if errors.IsAuthError(err) {
 				if lv.verifyState == verifyStateUnknown {
-					Error("WARNING: Authentication error: %s", err)
+					ErrorMsg("lock-verify.auth-warning", tr.Args{"Err": err})
 				} else if lv.verifyState == verifyStateEnabled {
-					Exit("ERROR: Authentication error: %s", err)
+					ExitMsg("lock-verify.auth-error", tr.Args{"Err": err})
 				}
 			} else {
-				Print("Remote %q does not support the LFS locking API. Consider disabling it with:", cfg.PushRemote())
-				Print("  $ git config lfs.%s.locksverify false", lv.endpoint.Url)
+				InfoMsg("lock-verify.unsupported", tr.Args{"Remote": cfg.PushRemote()})
+				InfoMsg("lock-verify.disable-command", tr.Args{"Endpoint": lv.endpoint.Url})
 				if lv.verifyState == verifyStateEnabled {
 					ExitWithError(err)
 				}
 			}
 		}
 	} else if lv.verifyState == verifyStateUnknown {
-		Print("Locking support detected on remote %q. Consider enabling it with:", cfg.PushRemote())
-		Print("  $ git config lfs.%s.locksverify true", lv.endpoint.Url)
+		InfoMsg("lock-verify.supported", tr.Args{"Remote": cfg.PushRemote()})
+		InfoMsg("lock-verify.enable-command", tr.Args{"Endpoint": lv.endpoint.Url})
 	}
 
 	lv.addLocks(ref, ours, lv.ourLocks)
@@ -89,7 +90,11 @@ func (lv *lockVerifier) addLocks(ref *git.Ref, locks []locking.Lock, set map[str
 	for _, l := range locks {
 		if rl, ok := set[l.Path]; ok {
 			if err := rl.Add(ref, l); err != nil {
-				Error("WARNING: error adding %q lock for ref %q: %+v", l.Path, ref, err)
+				ErrorMsg("lock-verify.add-lock-failed", tr.Args{
+					"Path": l.Path,
+					"Ref":  ref,
+					"Err":  fmt.Sprintf("%+v", err),
+				})
 			}
 		} else {
 			set[l.Path] = lv.newRefLocks(ref, l)
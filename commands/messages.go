@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"github.com/git-lfs/git-lfs/tr"
+)
+
+// The functions in this file print a message from the message catalog in
+// package tr, in the user's language, as the function of the same name without
+// the "Msg" suffix prints a formatted string. Messages which are printed for
+// people to read should be printed with them, so that they can be translated.
+// Output which is read by programs, such as JSON, must be printed without them.
+
+// PrintMsg prints the catalog message "id" to Stdout, like Print.
+func PrintMsg(id string, args ...tr.Args) {
+	Print("%s", tr.Tr(id, args...))
+}
+
+// InfoMsg prints the catalog message "id" to Stdout, like Info, unless --quiet
+// was given.
+func InfoMsg(id string, args ...tr.Args) {
+	Info("%s", tr.Tr(id, args...))
+}
+
+// VerboseMsg prints the catalog message "id" to Stdout, like Verbose, if
+// --verbose was given.
+func VerboseMsg(id string, args ...tr.Args) {
+	Verbose("%s", tr.Tr(id, args...))
+}
+
+// ErrorMsg prints the catalog message "id" to Stderr, like Error.
+func ErrorMsg(id string, args ...tr.Args) {
+	Error("%s", tr.Tr(id, args...))
+}
+
+// ExitMsg prints the catalog message "id" to Stderr and exits, like Exit.
+func ExitMsg(id string, args ...tr.Args) {
+	Exit("%s", tr.Tr(id, args...))
+}
+
+// ExitWithErrorMsg prints the catalog message "id" and exits with the exit
+// code for "err", like ExitWithErrorf.
+func ExitWithErrorMsg(err error, id string, args ...tr.Args) {
+	ExitWithErrorf(err, "%s", tr.Tr(id, args...))
+}
+
+// LoggedErrorMsg prints the catalog message "id", and logs "err", like
+// LoggedError.
+func LoggedErrorMsg(err error, id string, args ...tr.Args) {
+	LoggedError(err, "%s", tr.Tr(id, args...))
+}
+
+// PanicMsg prints the catalog message "id", logs "err", and exits, like Panic.
+func PanicMsg(err error, id string, args ...tr.Args) {
+	Panic(err, "%s", tr.Tr(id, args...))
+}
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/tr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// machineReadableFormats are the format strings, or strings, printed from
+// package commands which are output for programs to read, and so must not be
+// translated.
+var machineReadableFormats = map[string]bool{
+	// git lfs count-objects, like git count-objects
+	"%d objects, %d bytes":           true,
+	"incomplete: %d files, %d bytes": true,
+	"tmp: %d files, %d bytes":        true,
+	"%s: %d objects, %d bytes":       true,
+
+	// git lfs diff, like git diff
+	"diff --git a/%s b/%s":          true,
+	"Binary files %s and %s differ": true,
+
+	// git lfs env
+	"Endpoint=%s (auth=%s)":      true,
+	"Endpoint (%s)=%s (auth=%s)": true,
+	"  SSH=%s:%s":                true,
+	"git config %s = %q (%s)":    true,
+	"git config %s = %q":         true,
+
+	// git lfs ext
+	"Extension: %s":     true,
+	"    clean = %s":    true,
+	"    smudge = %s":   true,
+	"    priority = %d": true,
+
+	// git lfs ls-files --debug
+	"filepath: %s\n    size: %d\ncheckout: %v\ndownload: %v\n     oid: %s %s\n version: %s\n": true,
+
+	// git lfs locks
+	"%s%s%s\t%s%s\tID:%s": true,
+
+	// git lfs migrate, the filters hashed into the name of its state
+	"include %s\x00": true,
+	"exclude %s\x00": true,
+
+	// git lfs push --dry-run
+	"push %s => %s": true,
+
+	// git lfs trace, the command recorded in the log
+	"$ git %s\n": true,
+}
+
+// formatFuncs are the functions which print a format string, and the index of
+// the format string in their arguments.
+var formatFuncs = map[string]int{
+	"Print":          0,
+	"Info":           0,
+	"Verbose":        0,
+	"Error":          0,
+	"Exit":           0,
+	"Panic":          1,
+	"LoggedError":    1,
+	"ExitWithErrorf": 1,
+}
+
+// fmtFuncs are the functions of package fmt which print, the index of the
+// first of their arguments which is printed, and whether it is a format
+// string, rather than the first of several values.
+var fmtFuncs = map[string]struct {
+	index  int
+	format bool
+}{
+	"Print":    {0, false},
+	"Println":  {0, false},
+	"Printf":   {0, true},
+	"Fprint":   {1, false},
+	"Fprintln": {1, false},
+	"Fprintf":  {1, true},
+}
+
+// msgFuncs are the functions which print a message from the catalog, and the
+// index of its ID in their arguments.
+var msgFuncs = map[string]int{
+	"PrintMsg":         0,
+	"InfoMsg":          0,
+	"VerboseMsg":       0,
+	"ErrorMsg":         0,
+	"ExitMsg":          0,
+	"ExitWithErrorMsg": 1,
+	"LoggedErrorMsg":   1,
+	"PanicMsg":         1,
+}
+
+var formatVerbRE = regexp.MustCompile(`%[-+# 0-9.*]*[a-zA-Z%]`)
+var letterRE = regexp.MustCompile(`[a-zA-Z]`)
+
+func TestMessagesUseCatalog(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.Nil(t, err)
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		require.Nil(t, err)
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			pos := fset.Position(call.Pos())
+
+			switch fn := call.Fun.(type) {
+			case *ast.Ident:
+				if i, ok := formatFuncs[fn.Name]; ok && i < len(call.Args) {
+					assertTranslated(t, pos, fn.Name, call.Args[i])
+				}
+
+				if i, ok := msgFuncs[fn.Name]; ok && i < len(call.Args) {
+					assertInCatalog(t, pos, fn.Name, call.Args[i])
+				}
+			case *ast.SelectorExpr:
+				pkg, ok := fn.X.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				name := pkg.Name + "." + fn.Sel.Name
+
+				if f, ok := fmtFuncs[fn.Sel.Name]; ok && pkg.Name == "fmt" && f.index < len(call.Args) {
+					args := call.Args[f.index:]
+					if f.format {
+						args = args[:1]
+					}
+					for _, arg := range args {
+						assertTranslated(t, pos, name, arg)
+					}
+				}
+
+				// Messages are looked up with a variable ID only
+				// by the functions in messages.go.
+				if name == "tr.Tr" && len(call.Args) > 0 && file != "messages.go" {
+					assertInCatalog(t, pos, name, call.Args[0])
+				}
+			}
+			return true
+		})
+	}
+}
+
+// assertTranslated asserts that "arg", given to the function "fn", is not a
+// string literal with words in it, other than one of machineReadableFormats.
+func assertTranslated(t *testing.T, pos token.Position, fn string, arg ast.Expr) {
+	format, ok := stringLiteral(arg)
+	if !ok || machineReadableFormats[format] {
+		return
+	}
+
+	words := formatVerbRE.ReplaceAllString(format, "")
+	assert.False(t, letterRE.MatchString(words),
+		"%s: %s(%q) is not translated; use a message in tr/locales/en.json, or machineReadableFormats if programs read it",
+		pos, fn, format)
+}
+
+// assertInCatalog asserts that "arg", given to the function "fn", is the ID
+// of a message in the catalog.
+func assertInCatalog(t *testing.T, pos token.Position, fn string, arg ast.Expr) {
+	id, ok := stringLiteral(arg)
+	if assert.True(t, ok, "%s: the ID given to %s must be a string literal", pos, fn) {
+		assert.True(t, tr.Has(id), "%s: %q is not in tr/locales/en.json", pos, id)
+	}
+}
+
+// stringLiteral returns the value of "expr" if it is a string literal, or a
+// concatenation of them, folding it as the compiler would.
+func stringLiteral(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return stringLiteral(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringLiteral(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringLiteral(e.Y)
+		return x + y, ok
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	}
+	return "", false
+}
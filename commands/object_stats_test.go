@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectStatsBreakdown(t *testing.T) {
+	localObjects := map[string]int64{
+		"a": 10,
+		"b": 20,
+		"c": 30,
+		"d": 5,
+	}
+	found := &objectStatsRefs{
+		paths: map[string]tools.StringSet{
+			"a": tools.NewStringSetFromSlice([]string{"one.psd", "dir/two.psd"}),
+			"b": tools.NewStringSetFromSlice([]string{"three.psd", "three.png"}),
+			"c": tools.NewStringSetFromSlice([]string{"README"}),
+		},
+		refs: map[string]tools.StringSet{
+			"a": tools.NewStringSetFromSlice([]string{"refs/heads/main", "refs/tags/v1"}),
+			"b": tools.NewStringSetFromSlice([]string{"refs/heads/main"}),
+			"c": tools.NewStringSet(),
+		},
+	}
+
+	entries := objectStatsBreakdown(localObjects, found)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return objectStatsOrders["extension"](entries[i], entries[j])
+	})
+
+	for _, entry := range entries {
+		entry.refs = nil
+	}
+	assert.Equal(t, []*objectStatsEntry{
+		{Extension: "(none)", Count: 1, Size: 30, Average: 30, Refs: 0},
+		{Extension: "(unreferenced)", Count: 1, Size: 5, Average: 5, Refs: 0},
+		{Extension: ".png", Count: 1, Size: 20, Average: 20, Refs: 1},
+		{Extension: ".psd", Count: 2, Size: 30, Average: 15, Refs: 2},
+	}, entries)
+}
@@ -0,0 +1,56 @@
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/git-lfs/git-lfs/subprocess"
+)
+
+// exitWithParent exits once the process which started this one has exited.
+// When run as "git lfs proxy", the parent is git, which waits for this
+// process, so the parent of git is watched too.
+func exitWithParent() {
+	ppid := os.Getppid()
+	watched := []int{ppid}
+	if gppid := parentOf(ppid); gppid > 1 {
+		watched = append(watched, gppid)
+	}
+
+	for range time.Tick(time.Second) {
+		if os.Getppid() != ppid || !processesAlive(watched) {
+			ErrorMsg("proxy.parent-exited")
+			os.Exit(0)
+		}
+	}
+}
+
+// parentOf returns the ID of the parent of the process "pid", or 0 if it
+// cannot be found.
+func parentOf(pid int) int {
+	out, err := subprocess.ExecCommand("ps", "-o", "ppid=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0
+	}
+
+	ppid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0
+	}
+	return ppid
+}
+
+// processesAlive returns whether every process in "pids" is still running.
+func processesAlive(pids []int) bool {
+	for _, pid := range pids {
+		if err := syscall.Kill(pid, 0); err != nil && err != syscall.EPERM {
+			return false
+		}
+	}
+	return true
+}
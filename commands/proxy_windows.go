@@ -0,0 +1,7 @@
+// +build windows
+
+package commands
+
+// exitWithParent does nothing on Windows, which does not tell a process when
+// its parent exits, so the proxy runs until it is interrupted.
+func exitWithParent() {}
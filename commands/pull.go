@@ -12,7 +12,9 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/subprocess"
+	"github.com/git-lfs/git-lfs/tools/perf"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 )
 
 // Handles the process of checking out a single file, and updating the git
@@ -29,16 +31,30 @@ func newSingleCheckout(gitEnv config.Environment, remote string) abstractCheckou
 	// Since writing data & calling git update-index must be relative to cwd
 	pathConverter, err := lfs.NewRepoToCurrentPathConverter(cfg)
 	if err != nil {
-		Panic(err, "Could not convert file paths")
+		PanicMsg(err, "pull.convert-paths-failed")
 	}
 
+	gitfilter := lfs.NewGitFilter(cfg)
+	gitfilter.Linked = reportLinked
+
 	return &singleCheckout{
 		gitIndexer:    &gitIndexer{},
+		gitfilter:     gitfilter,
 		pathConverter: pathConverter,
 		manifest:      manifest,
 	}
 }
 
+// reportLinked reports, if --verbose was given, whether the file "name" was
+// hard linked to its object because of lfs.link, or copied, and why.
+func reportLinked(name string, err error) {
+	if err != nil {
+		VerboseMsg("checkout.copied", tr.Args{"Name": name, "Err": err})
+		return
+	}
+	VerboseMsg("checkout.linked", tr.Args{"Name": name})
+}
+
 type abstractCheckout interface {
 	Manifest() *tq.Manifest
 	Skip() bool
@@ -49,6 +65,7 @@ type abstractCheckout interface {
 
 type singleCheckout struct {
 	gitIndexer    *gitIndexer
+	gitfilter     *lfs.GitFilter
 	pathConverter lfs.PathConverter
 	manifest      *tq.Manifest
 }
@@ -64,6 +81,12 @@ func (c *singleCheckout) Skip() bool {
 func (c *singleCheckout) Run(p *lfs.WrappedPointer) {
 	cwdfilepath := c.pathConverter.Convert(p.Name)
 
+	// A symbolic link in place of the file was made by the user, and
+	// writing through it would modify whatever it points to.
+	if fi, err := os.Lstat(cwdfilepath); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return
+	}
+
 	// Check the content - either missing or still this pointer (not exist is ok)
 	filepointer, err := lfs.DecodePointerFromFile(cwdfilepath)
 	if err != nil && !os.IsNotExist(err) {
@@ -72,7 +95,7 @@ func (c *singleCheckout) Run(p *lfs.WrappedPointer) {
 			return
 		}
 
-		LoggedError(err, "Checkout error: %s", err)
+		LoggedErrorMsg(err, "pull.checkout-error", tr.Args{"Err": err})
 		return
 	}
 
@@ -83,31 +106,37 @@ func (c *singleCheckout) Run(p *lfs.WrappedPointer) {
 	}
 
 	if err := c.RunToPath(p, cwdfilepath); err != nil {
-		if errors.IsDownloadDeclinedError(err) {
+		if err == lfs.ErrTooManyQuarantined {
+			c.Close()
+			ExitWithError(err)
+		} else if errors.IsDownloadDeclinedError(err) {
 			// acceptable error, data not local (fetch not run or include/exclude)
-			Error("Skipped checkout for %q, content not local. Use fetch to download.", p.Name)
+			ErrorMsg("pull.skipped-not-local", tr.Args{"Name": p.Name})
 		} else {
-			FullError(fmt.Errorf("could not check out %q", p.Name))
+			FullError(fmt.Errorf("could not check out %q: %v", p.Name, err))
 		}
 		return
 	}
 
 	// errors are only returned when the gitIndexer is starting a new cmd
 	if err := c.gitIndexer.Add(cwdfilepath); err != nil {
-		Panic(err, "Could not update the index")
+		PanicMsg(err, "pull.update-index-failed")
 	}
 }
 
 // RunToPath checks out the pointer specified by p to the given path.  It does
 // not perform any sort of sanity checking or add the path to the index.
 func (c *singleCheckout) RunToPath(p *lfs.WrappedPointer, path string) error {
-	gitfilter := lfs.NewGitFilter(cfg)
-	return gitfilter.SmudgeToFile(path, p.Pointer, false, c.manifest, nil)
+	timer := perf.Start("checkout write")
+	defer timer.Stop()
+
+	return c.gitfilter.SmudgeToFile(path, p.Pointer, false, c.manifest, nil)
 }
 
 func (c *singleCheckout) Close() {
+	reportQuarantined(c.gitfilter)
 	if err := c.gitIndexer.Close(); err != nil {
-		LoggedError(err, "Error updating the git index:\n%s", c.gitIndexer.Output())
+		LoggedErrorMsg(err, "pull.update-index-error", tr.Args{"Output": c.gitIndexer.Output()})
 	}
 }
 
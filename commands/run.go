@@ -5,12 +5,17 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	runtimedebug "runtime/debug"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/git-lfs/git-lfs/config"
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/perf"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/spf13/cobra"
 )
 
@@ -19,15 +24,47 @@ var (
 	commandMu    sync.Mutex
 
 	rootVersion bool
+
+	// progressFormatArg is the format in which every command reports
+	// progress, given by --progress-format.
+	progressFormatArg string
 )
 
 // NewCommand creates a new 'git-lfs' sub command, given a command name and
 // command run function.
 //
 // Each command will initialize the local storage ('.git/lfs') directory when
-// run, unless the PreRun hook is set to nil.
+// run, and offer to set up Git LFS for the current repository if needed,
+// unless the PreRun hook is set to nil.
+//
+// A panic raised while running the command is written to a log file under
+// '.git/lfs/logs' before exiting, so that it can be found with 'git lfs logs'.
 func NewCommand(name string, runFn func(*cobra.Command, []string)) *cobra.Command {
-	return &cobra.Command{Use: name, Run: runFn, PreRun: setupHTTPLogger}
+	return &cobra.Command{Use: name, Run: logPanics(runFn), PreRun: preRunCommand}
+}
+
+// logPanics wraps the given command run function so that any panic it raises
+// is logged, along with the stack trace of the panicking goroutine.
+func logPanics(runFn func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	if runFn == nil {
+		return nil
+	}
+
+	return func(cmd *cobra.Command, args []string) {
+		defer func() {
+			if r := recover(); r != nil {
+				err := errors.Errorf("panic: %v\n\n%s", r, runtimedebug.Stack())
+				PanicMsg(err, "run.crashed", tr.Args{"Err": r})
+			}
+		}()
+
+		runFn(cmd, args)
+	}
+}
+
+func preRunCommand(cmd *cobra.Command, args []string) {
+	setupHTTPLogger(cmd, args)
+	autoInstall(cmd)
 }
 
 // RegisterCommand creates a direct 'git-lfs' subcommand, given a command name,
@@ -69,10 +106,11 @@ Simply type ` + root.Name() + ` help [path to command] for full details.`,
 
 		Run: func(c *cobra.Command, args []string) {
 			cmd, _, e := c.Root().Find(args)
-			// In the case of "git lfs help config", pretend the
-			// last arg was "help" so our command lookup succeeds,
-			// since cmd will be ignored in helpCommand().
-			if e != nil && args[0] == "config" {
+			// In the case of "git lfs help config" or "git lfs
+			// help exit-codes", pretend the last arg was "help" so
+			// our command lookup succeeds, since cmd will be
+			// ignored in helpCommand().
+			if e != nil && (args[0] == "config" || args[0] == "exit-codes") {
 				cmd, _, e = c.Root().Find([]string{"help"})
 			}
 			if cmd == nil || e != nil {
@@ -90,7 +128,30 @@ Simply type ` + root.Name() + ` help [path to command] for full details.`,
 	root.SetHelpFunc(helpCommand)
 	root.SetUsageFunc(usageCommand)
 
-	root.Flags().BoolVarP(&rootVersion, "version", "v", false, "")
+	root.Flags().BoolVar(&rootVersion, "version", false, "")
+	root.PersistentFlags().StringVar(&progressFormatArg, "progress-format", "", "Report progress as text or json")
+	root.PersistentFlags().BoolVarP(&quietArg, "quiet", "q", false, "Print only errors and the data asked for")
+	root.PersistentFlags().CountVarP(&verbosityArg, "verbose", "v", "Print more detail, and debugging messages if given twice")
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		// Reject an invalid format or verbosity before the command
+		// does any work.
+		progressEvents()
+		setVerbosity()
+
+		// The version can always be checked, even by a client
+		// which is too old to be used. Completions must print
+		// nothing but the candidates. Clone checks once it is
+		// inside the new repository, since reading the config here
+		// would load it from the directory it was started in.
+		switch {
+		case cmd == cmd.Root():
+		case cmd.Name() == "version", cmd.Name() == "help":
+		case cmd.Name() == "completion", cmd.Name() == "__complete":
+		case cmd.Name() == "clone":
+		default:
+			checkMinClientVersion()
+		}
+	}
 
 	cfg = config.New()
 
@@ -101,17 +162,27 @@ Simply type ` + root.Name() + ` help [path to command] for full details.`,
 	}
 
 	err := root.Execute()
+	writeStorageMetrics()
+	writePerf()
 	closeAPIClient()
 
 	if err != nil {
-		return 127
+		return exitCodeUsage
 	}
 	return 0
 }
 
 func gitlfsCommand(cmd *cobra.Command, args []string) {
+	// `git lfs -v` has always printed the version, before -v meant
+	// --verbose, and so prints just the version, as it did then.
+	legacyVersion := !rootVersion && verbosityArg > 0
+	if legacyVersion {
+		verbosityArg = 0
+	}
+
 	versionCommand(cmd, args)
-	if !rootVersion {
+
+	if !rootVersion && !legacyVersion {
 		cmd.Usage()
 	}
 }
@@ -133,10 +204,49 @@ func printHelp(commandName string) {
 	if commandName == "--help" {
 		commandName = "git-lfs"
 	}
+	if commandName == "exit-codes" {
+		printExitCodes()
+		return
+	}
 	if txt, ok := ManPages[commandName]; ok {
 		fmt.Fprintf(os.Stdout, "%s\n", strings.TrimSpace(txt))
 	} else {
-		fmt.Fprintf(os.Stdout, "Sorry, no usage text found for %q\n", commandName)
+		fmt.Fprintln(os.Stdout, tr.Tr("run.no-usage", tr.Args{"Command": commandName}))
+	}
+}
+
+// writeStorageMetrics writes the metrics of the local object store to the file
+// given by lfs.metricsfile, if one is configured.
+func writeStorageMetrics() {
+	if !cfg.InRepo() {
+		return
+	}
+
+	path := cfg.MetricsFile()
+	if len(path) == 0 {
+		return
+	}
+
+	f := cfg.Filesystem()
+	metrics, err := lfs.NewStorageMetrics(f)
+	if err == nil {
+		err = metrics.WriteFile(f, path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr.Tr("run.metrics-failed", tr.Args{"Path": path, "Err": err}))
+	}
+}
+
+// writePerf writes the timings of the phases of the command, if GIT_LFS_PERF is
+// set.
+func writePerf() {
+	command := filepath.Base(os.Args[0])
+	if len(os.Args) > 1 {
+		command += " " + traceRedact(strings.Join(os.Args[1:], " "))
+	}
+
+	if err := perf.Flush(command); err != nil {
+		fmt.Fprintln(os.Stderr, tr.Tr("run.timings-failed", tr.Args{"Err": err}))
 	}
 }
 
@@ -147,14 +257,14 @@ func setupHTTPLogger(cmd *cobra.Command, args []string) {
 
 	logBase := filepath.Join(cfg.LocalLogDir(), "http")
 	if err := tools.MkdirAll(logBase, cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "Error logging http stats: %s\n", err)
+		fmt.Fprintln(os.Stderr, tr.Tr("run.http-stats-failed", tr.Args{"Err": err}))
 		return
 	}
 
 	logFile := fmt.Sprintf("http-%d.log", time.Now().Unix())
 	file, err := os.Create(filepath.Join(logBase, logFile))
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error logging http stats: %s\n", err)
+		fmt.Fprintln(os.Stderr, tr.Tr("run.http-stats-failed", tr.Args{"Err": err}))
 	} else {
 		getAPIClient().LogHTTPStats(file)
 	}
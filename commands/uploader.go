@@ -16,6 +16,7 @@ import (
 	"github.com/git-lfs/git-lfs/tasklog"
 	"github.com/git-lfs/git-lfs/tools"
 	"github.com/git-lfs/git-lfs/tq"
+	"github.com/git-lfs/git-lfs/tr"
 	"github.com/rubyist/tracerx"
 )
 
@@ -123,9 +124,7 @@ func newUploadContext(dryRun bool) *uploadContext {
 		sink = ioutil.Discard
 	}
 
-	ctx.logger = tasklog.NewLogger(sink,
-		tasklog.ForceProgress(cfg.ForceProgress()),
-	)
+	ctx.logger = newLogger(sink)
 	ctx.meter = buildProgressMeter(ctx.DryRun, tq.Upload)
 	ctx.logger.Enqueue(ctx.meter)
 	ctx.committerName, ctx.committerEmail = cfg.CurrentCommitter()
@@ -287,50 +286,50 @@ func (c *uploadContext) ReportErrors() {
 	}
 
 	if len(c.missing) > 0 || len(c.corrupt) > 0 {
-		var action string
 		if c.allowMissing {
-			action = "missing objects"
+			PrintMsg("push.upload-missing-objects")
 		} else {
-			action = "failed"
+			PrintMsg("push.upload-failed")
 		}
-
-		Print("LFS upload %s:", action)
 		for name, oid := range c.missing {
-			Print("  (missing) %s (%s)", name, oid)
+			PrintMsg("push.validate-missing-object", tr.Args{"Name": name, "Oid": oid})
 		}
 		for name, oid := range c.corrupt {
-			Print("  (corrupt) %s (%s)", name, oid)
+			PrintMsg("push.validate-corrupt-object", tr.Args{"Name": name, "Oid": oid})
 		}
 
 		if !c.allowMissing {
-			pushMissingHint := []string{
-				"hint: Your push was rejected due to missing or corrupt local objects.",
-				"hint: You can disable this check with: 'git config lfs.allowincompletepush true'",
+			PrintMsg("push.missing-hint")
+			if len(c.missing) > 0 {
+				recordExitCode(exitCodeNotFound)
+			} else {
+				recordExitCode(exitCodeCorrupt)
 			}
-			Print(strings.Join(pushMissingHint, "\n"))
-			os.Exit(2)
+			writePerf()
+			os.Exit(exitCode())
 		}
 	}
 
 	if len(c.otherErrs) > 0 {
-		os.Exit(2)
+		writePerf()
+		os.Exit(exitCode())
 	}
 
 	if c.lockVerifier.HasUnownedLocks() {
-		Print("Unable to push locked files:")
+		PrintMsg("push.locked-files")
 		for _, unowned := range c.lockVerifier.UnownedLocks() {
 			Print("* %s - %s", unowned.Path(), unowned.Owners())
 		}
 
 		if c.lockVerifier.Enabled() {
-			Exit("ERROR: Cannot update locked files.")
+			ExitMsg("push.locked-files-error")
 		} else {
-			Error("WARNING: The above files would have halted this push.")
+			ErrorMsg("push.locked-files-warning")
 		}
 	} else if c.lockVerifier.HasOwnedLocks() {
-		Print("Consider unlocking your own locked files: (`git lfs unlock <path>`)")
+		InfoMsg("push.unlock-suggestion")
 		for _, owned := range c.lockVerifier.OwnedLocks() {
-			Print("* %s", owned.Path())
+			Info("* %s", owned.Path())
 		}
 	}
 }
@@ -378,7 +377,7 @@ func (c *uploadContext) uploadTransfer(p *lfs.WrappedPointer) (*tq.Transfer, err
 // ensureFile makes sure that the cleanPath exists before pushing it.  If it
 // does not exist, it attempts to clean it by reading the file at smudgePath.
 func (c *uploadContext) ensureFile(smudgePath, cleanPath, oid string) (bool, error) {
-	if _, err := os.Stat(cleanPath); err == nil {
+	if meta, err := lfs.StatObject(cfg, oid); err == nil && meta.Exists {
 		return false, nil
 	}
 
@@ -53,6 +53,7 @@ type Configuration struct {
 	remotes    []string
 	extensions map[string]Extension
 	mask       int
+	shared     bool
 	maskOnce   sync.Once
 	timestamp  time.Time
 }
@@ -99,8 +100,10 @@ func (c *Configuration) getMask() int {
 		switch strings.ToLower(val) {
 		case "group", "true", "1":
 			c.mask = 007
+			c.shared = true
 		case "all", "world", "everybody", "2":
 			c.mask = 002
+			c.shared = true
 		case "umask", "false", "0":
 			c.mask = umask()
 		default:
@@ -112,6 +115,7 @@ func (c *Configuration) getMask() int {
 				c.mask = umask()
 			} else {
 				c.mask = 0666 & ^int(mode)
+				c.shared = true
 			}
 		}
 	})
@@ -323,6 +327,44 @@ func (c *Configuration) ForceProgress() bool {
 	return c.Os.Bool("GIT_LFS_FORCE_PROGRESS", false) || c.Git.Bool("lfs.forceprogress", false)
 }
 
+// PlainProgress returns whether progress should be reported as periodic plain
+// lines, rather than a single updating line, even on a terminal, as given by
+// lfs.progress being false.
+func (c *Configuration) PlainProgress() bool {
+	return !c.Git.Bool("lfs.progress", true)
+}
+
+// MetricsFile returns the path to which storage metrics are written after each
+// operation, as given by lfs.metricsfile, or the empty string if no such file
+// is configured.
+func (c *Configuration) MetricsFile() string {
+	path, ok := c.Git.Get("lfs.metricsfile")
+	if !ok || len(path) == 0 {
+		return ""
+	}
+
+	expanded, err := tools.ExpandPath(path, false)
+	if err != nil {
+		return path
+	}
+	return expanded
+}
+
+// MinClientVersion returns the oldest version of Git LFS which the repository
+// should be used with, as given by lfs.minclientversion, or the empty string if
+// there is none.
+func (c *Configuration) MinClientVersion() string {
+	v, _ := c.Git.Get("lfs.minclientversion")
+	return strings.TrimSpace(v)
+}
+
+// EnforceMinClientVersion returns whether commands should fail, rather than
+// warn, when run by a version of Git LFS older than MinClientVersion, as given
+// by lfs.enforceminclientversion.
+func (c *Configuration) EnforceMinClientVersion() bool {
+	return c.Git.Bool("lfs.enforceminclientversion", false)
+}
+
 // HookDir returns the location of the hooks owned by this repository. If the
 // core.hooksPath configuration variable is supported, we prefer that and expand
 // paths appropriately.
@@ -337,7 +379,14 @@ func (c *Configuration) HookDir() (string, error) {
 			if filepath.IsAbs(path) {
 				return path, nil
 			}
-			return filepath.Join(c.LocalWorkingDir(), path), nil
+			// Like Git, resolve a relative path against the top of
+			// the working tree, or the Git directory of a bare
+			// repository.
+			base := c.LocalWorkingDir()
+			if len(base) == 0 {
+				base = c.LocalGitDir()
+			}
+			return filepath.Join(base, path), nil
 		}
 	}
 	return filepath.Join(c.LocalGitStorageDir(), "hooks"), nil
@@ -427,6 +476,22 @@ func (c *Configuration) Filesystem() *fs.Filesystem {
 			lfsdir,
 			c.RepositoryPermissions(false),
 		)
+		c.fs.SharedRepository = c.RepositoryShared()
+		for _, dir := range c.Git.GetAll("lfs.extraobjectstores") {
+			if len(dir) == 0 {
+				continue
+			}
+			if expanded, err := tools.ExpandPath(dir, false); err == nil {
+				dir = expanded
+			}
+			c.fs.ExtraObjectStores = append(c.fs.ExtraObjectStores, dir)
+		}
+		if tmpdir, ok := c.Git.Get("lfs.tmpdir"); ok && len(tmpdir) > 0 {
+			if expanded, err := tools.ExpandPath(tmpdir, false); err == nil {
+				tmpdir = expanded
+			}
+			c.fs.SetTempDir(tmpdir)
+		}
 	}
 
 	return c.fs
@@ -649,3 +714,12 @@ func (c *Configuration) RepositoryPermissions(executable bool) os.FileMode {
 	}
 	return perms
 }
+
+// RepositoryShared returns whether core.sharedRepository shares the repository
+// with other users, rather than leaving permissions to the umask, in which
+// case Git gives the directories it creates the setgid bit, so that files in
+// them belong to the repository's group.
+func (c *Configuration) RepositoryShared() bool {
+	c.getMask()
+	return c.shared
+}
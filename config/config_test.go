@@ -226,6 +226,32 @@ func TestRepositoryPermissionsExectable(t *testing.T) {
 	}
 }
 
+func TestRepositoryShared(t *testing.T) {
+	values := map[string]bool{
+		"group":     true,
+		"YES":       true,
+		"everybody": true,
+		"2":         true,
+		"0640":      true,
+		"false":     false,
+		"umask":     false,
+		"0":         false,
+		"NO":        false,
+		"this does not remotely look like a valid value": false,
+	}
+
+	for key, val := range values {
+		cfg := NewFrom(Values{
+			Git: map[string][]string{
+				"core.sharedrepository": []string{key},
+			},
+		})
+		assert.Equal(t, val, cfg.RepositoryShared(), "core.sharedRepository=%s", key)
+	}
+
+	assert.False(t, NewFrom(Values{}).RepositoryShared())
+}
+
 func TestCurrentUser(t *testing.T) {
 	cfg := NewFrom(Values{
 		Git: map[string][]string{
@@ -2,6 +2,8 @@ package config
 
 import (
 	"sync"
+
+	"github.com/git-lfs/git-lfs/tools/perf"
 )
 
 // delayedEnvironment is an implementation of the Environment which wraps the legacy
@@ -64,5 +66,7 @@ func (e *delayedEnvironment) Load() {
 		return
 	}
 
+	timer := perf.Start("config load")
 	e.env = e.callback()
+	timer.Stop()
 }
@@ -184,10 +184,12 @@ func keyIsUnsafe(key string) bool {
 
 var safeKeys = []string{
 	"lfs.allowincompletepush",
+	"lfs.enforceminclientversion",
 	"lfs.fetchexclude",
 	"lfs.fetchinclude",
 	"lfs.gitprotocol",
 	"lfs.locksverify",
+	"lfs.minclientversion",
 	"lfs.pushurl",
 	"lfs.url",
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -33,3 +34,103 @@ func init() {
 		gitCommit,
 	)
 }
+
+// CompareVersions returns -1, 0, or 1 if the version "a" is older than, the
+// same as, or newer than the version "b", following the rules of Semantic
+// Versioning. A leading "v", and any build metadata after a "+", are ignored.
+// A version with a pre-release suffix, such as "2.12.0-rc.1", is older than the
+// same version without one. Missing minor and patch numbers are taken to be
+// zero.
+func CompareVersions(a, b string) (int, error) {
+	av, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bv, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range av.core {
+		if c := compareInts(av.core[i], bv.core[i]); c != 0 {
+			return c, nil
+		}
+	}
+
+	switch {
+	case len(av.pre) == 0 && len(bv.pre) == 0:
+		return 0, nil
+	case len(av.pre) == 0:
+		return 1, nil
+	case len(bv.pre) == 0:
+		return -1, nil
+	}
+
+	for i := 0; i < len(av.pre) && i < len(bv.pre); i++ {
+		if c := comparePrerelease(av.pre[i], bv.pre[i]); c != 0 {
+			return c, nil
+		}
+	}
+	return compareInts(uint64(len(av.pre)), uint64(len(bv.pre))), nil
+}
+
+type version struct {
+	core [3]uint64
+	pre  []string
+}
+
+func parseVersion(s string) (*version, error) {
+	v := &version{}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		if i == len(rest)-1 {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		v.pre = strings.Split(rest[i+1:], ".")
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > len(v.core) {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", s)
+		}
+		v.core[i] = n
+	}
+	return v, nil
+}
+
+// comparePrerelease compares two identifiers of a pre-release suffix. Numeric
+// identifiers are compared as numbers, and are older than any other.
+func comparePrerelease(a, b string) int {
+	an, aerr := strconv.ParseUint(a, 10, 64)
+	bn, berr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aerr == nil && berr == nil:
+		return compareInts(an, bn)
+	case aerr == nil:
+		return -1
+	case berr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, c := range []struct {
+		A, B     string
+		Expected int
+	}{
+		{"2.11.0", "2.11.0", 0},
+		{"v2.11.0", "2.11.0", 0},
+		{"2.11", "2.11.0", 0},
+		{"2", "2.0.0", 0},
+		{"2.11.0+abc123", "2.11.0", 0},
+		{"2.11.0", "2.12.0", -1},
+		{"2.12.0", "2.11.9", 1},
+		{"2.10.0", "2.9.0", 1},
+		{"3.0.0", "2.99.99", 1},
+		{"2.12.0-pre", "2.12.0", -1},
+		{"2.12.0", "2.12.0-rc.1", 1},
+		{"2.12.0-rc.1", "2.11.0", 1},
+		{"2.12.0-alpha", "2.12.0-alpha.1", -1},
+		{"2.12.0-alpha.1", "2.12.0-alpha.beta", -1},
+		{"2.12.0-alpha.beta", "2.12.0-beta", -1},
+		{"2.12.0-beta.2", "2.12.0-beta.11", -1},
+		{"2.12.0-rc.1", "2.12.0-rc.1", 0},
+	} {
+		actual, err := CompareVersions(c.A, c.B)
+		assert.Nil(t, err, "%s <=> %s", c.A, c.B)
+		assert.Equal(t, c.Expected, actual, "%s <=> %s", c.A, c.B)
+	}
+}
+
+func TestCompareVersionsInvalid(t *testing.T) {
+	for _, v := range []string{"", "two", "2.x.0", "2.11.0.1", "2.11.0-", "-rc1"} {
+		_, err := CompareVersions(v, "2.11.0")
+		assert.NotNil(t, err, "%q", v)
+	}
+}
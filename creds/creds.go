@@ -6,11 +6,15 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/subprocess"
 	"github.com/rubyist/tracerx"
 )
 
@@ -68,6 +72,7 @@ type CredentialHelperContext struct {
 	commandCredHelper *commandCredentialHelper
 	askpassCredHelper *AskPassCredentialHelper
 	cachingCredHelper *credentialCacher
+	chainCredHelpers  []CredentialHelper
 
 	urlConfig *config.URLConfig
 }
@@ -95,8 +100,15 @@ func NewCredentialHelperContext(gitEnv config.Environment, osEnv config.Environm
 		c.cachingCredHelper = NewCredentialCacher()
 	}
 
+	if names, ok := gitEnv.Get("lfs.credentialhelpers"); ok {
+		for _, name := range strings.Fields(names) {
+			c.chainCredHelpers = append(c.chainCredHelpers, &namedCredentialHelper{Name: name})
+		}
+	}
+
 	c.commandCredHelper = &commandCredentialHelper{
 		SkipPrompt: osEnv.Bool("GIT_TERMINAL_PROMPT", false),
+		Expiry:     time.Duration(gitEnv.Int("lfs.credentialexpiry", defaultCredentialExpiry)) * time.Second,
 	}
 
 	return c
@@ -121,13 +133,14 @@ func (ctxt *CredentialHelperContext) GetCredentialHelper(helper CredentialHelper
 		return CredentialHelperWrapper{CredentialHelper: helper, Input: input, Url: u}
 	}
 
-	helpers := make([]CredentialHelper, 0, 4)
+	helpers := make([]CredentialHelper, 0, 4+len(ctxt.chainCredHelpers))
 	if ctxt.netrcCredHelper != nil {
 		helpers = append(helpers, ctxt.netrcCredHelper)
 	}
 	if ctxt.cachingCredHelper != nil {
 		helpers = append(helpers, ctxt.cachingCredHelper)
 	}
+	helpers = append(helpers, ctxt.chainCredHelpers...)
 	if ctxt.askpassCredHelper != nil {
 		helper, _ := ctxt.urlConfig.Get("credential", rawurl, "helper")
 		if len(helper) == 0 {
@@ -268,8 +281,18 @@ func (a *AskPassCredentialHelper) args(prompt string) []string {
 	return []string{prompt}
 }
 
+const (
+	// defaultCredentialExpiry is the number of seconds for which
+	// credentials approved with 'git credential approve' are cached,
+	// unless lfs.credentialexpiry says otherwise.
+	defaultCredentialExpiry = 900
+)
+
 type commandCredentialHelper struct {
 	SkipPrompt bool
+	// Expiry is the duration for which approved credentials should be
+	// cached by the credential helper. If zero, no expiry is given.
+	Expiry time.Duration
 }
 
 func (h *commandCredentialHelper) Fill(creds Creds) (Creds, error) {
@@ -279,6 +302,8 @@ func (h *commandCredentialHelper) Fill(creds Creds) (Creds, error) {
 }
 
 func (h *commandCredentialHelper) Reject(creds Creds) error {
+	tracerx.Printf("creds: git credential reject (%q, %q, %q)",
+		creds["protocol"], creds["host"], creds["path"])
 	_, err := h.exec("reject", creds)
 	return err
 }
@@ -286,10 +311,31 @@ func (h *commandCredentialHelper) Reject(creds Creds) error {
 func (h *commandCredentialHelper) Approve(creds Creds) error {
 	tracerx.Printf("creds: git credential approve (%q, %q, %q)",
 		creds["protocol"], creds["host"], creds["path"])
-	_, err := h.exec("approve", creds)
+	_, err := h.exec("approve", h.withExpiry(creds, time.Now()))
 	return err
 }
 
+// withExpiry returns a copy of "creds" which tells the credential helper to
+// cache them for h.Expiry after "now", unless the credentials already carry
+// an expiry of their own.
+func (h *commandCredentialHelper) withExpiry(creds Creds, now time.Time) Creds {
+	if h.Expiry <= 0 {
+		return creds
+	}
+	if _, ok := creds["password_expiry_utc"]; ok {
+		return creds
+	}
+
+	expiring := make(Creds, len(creds)+1)
+	for k, v := range creds {
+		expiring[k] = v
+	}
+	expiring["password_expiry_utc"] = strconv.FormatInt(now.Add(h.Expiry).Unix(), 10)
+
+	tracerx.Printf("creds: caching credentials until %s", now.Add(h.Expiry).UTC().Format(time.RFC3339))
+	return expiring
+}
+
 func (h *commandCredentialHelper) exec(subcommand string, input Creds) (Creds, error) {
 	output := new(bytes.Buffer)
 	cmd := exec.Command("git", "credential", subcommand)
@@ -341,6 +387,90 @@ func (h *commandCredentialHelper) exec(subcommand string, input Creds) (Creds, e
 	return creds, nil
 }
 
+// namedCredentialHelper runs one of the credential helpers listed in
+// lfs.credentialhelpers directly, speaking Git's credential helper protocol,
+// rather than through 'git credential'. The name is interpreted as Git
+// interprets credential.helper: "!cmd" is run by the shell, an absolute path
+// is run as it is, and any other name "foo" runs 'git credential-foo'.
+//
+// A helper which fills no password returns credHelperNoOp, so that the next
+// one is tried. Approvals and rejections are passed on to every helper in
+// turn, as Git passes them to every credential.helper, so they also return
+// credHelperNoOp.
+type namedCredentialHelper struct {
+	Name string
+}
+
+func (h *namedCredentialHelper) Fill(what Creds) (Creds, error) {
+	tracerx.Printf("creds: credential helper %q get (%q, %q, %q)",
+		h.Name, what["protocol"], what["host"], what["path"])
+	output, err := h.exec("get", what)
+	if err != nil {
+		return nil, err
+	}
+	if len(output["password"]) == 0 {
+		return nil, credHelperNoOp
+	}
+
+	creds := make(Creds, len(what)+len(output))
+	for k, v := range what {
+		creds[k] = v
+	}
+	for k, v := range output {
+		creds[k] = v
+	}
+	return creds, nil
+}
+
+func (h *namedCredentialHelper) Approve(creds Creds) error {
+	if _, err := h.exec("store", creds); err != nil {
+		tracerx.Printf("creds: credential helper %q store error: %s", h.Name, err)
+	}
+	return credHelperNoOp
+}
+
+func (h *namedCredentialHelper) Reject(creds Creds) error {
+	if _, err := h.exec("erase", creds); err != nil {
+		tracerx.Printf("creds: credential helper %q erase error: %s", h.Name, err)
+	}
+	return credHelperNoOp
+}
+
+func (h *namedCredentialHelper) command() string {
+	switch {
+	case strings.HasPrefix(h.Name, "!"):
+		return h.Name[1:]
+	case filepath.IsAbs(h.Name), strings.HasPrefix(h.Name, "/"):
+		return h.Name
+	default:
+		return "git credential-" + h.Name
+	}
+}
+
+func (h *namedCredentialHelper) exec(action string, input Creds) (Creds, error) {
+	output := new(bytes.Buffer)
+	name, args := subprocess.FormatForShell(h.command(), action)
+	cmd := subprocess.ExecCommand(name, args...)
+	cmd.Stdin = bufferCreds(input)
+	cmd.Stdout = output
+	// As with 'git credential', stderr is passed through rather than read.
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %q %s error: %s", h.Name, action, err)
+	}
+
+	creds := make(Creds)
+	for _, line := range strings.Split(output.String(), "\n") {
+		pieces := strings.SplitN(strings.TrimSuffix(line, "\r"), "=", 2)
+		if len(pieces) < 2 || len(pieces[1]) < 1 {
+			continue
+		}
+		creds[pieces[0]] = pieces[1]
+	}
+	return creds, nil
+}
+
 type credentialCacher struct {
 	creds map[string]Creds
 	mu    sync.Mutex
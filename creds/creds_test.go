@@ -2,9 +2,18 @@ package creds
 
 import (
 	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/git-lfs/git-lfs/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testCredHelper struct {
@@ -264,3 +273,135 @@ func TestCredHelperSetAllFillErrors(t *testing.T) {
 	assert.Equal(t, 0, len(helper1.reject))
 	assert.Equal(t, 0, len(helper2.reject))
 }
+
+func TestCommandCredHelperAddsExpiry(t *testing.T) {
+	h := &commandCredentialHelper{Expiry: 15 * time.Minute}
+	in := Creds{"protocol": "https", "host": "example.com", "password": "secret"}
+
+	out := h.withExpiry(in, time.Unix(1000, 0))
+
+	assert.Equal(t, "1900", out["password_expiry_utc"])
+	assert.Equal(t, "secret", out["password"])
+	_, ok := in["password_expiry_utc"]
+	assert.False(t, ok)
+}
+
+func TestCommandCredHelperKeepsExistingExpiry(t *testing.T) {
+	h := &commandCredentialHelper{Expiry: 15 * time.Minute}
+	in := Creds{"password": "secret", "password_expiry_utc": "42"}
+
+	out := h.withExpiry(in, time.Unix(1000, 0))
+
+	assert.Equal(t, "42", out["password_expiry_utc"])
+}
+
+func TestCommandCredHelperWithoutExpiry(t *testing.T) {
+	h := &commandCredentialHelper{}
+	in := Creds{"password": "secret"}
+
+	out := h.withExpiry(in, time.Unix(1000, 0))
+
+	_, ok := out["password_expiry_utc"]
+	assert.False(t, ok)
+}
+
+// writeCredHelper writes a credential helper script named "name" into "dir",
+// which logs each action it is given to "<name>.log" and answers "get" with
+// "output".
+func writeCredHelper(t *testing.T, dir, name, output string) string {
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\ncat >/dev/null\necho \"$1\" >> '" + filepath.ToSlash(path) + ".log'\n" +
+		"if [ \"$1\" = get ]; then printf '" + output + "'; fi\n"
+	require.Nil(t, ioutil.WriteFile(path, []byte(script), 0755))
+	return filepath.ToSlash(path)
+}
+
+func credHelperLog(t *testing.T, path string) []string {
+	data, err := ioutil.ReadFile(path + ".log")
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.Nil(t, err)
+	return strings.Fields(string(data))
+}
+
+func newCredHelperChainTest(t *testing.T) (empty, valid string, cleanup func()) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	dir, err := ioutil.TempDir("", "credhelpers")
+	require.Nil(t, err)
+
+	empty = writeCredHelper(t, dir, "empty", "")
+	valid = writeCredHelper(t, dir, "valid", "username=user\\npassword=pass\\n")
+	return empty, valid, func() { os.RemoveAll(dir) }
+}
+
+func TestCredHelperChainFillsFromFirstNonEmptyHelper(t *testing.T) {
+	empty, valid, cleanup := newCredHelperChainTest(t)
+	defer cleanup()
+
+	ctxt := NewCredentialHelperContext(config.EnvironmentOf(config.MapFetcher(map[string][]string{
+		"lfs.credentialhelpers": []string{empty + " " + valid},
+		"lfs.cachecredentials":  []string{"false"},
+	})), config.EnvironmentOf(config.MapFetcher(nil)))
+
+	u, err := url.Parse("https://example.com/repo.git")
+	require.Nil(t, err)
+	wrapper := ctxt.GetCredentialHelper(nil, u)
+	require.Nil(t, wrapper.FillCreds())
+
+	assert.Equal(t, Creds{
+		"protocol": "https",
+		"host":     "example.com",
+		"username": "user",
+		"password": "pass",
+	}, wrapper.Creds)
+	assert.Equal(t, []string{"get"}, credHelperLog(t, empty))
+	assert.Equal(t, []string{"get"}, credHelperLog(t, valid))
+}
+
+func TestCredHelperChainPassesApprovalsAndRejectionsOn(t *testing.T) {
+	empty, valid, cleanup := newCredHelperChainTest(t)
+	defer cleanup()
+
+	last := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{
+		&namedCredentialHelper{Name: empty},
+		&namedCredentialHelper{Name: valid},
+		last,
+	})
+	creds := Creds{"protocol": "https", "host": "example.com", "username": "user", "password": "pass"}
+
+	assert.Nil(t, helpers.Approve(creds))
+	assert.Nil(t, helpers.Reject(creds))
+
+	assert.Equal(t, []string{"store", "erase"}, credHelperLog(t, empty))
+	assert.Equal(t, []string{"store", "erase"}, credHelperLog(t, valid))
+	assert.Equal(t, []Creds{creds}, last.approve)
+	assert.Equal(t, []Creds{creds}, last.reject)
+}
+
+func TestCredHelperChainFallsThroughWhenAllEmpty(t *testing.T) {
+	empty, _, cleanup := newCredHelperChainTest(t)
+	defer cleanup()
+
+	last := newTestCredHelper()
+	helpers := NewCredentialHelpers([]CredentialHelper{&namedCredentialHelper{Name: empty}, last})
+	creds := Creds{"protocol": "https", "host": "example.com"}
+
+	out, err := helpers.Fill(creds)
+	assert.Nil(t, err)
+	assert.Equal(t, creds, out)
+	assert.Equal(t, 1, len(last.fill))
+}
+
+func TestNamedCredHelperCommand(t *testing.T) {
+	for name, command := range map[string]string{
+		"osxkeychain":           "git credential-osxkeychain",
+		"!f() { echo x; }; f":   "f() { echo x; }; f",
+		"/usr/local/bin/helper": "/usr/local/bin/helper",
+	} {
+		assert.Equal(t, command, (&namedCredentialHelper{Name: name}).command())
+	}
+}
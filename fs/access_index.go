@@ -0,0 +1,263 @@
+package fs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// accessIndexName is the name of the file in the LFS storage directory
+	// which records when each object was last read, one "<oid> <unix
+	// time>" line per object.
+	accessIndexName = "access-index"
+
+	// accessIndexResolution is how much later an object must be read
+	// than its recorded access for the index to be rewritten, so that
+	// reading the same objects over and over does not rewrite it each
+	// time.
+	accessIndexResolution = time.Hour
+
+	// maxAccessIndexEntries bounds the size of the access index. Beyond
+	// it, the least recently read objects are dropped, and fall back to
+	// their modification time.
+	maxAccessIndexEntries = 500000
+
+	// stateLockTimeout is how long to wait for another process to release
+	// a state file in the LFS storage directory, such as the access index,
+	// and stateStaleLock is how old a lock must be for it to be taken to
+	// belong to a process which has died.
+	stateLockTimeout = 2 * time.Second
+	stateStaleLock   = 30 * time.Second
+)
+
+// RecordAccess records that the object "oid" was read, such as to check it
+// out. Accesses are kept in memory, and written to the access index by
+// FlushAccesses.
+func (f *Filesystem) RecordAccess(oid string) {
+	f.accessMu.Lock()
+	defer f.accessMu.Unlock()
+
+	if f.accesses == nil {
+		f.accesses = make(map[string]time.Time)
+	}
+	f.accesses[oid] = time.Now()
+}
+
+// FlushAccesses merges the accesses recorded by RecordAccess into the access
+// index. The index is only rewritten if an object was read at least
+// accessIndexResolution after its recorded access.
+func (f *Filesystem) FlushAccesses() error {
+	f.accessMu.Lock()
+	accesses := f.accesses
+	f.accesses = nil
+	f.accessMu.Unlock()
+
+	if len(accesses) == 0 {
+		return nil
+	}
+
+	return f.updateAccessIndex(func(index map[string]int64) bool {
+		changed := false
+		for oid, t := range accesses {
+			if t.Unix()-index[oid] >= int64(accessIndexResolution/time.Second) {
+				index[oid] = t.Unix()
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// ForgetAccesses removes the given objects from the access index, such as once
+// they have been deleted.
+func (f *Filesystem) ForgetAccesses(oids []string) error {
+	if len(oids) == 0 {
+		return nil
+	}
+
+	return f.updateAccessIndex(func(index map[string]int64) bool {
+		changed := false
+		for _, oid := range oids {
+			if _, ok := index[oid]; ok {
+				delete(index, oid)
+				changed = true
+			}
+		}
+		return changed
+	})
+}
+
+// AccessTimes returns when each object in the access index was last read.
+// Objects which have not been read since the index was introduced are absent;
+// see LastAccessed.
+func (f *Filesystem) AccessTimes() (map[string]time.Time, error) {
+	index, err := f.readAccessIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[string]time.Time, len(index))
+	for oid, t := range index {
+		times[oid] = time.Unix(t, 0)
+	}
+	return times, nil
+}
+
+// LastAccessed returns when the object "oid" was last read according to
+// "times", as returned by AccessTimes, or when it was last modified, which is
+// usually when it was downloaded or added, if it has not been read since.
+func (f *Filesystem) LastAccessed(times map[string]time.Time, oid string) (time.Time, error) {
+	if t, ok := times[oid]; ok {
+		return t, nil
+	}
+
+	info, err := os.Stat(f.ObjectPathname(oid))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+func (f *Filesystem) accessIndexPath() string {
+	return filepath.Join(f.LFSStorageDir, accessIndexName)
+}
+
+// updateAccessIndex reads the access index while holding its lock, and writes
+// it back if "fn" reports that it changed it. The index is replaced
+// atomically, so that readers never see a partially written index.
+func (f *Filesystem) updateAccessIndex(fn func(index map[string]int64) bool) error {
+	unlock, err := f.lockAccessIndex()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	index, err := f.readAccessIndex()
+	if err != nil {
+		return err
+	}
+	if !fn(index) {
+		return nil
+	}
+	return f.writeAccessIndex(index)
+}
+
+// lockAccessIndex takes the lock on the access index. It returns a function
+// which releases the lock.
+func (f *Filesystem) lockAccessIndex() (func(), error) {
+	return lockStateFile(f.accessIndexPath())
+}
+
+// lockStateFile takes the lock on the state file at "path", waiting up to
+// stateLockTimeout for another process to release it. It returns a function
+// which releases the lock.
+func lockStateFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(stateLockTimeout)
+
+	for {
+		lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
+		if err == nil {
+			lock.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > stateStaleLock {
+			tracerx.Printf("fs: removing stale lock %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the lock %s", lockPath)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readAccessIndex returns the access index as a map from OIDs to Unix times.
+// A missing index is empty, and malformed lines are skipped.
+func (f *Filesystem) readAccessIndex() (map[string]int64, error) {
+	index := make(map[string]int64)
+
+	file, err := os.Open(f.accessIndexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !oidRE.MatchString(fields[0]) {
+			continue
+		}
+		t, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if t > index[fields[0]] {
+			index[fields[0]] = t
+		}
+	}
+	return index, scanner.Err()
+}
+
+// writeAccessIndex replaces the access index with "index", dropping the least
+// recently read objects if it has more than maxAccessIndexEntries. It must
+// only be called while holding the lock.
+func (f *Filesystem) writeAccessIndex(index map[string]int64) error {
+	oids := make([]string, 0, len(index))
+	for oid := range index {
+		oids = append(oids, oid)
+	}
+	sort.Slice(oids, func(i, j int) bool {
+		if index[oids[i]] != index[oids[j]] {
+			return index[oids[i]] > index[oids[j]]
+		}
+		return oids[i] < oids[j]
+	})
+	if len(oids) > maxAccessIndexEntries {
+		oids = oids[:maxAccessIndexEntries]
+	}
+
+	// Nothing else writes this file while the lock is held, so it need
+	// not have a unique name.
+	tmp := f.accessIndexPath() + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, f.RepositoryPermissions(false))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	for _, oid := range oids {
+		fmt.Fprintf(w, "%s %d\n", oid, index[oid])
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, f.RepositoryPermissions(false)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, f.accessIndexPath())
+}
@@ -0,0 +1,137 @@
+package fs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	accessTestOid1 = "1111111111111111111111111111111111111111111111111111111111111111"
+	accessTestOid2 = "2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func newAccessTestFilesystem(t *testing.T) (*Filesystem, func()) {
+	dir, err := ioutil.TempDir("", "fs-access-index")
+	assert.NoError(t, err)
+
+	fs := New(testEnv{}, filepath.Join(dir, ".git"), dir, "", 0755)
+	fs.LFSObjectDir()
+	return fs, func() { os.RemoveAll(dir) }
+}
+
+func TestFlushAccessesRecordsObjects(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	before := time.Now().Add(-time.Second)
+	fs.RecordAccess(accessTestOid1)
+	fs.RecordAccess(accessTestOid2)
+	assert.NoError(t, fs.FlushAccesses())
+
+	times, err := fs.AccessTimes()
+	assert.NoError(t, err)
+	assert.Len(t, times, 2)
+	assert.True(t, times[accessTestOid1].After(before))
+	assert.True(t, times[accessTestOid2].After(before))
+
+	_, err = os.Stat(fs.accessIndexPath() + ".lock")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFlushAccessesOnlyUpdatesOlderAccesses(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	recent := time.Now().Add(-10 * time.Minute).Unix()
+	old := time.Now().Add(-2 * accessIndexResolution).Unix()
+	assert.NoError(t, ioutil.WriteFile(fs.accessIndexPath(), []byte(fmt.Sprintf(
+		"%s %d\n%s %d\n", accessTestOid1, recent, accessTestOid2, old)), 0644))
+
+	fs.RecordAccess(accessTestOid1)
+	fs.RecordAccess(accessTestOid2)
+	assert.NoError(t, fs.FlushAccesses())
+
+	times, err := fs.AccessTimes()
+	assert.NoError(t, err)
+	assert.Equal(t, recent, times[accessTestOid1].Unix())
+	assert.True(t, times[accessTestOid2].Unix() > old)
+}
+
+func TestForgetAccesses(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	fs.RecordAccess(accessTestOid1)
+	fs.RecordAccess(accessTestOid2)
+	assert.NoError(t, fs.FlushAccesses())
+	assert.NoError(t, fs.ForgetAccesses([]string{accessTestOid1}))
+
+	times, err := fs.AccessTimes()
+	assert.NoError(t, err)
+	assert.Len(t, times, 1)
+	assert.Contains(t, times, accessTestOid2)
+}
+
+func TestAccessTimesSkipsMalformedLines(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	assert.NoError(t, ioutil.WriteFile(fs.accessIndexPath(), []byte(strings.Join([]string{
+		"not an oid 12",
+		accessTestOid1 + " soon",
+		accessTestOid2 + " 1000",
+		accessTestOid2 + " 500",
+		"",
+	}, "\n")), 0644))
+
+	times, err := fs.AccessTimes()
+	assert.NoError(t, err)
+	assert.Len(t, times, 1)
+	assert.Equal(t, int64(1000), times[accessTestOid2].Unix())
+}
+
+func TestLastAccessedFallsBackToModTime(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	path, err := fs.ObjectPath(accessTestOid1)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, []byte("object"), 0644))
+	modTime := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	accessed := time.Now().Truncate(time.Second)
+	times := map[string]time.Time{accessTestOid2: accessed}
+
+	last, err := fs.LastAccessed(times, accessTestOid1)
+	assert.NoError(t, err)
+	assert.True(t, modTime.Equal(last))
+
+	last, err = fs.LastAccessed(times, accessTestOid2)
+	assert.NoError(t, err)
+	assert.True(t, accessed.Equal(last))
+}
+
+func TestFlushAccessesRemovesStaleLock(t *testing.T) {
+	fs, cleanup := newAccessTestFilesystem(t)
+	defer cleanup()
+
+	lock := fs.accessIndexPath() + ".lock"
+	assert.NoError(t, ioutil.WriteFile(lock, nil, 0644))
+	stale := time.Now().Add(-2 * stateStaleLock)
+	assert.NoError(t, os.Chtimes(lock, stale, stale))
+
+	fs.RecordAccess(accessTestOid1)
+	assert.NoError(t, fs.FlushAccesses())
+
+	times, err := fs.AccessTimes()
+	assert.NoError(t, err)
+	assert.Contains(t, times, accessTestOid1)
+}
@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/git-lfs/git-lfs/tools"
 	"github.com/rubyist/tracerx"
@@ -36,11 +38,45 @@ type Filesystem struct {
 	GitStorageDir string   // parent of objects/lfs (may be same as GitDir but may not)
 	LFSStorageDir string   // parent of lfs objects and tmp dirs. Default: ".git/lfs"
 	ReferenceDirs []string // alternative local media dirs (relative to clone reference repo)
-	lfsobjdir     string
-	tmpdir        string
-	logdir        string
-	repoPerms     os.FileMode
-	mu            sync.Mutex
+	// ExtraObjectStores are read-only directories laid out like the
+	// object directory, from lfs.extraobjectstores, which are checked for
+	// objects before they are downloaded.
+	ExtraObjectStores []string
+	// SharedRepository is whether core.sharedRepository shares the
+	// repository, so that directories are created with the setgid bit.
+	SharedRepository bool
+	lfsobjdir        string
+	tmpdir           string
+	logdir           string
+	repoPerms        os.FileMode
+	mu               sync.Mutex
+
+	// cacheHits, cacheMisses, and cacheHitBytes count the number of
+	// objects found in, and absent from, the local object store during
+	// the lifetime of this process, and the total size of those found.
+	cacheHits     int64
+	cacheMisses   int64
+	cacheHitBytes int64
+
+	// accesses are the objects read by this process which have not yet
+	// been written to the access index, and when they were read.
+	accesses map[string]time.Time
+	accessMu sync.Mutex
+
+	// extraStoreHits counts the objects copied from each of the
+	// ExtraObjectStores during the lifetime of this process.
+	extraStoreHits map[string]int64
+	extraStoreMu   sync.Mutex
+
+	// objectsAdded and bytesAdded are the net number and size of the
+	// objects added to the local object store by this process.
+	objectsAdded int64
+	bytesAdded   int64
+
+	// metricsFlushed is what this process has already added to the
+	// metrics state.
+	metricsFlushed metricsCounts
+	metricsMu      sync.Mutex
 }
 
 func (f *Filesystem) EachObject(fn func(Object) error) error {
@@ -64,16 +100,62 @@ func (f *Filesystem) ObjectExists(oid string, size int64) bool {
 	return tools.FileExistsOfSize(f.ObjectPathname(oid), size)
 }
 
+// RecordCacheHit records that an object of the given size was served from the
+// local object store, rather than being downloaded.
+func (f *Filesystem) RecordCacheHit(size int64) {
+	atomic.AddInt64(&f.cacheHits, 1)
+	atomic.AddInt64(&f.cacheHitBytes, size)
+}
+
+// RecordCacheMiss records that an object was absent from the local object
+// store and had to be downloaded.
+func (f *Filesystem) RecordCacheMiss() {
+	atomic.AddInt64(&f.cacheMisses, 1)
+}
+
+// CacheCounters returns the number of cache hits and misses recorded so far,
+// and the total size of the objects served from the cache.
+func (f *Filesystem) CacheCounters() (hits, misses, hitBytes int64) {
+	return atomic.LoadInt64(&f.cacheHits),
+		atomic.LoadInt64(&f.cacheMisses),
+		atomic.LoadInt64(&f.cacheHitBytes)
+}
+
+// RecordExtraObjectStoreHit records that an object was copied from the extra
+// object store "dir", rather than being downloaded.
+func (f *Filesystem) RecordExtraObjectStoreHit(dir string) {
+	f.extraStoreMu.Lock()
+	defer f.extraStoreMu.Unlock()
+
+	if f.extraStoreHits == nil {
+		f.extraStoreHits = make(map[string]int64)
+	}
+	f.extraStoreHits[dir]++
+}
+
+// ExtraObjectStoreHits returns the number of objects copied from each extra
+// object store so far. Stores from which no objects were copied are absent.
+func (f *Filesystem) ExtraObjectStoreHits() map[string]int64 {
+	f.extraStoreMu.Lock()
+	defer f.extraStoreMu.Unlock()
+
+	hits := make(map[string]int64, len(f.extraStoreHits))
+	for dir, n := range f.extraStoreHits {
+		hits[dir] = n
+	}
+	return hits
+}
+
 func (f *Filesystem) ObjectPath(oid string) (string, error) {
 	dir := f.localObjectDir(oid)
-	if err := tools.MkdirAll(dir, f); err != nil {
+	if err := tools.MkdirAll(tools.LongPath(dir), f); err != nil {
 		return "", fmt.Errorf("error trying to create local storage directory in %q: %s", dir, err)
 	}
-	return filepath.Join(dir, oid), nil
+	return tools.LongPath(filepath.Join(dir, oid)), nil
 }
 
 func (f *Filesystem) ObjectPathname(oid string) string {
-	return filepath.Join(f.localObjectDir(oid), oid)
+	return tools.LongPath(filepath.Join(f.localObjectDir(oid), oid))
 }
 
 func (f *Filesystem) DecodePathname(path string) string {
@@ -87,6 +169,10 @@ func (f *Filesystem) RepositoryPermissions(executable bool) os.FileMode {
 	return f.repoPerms
 }
 
+func (f *Filesystem) RepositoryShared() bool {
+	return f.SharedRepository
+}
+
 /**
  * Revert non ascii chracters escaped by git or windows (as octal sequences \000) back to bytes.
  */
@@ -135,13 +221,38 @@ func (f *Filesystem) ObjectReferencePaths(oid string) []string {
 	return paths
 }
 
+// ExtraObjectStorePaths returns the paths at which the object "oid" would be
+// found in each of the ExtraObjectStores, in order.
+func (f *Filesystem) ExtraObjectStorePaths(oid string) []string {
+	if len(f.ExtraObjectStores) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(f.ExtraObjectStores))
+	for _, dir := range f.ExtraObjectStores {
+		paths = append(paths, filepath.Join(dir, oid[0:2], oid[2:4], oid))
+	}
+	return paths
+}
+
+// IsSharedStorage returns whether the LFS storage directory lies outside of
+// the Git directory, as it may when set with lfs.storage, in which case other
+// repositories may be using it too.
+func (f *Filesystem) IsSharedStorage() bool {
+	rel, err := filepath.Rel(f.GitStorageDir, f.LFSStorageDir)
+	if err != nil {
+		return true
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func (f *Filesystem) LFSObjectDir() string {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
 	if len(f.lfsobjdir) == 0 {
 		f.lfsobjdir = filepath.Join(f.LFSStorageDir, "objects")
-		tools.MkdirAll(f.lfsobjdir, f)
+		tools.MkdirAll(tools.LongPath(f.lfsobjdir), f)
 	}
 
 	return f.lfsobjdir
@@ -159,22 +270,72 @@ func (f *Filesystem) LogDir() string {
 	return f.logdir
 }
 
+// QuarantineDir returns the directory which the files rejected by
+// lfs.postsmudgecheck are moved to. It is not created until it is needed.
+func (f *Filesystem) QuarantineDir() string {
+	return filepath.Join(f.LFSStorageDir, "quarantine")
+}
+
+// TempDir returns the directory for temporary files, which are renamed into
+// the object store once complete. It is "tmp" beside the objects directory,
+// so that those renames stay within one filesystem, unless set otherwise with
+// SetTempDir.
 func (f *Filesystem) TempDir() string {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
 	if len(f.tmpdir) == 0 {
 		f.tmpdir = filepath.Join(f.LFSStorageDir, "tmp")
-		tools.MkdirAll(f.tmpdir, f)
+		tools.MkdirAll(tools.LongPath(f.tmpdir), f)
 	}
 
 	return f.tmpdir
 }
 
+// SetTempDir sets the directory for temporary files, as lfs.tmpdir does. A
+// relative path is relative to the Git directory, as it is for lfs.storage.
+func (f *Filesystem) SetTempDir(dir string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(f.GitStorageDir, dir)
+	}
+	f.tmpdir = dir
+	tools.MkdirAll(f.tmpdir, f)
+}
+
+// CheckTempDir returns an error naming the directory for temporary files if
+// one cannot be created in it.
+func (f *Filesystem) CheckTempDir() error {
+	dir := f.TempDir()
+	if err := tools.MkdirAll(dir, f); err != nil {
+		return fmt.Errorf("temporary directory %q is not writable: %s", dir, err)
+	}
+
+	tmp, err := ioutil.TempFile(dir, "check")
+	if err != nil {
+		return fmt.Errorf("temporary directory %q is not writable: %s", dir, err)
+	}
+	tmp.Close()
+	os.Remove(tmp.Name())
+	return nil
+}
+
 func (f *Filesystem) Cleanup() error {
 	if f == nil {
 		return nil
 	}
+	if err := f.FlushAccesses(); err != nil {
+		tracerx.Printf("fs: could not update the access index: %s", err)
+	}
+	hits := f.ExtraObjectStoreHits()
+	for _, dir := range f.ExtraObjectStores {
+		tracerx.Printf("fs: copied %d object(s) from extra object store %s", hits[dir], dir)
+	}
+	if err := f.FlushMetrics(); err != nil {
+		tracerx.Printf("fs: could not update the metrics state: %s", err)
+	}
 	return f.cleanupTmp()
 }
 
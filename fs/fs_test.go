@@ -1,7 +1,9 @@
 package fs
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -42,3 +44,61 @@ func TestRepositoryPermissions(t *testing.T) {
 		assert.Equal(t, v, fs.RepositoryPermissions(false))
 	}
 }
+
+func TestIsSharedStorage(t *testing.T) {
+	for lfsdir, shared := range map[string]bool{
+		"":               false,
+		"lfs":            false,
+		"custom/lfs":     false,
+		"../lfs":         true,
+		"../../shared":   true,
+		"/srv/lfs-store": true,
+		"/repo/.git/lfs": false,
+	} {
+		fs := New(testEnv{}, "/repo/.git", "/repo", lfsdir, 0644)
+		assert.Equal(t, shared, fs.IsSharedStorage(), "lfs.storage=%q", lfsdir)
+	}
+}
+
+func TestSetTempDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-tmpdir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	gitdir := filepath.Join(dir, ".git")
+	fs := New(testEnv{}, gitdir, dir, "", 0755)
+	assert.Equal(t, filepath.Join(gitdir, "lfs", "tmp"), fs.TempDir())
+
+	fs.SetTempDir("lfs-tmp")
+	assert.Equal(t, filepath.Join(gitdir, "lfs-tmp"), fs.TempDir())
+	assert.DirExists(t, fs.TempDir())
+	assert.NoError(t, fs.CheckTempDir())
+
+	abs := filepath.Join(dir, "elsewhere")
+	fs.SetTempDir(abs)
+	assert.Equal(t, abs, fs.TempDir())
+	assert.NoError(t, fs.CheckTempDir())
+}
+
+func TestCheckTempDirNamesUnwritableDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-tmpdir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// A file where the directory should be can't be written to, even by
+	// root.
+	blocked := filepath.Join(dir, "blocked")
+	assert.NoError(t, ioutil.WriteFile(blocked, nil, 0644))
+
+	fs := New(testEnv{}, filepath.Join(dir, ".git"), dir, "", 0755)
+	fs.SetTempDir(blocked)
+
+	err = fs.CheckTempDir()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), blocked)
+	}
+}
+
+type testEnv struct{}
+
+func (testEnv) Get(key string) (string, bool) { return "", false }
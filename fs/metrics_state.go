@@ -0,0 +1,397 @@
+package fs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/git-lfs/git-lfs/tools"
+)
+
+const (
+	// metricsStateName is the name of the file in the LFS storage directory
+	// which accumulates the metrics of the local object store across
+	// processes.
+	metricsStateName = "metrics"
+
+	// metricsPendingName is the name of the directory in the LFS storage
+	// directory in which each process leaves the metrics it recorded, to
+	// be added to the metrics state later, so that processes such as the
+	// filters need neither lock nor rewrite the state.
+	metricsPendingName = "metrics.d"
+
+	// maxPendingMetrics is how many processes may leave their metrics
+	// pending before the next to do so adds them all to the metrics state.
+	maxPendingMetrics = 64
+
+	// metricsRecountAge is how long the object count and total size are
+	// trusted before the objects in the store are counted again, in case
+	// they were changed without being recorded.
+	metricsRecountAge = 24 * time.Hour
+)
+
+// MetricsState is the behavior and contents of the local object store, as
+// accumulated by every Git LFS process which has used it.
+type MetricsState struct {
+	// CacheHits and CacheMisses are the number of objects which were,
+	// and were not, present in the local object store when needed.
+	CacheHits   int64 `json:"cache_hits"`
+	CacheMisses int64 `json:"cache_misses"`
+	// BandwidthSavedBytes is the total size of the objects served from
+	// the local object store.
+	BandwidthSavedBytes int64 `json:"bandwidth_saved_bytes"`
+	// ExtraObjectStoreHits is the number of objects copied from each of
+	// the stores given by lfs.extraobjectstores.
+	ExtraObjectStoreHits map[string]int64 `json:"extra_object_store_hits,omitempty"`
+	// ObjectCount and TotalCachedBytes are the number and total size of
+	// the objects in the local object store. They are counted by walking
+	// the store, and then kept up to date as objects are added and
+	// removed, until they are counted again.
+	ObjectCount      int64 `json:"object_count"`
+	TotalCachedBytes int64 `json:"total_cached_bytes"`
+	// CountedAt is when the objects were last counted, in seconds since
+	// the Unix epoch, or zero if they must be counted before they can be
+	// trusted.
+	CountedAt int64 `json:"counted_at"`
+}
+
+// metricsCounts are the metrics recorded by this process.
+type metricsCounts struct {
+	hits, misses, hitBytes int64
+	objects, bytes         int64
+	extraStoreHits         map[string]int64
+}
+
+// RecordObjectAdded records that an object of the given size was added to the
+// local object store.
+func (f *Filesystem) RecordObjectAdded(size int64) {
+	atomic.AddInt64(&f.objectsAdded, 1)
+	atomic.AddInt64(&f.bytesAdded, size)
+}
+
+// RecordObjectRemoved records that an object of the given size was removed
+// from the local object store.
+func (f *Filesystem) RecordObjectRemoved(size int64) {
+	atomic.AddInt64(&f.objectsAdded, -1)
+	atomic.AddInt64(&f.bytesAdded, -size)
+}
+
+// RemoveObject removes the object "oid" from the local object store, recording
+// its removal. It returns false, and no error, if there was no such object.
+func (f *Filesystem) RemoveObject(oid string) (bool, error) {
+	path := f.ObjectPathname(oid)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info != nil {
+		f.RecordObjectRemoved(info.Size())
+	}
+	return true, nil
+}
+
+// Metrics returns the metrics state of the local object store, including what
+// this process has recorded but not yet flushed. If the objects in the store
+// have not been counted within metricsRecountAge, they are counted now.
+func (f *Filesystem) Metrics() (*MetricsState, error) {
+	return f.metrics(false)
+}
+
+// RecountObjects counts the objects in the local object store, correcting the
+// object count and total size of the metrics state if anything changed the
+// store without recording it.
+func (f *Filesystem) RecountObjects() error {
+	_, err := f.metrics(true)
+	return err
+}
+
+func (f *Filesystem) metrics(recount bool) (*MetricsState, error) {
+	f.metricsMu.Lock()
+	defer f.metricsMu.Unlock()
+
+	var state *MetricsState
+	err := f.updateMetricsState(func(s *MetricsState) bool {
+		state = s
+
+		countedAt := time.Unix(s.CountedAt, 0)
+		if !recount && s.CountedAt != 0 && time.Since(countedAt) < metricsRecountAge {
+			return false
+		}
+		f.countObjects(s)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	state.add(f.currentMetrics().since(f.metricsFlushed))
+	return state, nil
+}
+
+// FlushMetrics leaves the metrics recorded by this process since it was last
+// called to be added to the metrics state, adding them, and those left by
+// other processes, if too many are pending. Nothing is written if nothing was
+// recorded.
+func (f *Filesystem) FlushMetrics() error {
+	f.metricsMu.Lock()
+	defer f.metricsMu.Unlock()
+
+	cur := f.currentMetrics()
+	d := cur.since(f.metricsFlushed)
+	if d.empty() {
+		return nil
+	}
+
+	if err := f.writePendingMetrics(d); err != nil {
+		return err
+	}
+	f.metricsFlushed = cur
+
+	if len(f.pendingMetrics()) < maxPendingMetrics {
+		return nil
+	}
+	return f.updateMetricsState(func(s *MetricsState) bool {
+		return false
+	})
+}
+
+// countObjects sets the object count and total size of "s" by walking the local
+// object store. It must only be called while holding metricsMu.
+func (f *Filesystem) countObjects(s *MetricsState) {
+	// The objects added and removed by this process so far are part of
+	// the count.
+	cur := f.currentMetrics()
+	f.metricsFlushed.objects, f.metricsFlushed.bytes = cur.objects, cur.bytes
+
+	s.ObjectCount, s.TotalCachedBytes = 0, 0
+	f.EachObject(func(obj Object) error {
+		s.ObjectCount++
+		s.TotalCachedBytes += obj.Size
+		return nil
+	})
+	s.CountedAt = time.Now().Unix()
+}
+
+func (f *Filesystem) currentMetrics() metricsCounts {
+	hits, misses, hitBytes := f.CacheCounters()
+	return metricsCounts{
+		hits:           hits,
+		misses:         misses,
+		hitBytes:       hitBytes,
+		objects:        atomic.LoadInt64(&f.objectsAdded),
+		bytes:          atomic.LoadInt64(&f.bytesAdded),
+		extraStoreHits: f.ExtraObjectStoreHits(),
+	}
+}
+
+// since returns the metrics recorded between "prev" and "c".
+func (c metricsCounts) since(prev metricsCounts) metricsCounts {
+	d := metricsCounts{
+		hits:           c.hits - prev.hits,
+		misses:         c.misses - prev.misses,
+		hitBytes:       c.hitBytes - prev.hitBytes,
+		objects:        c.objects - prev.objects,
+		bytes:          c.bytes - prev.bytes,
+		extraStoreHits: make(map[string]int64),
+	}
+	for dir, n := range c.extraStoreHits {
+		if n != prev.extraStoreHits[dir] {
+			d.extraStoreHits[dir] = n - prev.extraStoreHits[dir]
+		}
+	}
+	return d
+}
+
+func (c metricsCounts) empty() bool {
+	return c.hits == 0 && c.misses == 0 && c.hitBytes == 0 &&
+		c.objects == 0 && c.bytes == 0 && len(c.extraStoreHits) == 0
+}
+
+// add adds the metrics "d" to "s". The object count and total size, which are
+// negative for objects removed, may not fall below zero, as they may if
+// objects were removed which were never counted, in which case the objects
+// must be counted again.
+func (s *MetricsState) add(d metricsCounts) {
+	s.CacheHits += d.hits
+	s.CacheMisses += d.misses
+	s.BandwidthSavedBytes += d.hitBytes
+
+	if len(d.extraStoreHits) > 0 && s.ExtraObjectStoreHits == nil {
+		s.ExtraObjectStoreHits = make(map[string]int64)
+	}
+	for dir, n := range d.extraStoreHits {
+		s.ExtraObjectStoreHits[dir] += n
+	}
+
+	s.ObjectCount += d.objects
+	s.TotalCachedBytes += d.bytes
+	if s.ObjectCount < 0 || s.TotalCachedBytes < 0 {
+		s.ObjectCount, s.TotalCachedBytes = 0, 0
+		s.CountedAt = 0
+	}
+}
+
+// counts returns the metrics of "s" as recorded by a single process, such as
+// one which left them pending.
+func (s *MetricsState) counts() metricsCounts {
+	return metricsCounts{
+		hits:           s.CacheHits,
+		misses:         s.CacheMisses,
+		hitBytes:       s.BandwidthSavedBytes,
+		objects:        s.ObjectCount,
+		bytes:          s.TotalCachedBytes,
+		extraStoreHits: s.ExtraObjectStoreHits,
+	}
+}
+
+func (f *Filesystem) metricsStatePath() string {
+	return filepath.Join(f.LFSStorageDir, metricsStateName)
+}
+
+func (f *Filesystem) metricsPendingDir() string {
+	return filepath.Join(f.LFSStorageDir, metricsPendingName)
+}
+
+// updateMetricsState reads the metrics state while holding its lock, adding the
+// metrics left pending by other processes, and writes it back if there were
+// any, or if "fn" reports that it changed it, replacing it atomically.
+func (f *Filesystem) updateMetricsState(fn func(s *MetricsState) bool) error {
+	if err := tools.MkdirAll(f.LFSStorageDir, f); err != nil {
+		return err
+	}
+
+	unlock, err := lockStateFile(f.metricsStatePath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := f.readMetricsState()
+	if err != nil {
+		return err
+	}
+
+	pending := f.pendingMetrics()
+	for _, path := range pending {
+		p, err := readMetricsStateFile(path)
+		if err != nil {
+			return err
+		}
+		s.add(p.counts())
+	}
+
+	if !fn(s) && len(pending) == 0 {
+		return nil
+	}
+	if err := f.writeMetricsState(s); err != nil {
+		return err
+	}
+
+	for _, path := range pending {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// pendingMetrics returns the paths of the files in which processes left their
+// metrics to be added to the metrics state.
+func (f *Filesystem) pendingMetrics() []string {
+	infos, err := ioutil.ReadDir(f.metricsPendingDir())
+	if err != nil {
+		return nil
+	}
+
+	paths := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if strings.HasSuffix(info.Name(), ".json") {
+			paths = append(paths, filepath.Join(f.metricsPendingDir(), info.Name()))
+		}
+	}
+	return paths
+}
+
+// writePendingMetrics leaves the metrics "d" in a new file in the pending
+// directory, which only appears once it is complete.
+func (f *Filesystem) writePendingMetrics(d metricsCounts) error {
+	data, err := json.Marshal(&MetricsState{
+		CacheHits:            d.hits,
+		CacheMisses:          d.misses,
+		BandwidthSavedBytes:  d.hitBytes,
+		ExtraObjectStoreHits: d.extraStoreHits,
+		ObjectCount:          d.objects,
+		TotalCachedBytes:     d.bytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	dir := f.metricsPendingDir()
+	if err := tools.MkdirAll(dir, f); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), f.RepositoryPermissions(false))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), strings.TrimSuffix(tmp.Name(), ".tmp")+".json")
+}
+
+// readMetricsState returns the metrics state. A missing or malformed state is
+// empty, and so has not been counted.
+func (f *Filesystem) readMetricsState() (*MetricsState, error) {
+	return readMetricsStateFile(f.metricsStatePath())
+}
+
+func readMetricsStateFile(path string) (*MetricsState, error) {
+	s := &MetricsState{}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return &MetricsState{}, nil
+	}
+	return s, nil
+}
+
+// writeMetricsState replaces the metrics state with "s". It must only be
+// called while holding the lock.
+func (f *Filesystem) writeMetricsState(s *MetricsState) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := f.metricsStatePath() + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), f.RepositoryPermissions(false)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, f.metricsStatePath())
+}
@@ -0,0 +1,191 @@
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMetricsTestFilesystem(t *testing.T, dir string) *Filesystem {
+	fs := New(testEnv{}, filepath.Join(dir, ".git"), dir, "", 0755)
+	fs.LFSObjectDir()
+	return fs
+}
+
+func writeMetricsTestObject(t *testing.T, fs *Filesystem, oid, contents string) {
+	path, err := fs.ObjectPath(oid)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+}
+
+func TestFlushMetricsAccumulatesAcrossProcesses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := newMetricsTestFilesystem(t, dir)
+	first.RecordCacheHit(10)
+	first.RecordCacheHit(20)
+	first.RecordCacheMiss()
+	first.RecordExtraObjectStoreHit("/extra")
+	assert.NoError(t, first.FlushMetrics())
+
+	// Flushing leaves the metrics pending, without rewriting the state.
+	_, err = os.Stat(first.metricsStatePath())
+	assert.True(t, os.IsNotExist(err))
+	assert.Len(t, first.pendingMetrics(), 1)
+
+	// Flushing again adds nothing more.
+	assert.NoError(t, first.FlushMetrics())
+
+	second := newMetricsTestFilesystem(t, dir)
+	second.RecordCacheHit(5)
+	second.RecordExtraObjectStoreHit("/extra")
+	assert.NoError(t, second.FlushMetrics())
+
+	state, err := newMetricsTestFilesystem(t, dir).Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, state.CacheHits)
+	assert.EqualValues(t, 1, state.CacheMisses)
+	assert.EqualValues(t, 35, state.BandwidthSavedBytes)
+	assert.Equal(t, map[string]int64{"/extra": 2}, state.ExtraObjectStoreHits)
+	assert.Empty(t, first.pendingMetrics())
+
+	_, err = os.Stat(first.metricsStatePath() + ".lock")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFlushMetricsAddsTooManyPendingMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < maxPendingMetrics; i++ {
+		fs := newMetricsTestFilesystem(t, dir)
+		fs.RecordCacheMiss()
+		assert.NoError(t, fs.FlushMetrics())
+	}
+	assert.Empty(t, newMetricsTestFilesystem(t, dir).pendingMetrics())
+
+	state, err := newMetricsTestFilesystem(t, dir).readMetricsState()
+	assert.NoError(t, err)
+	assert.EqualValues(t, maxPendingMetrics, state.CacheMisses)
+}
+
+func TestMetricsCountsObjectsOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := newMetricsTestFilesystem(t, dir)
+	writeMetricsTestObject(t, fs, accessTestOid1, "one")
+
+	state, err := fs.Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, state.ObjectCount)
+	assert.EqualValues(t, 3, state.TotalCachedBytes)
+
+	// Objects are not counted again until they are recounted, and so
+	// those written without being recorded are absent, while those
+	// recorded are present.
+	writeMetricsTestObject(t, fs, accessTestOid2, "unrecorded")
+	fs.RecordObjectAdded(5)
+	assert.NoError(t, fs.FlushMetrics())
+
+	other := newMetricsTestFilesystem(t, dir)
+	other.RecordObjectRemoved(3)
+	state, err = other.Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, state.ObjectCount)
+	assert.EqualValues(t, 5, state.TotalCachedBytes)
+
+	assert.NoError(t, fs.RecountObjects())
+	state, err = newMetricsTestFilesystem(t, dir).Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, state.ObjectCount)
+	assert.EqualValues(t, 13, state.TotalCachedBytes)
+}
+
+func TestMetricsRecountsOldCounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := newMetricsTestFilesystem(t, dir)
+	writeMetricsTestObject(t, fs, accessTestOid1, "one")
+	assert.NoError(t, fs.writeMetricsState(&MetricsState{
+		ObjectCount:      5,
+		TotalCachedBytes: 50,
+		CountedAt:        time.Now().Add(-metricsRecountAge).Unix(),
+	}))
+
+	state, err := fs.Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, state.ObjectCount)
+	assert.EqualValues(t, 3, state.TotalCachedBytes)
+}
+
+func TestMetricsRecountsInconsistentCounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := newMetricsTestFilesystem(t, dir)
+	writeMetricsTestObject(t, fs, accessTestOid1, "one")
+	assert.NoError(t, fs.writeMetricsState(&MetricsState{CountedAt: time.Now().Unix()}))
+
+	// Removing an object which was never counted cannot be right, so the
+	// objects are counted again.
+	other := newMetricsTestFilesystem(t, dir)
+	other.RecordObjectRemoved(10)
+	assert.NoError(t, other.FlushMetrics())
+
+	state, err := fs.Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, state.ObjectCount)
+	assert.EqualValues(t, 3, state.TotalCachedBytes)
+}
+
+func TestMetricsDoesNotCountObjectsTwice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := newMetricsTestFilesystem(t, dir)
+	writeMetricsTestObject(t, fs, accessTestOid1, "one")
+	fs.RecordObjectAdded(3)
+	assert.NoError(t, fs.FlushMetrics())
+
+	state, err := newMetricsTestFilesystem(t, dir).Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, state.ObjectCount)
+	assert.EqualValues(t, 3, state.TotalCachedBytes)
+}
+
+func TestRemoveObjectRecordsRemoval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fs-metrics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := newMetricsTestFilesystem(t, dir)
+	writeMetricsTestObject(t, fs, accessTestOid1, "one")
+	_, err = fs.Metrics()
+	assert.NoError(t, err)
+
+	removed, err := fs.RemoveObject(accessTestOid1)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = fs.RemoveObject(accessTestOid1)
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	state, err := fs.Metrics()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, state.ObjectCount)
+	assert.EqualValues(t, 0, state.TotalCachedBytes)
+}
@@ -1,9 +1,12 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/git-lfs/git-lfs/filepathfilter"
 	"github.com/git-lfs/git-lfs/git/gitattr"
@@ -14,6 +17,14 @@ import (
 const (
 	LockableAttrib = "lockable"
 	FilterAttrib   = "filter"
+	CompressAttrib = "lfs-compress"
+	FetchAttrib    = "lfs-fetch"
+	VerifyAttrib   = "lfs-verify"
+	ExtAttrib      = "lfs-ext"
+
+	// lfsAttribPrefix is the prefix of the names of the attributes which
+	// Git LFS reads.
+	lfsAttribPrefix = "lfs-"
 )
 
 // AttributePath is a path entry in a gitattributes file which has the LFS filter
@@ -26,6 +37,14 @@ type AttributePath struct {
 	Lockable bool
 	// Path is handled by Git LFS (i.e., filter=lfs)
 	Tracked bool
+	// The value of the 'lfs-compress' attribute of Path, if it has one
+	Compress string
+	// The value of the 'lfs-fetch' attribute of Path, if it has one
+	Fetch string
+	// The value of the 'lfs-verify' attribute of Path, if it has one
+	Verify string
+	// The value of the 'lfs-ext' attribute of Path, if it has one
+	Ext string
 }
 
 type AttributeSource struct {
@@ -92,7 +111,7 @@ func GetAttributePaths(mp *gitattr.MacroProcessor, workingDir, gitDir string) []
 }
 
 func attrPaths(mp *gitattr.MacroProcessor, path, workingDir string, readMacros bool) []AttributePath {
-	attributes, err := os.Open(path)
+	attributes, err := os.Open(tools.LongPath(path))
 	if err != nil {
 		return nil
 	}
@@ -103,6 +122,9 @@ func attrPaths(mp *gitattr.MacroProcessor, path, workingDir string, readMacros b
 	relfile, _ := filepath.Rel(workingDir, path)
 	reldir := filepath.Dir(relfile)
 	source := &AttributeSource{Path: relfile}
+	if len(workingDir) == 0 {
+		source.Path = path
+	}
 
 	lines, eol, err := gitattr.ParseLines(attributes)
 	if err != nil {
@@ -115,17 +137,33 @@ func attrPaths(mp *gitattr.MacroProcessor, path, workingDir string, readMacros b
 		lockable := false
 		tracked := false
 		hasFilter := false
+		var compress, fetch, verify, ext string
 
 		for _, attr := range line.Attrs {
-			if attr.K == FilterAttrib {
+			switch attr.K {
+			case FilterAttrib:
 				hasFilter = true
 				tracked = attr.V == "lfs"
-			} else if attr.K == LockableAttrib && attr.V == "true" {
-				lockable = true
+			case LockableAttrib:
+				if attr.V == "true" {
+					lockable = true
+				}
+			case CompressAttrib:
+				compress = attr.V
+			case FetchAttrib:
+				fetch = attr.V
+			case VerifyAttrib:
+				verify = attr.V
+			case ExtAttrib:
+				ext = attr.V
+			default:
+				if strings.HasPrefix(attr.K, lfsAttribPrefix) {
+					warnUnknownAttribute(attr.K, source.Path)
+				}
 			}
 		}
 
-		if !hasFilter && !lockable {
+		if !hasFilter && !lockable && len(compress+fetch+verify+ext) == 0 {
 			continue
 		}
 
@@ -139,6 +177,10 @@ func attrPaths(mp *gitattr.MacroProcessor, path, workingDir string, readMacros b
 			Source:   source,
 			Lockable: lockable,
 			Tracked:  tracked,
+			Compress: compress,
+			Fetch:    fetch,
+			Verify:   verify,
+			Ext:      ext,
 		})
 	}
 
@@ -165,6 +207,54 @@ func GetAttributeFilter(workingDir, gitDir string) *filepathfilter.Filter {
 	return filepathfilter.NewFromPatterns(patterns, nil)
 }
 
+// AttributeValues gives the value which a path has for one of the attributes
+// read into AttributePath, as Git would resolve it: the last matching line of a
+// gitattributes file wins, and a file in a subdirectory wins over those in the
+// directories above it.
+type AttributeValues struct {
+	// patterns are the patterns with a value for the attribute, in the
+	// order in which they take precedence.
+	patterns []filepathfilter.Pattern
+	values   []string
+}
+
+// NewAttributeValues returns the values for the attribute which "value" reads
+// from each of "paths", which are ordered as GetAttributePaths orders them.
+// Paths for which "value" returns "" do not have the attribute.
+func NewAttributeValues(paths []AttributePath, value func(AttributePath) string) *AttributeValues {
+	v := &AttributeValues{}
+
+	// GetAttributePaths returns files with the most specific first, but
+	// the lines of each file in order, so each file's lines are reversed.
+	var start int
+	for i, path := range paths {
+		if i > 0 && path.Source != paths[i-1].Source {
+			start = len(v.patterns)
+		}
+
+		val := value(path)
+		if len(val) == 0 {
+			continue
+		}
+
+		pattern := filepathfilter.NewPattern(filepath.ToSlash(path.Path))
+		v.patterns = append(v.patterns[:start], append([]filepathfilter.Pattern{pattern}, v.patterns[start:]...)...)
+		v.values = append(v.values[:start], append([]string{val}, v.values[start:]...)...)
+	}
+	return v
+}
+
+// Get returns the value of the attribute for "filename", which is relative to
+// the root of the working tree, or "" if it does not have the attribute.
+func (v *AttributeValues) Get(filename string) string {
+	for i, pattern := range v.patterns {
+		if pattern.Match(filename) {
+			return v.values[i]
+		}
+	}
+	return ""
+}
+
 func findAttributeFiles(workingDir, gitDir string) []attrFile {
 	var paths []attrFile
 
@@ -181,9 +271,18 @@ func findAttributeFiles(workingDir, gitDir string) []attrFile {
 
 	if gitattributesFiles, present := lsFiles.FilesByName[".gitattributes"]; present {
 		for _, f := range gitattributesFiles {
+			path := filepath.Join(workingDir, f.FullPath)
+
+			// Git does not follow symlinked .gitattributes files
+			// within the working tree, so neither do we.
+			if info, err := os.Lstat(tools.LongPath(path)); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				warnSymlinkedAttributes(f.FullPath)
+				continue
+			}
+
 			tracerx.Printf("findAttributeFiles: located %s", f.FullPath)
 			paths = append(paths, attrFile{
-				path:       filepath.Join(workingDir, f.FullPath),
+				path:       path,
 				readMacros: f.FullPath == ".gitattributes", // Read macros from the top-level attributes
 			})
 		}
@@ -197,3 +296,29 @@ func findAttributeFiles(workingDir, gitDir string) []attrFile {
 
 	return paths
 }
+
+// warnedSymlinks is the set of symlinked .gitattributes files which have
+// already been warned about, since attributes may be read several times by a
+// single command.
+var warnedSymlinks sync.Map
+
+// warnSymlinkedAttributes warns that the symlinked .gitattributes file at
+// "path" is being skipped, once per path.
+func warnSymlinkedAttributes(path string) {
+	if _, warned := warnedSymlinks.LoadOrStore(path, true); !warned {
+		fmt.Fprintf(os.Stderr, "Skipping symlinked .gitattributes: %s\n", path)
+	}
+}
+
+// warnedAttributes is the set of unknown Git LFS attributes which have already
+// been warned about.
+var warnedAttributes sync.Map
+
+// warnUnknownAttribute warns that the attribute "name", found in the
+// gitattributes file at "path", looks like a Git LFS attribute but is not one,
+// once per name, since it is most likely misspelled.
+func warnUnknownAttribute(name, path string) {
+	if _, warned := warnedAttributes.LoadOrStore(name, true); !warned {
+		fmt.Fprintf(os.Stderr, "Ignoring unknown Git LFS attribute %q in %s\n", name, path)
+	}
+}
@@ -0,0 +1,83 @@
+package git_test // to avoid import cycles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/git/gitattr"
+	test "github.com/git-lfs/git-lfs/t/cmd/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAttributePathsSkipsSymlinks(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	write := func(name, contents string) {
+		require.Nil(t, ioutil.WriteFile(filepath.Join(repo.Path, name), []byte(contents), 0644))
+	}
+	write(".gitattributes", "*.dat filter=lfs diff=lfs merge=lfs -text\n")
+	write("other.attrs", "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+
+	require.Nil(t, os.Mkdir(filepath.Join(repo.Path, "dir"), 0755))
+	require.Nil(t, os.Symlink(filepath.Join("..", "other.attrs"), filepath.Join(repo.Path, "dir", ".gitattributes")))
+
+	paths := GetAttributePaths(gitattr.NewMacroProcessor(), repo.Path, filepath.Join(repo.Path, ".git"))
+
+	if assert.Len(t, paths, 1) {
+		assert.Equal(t, "*.dat", paths[0].Path)
+	}
+}
+
+func TestGetAttributePathsReadsLFSAttributes(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(repo.Path, ".gitattributes"), []byte(
+		"*.psd filter=lfs diff=lfs merge=lfs -text lfs-fetch=false\n"+
+			"*.key lfs-ext=enc lfs-verify\n"+
+			"*.txt text\n"), 0644))
+
+	paths := GetAttributePaths(gitattr.NewMacroProcessor(), repo.Path, filepath.Join(repo.Path, ".git"))
+
+	if assert.Len(t, paths, 2) {
+		assert.Equal(t, "*.psd", paths[0].Path)
+		assert.True(t, paths[0].Tracked)
+		assert.Equal(t, "false", paths[0].Fetch)
+
+		assert.Equal(t, "*.key", paths[1].Path)
+		assert.False(t, paths[1].Tracked)
+		assert.Equal(t, "enc", paths[1].Ext)
+		assert.Equal(t, "true", paths[1].Verify)
+	}
+}
+
+func TestAttributeValuesPrecedence(t *testing.T) {
+	root := &AttributeSource{Path: ".gitattributes"}
+	sub := &AttributeSource{Path: "dir/.gitattributes"}
+
+	// As GetAttributePaths orders them, with the most specific file first.
+	values := NewAttributeValues([]AttributePath{
+		{Path: "dir/*.bin", Source: sub, Fetch: "true"},
+		{Path: "*.bin", Source: root, Fetch: "false"},
+		{Path: "*.dat", Source: root},
+		{Path: "keep.bin", Source: root, Fetch: "true"},
+	}, func(p AttributePath) string { return p.Fetch })
+
+	assert.Equal(t, "false", values.Get("a.bin"))
+	assert.Equal(t, "true", values.Get("keep.bin"))
+	assert.Equal(t, "true", values.Get("dir/a.bin"))
+	assert.Equal(t, "", values.Get("a.dat"))
+}
@@ -89,6 +89,37 @@ func (c *Configuration) FindWorktree(key string) string {
 	return output
 }
 
+// FindScope returns the scope ("system", "global", "local", "worktree" or
+// "command") from which the effective git config value for the key comes, or
+// an empty string if the key is unset or Git is too old to report it.
+func (c *Configuration) FindScope(key string) string {
+	if !IsGitVersionAtLeast("2.26.0") {
+		return ""
+	}
+	output, err := c.gitConfig("--show-scope", "--get", key)
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(output, "\t", 2)[0]
+}
+
+// WorktreeConfigEnabled returns whether the worktreeConfig extension is
+// enabled, so that worktree scope is separate from local scope
+func (c *Configuration) WorktreeConfigEnabled() bool {
+	output, _ := c.gitConfig("--bool", "extensions.worktreeConfig")
+	return output == "true"
+}
+
+// EnableWorktreeConfig enables the worktreeConfig extension in the local
+// config, which also requires repository format version 1
+func (c *Configuration) EnableWorktreeConfig() error {
+	if _, err := c.gitConfigWrite("--local", "core.repositoryformatversion", "1"); err != nil {
+		return err
+	}
+	_, err := c.gitConfigWrite("--local", "--bool", "extensions.worktreeConfig", "true")
+	return err
+}
+
 // SetGlobal sets the git config value for the key in the global config
 func (c *Configuration) SetGlobal(key, val string) (string, error) {
 	return c.gitConfigWrite("--global", "--replace-all", key, val)
@@ -129,6 +160,21 @@ func (c *Configuration) UnsetWorktreeSection(key string) (string, error) {
 	return c.gitConfigWrite("--worktree", "--remove-section", key)
 }
 
+// UnsetGlobalKey removes the git config value for the key from the global config
+func (c *Configuration) UnsetGlobalKey(key string) (string, error) {
+	return c.gitConfigWrite("--global", "--unset", key)
+}
+
+// UnsetSystemKey removes the git config value for the key from the system config
+func (c *Configuration) UnsetSystemKey(key string) (string, error) {
+	return c.gitConfigWrite("--system", "--unset", key)
+}
+
+// UnsetWorktreeKey removes the git config value for the key from the worktree or local config, depending on whether multiple worktrees are in use
+func (c *Configuration) UnsetWorktreeKey(key string) (string, error) {
+	return c.gitConfigWrite("--worktree", "--unset", key)
+}
+
 // UnsetLocalKey removes the git config value for the key from the specified config file
 func (c *Configuration) UnsetLocalKey(key string) (string, error) {
 	return c.gitConfigWrite("--unset", key)
@@ -177,6 +177,24 @@ func gitBuffered(args ...string) (*subprocess.BufferedCmd, error) {
 	return subprocess.BufferedExec("git", args...)
 }
 
+// IndexBlob returns the contents of the blob staged in the index at "path",
+// relative to the root of the repository, without any trailing whitespace. It
+// returns an error if there is none, or if it is larger than "limit" bytes.
+func IndexBlob(path string, limit int64) (string, error) {
+	out, err := gitNoLFSSimple("cat-file", "-s", ":"+path)
+	if err != nil {
+		return "", err
+	}
+	size, err := strconv.ParseInt(out, 10, 64)
+	if err != nil {
+		return "", err
+	}
+	if size > limit {
+		return "", fmt.Errorf("blob at %q is larger than %d bytes", path, limit)
+	}
+	return gitNoLFSSimple("cat-file", "blob", ":"+path)
+}
+
 func CatFile() (*subprocess.BufferedCmd, error) {
 	return gitNoLFSBuffered("cat-file", "--batch-check")
 }
@@ -222,6 +240,23 @@ func Log(args ...string) (*subprocess.BufferedCmd, error) {
 	return gitNoLFSBuffered(logArgs...)
 }
 
+// FetchObjects fetches the objects "oids" from the promisor remote "remote" of
+// a partial clone in a single request, as Git fetches the objects it finds
+// missing, but for all of them at once. It requires Git 2.29.0 or later.
+func FetchObjects(remote string, oids []string) error {
+	cmd := gitNoLFS("-c", "fetch.negotiationAlgorithm=noop",
+		"fetch", "--no-tags", "--no-write-fetch-head",
+		"--recurse-submodules=no", "--filter=blob:none", "--stdin", remote)
+	cmd.Stdin = strings.NewReader(strings.Join(oids, "\n") + "\n")
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to fetch %d missing object(s) from %q: %v %s", len(oids), remote, err, stderr)
+	}
+	return nil
+}
+
 func LsRemote(remote, remoteRef string) (string, error) {
 	if remote == "" {
 		return "", errors.New("remote required")
@@ -244,6 +279,45 @@ func LsTree(ref string) (*subprocess.BufferedCmd, error) {
 	)
 }
 
+// LsFilesStage runs `git ls-files --stage` to list every entry in the index of
+// the working tree "workTree", or of the current one if it is empty, with paths
+// relative to the root of the repository.
+func LsFilesStage(workTree string) (*subprocess.BufferedCmd, error) {
+	var args []string
+	if len(workTree) > 0 {
+		args = append(args, "-C", workTree)
+	}
+	return gitNoLFSBuffered(append(args,
+		"ls-files",
+		"--stage",     // report the mode, blob and stage of each entry
+		"-z",          // null line termination
+		"--full-name", // report paths relative to the root
+		"--",
+		":/", // list the whole index regardless of where we are in it
+	)...)
+}
+
+// LastBlobAtPath returns the OID of the blob most recently committed at
+// "path", relative to the root of the repository, in the history of "ref",
+// even if the file has since been renamed or deleted.
+func LastBlobAtPath(ref, path string) (string, error) {
+	if oid, err := gitNoLFSSimple("rev-parse", "--verify", "--quiet", ref+":"+path); err == nil {
+		return oid, nil
+	}
+
+	commit, err := gitNoLFSSimple("log", "-1", "--format=%H", ref, "--", path)
+	if err != nil {
+		return "", err
+	}
+	if len(commit) == 0 {
+		return "", fmt.Errorf("no file at %q in the history of %s", path, ref)
+	}
+
+	// The last commit to change the path removed the file, so its parent
+	// has the file as it was last committed.
+	return gitNoLFSSimple("rev-parse", "--verify", "--quiet", commit+"^:"+path)
+}
+
 func ResolveRef(ref string) (*Ref, error) {
 	outp, err := gitNoLFSSimple("rev-parse", ref, "--symbolic-full-name", ref)
 	if err != nil {
@@ -792,6 +866,20 @@ func GitCommonDir() (string, error) {
 	return canonicalizeDir(path)
 }
 
+// ShallowCommits returns the commits at which the history of the repository
+// whose common Git directory is "gitDir" was cut off by a shallow clone or
+// fetch, as listed in its "shallow" file. It returns no commits if the
+// repository is not shallow.
+func ShallowCommits(gitDir string) ([]string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(gitDir, "shallow"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
+
 // GetAllWorkTreeHEADs returns the refs that all worktrees are using as HEADs
 // This returns all worktrees plus the master working copy, and works even if
 // working dir is actually in a worktree right now
@@ -842,6 +930,37 @@ func GetAllWorkTreeHEADs(storageDir string) ([]*Ref, error) {
 	return worktrees, nil
 }
 
+// GetAllWorkTreeDirs returns the directories of the main working tree, unless
+// the repository is bare, and of every linked working tree which still exists.
+func GetAllWorkTreeDirs() ([]string, error) {
+	out, err := gitNoLFSSimple("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list working trees: %v", err)
+	}
+
+	var dirs []string
+	for _, block := range strings.Split(out, "\n\n") {
+		var dir string
+		bare := false
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "worktree ") {
+				dir = strings.TrimPrefix(line, "worktree ")
+			} else if line == "bare" {
+				bare = true
+			}
+		}
+		if len(dir) == 0 || bare {
+			continue
+		}
+		if _, err := os.Stat(dir); err != nil {
+			tracerx.Printf("Error reading working tree %v, skipping: %v", dir, err)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
 // Manually parse a reference file like HEAD and return the Ref it resolves to
 func parseRefFile(filename string) (*Ref, error) {
 	bytes, err := ioutil.ReadFile(filename)
@@ -871,6 +990,13 @@ func IsBare() (bool, error) {
 	return strconv.ParseBool(s)
 }
 
+// Editor returns the editor that Git would use to edit a commit message, as
+// reported by "git var GIT_EDITOR". It honors GIT_EDITOR, core.editor, VISUAL,
+// and EDITOR, in that order.
+func Editor() (string, error) {
+	return gitNoLFSSimple("var", "GIT_EDITOR")
+}
+
 // For compatibility with git clone we must mirror all flags in CloneWithoutFilters
 type CloneFlags struct {
 	// --template <template_directory>
@@ -789,3 +789,21 @@ func TestMapRemoteURL(t *testing.T) {
 		assert.Equal(t, val, test.val)
 	}
 }
+
+func TestShallowCommits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shallow")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	commits, err := ShallowCommits(dir)
+	assert.Nil(t, err)
+	assert.Empty(t, commits)
+
+	a := "1111111111111111111111111111111111111111"
+	b := "2222222222222222222222222222222222222222"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "shallow"), []byte(a+"\n"+b+"\n"), 0644))
+
+	commits, err = ShallowCommits(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{a, b}, commits)
+}
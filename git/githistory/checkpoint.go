@@ -0,0 +1,156 @@
+package githistory
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+)
+
+const (
+	// checkpointHeader is the prefix of the first line of a checkpoint
+	// file, which is followed by the key of the rewrite that wrote it.
+	checkpointHeader = "git-lfs-migrate-checkpoint"
+)
+
+var (
+	// checkpointInterval is the number of commits rewritten between each
+	// time the checkpoint is flushed to disk.
+	checkpointInterval = 1000
+)
+
+// checkpoint records the mapping of original commits to rewritten ones as a
+// rewrite progresses, so that an interrupted rewrite may be resumed.
+//
+// The file consists of a header line identifying the arguments of the rewrite,
+// followed by one line per rewritten commit of the form "<old> <new>".
+type checkpoint struct {
+	path string
+
+	f *os.File
+	w *bufio.Writer
+	n int
+}
+
+// loadCheckpoint reads the commits recorded in the checkpoint at "path" into a
+// map from hex-encoded original commit SHAs to rewritten ones.
+//
+// If there is no checkpoint, or it was written by a rewrite with a key other
+// than "key", it is discarded and an empty map is returned.
+func loadCheckpoint(path, key string) (map[string][]byte, error) {
+	commits := make(map[string][]byte)
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return commits, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != fmt.Sprintf("%s %s", checkpointHeader, key) {
+		// This checkpoint belongs to a rewrite with different
+		// arguments, so it cannot be resumed.
+		f.Close()
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return commits, nil
+	}
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			// The last line may be incomplete if the previous
+			// rewrite was interrupted while writing it.
+			continue
+		}
+
+		from, err := hex.DecodeString(fields[0])
+		if err != nil {
+			continue
+		}
+		to, err := hex.DecodeString(fields[1])
+		if err != nil || len(to) != len(from) {
+			continue
+		}
+
+		commits[hex.EncodeToString(from)] = to
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// newCheckpoint opens the checkpoint at "path" for writing. If "resume" is
+// true, new commits are appended to those already recorded. Otherwise, any
+// existing checkpoint is replaced by a new one identified by "key".
+func newCheckpoint(path, key string, resume bool) (*checkpoint, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if resume {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open checkpoint")
+	}
+
+	c := &checkpoint{path: path, f: f, w: bufio.NewWriter(f)}
+	if !resume {
+		if _, err := fmt.Fprintf(c.w, "%s %s\n", checkpointHeader, key); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Record records that the commit "from" was rewritten as "to", periodically
+// flushing the recorded commits to disk.
+func (c *checkpoint) Record(from, to []byte) error {
+	if _, err := fmt.Fprintf(c.w, "%x %x\n", from, to); err != nil {
+		return err
+	}
+
+	c.n++
+	if c.n%checkpointInterval == 0 {
+		return c.flush()
+	}
+	return nil
+}
+
+func (c *checkpoint) flush() error {
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	return c.f.Sync()
+}
+
+// Close flushes any recorded commits and closes the checkpoint.
+func (c *checkpoint) Close() error {
+	if err := c.flush(); err != nil {
+		c.f.Close()
+		return err
+	}
+	return c.f.Close()
+}
+
+// Remove closes and deletes the checkpoint once the rewrite has completed.
+func (c *checkpoint) Remove() error {
+	if err := c.f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(c.path)
+}
@@ -0,0 +1,83 @@
+package githistory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadCheckpointMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	commits, err := loadCheckpoint(filepath.Join(dir, "checkpoint"), "key")
+
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+}
+
+func TestCheckpointRecordsCommits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "migrate", "checkpoint")
+
+	cp, err := newCheckpoint(path, "key", false)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.Record(HexDecode(t, "aa"), HexDecode(t, "bb")))
+	assert.NoError(t, cp.Close())
+
+	cp, err = newCheckpoint(path, "key", true)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.Record(HexDecode(t, "cc"), HexDecode(t, "dd")))
+	assert.NoError(t, cp.Close())
+
+	commits, err := loadCheckpoint(path, "key")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"aa": HexDecode(t, "bb"),
+		"cc": HexDecode(t, "dd"),
+	}, commits)
+}
+
+func TestLoadCheckpointDiscardsMismatchedKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint")
+
+	cp, err := newCheckpoint(path, "key", false)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.Record(HexDecode(t, "aa"), HexDecode(t, "bb")))
+	assert.NoError(t, cp.Close())
+
+	commits, err := loadCheckpoint(path, "other")
+
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadCheckpointSkipsIncompleteLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint")
+	contents := checkpointHeader + " key\naa bb\ncc d"
+	assert.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+	commits, err := loadCheckpoint(path, "key")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"aa": HexDecode(t, "bb")}, commits)
+}
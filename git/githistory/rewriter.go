@@ -1,6 +1,7 @@
 package githistory
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -75,6 +76,30 @@ type RewriteOptions struct {
 	// been reassembled by calling the above BlobFn on all existing tree
 	// entries.
 	TreeCallbackFn TreeCallbackFn
+
+	// Squash specifies whether the range of commits should be collapsed
+	// into a single commit containing the rewritten tree of the tip of
+	// that range. The new commit's parents are those of the first commit
+	// in the range, or none if the range begins at a root commit.
+	Squash bool
+	// SquashMessage is the message used for the commit created with
+	// Squash. If empty, the message of the original tip is used.
+	SquashMessage string
+
+	// CheckpointFilePath is the path to a file in which the rewritten
+	// commits are periodically recorded, so that an interrupted rewrite
+	// may be resumed by running it again. It is removed once the rewrite
+	// completes.
+	CheckpointFilePath string
+	// CheckpointKey identifies the arguments of the rewrite. A checkpoint
+	// written by a rewrite with a different key is discarded instead of
+	// being resumed.
+	CheckpointKey string
+	// ResumeFn, if given, is called with the last original commit
+	// recorded in the checkpoint and the commit it was rewritten to when
+	// an interrupted rewrite is resumed, before any further commits are
+	// rewritten.
+	ResumeFn func(original, rewritten []byte) error
 }
 
 // blobFn returns a useable BlobRewriteFn, either the one that was given in the
@@ -210,11 +235,21 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 		return nil, err
 	}
 
+	// Load the commits rewritten by a previous, interrupted run with the
+	// same arguments, if there was one.
+	resumed := make(map[string][]byte)
+	if len(opt.CheckpointFilePath) > 0 && !opt.Squash {
+		resumed, err = loadCheckpoint(opt.CheckpointFilePath, opt.CheckpointKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load checkpoint")
+		}
+	}
+
 	var perc *tasklog.PercentageTask
 	if opt.UpdateRefs {
-		perc = r.l.Percentage("migrate: Rewriting commits", uint64(len(commits)))
+		perc = r.l.PercentageRate("migrate: Rewriting commits", "commits", uint64(len(commits)))
 	} else {
-		perc = r.l.Percentage("migrate: Examining commits", uint64(len(commits)))
+		perc = r.l.PercentageRate("migrate: Examining commits", "commits", uint64(len(commits)))
 	}
 
 	var vPerc *tasklog.PercentageTask
@@ -224,17 +259,87 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 
 	var objectMapFile *os.File
 	if len(opt.ObjectMapFilePath) > 0 {
-		objectMapFile, err = os.OpenFile(opt.ObjectMapFilePath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		flags := os.O_RDWR | os.O_CREATE | os.O_EXCL
+		if len(resumed) > 0 {
+			// Replace the object map left by the interrupted run,
+			// since it may contain commits rewritten after the
+			// checkpoint was last written.
+			flags = os.O_RDWR | os.O_CREATE | os.O_TRUNC
+		}
+
+		objectMapFile, err = os.OpenFile(opt.ObjectMapFilePath, flags, 0666)
 		if err != nil {
 			return nil, fmt.Errorf("could not create object map file: %v", err)
 		}
 		defer objectMapFile.Close()
 	}
 
+	if opt.Squash {
+		tip, err := r.squash(commits, opt, vPerc, objectMapFile)
+		if err != nil {
+			return nil, err
+		}
+		perc.Count(uint64(len(commits)))
+
+		if opt.UpdateRefs {
+			if err := r.updateRefs(); err != nil {
+				return nil, err
+			}
+		}
+		return tip, nil
+	}
+
+	var cp *checkpoint
+	if len(opt.CheckpointFilePath) > 0 {
+		cp, err = newCheckpoint(opt.CheckpointFilePath, opt.CheckpointKey, len(resumed) > 0)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if cp != nil {
+				cp.Close()
+			}
+		}()
+	}
+
+	var lastResumed []byte
+	for _, oid := range commits {
+		if _, ok := resumed[hex.EncodeToString(oid)]; ok {
+			lastResumed = oid
+		}
+	}
+	if lastResumed != nil && opt.ResumeFn != nil {
+		if err := opt.ResumeFn(lastResumed, resumed[hex.EncodeToString(lastResumed)]); err != nil {
+			return nil, err
+		}
+	}
+
 	// Keep track of the last commit that we rewrote. Callers often want
 	// this so that they can perform a git-update-ref(1).
 	var tip []byte
+	// Keep track of how many commits were modified, as opposed to passed
+	// through unchanged, in order to summarize the rewrite.
+	var modified int
 	for _, oid := range commits {
+		if newSha, ok := resumed[hex.EncodeToString(oid)]; ok {
+			// This commit was rewritten before the previous run
+			// was interrupted.
+			if !bytes.Equal(oid, newSha) {
+				modified++
+
+				if objectMapFile != nil {
+					if _, err := fmt.Fprintf(objectMapFile, "%x,%x\n", oid, newSha); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			r.cacheCommit(oid, newSha)
+			perc.Count(1)
+			tip = newSha
+			continue
+		}
+
 		// Load the original commit to access the data necessary in
 		// order to rewrite it.
 		original, err := r.db.Commit(oid)
@@ -296,6 +401,8 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 			if err != nil {
 				return nil, err
 			}
+			modified++
+
 			if objectMapFile != nil {
 				if _, err := fmt.Fprintf(objectMapFile, "%x,%x\n", oid, newSha); err != nil {
 					return nil, err
@@ -307,6 +414,12 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 		// commit.
 		r.cacheCommit(oid, newSha)
 
+		if cp != nil {
+			if err := cp.Record(oid, newSha); err != nil {
+				return nil, errors.Wrap(err, "could not write checkpoint")
+			}
+		}
+
 		// Increment the percentage displayed in the terminal.
 		perc.Count(1)
 
@@ -315,30 +428,115 @@ func (r *Rewriter) Rewrite(opt *RewriteOptions) ([]byte, error) {
 	}
 
 	if opt.UpdateRefs {
-		refs, err := r.refsToMigrate()
-		if err != nil {
-			return nil, errors.Wrap(err, "could not find refs to update")
+		if err := r.updateRefs(); err != nil {
+			return nil, err
 		}
 
-		root, _ := r.db.Root()
-
-		updater := &refUpdater{
-			CacheFn: r.uncacheCommit,
-			Logger:  r.l,
-			Refs:    refs,
-			Root:    root,
-
-			db: r.db,
+		if r.l != nil {
+			summary := tasklog.NewSimpleTask()
+			r.l.Enqueue(summary)
+			summary.Logf("migrate: %d commit(s) modified, %d passed through unchanged",
+				modified, len(commits)-modified)
+			summary.Complete()
 		}
+	}
 
-		if err := updater.UpdateRefs(); err != nil {
-			return nil, errors.Wrap(err, "could not update refs")
+	if cp != nil {
+		// The rewrite is complete, so there is nothing left to
+		// resume.
+		if err := cp.Remove(); err != nil {
+			return nil, errors.Wrap(err, "could not remove checkpoint")
 		}
+		cp = nil
 	}
 
 	return tip, err
 }
 
+// squash rewrites the tree of the last commit in "commits" and writes a single
+// commit containing it, whose parents are those of the first commit in
+// "commits". Only the original tip is mapped to the new commit, so that only
+// the reference being migrated is moved onto it.
+//
+// It returns the new commit's SHA, or an error if it could not be written.
+func (r *Rewriter) squash(commits [][]byte, opt *RewriteOptions, perc *tasklog.PercentageTask, objectMapFile *os.File) ([]byte, error) {
+	if len(opt.Include) != 1 {
+		return nil, errors.New("can only squash a single reference")
+	}
+	if len(commits) == 0 {
+		return nil, nil
+	}
+
+	first, err := r.db.Commit(commits[0])
+	if err != nil {
+		return nil, err
+	}
+
+	oid := commits[len(commits)-1]
+	original, err := r.db.Commit(oid)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrittenTree, err := r.rewriteTree(oid, original.TreeID, "", opt.blobFn(), opt.treePreFn(), opt.treeFn(), perc)
+	if err != nil {
+		return nil, err
+	}
+
+	message := opt.SquashMessage
+	if len(message) == 0 {
+		message = original.Message
+	}
+
+	newSha, err := r.db.WriteCommit(&gitobj.Commit{
+		Author:       original.Author,
+		Committer:    original.Committer,
+		ExtraHeaders: original.ExtraHeaders,
+		Message:      message,
+
+		ParentIDs: first.ParentIDs,
+		TreeID:    rewrittenTree,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if objectMapFile != nil {
+		if _, err := fmt.Fprintf(objectMapFile, "%x,%x\n", oid, newSha); err != nil {
+			return nil, err
+		}
+	}
+
+	r.cacheCommit(oid, newSha)
+
+	return newSha, nil
+}
+
+// updateRefs moves the references being migrated onto their rewritten
+// counterparts, as recorded in the commit cache.
+func (r *Rewriter) updateRefs() error {
+	refs, err := r.refsToMigrate()
+	if err != nil {
+		return errors.Wrap(err, "could not find refs to update")
+	}
+
+	root, _ := r.db.Root()
+
+	updater := &refUpdater{
+		CacheFn: r.uncacheCommit,
+		Logger:  r.l,
+		Refs:    refs,
+		Root:    root,
+
+		db: r.db,
+	}
+
+	if err := updater.UpdateRefs(); err != nil {
+		return errors.Wrap(err, "could not update refs")
+	}
+	return nil
+}
+
 // rewriteTree is a recursive function which rewrites a tree given by the ID
 // "sha" and path "path". It uses the given BlobRewriteFn to rewrite all blobs
 // within the tree, either calling that function or recurring down into subtrees
@@ -417,9 +615,16 @@ func (r *Rewriter) rewriteTree(commitOID []byte, treeOID []byte, path string,
 	}
 
 	if tree.Equal(rewritten) {
+		// Reuse the original tree rather than writing an identical
+		// copy of it.
 		return treeOID, nil
 	}
-	return r.db.WriteTree(rewritten)
+
+	sha, err := r.db.WriteTree(rewritten)
+	if err != nil {
+		return nil, err
+	}
+	return sha, nil
 }
 
 func copyEntry(e *gitobj.TreeEntry) *gitobj.TreeEntry {
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
@@ -463,6 +465,191 @@ func TestHistoryRewriterUpdatesRefs(t *testing.T) {
 	AssertCommitParent(t, db, c2, c3)
 }
 
+func TestHistoryRewriterSquashesCommits(t *testing.T) {
+	db := DatabaseFromFixture(t, "linear-history.git")
+	r := NewRewriter(db)
+
+	tip, err := r.Rewrite(&RewriteOptions{
+		Include: []string{"refs/heads/master"},
+
+		UpdateRefs:    true,
+		Squash:        true,
+		SquashMessage: "squashed\n",
+	})
+
+	assert.Nil(t, err)
+
+	// The squashed commit should have the tree of the original tip, no
+	// parents (the range began at the root commit), and the given message.
+	commit, err := db.Commit(tip)
+	assert.Nil(t, err)
+
+	original, err := db.Commit(HexDecode(t, "e669b63f829bfb0b91fc52a5bcea53dd7977a0ee"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, original.TreeID, commit.TreeID)
+	assert.Empty(t, commit.ParentIDs)
+	assert.Equal(t, "squashed\n", commit.Message)
+
+	AssertRef(t, db, "refs/heads/master", tip)
+}
+
+func TestHistoryRewriterSquashUsesParentOfRange(t *testing.T) {
+	db := DatabaseFromFixture(t, "linear-history-with-tags.git")
+	r := NewRewriter(db)
+
+	tip, err := r.Rewrite(&RewriteOptions{
+		Include: []string{"refs/heads/master"},
+		Exclude: []string{"refs/tags/middle"},
+
+		Squash: true,
+	})
+
+	// Only HEAD is in range, so the squashed commit should be grafted
+	// onto refs/tags/middle.
+	expectedParent := "228afe30855933151f7a88e70d9d88314fd2f191"
+
+	assert.NoError(t, err)
+	AssertCommitParent(t, db, hex.EncodeToString(tip), expectedParent)
+}
+
+func TestHistoryRewriterResumesFromCheckpoint(t *testing.T) {
+	db := DatabaseFromFixture(t, "linear-history.git")
+	r := NewRewriter(db)
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Pretend that a previous rewrite was interrupted after rewriting the
+	// first two commits, leaving them as they were.
+	path := filepath.Join(dir, "checkpoint")
+	cp, err := newCheckpoint(path, "key", false)
+	assert.NoError(t, err)
+	for _, sha := range []string{
+		"62811b8f930323895033b3b338c35f51c0b7268b",
+		"efeab7a9b61312fa56fc74eee1e0f5a714abfb70",
+	} {
+		assert.NoError(t, cp.Record(HexDecode(t, sha), HexDecode(t, sha)))
+	}
+	assert.NoError(t, cp.Close())
+
+	var seen []string
+	var resumed string
+
+	tip, err := r.Rewrite(&RewriteOptions{
+		Include: []string{"refs/heads/master"},
+
+		UpdateRefs: true,
+
+		CheckpointFilePath: path,
+		CheckpointKey:      "key",
+		ResumeFn: func(original, rewritten []byte) error {
+			resumed = hex.EncodeToString(original)
+			return nil
+		},
+
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			contents, err := ioutil.ReadAll(b.Contents)
+			if err != nil {
+				return nil, err
+			}
+			seen = append(seen, string(contents))
+
+			return &gitobj.Blob{
+				Contents: bytes.NewReader(contents),
+				Size:     int64(len(contents)),
+			}, nil
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3"}, seen)
+	assert.Equal(t, "efeab7a9b61312fa56fc74eee1e0f5a714abfb70", resumed)
+
+	AssertRef(t, db, "refs/heads/master", tip)
+	AssertCommitParent(t, db, hex.EncodeToString(tip), "efeab7a9b61312fa56fc74eee1e0f5a714abfb70")
+
+	// The checkpoint is removed once the rewrite completes.
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestHistoryRewriterIgnoresCheckpointWithDifferentKey(t *testing.T) {
+	db := DatabaseFromFixture(t, "linear-history.git")
+	r := NewRewriter(db)
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "checkpoint")
+	cp, err := newCheckpoint(path, "key", false)
+	assert.NoError(t, err)
+	assert.NoError(t, cp.Record(
+		HexDecode(t, "62811b8f930323895033b3b338c35f51c0b7268b"),
+		HexDecode(t, "62811b8f930323895033b3b338c35f51c0b7268b")))
+	assert.NoError(t, cp.Close())
+
+	var calls int
+
+	_, err = r.Rewrite(&RewriteOptions{
+		Include: []string{"refs/heads/master"},
+
+		UpdateRefs: true,
+
+		CheckpointFilePath: path,
+		CheckpointKey:      "other",
+
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			calls++
+			return b, nil
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestHistoryRewriterReusesRewrittenTrees(t *testing.T) {
+	db := DatabaseFromFixture(t, "repeated-subtrees.git")
+	r := NewRewriter(db)
+
+	var roots, subtrees int
+	opts := &RewriteOptions{
+		Include: []string{"refs/heads/master"},
+		BlobFn: func(path string, b *gitobj.Blob) (*gitobj.Blob, error) {
+			return b, nil
+		},
+		TreeCallbackFn: func(path string, tr *gitobj.Tree) (*gitobj.Tree, error) {
+			if path == "/" {
+				roots++
+			} else {
+				subtrees++
+			}
+			return tr, nil
+		},
+	}
+
+	tip, err := r.Rewrite(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, roots)
+	visited := subtrees
+	assert.NotZero(t, visited)
+
+	// Each subtree entry has already been rewritten, so rewriting the
+	// same commits again does not visit them a second time, and the
+	// original trees are reused since they were unchanged. Root trees are
+	// always visited, as the callbacks may change them.
+	again, err := r.Rewrite(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, roots)
+	assert.Equal(t, visited, subtrees)
+
+	assert.Equal(t, "b9621d5d84b3174de020ad2c869f43b2f61f337f", hex.EncodeToString(tip))
+	assert.Equal(t, tip, again)
+}
+
 func TestHistoryRewriterReturnsFilter(t *testing.T) {
 	f := filepathfilter.New([]string{"a"}, []string{"b"})
 	r := NewRewriter(nil, WithFilter(f))
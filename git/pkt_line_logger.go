@@ -0,0 +1,142 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// pktlineLogMaxContent is the number of bytes of each packet's content
+	// which a PktlineLogger writes; the rest is elided.
+	pktlineLogMaxContent = 256
+)
+
+// PktlineLogger writes a line describing each packet of one or more pktline
+// data streams as they pass through it, for debugging the filter protocol. It
+// does not change the data in any way.
+type PktlineLogger struct {
+	out io.Writer
+	mu  sync.Mutex
+
+	// now returns the time at which a packet was seen.
+	now func() time.Time
+}
+
+// NewPktlineLogger returns a new *PktlineLogger which writes to "out".
+func NewPktlineLogger(out io.Writer) *PktlineLogger {
+	return &PktlineLogger{out: out, now: time.Now}
+}
+
+// Reader returns an io.Reader which reads from "r", logging each packet read
+// as inbound.
+func (l *PktlineLogger) Reader(r io.Reader) io.Reader {
+	return io.TeeReader(r, &pktlineFrameLogger{l: l, direction: "in "})
+}
+
+// Writer returns an io.Writer which writes to "w", logging each packet written
+// as outbound.
+func (l *PktlineLogger) Writer(w io.Writer) io.Writer {
+	return io.MultiWriter(w, &pktlineFrameLogger{l: l, direction: "out"})
+}
+
+// logf writes one line to the log, prefixed by the time and direction.
+func (l *PktlineLogger) logf(direction, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fmt.Fprintf(l.out, "%s %s %s\n", l.now().Format("2006-01-02T15:04:05.000000Z07:00"),
+		direction, fmt.Sprintf(format, args...))
+}
+
+// pktlineFrameLogger splits the data written to it into packets, which need
+// not be aligned with the writes, and logs each one as it is completed.
+type pktlineFrameLogger struct {
+	l         *PktlineLogger
+	direction string
+
+	// header holds the length header of the current packet, until all
+	// four bytes have been seen.
+	header []byte
+	// length is the length of the current packet, including its header,
+	// and remaining is how many bytes of its content have yet to be
+	// seen.
+	length    int
+	remaining int
+	// content holds up to pktlineLogMaxContent bytes of the content of
+	// the current packet.
+	content []byte
+}
+
+func (f *pktlineFrameLogger) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if f.remaining == 0 {
+			k := 4 - len(f.header)
+			if k > len(p) {
+				k = len(p)
+			}
+			f.header = append(f.header, p[:k]...)
+			p = p[k:]
+			if len(f.header) == 4 {
+				f.startPacket()
+			}
+			continue
+		}
+
+		k := f.remaining
+		if k > len(p) {
+			k = len(p)
+		}
+		if room := pktlineLogMaxContent - len(f.content); room > 0 {
+			if room > k {
+				room = k
+			}
+			f.content = append(f.content, p[:room]...)
+		}
+		f.remaining -= k
+		p = p[k:]
+		if f.remaining == 0 {
+			f.logPacket()
+		}
+	}
+	return n, nil
+}
+
+// startPacket parses the header of a new packet, and logs it at once if it
+// has no content.
+func (f *pktlineFrameLogger) startPacket() {
+	header := string(f.header)
+	f.header = f.header[:0]
+
+	length, err := strconv.ParseUint(header, 16, 16)
+	switch {
+	case err != nil:
+		f.l.logf(f.direction, "invalid packet header %q", header)
+	case length == 0:
+		f.l.logf(f.direction, "%s (flush)", header)
+	case length == 1:
+		f.l.logf(f.direction, "%s (delim)", header)
+	case length == 2:
+		f.l.logf(f.direction, "%s (response end)", header)
+	case length < 4:
+		f.l.logf(f.direction, "%s (invalid length)", header)
+	default:
+		f.length = int(length)
+		f.remaining = f.length - 4
+		f.content = f.content[:0]
+		if f.remaining == 0 {
+			f.logPacket()
+		}
+	}
+}
+
+func (f *pktlineFrameLogger) logPacket() {
+	if size := f.length - 4; size > len(f.content) {
+		f.l.logf(f.direction, "%04x %q... (%d bytes)", f.length, f.content, size)
+	} else {
+		f.l.logf(f.direction, "%04x %q", f.length, f.content)
+	}
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPktlineLogger(out *bytes.Buffer) *PktlineLogger {
+	l := NewPktlineLogger(out)
+	l.now = func() time.Time {
+		return time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	}
+	return l
+}
+
+func TestPktlineLoggerLogsPacketsSplitAcrossWrites(t *testing.T) {
+	var log, sent bytes.Buffer
+	w := newTestPktlineLogger(&log).Writer(&sent)
+
+	data := "0016git-filter-client\n000dversion=2" + "0000" + "0004" + "0001"
+	for _, chunk := range []string{data[:2], data[2:10], data[10:]} {
+		n, err := w.Write([]byte(chunk))
+		require.Nil(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, data, sent.String())
+	assert.Equal(t, strings.Join([]string{
+		`2020-01-02T03:04:05.000006Z out 0016 "git-filter-client\n"`,
+		`2020-01-02T03:04:05.000006Z out 000d "version=2"`,
+		`2020-01-02T03:04:05.000006Z out 0000 (flush)`,
+		`2020-01-02T03:04:05.000006Z out 0004 ""`,
+		`2020-01-02T03:04:05.000006Z out 0001 (delim)`,
+	}, "\n")+"\n", log.String())
+}
+
+func TestPktlineLoggerTruncatesLargePackets(t *testing.T) {
+	var log bytes.Buffer
+	content := strings.Repeat("a", 1000)
+	r := newTestPktlineLogger(&log).Reader(strings.NewReader("03ec" + content))
+
+	data, err := ioutil.ReadAll(r)
+	require.Nil(t, err)
+	assert.Equal(t, "03ec"+content, string(data))
+	assert.Equal(t, `2020-01-02T03:04:05.000006Z in  03ec "`+content[:256]+`"... (1000 bytes)`+"\n", log.String())
+}
+
+func TestPktlineLoggerLogsInvalidHeaders(t *testing.T) {
+	var log bytes.Buffer
+	w := newTestPktlineLogger(&log).Writer(ioutil.Discard)
+
+	w.Write([]byte("zzzz00020003"))
+	assert.Equal(t, `2020-01-02T03:04:05.000006Z out invalid packet header "zzzz"`+"\n"+
+		`2020-01-02T03:04:05.000006Z out 0002 (response end)`+"\n"+
+		`2020-01-02T03:04:05.000006Z out 0003 (invalid length)`+"\n", log.String())
+}
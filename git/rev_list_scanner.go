@@ -2,6 +2,7 @@ package git
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -361,3 +362,39 @@ func (s *RevListScanner) scan() ([]byte, string, error) {
 
 	return sha1, name, nil
 }
+
+// MissingObjects returns the objects which a RevListScanner with the same
+// arguments would list, but which are missing from a partial clone, without
+// fetching any of them.
+func MissingObjects(include, excluded []string, opt *ScanRefsOptions) ([]string, error) {
+	listOpt := *opt
+	listOpt.CommitsOnly = false
+
+	stdin, args, err := revListArgs(include, excluded, &listOpt)
+	if err != nil {
+		return nil, err
+	}
+	args = append([]string{args[0], "--missing=print"}, args[1:]...)
+
+	cmd := gitNoLFS(args...)
+	if len(opt.WorkingDir) > 0 {
+		cmd.Dir = opt.WorkingDir
+	}
+	cmd.Stdin = stdin
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	tracerx.Printf("run_command: git %s", strings.Join(args, " "))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Errorf("Error in git %s: %v %s", strings.Join(args, " "), err, stderr)
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "?") {
+			missing = append(missing, strings.TrimSpace(line[1:]))
+		}
+	}
+	return missing, nil
+}
@@ -2,6 +2,8 @@ package lfs
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
 	"github.com/git-lfs/git-lfs/git"
@@ -34,19 +36,116 @@ type FilterOptions struct {
 	Worktree   bool
 	System     bool
 	SkipSmudge bool
+
+	// BinaryPath, if set, is recorded as lfs.binarypath by Install, for
+	// the hooks to fall back on if they cannot otherwise find git-lfs.
+	BinaryPath string
 }
 
 func (o *FilterOptions) Install() error {
+	attr := filterAttribute()
 	if o.SkipSmudge {
-		return skipSmudgeFilterAttribute().Install(o)
+		attr = skipSmudgeFilterAttribute()
+	}
+	if err := attr.Install(o); err != nil {
+		return err
+	}
+
+	if len(o.BinaryPath) > 0 {
+		return o.setKey(binaryPathKey, o.BinaryPath)
 	}
-	return filterAttribute().Install(o)
+	return nil
 }
 
-func (o *FilterOptions) Uninstall() error {
-	return filterAttribute().Uninstall(o)
+// Uninstall removes the "lfs" filter, and lfs.binarypath, from the chosen
+// scope, and returns the keys of the filter which were set there.
+func (o *FilterOptions) Uninstall() ([]string, error) {
+	removed, err := filterAttribute().Uninstall(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(o.find(binaryPathKey)) > 0 {
+		if err := o.unsetKey(binaryPathKey); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// Installed returns whether or not the effective Git config sets up the "lfs"
+// filter as Install does, either with or without SkipSmudge, regardless of the
+// scope chosen by these options.
+func (o *FilterOptions) Installed() bool {
+	return filterAttribute().installed(o.GitConfig) ||
+		skipSmudgeFilterAttribute().installed(o.GitConfig)
+}
+
+// Scope returns the name of the Git configuration scope chosen by these
+// options.
+func (o *FilterOptions) Scope() string {
+	switch {
+	case o.Local:
+		return "local"
+	case o.Worktree:
+		return "worktree"
+	case o.System:
+		return "system"
+	default:
+		return "global"
+	}
 }
 
+// find returns the value of "key" in the chosen scope.
+func (o *FilterOptions) find(key string) string {
+	switch {
+	case o.Local:
+		return o.GitConfig.FindLocal(key)
+	case o.Worktree:
+		return o.GitConfig.FindWorktree(key)
+	case o.System:
+		return o.GitConfig.FindSystem(key)
+	default:
+		return o.GitConfig.FindGlobal(key)
+	}
+}
+
+// setKey sets "key" to "value" in the chosen scope.
+func (o *FilterOptions) setKey(key, value string) error {
+	var err error
+	switch {
+	case o.Local:
+		_, err = o.GitConfig.SetLocal(key, value)
+	case o.Worktree:
+		_, err = o.GitConfig.SetWorktree(key, value)
+	case o.System:
+		_, err = o.GitConfig.SetSystem(key, value)
+	default:
+		_, err = o.GitConfig.SetGlobal(key, value)
+	}
+	return err
+}
+
+// unsetKey removes "key" from the chosen scope.
+func (o *FilterOptions) unsetKey(key string) error {
+	var err error
+	switch {
+	case o.Local:
+		_, err = o.GitConfig.UnsetLocalKey(key)
+	case o.Worktree:
+		_, err = o.GitConfig.UnsetWorktreeKey(key)
+	case o.System:
+		_, err = o.GitConfig.UnsetSystemKey(key)
+	default:
+		_, err = o.GitConfig.UnsetGlobalKey(key)
+	}
+	return err
+}
+
+// binaryPathKey is the Git config key in which the path of the git-lfs
+// program is recorded at install time, for the hooks.
+const binaryPathKey = "lfs.binarypath"
+
 func filterAttribute() *Attribute {
 	return &Attribute{
 		Section: "filter.lfs",
@@ -108,7 +207,14 @@ func skipSmudgeFilterAttribute() *Attribute {
 // different value than what is given, and force is false, an error will be
 // returned immediately, and the rest of the attributes will not be set.
 func (a *Attribute) Install(opt *FilterOptions) error {
-	for k, v := range a.Properties {
+	keys := make([]string, 0, len(a.Properties))
+	for k := range a.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := a.Properties[k]
 		var upgradeables []string
 		if a.Upgradeables != nil {
 			// use pre-normalised key since caller will have set up the same
@@ -123,6 +229,17 @@ func (a *Attribute) Install(opt *FilterOptions) error {
 	return nil
 }
 
+// installed returns whether or not every property of this Attribute has the
+// desired value in the effective Git config.
+func (a *Attribute) installed(gitConfig *git.Configuration) bool {
+	for k, v := range a.Properties {
+		if gitConfig.Find(a.normalizeKey(k)) != v {
+			return false
+		}
+	}
+	return true
+}
+
 // normalizeKey makes an absolute path out of a partial relative one. For a
 // relative path of "foo", and a root Section of "bar", "bar.foo" will be returned.
 func (a *Attribute) normalizeKey(relative string) string {
@@ -134,29 +251,13 @@ func (a *Attribute) normalizeKey(relative string) string {
 // an error will be thrown if force is set to false. If force is true, the value
 // will be overridden.
 func (a *Attribute) set(gitConfig *git.Configuration, key, value string, upgradeables []string, opt *FilterOptions) error {
-	var currentValue string
-	if opt.Local {
-		currentValue = gitConfig.FindLocal(key)
-	} else if opt.Worktree {
-		currentValue = gitConfig.FindWorktree(key)
-	} else if opt.System {
-		currentValue = gitConfig.FindSystem(key)
-	} else {
-		currentValue = gitConfig.FindGlobal(key)
-	}
+	currentValue := opt.find(key)
 
 	if opt.Force || shouldReset(currentValue, upgradeables) {
-		var err error
-		if opt.Local {
-			_, err = gitConfig.SetLocal(key, value)
-		} else if opt.Worktree {
-			_, err = gitConfig.SetWorktree(key, value)
-		} else if opt.System {
-			_, err = gitConfig.SetSystem(key, value)
-		} else {
-			_, err = gitConfig.SetGlobal(key, value)
-		}
-		return err
+		return opt.setKey(key, value)
+	} else if !strings.HasSuffix(key, ".required") && !runsGitLFS(currentValue) {
+		return fmt.Errorf("the %q attribute is set to %q in the %s Git config, which does not run Git LFS; another tool may be using the \"lfs\" filter",
+			key, currentValue, opt.Scope())
 	} else if currentValue != value {
 		return fmt.Errorf("the %q attribute should be %q but is %q",
 			key, value, currentValue)
@@ -165,8 +266,44 @@ func (a *Attribute) set(gitConfig *git.Configuration, key, value string, upgrade
 	return nil
 }
 
-// Uninstall removes all properties in the path of this property.
-func (a *Attribute) Uninstall(opt *FilterOptions) error {
+// runsGitLFS returns whether or not the command given as a filter setting runs
+// Git LFS, either as "git-lfs" or "git lfs", possibly by an absolute path.
+func runsGitLFS(command string) bool {
+	command = strings.TrimSpace(command)
+	if len(command) == 0 {
+		return false
+	}
+
+	// The program may be quoted, if its path contains spaces.
+	var name string
+	var args []string
+	if q := command[0]; q == '"' || q == '\'' {
+		end := strings.IndexByte(command[1:], q)
+		if end < 0 {
+			return false
+		}
+		name, args = command[1:end+1], strings.Fields(command[end+2:])
+	} else {
+		fields := strings.Fields(command)
+		name, args = fields[0], fields[1:]
+	}
+
+	name = strings.TrimSuffix(path.Base(strings.Replace(name, "\\", "/", -1)), ".exe")
+	return name == "git-lfs" || (name == "git" && len(args) > 0 && args[0] == "lfs")
+}
+
+// Uninstall removes all properties in the path of this property, and returns
+// the keys of those which were set, in order.
+func (a *Attribute) Uninstall(opt *FilterOptions) ([]string, error) {
+	var removed []string
+	for k := range a.Properties {
+		key := a.normalizeKey(k)
+		if len(opt.find(key)) > 0 {
+			removed = append(removed, key)
+		}
+	}
+	sort.Strings(removed)
+
 	var err error
 	if opt.Local {
 		_, err = opt.GitConfig.UnsetLocalSection(a.Section)
@@ -177,7 +314,10 @@ func (a *Attribute) Uninstall(opt *FilterOptions) error {
 	} else {
 		_, err = opt.GitConfig.UnsetGlobalSection(a.Section)
 	}
-	return err
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
 }
 
 // shouldReset determines whether or not a value is resettable given its current
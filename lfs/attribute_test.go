@@ -0,0 +1,29 @@
+package lfs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunsGitLFS(t *testing.T) {
+	for _, command := range []string{
+		"git-lfs clean -- %f",
+		"git-lfs smudge --something %f",
+		"git lfs filter-process",
+		"/usr/local/bin/git-lfs filter-process",
+		`"C:\Program Files\Git LFS\git-lfs.exe" clean -- %f`,
+	} {
+		assert.True(t, runsGitLFS(command), command)
+	}
+
+	for _, command := range []string{
+		"",
+		"true",
+		"other-tool clean %f",
+		"git annex smudge %f",
+		"git-lfs-wrapper clean %f",
+	} {
+		assert.False(t, runsGitLFS(command), command)
+	}
+}
@@ -0,0 +1,231 @@
+package lfs
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+	"github.com/rubyist/tracerx"
+)
+
+// CompressExtension is the name of the built-in extension which gzips the
+// objects of files with the lfs-compress attribute. It is recorded in their
+// pointers as any other extension is, but is not configured, and is always the
+// last to be applied on clean, and so the first to be undone on smudge.
+const CompressExtension = "compress"
+
+const (
+	// defaultCompressMinSize is the size of the smallest file which is
+	// compressed, unless the lfs-compress attribute gives another.
+	defaultCompressMinSize = humanize.Megabyte
+	// compressMinRatio is how many times smaller the start of a file must
+	// become when compressed for it to be compressed at all.
+	compressMinRatio = 1.25
+	// compressSampleSize is the length of the start of a file which is
+	// compressed to find out whether it is worth compressing.
+	compressSampleSize = humanize.Megabyte
+)
+
+// shouldCompress returns whether the cleaned contents of "filename", which are
+// "size" bytes long and stored in the file at "path", are to be compressed. It
+// is the case if "filename" has the lfs-compress attribute, it is at least as
+// long as the size given as the attribute's value, if any, and its start
+// compresses well. Since every clone must clean a file to the same pointer,
+// this depends only on the attributes and the file's contents.
+func (f *GitFilter) shouldCompress(filename, path string, size int64) bool {
+	value := f.attributes().compress.Get(filename)
+	if len(value) == 0 || value == "false" {
+		return false
+	}
+
+	minSize := uint64(defaultCompressMinSize)
+	if value != "true" {
+		n, err := humanize.ParseBytes(value)
+		if err != nil {
+			tracerx.Printf("clean: invalid lfs-compress size %q for %s, using %d bytes", value, filename, minSize)
+		} else {
+			minSize = n
+		}
+	}
+
+	if uint64(size) < minSize {
+		tracerx.Printf("clean: not compressing %s: smaller than %d bytes", filename, minSize)
+		return false
+	}
+
+	ratio, err := trialCompress(path)
+	if err != nil {
+		tracerx.Printf("clean: not compressing %s: %v", filename, err)
+		return false
+	}
+	if ratio < compressMinRatio {
+		tracerx.Printf("clean: not compressing %s: compresses by %.2f, less than %.2f", filename, ratio, compressMinRatio)
+		return false
+	}
+	return true
+}
+
+// trialCompress returns how many times smaller the start of the file at "path"
+// becomes when it is compressed.
+func trialCompress(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var compressed countingWriter
+	gz := gzip.NewWriter(&compressed)
+	n, err := io.CopyN(gz, file, compressSampleSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return 0, err
+	}
+	return float64(n) / float64(compressed), nil
+}
+
+// compressObject compresses the cleaned contents of a file, stored in the file
+// at "path", which "ptr" points to. It returns a pointer to the compressed
+// object, which records the compress extension with the oid and size of the
+// contents before they were compressed, and the temporary file which holds it.
+//
+// The compressed object depends on the implementation of compress/flate in the
+// Go release Git LFS was built with, which may differ from one release to the
+// next, so callers should prefer the pointer returned by stagedCompressed.
+func (f *GitFilter) compressObject(path string, ptr *Pointer) (*Pointer, *os.File, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Close()
+
+	tmp, err := TempFile(f.cfg, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tmp.Close()
+
+	oidHash := sha256.New()
+	var counter countingWriter
+	gz := gzip.NewWriter(io.MultiWriter(tmp, oidHash, &counter))
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, nil, errors.Wrap(err, "compress")
+	}
+
+	exts := make([]*PointerExtension, 0, len(ptr.Extensions)+1)
+	exts = append(exts, ptr.Extensions...)
+	ext := NewPointerExtension(CompressExtension, len(ptr.Extensions), ptr.Oid)
+	ext.Size = ptr.Size
+	exts = append(exts, ext)
+	return NewPointer(hex.EncodeToString(oidHash.Sum(nil)), int64(counter), exts), tmp, nil
+}
+
+// stagedCompressed returns the pointer staged in the index for "filename" if it
+// points to a compressed object in the local store whose contents before they
+// were compressed are those "ptr" points to. Since such a pointer records the
+// oid of the contents before compression, reusing it makes a file clean to
+// the same pointer whichever version of Git LFS compressed it first, and so
+// keeps it from appearing modified after Git LFS is upgraded.
+func (f *GitFilter) stagedCompressed(filename string, ptr *Pointer) *Pointer {
+	blob, err := git.IndexBlob(f.repoPath(filename), blobSizeCutoff)
+	if err != nil {
+		return nil
+	}
+	staged, err := DecodePointer(strings.NewReader(blob + "\n"))
+	if err != nil {
+		return nil
+	}
+
+	compress, others := splitCompressExtension(staged)
+	if compress == nil || compress.Oid != ptr.Oid || compress.Size != ptr.Size {
+		return nil
+	}
+	if len(others) != len(ptr.Extensions) {
+		return nil
+	}
+	for i, ext := range others {
+		if ext.Name != ptr.Extensions[i].Name || ext.Oid != ptr.Extensions[i].Oid {
+			return nil
+		}
+	}
+
+	if !f.cfg.LFSObjectExists(staged.Oid, staged.Size) {
+		return nil
+	}
+	tracerx.Printf("clean: reusing staged compressed pointer %s for %s", staged.Oid, filename)
+	return staged
+}
+
+// splitCompressExtension returns the compress extension of "ptr", if it has
+// one, and its other extensions.
+func splitCompressExtension(ptr *Pointer) (*PointerExtension, []*PointerExtension) {
+	var compress *PointerExtension
+	var others []*PointerExtension
+	for _, ext := range ptr.Extensions {
+		if ext.Name == CompressExtension {
+			compress = ext
+		} else {
+			others = append(others, ext)
+		}
+	}
+	return compress, others
+}
+
+// decompress writes the decompressed contents of the object read from
+// "reader" to a temporary file, and checks that they hash to the oid, and are
+// as long as the size, which the compress extension "ext" recorded before they
+// were compressed. Pointers written before the size was recorded have none to
+// check. It returns the temporary file, ready to be read from the start, which
+// the caller must close and remove.
+func (f *GitFilter) decompress(reader io.Reader, ext *PointerExtension) (*os.File, error) {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompress")
+	}
+	defer gz.Close()
+
+	tmp, err := TempFile(f.cfg, "")
+	if err != nil {
+		return nil, err
+	}
+
+	oidHash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, oidHash), gz)
+	if err == nil {
+		if ext.Size > 0 && size != ext.Size {
+			err = fmt.Errorf("actual size %d for extension '%s' does not match expected %d", size, CompressExtension, ext.Size)
+		} else if actual := hex.EncodeToString(oidHash.Sum(nil)); actual != ext.Oid {
+			err = fmt.Errorf("actual oid %s for extension '%s' does not match expected %s", actual, CompressExtension, ext.Oid)
+		}
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "decompress")
+	}
+	return tmp, nil
+}
+
+// countingWriter counts the bytes written to it, and discards them.
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,93 @@
+package lfs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressObjectRoundTrip(t *testing.T) {
+	f := NewGitFilter(config.NewFrom(config.Values{}))
+	contents := strings.Repeat("id,name,value\n1,a,100\n", 1000)
+
+	src, err := ioutil.TempFile("", "compress")
+	require.Nil(t, err)
+	defer os.Remove(src.Name())
+	src.WriteString(contents)
+	src.Close()
+
+	sum := sha256.Sum256([]byte(contents))
+	oid := hex.EncodeToString(sum[:])
+	ptr, tmp, err := f.compressObject(src.Name(), NewPointer(oid, int64(len(contents)), nil))
+	require.Nil(t, err)
+	defer os.Remove(tmp.Name())
+
+	if assert.Len(t, ptr.Extensions, 1) {
+		assert.Equal(t, CompressExtension, ptr.Extensions[0].Name)
+		assert.Equal(t, 0, ptr.Extensions[0].Priority)
+		assert.Equal(t, oid, ptr.Extensions[0].Oid)
+		assert.EqualValues(t, len(contents), ptr.Extensions[0].Size)
+	}
+	assert.True(t, ptr.Size < int64(len(contents)))
+
+	compressed, err := os.Open(tmp.Name())
+	require.Nil(t, err)
+	defer compressed.Close()
+
+	other := strings.Repeat("0", 64)
+	_, err = f.decompress(compressed, NewPointerExtension(CompressExtension, 0, other))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "does not match expected "+other)
+	}
+
+	compressed.Seek(0, io.SeekStart)
+	wrongSize := NewPointerExtension(CompressExtension, 0, oid)
+	wrongSize.Size = int64(len(contents)) + 1
+	_, err = f.decompress(compressed, wrongSize)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "actual size")
+	}
+
+	// Pointers which do not record the size are only checked by oid.
+	for _, ext := range []*PointerExtension{ptr.Extensions[0], NewPointerExtension(CompressExtension, 0, oid)} {
+		compressed.Seek(0, io.SeekStart)
+		decompressed, err := f.decompress(compressed, ext)
+		require.Nil(t, err)
+
+		by, err := ioutil.ReadAll(decompressed)
+		decompressed.Close()
+		os.Remove(decompressed.Name())
+		assert.Nil(t, err)
+		assert.Equal(t, contents, string(by))
+	}
+}
+
+func TestTrialCompress(t *testing.T) {
+	for desc, c := range map[string]struct {
+		contents string
+		minRatio float64
+		maxRatio float64
+	}{
+		"repetitive": {strings.Repeat("abcdefgh", 10000), 50, 1e9},
+		"short":      {"abc", 0, 1},
+	} {
+		file, err := ioutil.TempFile("", "compress")
+		require.Nil(t, err)
+		file.WriteString(c.contents)
+		file.Close()
+
+		ratio, err := trialCompress(file.Name())
+		os.Remove(file.Name())
+
+		assert.Nil(t, err, desc)
+		assert.True(t, ratio >= c.minRatio && ratio < c.maxRatio, "%s: ratio %f", desc, ratio)
+	}
+}
@@ -1,6 +1,10 @@
 package lfs
 
-import "github.com/git-lfs/git-lfs/config"
+import (
+	"time"
+
+	"github.com/git-lfs/git-lfs/config"
+)
 
 // FetchPruneConfig collects together the config options that control fetching and pruning
 type FetchPruneConfig struct {
@@ -21,6 +25,9 @@ type FetchPruneConfig struct {
 	PruneVerifyRemoteAlways bool
 	// Name of remote to check for unpushed and verify checks
 	PruneRemoteName string
+	// Objects read more recently than this are not pruned (default 0 =
+	// prune regardless of when objects were last read)
+	PruneNotAccessedIn time.Duration
 }
 
 func NewFetchPruneConfig(git config.Environment) FetchPruneConfig {
@@ -70,6 +70,10 @@ func pipeExtensions(cfg *config.Configuration, request *pipeRequest) (response p
 			return
 		}
 		name := strings.Trim(pieces[0], " ")
+		if len(name) == 0 {
+			err = fmt.Errorf("extension '%s' has no %s command: set lfs.extension.%s.%s", e.Name, request.action, e.Name, request.action)
+			return
+		}
 		var args []string
 		for _, value := range pieces[1:] {
 			arg := strings.Replace(value, "%f", request.fileName, -1)
@@ -0,0 +1,69 @@
+package lfs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/rubyist/tracerx"
+)
+
+// copyFromExtraObjectStores copies the object "oid" to "mediafile" from the
+// first of the stores given by lfs.extraobjectstores which has an intact copy
+// of it, and returns whether one did. Missing and corrupt copies are skipped,
+// so that the object is downloaded as usual if no store has it.
+func copyFromExtraObjectStores(cfg *config.Configuration, oid string, size int64, mediafile string) bool {
+	f := cfg.Filesystem()
+	for i, path := range f.ExtraObjectStorePaths(oid) {
+		dir := f.ExtraObjectStores[i]
+		if !tools.FileExistsOfSize(path, size) {
+			continue
+		}
+
+		if err := copyVerifiedObject(cfg, path, mediafile, oid); err != nil {
+			tracerx.Printf("extra object store %s: skipping %s: %s", dir, oid, err)
+			continue
+		}
+
+		tracerx.Printf("extra object store %s: copied %s", dir, oid)
+		f.RecordExtraObjectStoreHit(dir)
+		return true
+	}
+	return false
+}
+
+// copyVerifiedObject copies the object at "src" to "dst" by way of a temporary
+// file, cloning it if the file system supports it, and checks that the copy
+// hashes to "oid" before moving it into place.
+func copyVerifiedObject(cfg *config.Configuration, src, dst, oid string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := TempFile(cfg, filepath.Base(dst))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	if ok, _ := tools.CloneFile(tmp, in); !ok {
+		if _, err := io.Copy(tmp, in); err != nil {
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := tools.VerifyFileHash(oid, tmp.Name()); err != nil {
+		return err
+	}
+	return tools.RenameAcrossDevices(tmp.Name(), dst)
+}
@@ -0,0 +1,60 @@
+package lfs_test // avoid import cycle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	test "github.com/git-lfs/git-lfs/t/cmd/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeStoreObject(t *testing.T, store, oid, contents string) {
+	dir := filepath.Join(store, oid[0:2], oid[2:4])
+	require.Nil(t, os.MkdirAll(dir, 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, oid), []byte(contents), 0644))
+}
+
+func testOid(contents string) string {
+	sum := sha256.Sum256([]byte(contents))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLinkOrCopyFromReferenceUsesExtraObjectStores(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	first := filepath.Join(repo.Path, "first")
+	second := filepath.Join(repo.Path, "second")
+	repo.Filesystem().ExtraObjectStores = []string{first, second}
+
+	good := "stored contents"
+	corrupt := "corrupt contents"
+	writeStoreObject(t, second, testOid(good), good)
+	// Same size, different contents.
+	writeStoreObject(t, first, testOid(corrupt), "CORRUPT contents")
+
+	cfg := repo.Configuration()
+	require.Nil(t, lfs.LinkOrCopyFromReference(cfg, testOid(good), int64(len(good))))
+	require.Nil(t, lfs.LinkOrCopyFromReference(cfg, testOid(corrupt), int64(len(corrupt))))
+
+	data, err := ioutil.ReadFile(repo.Filesystem().ObjectPathname(testOid(good)))
+	require.Nil(t, err)
+	assert.Equal(t, good, string(data))
+
+	assert.False(t, cfg.LFSObjectExists(testOid(corrupt), int64(len(corrupt))))
+	assert.Equal(t, map[string]int64{second: 1}, repo.Filesystem().ExtraObjectStoreHits())
+
+	// The stores are only read.
+	_, err = os.Stat(filepath.Join(second, testOid(good)[0:2], testOid(good)[2:4], testOid(good)))
+	assert.Nil(t, err)
+}
@@ -1,15 +1,39 @@
 package lfs
 
 import (
+	"io"
+	"path/filepath"
+	"sync"
+
 	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/fs"
 	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/git/gitattr"
+	"github.com/git-lfs/git-lfs/tools"
 )
 
 // GitFilter provides clean and smudge capabilities
 type GitFilter struct {
 	cfg *config.Configuration
 	fs  *fs.Filesystem
+
+	// attrs are the values of the Git LFS attributes of the working tree's
+	// files, once they are loaded by attrsOnce.
+	attrs     *filterAttributes
+	attrsOnce sync.Once
+
+	// checker runs lfs.postsmudgecheck, once it is set up by checkerOnce.
+	checker     *postSmudgeChecker
+	checkerOnce sync.Once
+
+	// RetryLog, if not nil, is where RetryClean reports each retry, as
+	// well as tracing it.
+	RetryLog io.Writer
+
+	// Linked, if not nil, is called by SmudgeToFile for each file it
+	// writes when lfs.link is set to hardlink, with a nil error if the
+	// file was hard linked to its object, or the reason it was copied.
+	Linked func(filename string, err error)
 }
 
 // NewGitFilter initializes a new *GitFilter
@@ -17,6 +41,62 @@ func NewGitFilter(cfg *config.Configuration) *GitFilter {
 	return &GitFilter{cfg: cfg, fs: cfg.Filesystem()}
 }
 
+// filterAttributes are the values of the attributes which change how Git LFS
+// cleans and smudges the files of particular patterns.
+type filterAttributes struct {
+	compress *git.AttributeValues
+	verify   *git.AttributeValues
+	ext      *git.AttributeValues
+}
+
+// attributes returns the values of the working tree's Git LFS attributes,
+// reading them the first time it is called.
+func (f *GitFilter) attributes() *filterAttributes {
+	f.attrsOnce.Do(func() {
+		var paths []git.AttributePath
+		if workingDir := f.cfg.LocalWorkingDir(); len(workingDir) > 0 {
+			paths = git.GetAttributePaths(gitattr.NewMacroProcessor(), workingDir, f.cfg.LocalGitDir())
+		}
+
+		f.attrs = &filterAttributes{
+			compress: git.NewAttributeValues(paths, func(p git.AttributePath) string { return p.Compress }),
+			verify:   git.NewAttributeValues(paths, func(p git.AttributePath) string { return p.Verify }),
+			ext:      git.NewAttributeValues(paths, func(p git.AttributePath) string { return p.Ext }),
+		}
+	})
+	return f.attrs
+}
+
+// repoPath returns the path of "filename", which is relative to the current
+// directory, relative to the root of the working tree, as attributes are
+// matched against it.
+func (f *GitFilter) repoPath(filename string) string {
+	workingDir := f.cfg.LocalWorkingDir()
+	if len(workingDir) == 0 {
+		return filepath.ToSlash(filename)
+	}
+
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	rel, err := filepath.Rel(workingDir, abs)
+	if err != nil {
+		return filepath.ToSlash(filename)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// worktreePath returns the path through which the working tree file
+// "filename" is written. If core.longpaths is set, it is in the extended-length
+// form when it is too long for Windows otherwise, as Git writes such files.
+func (f *GitFilter) worktreePath(filename string) string {
+	if f.cfg.Git.Bool("core.longpaths", false) {
+		return tools.LongPath(filename)
+	}
+	return filename
+}
+
 func (f *GitFilter) ObjectPath(oid string) (string, error) {
 	return f.fs.ObjectPath(oid)
 }
@@ -4,11 +4,28 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/perf"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// defaultCleanRetries is the number of times a failed write to the
+	// local object store is retried by the clean filter, unless
+	// lfs.cleanretries says otherwise.
+	defaultCleanRetries = 3
+	// defaultCleanRetryDelay is the delay before the first retry, which
+	// doubles with each retry after it, unless lfs.cleanretrydelay says
+	// otherwise.
+	defaultCleanRetryDelay = time.Second
 )
 
 type cleanedAsset struct {
@@ -17,7 +34,7 @@ type cleanedAsset struct {
 }
 
 func (f *GitFilter) Clean(reader io.Reader, fileName string, fileSize int64, cb tools.CopyCallback) (*cleanedAsset, error) {
-	extensions, err := f.cfg.SortedExtensions()
+	extensions, err := f.cleanExtensions(fileName)
 	if err != nil {
 		return nil, err
 	}
@@ -56,19 +73,50 @@ func (f *GitFilter) Clean(reader io.Reader, fileName string, fileSize int64, cb
 	}
 
 	pointer := NewPointer(oid, size, exts)
+
+	// Extension priorities are a single digit, so the compress extension
+	// can follow at most nine others.
+	if len(exts) < 10 && f.shouldCompress(fileName, tmp.Name(), size) {
+		if staged := f.stagedCompressed(fileName, pointer); staged != nil {
+			// The temporary file is removed by the caller, as the
+			// staged pointer's object is already stored.
+			return &cleanedAsset{tmp.Name(), staged}, nil
+		}
+
+		compressed, ctmp, err := f.compressObject(tmp.Name(), pointer)
+		os.Remove(tmp.Name())
+		if err != nil {
+			return nil, err
+		}
+		pointer, tmp = compressed, ctmp
+	}
+
 	return &cleanedAsset{tmp.Name(), pointer}, err
 }
 
 func (f *GitFilter) copyToTemp(reader io.Reader, fileSize int64, cb tools.CopyCallback) (oid string, size int64, tmp *os.File, err error) {
-	tmp, err = TempFile(f.cfg, "")
+	timer := perf.Start("hashing")
+	defer timer.Stop()
+
+	err = f.RetryClean("create temporary file", func() error {
+		var terr error
+		tmp, terr = TempFile(f.cfg, "")
+		return terr
+	})
 	if err != nil {
 		return
 	}
 
-	defer tmp.Close()
+	defer func() {
+		tmp.Close()
+		if err != nil {
+			// Don't leave a partial object behind.
+			os.Remove(tmp.Name())
+		}
+	}()
 
 	oidHash := sha256.New()
-	writer := io.MultiWriter(oidHash, tmp)
+	writer := io.MultiWriter(oidHash, &cleanRetryWriter{f: f, w: tmp})
 
 	if fileSize <= 0 {
 		cb = nil
@@ -102,6 +150,112 @@ func (f *GitFilter) copyToTemp(reader io.Reader, fileSize int64, cb tools.CopyCa
 	return
 }
 
+// RetryClean calls "fn", which writes to the local object store on behalf of
+// the clean filter, retrying it with exponential backoff if it fails, up to
+// lfs.cleanretries times. This allows the clean filter to survive transient
+// errors, such as a full disk or a network filesystem which is briefly
+// unavailable. Each retry is traced, and reported to RetryLog, and the last
+// error is returned once the retries are exhausted.
+func (f *GitFilter) RetryClean(desc string, fn func() error) error {
+	retries := f.cfg.Git.Int("lfs.cleanretries", defaultCleanRetries)
+	if retries < 0 {
+		retries = defaultCleanRetries
+	}
+
+	delay := defaultCleanRetryDelay
+	if v, ok := f.cfg.Git.Get("lfs.cleanretrydelay"); ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			delay = d
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > retries {
+			return err
+		}
+
+		tracerx.Printf("clean: could not %s: %v; retrying in %s (%d of %d)",
+			desc, err, delay, attempt, retries)
+		if f.RetryLog != nil {
+			fmt.Fprintf(f.RetryLog, "Git LFS: could not %s: %v; retrying in %s (%d of %d)\n",
+				desc, err, delay, attempt, retries)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// cleanRetryWriter writes to the local object store on behalf of the clean
+// filter, retrying failed writes of the remaining data with RetryClean. Since
+// the data being cleaned cannot be read again, retries happen here rather
+// than by copying it from the start.
+type cleanRetryWriter struct {
+	f *GitFilter
+	w io.Writer
+}
+
+func (w *cleanRetryWriter) Write(p []byte) (int, error) {
+	var written int
+	err := w.f.RetryClean("write object", func() error {
+		n, err := w.w.Write(p[written:])
+		written += n
+		return err
+	})
+	return written, err
+}
+
 func (a *cleanedAsset) Teardown() error {
 	return os.Remove(a.Filename)
 }
+
+// cleanExtensions returns the configured extensions which "fileName" is to be
+// cleaned by, in order. These are all of them, unless the file has the lfs-ext
+// attribute, which either names the ones to use, separated by commas, or is
+// unset to use none. Naming one which is not configured is an error, rather
+// than the file being stored without it.
+func (f *GitFilter) cleanExtensions(fileName string) ([]config.Extension, error) {
+	configured, err := f.cfg.SortedExtensions()
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of the built-in compress extension is reserved.
+	var extensions []config.Extension
+	for _, ext := range configured {
+		if ext.Name != CompressExtension {
+			extensions = append(extensions, ext)
+		}
+	}
+
+	value := f.attributes().ext.Get(fileName)
+	switch value {
+	case "", "true":
+		return extensions, nil
+	case "false":
+		return nil, nil
+	}
+
+	available := make(map[string]bool, len(extensions))
+	for _, ext := range extensions {
+		available[ext.Name] = true
+	}
+
+	named := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		if !available[name] {
+			return nil, errors.Errorf("%s requires the extension '%s' (lfs-ext=%s), which is not configured: set lfs.extension.%s.clean to its clean command (see git-lfs-config(5))", fileName, name, value, name)
+		}
+		named[name] = true
+	}
+
+	// The extensions are applied in order of priority, whatever order
+	// they are named in.
+	var selected []config.Extension
+	for _, ext := range extensions {
+		if named[ext.Name] {
+			selected = append(selected, ext)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,111 @@
+package lfs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCleanRetryFilter(retries string) *GitFilter {
+	return NewGitFilter(config.NewFrom(config.Values{
+		Git: map[string][]string{
+			"lfs.cleanretries":    []string{retries},
+			"lfs.cleanretrydelay": []string{"1ms"},
+		},
+	}))
+}
+
+func TestRetryCleanSucceedsAfterTransientErrors(t *testing.T) {
+	f := newCleanRetryFilter("3")
+
+	var calls int
+	err := f.RetryClean("write object", func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("no space left on device")
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryCleanGivesUpAfterRetries(t *testing.T) {
+	f := newCleanRetryFilter("2")
+
+	var calls int
+	err := f.RetryClean("write object", func() error {
+		calls++
+		return errors.New("no space left on device")
+	})
+
+	assert.EqualError(t, err, "no space left on device")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryCleanWithoutRetries(t *testing.T) {
+	f := newCleanRetryFilter("0")
+
+	var calls int
+	err := f.RetryClean("write object", func() error {
+		calls++
+		return errors.New("no space left on device")
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+// flakyWriter accepts at most "limit" bytes per write, failing every other
+// write.
+type flakyWriter struct {
+	buf   bytes.Buffer
+	limit int
+	fail  bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.fail = !w.fail
+	if len(p) > w.limit {
+		p = p[:w.limit]
+	}
+	n, _ := w.buf.Write(p)
+	if w.fail {
+		return n, errors.New("transient error")
+	}
+	return n, nil
+}
+
+func TestCleanRetryWriterWritesRemainingData(t *testing.T) {
+	w := &flakyWriter{limit: 3}
+	rw := &cleanRetryWriter{f: newCleanRetryFilter("3"), w: w}
+
+	n, err := rw.Write([]byte("abcdef"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, "abcdef", w.buf.String())
+}
+
+func TestRetryCleanReportsRetries(t *testing.T) {
+	f := newCleanRetryFilter("3")
+
+	var log bytes.Buffer
+	f.RetryLog = &log
+
+	var calls int
+	err := f.RetryClean("write object", func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("no space left on device")
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Git LFS: could not write object: no space left on device; retrying in 1ms (1 of 3)\n", log.String())
+}
@@ -3,8 +3,10 @@ package lfs
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/errors"
@@ -15,22 +17,62 @@ import (
 )
 
 func (f *GitFilter) SmudgeToFile(filename string, ptr *Pointer, download bool, manifest *tq.Manifest, cb tools.CopyCallback) error {
-	tools.MkdirAll(filepath.Dir(filename), f.cfg)
+	if f.postSmudgeChecker().aborted() {
+		return ErrTooManyQuarantined
+	}
+
+	path := f.worktreePath(filename)
+	tools.MkdirAll(filepath.Dir(path), f.cfg)
 
-	if stat, _ := os.Stat(filename); stat != nil && stat.Mode()&0200 == 0 {
-		if err := os.Chmod(filename, stat.Mode()|0200); err != nil {
+	// A file which is hard linked to an object must not be written in
+	// place, since that would modify the object as well.
+	if n, _ := tools.HardLinkCount(path); n > 1 {
+		if err := os.Remove(path); err != nil {
+			return errors.Wrap(err, "Could not remove hard linked file")
+		}
+	}
+
+	if stat, _ := os.Stat(path); stat != nil && stat.Mode()&0200 == 0 {
+		if err := os.Chmod(path, stat.Mode()|0200); err != nil {
 			return errors.Wrap(err,
 				"Could not restore write permission")
 		}
 
 		// When we're done, return the file back to its normal
 		// permission bits.
-		defer os.Chmod(filename, stat.Mode())
+		defer os.Chmod(path, stat.Mode())
 	}
 
-	abs, err := filepath.Abs(filename)
-	if err != nil {
-		return fmt.Errorf("could not produce absolute path for %q", filename)
+	// Leave the working tree file as it is if the object cannot be turned
+	// back into its contents.
+	if _, err := f.smudgeExtensions(ptr, filename); err != nil {
+		return errors.NewSmudgeError(err, ptr.Oid, filename)
+	}
+
+	// Verify the object before the working tree file is truncated, so that
+	// it is left as it was if the object is damaged.
+	if f.checkoutVerify(f.repoPath(filename)) {
+		if err := f.verifyObject(f.fs.ObjectPathname(ptr.Oid), ptr); err != nil {
+			return errors.NewSmudgeError(err, ptr.Oid, filename)
+		}
+	}
+
+	abs := path
+	if !filepath.IsAbs(abs) {
+		var err error
+		if abs, err = filepath.Abs(path); err != nil {
+			return fmt.Errorf("could not produce absolute path for %q", filename)
+		}
+	}
+
+	if f.linkMode() == linkModeHardlink {
+		err := f.linkFile(abs, ptr)
+		if f.Linked != nil {
+			f.Linked(filename, err)
+		}
+		if err == nil {
+			return nil
+		}
 	}
 
 	file, err := os.Create(abs)
@@ -38,7 +80,7 @@ func (f *GitFilter) SmudgeToFile(filename string, ptr *Pointer, download bool, m
 		return fmt.Errorf("could not create working directory file: %v", err)
 	}
 	defer file.Close()
-	if _, err := f.Smudge(file, ptr, filename, download, manifest, cb); err != nil {
+	if _, err := f.smudge(file, ptr, filename, download, manifest, cb, false); err != nil {
 		if errors.IsDownloadDeclinedError(err) {
 			// write placeholder data instead
 			file.Seek(0, io.SeekStart)
@@ -48,15 +90,77 @@ func (f *GitFilter) SmudgeToFile(filename string, ptr *Pointer, download bool, m
 			return fmt.Errorf("could not write working directory file: %v", err)
 		}
 	}
-	return nil
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("could not write working directory file: %v", err)
+	}
+
+	_, err = f.postSmudgeCheck(abs, filename, ptr, func() error {
+		return ioutil.WriteFile(abs, []byte(ptr.Encoded()), f.cfg.RepositoryPermissions(false))
+	})
+	return err
 }
 
 func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, download bool, manifest *tq.Manifest, cb tools.CopyCallback) (int64, error) {
+	checker := f.postSmudgeChecker()
+	if len(checker.command) == 0 {
+		return f.smudge(writer, ptr, workingfile, download, manifest, cb, f.checkoutVerify(workingfile))
+	}
+	if checker.aborted() {
+		return 0, ErrTooManyQuarantined
+	}
+
+	// Git writes the working tree file itself, so the contents are checked
+	// in a temporary file with the same extension, and only then passed on.
+	tmp, err := TempFile(f.cfg, "smudge-*"+filepath.Ext(workingfile))
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = f.smudge(tmp, ptr, workingfile, download, manifest, cb, f.checkoutVerify(workingfile))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The caller writes the pointer if an error is returned.
+	ok, err := f.postSmudgeCheck(tmp.Name(), workingfile, ptr, func() error { return nil })
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		n, err := ptr.Encode(writer)
+		return int64(n), err
+	}
+
+	file, err := os.Open(tmp.Name())
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return io.Copy(writer, file)
+}
+
+func (f *GitFilter) smudge(writer io.Writer, ptr *Pointer, workingfile string, download bool, manifest *tq.Manifest, cb tools.CopyCallback, verify bool) (int64, error) {
 	mediafile, err := f.ObjectPath(ptr.Oid)
 	if err != nil {
 		return 0, err
 	}
 
+	if verify {
+		if err := f.verifyObject(mediafile, ptr); err != nil {
+			return 0, errors.NewSmudgeError(err, ptr.Oid, mediafile)
+		}
+	}
+
+	// Without every extension which cleaned the object, it cannot be
+	// turned back into the file's contents, so it is not downloaded.
+	if _, err := f.smudgeExtensions(ptr, workingfile); err != nil {
+		return 0, errors.NewSmudgeError(err, ptr.Oid, mediafile)
+	}
+
 	LinkOrCopyFromReference(f.cfg, ptr.Oid, ptr.Size)
 
 	stat, statErr := os.Stat(mediafile)
@@ -64,7 +168,9 @@ func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, d
 		fileSize := stat.Size()
 		if fileSize != ptr.Size {
 			tracerx.Printf("Removing %s, size %d is invalid", mediafile, fileSize)
-			os.RemoveAll(mediafile)
+			if err := os.RemoveAll(mediafile); err == nil {
+				f.fs.RecordObjectRemoved(fileSize)
+			}
 			stat = nil
 		}
 	}
@@ -72,12 +178,14 @@ func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, d
 	var n int64
 
 	if statErr != nil || stat == nil {
+		f.fs.RecordCacheMiss()
 		if download {
 			n, err = f.downloadFile(writer, ptr, workingfile, mediafile, manifest, cb)
 		} else {
 			return 0, errors.NewDownloadDeclinedError(statErr, "smudge")
 		}
 	} else {
+		f.fs.RecordCacheHit(ptr.Size)
 		n, err = f.readLocalFile(writer, ptr, mediafile, workingfile, cb)
 	}
 
@@ -85,9 +193,152 @@ func (f *GitFilter) Smudge(writer io.Writer, ptr *Pointer, workingfile string, d
 		return 0, errors.NewSmudgeError(err, ptr.Oid, mediafile)
 	}
 
+	f.fs.RecordAccess(ptr.Oid)
 	return n, nil
 }
 
+// linkModeHardlink is the value of lfs.link with which SmudgeToFile hard links
+// objects into the working tree, rather than copying them.
+const linkModeHardlink = "hardlink"
+
+// linkMode returns how SmudgeToFile creates working tree files from objects,
+// as set by lfs.link.
+func (f *GitFilter) linkMode() string {
+	mode, _ := f.cfg.Git.Get("lfs.link")
+	return strings.ToLower(mode)
+}
+
+// linkFile replaces the file at "filename" with a hard link to the local
+// object of "ptr", first making the object read-only so that the file cannot
+// be modified in place. It returns an error if the file cannot be linked, such
+// as when its object is not present or is on another file system, or when the
+// object is not the file's contents, in which case it should be copied
+// instead.
+func (f *GitFilter) linkFile(filename string, ptr *Pointer) error {
+	if len(ptr.Extensions) > 0 {
+		return errors.New("its object is transformed by extensions")
+	}
+
+	mediafile := f.fs.ObjectPathname(ptr.Oid)
+	if stat, err := os.Stat(mediafile); err != nil {
+		return errors.Wrap(err, "object not found")
+	} else if stat.Size() != ptr.Size {
+		return fmt.Errorf("object has size %d, expected %d", stat.Size(), ptr.Size)
+	}
+
+	if err := tools.SetFileWriteFlag(mediafile, false); err != nil {
+		tracerx.Printf("smudge: could not make %s read-only, copying it: %v", mediafile, err)
+		return errors.Wrap(err, "could not make object read-only")
+	}
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		tracerx.Printf("smudge: could not replace %s, copying it: %v", filename, err)
+		return err
+	}
+	if err := os.Link(mediafile, filename); err != nil {
+		tracerx.Printf("smudge: could not hard link %s, copying it: %v", filename, err)
+		return err
+	}
+
+	tracerx.Printf("smudge: hard linked %s to %s", filename, mediafile)
+	f.fs.RecordAccess(ptr.Oid)
+	return nil
+}
+
+// CheckExtensions returns an error if "ptr" records an extension which is not
+// configured with a smudge command, in which case its object cannot be turned
+// back into the contents of "workingfile".
+func (f *GitFilter) CheckExtensions(ptr *Pointer, workingfile string) error {
+	_, err := f.smudgeExtensions(ptr, workingfile)
+	return err
+}
+
+// smudgeExtensions returns the extensions which "ptr" records were applied to
+// its file when it was cleaned, in the reverse order, in which their smudge
+// commands are run. It returns an error if any of them is not configured with
+// a smudge command, since the object alone is not the file's contents.
+func (f *GitFilter) smudgeExtensions(ptr *Pointer, workingfile string) ([]config.Extension, error) {
+	if len(ptr.Extensions) == 0 {
+		return nil, nil
+	}
+
+	_, others := splitCompressExtension(ptr)
+	if len(others) == 0 {
+		return nil, nil
+	}
+
+	registeredExts := f.cfg.Extensions()
+	extensions := make(map[string]config.Extension)
+	for _, ptrExt := range others {
+		ext, ok := registeredExts[ptrExt.Name]
+		if !ok || len(strings.TrimSpace(ext.Smudge)) == 0 {
+			return nil, errors.Errorf("%s was cleaned by the extension '%s', which is not configured: set lfs.extension.%s.smudge to its smudge command (see git-lfs-config(5))",
+				workingfile, ptrExt.Name, ptrExt.Name)
+		}
+		ext.Priority = ptrExt.Priority
+		extensions[ext.Name] = ext
+	}
+	exts, err := config.SortExtensions(extensions)
+	if err != nil {
+		return nil, err
+	}
+
+	extsR := make([]config.Extension, 0, len(exts))
+	for i := range exts {
+		extsR = append(extsR, exts[len(exts)-1-i])
+	}
+	return extsR, nil
+}
+
+// checkoutVerify returns whether the local object of "filename", which is
+// relative to the root of the working tree, should be verified against its
+// pointer before it is written to the working tree. This is configured by
+// lfs.checkoutverify, unless the file has the lfs-verify attribute, which
+// overrides it. Objects are always verified as they are downloaded.
+func (f *GitFilter) checkoutVerify(filename string) bool {
+	switch f.attributes().verify.Get(filename) {
+	case "":
+		return f.cfg.Git.Bool("lfs.checkoutverify", false)
+	case "false":
+		return false
+	default:
+		return true
+	}
+}
+
+// verifyObject checks that the local object at "mediafile" is exactly as long
+// as "ptr" records, and that its contents hash to the pointer's OID. Objects
+// which are not present locally are not checked.
+func (f *GitFilter) verifyObject(mediafile string, ptr *Pointer) error {
+	stat, err := os.Stat(mediafile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// As in readLocalFile, a size of zero is taken to be unknown.
+	if ptr.Size > 0 && stat.Size() != ptr.Size {
+		return errors.Errorf("size mismatch: object %s is %d bytes, but its pointer records %d bytes",
+			ptr.Oid, stat.Size(), ptr.Size)
+	}
+
+	file, err := os.Open(mediafile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hr := tools.NewHashingReader(file)
+	if _, err := io.Copy(ioutil.Discard, hr); err != nil {
+		return err
+	}
+	if oid := hr.Hash(); oid != ptr.Oid {
+		return errors.Errorf("hash mismatch: object %s has contents with OID %s", ptr.Oid, oid)
+	}
+	return nil
+}
+
 func (f *GitFilter) downloadFile(writer io.Writer, ptr *Pointer, workingfile, mediafile string, manifest *tq.Manifest, cb tools.CopyCallback) (int64, error) {
 	fmt.Fprintf(os.Stderr, "Downloading %s (%s)\n", workingfile, humanize.FormatBytes(uint64(ptr.Size)))
 
@@ -132,28 +383,30 @@ func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile stri
 		}
 	}
 
-	if len(ptr.Extensions) > 0 {
-		registeredExts := f.cfg.Extensions()
-		extensions := make(map[string]config.Extension)
-		for _, ptrExt := range ptr.Extensions {
-			ext, ok := registeredExts[ptrExt.Name]
-			if !ok {
-				err := fmt.Errorf("extension '%s' is not configured", ptrExt.Name)
-				return 0, errors.Wrap(err, "smudge")
-			}
-			ext.Priority = ptrExt.Priority
-			extensions[ext.Name] = ext
-		}
-		exts, err := config.SortExtensions(extensions)
+	// The oid of the contents which the configured extensions undo.
+	oid := ptr.Oid
+	size := ptr.Size
+
+	compress, others := splitCompressExtension(ptr)
+	if compress != nil {
+		decompressed, err := f.decompress(reader, compress)
 		if err != nil {
 			return 0, errors.Wrap(err, "smudge")
 		}
+		defer os.Remove(decompressed.Name())
+		defer decompressed.Close()
 
-		// pipe extensions in reverse order
-		var extsR []config.Extension
-		for i := range exts {
-			ext := exts[len(exts)-1-i]
-			extsR = append(extsR, ext)
+		if stat, err := decompressed.Stat(); err == nil {
+			size = stat.Size()
+		}
+		reader = decompressed
+		oid = compress.Oid
+	}
+
+	if len(others) > 0 {
+		extsR, err := f.smudgeExtensions(ptr, workingfile)
+		if err != nil {
+			return 0, errors.Wrap(err, "smudge")
 		}
 
 		request := &pipeRequest{"smudge", reader, workingfile, extsR}
@@ -169,13 +422,12 @@ func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile stri
 		}
 
 		// verify name, order, and oids
-		oid := response.results[0].oidIn
-		if ptr.Oid != oid {
-			err = fmt.Errorf("actual oid %s during smudge does not match expected %s", oid, ptr.Oid)
+		if actual := response.results[0].oidIn; actual != oid {
+			err = fmt.Errorf("actual oid %s during smudge does not match expected %s", actual, oid)
 			return 0, errors.Wrap(err, "smudge")
 		}
 
-		for _, expected := range ptr.Extensions {
+		for _, expected := range others {
 			actual := actualExts[expected.Name]
 			if actual.name != expected.Name {
 				err = fmt.Errorf("actual extension name '%s' does not match expected '%s'", actual.name, expected.Name)
@@ -195,7 +447,14 @@ func (f *GitFilter) readLocalFile(writer io.Writer, ptr *Pointer, mediafile stri
 		defer reader.Close()
 	}
 
-	n, err := tools.CopyWithCallback(writer, reader, ptr.Size, cb)
+	// Where the file system supports it, the working tree file is cloned
+	// from the object rather than copied, unless lfs.dedup is disabled.
+	copyFn := tools.CopyWithCallback
+	if !f.cfg.Git.Bool("lfs.dedup", true) {
+		copyFn = tools.StreamWithCallback
+	}
+
+	n, err := copyFn(writer, reader, size, cb)
 	if err != nil {
 		return n, errors.Wrapf(err, "Error reading from media file: %s", err)
 	}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/git-lfs/git-lfs/config"
 	"github.com/git-lfs/git-lfs/filepathfilter"
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/git-lfs/git-lfs/tools/perf"
 	"github.com/rubyist/tracerx"
 )
 
@@ -30,6 +32,7 @@ type GitScanner struct {
 
 	closed  bool
 	started time.Time
+	timer   *perf.Timer
 	mu      sync.Mutex
 	cfg     *config.Configuration
 }
@@ -44,7 +47,7 @@ type GitScannerSet interface {
 // NewGitScanner initializes a *GitScanner for a Git repository in the current
 // working directory.
 func NewGitScanner(cfg *config.Configuration, cb GitScannerFoundPointer) *GitScanner {
-	return &GitScanner{started: time.Now(), FoundPointer: cb, cfg: cfg}
+	return &GitScanner{started: time.Now(), timer: perf.Start("ref scan"), FoundPointer: cb, cfg: cfg}
 }
 
 // Close stops exits once all processing has stopped, and all resources are
@@ -59,6 +62,7 @@ func (s *GitScanner) Close() {
 
 	s.closed = true
 	tracerx.PerformanceSince("scan", s.started)
+	s.timer.Stop()
 }
 
 // RemoteForPush sets up this *GitScanner to scan for objects to push to the
@@ -179,9 +183,31 @@ func (s *GitScanner) ScanTree(ref string) error {
 	if err != nil {
 		return err
 	}
+	s.prefetchMissing([]string{ref}, nil, &git.ScanRefsOptions{
+		Mode:             git.ScanRefsMode,
+		SkipDeletedBlobs: true,
+	})
 	return runScanTree(callback, ref, s.Filter, s.cfg.OSEnv())
 }
 
+// ScanIndexTree returns WrappedPointer objects for the files in the git index,
+// as ScanTree does for the tree at a ref. Files which are staged but not yet
+// committed are reported with their staged contents.
+func (s *GitScanner) ScanIndexTree() error {
+	return s.ScanWorkTreeIndexTree("")
+}
+
+// ScanWorkTreeIndexTree is like ScanIndexTree, but scans the index of the
+// working tree in the directory "workTree", or of the current one if it is
+// empty.
+func (s *GitScanner) ScanWorkTreeIndexTree(workTree string) error {
+	callback, err := firstGitScannerCallback(s.FoundPointer)
+	if err != nil {
+		return err
+	}
+	return runScanIndexTree(callback, workTree, s.Filter, s.cfg.OSEnv())
+}
+
 // ScanUnpushed scans history for all LFS pointers which have been added but not
 // pushed to the named remote. remote can be left blank to mean 'any remote'.
 func (s *GitScanner) ScanUnpushed(remote string, cb GitScannerFoundPointer) error {
@@ -221,9 +247,28 @@ func (s *GitScanner) opts(mode ScanningMode) *ScanRefsOptions {
 	opts.ScanMode = mode
 	opts.RemoteName = s.remote
 	opts.skippedRefs = s.skippedRefs
+	if mode == ScanRangeToRemoteMode {
+		opts.shallowCommits = s.shallowCommits()
+	}
 	return opts
 }
 
+// shallowCommits returns the set of commits at which the history of a shallow
+// repository was cut off, or nil if it is not shallow.
+func (s *GitScanner) shallowCommits() map[string]bool {
+	commits, err := git.ShallowCommits(s.cfg.LocalGitStorageDir())
+	if err != nil {
+		tracerx.Printf("scan: unable to read shallow commits: %v", err)
+		return nil
+	}
+
+	set := make(map[string]bool, len(commits))
+	for _, commit := range commits {
+		set[commit] = true
+	}
+	return set
+}
+
 func firstGitScannerCallback(callbacks ...GitScannerFoundPointer) (GitScannerFoundPointer, error) {
 	for _, cb := range callbacks {
 		if cb == nil {
@@ -250,6 +295,24 @@ type ScanRefsOptions struct {
 	skippedRefs      []string
 	nameMap          map[string]string
 	mutex            *sync.Mutex
+
+	// shallowCommits, if not empty, are the commits at which the history
+	// of a shallow repository was cut off, which a scan for objects to
+	// push must not reach.
+	shallowCommits map[string]bool
+}
+
+// gitOptions returns the options with which to run git rev-list for a scan
+// with these options.
+func (o *ScanRefsOptions) gitOptions() *git.ScanRefsOptions {
+	return &git.ScanRefsOptions{
+		Mode:             git.ScanningMode(o.ScanMode),
+		Remote:           o.RemoteName,
+		SkipDeletedBlobs: o.SkipDeletedBlobs,
+		SkippedRefs:      o.skippedRefs,
+		Mutex:            o.mutex,
+		Names:            o.nameMap,
+	}
 }
 
 func (o *ScanRefsOptions) GetName(sha string) (string, bool) {
@@ -65,6 +65,7 @@ type PointerScanner struct {
 	scanner *git.ObjectScanner
 
 	blobSha     string
+	blobSize    int64
 	contentsSha string
 	pointer     *WrappedPointer
 	err         error
@@ -83,6 +84,12 @@ func (s *PointerScanner) BlobSHA() string {
 	return s.blobSha
 }
 
+// BlobSize returns the size of the last blob scanned, which for a pointer is
+// the size of the pointer itself rather than of the object it points to.
+func (s *PointerScanner) BlobSize() int64 {
+	return s.blobSize
+}
+
 func (s *PointerScanner) ContentsSha() string {
 	return s.contentsSha
 }
@@ -98,6 +105,7 @@ func (s *PointerScanner) Err() error {
 func (s *PointerScanner) Scan(sha string) bool {
 	s.pointer, s.err = nil, nil
 	s.blobSha, s.contentsSha = "", ""
+	s.blobSize = 0
 
 	b, c, p, err := s.next(sha)
 	s.blobSha = b
@@ -128,6 +136,7 @@ func (s *PointerScanner) next(blob string) (string, string, *WrappedPointer, err
 
 	blobSha := s.scanner.Sha1()
 	size := s.scanner.Size()
+	s.blobSize = size
 
 	sha := sha256.New()
 
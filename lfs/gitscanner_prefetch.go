@@ -0,0 +1,53 @@
+package lfs
+
+import (
+	"github.com/git-lfs/git-lfs/git"
+	"github.com/rubyist/tracerx"
+)
+
+// prefetchMissing downloads, in a partial clone, the objects which a scan of
+// the revisions given by "include", "exclude" and "opt" would read but which
+// are missing, in a single fetch. Otherwise Git would fetch each of them
+// lazily, one at a time, as the scan read it, which is very slow when there
+// are many, such as after a "git clone --filter=blob:none".
+//
+// It does nothing outside a partial clone, and if the objects cannot be
+// fetched now, Git still fetches them lazily.
+func (s *GitScanner) prefetchMissing(include, exclude []string, opt *git.ScanRefsOptions) {
+	remote := s.promisorRemote()
+	if len(remote) == 0 || !git.IsGitVersionAtLeast("2.29.0") {
+		return
+	}
+
+	missing, err := git.MissingObjects(include, exclude, opt)
+	if err != nil {
+		tracerx.Printf("prefetch: unable to list missing objects: %v", err)
+		return
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	tracerx.Printf("prefetch: fetching %d missing object(s) from %q", len(missing), remote)
+	if err := git.FetchObjects(remote, missing); err != nil {
+		tracerx.Printf("prefetch: %v", err)
+	}
+}
+
+// promisorRemote returns the remote from which a partial clone fetches its
+// missing objects, or an empty string if the repository is not a partial
+// clone.
+func (s *GitScanner) promisorRemote() string {
+	if s.cfg == nil {
+		return ""
+	}
+	if remote, ok := s.cfg.Git.Get("extensions.partialclone"); ok && len(remote) > 0 {
+		return remote
+	}
+	for _, remote := range s.cfg.Remotes() {
+		if s.cfg.Git.Bool("remote."+remote+".promisor", false) {
+			return remote
+		}
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package lfs
 
 import (
 	"encoding/hex"
+	"errors"
 	"regexp"
 
 	"github.com/git-lfs/git-lfs/config"
@@ -10,6 +11,11 @@ import (
 
 var z40 = regexp.MustCompile(`\^?0{40}`)
 
+// shallowCloneErr is returned by a scan for the objects to push which reaches
+// the commits at which a shallow clone's history was cut off, since which of
+// the objects beyond them the remote has cannot be known.
+var shallowCloneErr = errors.New("shallow clone: cannot determine objects, fetch more history or use --all")
+
 type lockableNameSet struct {
 	opt *ScanRefsOptions
 	set GitScannerSet
@@ -43,6 +49,8 @@ func scanRefsToChan(scanner *GitScanner, pointerCb GitScannerFoundPointer, inclu
 		panic("no scan ref options")
 	}
 
+	scanner.prefetchMissing(include, exclude, opt.gitOptions())
+
 	revs, err := revListShas(include, exclude, opt)
 	if err != nil {
 		return err
@@ -112,14 +120,7 @@ func scanMultiLeftRightToChan(scanner *GitScanner, pointerCb GitScannerFoundPoin
 // for the given ref. If all is true, ref is ignored. It returns a
 // channel from which sha1 strings can be read.
 func revListShas(include, exclude []string, opt *ScanRefsOptions) (*StringChannelWrapper, error) {
-	scanner, err := git.NewRevListScanner(include, exclude, &git.ScanRefsOptions{
-		Mode:             git.ScanningMode(opt.ScanMode),
-		Remote:           opt.RemoteName,
-		SkipDeletedBlobs: opt.SkipDeletedBlobs,
-		SkippedRefs:      opt.skippedRefs,
-		Mutex:            opt.mutex,
-		Names:            opt.nameMap,
-	})
+	scanner, err := git.NewRevListScanner(include, exclude, opt.gitOptions())
 
 	if err != nil {
 		return nil, err
@@ -129,8 +130,23 @@ func revListShas(include, exclude []string, opt *ScanRefsOptions) (*StringChanne
 	errs := make(chan error, 5) // may be multiple errors
 
 	go func() {
+		reachedShallow := false
 		for scanner.Scan() {
 			sha := hex.EncodeToString(scanner.OID())
+			if reachedShallow {
+				// Read the rest of the output, so that git
+				// rev-list can exit, but scan none of it.
+				continue
+			}
+			if opt.shallowCommits[sha] {
+				// git rev-list lists every commit before any
+				// other object, so nothing has been scanned
+				// yet.
+				reachedShallow = true
+				errs <- shallowCloneErr
+				continue
+			}
+
 			if name := scanner.Name(); len(name) > 0 {
 				opt.SetName(sha, name)
 			}
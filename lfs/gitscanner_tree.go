@@ -43,6 +43,27 @@ func runScanTree(cb GitScannerFoundPointer, ref string, filter *filepathfilter.F
 	return nil
 }
 
+func runScanIndexTree(cb GitScannerFoundPointer, workTree string, filter *filepathfilter.Filter, osEnv config.Environment) error {
+	indexShas, err := lsFilesBlobs(workTree, filter)
+	if err != nil {
+		return err
+	}
+
+	pcw, err := catFileBatchTree(indexShas, osEnv)
+	if err != nil {
+		return err
+	}
+
+	for p := range pcw.Results {
+		cb(p, nil)
+	}
+
+	if err := pcw.Wait(); err != nil {
+		cb(nil, err)
+	}
+	return nil
+}
+
 // catFileBatchTree uses git cat-file --batch to get the object contents
 // of a git object, given its sha1. The contents will be decoded into
 // a Git LFS pointer. treeblobs is a channel over which blob entries
@@ -130,6 +151,61 @@ func lsTreeBlobs(ref string, filter *filepathfilter.Filter) (*TreeBlobChannelWra
 	return NewTreeBlobChannelWrapper(blobs, errchan), nil
 }
 
+// Use ls-files to find the blobs in the index which might be lfs files, as
+// lsTreeBlobs does for a tree. Entries which are not regular files, or which
+// are unmerged, are skipped.
+func lsFilesBlobs(workTree string, filter *filepathfilter.Filter) (*TreeBlobChannelWrapper, error) {
+	cmd, err := git.LsFilesStage(workTree)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Stdin.Close()
+
+	blobs := make(chan TreeBlob, chanBufSize)
+	errchan := make(chan error, 1)
+
+	go func() {
+		scanner := bufio.NewScanner(cmd.Stdout)
+		scanner.Split(scanNullLines)
+		for scanner.Scan() {
+			if t := parseLsFilesStage(scanner.Text()); t != nil && filter.Allows(t.Filename) {
+				blobs <- *t
+			}
+		}
+
+		stderr, _ := ioutil.ReadAll(cmd.Stderr)
+		err := cmd.Wait()
+		if err != nil {
+			errchan <- fmt.Errorf("error in git ls-files: %v %v", err, string(stderr))
+		}
+		close(blobs)
+		close(errchan)
+	}()
+
+	return NewTreeBlobChannelWrapper(blobs, errchan), nil
+}
+
+// parseLsFilesStage parses a line of `git ls-files --stage` output of the
+// form "<mode> <sha1> <stage>\t<path>", returning nil for entries other than
+// merged regular files.
+func parseLsFilesStage(line string) *TreeBlob {
+	parts := strings.SplitN(line, "\t", 2)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	attrs := strings.SplitN(parts[0], " ", 3)
+	if len(attrs) < 3 {
+		return nil
+	}
+
+	if !strings.HasPrefix(attrs[0], "100") || attrs[2] != "0" {
+		return nil
+	}
+	return &TreeBlob{Sha1: attrs[1], Filename: parts[1]}
+}
+
 type lsTreeScanner struct {
 	s    *bufio.Scanner
 	tree *TreeBlob
@@ -168,7 +244,9 @@ func (s *lsTreeScanner) next() (*TreeBlob, bool) {
 		return nil, hasNext
 	}
 
-	if attrs[1] != "blob" {
+	// Git stores the target of a symbolic link as its blob, which is
+	// never a Git LFS pointer, even if it looks like one.
+	if attrs[1] != "blob" || !strings.HasPrefix(attrs[0], "100") {
 		return nil, hasNext
 	}
 
@@ -0,0 +1,431 @@
+package lfs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+)
+
+const (
+	// hexDumpWidth is the number of bytes represented on each line of a
+	// hex dump.
+	hexDumpWidth = 16
+
+	// DefaultDiffContext is the number of unchanged lines shown around
+	// each change in a unified diff, matching git-diff(1).
+	DefaultDiffContext = 3
+
+	// maxDiffEdits is the largest number of inserted and deleted lines
+	// that UnifiedDiff will look for, since the time taken to find them
+	// grows with the square of their number. A single byte inserted near
+	// the start of a large file shifts every line of its hex dump, for
+	// example.
+	maxDiffEdits = 10000
+)
+
+// ErrDiffTooLarge is returned by UnifiedDiff and HexDiff when the inputs differ
+// by more lines than they will compare, in which case the caller may only
+// report that they differ.
+var ErrDiffTooLarge = errors.New("too many differences to show")
+
+// HexDump returns the contents of "r" formatted in the style of xxd(1), one
+// line per 16 bytes, with the offset, the bytes in hexadecimal grouped in
+// pairs, and their printable ASCII representation.
+func HexDump(r io.Reader) ([]string, error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, hexDumpWidth)
+
+	var lines []string
+	var offset int64
+
+	for {
+		n, err := io.ReadFull(br, buf)
+		if n > 0 {
+			lines = append(lines, hexDumpLine(offset, buf[:n]))
+			offset += int64(n)
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// hexDumpLine formats a single line of a hex dump for the given bytes "b",
+// which begin at "offset" in the dumped data.
+func hexDumpLine(offset int64, b []byte) string {
+	var line strings.Builder
+
+	fmt.Fprintf(&line, "%08x: ", offset)
+	for i := 0; i < hexDumpWidth; i++ {
+		if i < len(b) {
+			fmt.Fprintf(&line, "%02x", b[i])
+		} else {
+			line.WriteString("  ")
+		}
+		if i%2 == 1 {
+			line.WriteByte(' ')
+		}
+	}
+
+	line.WriteByte(' ')
+	for _, c := range b {
+		if c >= 0x20 && c < 0x7f {
+			line.WriteByte(c)
+		} else {
+			line.WriteByte('.')
+		}
+	}
+
+	return line.String()
+}
+
+// HexDiff writes a unified diff of the hex dumps of "from" and "to" to "w",
+// labelling them "fromName" and "toName" respectively, and showing "context"
+// unchanged lines around each change. If both inputs are identical, nothing is
+// written. If they differ by too many lines, ErrDiffTooLarge is returned and
+// nothing is written.
+func HexDiff(w io.Writer, fromName, toName string, from, to io.Reader, context int) error {
+	a, err := HexDump(from)
+	if err != nil {
+		return err
+	}
+	b, err := HexDump(to)
+	if err != nil {
+		return err
+	}
+
+	return UnifiedDiff(w, fromName, toName, a, b, context)
+}
+
+// UnifiedDiff writes a unified diff between the lines "a" and "b" to "w",
+// labelling them "fromName" and "toName" respectively, and showing "context"
+// unchanged lines around each change. If both are identical, nothing is
+// written. If they differ by too many lines, ErrDiffTooLarge is returned and
+// nothing is written.
+func UnifiedDiff(w io.Writer, fromName, toName string, a, b []string, context int) error {
+	if context < 0 {
+		context = 0
+	}
+
+	edits, ok := diffLines(a, b, maxDiffEdits)
+	if !ok {
+		return ErrDiffTooLarge
+	}
+
+	hunks := diffHunks(edits, context)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", fromName, toName); err != nil {
+		return err
+	}
+
+	for _, h := range hunks {
+		if _, err := fmt.Fprintf(w, "@@ -%s +%s @@\n",
+			hunkRange(h.aStart, h.aLen), hunkRange(h.bStart, h.bLen)); err != nil {
+			return err
+		}
+
+		for _, e := range h.edits {
+			var line string
+			switch e.op {
+			case diffEqual:
+				line = " " + a[e.a]
+			case diffDelete:
+				line = "-" + a[e.a]
+			case diffInsert:
+				line = "+" + b[e.b]
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hunkRange formats the range of a hunk header as git-diff(1) does, where
+// "start" is zero-based.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// diffEdit is a single step of an edit script, referring to the line "a" of
+// the old input and/or "b" of the new input. An insertion's "a", and a
+// deletion's "b", is the line of the other input at which it applies.
+type diffEdit struct {
+	op   diffOp
+	a, b int
+}
+
+type diffHunk struct {
+	aStart, aLen int
+	bStart, bLen int
+	edits        []diffEdit
+}
+
+// diffLines returns a shortest edit script transforming "a" into "b", using
+// the linear space refinement of the algorithm described in "An O(ND)
+// Difference Algorithm and Its Variations" by Eugene W. Myers. If the script
+// would contain more than "maxEdits" insertions and deletions, it returns
+// false instead, since finding it would take time proportional to the square
+// of that number.
+func diffLines(a, b []string, maxEdits int) ([]diffEdit, bool) {
+	d := &differ{
+		a:     a,
+		b:     b,
+		edits: make([]diffEdit, 0, len(a)+len(b)),
+	}
+
+	// Only the first call to middleSnake can find the full edit distance,
+	// and it needs at most half as many steps as there are edits.
+	steps := (len(a) + len(b) + 1) / 2
+	if limit := (maxEdits + 1) / 2; limit < steps {
+		steps = limit
+	}
+	d.vf = make([]int, 2*steps+3)
+	d.vb = make([]int, 2*steps+3)
+
+	if !d.compare(0, len(a), 0, len(b), maxEdits) {
+		return nil, false
+	}
+	return d.edits, true
+}
+
+// differ holds the state of a single call to diffLines.
+type differ struct {
+	a, b []string
+	// vf and vb are the furthest reaching x positions on each diagonal
+	// of the forward and backward searches of middleSnake.
+	vf, vb []int
+	edits  []diffEdit
+}
+
+// compare appends the edit script transforming a[aLo:aHi] into b[bLo:bHi] to
+// d.edits. It returns false if that would take more than "maxEdits" edits.
+func (d *differ) compare(aLo, aHi, bLo, bHi, maxEdits int) bool {
+	// Strip the common prefix and suffix, which are cheap to find and
+	// shrink the search space considerably for typical inputs.
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.edits = append(d.edits, diffEdit{op: diffEqual, a: aLo, b: bLo})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-1-suffix] == d.b[bHi-1-suffix] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		if bHi-bLo > maxEdits {
+			return false
+		}
+		for y := bLo; y < bHi; y++ {
+			d.edits = append(d.edits, diffEdit{op: diffInsert, a: aLo, b: y})
+		}
+	case bLo == bHi:
+		if aHi-aLo > maxEdits {
+			return false
+		}
+		for x := aLo; x < aHi; x++ {
+			d.edits = append(d.edits, diffEdit{op: diffDelete, a: x, b: bLo})
+		}
+	default:
+		// Both ranges are non-empty, and differ in their first and
+		// last lines, so there are at least two edits, and both halves
+		// either side of the middle snake are smaller problems, taking
+		// no more edits than the whole.
+		edits, x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi, maxEdits)
+		if edits < 0 {
+			return false
+		}
+
+		d.compare(aLo, x, bLo, y, edits)
+		for ; x < u; x, y = x+1, y+1 {
+			d.edits = append(d.edits, diffEdit{op: diffEqual, a: x, b: y})
+		}
+		d.compare(u, aHi, v, bHi, edits)
+	}
+
+	for i := 0; i < suffix; i++ {
+		d.edits = append(d.edits, diffEdit{op: diffEqual, a: aHi + i, b: bHi + i})
+	}
+	return true
+}
+
+// middleSnake finds the middle snake of a shortest edit script transforming
+// a[aLo:aHi] into b[bLo:bHi], by searching forwards from the start and
+// backwards from the end at the same time until the two searches overlap. It
+// returns the length of the edit script, and the snake, which runs from
+// (x, y) to (u, v). If the script would be longer than "maxEdits", it returns
+// -1.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi, maxEdits int) (edits, x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+
+	steps := (n + m + 1) / 2
+	if (maxEdits+1)/2 < steps {
+		steps = (maxEdits + 1) / 2
+	}
+
+	// vf and vb are indexed by diagonal, offset so that diagonal -steps-1
+	// is at index 0. The backward search measures x and y from the end.
+	off := steps + 1
+	vf, vb := d.vf, d.vb
+	vf[off+1], vb[off+1] = 0, 0
+
+	for step := 0; step <= steps; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+
+			// The backward search has taken one step fewer, and
+			// reached diagonals delta-(step-1) to delta+(step-1).
+			if odd && k >= delta-(step-1) && k <= delta+(step-1) && x+vb[off+delta-k] >= n {
+				return checkEdits(2*step-1, maxEdits, aLo+x0, bLo+y0, aLo+x, bLo+y)
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+
+			x0, y0 := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+
+			// Diagonal k of the backward search is delta-k of the
+			// forward search, which has taken as many steps.
+			if !odd && k >= delta-step && k <= delta+step && x+vf[off+delta-k] >= n {
+				return checkEdits(2*step, maxEdits, aHi-x, bHi-y, aHi-x0, bHi-y0)
+			}
+		}
+	}
+	return -1, 0, 0, 0, 0
+}
+
+// checkEdits returns its arguments, unless "edits" is more than "maxEdits", in
+// which case it returns -1 edits.
+func checkEdits(edits, maxEdits, x, y, u, v int) (int, int, int, int, int) {
+	if edits > maxEdits {
+		return -1, 0, 0, 0, 0
+	}
+	return edits, x, y, u, v
+}
+
+// diffHunks groups the edit script "edits" into hunks, each surrounded by at
+// most "context" unchanged lines. Changes separated by no more than twice
+// that many unchanged lines share a hunk.
+func diffHunks(edits []diffEdit, context int) []*diffHunk {
+	var hunks []*diffHunk
+
+	i := 0
+	for i < len(edits) {
+		// Find the next change.
+		for i < len(edits) && edits[i].op == diffEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		// Hunks are separated by more than 2*context unchanged lines,
+		// so this never overlaps with the previous hunk.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// Extend the hunk until a run of more than 2*context unchanged
+		// lines, or the end of the script.
+		end := i
+		for end < len(edits) {
+			if edits[end].op != diffEqual {
+				end++
+				continue
+			}
+
+			run := end
+			for run < len(edits) && edits[run].op == diffEqual {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+
+		stop := end + context
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+
+		// Each edit records the lines of both inputs at which it
+		// applies, even those only inserting or deleting a line.
+		h := &diffHunk{
+			aStart: edits[start].a,
+			bStart: edits[start].b,
+			edits:  edits[start:stop],
+		}
+		for _, e := range h.edits {
+			if e.op != diffInsert {
+				h.aLen++
+			}
+			if e.op != diffDelete {
+				h.bLen++
+			}
+		}
+
+		hunks = append(hunks, h)
+		i = stop
+	}
+
+	return hunks
+}
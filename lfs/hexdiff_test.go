@@ -0,0 +1,190 @@
+package lfs
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexDumpFormatsLines(t *testing.T) {
+	lines, err := HexDump(strings.NewReader("Hello, world!\x00\x01\x02\x03\xff"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"00000000: 4865 6c6c 6f2c 2077 6f72 6c64 2100 0102  Hello, world!...",
+		"00000010: 03ff                                     ..",
+	}, lines)
+}
+
+func TestHexDumpEmpty(t *testing.T) {
+	lines, err := HexDump(strings.NewReader(""))
+
+	assert.Nil(t, err)
+	assert.Empty(t, lines)
+}
+
+func TestUnifiedDiffIdentical(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := UnifiedDiff(&buf, "a/x", "b/x", []string{"1", "2"}, []string{"1", "2"}, 3)
+
+	assert.Nil(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestUnifiedDiffSeparatesDistantHunks(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}
+	b := []string{"1", "two", "3", "4", "5", "6", "7", "8", "9", "10", "11"}
+
+	var buf bytes.Buffer
+	err := UnifiedDiff(&buf, "a/x", "b/x", a, b, 1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"--- a/x",
+		"+++ b/x",
+		"@@ -1,3 +1,3 @@",
+		" 1",
+		"-2",
+		"+two",
+		" 3",
+		"@@ -10 +10,2 @@",
+		" 10",
+		"+11",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestUnifiedDiffFromEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	err := UnifiedDiff(&buf, "a/x", "b/x", nil, []string{"1", "2"}, 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "--- a/x\n+++ b/x\n@@ -0,0 +1,2 @@\n+1\n+2\n", buf.String())
+}
+
+func TestDiffLinesProducesValidEditScript(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+
+	for i := 0; i < 200; i++ {
+		a := make([]string, r.Intn(20))
+		for j := range a {
+			a[j] = alphabet[r.Intn(len(alphabet))]
+		}
+		b := make([]string, r.Intn(20))
+		for j := range b {
+			b[j] = alphabet[r.Intn(len(alphabet))]
+		}
+
+		var fromA, toB []string
+		var equal int
+		edits, ok := diffLines(a, b, len(a)+len(b))
+		assert.True(t, ok)
+		for _, e := range edits {
+			switch e.op {
+			case diffEqual:
+				equal++
+				assert.Equal(t, a[e.a], b[e.b])
+				fromA = append(fromA, a[e.a])
+				toB = append(toB, b[e.b])
+			case diffDelete:
+				fromA = append(fromA, a[e.a])
+			case diffInsert:
+				toB = append(toB, b[e.b])
+			}
+		}
+
+		assert.Equal(t, len(a), len(fromA))
+		assert.Equal(t, len(b), len(toB))
+		assert.Equal(t, len(a)+len(b)-2*lcsLength(a, b), len(edits)-equal)
+		for j := range a {
+			assert.Equal(t, a[j], fromA[j])
+		}
+		for j := range b {
+			assert.Equal(t, b[j], toB[j])
+		}
+	}
+}
+
+// lcsLength returns the length of the longest common subsequence of "a" and
+// "b", the number of lines a shortest edit script leaves unchanged.
+func lcsLength(a, b []string) int {
+	l := make([][]int, len(a)+1)
+	for i := range l {
+		l[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				l[i][j] = l[i+1][j+1] + 1
+			} else if l[i+1][j] > l[i][j+1] {
+				l[i][j] = l[i+1][j]
+			} else {
+				l[i][j] = l[i][j+1]
+			}
+		}
+	}
+	return l[0][0]
+}
+
+func TestDiffLinesStopsAtMaxEdits(t *testing.T) {
+	a := []string{"1", "2", "3", "4"}
+	b := []string{"1", "two", "three", "4"}
+
+	_, ok := diffLines(a, b, 3)
+	assert.False(t, ok)
+
+	edits, ok := diffLines(a, b, 4)
+	assert.True(t, ok)
+	assert.Len(t, edits, 6)
+}
+
+func TestHexDiff(t *testing.T) {
+	var buf bytes.Buffer
+	err := HexDiff(&buf, "a/db", "b/db",
+		strings.NewReader("0123456789abcdef0123456789abcdef"),
+		strings.NewReader("0123456789abcdef0123456789abcdeF"), DefaultDiffContext)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"--- a/db",
+		"+++ b/db",
+		"@@ -1,2 +1,2 @@",
+		" 00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef",
+		"-00000010: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef",
+		"+00000010: 3031 3233 3435 3637 3839 6162 6364 6546  0123456789abcdeF",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestHexDiffLargeFileWithChangedByte(t *testing.T) {
+	from := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(1)).Read(from)
+	to := append([]byte{}, from...)
+	to[512*1024] ^= 0xff
+
+	var buf bytes.Buffer
+	err := HexDiff(&buf, "a/db", "b/db", bytes.NewReader(from), bytes.NewReader(to), DefaultDiffContext)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n-"))
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n+0"))
+}
+
+func TestHexDiffLargeFileWithInsertedByte(t *testing.T) {
+	from := make([]byte, 10*1024*1024)
+	rand.New(rand.NewSource(1)).Read(from)
+	to := append([]byte{0}, from...)
+
+	// Every line of the hex dump is shifted, which is too many changes to
+	// show, and must not take quadratic time or memory to find out.
+	var buf bytes.Buffer
+	err := HexDiff(&buf, "a/db", "b/db", bytes.NewReader(from), bytes.NewReader(to), DefaultDiffContext)
+
+	assert.Equal(t, ErrDiffTooLarge, err)
+	assert.Empty(t, buf.String())
+}
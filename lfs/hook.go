@@ -14,10 +14,64 @@ import (
 )
 
 var (
-	// The basic hook which just calls 'git lfs TYPE'
-	hookBaseContent = "#!/bin/sh\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/{{Command}}.\\n\"; exit 2; }\ngit lfs {{Command}} \"$@\""
+	// hookLocateContent finds the git-lfs program, trying in turn
+	// $GIT_LFS_PATH, Git's exec path, the PATH, and the path recorded in
+	// lfs.binarypath by `git lfs install`, so that hooks still work when Git
+	// is run with a minimal PATH, as by some GUI clients. Git for Windows'
+	// sh treats "git-lfs" as "git-lfs.exe" when running it, but not always
+	// when testing it, so both are tested. If git-lfs is not found, the
+	// hook lists what was tried and fails.
+	hookLocateContent = `#!/bin/sh
+tried=
+for source in GIT_LFS_PATH exec-path PATH lfs.binarypath; do
+case $source in
+GIT_LFS_PATH) git_lfs="$GIT_LFS_PATH" ;;
+exec-path) git_lfs="$(git --exec-path)/git-lfs" ;;
+PATH) git_lfs="$(command -v git-lfs)" ;;
+lfs.binarypath) git_lfs="$(git config lfs.binarypath)" ;;
+esac
+[ -n "$git_lfs" ] && { [ -x "$git_lfs" ] || [ -x "$git_lfs.exe" ]; } && break
+tried="$tried  $source: ${git_lfs:-(none)}\n"
+git_lfs=
+done
+[ -n "$git_lfs" ] || { printf >&2 '\nThis repository is configured for Git LFS but git-lfs was not found. Tried:\n%b\nSet GIT_LFS_PATH or lfs.binarypath to the path of git-lfs. If you no longer wish to use Git LFS, {{Remedy}}.\n\n' "$tried"; exit 2; }`
+
+	// The basic hook which just calls 'git-lfs TYPE'
+	hookBaseContent = strings.Replace(hookLocateContent, "{{Remedy}}", "remove this hook by deleting .git/hooks/{{Command}}", 1) + `
+"$git_lfs" {{Command}} "$@"`
+
+	// The hook installed in place of an existing, non-LFS hook, which is
+	// moved aside to TYPE.git-lfs-chained. Both hooks are given the same
+	// arguments and standard input, and the hook fails if either does.
+	hookChainedContent = strings.Replace(hookLocateContent, "{{Remedy}}", "replace .git/hooks/{{Command}} with .git/hooks/{{Command}}"+hookChainedSuffix, 1) + `
+input=$(cat; echo x)
+chained="$(dirname "$0")/{{Command}}` + hookChainedSuffix + `"
+status=0
+if [ -x "$chained" ]; then
+printf '%s' "${input%x}" | "$chained" "$@" || status=$?
+fi
+printf '%s' "${input%x}" | "$git_lfs" {{Command}} "$@" || exit $?
+exit $status`
+
+	// Hooks written by older versions of Git LFS for any type of hook,
+	// which are upgraded or removed as if they were the current one.
+	hookLegacyContents = []string{
+		"#!/bin/sh\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository has been set up with Git LFS but Git LFS is not installed.\\n\"; exit 0; }\ngit lfs {{Command}} \"$@\"",
+		"#!/bin/sh\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository has been set up with Git LFS but Git LFS is not installed.\\n\"; exit 2; }\ngit lfs {{Command}} \"$@\"",
+		"#!/bin/sh\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/{{Command}}.\\n\"; exit 2; }\ngit lfs {{Command}} \"$@\"",
+	}
+
+	// Chained hooks written by older versions of Git LFS, which are
+	// upgraded to the current chained hook.
+	hookLegacyChainedContents = []string{
+		"#!/bin/sh\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, replace .git/hooks/{{Command}} with .git/hooks/{{Command}}" + hookChainedSuffix + ".\\n\"; exit 2; }\ninput=$(cat; echo x)\nchained=\"$(dirname \"$0\")/{{Command}}" + hookChainedSuffix + "\"\nstatus=0\nif [ -x \"$chained\" ]; then\nprintf '%s' \"${input%x}\" | \"$chained\" \"$@\" || status=$?\nfi\nprintf '%s' \"${input%x}\" | git lfs {{Command}} \"$@\" || exit $?\nexit $status",
+	}
 )
 
+// hookChainedSuffix is appended to the name of an existing hook when it is
+// moved aside to be run by the Git LFS hook.
+const hookChainedSuffix = ".git-lfs-chained"
+
 // A Hook represents a githook as described in http://git-scm.com/docs/githooks.
 // Hooks have a type, which is the type of hook that they are, and a body, which
 // represents the thing they will execute when invoked by Git.
@@ -30,7 +84,7 @@ type Hook struct {
 }
 
 func LoadHooks(hookDir string, cfg *config.Configuration) []*Hook {
-	return []*Hook{
+	hooks := []*Hook{
 		NewStandardHook("pre-push", hookDir, []string{
 			"#!/bin/sh\ngit lfs push --stdin $*",
 			"#!/bin/sh\ngit lfs push --stdin \"$@\"",
@@ -42,10 +96,37 @@ func LoadHooks(hookDir string, cfg *config.Configuration) []*Hook {
 		NewStandardHook("post-commit", hookDir, []string{}, cfg),
 		NewStandardHook("post-merge", hookDir, []string{}, cfg),
 	}
+
+	if PreCommitHookEnabled(cfg) {
+		hooks = append(hooks, NewPreCommitHook(hookDir, cfg))
+	}
+	return hooks
+}
+
+// PreCommitHookEnabled returns whether or not the optional pre-commit hook has
+// any checks to run, because lfs.precommitsizecheck or lfs.maxobjectsize is
+// set.
+func PreCommitHookEnabled(cfg *config.Configuration) bool {
+	if cfg.Git.Bool("lfs.precommitsizecheck", false) {
+		return true
+	}
+	v, ok := cfg.Git.Get("lfs.maxobjectsize")
+	return ok && len(v) > 0
+}
+
+// NewPreCommitHook creates the optional pre-commit hook, which guards against
+// committing large files that are not tracked by Git LFS, and Git LFS files
+// larger than lfs.maxobjectsize.
+func NewPreCommitHook(hookDir string, cfg *config.Configuration) *Hook {
+	return NewStandardHook("pre-commit", hookDir, []string{}, cfg)
 }
 
 // NewStandardHook creates a new hook using the template script calling 'git lfs theType'
 func NewStandardHook(theType, hookDir string, upgradeables []string, cfg *config.Configuration) *Hook {
+	for _, legacy := range hookLegacyContents {
+		upgradeables = append(upgradeables, strings.Replace(legacy, "{{Command}}", theType, -1))
+	}
+
 	return &Hook{
 		Type:         theType,
 		Contents:     strings.Replace(hookBaseContent, "{{Command}}", theType, -1),
@@ -67,6 +148,18 @@ func (h *Hook) Path() string {
 	return filepath.Join(h.Dir, h.Type)
 }
 
+// ChainedPath returns the location to which an existing, non-LFS hook is
+// moved when this hook is installed in its place.
+func (h *Hook) ChainedPath() string {
+	return h.Path() + hookChainedSuffix
+}
+
+// chainedContents returns the contents of this hook when it also runs the
+// hook at ChainedPath().
+func (h *Hook) chainedContents() string {
+	return strings.Replace(hookChainedContent, "{{Command}}", h.Type, -1)
+}
+
 // Install installs this Git hook on disk, or upgrades it if it does exist, and
 // is upgradeable. It will create a hooks directory relative to the local Git
 // directory. It returns and halts at any errors, and returns nil if the
@@ -88,74 +181,193 @@ func (h *Hook) Install(force bool) error {
 }
 
 // write writes the contents of this Hook to disk, appending a newline at the
-// end, and sets the mode to octal 0755. It writes to disk unconditionally, and
-// returns at any error.
+// end, and sets the mode to the executable permissions given by
+// core.sharedRepository. It writes to disk unconditionally, and returns at any
+// error.
 func (h *Hook) write() error {
-	return ioutil.WriteFile(h.Path(), []byte(h.Contents+"\n"), 0755)
+	return h.writeContents(h.Contents)
+}
+
+func (h *Hook) writeContents(contents string) error {
+	perms := h.cfg.RepositoryPermissions(true)
+	if err := ioutil.WriteFile(h.Path(), []byte(contents+"\n"), perms); err != nil {
+		return err
+	}
+	return os.Chmod(h.Path(), perms)
 }
 
 // Upgrade upgrades the (assumed to be) existing git hook to the current
 // contents. A hook is considered "upgrade-able" if its contents are matched in
-// the member variable `Upgradeables`. It halts and returns any errors as they
-// arise.
+// the member variable `Upgradeables`. Any other existing hook is moved aside to
+// ChainedPath() and run by the hook which replaces it, unless a hook has
+// already been moved there. It halts and returns any errors as they arise.
 func (h *Hook) Upgrade() error {
-	match, err := h.matchesCurrent()
+	contents, err := h.contents()
 	if err != nil {
 		return err
 	}
 
-	if !match {
+	switch {
+	case contents == h.Contents || contents == h.chainedContents():
+		// Already up to date, so there is no need to be able to
+		// write to a shared hooks directory.
 		return nil
+	case h.matches(contents):
+		return h.write()
+	case h.matchesChained(contents):
+		return h.writeContents(h.chainedContents())
 	}
 
-	return h.write()
+	if h.Chained() {
+		return fmt.Errorf("Hook already exists: %s\n\n%s\n", string(h.Type), tools.Indent(contents))
+	}
+
+	tracerx.Printf("Install hook: %s, chaining existing hook to %s", h.Type, h.ChainedPath())
+	if err := os.Rename(h.Path(), h.ChainedPath()); err != nil {
+		return err
+	}
+	return h.writeContents(h.chainedContents())
 }
 
 // Uninstall removes the hook on disk so long as it matches the current version,
-// or any of the past versions of this hook.
+// or any of the past versions of this hook, and restores any hook which was
+// chained to it.
 func (h *Hook) Uninstall() error {
 	msg := fmt.Sprintf("Uninstall hook: %s, path=%s", h.Type, h.Path())
 
-	match, err := h.matchesCurrent()
+	if !h.Exists() {
+		tracerx.Printf(msg + ", doesn't exist...")
+		return nil
+	}
+
+	contents, err := h.contents()
 	if err != nil {
 		return err
 	}
 
-	if !match {
+	if !h.matches(contents) && !h.matchesChained(contents) {
 		tracerx.Printf(msg + ", doesn't match...")
 		return nil
 	}
 
 	tracerx.Printf(msg)
-	return os.RemoveAll(h.Path())
+	if err := os.RemoveAll(h.Path()); err != nil {
+		return err
+	}
+
+	if h.Chained() {
+		tracerx.Printf("Uninstall hook: %s, restoring %s", h.Type, h.ChainedPath())
+		return os.Rename(h.ChainedPath(), h.Path())
+	}
+	return nil
 }
 
-// matchesCurrent returns whether or not an existing git hook is able to be
-// written to or upgraded. A git hook matches those conditions if and only if
-// its contents match the current contents, or any past "upgrade-able" contents
-// of this hook.
-func (h *Hook) matchesCurrent() (bool, error) {
+// Installed returns whether or not the existing hook was installed by Git LFS,
+// either on its own or chained to another hook.
+func (h *Hook) Installed() bool {
+	contents, err := h.contents()
+	if err != nil {
+		return false
+	}
+	return h.matches(contents) || h.matchesChained(contents)
+}
+
+// HookState describes the hook at a Hook's path, if any, as Git LFS sees it.
+type HookState string
+
+const (
+	// HookMissing means that there is no hook.
+	HookMissing HookState = "missing"
+	// HookInstalled means that the hook is the current Git LFS hook,
+	// either on its own or chained to another hook.
+	HookInstalled HookState = "installed"
+	// HookOutdated means that the hook was written by an older version of
+	// Git LFS, or is empty, and so can be upgraded without losing
+	// anything.
+	HookOutdated HookState = "outdated"
+	// HookForeign means that the hook was not written by Git LFS.
+	HookForeign HookState = "foreign"
+)
+
+// State returns the state of the existing hook, if any.
+func (h *Hook) State() HookState {
+	if !h.Exists() {
+		return HookMissing
+	}
+
+	contents, err := h.contents()
+	switch {
+	case err != nil:
+		return HookForeign
+	case contents == h.Contents || contents == h.chainedContents():
+		return HookInstalled
+	case h.matches(contents) || h.matchesChained(contents):
+		return HookOutdated
+	default:
+		return HookForeign
+	}
+}
+
+// Outdated returns whether or not the existing hook was written by an older
+// version of Git LFS, and so can be upgraded without losing anything.
+func (h *Hook) Outdated() bool {
+	return h.State() == HookOutdated
+}
+
+// Chained returns whether or not an existing hook has been moved aside to be
+// run by this one.
+func (h *Hook) Chained() bool {
+	_, err := os.Lstat(h.ChainedPath())
+	return err == nil
+}
+
+// contents returns the normalized contents of the existing hook, without
+// indentation, carriage returns or surrounding whitespace.
+func (h *Hook) contents() (string, error) {
 	file, err := os.Open(h.Path())
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	by, err := ioutil.ReadAll(io.LimitReader(file, 1024))
+	by, err := ioutil.ReadAll(io.LimitReader(file, 2048))
 	file.Close()
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	contents := strings.TrimSpace(tools.Undent(string(by)))
+	contents := strings.Replace(string(by), "\r\n", "\n", -1)
+	return strings.TrimSpace(tools.Undent(contents)), nil
+}
+
+// matches returns whether or not the contents of an existing git hook are able
+// to be overwritten. A git hook matches if and only if its contents are empty,
+// or match the current contents or any past "upgrade-able" contents of this
+// hook.
+func (h *Hook) matches(contents string) bool {
 	if contents == h.Contents || len(contents) == 0 {
-		return true, nil
+		return true
 	}
 
 	for _, u := range h.upgradeables {
 		if u == contents {
-			return true, nil
+			return true
 		}
 	}
+	return false
+}
 
-	return false, fmt.Errorf("Hook already exists: %s\n\n%s\n", string(h.Type), tools.Indent(contents))
+// matchesChained returns whether or not the contents of an existing git hook
+// are the current or any past contents of this hook when it runs a chained
+// hook.
+func (h *Hook) matchesChained(contents string) bool {
+	if contents == h.chainedContents() {
+		return true
+	}
+
+	for _, legacy := range hookLegacyChainedContents {
+		if contents == strings.Replace(legacy, "{{Command}}", h.Type, -1) {
+			return true
+		}
+	}
+	return false
 }
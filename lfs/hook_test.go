@@ -0,0 +1,162 @@
+package lfs
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// When run as a fake git-lfs by the hooks below, the test binary prints the
+// path it was run by and its arguments, and exits.
+func init() {
+	if os.Getenv("GIT_LFS_HOOK_TEST_FAKE") == "1" {
+		input, _ := ioutil.ReadAll(os.Stdin)
+		fmt.Printf("%s %s %q\n", filepath.Base(filepath.Dir(os.Args[0])), strings.Join(os.Args[1:], " "), input)
+		os.Exit(0)
+	}
+}
+
+// hookTest is a directory holding a hook, and directories named after each
+// place the hook looks for git-lfs, which may each hold a fake git-lfs.
+type hookTest struct {
+	t   *testing.T
+	dir string
+	env []string
+}
+
+func newHookTest(t *testing.T, contents string) *hookTest {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not found")
+	}
+
+	dir, err := ioutil.TempDir("", "hook")
+	require.Nil(t, err)
+	for _, d := range []string{"hooks", "env", "exec", "path", "config", "git"} {
+		require.Nil(t, os.Mkdir(filepath.Join(dir, d), 0755))
+	}
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "hooks", "pre-push"), []byte(contents+"\n"), 0755))
+
+	// Only git, and the "path" directory, are on the PATH, so that any
+	// real git-lfs beside git is not found.
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "git", "git"),
+		[]byte(fmt.Sprintf("#!/bin/sh\nexec '%s' \"$@\"\n", filepath.ToSlash(gitPath))), 0755))
+
+	return &hookTest{t: t, dir: dir, env: []string{
+		"GIT_LFS_HOOK_TEST_FAKE=1",
+		"GIT_EXEC_PATH=" + filepath.Join(dir, "exec"),
+		"PATH=" + filepath.Join(dir, "git") + string(os.PathListSeparator) + filepath.Join(dir, "path"),
+		"GIT_CONFIG_NOSYSTEM=1",
+		"HOME=" + dir,
+	}}
+}
+
+// fake installs the test binary as git-lfs in the directory "name".
+func (h *hookTest) fake(name string) string {
+	exe, err := os.Executable()
+	require.Nil(h.t, err)
+
+	path := filepath.Join(h.dir, name, "git-lfs")
+	if runtime.GOOS == "windows" {
+		// Git for Windows' sh runs "git-lfs" as "git-lfs.exe".
+		copyExecutable(h.t, exe, path+".exe")
+	} else {
+		copyExecutable(h.t, exe, path)
+	}
+	return path
+}
+
+func (h *hookTest) run(env ...string) (string, error) {
+	cmd := exec.Command("sh", filepath.Join(h.dir, "hooks", "pre-push"), "origin", "url")
+	cmd.Env = append(h.env, env...)
+	cmd.Stdin = strings.NewReader("refs\n")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func (h *hookTest) Close() {
+	os.RemoveAll(h.dir)
+}
+
+func copyExecutable(t *testing.T, from, to string) {
+	src, err := os.Open(from)
+	require.Nil(t, err)
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	require.Nil(t, err)
+	_, err = io.Copy(dst, src)
+	require.Nil(t, err)
+	require.Nil(t, dst.Close())
+}
+
+func TestHookLocatesGitLFS(t *testing.T) {
+	h := newHookTest(t, NewStandardHook("pre-push", "", nil, config.NewIn("", "")).Contents)
+	defer h.Close()
+
+	binaryPath := "GIT_CONFIG_PARAMETERS='lfs.binarypath=" + filepath.ToSlash(h.fake("config")) + "'"
+	out, err := h.run(binaryPath)
+	require.Nil(t, err, out)
+	assert.Equal(t, "config pre-push origin url \"refs\\n\"\n", out)
+
+	h.fake("path")
+	out, err = h.run(binaryPath)
+	require.Nil(t, err, out)
+	assert.Equal(t, "path pre-push origin url \"refs\\n\"\n", out)
+
+	h.fake("exec")
+	out, err = h.run(binaryPath)
+	require.Nil(t, err, out)
+	assert.Equal(t, "exec pre-push origin url \"refs\\n\"\n", out)
+
+	out, err = h.run(binaryPath, "GIT_LFS_PATH="+h.fake("env"))
+	require.Nil(t, err, out)
+	assert.Equal(t, "env pre-push origin url \"refs\\n\"\n", out)
+}
+
+func TestHookReportsMissingGitLFS(t *testing.T) {
+	h := newHookTest(t, NewStandardHook("pre-push", "", nil, config.NewIn("", "")).Contents)
+	defer h.Close()
+
+	missing := filepath.ToSlash(filepath.Join(h.dir, "missing", "git-lfs"))
+	out, err := h.run("GIT_LFS_PATH="+missing, "GIT_CONFIG_PARAMETERS='lfs.binarypath="+missing+"'")
+	require.NotNil(t, err)
+	if exitErr, ok := err.(*exec.ExitError); ok && runtime.GOOS != "windows" {
+		assert.Equal(t, "exit status 2", exitErr.Error())
+	}
+
+	assert.Contains(t, out, "This repository is configured for Git LFS but git-lfs was not found. Tried:\n")
+	assert.Contains(t, out, "  GIT_LFS_PATH: "+missing+"\n")
+	assert.Contains(t, out, "  exec-path: "+filepath.ToSlash(filepath.Join(h.dir, "exec", "git-lfs"))+"\n")
+	assert.Contains(t, out, "  PATH: (none)\n")
+	assert.Contains(t, out, "  lfs.binarypath: "+missing+"\n")
+	assert.Contains(t, out, "remove this hook by deleting .git/hooks/pre-push.")
+}
+
+func TestChainedHookLocatesGitLFS(t *testing.T) {
+	hook := NewStandardHook("pre-push", "", nil, config.NewIn("", ""))
+	h := newHookTest(t, hook.chainedContents())
+	defer h.Close()
+
+	// The chained hook needs cat and dirname, as well as git.
+	h.env[2] += string(os.PathListSeparator) + os.Getenv("PATH")
+	require.Nil(t, ioutil.WriteFile(filepath.Join(h.dir, "hooks", "pre-push"+hookChainedSuffix),
+		[]byte("#!/bin/sh\necho \"chained $* $(cat)\"\n"), 0755))
+
+	out, err := h.run("GIT_LFS_PATH=" + h.fake("env"))
+	require.Nil(t, err, out)
+	assert.Equal(t, "chained origin url refs\nenv pre-push origin url \"refs\\n\"\n", out)
+}
@@ -112,9 +112,14 @@ func LinkOrCopyFromReference(cfg *config.Configuration, oid string, size int64)
 		if altMediafile != "" && tools.FileExistsOfSize(altMediafile, size) {
 			err = LinkOrCopy(cfg, altMediafile, mediafile)
 			if err == nil {
-				break
+				cfg.Filesystem().RecordObjectAdded(size)
+				return nil
 			}
 		}
 	}
+	if copyFromExtraObjectStores(cfg, oid, size, mediafile) {
+		cfg.Filesystem().RecordObjectAdded(size)
+		return nil
+	}
 	return err
 }
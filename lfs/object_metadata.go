@@ -0,0 +1,58 @@
+package lfs
+
+import (
+	"os"
+	"time"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/git-lfs/git-lfs/errors"
+)
+
+// ObjectMetadata describes an object in the local object store, as returned
+// by StatObject.
+type ObjectMetadata struct {
+	Oid string
+	// Path is where the object is stored, or would be if it existed.
+	Path string
+	// Exists is whether the object is present. Size and ModTime are only
+	// set if it is.
+	Exists  bool
+	Size    int64
+	ModTime time.Time
+	// Verified is whether the contents of the object have been hashed and
+	// found to match its OID. StatObject does not hash objects, so it is
+	// left for the caller to set.
+	Verified bool
+}
+
+// HasSize returns whether the object exists and is "size" bytes long, which
+// is how the object store is checked for an object before it is downloaded.
+func (m ObjectMetadata) HasSize(size int64) bool {
+	return m.Exists && m.Size == size
+}
+
+// StatObject returns the metadata of the object "oid" in the local object
+// store. An object which does not exist is not an error, but one which cannot
+// be examined, or which is not a regular file, is.
+func StatObject(cfg *config.Configuration, oid string) (ObjectMetadata, error) {
+	meta := ObjectMetadata{
+		Oid:  oid,
+		Path: cfg.Filesystem().ObjectPathname(oid),
+	}
+
+	info, err := os.Stat(meta.Path)
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return meta, errors.Wrapf(err, "could not examine object %s", oid)
+	}
+	if !info.Mode().IsRegular() {
+		return meta, errors.Errorf("object %s is not a regular file: %s", oid, meta.Path)
+	}
+
+	meta.Exists = true
+	meta.Size = info.Size()
+	meta.ModTime = info.ModTime()
+	return meta, nil
+}
@@ -0,0 +1,67 @@
+package lfs_test // avoid import cycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/lfs"
+	test "github.com/git-lfs/git-lfs/t/cmd/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatObject(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	ptr := writeTestObject(t, repo, "stat contents")
+
+	meta, err := lfs.StatObject(repo.Configuration(), ptr.Oid)
+	require.Nil(t, err)
+	assert.Equal(t, ptr.Oid, meta.Oid)
+	assert.Equal(t, repo.Filesystem().ObjectPathname(ptr.Oid), meta.Path)
+	assert.True(t, meta.Exists)
+	assert.Equal(t, ptr.Size, meta.Size)
+	assert.False(t, meta.ModTime.IsZero())
+	assert.False(t, meta.Verified)
+	assert.True(t, meta.HasSize(ptr.Size))
+	assert.False(t, meta.HasSize(ptr.Size+1))
+}
+
+func TestStatObjectMissing(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	oid := "0000000000000000000000000000000000000000000000000000000000000000"
+	meta, err := lfs.StatObject(repo.Configuration(), oid)
+	require.Nil(t, err)
+	assert.False(t, meta.Exists)
+	assert.Equal(t, repo.Filesystem().ObjectPathname(oid), meta.Path)
+	assert.False(t, meta.HasSize(0))
+}
+
+func TestStatObjectDirectory(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	oid := "1111111111111111111111111111111111111111111111111111111111111111"
+	path := repo.Filesystem().ObjectPathname(oid)
+	require.Nil(t, os.MkdirAll(filepath.Join(path, "inside"), 0755))
+
+	meta, err := lfs.StatObject(repo.Configuration(), oid)
+	assert.NotNil(t, err)
+	assert.False(t, meta.Exists)
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/gitobj"
 )
 
 var (
@@ -42,6 +43,9 @@ type PointerExtension struct {
 	Priority int
 	Oid      string
 	OidType  string
+	// Size is the size of the input to the extension, or zero if it is
+	// not recorded, as only the built-in compress extension does.
+	Size int64
 }
 
 type ByPriority []*PointerExtension
@@ -55,7 +59,7 @@ func NewPointer(oid string, size int64, exts []*PointerExtension) *Pointer {
 }
 
 func NewPointerExtension(name string, priority int, oid string) *PointerExtension {
-	return &PointerExtension{name, priority, oid, oidType}
+	return &PointerExtension{name, priority, oid, oidType, 0}
 }
 
 func (p *Pointer) Encode(writer io.Writer) (int, error) {
@@ -70,7 +74,11 @@ func (p *Pointer) Encoded() string {
 	var buffer bytes.Buffer
 	buffer.WriteString(fmt.Sprintf("version %s\n", latest))
 	for _, ext := range p.Extensions {
-		buffer.WriteString(fmt.Sprintf("ext-%d-%s %s:%s\n", ext.Priority, ext.Name, ext.OidType, ext.Oid))
+		buffer.WriteString(fmt.Sprintf("ext-%d-%s %s:%s", ext.Priority, ext.Name, ext.OidType, ext.Oid))
+		if ext.Size > 0 {
+			buffer.WriteString(fmt.Sprintf(" %d", ext.Size))
+		}
+		buffer.WriteString("\n")
 	}
 	buffer.WriteString(fmt.Sprintf("oid %s:%s\n", p.OidType, p.Oid))
 	buffer.WriteString(fmt.Sprintf("size %d\n", p.Size))
@@ -97,6 +105,17 @@ func DecodePointerFromFile(file string) (*Pointer, error) {
 	defer f.Close()
 	return DecodePointer(f)
 }
+
+// DecodePointerFromBlob decodes a *Pointer from the given *gitobj.Blob,
+// without reading the contents of blobs which are too large to be pointers.
+func DecodePointerFromBlob(b *gitobj.Blob) (*Pointer, error) {
+	// Check size before reading
+	if b.Size >= blobSizeCutoff {
+		return nil, errors.NewNotAPointerError(errors.New("blob size exceeds lfs pointer size cutoff"))
+	}
+	return DecodePointer(b.Contents)
+}
+
 func DecodePointer(reader io.Reader) (*Pointer, error) {
 	p, _, err := DecodeFrom(reader)
 	return p, err
@@ -215,12 +234,24 @@ func parsePointerExtension(key string, value string) (*PointerExtension, error)
 
 	name := keyParts[2]
 
+	// The oid may be followed by the size of the extension's input.
+	var size int64
+	if i := strings.IndexByte(value, ' '); i >= 0 {
+		size, err = strconv.ParseInt(value[i+1:], 10, 64)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("invalid size for extension %q: %q", name, value[i+1:])
+		}
+		value = value[:i]
+	}
+
 	oid, err := parseOid(value)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewPointerExtension(name, p, oid), nil
+	ext := NewPointerExtension(name, p, oid)
+	ext.Size = size
+	return ext, nil
 }
 
 func validatePointerExtensions(exts []*PointerExtension) error {
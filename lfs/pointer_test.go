@@ -127,6 +127,25 @@ size 12345`
 	assertEqualWithExample(t, ex, "sha256", p.Extensions[2].OidType)
 }
 
+func TestEncodeDecodeExtensionSize(t *testing.T) {
+	ext := NewPointerExtension("compress", 0, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	ext.Size = 54321
+	pointer := NewPointer("4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393", 12345, []*PointerExtension{ext})
+
+	ex := `version https://git-lfs.github.com/spec/v1
+ext-0-compress sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff 54321
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345
+`
+	assert.Equal(t, ex, pointer.Encoded())
+
+	p, err := DecodePointer(bytes.NewBufferString(ex))
+	assertEqualWithExample(t, ex, nil, err)
+	assertEqualWithExample(t, ex, "compress", p.Extensions[0].Name)
+	assertEqualWithExample(t, ex, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", p.Extensions[0].Oid)
+	assertEqualWithExample(t, ex, int64(54321), p.Extensions[0].Size)
+}
+
 func TestDecodeExtensionsSort(t *testing.T) {
 	ex := `version https://git-lfs.github.com/spec/v1
 ext-2-baz sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
@@ -199,6 +218,12 @@ size 12345`,
 		`version https://git-lfs.github.com/spec/v1
 size 12345`,
 
+		// bad extension size
+		`version https://git-lfs.github.com/spec/v1
+ext-0-compress sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff big
+oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
+size 12345`,
+
 		// bad version
 		`version http://git-media.io/v/whatever
 oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393
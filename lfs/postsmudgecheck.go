@@ -0,0 +1,197 @@
+package lfs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/git-lfs/git-lfs/config"
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/subprocess"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// defaultPostSmudgeCheckConcurrency is how many lfs.postsmudgecheck
+	// commands may run at once, unless lfs.postsmudgecheckconcurrency gives
+	// another number.
+	defaultPostSmudgeCheckConcurrency = 4
+	// defaultPostSmudgeCheckMaxFailures is how many files
+	// lfs.postsmudgecheck may reject before the operation is aborted,
+	// unless lfs.postsmudgecheckmaxfailures gives another number.
+	defaultPostSmudgeCheckMaxFailures = 10
+)
+
+// ErrTooManyQuarantined is returned once lfs.postsmudgecheck has rejected as
+// many files as lfs.postsmudgecheckmaxfailures allows, so that a misconfigured
+// checker cannot replace every file in the working tree with its pointer.
+var ErrTooManyQuarantined = errors.New("Too many files were rejected by lfs.postsmudgecheck: aborting")
+
+// QuarantinedFile is a file whose smudged contents lfs.postsmudgecheck
+// rejected, and which was left as its pointer.
+type QuarantinedFile struct {
+	// Name is the path of the file in the working tree.
+	Name string
+	// Oid is the OID of the file's object.
+	Oid string
+	// Path is where the rejected contents were moved to.
+	Path string
+	// Output is what the check command printed.
+	Output string
+}
+
+// postSmudgeChecker runs the lfs.postsmudgecheck command on smudged files, and
+// records those it rejects.
+type postSmudgeChecker struct {
+	command     string
+	maxFailures int
+	// sem bounds the number of commands which run at once.
+	sem chan struct{}
+
+	mu          sync.Mutex
+	quarantined []*QuarantinedFile
+}
+
+// postSmudgeChecker returns the checker for the files smudged by "f", which has
+// no command if lfs.postsmudgecheck is not set.
+func (f *GitFilter) postSmudgeChecker() *postSmudgeChecker {
+	f.checkerOnce.Do(func() {
+		command, _ := f.cfg.Git.Get("lfs.postsmudgecheck")
+		concurrency := f.cfg.Git.Int("lfs.postsmudgecheckconcurrency", defaultPostSmudgeCheckConcurrency)
+		if concurrency < 1 {
+			concurrency = 1
+		}
+
+		f.checker = &postSmudgeChecker{
+			command:     command,
+			maxFailures: f.cfg.Git.Int("lfs.postsmudgecheckmaxfailures", defaultPostSmudgeCheckMaxFailures),
+			sem:         make(chan struct{}, concurrency),
+		}
+	})
+	return f.checker
+}
+
+// Quarantined returns the files which lfs.postsmudgecheck has rejected so far.
+func (f *GitFilter) Quarantined() []*QuarantinedFile {
+	c := f.postSmudgeChecker()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*QuarantinedFile(nil), c.quarantined...)
+}
+
+// aborted returns whether the checker has rejected as many files as it may.
+// A limit of zero or less means there is none.
+func (c *postSmudgeChecker) aborted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.maxFailures > 0 && len(c.quarantined) >= c.maxFailures
+}
+
+// run runs the check command on the file at "path", which holds the smudged
+// contents of the object "oid". It returns whether the command accepted them,
+// and what it printed.
+func (c *postSmudgeChecker) run(path, oid string) (bool, string, error) {
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+
+	name, args := subprocess.FormatForShellQuotedArgs(c.command, []string{path, oid})
+	cmd := subprocess.ExecCommand(name, args...)
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			tracerx.Printf("post-smudge check: rejected %s: %v", path, err)
+			return false, output, nil
+		}
+		return false, output, errors.Wrap(err, "lfs.postsmudgecheck")
+	}
+	return true, output, nil
+}
+
+// postSmudgeCheck runs the lfs.postsmudgecheck command, if one is configured,
+// on the file at "path", which holds the smudged contents of "ptr" for the
+// working tree file "name". If the command rejects them, they are moved into
+// quarantine, and "replace" is called to write the pointer in their place. It
+// returns whether the contents were accepted, and ErrTooManyQuarantined if this
+// was the last rejection allowed.
+func (f *GitFilter) postSmudgeCheck(path, name string, ptr *Pointer, replace func() error) (bool, error) {
+	c := f.postSmudgeChecker()
+	if len(c.command) == 0 {
+		return true, nil
+	}
+
+	ok, output, err := c.run(path, ptr.Oid)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	dest := filepath.Join(f.fs.QuarantineDir(), ptr.Oid, filepath.Base(name))
+	if err := quarantineFile(path, dest, f.cfg); err != nil {
+		return false, errors.Wrapf(err, "Could not quarantine %s", name)
+	}
+	if err := replace(); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.quarantined = append(c.quarantined, &QuarantinedFile{
+		Name:   name,
+		Oid:    ptr.Oid,
+		Path:   dest,
+		Output: output,
+	})
+	c.mu.Unlock()
+
+	if c.aborted() {
+		return false, ErrTooManyQuarantined
+	}
+	return false, nil
+}
+
+// quarantineFile moves the file at "path" to "dest", copying it if it cannot
+// be renamed, as when they are on different filesystems.
+func quarantineFile(path, dest string, cfg *config.Configuration) error {
+	if err := tools.MkdirAll(filepath.Dir(dest), cfg); err != nil {
+		return err
+	}
+	if err := os.Rename(path, dest); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// String describes the quarantined file for the report at the end of an
+// operation.
+func (q *QuarantinedFile) String() string {
+	s := fmt.Sprintf("%s (%s): moved to %s", q.Name, q.Oid, q.Path)
+	if len(q.Output) > 0 {
+		s += "\n" + tools.Indent(q.Output)
+	}
+	return s
+}
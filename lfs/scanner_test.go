@@ -315,6 +315,16 @@ func TestLsTreeParser(t *testing.T) {
 	assertScannerDone(t, scanner)
 }
 
+func TestLsTreeParserSkipsSymlinks(t *testing.T) {
+	stdout := "120000 blob d899f6551a51cf19763c5955c7a06a2726f018e9     126	link.wav\000100644 blob 4d343e022e11a8618db494dc3c501e80c7e18197     126	PB SCN 16 Odhrán.wav"
+	scanner := newLsTreeScanner(strings.NewReader(stdout))
+
+	assertNextScan(t, scanner)
+	assert.Nil(t, scanner.TreeBlob())
+	assertNextTreeBlob(t, scanner, "4d343e022e11a8618db494dc3c501e80c7e18197", "PB SCN 16 Odhrán.wav")
+	assertScannerDone(t, scanner)
+}
+
 func assertNextTreeBlob(t *testing.T, scanner *lsTreeScanner, oid, filename string) {
 	assertNextScan(t, scanner)
 	b := scanner.TreeBlob()
@@ -323,6 +333,20 @@ func assertNextTreeBlob(t *testing.T, scanner *lsTreeScanner, oid, filename stri
 	assert.Equal(t, filename, b.Filename)
 }
 
+func TestLsFilesStageParser(t *testing.T) {
+	b := parseLsFilesStage("100644 d899f6551a51cf19763c5955c7a06a2726f018e9 0\tdir/PB SCN 16 Odhrán.wav")
+	if assert.NotNil(t, b) {
+		assert.Equal(t, "d899f6551a51cf19763c5955c7a06a2726f018e9", b.Sha1)
+		assert.Equal(t, "dir/PB SCN 16 Odhrán.wav", b.Filename)
+	}
+
+	// symbolic links, submodules and unmerged entries are skipped
+	assert.Nil(t, parseLsFilesStage("120000 d899f6551a51cf19763c5955c7a06a2726f018e9 0\tlink"))
+	assert.Nil(t, parseLsFilesStage("160000 d899f6551a51cf19763c5955c7a06a2726f018e9 0\tsubmodule"))
+	assert.Nil(t, parseLsFilesStage("100644 d899f6551a51cf19763c5955c7a06a2726f018e9 2\tconflict.dat"))
+	assert.Nil(t, parseLsFilesStage(""))
+}
+
 func BenchmarkLsTreeParser(b *testing.B) {
 	stdout := "100644 blob d899f6551a51cf19763c5955c7a06a2726f018e9      42	.gitattributes\000100644 blob 4d343e022e11a8618db494dc3c501e80c7e18197     126	PB SCN 16 Odhrán.wav"
 
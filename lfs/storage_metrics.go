@@ -0,0 +1,99 @@
+package lfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/git-lfs/git-lfs/fs"
+	"github.com/git-lfs/git-lfs/tools"
+)
+
+// StorageMetrics reports on the behavior of the local object store across the
+// commands run in the repository, as well as on its overall contents.
+type StorageMetrics struct {
+	// Time is the time at which these metrics were collected.
+	Time time.Time `json:"time"`
+	// CacheHits is the number of objects which were served from the local
+	// object store.
+	CacheHits int64 `json:"cache_hits"`
+	// CacheMisses is the number of objects which were not present in the
+	// local object store, and had to be downloaded.
+	CacheMisses int64 `json:"cache_misses"`
+	// TotalCachedBytes is the total size of all objects in the local
+	// object store.
+	TotalCachedBytes int64 `json:"total_cached_bytes"`
+	// ObjectCount is the number of objects in the local object store.
+	ObjectCount int64 `json:"object_count"`
+	// BandwidthSavedBytes is the total size of the objects which were
+	// served from the local object store, and therefore did not have to be
+	// downloaded.
+	BandwidthSavedBytes int64 `json:"bandwidth_saved_bytes"`
+	// ExtraObjectStoreHits is the number of objects which were copied
+	// from each of the stores given by lfs.extraobjectstores, rather than
+	// being downloaded.
+	ExtraObjectStoreHits map[string]int64 `json:"extra_object_store_hits,omitempty"`
+}
+
+// NewStorageMetrics collects the current *StorageMetrics of the given
+// *fs.Filesystem from its metrics state, which accumulates the metrics of every
+// command run in the repository.
+func NewStorageMetrics(f *fs.Filesystem) (*StorageMetrics, error) {
+	state, err := f.Metrics()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &StorageMetrics{
+		Time:                time.Now(),
+		CacheHits:           state.CacheHits,
+		CacheMisses:         state.CacheMisses,
+		TotalCachedBytes:    state.TotalCachedBytes,
+		ObjectCount:         state.ObjectCount,
+		BandwidthSavedBytes: state.BandwidthSavedBytes,
+	}
+	if len(state.ExtraObjectStoreHits) > 0 {
+		m.ExtraObjectStoreHits = state.ExtraObjectStoreHits
+	}
+	return m, nil
+}
+
+// HitRate returns the fraction of objects which were served from the local
+// object store, or zero if no objects were requested.
+func (m *StorageMetrics) HitRate() float64 {
+	total := m.CacheHits + m.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(m.CacheHits) / float64(total)
+}
+
+// Environ returns the metrics formatted as "key=value" pairs, in the style of
+// Environ().
+func (m *StorageMetrics) Environ() []string {
+	return []string{
+		fmt.Sprintf("CacheHits=%d", m.CacheHits),
+		fmt.Sprintf("CacheMisses=%d", m.CacheMisses),
+		fmt.Sprintf("CacheHitRate=%.2f", m.HitRate()),
+		fmt.Sprintf("TotalCachedBytes=%d", m.TotalCachedBytes),
+		fmt.Sprintf("ObjectCount=%d", m.ObjectCount),
+		fmt.Sprintf("BandwidthSavedBytes=%d", m.BandwidthSavedBytes),
+	}
+}
+
+// WriteFile writes the metrics as JSON to the file at "path", replacing any
+// previous contents, and creating its directory with the permissions of the
+// repository of the given *fs.Filesystem.
+func (m *StorageMetrics) WriteFile(f *fs.Filesystem, path string) error {
+	if err := tools.MkdirAll(filepath.Dir(path), f); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,81 @@
+package lfs_test // avoid import cycle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/lfs"
+	test "github.com/git-lfs/git-lfs/t/cmd/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageMetricsCountsHitsAndMisses(t *testing.T) {
+	repo := test.NewRepo(t)
+	repo.Pushd()
+	defer func() {
+		repo.Popd()
+		repo.Cleanup()
+	}()
+
+	cached := writeTestObject(t, repo, "cached contents")
+	missing := lfs.NewPointer(
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		16, nil)
+
+	gf := lfs.NewGitFilter(repo.Configuration())
+
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		_, err := gf.Smudge(&buf, cached, "cached.dat", false, nil, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "cached contents", buf.String())
+	}
+
+	var buf bytes.Buffer
+	_, err := gf.Smudge(&buf, missing, "missing.dat", false, nil, nil)
+	assert.True(t, errors.IsDownloadDeclinedError(err))
+
+	metrics, err := lfs.NewStorageMetrics(repo.Filesystem())
+	require.Nil(t, err)
+
+	assert.EqualValues(t, 3, metrics.CacheHits)
+	assert.EqualValues(t, 1, metrics.CacheMisses)
+	assert.EqualValues(t, 1, metrics.ObjectCount)
+	assert.EqualValues(t, cached.Size, metrics.TotalCachedBytes)
+	assert.EqualValues(t, 3*cached.Size, metrics.BandwidthSavedBytes)
+	assert.Equal(t, 0.75, metrics.HitRate())
+
+	path := filepath.Join(repo.Path, "metrics", "metrics.json")
+	require.Nil(t, metrics.WriteFile(repo.Filesystem(), path))
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+
+	var written map[string]interface{}
+	require.Nil(t, json.Unmarshal(data, &written))
+	assert.EqualValues(t, 3, written["cache_hits"])
+	assert.EqualValues(t, 1, written["cache_misses"])
+	assert.EqualValues(t, 3*cached.Size, written["bandwidth_saved_bytes"])
+}
+
+func TestStorageMetricsHitRateWithoutRequests(t *testing.T) {
+	assert.Equal(t, float64(0), (&lfs.StorageMetrics{}).HitRate())
+}
+
+func writeTestObject(t *testing.T, repo *test.Repo, contents string) *lfs.Pointer {
+	sum := sha256.Sum256([]byte(contents))
+	oid := hex.EncodeToString(sum[:])
+
+	path, err := repo.Filesystem().ObjectPath(oid)
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+	return lfs.NewPointer(oid, int64(len(contents)), nil)
+}
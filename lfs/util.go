@@ -209,7 +209,7 @@ func CopyFileContents(cfg *config.Configuration, src string, dst string) error {
 	if err != nil {
 		return err
 	}
-	return os.Rename(tmp.Name(), dst)
+	return tools.RenameAcrossDevices(tmp.Name(), dst)
 }
 
 func LinkOrCopy(cfg *config.Configuration, src string, dst string) error {
@@ -27,6 +27,7 @@ type EndpointFinder interface {
 	GitRemoteURL(remote string, forpush bool) string
 	AccessFor(rawurl string) creds.Access
 	SetAccess(access creds.Access)
+	SetPreferredRemote(remote string)
 	GitProtocol() string
 }
 
@@ -35,6 +36,10 @@ type endpointGitFinder struct {
 	gitEnv      config.Environment
 	gitProtocol string
 
+	// preferredRemote is a remote chosen explicitly by the user, whose own
+	// endpoint takes precedence over lfs.url and lfs.pushurl.
+	preferredRemote string
+
 	aliasMu     sync.Mutex
 	aliases     map[string]string
 	pushAliases map[string]string
@@ -78,6 +83,12 @@ func (e *endpointGitFinder) getEndpoint(operation, remote string) lfshttp.Endpoi
 		return lfshttp.Endpoint{}
 	}
 
+	if len(remote) > 0 && remote == e.preferredRemote {
+		if e := e.RemoteEndpoint(operation, remote); len(e.Url) > 0 {
+			return e
+		}
+	}
+
 	if operation == "upload" {
 		if url, ok := e.gitEnv.Get("lfs.pushurl"); ok {
 			return e.NewEndpoint(operation, url)
@@ -229,6 +240,13 @@ func (e *endpointGitFinder) AccessFor(rawurl string) creds.Access {
 	return creds.NewAccess(e.urlAccess[accessurl], accessurl)
 }
 
+// SetPreferredRemote makes the endpoint of "remote", given by
+// remote.<remote>.lfsurl or inferred from its Git URL, take precedence over
+// lfs.url and lfs.pushurl for operations on that remote.
+func (e *endpointGitFinder) SetPreferredRemote(remote string) {
+	e.preferredRemote = remote
+}
+
 func (e *endpointGitFinder) SetAccess(access creds.Access) {
 	key := fmt.Sprintf("lfs.%s.access", access.URL())
 	tracerx.Printf("setting repository access to %s", access.Mode())
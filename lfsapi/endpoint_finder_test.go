@@ -133,6 +133,38 @@ func TestEndpointGlobalSeparateLfsPush(t *testing.T) {
 	assert.Equal(t, "", e.SshPath)
 }
 
+func TestEndpointPreferredRemoteOverridesLfsUrl(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url":             "https://global.com/foo/bar",
+		"lfs.pushurl":         "https://global-write.com/foo/bar",
+		"remote.other.lfsurl": "https://other.com/foo/bar",
+		"remote.third.url":    "https://third.com/foo/bar.git",
+	}))
+
+	e := finder.Endpoint("upload", "other")
+	assert.Equal(t, "https://global-write.com/foo/bar", e.Url)
+
+	finder.SetPreferredRemote("other")
+	e = finder.Endpoint("upload", "other")
+	assert.Equal(t, "https://other.com/foo/bar", e.Url)
+	e = finder.Endpoint("download", "third")
+	assert.Equal(t, "https://global.com/foo/bar", e.Url)
+
+	finder.SetPreferredRemote("third")
+	e = finder.Endpoint("upload", "third")
+	assert.Equal(t, "https://third.com/foo/bar.git/info/lfs", e.Url)
+}
+
+func TestEndpointPreferredRemoteWithoutUrlUsesLfsUrl(t *testing.T) {
+	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
+		"lfs.url": "https://global.com/foo/bar",
+	}))
+
+	finder.SetPreferredRemote("missing")
+	e := finder.Endpoint("download", "missing")
+	assert.Equal(t, "https://global.com/foo/bar", e.Url)
+}
+
 func TestSSHEndpointOverridden(t *testing.T) {
 	finder := NewEndpointFinder(lfshttp.NewContext(nil, nil, map[string]string{
 		"remote.origin.url":    "git@example.com:foo/bar",
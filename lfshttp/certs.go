@@ -27,10 +27,29 @@ func isCertVerificationDisabledForHost(c *Client, host string) bool {
 // isClientCertEnabledForHost returns whether client certificate
 // are configured for the given host
 func isClientCertEnabledForHost(c *Client, host string) bool {
-	_, hostSslKeyOk := c.uc.Get("http", fmt.Sprintf("https://%v/", host), "sslKey")
-	_, hostSslCertOk := c.uc.Get("http", fmt.Sprintf("https://%v/", host), "sslCert")
+	_, _, ok := clientCertFilesForHost(c, host)
+	return ok
+}
+
+// clientCertFilesForHost returns the files of the client certificate and its
+// private key for the given host, from either Git's http.<url>.sslCert and
+// http.<url>.sslKey, or lfs.<url>.tlscertfile and lfs.<url>.tlskeyfile, in
+// that order. Both files must be configured by the same pair of options.
+func clientCertFilesForHost(c *Client, host string) (string, string, bool) {
+	rawurl := fmt.Sprintf("https://%v/", host)
 
-	return hostSslKeyOk && hostSslCertOk
+	hostSslKey, hostSslKeyOk := c.uc.Get("http", rawurl, "sslKey")
+	hostSslCert, hostSslCertOk := c.uc.Get("http", rawurl, "sslCert")
+	if hostSslKeyOk && hostSslCertOk {
+		return hostSslCert, hostSslKey, true
+	}
+
+	tlsKey, tlsKeyOk := c.uc.Get("lfs", rawurl, "tlskeyfile")
+	tlsCert, tlsCertOk := c.uc.Get("lfs", rawurl, "tlscertfile")
+	if tlsKeyOk && tlsCertOk {
+		return tlsCert, tlsKey, true
+	}
+	return "", "", false
 }
 
 // decryptPEMBlock decrypts an encrypted PEM block representing a private key,
@@ -68,8 +87,7 @@ func decryptPEMBlock(c *Client, block *pem.Block, path string, key []byte) ([]by
 // getClientCertForHost returns a client certificate for a specific host (which may
 // be "host:port" loaded from the gitconfig
 func getClientCertForHost(c *Client, host string) *tls.Certificate {
-	hostSslKey, _ := c.uc.Get("http", fmt.Sprintf("https://%v/", host), "sslKey")
-	hostSslCert, _ := c.uc.Get("http", fmt.Sprintf("https://%v/", host), "sslCert")
+	hostSslCert, hostSslKey, _ := clientCertFilesForHost(c, host)
 
 	cert, err := ioutil.ReadFile(hostSslCert)
 	if err != nil {
@@ -283,3 +283,36 @@ func TestCertVerifyDisabledHostConfig(t *testing.T) {
 		assert.False(t, tr.TLSClientConfig.InsecureSkipVerify)
 	}
 }
+
+func TestClientCertFilesForHost(t *testing.T) {
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"http.https://githost.com/.sslkey":       "git.key",
+		"http.https://githost.com/.sslcert":      "git.crt",
+		"lfs.https://githost.com/.tlskeyfile":    "lfs-githost.key",
+		"lfs.https://githost.com/.tlscertfile":   "lfs-githost.crt",
+		"lfs.https://lfshost.com/.tlskeyfile":    "lfshost.key",
+		"lfs.https://lfshost.com/.tlscertfile":   "lfshost.crt",
+		"lfs.https://keyonly.com/.tlskeyfile":    "keyonly.key",
+		"lfs.https://otherhost.com/.tlscertfile": "other.crt",
+		"lfs.tlskeyfile":                         "default.key",
+	}))
+	assert.Nil(t, err)
+
+	for host, expected := range map[string][]string{
+		"githost.com":   {"git.crt", "git.key"},
+		"lfshost.com":   {"lfshost.crt", "lfshost.key"},
+		"otherhost.com": {"other.crt", "default.key"},
+		"keyonly.com":   nil,
+		"nohost.com":    nil,
+	} {
+		cert, key, ok := clientCertFilesForHost(c, host)
+		if expected == nil {
+			assert.False(t, ok, host)
+			continue
+		}
+		if assert.True(t, ok, host) {
+			assert.Equal(t, expected[0], cert, host)
+			assert.Equal(t, expected[1], key, host)
+		}
+	}
+}
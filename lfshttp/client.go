@@ -1,7 +1,6 @@
 package lfshttp
 
 import (
-	"context"
 	"crypto/tls"
 	"fmt"
 	"io"
@@ -11,7 +10,6 @@ import (
 	"net/url"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -290,7 +288,7 @@ func (c *Client) DoWithRedirect(cli *http.Client, req *http.Request, remote stri
 
 	requests := tools.MaxInt(0, retries) + 1
 	for i := 0; i < requests; i++ {
-		res, err = cli.Do(req)
+		res, err = c.doWithIdleTimeout(cli, req)
 		if err == nil {
 			break
 		}
@@ -397,57 +395,31 @@ func (c *Client) Transport(u *url.URL, access creds.AccessMode) (http.RoundTripp
 		concurrentTransfers = 8
 	}
 
-	dialtime := c.DialTimeout
-	if dialtime < 1 {
-		dialtime = 30
-	}
-
 	keepalivetime := c.KeepaliveTimeout
 	if keepalivetime < 1 {
 		keepalivetime = 1800
 	}
 
-	tlstime := c.TLSTimeout
-	if tlstime < 1 {
-		tlstime = 30
-	}
-	tr := &http.Transport{
-		Proxy:               proxyFromClient(c),
-		TLSHandshakeTimeout: time.Duration(tlstime) * time.Second,
-		MaxIdleConnsPerHost: concurrentTransfers,
-	}
+	// There is deliberately no overall timeout, so that large transfers
+	// are not cut off while data is still flowing.
+	timeout := c.HTTPTimeout(u)
+	tracerx.Printf("http: timeouts for %s: connect %s, read header %s, idle %s",
+		host, timeout.Connect, timeout.ReadHeader, timeout.Idle)
 
-	activityTimeout := 30
-	if v, ok := c.uc.Get("lfs", u.String(), "activitytimeout"); ok {
-		if i, err := strconv.Atoi(v); err == nil {
-			activityTimeout = i
-		} else {
-			activityTimeout = 0
-		}
+	tr := &http.Transport{
+		Proxy:                 proxyFromClient(c),
+		TLSHandshakeTimeout:   timeout.Connect,
+		ResponseHeaderTimeout: timeout.ReadHeader,
+		MaxIdleConnsPerHost:   concurrentTransfers,
 	}
 
 	dialer := &net.Dialer{
-		Timeout:   time.Duration(dialtime) * time.Second,
+		Timeout:   timeout.Connect,
 		KeepAlive: time.Duration(keepalivetime) * time.Second,
 		DualStack: true,
 	}
 
-	if activityTimeout > 0 {
-		activityDuration := time.Duration(activityTimeout) * time.Second
-		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-			c, err := dialer.DialContext(ctx, network, addr)
-			if c == nil {
-				return c, err
-			}
-			if tc, ok := c.(*net.TCPConn); ok {
-				tc.SetKeepAlive(true)
-				tc.SetKeepAlivePeriod(dialer.KeepAlive)
-			}
-			return &deadlineConn{Timeout: activityDuration, Conn: c}, err
-		}
-	} else {
-		tr.DialContext = dialer.DialContext
-	}
+	tr.DialContext = dialer.DialContext
 
 	tr.TLSClientConfig = &tls.Config{
 		Renegotiation: tls.RenegotiateFreelyAsClient,
@@ -475,9 +447,9 @@ func (c *Client) Transport(u *url.URL, access creds.AccessMode) (http.RoundTripp
 	if access == creds.NegotiateAccess {
 		// This technically copies a mutex, but we know since we've just created
 		// the object that this mutex is unlocked.
-		return &spnego.Transport{Transport: *tr}, nil
+		return c.debugTransport(&spnego.Transport{Transport: *tr}), nil
 	}
-	return tr, nil
+	return c.debugTransport(tr), nil
 }
 
 func (c *Client) HttpClient(u *url.URL, access creds.AccessMode) (*http.Client, error) {
@@ -566,26 +538,6 @@ func newRequestForRetry(req *http.Request, location string) (*http.Request, erro
 	return newReq, nil
 }
 
-type deadlineConn struct {
-	Timeout time.Duration
-	net.Conn
-}
-
-func (c *deadlineConn) Read(b []byte) (int, error) {
-	if err := c.Conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
-		return 0, err
-	}
-	return c.Conn.Read(b)
-}
-
-func (c *deadlineConn) Write(b []byte) (int, error) {
-	if err := c.Conn.SetDeadline(time.Now().Add(c.Timeout)); err != nil {
-		return 0, err
-	}
-
-	return c.Conn.Write(b)
-}
-
 func init() {
 	UserAgent = config.VersionDesc
 }
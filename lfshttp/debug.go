@@ -0,0 +1,161 @@
+package lfshttp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// debugBodyLimit is the number of bytes of each request and response
+	// body which DebugTransport logs, if it logs bodies at all.
+	debugBodyLimit = 4096
+
+	// debugAuthLimit is the number of characters of each credential header
+	// which DebugTransport logs.
+	debugAuthLimit = 8
+)
+
+// debugAuthHeaders are the headers whose values DebugTransport truncates, so
+// that credentials are not written to the log.
+var debugAuthHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// DebugTransport is an http.RoundTripper which logs the method, URL and
+// headers of each request made through it, and the status and headers of each
+// response, much as GIT_CURL_VERBOSE does for Git. It is enabled by
+// GIT_LFS_DEBUG_HTTP or lfs.debughttp.
+type DebugTransport struct {
+	// Transport makes the requests which are logged.
+	Transport http.RoundTripper
+	// Out is where requests and responses are logged.
+	Out io.Writer
+	// Bodies is whether the first few kilobytes of each request and
+	// response body are logged as well.
+	Bodies bool
+}
+
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if t.Bodies && req.Body != nil {
+		// Don't modify the caller's request, but send the copy with
+		// a body which starts with the bytes already read.
+		body := bufio.NewReaderSize(req.Body, debugBodyLimit)
+		reqBody, _ = body.Peek(debugBodyLimit)
+
+		r := new(http.Request)
+		*r = *req
+		r.Body = &debugBody{Reader: body, Closer: req.Body}
+		req = r
+	}
+
+	t.log(">", fmt.Sprintf("%s %s", req.Method, req.URL), req.Header, reqBody)
+
+	res, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		t.log("<", fmt.Sprintf("error: %s", err), nil, nil)
+		return res, err
+	}
+
+	var resBody []byte
+	if t.Bodies && res.Body != nil {
+		body := bufio.NewReaderSize(res.Body, debugBodyLimit)
+		resBody, _ = body.Peek(debugBodyLimit)
+		res.Body = &debugBody{Reader: body, Closer: res.Body}
+	}
+
+	t.log("<", fmt.Sprintf("%s %s", res.Proto, res.Status), res.Header, resBody)
+	return res, nil
+}
+
+// log writes the first line of a request or response, its headers and the
+// given part of its body, each prefixed by "prefix".
+func (t *DebugTransport) log(prefix, line string, header http.Header, body []byte) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", prefix, line)
+
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(&b, "%s %s: %s\n", prefix, key, debugHeaderValue(key, value))
+		}
+	}
+
+	if len(body) > 0 {
+		fmt.Fprintf(&b, "%s\n", prefix)
+		for _, bodyLine := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+			fmt.Fprintf(&b, "%s %s\n", prefix, bodyLine)
+		}
+		if len(body) == debugBodyLimit {
+			fmt.Fprintf(&b, "%s (body truncated to %d bytes)\n", prefix, debugBodyLimit)
+		}
+	}
+	b.WriteString("\n")
+
+	// The output may be shared by the transports for several hosts.
+	debugOutMu.Lock()
+	defer debugOutMu.Unlock()
+	io.WriteString(t.Out, b.String())
+}
+
+// debugHeaderValue returns "value" as it should be logged for the header
+// "key", truncating credentials.
+func debugHeaderValue(key, value string) string {
+	for _, auth := range debugAuthHeaders {
+		if strings.EqualFold(key, auth) && len(value) > debugAuthLimit {
+			return value[:debugAuthLimit] + "..."
+		}
+	}
+	return value
+}
+
+// debugBody is a request or response body whose first bytes were read ahead
+// to be logged.
+type debugBody struct {
+	io.Reader
+	io.Closer
+}
+
+var (
+	debugOut     io.Writer
+	debugOutOnce sync.Once
+	debugOutMu   sync.Mutex
+)
+
+// debugTransport wraps "tr" in a DebugTransport if GIT_LFS_DEBUG_HTTP or
+// lfs.debughttp is set, logging to the file given by lfs.debugfile, or to
+// standard error.
+func (c *Client) debugTransport(tr http.RoundTripper) http.RoundTripper {
+	if !c.osEnv.Bool("GIT_LFS_DEBUG_HTTP", false) && !c.gitEnv.Bool("lfs.debughttp", false) {
+		return tr
+	}
+
+	debugOutOnce.Do(func() {
+		debugOut = os.Stderr
+		if path, ok := c.gitEnv.Get("lfs.debugfile"); ok && len(path) > 0 {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+			if err != nil {
+				tracerx.Printf("http: could not open lfs.debugfile %q: %s", path, err)
+				return
+			}
+			debugOut = f
+		}
+	})
+
+	return &DebugTransport{
+		Transport: tr,
+		Out:       debugOut,
+		Bodies:    c.gitEnv.Bool("lfs.debugbodies", false),
+	}
+}
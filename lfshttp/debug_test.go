@@ -0,0 +1,106 @@
+package lfshttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugTransportLogsHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"Test":"Debug"}`, string(body))
+		assert.Equal(t, "Basic dXNlcjpwYXNz", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(201)
+		w.Write([]byte(`{"Status":"Ok"}`))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	tr := &DebugTransport{Transport: http.DefaultTransport, Out: out}
+
+	req, err := http.NewRequest("POST", srv.URL+"/objects/batch", strings.NewReader(`{"Test":"Debug"}`))
+	require.Nil(t, err)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+
+	res, err := tr.RoundTrip(req)
+	require.Nil(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, `{"Status":"Ok"}`, string(body))
+
+	s := out.String()
+	t.Log(s)
+
+	assert.Contains(t, s, "> POST "+srv.URL+"/objects/batch\n")
+	assert.Contains(t, s, "> Authorization: Basic dX...\n")
+	assert.NotContains(t, s, "dXNlcjpwYXNz")
+	assert.Contains(t, s, "< HTTP/1.1 201 Created\n")
+	assert.Contains(t, s, "< Content-Type: application/json\n")
+	assert.NotContains(t, s, "Debug")
+	assert.NotContains(t, s, "Ok")
+}
+
+func TestDebugTransportLogsBodies(t *testing.T) {
+	large := strings.Repeat("a", debugBodyLimit+100)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"Test":"Debug"}`, string(body))
+		w.Write([]byte(large))
+	}))
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	tr := &DebugTransport{Transport: http.DefaultTransport, Out: out, Bodies: true}
+
+	req, err := http.NewRequest("POST", srv.URL, strings.NewReader(`{"Test":"Debug"}`))
+	require.Nil(t, err)
+
+	res, err := tr.RoundTrip(req)
+	require.Nil(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	res.Body.Close()
+
+	// The whole body is still read, despite only part of it being logged.
+	assert.Equal(t, large, string(body))
+
+	s := out.String()
+	assert.Contains(t, s, "> {\"Test\":\"Debug\"}\n")
+	assert.Contains(t, s, "< "+strings.Repeat("a", debugBodyLimit)+"\n")
+	assert.NotContains(t, s, strings.Repeat("a", debugBodyLimit+1))
+	assert.Contains(t, s, "< (body truncated to 4096 bytes)\n")
+}
+
+func TestDebugTransportDisabledByDefault(t *testing.T) {
+	c, err := NewClient(nil)
+	require.Nil(t, err)
+
+	_, ok := c.debugTransport(http.DefaultTransport).(*DebugTransport)
+	assert.False(t, ok)
+}
+
+func TestDebugTransportEnabledByConfig(t *testing.T) {
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.debughttp":   "true",
+		"lfs.debugbodies": "true",
+	}))
+	require.Nil(t, err)
+
+	tr, ok := c.debugTransport(http.DefaultTransport).(*DebugTransport)
+	if assert.True(t, ok) {
+		assert.True(t, tr.Bodies)
+	}
+}
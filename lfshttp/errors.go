@@ -125,5 +125,8 @@ func defaultError(res *http.Response) error {
 		msgFmt = defaultErrors[500] + fmt.Sprintf(" from HTTP %d", res.StatusCode)
 	}
 
-	return errors.Errorf(msgFmt, res.Request.URL)
+	return &ClientError{
+		Message:  fmt.Sprintf(msgFmt, res.Request.URL),
+		response: res,
+	}
 }
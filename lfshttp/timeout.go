@@ -0,0 +1,228 @@
+package lfshttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	defaultConnectTimeout    = 30 * time.Second
+	defaultReadHeaderTimeout = 60 * time.Second
+	defaultIdleTimeout       = 30 * time.Second
+)
+
+// HTTPTimeout holds the timeouts for requests to a host. No timeout bounds a
+// whole request, so that large transfers may take as long as they need to
+// while data is still flowing. A zero timeout is disabled.
+type HTTPTimeout struct {
+	// Connect bounds establishing a connection, and applies to both the
+	// TCP connect and the TLS handshake.
+	Connect time.Duration
+	// ReadHeader bounds how long to wait for the response headers once
+	// the request, including its body, has been sent.
+	ReadHeader time.Duration
+	// Idle bounds how long a connection may go without any data being
+	// read or written.
+	Idle time.Duration
+}
+
+// HTTPTimeout returns the timeouts for requests to "u", as configured by
+// lfs.connecttimeout, lfs.readheadertimeout and lfs.idletransfertimeout, each
+// of which may also be given for a URL, such as
+// lfs.https://example.com.connecttimeout.
+//
+// For compatibility, lfs.dialtimeout and lfs.tlstimeout are used for the
+// connect timeout if lfs.connecttimeout isn't set, and lfs.activitytimeout for
+// the idle timeout if lfs.idletransfertimeout isn't set.
+func (c *Client) HTTPTimeout(u *url.URL) HTTPTimeout {
+	t := HTTPTimeout{
+		Connect:    defaultConnectTimeout,
+		ReadHeader: defaultReadHeaderTimeout,
+		Idle:       defaultIdleTimeout,
+	}
+
+	if d, ok := c.timeoutConfig(u, "connecttimeout"); ok {
+		t.Connect = d
+	} else if c.DialTimeout > 0 || c.TLSTimeout > 0 {
+		t.Connect = time.Duration(maxInt(c.DialTimeout, c.TLSTimeout)) * time.Second
+	}
+
+	if d, ok := c.timeoutConfig(u, "readheadertimeout"); ok {
+		t.ReadHeader = d
+	}
+
+	if d, ok := c.timeoutConfig(u, "idletransfertimeout"); ok {
+		t.Idle = d
+	} else if v, ok := c.uc.Get("lfs", u.String(), "activitytimeout"); ok {
+		// An activity timeout which is not a number of seconds has
+		// always disabled it.
+		i, _ := strconv.Atoi(v)
+		t.Idle = time.Duration(i) * time.Second
+	}
+
+	return t
+}
+
+// timeoutConfig returns the timeout given by the lfs."key" option for "u",
+// which is either a number of seconds or a duration such as "90s" or "5m". A
+// negative timeout disables it, as does zero. Invalid values are ignored.
+func (c *Client) timeoutConfig(u *url.URL, key string) (time.Duration, bool) {
+	v, ok := c.uc.Get("lfs", u.String(), key)
+	if !ok || len(v) == 0 {
+		return 0, false
+	}
+
+	d, err := parseTimeout(v)
+	if err != nil {
+		tracerx.Printf("http: ignoring invalid lfs.%s %q: %s", key, v, err)
+		return 0, false
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// parseTimeout parses "v" as a number of seconds, or failing that as a
+// duration.
+func parseTimeout(v string) (time.Duration, error) {
+	if i, err := strconv.Atoi(v); err == nil {
+		return time.Duration(i) * time.Second, nil
+	}
+	return time.ParseDuration(v)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// doWithIdleTimeout sends "req" with "cli", cancelling it if it goes for
+// longer than the idle timeout for its URL without any of the request body
+// being sent, or any of the response body being received. Waiting for the
+// response headers is bounded by the read header timeout instead.
+func (c *Client) doWithIdleTimeout(cli *http.Client, req *http.Request) (*http.Response, error) {
+	timeout := c.HTTPTimeout(req.URL).Idle
+	if timeout <= 0 {
+		return cli.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	w := &idleWatchdog{timeout: timeout, cancel: cancel}
+
+	req = req.WithContext(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &idleReader{ReadCloser: req.Body, w: w, stopAtEOF: true}
+		w.reset()
+	}
+
+	res, err := cli.Do(req)
+	if err != nil {
+		w.stop()
+		cancel()
+		return res, w.wrap(err)
+	}
+
+	w.reset()
+	res.Body = &idleReader{ReadCloser: res.Body, w: w, close: cancel}
+	return res, nil
+}
+
+// idleWatchdog cancels a request once its timer, which is reset as data is
+// transferred, expires.
+type idleWatchdog struct {
+	timeout time.Duration
+	cancel  func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+	fired bool
+}
+
+func (w *idleWatchdog) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.fired {
+		return
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.timeout, w.fire)
+	} else {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+func (w *idleWatchdog) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+func (w *idleWatchdog) fire() {
+	w.mu.Lock()
+	w.fired = true
+	w.mu.Unlock()
+
+	w.cancel()
+}
+
+// wrap returns "err", or if the request was cancelled because it was idle, an
+// error saying so, which may be retried.
+func (w *idleWatchdog) wrap(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err == nil || !w.fired {
+		return err
+	}
+	return errors.NewRetriableError(errors.Errorf("no data transferred for %s (see lfs.idletransfertimeout)", w.timeout))
+}
+
+// idleReader resets its watchdog whenever data is read through it.
+type idleReader struct {
+	io.ReadCloser
+	w *idleWatchdog
+
+	// stopAtEOF stops the watchdog once everything has been read, such
+	// as once a request body has been sent.
+	stopAtEOF bool
+	// close is called once the reader is closed.
+	close func()
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.w.reset()
+	}
+	if err == io.EOF && r.stopAtEOF {
+		r.w.stop()
+	}
+	if err != nil && err != io.EOF {
+		err = r.w.wrap(err)
+	}
+	return n, err
+}
+
+func (r *idleReader) Close() error {
+	err := r.ReadCloser.Close()
+	if r.close != nil {
+		r.w.stop()
+		r.close()
+	}
+	return err
+}
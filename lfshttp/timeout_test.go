@@ -0,0 +1,145 @@
+package lfshttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTimeoutDefaults(t *testing.T) {
+	c, err := NewClient(nil)
+	require.Nil(t, err)
+
+	u, _ := url.Parse("https://example.com/repo")
+	assert.Equal(t, HTTPTimeout{
+		Connect:    30 * time.Second,
+		ReadHeader: 60 * time.Second,
+		Idle:       30 * time.Second,
+	}, c.HTTPTimeout(u))
+}
+
+func TestHTTPTimeoutConfig(t *testing.T) {
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.connecttimeout":                               "10",
+		"lfs.readheadertimeout":                            "2m",
+		"lfs.idletransfertimeout":                          "0",
+		"lfs.https://slow.example.com.idletransfertimeout": "90s",
+	}))
+	require.Nil(t, err)
+
+	u, _ := url.Parse("https://example.com/repo")
+	assert.Equal(t, HTTPTimeout{
+		Connect:    10 * time.Second,
+		ReadHeader: 2 * time.Minute,
+		Idle:       0,
+	}, c.HTTPTimeout(u))
+
+	u, _ = url.Parse("https://slow.example.com/repo")
+	assert.Equal(t, 90*time.Second, c.HTTPTimeout(u).Idle)
+}
+
+func TestHTTPTimeoutLegacyConfig(t *testing.T) {
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.dialtimeout":     "5",
+		"lfs.tlstimeout":      "15",
+		"lfs.activitytimeout": "45",
+	}))
+	require.Nil(t, err)
+
+	u, _ := url.Parse("https://example.com/repo")
+	timeout := c.HTTPTimeout(u)
+	assert.Equal(t, 15*time.Second, timeout.Connect)
+	assert.Equal(t, 45*time.Second, timeout.Idle)
+}
+
+func TestHTTPTimeoutIgnoresInvalidValues(t *testing.T) {
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.readheadertimeout": "soon",
+	}))
+	require.Nil(t, err)
+
+	u, _ := url.Parse("https://example.com/repo")
+	assert.Equal(t, 60*time.Second, c.HTTPTimeout(u).ReadHeader)
+}
+
+func TestReadHeaderTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.readheadertimeout": "100ms",
+	}))
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	require.Nil(t, err)
+
+	_, err = c.Do(req)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "timeout awaiting response headers")
+}
+
+func TestIdleTransferTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("some data"))
+		w.(http.Flusher).Flush()
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.idletransfertimeout": "100ms",
+	}))
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	require.Nil(t, err)
+
+	res, err := c.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	_, err = ioutil.ReadAll(res.Body)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "no data transferred for 100ms")
+}
+
+func TestIdleTransferTimeoutAllowsSlowTransfers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+		for i := 0; i < 5; i++ {
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte("."))
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewClient(NewContext(nil, nil, map[string]string{
+		"lfs.idletransfertimeout": "200ms",
+	}))
+	require.Nil(t, err)
+
+	req, err := http.NewRequest("POST", srv.URL, bytes.NewReader([]byte("body")))
+	require.Nil(t, err)
+
+	// The whole response takes longer than the idle timeout, but data
+	// keeps arriving.
+	res, err := c.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, "body.....", string(body))
+}
@@ -0,0 +1,138 @@
+// Package lfsproxy implements a small HTTP forward proxy, which adds a Bearer
+// token to Git LFS Batch API requests. It lets clients which can be pointed at
+// a proxy with HTTP_PROXY, but which cannot be given credentials, use a Git
+// LFS server which requires them, for example in CI.
+package lfsproxy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rubyist/tracerx"
+)
+
+// batchSuffix is the suffix of the path of every Batch API endpoint.
+const batchSuffix = "/objects/batch"
+
+// Proxy is an http.Handler which forwards the requests made to it, as an HTTP
+// proxy, to their destination.
+//
+// Plain HTTP requests are forwarded one at a time, and an "Authorization:
+// Bearer <Token>" header is added to Batch API requests. HTTPS requests are
+// tunneled with CONNECT, and pass through unchanged, since the proxy cannot
+// see inside them.
+type Proxy struct {
+	// Token is the Bearer token to add to Batch API requests. If it is
+	// empty, requests are forwarded unchanged.
+	Token string
+	// Transport sends the forwarded requests. If it is nil, a transport
+	// which connects directly to each destination, ignoring any proxy set
+	// in the environment, is used.
+	Transport http.RoundTripper
+
+	once    sync.Once
+	forward *httputil.ReverseProxy
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+
+	if !r.URL.IsAbs() {
+		http.Error(w, "lfsproxy: not a proxy request", http.StatusBadRequest)
+		return
+	}
+
+	p.once.Do(func() {
+		transport := p.Transport
+		if transport == nil {
+			transport = &http.Transport{}
+		}
+
+		p.forward = &httputil.ReverseProxy{
+			Director:  p.direct,
+			Transport: transport,
+		}
+	})
+	p.forward.ServeHTTP(w, r)
+}
+
+// direct prepares "r" to be forwarded to the URL it was made for.
+func (p *Proxy) direct(r *http.Request) {
+	r.Header.Del("Proxy-Authorization")
+	if len(p.Token) == 0 || !isBatchRequest(r) {
+		return
+	}
+
+	tracerx.Printf("lfsproxy: adding authorization to %s %s", r.Method, r.URL)
+	r.Header.Set("Authorization", "Bearer "+p.Token)
+}
+
+// tunnel connects the client which made the CONNECT request "r" to the host
+// it names, and copies data between them until either side closes.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "lfsproxy: cannot tunnel this connection", http.StatusInternalServerError)
+		return
+	}
+
+	upstream, err := net.DialTimeout("tcp", r.Host, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	client, buf, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		upstream.Close()
+		client.Close()
+		return
+	}
+
+	tracerx.Printf("lfsproxy: tunneling to %s", r.Host)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		// Send anything the client sent after the CONNECT request,
+		// which net/http may already have read.
+		io.Copy(upstream, buf)
+		closeWrite(upstream)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		closeWrite(client)
+		done <- struct{}{}
+	}()
+
+	<-done
+	<-done
+	upstream.Close()
+	client.Close()
+}
+
+// closeWrite shuts down the writing side of "conn" if it can, so that the
+// other end sees the end of the stream.
+func closeWrite(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.CloseWrite()
+	}
+}
+
+// isBatchRequest returns whether "r" is a request to a Batch API endpoint.
+func isBatchRequest(r *http.Request) bool {
+	return r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, batchSuffix)
+}
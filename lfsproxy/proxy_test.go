@@ -0,0 +1,95 @@
+package lfsproxy
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client which sends its requests through a new Proxy
+// with the given token, and a function which stops the proxy.
+func newTestClient(t *testing.T, token string) (*http.Client, func()) {
+	proxy := httptest.NewServer(&Proxy{Token: token})
+	proxyURL, err := url.Parse(proxy.URL)
+	require.Nil(t, err)
+
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	return client, proxy.Close
+}
+
+// authServer answers every request with the Authorization header it was sent.
+func authServer() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	})
+}
+
+func do(t *testing.T, client *http.Client, method, url string) string {
+	req, err := http.NewRequest(method, url, strings.NewReader("{}"))
+	require.Nil(t, err)
+
+	res, err := client.Do(req)
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	return string(body)
+}
+
+func TestProxyAddsTokenToBatchRequests(t *testing.T) {
+	srv := httptest.NewServer(authServer())
+	defer srv.Close()
+
+	client, stop := newTestClient(t, "s3cret")
+	defer stop()
+
+	assert.Equal(t, "Bearer s3cret", do(t, client, "POST", srv.URL+"/repo.git/info/lfs/objects/batch"))
+	assert.Equal(t, "", do(t, client, "GET", srv.URL+"/repo.git/info/lfs/objects/batch"))
+	assert.Equal(t, "", do(t, client, "POST", srv.URL+"/repo.git/info/lfs/locks"))
+	assert.Equal(t, "", do(t, client, "GET", srv.URL+"/repo.git/info/refs"))
+}
+
+func TestProxyWithoutTokenForwardsUnchanged(t *testing.T) {
+	srv := httptest.NewServer(authServer())
+	defer srv.Close()
+
+	client, stop := newTestClient(t, "")
+	defer stop()
+
+	assert.Equal(t, "", do(t, client, "POST", srv.URL+"/repo.git/info/lfs/objects/batch"))
+}
+
+func TestProxyTunnelsHTTPS(t *testing.T) {
+	srv := httptest.NewTLSServer(authServer())
+	defer srv.Close()
+
+	client, stop := newTestClient(t, "s3cret")
+	defer stop()
+
+	// The proxy cannot see inside the tunnel, so the request arrives
+	// unchanged.
+	assert.Equal(t, "", do(t, client, "POST", srv.URL+"/repo.git/info/lfs/objects/batch"))
+}
+
+func TestProxyRejectsNonProxyRequests(t *testing.T) {
+	proxy := httptest.NewServer(&Proxy{Token: "s3cret"})
+	defer proxy.Close()
+
+	res, err := http.Get(proxy.URL + "/objects/batch")
+	require.Nil(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
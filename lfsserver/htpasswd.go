@@ -0,0 +1,165 @@
+package lfsserver
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/git-lfs/git-lfs/errors"
+)
+
+// Htpasswd is the set of users, and their hashed passwords, read from an
+// Apache htpasswd file. It supports passwords hashed with "htpasswd -m" (the
+// default, "$apr1$") or "htpasswd -s" ("{SHA}"), and plain text passwords
+// ("htpasswd -p"). It does not support bcrypt or crypt(3).
+type Htpasswd struct {
+	users map[string]string
+}
+
+// ReadHtpasswdFile reads the htpasswd file at "path".
+func ReadHtpasswdFile(path string) (*Htpasswd, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h, err := ReadHtpasswd(f)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return h, nil
+}
+
+// ReadHtpasswd reads an htpasswd file from "r". Blank lines, and those which
+// begin with "#", are ignored.
+func ReadHtpasswd(r io.Reader) (*Htpasswd, error) {
+	h := &Htpasswd{users: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, errors.Errorf("line %d: expected <user>:<password>", n)
+		}
+		if strings.HasPrefix(parts[1], "$2") {
+			return nil, errors.Errorf("line %d: bcrypt passwords are not supported; use `htpasswd -m` or `htpasswd -s`", n)
+		}
+		h.users[parts[0]] = parts[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Authenticate returns whether "password" is the password of "user".
+func (h *Htpasswd) Authenticate(user, password string) bool {
+	hashed, ok := h.users[user]
+	if !ok {
+		return false
+	}
+
+	var actual string
+	switch {
+	case strings.HasPrefix(hashed, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		actual = "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	case strings.HasPrefix(hashed, apr1Magic):
+		salt := strings.TrimPrefix(hashed, apr1Magic)
+		if i := strings.IndexByte(salt, '$'); i >= 0 {
+			salt = salt[:i]
+		}
+		actual = apr1(password, salt)
+	default:
+		actual = password
+	}
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(hashed)) == 1
+}
+
+const (
+	apr1Magic  = "$apr1$"
+	apr1Itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// apr1 returns "password" hashed with "salt" by Apache's variant of the MD5
+// crypt(3) algorithm, in the form "$apr1$<salt>$<hash>".
+func apr1(password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw, s := []byte(password), []byte(salt)
+
+	alt := md5.New()
+	alt.Write(pw)
+	alt.Write(s)
+	alt.Write(pw)
+	sum := alt.Sum(nil)
+
+	d := md5.New()
+	d.Write(pw)
+	d.Write([]byte(apr1Magic))
+	d.Write(s)
+	for i := len(pw); i > 0; i -= 16 {
+		if i > 16 {
+			d.Write(sum)
+		} else {
+			d.Write(sum[:i])
+		}
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			d.Write([]byte{0})
+		} else {
+			d.Write(pw[:1])
+		}
+	}
+	sum = d.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		r := md5.New()
+		if i&1 != 0 {
+			r.Write(pw)
+		} else {
+			r.Write(sum)
+		}
+		if i%3 != 0 {
+			r.Write(s)
+		}
+		if i%7 != 0 {
+			r.Write(pw)
+		}
+		if i&1 != 0 {
+			r.Write(sum)
+		} else {
+			r.Write(pw)
+		}
+		sum = r.Sum(nil)
+	}
+
+	var b strings.Builder
+	b.WriteString(apr1Magic)
+	b.WriteString(salt)
+	b.WriteByte('$')
+	to64 := func(v uint, n int) {
+		for ; n > 0; n-- {
+			b.WriteByte(apr1Itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	for _, g := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		to64(uint(sum[g[0]])<<16|uint(sum[g[1]])<<8|uint(sum[g[2]]), 4)
+	}
+	to64(uint(sum[11]), 2)
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package lfsserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHtpasswdAuthenticate(t *testing.T) {
+	h, err := ReadHtpasswd(strings.NewReader(`
+# comment
+md5:$apr1$saltsalt$LrttParrLPdxvgutaSXWJ0
+sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=
+plain:secret
+`))
+	require.Nil(t, err)
+
+	for _, user := range []string{"md5", "sha", "plain"} {
+		assert.True(t, h.Authenticate(user, "secret"), user)
+		assert.False(t, h.Authenticate(user, "wrong"), user)
+		assert.False(t, h.Authenticate(user, ""), user)
+	}
+	assert.False(t, h.Authenticate("nobody", "secret"))
+	assert.False(t, h.Authenticate("sha", "{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ="))
+}
+
+func TestHtpasswdRejectsBcrypt(t *testing.T) {
+	_, err := ReadHtpasswd(strings.NewReader("user:$2y$05$abcdefghijklmnopqrstuv\n"))
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 1: bcrypt passwords are not supported")
+}
+
+func TestHtpasswdRejectsMalformedLines(t *testing.T) {
+	_, err := ReadHtpasswd(strings.NewReader("user:secret\nnopassword\n"))
+	require.NotNil(t, err)
+	assert.Equal(t, "line 2: expected <user>:<password>", err.Error())
+}
+
+func TestApr1(t *testing.T) {
+	assert.Equal(t, "$apr1$saltsalt$LrttParrLPdxvgutaSXWJ0", apr1("secret", "saltsalt"))
+}
@@ -0,0 +1,284 @@
+// Package lfsserver implements a small Git LFS server, which answers Batch API
+// requests and stores objects in a directory on disk. It is meant for
+// development and small teams, for example alongside repositories hosted with
+// git-shell or gitolite, rather than for production use at scale.
+package lfsserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rubyist/tracerx"
+)
+
+const (
+	// mediaType is the media type of Batch API requests and responses.
+	mediaType = "application/vnd.git-lfs+json"
+	// apiPrefix separates the path of a repository from the path of an
+	// API endpoint within it.
+	apiPrefix = "/info/lfs/"
+)
+
+var oidRE = regexp.MustCompile(`\A[0-9a-f]{64}\z`)
+
+// Server is an http.Handler which serves the objects of each repository from
+// a directory of the same name in Storage. Each request is handled in its own
+// goroutine by net/http; objects are written to a temporary file and renamed
+// into place, so concurrent uploads of the same object are safe.
+//
+// It handles three requests for a repository "<repo>", which may contain
+// slashes:
+//
+//	POST /<repo>/info/lfs/objects/batch
+//	GET  /<repo>/info/lfs/objects/<oid>
+//	PUT  /<repo>/info/lfs/objects/<oid>
+type Server struct {
+	// Storage is the directory in which objects are stored, in
+	// "<repo>/<oid[0:2]>/<oid[2:4]>/<oid>".
+	Storage string
+	// Auth, if not nil, is the set of users who may use the server, with
+	// HTTP Basic authentication. Otherwise anyone may.
+	Auth *Htpasswd
+}
+
+type batchRequest struct {
+	Operation string         `json:"operation"`
+	Transfers []string       `json:"transfers,omitempty"`
+	Objects   []*batchObject `json:"objects"`
+}
+
+type batchResponse struct {
+	Transfer string         `json:"transfer"`
+	Objects  []*batchObject `json:"objects"`
+}
+
+type batchObject struct {
+	Oid           string                  `json:"oid"`
+	Size          int64                   `json:"size"`
+	Authenticated bool                    `json:"authenticated,omitempty"`
+	Actions       map[string]*batchAction `json:"actions,omitempty"`
+	Error         *batchError             `json:"error,omitempty"`
+}
+
+type batchAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type batchError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tracerx.Printf("serve: %s %s", r.Method, r.URL.Path)
+
+	if s.Auth != nil {
+		user, password, ok := r.BasicAuth()
+		if !ok || !s.Auth.Authenticate(user, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Git LFS"`)
+			writeError(w, http.StatusUnauthorized, "Credentials needed")
+			return
+		}
+	}
+
+	i := strings.Index(r.URL.Path, apiPrefix)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	repo, endpoint := strings.Trim(r.URL.Path[:i], "/"), r.URL.Path[i+len(apiPrefix):]
+	if !validRepo(repo) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Invalid repository %q", repo))
+		return
+	}
+
+	if endpoint == "objects/batch" {
+		if r.Method != "POST" {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		s.serveBatch(w, r, repo)
+		return
+	}
+
+	oid := strings.TrimPrefix(endpoint, "objects/")
+	if oid == endpoint || !oidRE.MatchString(oid) {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		s.serveDownload(w, r, repo, oid)
+	case "PUT":
+		s.serveUpload(w, r, repo, oid)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (s *Server) serveBatch(w http.ResponseWriter, r *http.Request, repo string) {
+	req := &batchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid request: %s", err))
+		return
+	}
+	if req.Operation != "download" && req.Operation != "upload" {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Invalid operation %q", req.Operation))
+		return
+	}
+	if len(req.Transfers) > 0 && !contains(req.Transfers, "basic") {
+		writeError(w, http.StatusUnprocessableEntity, "Only the basic transfer adapter is supported")
+		return
+	}
+
+	res := &batchResponse{Transfer: "basic", Objects: make([]*batchObject, 0, len(req.Objects))}
+	for _, obj := range req.Objects {
+		res.Objects = append(res.Objects, s.batchObject(r, repo, req.Operation, obj))
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	json.NewEncoder(w).Encode(res)
+}
+
+// batchObject returns the response to a request to upload or download "obj":
+// the action to take, if any, or an error.
+func (s *Server) batchObject(r *http.Request, repo, operation string, obj *batchObject) *batchObject {
+	res := &batchObject{Oid: obj.Oid, Size: obj.Size, Authenticated: true}
+	if !oidRE.MatchString(obj.Oid) || obj.Size < 0 {
+		res.Error = &batchError{Code: http.StatusUnprocessableEntity, Message: "Invalid object"}
+		return res
+	}
+
+	fi, err := os.Stat(s.objectPath(repo, obj.Oid))
+	exists := err == nil && fi.Mode().IsRegular()
+
+	switch {
+	case operation == "upload" && exists:
+		// The client need not do anything.
+	case operation == "upload":
+		res.Actions = map[string]*batchAction{"upload": s.action(r, repo, obj.Oid)}
+	case exists:
+		res.Size = fi.Size()
+		res.Actions = map[string]*batchAction{"download": s.action(r, repo, obj.Oid)}
+	default:
+		res.Error = &batchError{Code: http.StatusNotFound, Message: "Object does not exist"}
+	}
+	return res
+}
+
+// action returns the action with which to transfer the object "oid", at the
+// same host as the batch request "r", with the same credentials.
+func (s *Server) action(r *http.Request, repo, oid string) *batchAction {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	a := &batchAction{Href: fmt.Sprintf("%s://%s/%s%sobjects/%s", scheme, r.Host, repo, apiPrefix, oid)}
+	if auth := r.Header.Get("Authorization"); len(auth) > 0 {
+		a.Header = map[string]string{"Authorization": auth}
+	}
+	return a
+}
+
+func (s *Server) serveDownload(w http.ResponseWriter, r *http.Request, repo, oid string) {
+	f, err := os.Open(s.objectPath(repo, oid))
+	if os.IsNotExist(err) {
+		writeError(w, http.StatusNotFound, "Object does not exist")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if fi, err := f.Stat(); err == nil {
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", fi.Size()))
+	}
+	io.Copy(w, f)
+}
+
+func (s *Server) serveUpload(w http.ResponseWriter, r *http.Request, repo, oid string) {
+	dest := s.objectPath(repo, oid)
+	tmpDir := filepath.Join(s.repoPath(repo), "tmp")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tmp, err := ioutil.TempFile(tmpDir, oid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), r.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != oid {
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Expected OID %s, got %s", oid, actual))
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) repoPath(repo string) string {
+	return filepath.Join(s.Storage, filepath.FromSlash(repo))
+}
+
+func (s *Server) objectPath(repo, oid string) string {
+	return filepath.Join(s.repoPath(repo), oid[0:2], oid[2:4], oid)
+}
+
+// validRepo returns whether "repo" names a directory within the storage
+// directory, rather than the storage directory itself or anything outside it.
+func validRepo(repo string) bool {
+	if len(repo) == 0 || strings.ContainsAny(repo, `\:`) {
+		return false
+	}
+	return path.Clean("/" + repo)[1:] == repo
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package lfsserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a server which stores objects in a new temporary
+// directory, which is also returned, and a function which stops the server
+// and removes the directory.
+func newTestServer(t *testing.T, auth *Htpasswd) (*httptest.Server, string, func()) {
+	dir, err := ioutil.TempDir("", "lfsserver")
+	require.Nil(t, err)
+
+	srv := httptest.NewServer(&Server{Storage: dir, Auth: auth})
+	return srv, dir, func() {
+		srv.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func oidOf(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func batch(t *testing.T, srv *httptest.Server, repo, operation string, objects ...*batchObject) *batchResponse {
+	body, err := json.Marshal(&batchRequest{Operation: operation, Objects: objects})
+	require.Nil(t, err)
+
+	res, err := http.Post(srv.URL+"/"+repo+"/info/lfs/objects/batch", mediaType, bytes.NewReader(body))
+	require.Nil(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, mediaType, res.Header.Get("Content-Type"))
+
+	bRes := &batchResponse{}
+	require.Nil(t, json.NewDecoder(res.Body).Decode(bRes))
+	assert.Equal(t, "basic", bRes.Transfer)
+	require.Len(t, bRes.Objects, len(objects))
+	return bRes
+}
+
+func put(t *testing.T, href, content string) *http.Response {
+	req, err := http.NewRequest("PUT", href, strings.NewReader(content))
+	require.Nil(t, err)
+	res, err := http.DefaultClient.Do(req)
+	require.Nil(t, err)
+	res.Body.Close()
+	return res
+}
+
+func TestServerUploadThenDownload(t *testing.T) {
+	srv, dir, cleanup := newTestServer(t, nil)
+	defer cleanup()
+	content := "hello, world\n"
+	oid := oidOf(content)
+	obj := &batchObject{Oid: oid, Size: int64(len(content))}
+
+	bRes := batch(t, srv, "group/repo.git", "download", obj)
+	require.NotNil(t, bRes.Objects[0].Error)
+	assert.Equal(t, http.StatusNotFound, bRes.Objects[0].Error.Code)
+
+	bRes = batch(t, srv, "group/repo.git", "upload", obj)
+	upload := bRes.Objects[0].Actions["upload"]
+	require.NotNil(t, upload)
+	assert.Equal(t, srv.URL+"/group/repo.git/info/lfs/objects/"+oid, upload.Href)
+	assert.Equal(t, http.StatusOK, put(t, upload.Href, content).StatusCode)
+
+	assert.FileExists(t, filepath.Join(dir, "group", "repo.git", oid[0:2], oid[2:4], oid))
+
+	bRes = batch(t, srv, "group/repo.git", "upload", obj)
+	assert.Nil(t, bRes.Objects[0].Actions)
+	assert.Nil(t, bRes.Objects[0].Error)
+
+	bRes = batch(t, srv, "group/repo.git", "download", obj)
+	download := bRes.Objects[0].Actions["download"]
+	require.NotNil(t, download)
+
+	res, err := http.Get(download.Href)
+	require.Nil(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	data, err := ioutil.ReadAll(res.Body)
+	require.Nil(t, err)
+	assert.Equal(t, content, string(data))
+
+	// Objects belong to a single repository.
+	bRes = batch(t, srv, "other.git", "download", obj)
+	require.NotNil(t, bRes.Objects[0].Error)
+	assert.Equal(t, http.StatusNotFound, bRes.Objects[0].Error.Code)
+}
+
+func TestServerRejectsCorruptUpload(t *testing.T) {
+	srv, dir, cleanup := newTestServer(t, nil)
+	defer cleanup()
+	oid := oidOf("expected")
+
+	res := put(t, srv.URL+"/repo/info/lfs/objects/"+oid, "actual")
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	_, err := os.Stat(filepath.Join(dir, "repo", oid[0:2], oid[2:4], oid))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestServerRejectsInvalidObjects(t *testing.T) {
+	srv, _, cleanup := newTestServer(t, nil)
+	defer cleanup()
+
+	bRes := batch(t, srv, "repo", "upload",
+		&batchObject{Oid: "../../etc/passwd", Size: 1},
+		&batchObject{Oid: oidOf("x"), Size: -1})
+	for _, obj := range bRes.Objects {
+		require.NotNil(t, obj.Error)
+		assert.Equal(t, http.StatusUnprocessableEntity, obj.Error.Code)
+	}
+}
+
+func TestServerRejectsInvalidRequests(t *testing.T) {
+	srv, _, cleanup := newTestServer(t, nil)
+	defer cleanup()
+	oid := oidOf("x")
+
+	for _, test := range []struct {
+		method, path, body string
+		code               int
+	}{
+		{"POST", "/repo/info/lfs/objects/batch", `{"operation":"delete","objects":[]}`, http.StatusUnprocessableEntity},
+		{"POST", "/repo/info/lfs/objects/batch", `{"operation":"download","transfers":["tus"],"objects":[]}`, http.StatusUnprocessableEntity},
+		{"POST", "/repo/info/lfs/objects/batch", `not json`, http.StatusUnprocessableEntity},
+		{"GET", "/repo/info/lfs/objects/batch", "", http.StatusMethodNotAllowed},
+		{"DELETE", "/repo/info/lfs/objects/" + oid, "", http.StatusMethodNotAllowed},
+		{"GET", "/repo/info/lfs/objects/" + oid, "", http.StatusNotFound},
+		{"GET", "/repo/info/lfs/objects/notanoid", "", http.StatusNotFound},
+		{"GET", "/repo/info/lfs/locks", "", http.StatusNotFound},
+		{"GET", "/repo.git", "", http.StatusNotFound},
+		{"PUT", "/info/lfs/objects/" + oid, "x", http.StatusNotFound},
+		{"PUT", "/a/%2e%2e/%2e%2e/info/lfs/objects/" + oid, "x", http.StatusNotFound},
+	} {
+		req, err := http.NewRequest(test.method, srv.URL+test.path, strings.NewReader(test.body))
+		require.Nil(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		res.Body.Close()
+		assert.Equal(t, test.code, res.StatusCode, "%s %s", test.method, test.path)
+	}
+}
+
+func TestServerRequiresAuth(t *testing.T) {
+	auth, err := ReadHtpasswd(strings.NewReader("user:secret\n"))
+	require.Nil(t, err)
+	srv, _, cleanup := newTestServer(t, auth)
+	defer cleanup()
+	url := srv.URL + "/repo/info/lfs/objects/batch"
+	body := `{"operation":"upload","objects":[{"oid":"` + oidOf("x") + `","size":1}]}`
+
+	res, err := http.Post(url, mediaType, strings.NewReader(body))
+	require.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, `Basic realm="Git LFS"`, res.Header.Get("WWW-Authenticate"))
+
+	for password, code := range map[string]int{"wrong": http.StatusUnauthorized, "secret": http.StatusOK} {
+		req, err := http.NewRequest("POST", url, strings.NewReader(body))
+		require.Nil(t, err)
+		req.SetBasicAuth("user", password)
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		assert.Equal(t, code, res.StatusCode)
+
+		if code == http.StatusOK {
+			bRes := &batchResponse{}
+			require.Nil(t, json.NewDecoder(res.Body).Decode(bRes))
+			// Actions carry the credentials of the batch request.
+			assert.Equal(t, req.Header.Get("Authorization"), bRes.Objects[0].Actions["upload"].Header["Authorization"])
+		}
+		res.Body.Close()
+	}
+}
+
+func TestServerConcurrentUploads(t *testing.T) {
+	srv, _, cleanup := newTestServer(t, nil)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		// Each object is uploaded twice, at the same time.
+		content := fmt.Sprintf("object %d", i%10)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest("PUT", srv.URL+"/repo/info/lfs/objects/"+oidOf(content), strings.NewReader(content))
+			res, err := http.DefaultClient.Do(req)
+			if assert.Nil(t, err) {
+				res.Body.Close()
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	objects := make([]*batchObject, 0, 10)
+	for i := 0; i < 10; i++ {
+		content := fmt.Sprintf("object %d", i)
+		objects = append(objects, &batchObject{Oid: oidOf(content), Size: int64(len(content))})
+	}
+	for _, obj := range batch(t, srv, "repo", "download", objects...).Objects {
+		assert.Nil(t, obj.Error)
+		assert.NotNil(t, obj.Actions["download"])
+	}
+}
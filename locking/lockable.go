@@ -168,7 +168,7 @@ func (c *Client) fixSingleFileWriteFlags(file string, lockable, unlockable *file
 	}
 	if lockable != nil && lockable.Allows(file) {
 		// Lockable files are writeable only if they're currently locked
-		err := tools.SetFileWriteFlag(file, c.IsFileLockedByCurrentCommitter(file))
+		err := setFileWriteFlag(file, c.IsFileLockedByCurrentCommitter(file))
 		// Ignore not exist errors
 		if err != nil && !os.IsNotExist(err) {
 			return err
@@ -178,10 +178,23 @@ func (c *Client) fixSingleFileWriteFlags(file string, lockable, unlockable *file
 		// We only check files which match the incoming patterns to avoid
 		// checking every file in the system all the time, and only do it
 		// when a file has had its lockable attribute removed
-		err := tools.SetFileWriteFlag(file, true)
+		err := setFileWriteFlag(file, true)
 		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
 	}
 	return nil
 }
+
+// setFileWriteFlag changes the write permissions on a file as
+// tools.SetFileWriteFlag does, except that a file which is hard linked to an
+// object, as with lfs.link=hardlink, is first replaced by a copy of itself
+// before it is made writeable, so that writing to it cannot modify the object.
+func setFileWriteFlag(path string, writeEnabled bool) error {
+	if writeEnabled {
+		if err := tools.BreakHardLink(path); err != nil {
+			return err
+		}
+	}
+	return tools.SetFileWriteFlag(path, writeEnabled)
+}
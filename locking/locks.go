@@ -127,7 +127,7 @@ func (c *Client) LockFile(path string) (Lock, error) {
 
 	// If the file exists, ensure that it's writeable on return
 	if tools.FileExists(abs) {
-		if err := tools.SetFileWriteFlag(abs, true); err != nil {
+		if err := setFileWriteFlag(abs, true); err != nil {
 			return Lock{}, errors.Wrap(err, "set file write flag")
 		}
 	}
@@ -187,9 +187,12 @@ func (c *Client) UnlockFileById(id string, force bool) error {
 			return errors.Wrap(err, "make lockpath absolute")
 		}
 
-		// Make non-writeable if required
+		// Make non-writeable if required, unless the file has been
+		// removed or renamed since it was locked.
 		if c.SetLockableFilesReadOnly && c.IsFileLockable(unlockRes.Lock.Path) {
-			return tools.SetFileWriteFlag(abs, false)
+			if err := tools.SetFileWriteFlag(abs, false); err != nil && !os.IsNotExist(err) {
+				return err
+			}
 		}
 	}
 
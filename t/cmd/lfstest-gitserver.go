@@ -1573,6 +1573,12 @@ func skipIfBadAuth(w http.ResponseWriter, r *http.Request, id string, ntlmSessio
 		}
 	case "netrcuser", "requirecreds":
 		return false
+	case "expired":
+		// Simulate credentials which were valid once, but have since
+		// expired, and so must be rejected by the client.
+		w.WriteHeader(401)
+		debug(id, "Expired auth: %q", auth)
+		return true
 	case "path":
 		if strings.HasPrefix(r.URL.Path, "/"+pass) {
 			return false
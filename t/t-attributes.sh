@@ -84,3 +84,178 @@ begin_test "macros with HOME split"
   grep '"*.dat" already supported' track.log
 )
 end_test
+
+begin_test "symlinked .gitattributes are skipped"
+(
+  set -e
+
+  reponame="attributes-symlink"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf '*.bin filter=lfs diff=lfs merge=lfs -text\n' > other.attrs
+  mkdir dir
+  ln -s ../other.attrs dir/.gitattributes
+
+  git lfs track 2>track.log >list.log
+  cat track.log list.log
+
+  grep "Skipping symlinked .gitattributes: dir/.gitattributes" track.log
+  [ "1" -eq "$(grep -c "Skipping symlinked" track.log)" ]
+  grep '\*.dat' list.log
+  [ "0" -eq "$(grep -c '\*.bin' list.log)" ]
+)
+end_test
+
+begin_test "lfs-fetch=false"
+(
+  set -e
+
+  reponame="attributes-fetch"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  git lfs track --attribute lfs-fetch=false "*.psd"
+  grep '^\*\.psd filter=lfs diff=lfs merge=lfs -text lfs-fetch=false$' .gitattributes
+
+  printf "%s" "dat" > a.dat
+  printf "%s" "psd" > a.psd
+  dat_oid="$(calc_oid "dat")"
+  psd_oid="$(calc_oid "psd")"
+  git add .gitattributes a.dat a.psd
+  git commit -m "add files"
+  git push origin main
+
+  cd ..
+  git clone "$GITSERVER/$reponame" "$reponame-clone"
+  cd "$reponame-clone"
+
+  [ "dat" = "$(cat a.dat)" ]
+  assert_local_object "$dat_oid" 3
+  grep "^oid sha256:$psd_oid$" a.psd
+  refute_local_object "$psd_oid"
+
+  git lfs fetch
+  git lfs pull
+  refute_local_object "$psd_oid"
+  grep "^oid sha256:$psd_oid$" a.psd
+
+  git lfs fetch --include "*.psd"
+  assert_local_object "$psd_oid" 3
+  git lfs checkout a.psd
+  [ "psd" = "$(cat a.psd)" ]
+)
+end_test
+
+begin_test "lfs-verify"
+(
+  set -e
+
+  reponame="attributes-verify"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "*.dat filter=lfs diff=lfs merge=lfs -text lfs-verify\n" > .gitattributes
+  printf "*.bin filter=lfs diff=lfs merge=lfs -text -lfs-verify\n" >> .gitattributes
+  printf "%s" "a data" > a.dat
+  printf "%s" "a bin" > a.bin
+  git add .gitattributes a.dat a.bin
+  git commit -m "add files"
+
+  dat_oid="$(calc_oid "a data")"
+  bin_oid="$(calc_oid "a bin")"
+  dat_pointer="$(git cat-file -p ":a.dat")"
+
+  # Damage the objects without changing their lengths.
+  printf "%s" "b data" > ".git/lfs/objects/${dat_oid:0:2}/${dat_oid:2:2}/$dat_oid"
+  printf "%s" "b bin" > ".git/lfs/objects/${bin_oid:0:2}/${bin_oid:2:2}/$bin_oid"
+  printf "%s\n" "$dat_pointer" > a.dat
+  git cat-file -p ":a.bin" > a.bin
+
+  # lfs-verify overrides lfs.checkoutverify in both directions.
+  git -c lfs.checkoutverify=true lfs checkout 2>&1 | tee checkout.log
+  grep "hash mismatch" checkout.log
+  [ "$dat_pointer" = "$(cat a.dat)" ]
+  [ "b bin" = "$(cat a.bin)" ]
+)
+end_test
+
+begin_test "lfs-ext"
+(
+  set -e
+
+  reponame="attributes-ext"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.extension.rot13.clean "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.smudge "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.priority 0
+  git config lfs.extension.rev.clean "rev"
+  git config lfs.extension.rev.smudge "rev"
+  git config lfs.extension.rev.priority 1
+
+  git lfs track "*.all"
+  git lfs track --attribute lfs-ext=rot13 "*.rot"
+  git lfs track --attribute lfs-ext=rev,rot13 "*.both"
+  printf "*.none filter=lfs diff=lfs merge=lfs -text -lfs-ext\n" >> .gitattributes
+  git lfs track --attribute lfs-ext=enc "*.enc"
+
+  for ext in all rot both none enc; do
+    printf "hello world\n" > "a.$ext"
+  done
+  git add .gitattributes a.all a.rot a.both a.none
+
+  git cat-file -p :a.all | grep "^ext-0-rot13 "
+  git cat-file -p :a.all | grep "^ext-1-rev "
+  git cat-file -p :a.rot | grep "^ext-0-rot13 "
+  git cat-file -p :a.rot | grep "^ext-1-rev " && exit 1
+  git cat-file -p :a.both | grep "^ext-0-rot13 "
+  git cat-file -p :a.both | grep "^ext-1-rev "
+  git cat-file -p :a.none | grep "^ext-" && exit 1
+
+  set +e
+  git add a.enc 2> add.log
+  res=$?
+  set -e
+  cat add.log
+  [ "$res" -ne 0 ]
+  grep "a.enc requires the extension 'enc' (lfs-ext=enc), which is not configured" add.log
+  git cat-file -e :a.enc && exit 1
+
+  git commit -m "add files"
+  rm a.all a.rot a.both a.none
+  git checkout -- .
+  for ext in all rot both none; do
+    [ "hello world" = "$(cat "a.$ext")" ]
+  done
+)
+end_test
+
+begin_test "unknown lfs- attributes"
+(
+  set -e
+
+  reponame="attributes-unknown"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "*.dat filter=lfs diff=lfs merge=lfs -text lfs-fecth=false\n" > .gitattributes
+  printf "*.bin filter=lfs diff=lfs merge=lfs -text lfs-fecth=false\n" >> .gitattributes
+
+  git lfs track 2>&1 | tee track.log
+  [ "1" -eq "$(grep -c 'Ignoring unknown Git LFS attribute "lfs-fecth" in .gitattributes' track.log)" ]
+
+  set +e
+  git lfs track --attribute lfs-fecth=false "*.psd" 2>&1 | tee track.log
+  res="${PIPESTATUS[0]}"
+  set -e
+  [ "$res" -ne 0 ]
+  grep 'Unknown Git LFS attribute "lfs-fecth"' track.log
+  grep "psd" .gitattributes && exit 1
+
+  exit 0
+)
+end_test
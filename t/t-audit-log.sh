@@ -0,0 +1,92 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+write_operation_log() {
+  mkdir -p .git/lfs
+  cat > .git/lfs/operation.log <<-LOG
+{"time":"2020-01-01T10:00:00Z","user":"alice","type":"push","ref":"refs/heads/main","objects":2,"bytes":2048}
+not json
+{"time":"2020-01-02T10:00:00Z","user":"bob","type":"fetch","ref":"refs/heads/main","objects":1,"bytes":100}
+{"time":"2020-01-03T10:00:00Z","user":"alice","type":"push","ref":"refs/heads/topic","objects":1,"bytes":1024}
+LOG
+}
+
+begin_test "audit-log"
+(
+  set -e
+
+  reponame="audit-log"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs audit-log >out.log 2>&1
+  [ "TIME  USER  TYPE  OBJECTS  SIZE  REF" = "$(cat out.log)" ]
+
+  write_operation_log
+
+  git lfs audit-log >out.log 2>err.log
+  cat out.log err.log
+  [ 4 -eq "$(wc -l < out.log)" ]
+  grep "alice *push *2 *2.0 KB *refs/heads/main" out.log
+  grep "bob *fetch *1 *100 B *refs/heads/main" out.log
+  grep "Skipping malformed line 2 of" err.log
+
+  git lfs audit-log --user=alice --type=push --since=2020-01-02 >out.log 2>/dev/null
+  [ 2 -eq "$(wc -l < out.log)" ]
+  grep "refs/heads/topic" out.log
+
+  git lfs audit-log --until=2020-01-02 >out.log 2>/dev/null
+  [ 2 -eq "$(wc -l < out.log)" ]
+  grep "refs/heads/main" out.log
+)
+end_test
+
+begin_test "audit-log --format"
+(
+  set -e
+
+  reponame="audit-log-format"
+  git init "$reponame"
+  cd "$reponame"
+  write_operation_log
+
+  git lfs audit-log --format=csv --type=fetch >out.log 2>/dev/null
+  [ "time,user,type,ref,objects,bytes
+2020-01-02T10:00:00Z,bob,fetch,refs/heads/main,1,100" = "$(cat out.log)" ]
+
+  git lfs audit-log --format=json --user=bob >out.log 2>/dev/null
+  [ '[{"time":"2020-01-02T10:00:00Z","user":"bob","type":"fetch","ref":"refs/heads/main","objects":1,"bytes":100}]' = "$(cat out.log)" ]
+
+  git lfs audit-log --format=json --user=nobody >out.log 2>/dev/null
+  [ "[]" = "$(cat out.log)" ]
+
+  set +e
+  git lfs audit-log --format=xml 2>err.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "Invalid --format" err.log
+)
+end_test
+
+begin_test "audit-log --summary"
+(
+  set -e
+
+  reponame="audit-log-summary"
+  git init "$reponame"
+  cd "$reponame"
+  write_operation_log
+
+  git lfs audit-log --summary >out.log 2>/dev/null
+  cat out.log
+  grep "fetch *1 *1 *100 B" out.log
+  grep "push *2 *3 *3.1 KB" out.log
+
+  git lfs audit-log --summary --format=csv >out.log 2>/dev/null
+  [ "type,operations,objects,bytes
+fetch,1,1,100
+push,2,3,3072" = "$(cat out.log)" ]
+)
+end_test
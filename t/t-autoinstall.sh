@@ -0,0 +1,82 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# setup_unconfigured_home points HOME at a Git config without the "lfs"
+# filter, as on a machine where `git lfs install` was never run.
+setup_unconfigured_home() {
+  export HOME="$TRASHDIR/home-$1"
+  mkdir -p "$HOME"
+  git config --global user.name "Git LFS Tests"
+  git config --global user.email "git-lfs@example.com"
+  git config --global init.defaultBranch main
+}
+
+begin_test "autoinstall: warns once when not interactive"
+(
+  set -e
+
+  reponame="autoinstall-warn"
+  git init "$reponame"
+  cd "$reponame"
+  git lfs track "*.dat"
+  setup_unconfigured_home "$reponame"
+
+  git lfs ls-files 2>err.log </dev/null
+  cat err.log
+  grep "WARNING: Git LFS is not set up for this repository" err.log
+  grep "Run \`git lfs install --local\` to set it up" err.log
+  [ -z "$(git config --local filter.lfs.process)" ]
+
+  git lfs ls-files 2>err.log </dev/null
+  [ "0" -eq "$(grep -c "WARNING" err.log)" ]
+)
+end_test
+
+begin_test "autoinstall: lfs.autoinstall=true"
+(
+  set -e
+
+  reponame="autoinstall-true"
+  git init "$reponame"
+  cd "$reponame"
+  git lfs track "*.dat"
+  setup_unconfigured_home "$reponame"
+
+  git -c lfs.autoinstall=true lfs ls-files 2>err.log </dev/null
+  cat err.log
+  grep "Set up Git LFS for this repository" err.log
+  [ "git-lfs filter-process" = "$(git config --local filter.lfs.process)" ]
+  [ "git-lfs smudge -- %f" = "$(git config --local filter.lfs.smudge)" ]
+  [ -z "$(git config --global filter.lfs.process)" ]
+  [ -x .git/hooks/pre-push ]
+
+  git lfs ls-files 2>err.log </dev/null
+  [ "0" -eq "$(grep -c "Git LFS" err.log)" ]
+)
+end_test
+
+begin_test "autoinstall: lfs.autoinstall=false and untracked repositories"
+(
+  set -e
+
+  reponame="autoinstall-false"
+  git init "$reponame"
+  cd "$reponame"
+  setup_unconfigured_home "$reponame"
+
+  # Nothing is tracked by Git LFS.
+  git -c lfs.autoinstall=true lfs ls-files 2>err.log </dev/null
+  [ "0" -eq "$(grep -c "Git LFS" err.log)" ]
+  [ -z "$(git config --local filter.lfs.process)" ]
+
+  echo "*.dat filter=lfs diff=lfs merge=lfs -text" > .gitattributes
+  git -c lfs.autoinstall=false lfs ls-files 2>err.log </dev/null
+  [ "0" -eq "$(grep -c "Git LFS" err.log)" ]
+  [ ! -e .git/lfs/autoinstall-prompted ]
+
+  # Commands which set up Git LFS themselves are left alone.
+  git lfs install --check >/dev/null 2>err.log </dev/null || true
+  [ "0" -eq "$(grep -c "WARNING" err.log)" ]
+)
+end_test
@@ -106,6 +106,177 @@ begin_test "checkout"
 )
 end_test
 
+begin_test "checkout --progress"
+(
+  set -e
+
+  reponame="checkout-progress"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "a" > a.dat
+  printf "%s" "bb" > b.dat
+  printf "%s" "ccc" > c.dat
+  git add .gitattributes *.dat
+  git commit -m "add files"
+
+  rm *.dat
+  git lfs checkout --progress 2>checkout.log >/dev/null
+  cat checkout.log
+
+  [ "a" = "$(cat a.dat)" ]
+  [ "3" -eq "$(grep -c "Checking out LFS objects: [0-9]/3" checkout.log)" ]
+  grep "Checking out LFS objects: 1/3 (1 B/6 B)" checkout.log
+  grep "Checking out LFS objects: 3/3 (6 B/6 B)" checkout.log
+
+  # Without --progress, nothing is written when standard error is not a
+  # terminal.
+  rm *.dat
+  git lfs checkout 2>checkout.log >/dev/null
+  [ ! -s checkout.log ]
+)
+end_test
+
+begin_test "checkout --index"
+(
+  set -e
+
+  reponame="checkout-index"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "committed" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  printf "%s" "staged" > a.dat
+  git add a.dat
+  git commit -m "modify a.dat"
+
+  # Move HEAD back, leaving the newer version staged, and replace the working
+  # copy with the staged pointer.
+  git reset --soft HEAD~1
+  git cat-file -p ":a.dat" > a.dat
+  assert_pointer "HEAD" "a.dat" "$(calc_oid "committed")" 9
+  grep "oid sha256:$(calc_oid "staged")" a.dat
+
+  # The pointer does not match HEAD, so it is left alone.
+  git lfs checkout
+  grep "oid sha256:$(calc_oid "staged")" a.dat
+
+  git lfs checkout --index
+  [ "staged" = "$(cat a.dat)" ]
+  git cat-file -p ":a.dat" | grep "oid sha256:$(calc_oid "staged")"
+
+  # Files which are staged but not yet committed are checked out too.
+  printf "%s" "new" > b.dat
+  git add b.dat
+  git cat-file -p ":b.dat" > b.dat
+  git lfs checkout --index b.dat
+  [ "new" = "$(cat b.dat)" ]
+)
+end_test
+
+begin_test "checkout: lfs.checkoutverify"
+(
+  set -e
+
+  reponame="checkout-verify"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  contents="a data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  objPath=".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+  pointer="$(git cat-file -p ":a.dat")"
+
+  # Damage the object without changing its length.
+  printf "%s" "b data" > "$objPath"
+  printf "%s\n" "$pointer" > a.dat
+
+  git -c lfs.checkoutverify=true lfs checkout 2>&1 | tee checkout.log
+  grep "hash mismatch" checkout.log
+  [ "$pointer" = "$(cat a.dat)" ]
+
+  # Append trailing data to the object.
+  printf "%s\n" "$contents" > "$objPath"
+
+  git -c lfs.checkoutverify=true lfs checkout 2>&1 | tee checkout.log
+  grep "size mismatch: object $contents_oid is 7 bytes, but its pointer records 6 bytes" checkout.log
+  [ "$pointer" = "$(cat a.dat)" ]
+
+  printf "%s" "$contents" > "$objPath"
+  git -c lfs.checkoutverify=true lfs checkout
+  [ "$contents" = "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "checkout: lfs.link=hardlink"
+(
+  set -e
+
+  reponame="checkout-hardlink"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  contents="a data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  objPath=".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+  inode () {
+    ls -i "$1" | awk '{ print $1 }'
+  }
+
+  rm a.dat
+  git lfs checkout a.dat
+  [ "$contents" = "$(cat a.dat)" ]
+  [ "$(inode a.dat)" != "$(inode "$objPath")" ]
+
+  git config lfs.link hardlink
+  rm a.dat
+  git lfs checkout --verbose a.dat 2>&1 | tee checkout.log
+  grep "Linked a.dat to its object" checkout.log
+  [ "$contents" = "$(cat a.dat)" ]
+  [ "$(inode a.dat)" = "$(inode "$objPath")" ]
+  refute_file_writeable "$objPath"
+
+  # A file whose object is transformed by an extension is copied.
+  git config lfs.extension.rot13.clean "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.smudge "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.priority 0
+  printf "%s" "b data" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  rm b.dat
+  git lfs checkout --verbose b.dat 2>&1 | tee checkout.log
+  grep "Copied b.dat, which could not be linked to its object: its object is transformed by extensions" checkout.log
+  [ "b data" = "$(cat b.dat)" ]
+
+  git lfs checkout a.dat 2>&1 | tee checkout.log
+  [ "0" -eq "$(grep -c "Linked" checkout.log)" ]
+
+  # Modifying the working tree file in place damages the object, which fsck
+  # detects.
+  chmod u+w a.dat
+  printf "%s" "b data" > a.dat
+  git lfs fsck --objects 2>&1 | tee fsck.log
+  [ "0" -ne "${PIPESTATUS[0]}" ]
+  grep "Object a.dat ($contents_oid) is corrupt" fsck.log
+  grep "It has 2 hard links" fsck.log
+)
+end_test
+
 begin_test "checkout: without clean filter"
 (
   set -e
@@ -224,3 +395,63 @@ begin_test "checkout: conflicts"
   popd > /dev/null
 )
 end_test
+
+begin_test "checkout: --source"
+(
+  set -e
+
+  reponame="checkout-source"
+  setup_remote_repo "$reponame"
+  setup_remote_repo "$reponame-mirror"
+  clone_repo "$reponame" "$reponame"
+
+  git remote add mirror "$GITSERVER/$reponame-mirror"
+
+  git lfs track "*.dat"
+  mkdir art
+  printf "art" > art/a.dat
+  printf "src" > b.dat
+  git add .gitattributes art/a.dat b.dat
+  git commit -m "add art/a.dat, b.dat"
+  git push origin main
+
+  art_oid="$(calc_oid "art")"
+  src_oid="$(calc_oid "src")"
+
+  # Only the mirror has the art, and lfs.url points at origin.
+  git lfs push --object-id mirror "$art_oid"
+  delete_server_object "$reponame" "$art_oid"
+  git config lfs.url "$GITSERVER/$reponame.git/info/lfs"
+
+  rm -rf .git/lfs/objects art/a.dat b.dat
+  GIT_LFS_SKIP_SMUDGE=1 git checkout -- art/a.dat b.dat
+  assert_pointer "main" "art/a.dat" "$art_oid" 3
+
+  git lfs checkout --source=mirror --include="art/**" 2>&1 | tee checkout.log
+  [ "art" = "$(cat art/a.dat)" ]
+  grep "version https://git-lfs" b.dat
+  refute_local_object "$src_oid"
+
+  git lfs checkout --source=origin 2>&1 | tee checkout.log
+  [ "src" = "$(cat b.dat)" ]
+
+  # An object which cannot be downloaded is left as a pointer, and the
+  # command fails once the others are checked out.
+  rm -rf .git/lfs/objects art/a.dat b.dat
+  GIT_LFS_SKIP_SMUDGE=1 git checkout -- art/a.dat b.dat
+
+  set +e
+  git lfs checkout --source=origin > checkout.log 2>&1
+  res=$?
+  set -e
+
+  cat checkout.log
+  [ "$res" = "5" ]
+  grep "failed to fetch some objects" checkout.log
+  [ "src" = "$(cat b.dat)" ]
+  grep "version https://git-lfs" art/a.dat
+
+  git lfs checkout --source=no-such-remote > checkout.log 2>&1 && exit 1
+  grep "Invalid remote name" checkout.log
+)
+end_test
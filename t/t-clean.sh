@@ -87,3 +87,62 @@ begin_test "clean stdin"
   fi
 )
 end_test
+
+begin_test "clean --stdin-filename"
+(
+  set -e
+
+  reponame="clean-stdin-filename"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "attrs" > attrs.txt
+  base64 /dev/urandom | head -c 2048 > large.dat
+
+  # Files on the blocklist are written out as they are.
+  git lfs clean --stdin-filename=.gitattributes < attrs.txt > out.txt 2> err.txt
+  [ "attrs" = "$(cat out.txt)" ]
+  grep "Not storing .gitattributes in Git LFS" err.txt
+  git lfs clean -- .lfsconfig < attrs.txt > out.txt 2> err.txt
+  [ "attrs" = "$(cat out.txt)" ]
+
+  # The flag takes precedence over the argument.
+  git lfs clean --stdin-filename=dir/.lfsconfig -- a.dat < attrs.txt > out.txt 2> err.txt
+  [ "attrs" = "$(cat out.txt)" ]
+  grep "Not storing dir/.lfsconfig in Git LFS" err.txt
+
+  git config lfs.maxobjectsize 1KB
+  git lfs clean --stdin-filename=large.dat < large.dat > out.txt 2> err.txt
+  grep "oid sha256:$(calc_oid_file large.dat)" out.txt
+  grep "large.dat (2.0 KB) is larger than lfs.maxobjectsize (1.0 KB)" err.txt
+
+  # Without a file name, neither check is made.
+  git lfs clean < large.dat > out.txt 2> err.txt
+  grep "oid sha256:$(calc_oid_file large.dat)" out.txt
+  [ ! -s err.txt ]
+  git lfs clean < attrs.txt > out.txt
+  grep "oid sha256:$(calc_oid "attrs")" out.txt
+)
+end_test
+
+begin_test "clean through filter-process checks the file name"
+(
+  set -e
+
+  reponame="clean-filter-process-file-name"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*"
+  git config lfs.maxobjectsize 1KB
+  base64 /dev/urandom | head -c 2048 > large.dat
+
+  git add .gitattributes large.dat 2>&1 | tee add.log
+  grep "Not storing .gitattributes in Git LFS" add.log
+  grep "large.dat (2.0 KB) is larger than lfs.maxobjectsize (1.0 KB)" add.log
+
+  git commit --no-verify -m "add files"
+  refute_pointer "main" ".gitattributes"
+  assert_pointer "main" "large.dat" "$(calc_oid_file large.dat)" 2048
+)
+end_test
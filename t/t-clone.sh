@@ -756,3 +756,136 @@ begin_test "clone (HTTP server/proxy require cookies)"
   rm "$CREDSDIR/localhost"
 )
 end_test
+
+begin_test "clone (with missing objects)"
+(
+  set -e
+
+  reponame="clone-missing-objects"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "present" > present.dat
+  printf "missing" > missing.dat
+  git add .gitattributes present.dat missing.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  missing_oid="$(calc_oid "missing")"
+  delete_server_object "$reponame" "$missing_oid"
+
+  cd "$TRASHDIR"
+  set +e
+  git lfs clone "$GITSERVER/$reponame" "$reponame-clone" >clone.log 2>&1
+  res=$?
+  set -e
+  cat clone.log
+  [ "$res" -ne 0 ]
+  grep "some Git LFS objects could not be downloaded" clone.log
+  grep "run \`git lfs pull\` in that directory" clone.log
+
+  # The clone is kept, with the missing object's file left as a pointer.
+  cd "$reponame-clone"
+  assert_hooks "$(dot_git_dir)"
+  [ "present" = "$(cat present.dat)" ]
+  [ "$(pointer "$missing_oid" 7)" = "$(cat missing.dat)" ]
+  [ -z "$(git status --porcelain)" ]
+
+  # Once the object is available again, pulling finishes the clone.
+  pushd "$TRASHDIR/$reponame"
+    git lfs push --object-id origin "$missing_oid"
+  popd
+  git lfs pull
+  [ "missing" = "$(cat missing.dat)" ]
+  [ -z "$(git status --porcelain)" ]
+)
+end_test
+
+begin_test "clone (shallow)"
+(
+  set -e
+
+  reponame="clone-shallow"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "old" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "old"
+  printf "new" > a.dat
+  git add a.dat
+  git commit -m "new"
+  git push origin main
+
+  cd "$TRASHDIR"
+  git lfs clone --depth=1 "$GITSERVER/$reponame" "$reponame-shallow"
+
+  cd "$reponame-shallow"
+  [ -f "$(dot_git_dir)/shallow" ]
+  [ "new" = "$(cat a.dat)" ]
+  [ -z "$(git status --porcelain)" ]
+  [ "0" = "$(git config --local lfs.fetchrecentrefsdays)" ]
+  [ "0" = "$(git config --local lfs.fetchrecentcommitsdays)" ]
+  refute_local_object "$(calc_oid "old")"
+
+  # Fetching recent refs and commits is skipped, since the history it would
+  # need was not cloned.
+  git lfs fetch --recent 2>&1 | tee fetch.log
+  grep "Fetching recent" fetch.log && exit 1
+  grep "Fetching changes within" fetch.log && exit 1
+  refute_local_object "$(calc_oid "old")"
+
+  # Settings given to the clone are kept.
+  cd "$TRASHDIR"
+  git lfs clone --depth=1 --config lfs.fetchrecentcommitsdays=3 "$GITSERVER/$reponame" "$reponame-configured"
+  [ "3" = "$(git -C "$reponame-configured" config --local lfs.fetchrecentcommitsdays)" ]
+  [ "0" = "$(git -C "$reponame-configured" config --local lfs.fetchrecentrefsdays)" ]
+
+  # A full clone is left alone.
+  git lfs clone "$GITSERVER/$reponame" "$reponame-full"
+  [ -z "$(git -C "$reponame-full" config --local lfs.fetchrecentrefsdays)" ]
+)
+end_test
+
+begin_test "clone (with lfs.minclientversion)"
+(
+  set -e
+
+  reponame="clone-min-client-version"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "contents" > a.dat
+  git config -f .lfsconfig lfs.minclientversion "999.0.0"
+  git add .gitattributes .lfsconfig a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  # The version is checked in the new clone, which is still downloaded and
+  # checked out.
+  cd "$TRASHDIR"
+  git lfs clone "$GITSERVER/$reponame" "$reponame-clone" 2>&1 | tee clone.log
+  grep "warning: this repository requires Git LFS 999.0.0 or newer" clone.log
+  [ "contents" = "$(cat "$reponame-clone/a.dat")" ]
+
+  # A local clone works too, once it is inside the new repository.
+  git lfs clone "$reponame-clone" "$reponame-local" 2>&1 | tee clone.log
+  grep "Not in a git repository" clone.log && exit 1
+  grep "warning: this repository requires Git LFS 999.0.0 or newer" clone.log
+  [ -d "$reponame-local/.git" ]
+
+  # When the version is enforced, nothing is downloaded.
+  set +e
+  git lfs clone --config lfs.enforceminclientversion=true \
+    "$GITSERVER/$reponame" "$reponame-enforced" >clone.log 2>&1
+  res=$?
+  set -e
+  cat clone.log
+  [ "$res" = "2" ]
+  grep "error: this repository requires Git LFS 999.0.0 or newer" clone.log
+  [ "$(pointer "$(calc_oid "contents")" 8)" = "$(cat "$reponame-enforced/a.dat")" ]
+)
+end_test
@@ -0,0 +1,122 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "completion: scripts"
+(
+  set -e
+
+  for shell in bash zsh fish; do
+    git lfs completion "$shell" > "completion.$shell"
+    grep "git lfs __complete" "completion.$shell"
+  done
+
+  set +e
+  git lfs completion tcsh 2>err.log
+  res=$?
+  set -e
+
+  [ "$res" -eq 2 ]
+  grep "Unknown shell \"tcsh\": must be bash, zsh, or fish" err.log
+)
+end_test
+
+begin_test "completion: commands and flags"
+(
+  set -e
+
+  [ "untrack" = "$(git lfs __complete untr)" ]
+  git lfs __complete "" > commands.log
+  grep "^fetch$" commands.log
+  grep "^completion$" commands.log
+  [ "0" -eq "$(grep -c "__complete" commands.log)" ]
+
+  [ "--recent" = "$(git lfs __complete fetch --rec)" ]
+  [ "--max-commit-size" = "$(git lfs __complete migrate import --max)" ]
+  git lfs __complete fetch - > flags.log
+  grep "^--quiet$" flags.log
+)
+end_test
+
+begin_test "completion: repository arguments"
+(
+  set -e
+
+  reponame="completion-repository"
+  git init "$reponame"
+  cd "$reponame"
+
+  git remote add origin "$GITSERVER/$reponame"
+  git remote add upstream "$GITSERVER/$reponame-upstream"
+  git commit --allow-empty -m "initial commit"
+  git branch feature
+  git tag v1.0
+
+  git lfs track "*.dat" > /dev/null
+  git lfs track "*.psd" --lockable > /dev/null
+  touch a.dat b.psd "c d.psd"
+  mkdir dir
+  touch dir/e.psd
+
+  diff -u <(printf "origin\nupstream\n") <(git lfs __complete fetch "")
+  diff -u <(printf "upstream\n") <(git lfs __complete lock --remote u)
+  diff -u <(printf -- "--remote=origin\n") <(git lfs __complete unlock --remote=o)
+  diff -u <(printf "feature\nv1.0\n") <(git lfs __complete push origin "" | grep -v "^main$")
+  diff -u <(printf "*.dat\n*.psd\n") <(git lfs __complete untrack "")
+  diff -u <(printf "b.psd\nc d.psd\ndir/e.psd\n") <(git lfs __complete lock "")
+
+  cd dir
+  diff -u <(printf "e.psd\n") <(git lfs __complete unlock "")
+)
+end_test
+
+begin_test "completion: outside a repository"
+(
+  set -e
+
+  git lfs __complete fetch "" > out.log 2> err.log
+  git lfs __complete lock "" >> out.log 2>> err.log
+  git lfs __complete untrack "" >> out.log 2>> err.log
+  [ ! -s out.log ]
+  [ ! -s err.log ]
+)
+end_test
+
+begin_test "completion: bash"
+(
+  set -e
+
+  reponame="completion-bash"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.psd" --lockable > /dev/null
+  touch "a b.psd"
+
+  source <(git lfs completion bash)
+  complete -p git-lfs | grep "__git_lfs_main"
+
+  COMP_WORDS=(git-lfs lock "")
+  COMP_CWORD=2
+  __git_lfs_main
+  [ "a\\ b.psd" = "${COMPREPLY[*]}" ]
+
+  COMP_WORDS=(git-lfs untrack "")
+  COMP_CWORD=2
+  __git_lfs_main
+  [ "\\*.psd" = "${COMPREPLY[*]}" ]
+
+  # The shell splits "--flag=value" at the "=".
+  COMP_WORDS=(git-lfs lock --remote = o)
+  COMP_CWORD=4
+  git remote add origin "$GITSERVER/$reponame"
+  __git_lfs_main
+  [ "origin" = "${COMPREPLY[*]}" ]
+
+  # Git's own completion calls _git_lfs.
+  words=(git -C . lfs untr)
+  cword=4
+  _git_lfs
+  [ "untrack " = "${COMPREPLY[*]}" ]
+)
+end_test
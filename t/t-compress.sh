@@ -0,0 +1,138 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "compress: clean and smudge files with lfs-compress"
+(
+  set -e
+
+  reponame="compress"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  printf "*.csv filter=lfs diff=lfs merge=lfs -text lfs-compress=1KB\n" > .gitattributes
+  printf "*.dat filter=lfs diff=lfs merge=lfs -text\n" >> .gitattributes
+
+  for i in $(seq 1 500); do echo "$i,name,value"; done > big.csv
+  printf "a,b,c\n" > small.csv
+  head -c 4096 /dev/urandom > random.csv
+  cp big.csv other.dat
+
+  big_oid="$(calc_oid_file big.csv)"
+  big_size="$(wc -c < big.csv | tr -d ' ')"
+
+  git add .gitattributes *.csv other.dat
+  git commit -m "add files"
+
+  pointer="$(git cat-file -p :big.csv)"
+  echo "$pointer" | grep "^ext-0-compress sha256:$big_oid $big_size$"
+  stored_oid="$(echo "$pointer" | grep "^oid " | cut -d : -f 2)"
+  stored_size="$(echo "$pointer" | grep "^size " | cut -d " " -f 2)"
+  [ "$stored_oid" != "$big_oid" ]
+  [ "$stored_size" -lt "$big_size" ]
+  assert_local_object "$stored_oid" "$stored_size"
+
+  # Files which are too small, which do not compress well, or which do not
+  # have the attribute, are stored as they are.
+  for file in small.csv random.csv other.dat; do
+    git cat-file -p ":$file" | grep "^ext-" && exit 1
+    assert_pointer "main" "$file" "$(calc_oid_file "$file")" "$(wc -c < "$file" | tr -d ' ')"
+  done
+
+  # The server only sees the compressed object.
+  git push origin main
+  assert_server_object "$reponame" "$stored_oid"
+
+  cd ..
+  GIT_TRACE=0 git clone "$GITSERVER/$reponame" "$reponame-clone"
+  cd "$reponame-clone"
+  [ "$big_oid" = "$(calc_oid_file big.csv)" ]
+  cmp big.csv ../"$reponame"/big.csv
+  cmp random.csv ../"$reponame"/random.csv
+  [ -z "$(git status --porcelain)" ]
+)
+end_test
+
+begin_test "compress: smudge an object which does not match its pointer"
+(
+  set -e
+
+  reponame="compress-mismatch"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "*.csv filter=lfs diff=lfs merge=lfs -text lfs-compress=1KB\n" > .gitattributes
+  for i in $(seq 1 500); do echo "$i,name,value"; done > big.csv
+  git add .gitattributes big.csv
+  git commit -m "add big.csv"
+
+  pointer="$(git cat-file -p :big.csv)"
+  echo "$pointer" | grep "^ext-0-compress "
+
+  # A pointer whose compress extension records other contents.
+  other="$(echo "$pointer" | sed -e "s/^ext-0-compress sha256:[0-9a-f]*/ext-0-compress sha256:$(calc_oid "other")/")"
+
+  set +e
+  echo "$other" | git lfs smudge big.csv > smudged.txt 2> smudge.log
+  res=$?
+  set -e
+  [ "$res" -eq 2 ]
+  grep "actual oid .* for extension 'compress' does not match expected" smudge.log
+  [ "$other" = "$(cat smudged.txt)" ]
+
+  # A pointer whose compress extension records another size.
+  other="$(echo "$pointer" | sed -e "s/^\(ext-0-compress sha256:[0-9a-f]*\) .*/\1 12345/")"
+
+  set +e
+  echo "$other" | git lfs smudge big.csv > smudged.txt 2> smudge.log
+  res=$?
+  set -e
+  [ "$res" -eq 2 ]
+  grep "actual size .* for extension 'compress' does not match expected 12345" smudge.log
+  [ "$other" = "$(cat smudged.txt)" ]
+)
+end_test
+
+begin_test "compress: clean reuses the staged pointer for unchanged contents"
+(
+  set -e
+
+  reponame="compress-staged"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "*.csv filter=lfs diff=lfs merge=lfs -text lfs-compress=1KB\n" > .gitattributes
+  for i in $(seq 1 500); do echo "$i,name,value"; done > big.csv
+  git add .gitattributes big.csv
+  git commit -m "add big.csv"
+
+  big_oid="$(calc_oid_file big.csv)"
+  big_size="$(wc -c < big.csv | tr -d ' ')"
+
+  # Stage a pointer to the same contents, compressed by another gzip, as
+  # another version of Git LFS might have.
+  gzip -9 -n -c big.csv > other.gz
+  other_oid="$(calc_oid_file other.gz)"
+  other_size="$(wc -c < other.gz | tr -d ' ')"
+  mkdir -p ".git/lfs/objects/${other_oid:0:2}/${other_oid:2:2}"
+  mv other.gz ".git/lfs/objects/${other_oid:0:2}/${other_oid:2:2}/$other_oid"
+
+  pointer="$(printf "version https://git-lfs.github.com/spec/v1\next-0-compress sha256:%s %s\noid sha256:%s\nsize %s\n" \
+    "$big_oid" "$big_size" "$other_oid" "$other_size")"
+  blob="$(echo "$pointer" | git hash-object -w --stdin)"
+  git update-index --cacheinfo "100644,$blob,big.csv"
+
+  git commit -m "recompress big.csv"
+
+  [ "$pointer" = "$(git lfs clean big.csv < big.csv)" ]
+  touch big.csv
+  [ -z "$(git status --porcelain -- big.csv)" ]
+
+  # Without the staged pointer's object, the file is compressed again.
+  rm ".git/lfs/objects/${other_oid:0:2}/${other_oid:2:2}/$other_oid"
+  git lfs clean big.csv < big.csv > cleaned.txt
+  grep "^ext-0-compress sha256:$big_oid $big_size$" cleaned.txt
+  grep "^oid sha256:$other_oid$" cleaned.txt && exit 1
+  true
+)
+end_test
@@ -182,3 +182,61 @@ begin_test "config: ignoring unsafe lfsconfig keys"
   grep "  core.askpass" status.log
 )
 end_test
+
+begin_test "config: lfs.tmpdir"
+(
+  set -e
+
+  reponame="config-tmpdir"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  tmpdir="$TRASHDIR/$reponame-tmp"
+  git config lfs.tmpdir "$tmpdir"
+  git lfs env | grep "TempDir=$tmpdir"
+
+  git lfs track "*.dat"
+  contents="tmpdir contents"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  assert_local_object "$oid" "${#contents}"
+  git push origin main
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+  git config lfs.tmpdir "$tmpdir"
+  git lfs pull
+  assert_local_object "$oid" "${#contents}"
+  [ "$contents" = "$(cat a.dat)" ]
+
+  # A relative path is relative to the Git directory.
+  git config lfs.tmpdir "lfs-tmp"
+  git lfs env | grep "TempDir=$(native_path "$(pwd)/.git/lfs-tmp")"
+  [ -d .git/lfs-tmp ]
+)
+end_test
+
+begin_test "config: unwritable lfs.tmpdir"
+(
+  set -e
+
+  reponame="config-tmpdir-unwritable"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  # A file where the directory should be can't be written to, even by root.
+  tmpdir="$TRASHDIR/$reponame-tmp"
+  touch "$tmpdir"
+  git config lfs.tmpdir "$tmpdir"
+
+  set +e
+  git lfs fetch 2>&1 | tee fetch.log
+  fetch_exit="${PIPESTATUS[0]}"
+  set -e
+  [ "$fetch_exit" -ne 0 ]
+  grep "temporary directory \"$tmpdir\" is not writable" fetch.log
+  grep "Set lfs.tmpdir to a writable directory" fetch.log
+)
+end_test
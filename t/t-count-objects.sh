@@ -0,0 +1,55 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "count-objects"
+(
+  set -e
+
+  reponame="count-objects"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs count-objects 2>&1 | tee count.log
+  [ "0 objects, 0 bytes" = "$(cat count.log)" ]
+
+  git lfs track "*.dat"
+  a_oid="$(calc_oid "aaa")"
+  b_oid="$(calc_oid "bbbbb")"
+  printf "aaa" > a.dat
+  printf "bbbbb" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add objects"
+
+  git lfs count-objects 2>&1 | tee count.log
+  [ "2 objects, 8 bytes" = "$(cat count.log)" ]
+
+  missing_oid="$(calc_oid "missing")"
+  mkdir -p .git/lfs/incomplete .git/lfs/tmp
+  printf "part" > ".git/lfs/incomplete/$missing_oid.part"
+  printf "tmp" > ".git/lfs/tmp/$missing_oid-1"
+
+  git lfs count-objects -v 2>&1 | tee count.log
+  grep "^2 objects, 8 bytes$" count.log
+  grep "^incomplete: 1 files, 4 bytes$" count.log
+  grep "^tmp: 1 files, 3 bytes$" count.log
+  grep "^objects/${a_oid:0:2}/${a_oid:2:2}: 1 objects, 3 bytes$" count.log
+  grep "^objects/${b_oid:0:2}/${b_oid:2:2}: 1 objects, 5 bytes$" count.log
+)
+end_test
+
+begin_test "count-objects: outside git repository"
+(
+  set +e
+  git lfs count-objects 2>&1 > count.log
+  res=$?
+
+  set -e
+  if [ "$res" = "0" ]; then
+    echo "Passes because $GIT_LFS_TEST_DIR is unset."
+    exit 0
+  fi
+  [ "$res" = "128" ]
+  grep "Not in a git repository" count.log
+)
+end_test
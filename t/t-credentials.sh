@@ -169,6 +169,107 @@ begin_test "credentials with useHttpPath, with correct password"
 )
 end_test
 
+begin_test "credentials approved with lfs.credentialexpiry"
+(
+  set -e
+
+  reponame="credential-expiry"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" "$reponame"
+
+  # Record each call made to the credential helpers, after the one which
+  # fills in the credentials.
+  git config --add credential.helper \
+    "!f() { echo \"\$1\" >> \"$TRASHDIR/$reponame-helper.log\"; cat > /dev/null; }; f"
+  git config lfs.credentialexpiry 60
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 1 B" push.log
+
+  echo "approvals:"
+  [ "1" -eq "$(grep -c "creds: git credential approve" push.log)" ]
+  [ "1" -eq "$(grep -c "^store$" "$TRASHDIR/$reponame-helper.log")" ]
+  [ "0" -eq "$(grep -c "^erase$" "$TRASHDIR/$reponame-helper.log")" ]
+
+  echo "expiry:"
+  expiry="$(grep "creds: caching credentials until" push.log | sed -e 's/.*until //')"
+  [ -n "$expiry" ]
+  now="$(date -u +%s)"
+  then="$(date -u -d "$expiry" +%s 2>/dev/null || echo "$now")"
+  [ "$then" -le "$((now + 60))" ]
+)
+end_test
+
+begin_test "credentials approved with lfs.credentialexpiry=0"
+(
+  set -e
+
+  reponame="credential-expiry-zero"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" "$reponame"
+  git config lfs.credentialexpiry 0
+
+  git lfs track "*.dat"
+  printf "b" > b.dat
+  git add .gitattributes b.dat
+  git commit -m "add b.dat"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 1 B" push.log
+
+  [ "1" -eq "$(grep -c "creds: git credential approve" push.log)" ]
+  [ "0" -eq "$(grep -c "creds: caching credentials until" push.log)" ]
+)
+end_test
+
+begin_test "credentials rejected on authentication failure"
+(
+  set -e
+
+  reponame="credential-expiry-reject"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" "$reponame"
+
+  # Use a credential helper which first returns credentials that the server
+  # considers expired, and then valid ones once those have been erased.
+  log="$TRASHDIR/$reponame-helper.log"
+  git config credential.helper ""
+  git config --add credential.helper "!f() {
+    echo \"\$1\" >> \"$log\"
+    cat > /dev/null
+    [ \"\$1\" = get ] || return 0
+    if grep -q '^erase\$' \"$log\"; then
+      printf 'username=user\\npassword=pass\\n'
+    else
+      printf 'username=expired\\npassword=pass\\n'
+    fi
+  }; f"
+
+  git lfs track "*.dat"
+  printf "c" > c.dat
+  git add .gitattributes c.dat
+  git commit -m "add c.dat"
+
+  GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 1 B" push.log
+
+  echo "rejections:"
+  [ "1" -eq "$(grep -c "creds: git credential reject" push.log)" ]
+  [ "1" -eq "$(grep -c "^erase$" "$log")" ]
+  echo "approvals:"
+  [ "1" -eq "$(grep -c "creds: git credential approve" push.log)" ]
+  [ "1" -eq "$(grep -c "^store$" "$log")" ]
+)
+end_test
+
 begin_test "git credential"
 (
   set -e
@@ -521,3 +622,29 @@ begin_test "credentials from remote.origin.url"
   git lfs fsck
 )
 end_test
+
+begin_test "credentials from lfs.credentialhelpers chain"
+(
+  set -e
+
+  reponame="credential-helpers-chain"
+  setup_remote_repo "$reponame"
+
+  clone_repo "$reponame" "$reponame"
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # Only the second helper in the chain has credentials; Git's own helpers
+  # have none.
+  git config credential.helper ""
+  git config lfs.credentialhelpers "!true lfstest"
+
+  GIT_TERMINAL_PROMPT=0 GIT_TRACE=1 git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 1 B" push.log
+  grep "creds: credential helper \"!true\" get" push.log
+  grep "creds: credential helper \"lfstest\" get" push.log
+  [ "0" -eq "$(grep -c "creds: git credential fill" push.log)" ]
+)
+end_test
@@ -105,3 +105,32 @@ begin_test "dedup dirty workdir"
   echo "$result" | grep 'Working tree is dirty. Please commit or reset your change.'
 )
 end_test
+
+begin_test "dedup disabled by lfs.dedup"
+(
+  set -e
+
+  reponame="dedup_disabled"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  echo "test data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  git config lfs.dedup false
+
+  git lfs dedup 2>&1 | tee dedup.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs dedup' to fail ..."
+    exit 1
+  fi
+  grep "De-duplication is disabled by lfs.dedup." dedup.log
+
+  # Checking out still works, copying rather than cloning the object.
+  rm a.dat
+  git lfs checkout a.dat
+  [ "test data" = "$(cat a.dat)" ]
+)
+end_test
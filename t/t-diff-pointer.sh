@@ -0,0 +1,68 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+write_pointer() {
+  local file="$1"
+  local contents="$2"
+
+  printf "version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n" \
+    "$(calc_oid "$contents")" "${#contents}" > "$file"
+}
+
+begin_test "diff-pointer"
+(
+  set -e
+
+  mkdir diff-pointer
+  cd diff-pointer
+
+  write_pointer before "small"
+  write_pointer after "much larger"
+
+  git lfs diff-pointer before after > diff.json
+  cat diff.json
+  expected="{\"oid_before\":\"$(calc_oid "small")\",\"oid_after\":\"$(calc_oid "much larger")\",\"size_before\":5,\"size_after\":11,\"size_delta\":6}"
+  [ "$expected" = "$(cat diff.json)" ]
+
+  git lfs diff-pointer after before > diff.json
+  grep "\"size_delta\":-6}" diff.json
+)
+end_test
+
+begin_test "diff-pointer: not a pointer"
+(
+  set -e
+
+  mkdir diff-pointer-invalid
+  cd diff-pointer-invalid
+
+  write_pointer before "small"
+  printf "large file contents" > after
+
+  set +e
+  git lfs diff-pointer before after > diff.json
+  res=$?
+  set -e
+
+  [ "$res" = "1" ]
+  [ "{\"error\":\"not a pointer\",\"file\":\"after\"}" = "$(cat diff.json)" ]
+
+  set +e
+  git lfs diff-pointer missing before > diff.json
+  res=$?
+  set -e
+
+  cat diff.json
+  [ "$res" = "1" ]
+  grep "\"error\":\".*no such file or directory\",\"file\":\"missing\"" diff.json
+
+  set +e
+  git lfs diff-pointer before > usage.log 2>&1
+  res=$?
+  set -e
+
+  [ "$res" = "2" ]
+  grep "Usage: git lfs diff-pointer" usage.log
+)
+end_test
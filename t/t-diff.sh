@@ -0,0 +1,150 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "diff: pointers"
+(
+  set -e
+
+  reponame="diff-pointers"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  printf "abd" > a.dat
+  git add a.dat
+  git commit -m "change a.dat"
+
+  old_oid="$(calc_oid "abc")"
+  new_oid="$(calc_oid "abd")"
+
+  git lfs diff HEAD^ HEAD 2>&1 | tee diff.log
+  grep "^diff --git a/a.dat b/a.dat$" diff.log
+  grep "^-oid sha256:$old_oid$" diff.log
+  grep "^+oid sha256:$new_oid$" diff.log
+
+  # Identical versions produce no output.
+  [ -z "$(git lfs diff HEAD HEAD)" ]
+)
+end_test
+
+begin_test "diff: --binary"
+(
+  set -e
+
+  reponame="diff-binary"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "0123456789abcdef0123456789abcdef" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  printf "0123456789abcdef0123456789abcdeF" > a.dat
+  git add a.dat
+  git commit -m "change a.dat"
+
+  git push origin main
+
+  cat > "$TRASHDIR/expected.log" <<-EOF
+diff --git a/a.dat b/a.dat
+--- a/a.dat
++++ b/a.dat
+@@ -1,2 +1,2 @@
+ 00000000: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef
+-00000010: 3031 3233 3435 3637 3839 6162 6364 6566  0123456789abcdef
++00000010: 3031 3233 3435 3637 3839 6162 6364 6546  0123456789abcdeF
+EOF
+
+  git lfs diff --binary HEAD^ HEAD > diff.log
+  diff -u "$TRASHDIR/expected.log" diff.log
+
+  # Objects missing locally are downloaded first.
+  rm -rf .git/lfs/objects
+  git lfs diff --binary HEAD^ HEAD -- a.dat > diff.log
+  diff -u "$TRASHDIR/expected.log" diff.log
+
+  # The working copy is compared with HEAD when no commit is given.
+  printf "0123456789abcdef" > a.dat
+  git lfs diff --binary --context=0 > diff.log
+  grep "^@@ -2 +1,0 @@$" diff.log
+  grep "^-00000010:" diff.log
+)
+end_test
+
+begin_test "diff: --binary skips large files"
+(
+  set -e
+
+  reponame="diff-binary-max-size"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  printf "abcdef" > a.dat
+  git add a.dat
+  git commit -m "change a.dat"
+
+  git -c lfs.binarydiffmaxsize=4B lfs diff --binary HEAD^ HEAD 2>&1 | tee diff.log
+  grep "Skipping a.dat: larger than lfs.binarydiffmaxsize (4 B)" diff.log
+  [ "0" -eq "$(grep -c "^[-+]0000" diff.log)" ]
+)
+end_test
+
+begin_test "diff: new files in the index"
+(
+  set -e
+
+  reponame="diff-new-files"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "abc" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  printf "def" > b.dat
+  git lfs diff 2>&1 | tee diff.log
+  [ "0" -eq "$(grep -c "b.dat" diff.log)" ]
+
+  git add b.dat
+  git lfs diff 2>&1 | tee diff.log
+  grep "^diff --git a/b.dat b/b.dat$" diff.log
+  grep "^--- /dev/null$" diff.log
+  grep "^+oid sha256:$(calc_oid "def")$" diff.log
+)
+end_test
+
+begin_test "diff: --binary with too many changes"
+(
+  set -e
+
+  reponame="diff-binary-too-many"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  base64 /dev/urandom | head -c 1048576 > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+
+  (printf "x" && cat a.dat) > b.dat
+  mv b.dat a.dat
+  git add a.dat
+  git commit -m "insert a byte"
+
+  git lfs diff --binary HEAD^ HEAD 2>&1 | tee diff.log
+  grep "^Binary files a/a.dat and b/a.dat differ$" diff.log
+  [ "0" -eq "$(grep -c "^[-+]0000" diff.log)" ]
+)
+end_test
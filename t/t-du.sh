@@ -0,0 +1,133 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "du"
+(
+  set -e
+
+  reponame="du"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat" "*.bin"
+  mkdir -p data/sub
+  printf "aaa" > a.dat
+  printf "bbbbb" > data/sub/b.bin
+  git add .gitattributes a.dat data
+  git commit -m "add objects"
+  git push origin main
+
+  printf "old" > old.dat
+  git add old.dat
+  git commit -m "add old.dat"
+  git push origin main
+  git rm old.dat
+  git commit -m "remove old.dat"
+  git push origin main
+
+  git config lfs.fetchrecentrefsdays 0
+  git config lfs.fetchrecentcommitsdays 0
+  git config lfs.pruneoffsetdays 0
+
+  git lfs du --bytes 2>&1 | tee du.log
+  grep "^objects: *11 bytes in 3 file(s)$" du.log
+  grep "^checkout: *8 bytes in 2 file(s)$" du.log
+  grep "^recent: *0 bytes in 0 file(s)$" du.log
+  grep "^prunable: *3 bytes in 1 file(s)$" du.log
+
+  git lfs du 2>&1 | tee du.log
+  grep "^objects: *11 B in 3 file(s)$" du.log
+
+  git lfs du --bytes --by-extension --by-dir 1 2>&1 | tee du.log
+  grep "^\.bin: *5 bytes in 1 file(s)$" du.log
+  grep "^\.dat: *3 bytes in 1 file(s)$" du.log
+  grep "^data: *5 bytes in 1 file(s)$" du.log
+  grep "^\.: *3 bytes in 1 file(s)$" du.log
+
+  git lfs du --json --by-dir 2 > du.json
+  grep '{"name":"prunable","count":1,"size":3}' du.json
+  grep '"by_dir":\[{"name":"data/sub","count":1,"size":5},{"name":".","count":1,"size":3}\]' du.json
+  [ "0" -eq "$(grep -c by_extension du.json)" ]
+)
+end_test
+
+begin_test "du: recent and unpushed objects"
+(
+  set -e
+
+  reponame="du-recent"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "old" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  printf "new" > a.dat
+  git add a.dat
+  git commit -m "change a.dat"
+
+  git config lfs.fetchrecentrefsdays 0
+  git config lfs.fetchrecentcommitsdays 0
+  git config lfs.pruneoffsetdays 0
+
+  # Nothing has been pushed, so the old version is kept.
+  git lfs du --bytes 2>&1 | tee du.log
+  grep "^recent: *3 bytes in 1 file(s)$" du.log
+  grep "^prunable: *0 bytes in 0 file(s)$" du.log
+
+  git push origin main
+  git lfs du --bytes 2>&1 | tee du.log
+  grep "^recent: *0 bytes in 0 file(s)$" du.log
+  grep "^prunable: *3 bytes in 1 file(s)$" du.log
+
+  git config lfs.fetchrecentcommitsdays 1
+  git lfs du --bytes 2>&1 | tee du.log
+  grep "^recent: *3 bytes in 1 file(s)$" du.log
+)
+end_test
+
+begin_test "du: outside git repository"
+(
+  set +e
+  git lfs du 2>&1 > du.log
+  res=$?
+
+  set -e
+  if [ "$res" = "0" ]; then
+    echo "Passes because $GIT_LFS_TEST_DIR is unset."
+    exit 0
+  fi
+  [ "$res" = "128" ]
+  grep "Not in a git repository" du.log
+)
+end_test
+
+begin_test "du --by-access-age"
+(
+  set -e
+
+  reponame="du-access-age"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "aaa" > a.dat
+  printf "bbbbb" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add objects"
+
+  find .git/lfs/objects -type f -exec touch -t 200001010000 {} +
+  git cat-file -p HEAD:a.dat | git lfs smudge a.dat > /dev/null
+
+  git lfs du --bytes --by-access-age 2>&1 | tee du.log
+  grep "By last access:" du.log
+  grep "< 1 day: *3 bytes in 1 file(s)" du.log
+  grep "< 1 week: *0 bytes in 0 file(s)" du.log
+  grep ">= 1 year: *5 bytes in 1 file(s)" du.log
+
+  git lfs du --json --by-access-age | tee du.json
+  grep '"by_access_age":\[{"name":"< 1 day","count":1,"size":3}' du.json
+)
+end_test
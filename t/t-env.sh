@@ -2,9 +2,13 @@
 
 . "$(dirname "$0")/testlib.sh"
 
-envInitConfig='git config filter.lfs.process = "git-lfs filter-process"
-git config filter.lfs.smudge = "git-lfs smudge -- %f"
-git config filter.lfs.clean = "git-lfs clean -- %f"'
+# Git 2.26.0 and later can report the scope the filter settings come from.
+envInitScope=" (global)"
+compare_version "$(git version | cut -d" " -f3)" "2.26.0" ||
+  [ $? -ne $VERSION_LOWER ] || envInitScope=""
+envInitConfig="git config filter.lfs.process = \"git-lfs filter-process\"$envInitScope
+git config filter.lfs.smudge = \"git-lfs smudge -- %f\"$envInitScope
+git config filter.lfs.clean = \"git-lfs clean -- %f\"$envInitScope"
 
 unset_vars() {
     # If set, these will cause the test to fail.
@@ -975,3 +979,53 @@ UploadTransfers=basic,lfs-standalone-file
   contains_same_elements "$expected" "$actual"
 )
 end_test
+
+begin_test "env --metrics: reports metrics recorded by earlier commands"
+(
+  set -e
+  reponame="env-metrics"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents="metrics"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  git lfs env --metrics | tee metrics.log
+  grep "ObjectCount=1" metrics.log
+  grep "TotalCachedBytes=${#contents}" metrics.log
+  grep "CacheHits=0" metrics.log
+
+  # Fetching an object which is already present is a cache hit, which is
+  # kept for later commands.
+  git lfs fetch
+  git lfs env --metrics | tee metrics.log
+  grep "CacheHits=1" metrics.log
+  grep "BandwidthSavedBytes=${#contents}" metrics.log
+
+  # Objects added and removed update the totals without any other walk of
+  # the object store.
+  git config lfs.metricsfile "$TRASHDIR/metrics.json"
+  printf "more" > b.dat
+  git add b.dat
+  grep '"object_count":2' "$TRASHDIR/metrics.json"
+  grep "\"total_cached_bytes\":$((${#contents} + 4))" "$TRASHDIR/metrics.json"
+
+  # Objects removed without being recorded are found when the objects are
+  # counted again, as env --metrics does.
+  oid="$(calc_oid "more")"
+  rm ".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid"
+  git lfs env --metrics | tee metrics.log
+  grep "ObjectCount=1" metrics.log
+  grep "TotalCachedBytes=${#contents}" metrics.log
+
+  git commit -m "add b.dat"
+  git lfs reset --all
+  git lfs env --metrics | tee metrics.log
+  grep "ObjectCount=0" metrics.log
+  grep "TotalCachedBytes=0" metrics.log
+)
+end_test
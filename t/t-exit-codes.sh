@@ -0,0 +1,126 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "exit codes: help"
+(
+  set -e
+
+  git lfs help exit-codes > help.log
+  grep "^  3     The Git LFS server rejected the credentials, or needed some.$" help.log
+  grep "^  128   The command must be run in a Git repository, or a work tree.$" help.log
+)
+end_test
+
+begin_test "exit codes: usage"
+(
+  set -e
+
+  reponame="exit-codes-usage"
+  git init "$reponame"
+  cd "$reponame"
+
+  set +e
+  git lfs fetch --no-such-flag > fetch.log 2>&1
+  res=$?
+  set -e
+
+  [ "$res" = "2" ]
+)
+end_test
+
+begin_test "exit codes: object not found"
+(
+  set -e
+
+  reponame="exit-codes-not-found"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "missing" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  delete_server_object "$reponame" "$(calc_oid "missing")"
+  rm -rf .git/lfs/objects
+
+  set +e
+  git lfs fetch > fetch.log 2>&1
+  res=$?
+  set -e
+
+  cat fetch.log
+  grep "failed to fetch some objects" fetch.log
+  [ "$res" = "5" ]
+)
+end_test
+
+begin_test "exit codes: auth"
+(
+  set -e
+
+  reponame="requirecreds-exit-codes"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "auth" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  gitserverhost=$(echo "$GITSERVER" | cut -d'/' -f3)
+  git config lfs.url "http://requirecreds:wrong@$gitserverhost/$reponame.git/info/lfs"
+
+  set +e
+  git lfs push origin main > push.log 2>&1
+  res=$?
+  set -e
+
+  cat push.log
+  [ "$res" = "3" ]
+)
+end_test
+
+begin_test "exit codes: network"
+(
+  set -e
+
+  reponame="exit-codes-network"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "network" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # Nothing listens on port 1.
+  git config lfs.url "http://127.0.0.1:1/$reponame.git/info/lfs"
+
+  set +e
+  git lfs push origin main > push.log 2>&1
+  res=$?
+  set -e
+
+  cat push.log
+  [ "$res" = "4" ]
+)
+end_test
+
+begin_test "exit codes: not a repository"
+(
+  set -e
+
+  mkdir exit-codes-no-repo
+  cd exit-codes-no-repo
+
+  set +e
+  git lfs fetch > fetch.log 2>&1
+  res=$?
+  set -e
+
+  [ "$res" = "128" ]
+)
+end_test
@@ -0,0 +1,80 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "expire-locks (--dry-run)"
+(
+  set -e
+
+  reponame="expire-locks-dry-run"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs lock --json "a.dat" | tee lock.log
+  id=$(assert_lock lock.log a.dat)
+
+  git lfs expire-locks --older-than=0s --dry-run 2>&1 | tee expire.log
+  grep "Would expire lock $id on a.dat (owner: Git LFS Tests" expire.log
+  grep "Would expire 1 of 1 lock(s) older than 0s" expire.log
+
+  assert_server_lock "$reponame" "$id"
+)
+end_test
+
+begin_test "expire-locks (--older-than)"
+(
+  set -e
+
+  reponame="expire-locks-older-than"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs lock --json "a.dat" | tee lock.log
+  id=$(assert_lock lock.log a.dat)
+
+  # The lock was just taken, so it is not yet 30 days old.
+  git lfs expire-locks 2>&1 | tee expire.log
+  grep "Expired 0 of 1 lock(s) older than 30d" expire.log
+  assert_server_lock "$reponame" "$id"
+
+  git lfs expire-locks --older-than=0s 2>&1 | tee expire.log
+  grep "Expired lock $id on a.dat" expire.log
+  grep "Expired 1 of 1 lock(s) older than 0s" expire.log
+  refute_server_lock "$reponame" "$id"
+)
+end_test
+
+begin_test "expire-locks (--owner)"
+(
+  set -e
+
+  reponame="expire-locks-owner"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs lock --json "a.dat" | tee lock.log
+  id=$(assert_lock lock.log a.dat)
+
+  git lfs expire-locks --older-than=0s --owner="someone else" 2>&1 | tee expire.log
+  grep "Expired 0 of 1 lock(s) older than 0s" expire.log
+  assert_server_lock "$reponame" "$id"
+
+  git lfs expire-locks --older-than=0s --owner="Git LFS Tests" 2>&1 | tee expire.log
+  grep "Expired 1 of 1 lock(s) older than 0s" expire.log
+  refute_server_lock "$reponame" "$id"
+)
+end_test
+
+begin_test "expire-locks (invalid --older-than)"
+(
+  set -e
+
+  reponame="expire-locks-invalid"
+  setup_remote_repo_with_file "$reponame" "a.dat"
+
+  git lfs expire-locks --older-than=soon 2>&1 | tee expire.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected expire-locks to fail, didn't"
+    exit 1
+  fi
+
+  grep "Invalid --older-than" expire.log
+)
+end_test
@@ -0,0 +1,258 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+setup_export_repo() {
+  reponame="$1"
+
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  printf "bb" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+
+  git checkout -b other
+  printf "ccc" > c.dat
+  git add c.dat
+  git commit -m "add c.dat"
+  git checkout main
+}
+
+begin_test "export-objects and import-objects round trip"
+(
+  set -e
+
+  setup_export_repo "export-import-round-trip"
+
+  git lfs export-objects ../objects.tar 2>&1 | tee export.log
+  grep "Exported 2 object(s), 3 B" export.log
+
+  tar -tf ../objects.tar > entries.log
+  [ "manifest" = "$(head -n 1 entries.log)" ]
+  grep "objects/ca/97/$(calc_oid "a")" entries.log
+  tar -xOf ../objects.tar manifest > manifest.log
+  grep "^$(calc_oid "bb") 2$" manifest.log
+  [ "2" -eq "$(wc -l < manifest.log)" ]
+
+  cd ..
+  git init "import-round-trip"
+  cd "import-round-trip"
+
+  git lfs import-objects ../objects.tar 2>&1 | tee import.log
+  grep "Imported 2 object(s), 3 B; skipped 0 already present" import.log
+
+  assert_local_object "$(calc_oid "a")" 1
+  assert_local_object "$(calc_oid "bb")" 2
+  refute_local_object "$(calc_oid "ccc")"
+
+  git lfs import-objects ../objects.tar 2>&1 | tee import.log
+  grep "Imported 0 object(s), 0 B; skipped 2 already present" import.log
+)
+end_test
+
+begin_test "export-objects --all and refs"
+(
+  set -e
+
+  setup_export_repo "export-import-all"
+
+  git lfs export-objects --all ../all.tar 2>&1 | tee export.log
+  grep "Exported 3 object(s), 6 B" export.log
+
+  git lfs export-objects ../other.tar other 2>&1 | tee export.log
+  grep "Exported 3 object(s), 6 B" export.log
+
+  git lfs export-objects --all ../both.tar main 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Cannot combine --all with refs" export.log
+)
+end_test
+
+begin_test "export-objects and import-objects through a pipe"
+(
+  set -e
+
+  setup_export_repo "export-import-pipe"
+
+  cd ..
+  git init "import-pipe"
+
+  (cd "export-import-pipe" && git lfs export-objects --all - 2>../export.log) |
+    (cd "import-pipe" && git lfs import-objects -) 2>&1 | tee import.log
+  grep "Exported 3 object(s), 6 B" export.log
+  grep "Imported 3 object(s), 6 B; skipped 0 already present" import.log
+
+  cd "import-pipe"
+  assert_local_object "$(calc_oid "ccc")" 3
+)
+end_test
+
+begin_test "export-objects reports missing objects"
+(
+  set -e
+
+  setup_export_repo "export-missing"
+
+  rm -rf .git/lfs/objects
+  git lfs export-objects ../missing.tar 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Missing object $(calc_oid "a") (a.dat), not exported" export.log
+  grep "Exported 0 object(s), 0 B" export.log
+)
+end_test
+
+begin_test "import-objects rejects corrupt objects"
+(
+  set -e
+
+  reponame="import-corrupt"
+  git init "$reponame"
+  cd "$reponame"
+
+  good="$(calc_oid "good")"
+  bad="$(calc_oid "bad")"
+  missing="$(calc_oid "missing")"
+
+  mkdir -p "archive/objects/${good:0:2}/${good:2:2}" "archive/objects/${bad:0:2}/${bad:2:2}"
+  printf "good" > "archive/objects/${good:0:2}/${good:2:2}/$good"
+  printf "evil" > "archive/objects/${bad:0:2}/${bad:2:2}/$bad"
+  printf "%s 4\n%s 3\n%s 7\n" "$good" "$bad" "$missing" > archive/manifest
+  tar -C archive -cf ../corrupt.tar manifest objects
+
+  git lfs import-objects ../corrupt.tar 2>&1 | tee import.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Object $bad has size 4, expected 3 from the manifest; not imported" import.log
+  grep "Object $missing is listed in the manifest but missing from the archive" import.log
+  grep "Imported 1 object(s), 4 B; skipped 0 already present" import.log
+
+  assert_local_object "$good" 4
+  refute_local_object "$bad"
+
+  # Sizes which agree with the manifest must still hash to the OID.
+  printf "%s 4\n" "$bad" > archive/manifest
+  tar -C archive -cf ../corrupt.tar manifest objects
+
+  git lfs import-objects ../corrupt.tar 2>&1 | tee import.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Could not import $bad: contents hash to $(calc_oid "evil")" import.log
+  refute_local_object "$bad"
+  [ -z "$(find .git/lfs/tmp -type f 2>/dev/null)" ]
+)
+end_test
+
+begin_test "import-objects from a directory"
+(
+  set -e
+
+  reponame="import-directory"
+  git init "$reponame"
+  cd "$reponame"
+
+  a="$(calc_oid "a")"
+  bb="$(calc_oid "bb")"
+  present="$(calc_oid "present")"
+  wrong="$(calc_oid "wrong")"
+
+  mkdir -p ../raw/nested
+  printf "a" > "../raw/$a"
+  printf "bb" > "../raw/nested/$bb"
+  printf "present" > "../raw/$present"
+  printf "not wrong" > "../raw/$wrong"
+  printf "notes" > ../raw/README
+
+  printf "present" > present.dat
+  git lfs clean < present.dat > /dev/null
+  assert_local_object "$present" 7
+
+  git lfs import-objects --dry-run ../raw 2>&1 | tee import.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Would import 2 object(s), 3 B; skipped 1 already present; 2 invalid" import.log
+  refute_local_object "$a"
+
+  git lfs import-objects ../raw 2>&1 | tee import.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Invalid object ../raw/README: not a file named by its OID" import.log
+  grep "Invalid object ../raw/$wrong: contents hash to $(calc_oid "not wrong")" import.log
+  grep "Imported 2 object(s), 3 B; skipped 1 already present; 2 invalid" import.log
+
+  assert_local_object "$a" 1
+  assert_local_object "$bb" 2
+  refute_local_object "$wrong"
+  [ -f "../raw/$a" ]
+
+  rm "../raw/$wrong" ../raw/README
+  git lfs import-objects ../raw 2>&1 | tee import.log
+  grep "Imported 0 object(s), 0 B; skipped 3 already present; 0 invalid" import.log
+)
+end_test
+
+begin_test "export-objects --output to a flat directory"
+(
+  set -e
+
+  setup_export_repo "export-flat"
+
+  a="$(calc_oid "a")"
+  bb="$(calc_oid "bb")"
+  ccc="$(calc_oid "ccc")"
+
+  git lfs export-objects --output=../flat 2>&1 | tee export.log
+  grep "Exported 3 object(s), 6 B to ../flat" export.log
+  [ "a" = "$(cat "../flat/$a")" ]
+  [ "ccc" = "$(cat "../flat/$ccc")" ]
+  [ 4 -eq "$(ls ../flat | wc -l)" ]
+  grep "\"oid\": \"$bb\"" ../flat/manifest.json
+  grep "\"size\": 2" ../flat/manifest.json
+  grep "\"name\": \"$bb\"" ../flat/manifest.json
+
+  git lfs export-objects --output=../gz --compress 2>&1 | tee export.log
+  grep "Exported 3 object(s), 6 B to ../gz" export.log
+  [ "bb" = "$(gzip -dc "../gz/$bb.gz")" ]
+  grep "\"compression\": \"gzip\"" ../gz/manifest.json
+  grep "\"name\": \"$bb.gz\"" ../gz/manifest.json
+
+  git lfs export-objects --output=../linked --link 2>&1 | tee export.log
+  [ -L "../linked/$a" ]
+  [ "a" = "$(cat "../linked/$a")" ]
+
+  # Only objects cached after --since are exported.
+  touch -d "2000-01-01" .git/lfs/objects/*/*/*
+  printf "dddd" > d.dat
+  git add d.dat
+  git commit -m "add d.dat"
+
+  git lfs export-objects --output=../recent --since=2010-01-01 2>&1 | tee export.log
+  grep "Exported 1 object(s), 4 B to ../recent" export.log
+  [ -f "../recent/$(calc_oid "dddd")" ]
+  [ ! -e "../recent/$a" ]
+
+  git lfs export-objects --output=../recent --since=1d 2>&1 | tee export.log
+  grep "Exported 1 object(s), 4 B to ../recent" export.log
+
+  git lfs export-objects --output=../bad --since=yesterday-ish 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Invalid --since" export.log
+
+  git lfs export-objects --output=../bad --compress=xz 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Unsupported --compress format \"xz\"" export.log
+
+  git lfs export-objects --output=../bad --compress --link 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Cannot combine --link with --compress" export.log
+
+  git lfs export-objects ../objects.tar --link 2>&1 | tee export.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep -- "--compress, --since and --link require --output" export.log
+
+  # Each form of directory can be imported again.
+  for dir in flat gz linked; do
+    git init "../import-$dir"
+    (cd "../import-$dir" && git lfs import-objects "../$dir") 2>&1 | tee import.log
+    grep "Imported 3 object(s), 6 B; skipped 0 already present; 0 invalid" import.log
+  done
+)
+end_test
@@ -60,3 +60,103 @@ begin_test "ext"
   [ "$actual" = "$expected" ]
 )
 end_test
+
+begin_test "ext: clean and smudge through a chain of extensions"
+(
+  set -e
+
+  reponame="ext-chain"
+  git init "$reponame"
+  cd "$reponame"
+
+  # Both commands are their own inverse.
+  git config lfs.extension.rot13.clean "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.smudge "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.priority 0
+  git config lfs.extension.rev.clean "rev"
+  git config lfs.extension.rev.smudge "rev"
+  git config lfs.extension.rev.priority 1
+
+  git lfs track "*.dat"
+  # rev(1) works on lines, so the contents end with a newline.
+  contents_oid="$(calc_oid "hello world\n")"
+  rot13_oid="$(calc_oid "uryyb jbeyq\n")"
+  stored_oid="$(calc_oid "qyebj byyru\n")"
+  printf "hello world\n" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  pointer="$(git cat-file -p :a.dat)"
+  echo "$pointer" | grep "^ext-0-rot13 sha256:$contents_oid$"
+  echo "$pointer" | grep "^ext-1-rev sha256:$rot13_oid$"
+  echo "$pointer" | grep "^oid sha256:$stored_oid$"
+  assert_local_object "$stored_oid" 12
+  [ "qyebj byyru" = "$(cat ".git/lfs/objects/${stored_oid:0:2}/${stored_oid:2:2}/$stored_oid")" ]
+
+  rm a.dat
+  git checkout -- a.dat
+  [ "hello world" = "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "ext: smudge with an extension which is not configured"
+(
+  set -e
+
+  reponame="ext-unconfigured"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.extension.rot13.clean "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.smudge "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.priority 0
+
+  git lfs track "*.dat"
+  printf "secret" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git config --unset lfs.extension.rot13.smudge
+
+  set +e
+  git cat-file -p :a.dat | git lfs smudge a.dat > smudged.txt 2> smudge.log
+  res=$?
+  set -e
+  [ "$res" -eq 2 ]
+  grep "extension 'rot13', which is not configured" smudge.log
+  grep "lfs.extension.rot13.smudge" smudge.log
+  [ "$(git cat-file -p :a.dat)" = "$(cat smudged.txt)" ]
+
+  git config --unset-all lfs.extension.rot13.clean
+  git config --unset-all lfs.extension.rot13.priority
+
+  rm a.dat
+  set +e
+  git checkout -- a.dat 2> checkout.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "extension 'rot13', which is not configured" checkout.log
+  [ ! -e a.dat ] || [ "frperg" != "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "ext: clean with an extension without a clean command"
+(
+  set -e
+
+  reponame="ext-no-clean"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.extension.rot13.smudge "tr a-zA-Z n-za-mN-ZA-M"
+  git config lfs.extension.rot13.priority 0
+
+  set +e
+  printf "secret" | git lfs clean a.dat > clean.txt 2> clean.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "extension 'rot13' has no clean command" clean.log
+)
+end_test
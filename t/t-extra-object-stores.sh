@@ -0,0 +1,107 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# store_object copies the local object with the given OID into the object
+# store "$1", laid out like .git/lfs/objects.
+store_object () {
+  local store="$1"
+  local oid="$2"
+  mkdir -p "$store/${oid:0:2}/${oid:2:2}"
+  cp ".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid" "$store/${oid:0:2}/${oid:2:2}/$oid"
+}
+
+begin_test "extra object stores: fetch copies objects instead of downloading"
+(
+  set -e
+
+  reponame="extra-object-stores-fetch"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="object a"
+  contents_b="object b"
+  contents_c="object c"
+  oid_a="$(calc_oid "$contents_a")"
+  oid_b="$(calc_oid "$contents_b")"
+  oid_c="$(calc_oid "$contents_c")"
+  printf "%s" "$contents_a" > a.dat
+  printf "%s" "$contents_b" > b.dat
+  printf "%s" "$contents_c" > c.dat
+  git add .gitattributes a.dat b.dat c.dat
+  git commit -m "add objects"
+  git push origin main
+
+  first="$TRASHDIR/$reponame-first"
+  second="$TRASHDIR/$reponame-second"
+  store_object "$first" "$oid_a"
+  store_object "$second" "$oid_a"
+  store_object "$second" "$oid_b"
+
+  # A corrupt copy of the same size falls through to a download.
+  store_object "$first" "$oid_c"
+  printf "%s" "object x" > "$first/${oid_c:0:2}/${oid_c:2:2}/$oid_c"
+
+  chmod -R a-w "$first" "$second"
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+  git config --add lfs.extraobjectstores "$first"
+  git config --add lfs.extraobjectstores "$second"
+  git config lfs.metricsfile "$TRASHDIR/$reponame-metrics.json"
+
+  GIT_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "extra object store $first: copied $oid_a" fetch.log
+  grep "extra object store $second: copied $oid_b" fetch.log
+  grep "extra object store $first: skipping $oid_c" fetch.log
+  grep "copied 1 object(s) from extra object store $first" fetch.log
+  grep "copied 1 object(s) from extra object store $second" fetch.log
+  grep "Downloading LFS objects: 100% (1/1)" fetch.log
+
+  grep "\"extra_object_store_hits\":{\"$first\":1,\"$second\":1}" "$TRASHDIR/$reponame-metrics.json"
+
+  assert_local_object "$oid_a" "${#contents_a}"
+  assert_local_object "$oid_b" "${#contents_b}"
+  assert_local_object "$oid_c" "${#contents_c}"
+  git lfs fsck
+
+  chmod -R u+w "$first" "$second"
+)
+end_test
+
+begin_test "extra object stores: checkout without a reachable server"
+(
+  set -e
+
+  reponame="extra-object-stores-checkout"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents="checked out from the extra store"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  store="$TRASHDIR/$reponame-store"
+  store_object "$store" "$oid"
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-clone"
+  git config lfs.url "http://127.0.0.1:1/unreachable"
+  git config lfs.extraobjectstores "$store"
+
+  rm a.dat
+  git checkout -- a.dat
+  [ "$contents" = "$(cat a.dat)" ]
+  assert_local_object "$oid" "${#contents}"
+
+  rm -rf .git/lfs/objects
+  git lfs pull
+  [ "$contents" = "$(cat a.dat)" ]
+  assert_local_object "$oid" "${#contents}"
+)
+end_test
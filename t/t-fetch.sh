@@ -71,6 +71,58 @@ begin_test "fetch"
 )
 end_test
 
+begin_test "fetch --verify"
+(
+  set -e
+  cd clone
+  rm -rf .git/lfs/objects
+
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch --verify 2>&1 | tee fetch.log
+  grep "xfer: verifying $contents_oid" fetch.log
+  assert_local_object "$contents_oid" 1
+
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 GIT_TRANSFER_TRACE=1 git lfs fetch 2>&1 | tee fetch.log
+  grep "xfer: verifying" fetch.log && exit 1
+  assert_local_object "$contents_oid" 1
+)
+end_test
+
+begin_test "fetch with lfs.minfreespace"
+(
+  set -e
+  cd clone
+  rm -rf .git/lfs/objects
+
+  git config lfs.minfreespace 1000PB
+  git lfs fetch 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected fetch to fail without enough free space"
+    exit 1
+  fi
+  grep "Not enough free space" fetch.log
+  grep "run again with --force" fetch.log
+  refute_local_object "$contents_oid"
+
+  git lfs fetch --force 2>&1 | tee fetch.log
+  assert_local_object "$contents_oid" 1
+
+  rm -rf .git/lfs/objects
+  git config lfs.minfreespace 0
+  git lfs fetch 2>&1 | tee fetch.log
+  assert_local_object "$contents_oid" 1
+
+  rm -rf .git/lfs/objects
+  git config lfs.minfreespace nonsense
+  git lfs fetch 2>&1 | tee fetch.log
+  grep 'Invalid value for lfs.minfreespace: "nonsense"' fetch.log
+
+  git config --unset lfs.minfreespace
+  git lfs fetch
+  assert_local_object "$contents_oid" 1
+)
+end_test
+
 begin_test "fetch (shared repository)"
 (
   set -e
@@ -588,6 +640,35 @@ begin_test "fetch --prune"
 )
 end_test
 
+begin_test "fetch --prune (shared storage)"
+(
+  set -e
+
+  reponame="fetch_prune_shared_storage"
+  setup_remote_repo "remote_$reponame"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+  storage="$TRASHDIR/$reponame-storage"
+  git config lfs.storage "$storage"
+
+  git lfs track "*.dat"
+  content="shared content"
+  oid=$(calc_oid "$content")
+  printf "%s" "$content" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  # The objects are fetched, and the fetch succeeds, but nothing is pruned.
+  rm -rf "$storage"
+  git lfs fetch --prune 2>&1 | tee fetch.log
+  [ "0" -eq "${PIPESTATUS[0]}" ]
+  grep "not pruning $storage, since it may be shared with other repositories" fetch.log
+  grep "prune:" fetch.log && exit 1
+  [ -f "$storage/objects/${oid:0:2}/${oid:2:2}/$oid" ]
+)
+end_test
+
 begin_test "fetch raw remote url"
 (
   set -e
@@ -639,3 +720,177 @@ begin_test "fetch fails when LFS directory has wrong permissions"
   grep "error trying to create local storage directory" fetch.log
 )
 end_test
+
+begin_test "fetch --no-smudge warms the cache for checkout"
+(
+  set -e
+
+  reponame="fetch-no-smudge"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+  git push origin main
+
+  git checkout -b newbranch
+  printf "%s" "$b" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  git push origin newbranch
+
+  cd ..
+  clone_repo "$reponame" "$reponame-clone"
+  refute_local_object "$b_oid"
+
+  git lfs fetch --no-smudge origin origin/newbranch 2>&1 | tee ../fetch.log
+  assert_local_object "$b_oid" 1
+
+  # The working tree is left alone.
+  [ ! -e b.dat ]
+  [ -z "$(git status --porcelain --untracked-files=no)" ]
+
+  # Checking out the branch uses the cached object, without the server.
+  git config lfs.url "http://127.0.0.1:1/unreachable"
+  git checkout newbranch 2>&1 | tee ../checkout.log
+  [ "0" -eq "$(grep -c "Downloading" ../checkout.log)" ]
+  [ "$b" = "$(cat b.dat)" ]
+)
+end_test
+
+begin_test "fetch --limit-bytes"
+(
+  set -e
+
+  reponame="fetch-limit-bytes"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "1" > a.dat
+  printf "22" > b.dat
+  printf "333" > c.dat
+  printf "4444" > d.dat
+  git add .gitattributes *.dat
+  git commit -m "add objects"
+  git push origin main
+
+  cd "$TRASHDIR"
+  GIT_LFS_SKIP_SMUDGE=1 git clone "$GITSERVER/$reponame" "$reponame-limited"
+  cd "$reponame-limited"
+
+  # The smallest objects are fetched first, up to exactly the limit.
+  git lfs fetch --limit-bytes=6 2>&1 | tee fetch.log
+  grep "Fetched 3 of 4 objects (6 B/10 B). Limit reached. Run 'git lfs fetch' again to continue." fetch.log
+  assert_local_object "$(calc_oid "1")" 1
+  assert_local_object "$(calc_oid "22")" 2
+  assert_local_object "$(calc_oid "333")" 3
+  refute_local_object "$(calc_oid "4444")"
+
+  # Objects already fetched don't count towards the limit.
+  git lfs fetch --limit-bytes=3 2>&1 | tee fetch-again.log
+  grep "Fetched 0 of 1 objects (0 B/4 B). Limit reached." fetch-again.log
+  refute_local_object "$(calc_oid "4444")"
+
+  git lfs fetch --limit-bytes=4 2>&1 | tee fetch-rest.log
+  grep "Limit reached" fetch-rest.log && exit 1
+  assert_local_object "$(calc_oid "4444")" 4
+
+  git lfs fetch --limit-bytes=lots >fetch-invalid.log 2>&1 && exit 1
+  cat fetch-invalid.log
+  grep "Invalid --limit-bytes \"lots\"" fetch-invalid.log
+)
+end_test
+
+begin_test "fetch --checkpoint"
+(
+  set -e
+
+  reponame="fetch-checkpoint"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  printf "b" > b.dat
+  git add .gitattributes *.dat
+  git commit -m "add objects"
+  git push origin main
+
+  oida="$(calc_oid "a")"
+  oidb="$(calc_oid "b")"
+
+  # Interrupt the fetch part of the way through, by making one object fail.
+  delete_server_object "$reponame" "$oidb"
+
+  cd "$TRASHDIR"
+  GIT_LFS_SKIP_SMUDGE=1 git clone "$GITSERVER/$reponame" "$reponame-resume"
+  cd "$reponame-resume"
+
+  git lfs fetch --checkpoint=checkpoint.jsonl 2>&1 | tee fetch.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected fetch to fail"
+    exit 1
+  fi
+  grep "\"oid\":\"$oida\"" checkpoint.jsonl
+  grep "$oidb" checkpoint.jsonl && exit 1
+
+  # A line cut short by a crash is ignored.
+  printf '{"oid":"%s","si' "$oidb" >> checkpoint.jsonl
+
+  # Objects recorded in the checkpoint are skipped, even if they are no longer
+  # in the local cache.
+  rm -rf .git/lfs/objects
+  GIT_TRACE=1 git lfs fetch --checkpoint=checkpoint.jsonl > fetch-again.log 2>&1 && exit 1
+  grep "fetch: Skipped 1 object(s) already fetched according to checkpoint.jsonl" fetch-again.log
+  grep "fetch a.dat" fetch-again.log && exit 1
+  grep "fetch b.dat" fetch-again.log
+  refute_local_object "$oida"
+  [ "1" -eq "$(grep -c "$oida" checkpoint.jsonl)" ]
+
+  # Without a checkpoint, everything missing is fetched again.
+  git lfs fetch > fetch-all.log 2>&1 && exit 1
+  assert_local_object "$oida" 1
+)
+end_test
+
+begin_test "fetch --checkpoint=auto"
+(
+  set -e
+
+  reponame="fetch-checkpoint-auto"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "auto a" > a.dat
+  printf "auto b" > b.dat
+  git add .gitattributes *.dat
+  git commit -m "add objects"
+  git push origin main
+
+  oida="$(calc_oid "auto a")"
+  oidb="$(calc_oid "auto b")"
+  delete_server_object "$reponame" "$oidb"
+
+  cd "$TRASHDIR"
+  GIT_LFS_SKIP_SMUDGE=1 git clone "$GITSERVER/$reponame" "$reponame-resume"
+  cd "$reponame-resume"
+
+  git lfs fetch --checkpoint=auto > fetch.log 2>&1 && exit 1
+  [ "1" -eq "$(ls .git/lfs/checkpoints | wc -l)" ]
+  checkpoint=".git/lfs/checkpoints/$(ls .git/lfs/checkpoints)"
+  grep "$oida" "$checkpoint"
+
+  # The same remote and ref use the same checkpoint.
+  rm -rf .git/lfs/objects
+  git lfs fetch --checkpoint=auto > fetch-again.log 2>&1 && exit 1
+  grep "fetch: Skipped 1 object(s) already fetched according to .*/$(basename "$checkpoint")" fetch-again.log
+  [ "1" -eq "$(ls .git/lfs/checkpoints | wc -l)" ]
+
+  # Once the fetch succeeds, the checkpoint is removed.
+  git lfs fetch --checkpoint=auto --include=a.dat
+  [ ! -f "$checkpoint" ]
+)
+end_test
@@ -137,3 +137,43 @@ begin_test "filter process: hash-object --stdin --path does not hang"
   git add .
 )
 end_test
+
+begin_test "filter process: --debug-protocol"
+(
+  set -e
+
+  reponame="filter-process-debug-protocol"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config filter.lfs.process "git-lfs filter-process --debug-protocol"
+  git config lfs.filterprotocoldebugfile "$TRASHDIR/$reponame.log"
+
+  git lfs track "*.dat"
+  contents="$(printf '%0300d' 0)"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  cat "$TRASHDIR/$reponame.log"
+  grep -E '^[0-9T:.+Z-]+ in  0016 "git-filter-client\\n"$' "$TRASHDIR/$reponame.log"
+  grep -E '^[0-9T:.+Z-]+ out 0016 "git-filter-server\\n"$' "$TRASHDIR/$reponame.log"
+  grep ' in  0012 "command=clean\\n"' "$TRASHDIR/$reponame.log"
+  grep ' in  0000 (flush)' "$TRASHDIR/$reponame.log"
+  grep " in  0130 \"$(printf '%0256d' 0)\"... (300 bytes)" "$TRASHDIR/$reponame.log"
+  grep ' out 0013 "status=success\\n"' "$TRASHDIR/$reponame.log"
+  grep " out [0-9a-f]\{4\} \"version https://git-lfs.github.com/spec/v1" "$TRASHDIR/$reponame.log"
+
+  # The filter still works as usual.
+  rm a.dat
+  git checkout -- a.dat
+  [ "$contents" = "$(cat a.dat)" ]
+  grep ' in  0013 "command=smudge\\n"' "$TRASHDIR/$reponame.log"
+
+  # Without lfs.filterprotocoldebugfile, packets are logged to stderr.
+  git config --unset lfs.filterprotocoldebugfile
+  rm a.dat
+  git checkout -- a.dat 2>checkout.log
+  grep ' in  0013 "command=smudge\\n"' checkout.log
+)
+end_test
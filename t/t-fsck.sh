@@ -39,7 +39,8 @@ begin_test "fsck default"
   echo "CORRUPTION" >> .git/lfs/objects/$aOid12/$aOid34/$aOid
 
   moved=$(canonical_path "$TRASHDIR/$reponame/.git/lfs/bad")
-  expected="$(printf 'Object a.dat (%s) is corrupt
+  expected="$(printf 'Object a.dat (%s) has a size mismatch: it is 21 bytes, but its pointer records 10 bytes
+\tIts first 10 bytes match, so it has 11 bytes of trailing data
 Moving corrupt objects to %s' "$aOid" "$moved")"
   [ "$expected" = "$(git lfs fsck)" ]
 
@@ -84,7 +85,9 @@ begin_test "fsck dry run"
 
   echo "CORRUPTION" >> .git/lfs/objects/$aOid12/$aOid34/$aOid
 
-  [ "Object a.dat ($aOid) is corrupt" = "$(git lfs fsck --dry-run)" ]
+  expected="$(printf 'Object a.dat (%s) has a size mismatch: it is 21 bytes, but its pointer records 10 bytes
+\tIts first 10 bytes match, so it has 11 bytes of trailing data' "$aOid")"
+  [ "$expected" = "$(git lfs fsck --dry-run)" ]
 
   if [ "$aOid" = "$(calc_oid_file .git/lfs/objects/$aOid12/$aOid34/$aOid)" ]; then
     echo "oid for a.dat still matches match"
@@ -137,3 +140,430 @@ begin_test "fsck: outside git repository"
   grep "Not in a git repository" fsck.log
 )
 end_test
+
+begin_test "fsck: missing object"
+(
+  set -e
+
+  reponame="fsck-missing"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  echo "test data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  aOid=$(git log --patch a.dat | grep "^+oid" | cut -d ":" -f 2)
+  # Remove the working copy too, since scanning the index may otherwise
+  # clean it into the object store again.
+  rm a.dat ".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/$aOid"
+
+  set +e
+  git lfs fsck > fsck.log 2>&1
+  res=$?
+  set -e
+
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object a.dat ($aOid) is missing" fsck.log
+  [ "0" -eq "$(grep -c "fsck OK" fsck.log)" ]
+  [ ! -d .git/lfs/bad ]
+)
+end_test
+
+begin_test "fsck: unreferenced corrupt object"
+(
+  set -e
+
+  reponame="fsck-unreferenced"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  echo "test data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  echo "other data" > b.dat
+  bOid="$(calc_oid_file b.dat)"
+  git add b.dat
+  git rm --cached -q b.dat
+  rm b.dat
+
+  bPath=".git/lfs/objects/${bOid:0:2}/${bOid:2:2}/$bOid"
+  [ -f "$bPath" ]
+  echo "CORRUPTION" >> "$bPath"
+
+  set +e
+  git lfs fsck > fsck.log 2>&1
+  res=$?
+  set -e
+
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object $bOid is corrupt" fsck.log
+  [ -f ".git/lfs/bad/$bOid" ]
+  [ ! -e "$bPath" ]
+
+  [ "Git LFS fsck OK" = "$(git lfs fsck)" ]
+)
+end_test
+
+begin_test "fsck: files which should be pointers"
+(
+  set -e
+
+  reponame="fsck-pointers"
+  git init $reponame
+  cd $reponame
+
+  echo "test data" > a.dat
+  echo "test data 2" > b.dat
+  git add a.dat b.dat
+  git commit -m "add files to git"
+
+  git lfs track "a.dat"
+  git add .gitattributes
+  git commit -m "track a.dat"
+
+  set +e
+  git lfs fsck --pointers > fsck.log 2>&1
+  res=$?
+  set -e
+
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "File a.dat should have been a pointer, but was not" fsck.log
+  [ "0" -eq "$(grep -c "b.dat" fsck.log)" ]
+
+  # The object check alone finds nothing wrong.
+  [ "Git LFS fsck OK" = "$(git lfs fsck --objects)" ]
+)
+end_test
+
+begin_test "fsck --fix: downloads missing and corrupt objects"
+(
+  set -e
+
+  reponame="fsck-fix"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="a data"
+  contents_a_oid="$(calc_oid "$contents_a")"
+  contents_b="b data"
+  contents_b_oid="$(calc_oid "$contents_b")"
+  printf "%s" "$contents_a" > a.dat
+  printf "%s" "$contents_b" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  rm a.dat ".git/lfs/objects/${contents_a_oid:0:2}/${contents_a_oid:2:2}/$contents_a_oid"
+  printf "%s" "CORRUPTION" >> ".git/lfs/objects/${contents_b_oid:0:2}/${contents_b_oid:2:2}/$contents_b_oid"
+
+  git lfs fsck --fix --dry-run 2>&1 | tee fsck.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fsck --fix --dry-run' to fail"
+    exit 1
+  fi
+  grep "Would download a.dat ($contents_a_oid)" fsck.log
+  grep "Would download b.dat ($contents_b_oid)" fsck.log
+  refute_local_object "$contents_a_oid"
+
+  git lfs fsck --fix 2>&1 | tee fsck.log
+  grep "Object a.dat ($contents_a_oid) is missing" fsck.log
+  grep "Object b.dat ($contents_b_oid) has a size mismatch" fsck.log
+  grep "Downloaded 2 object(s)" fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  [ -f ".git/lfs/bad/$contents_b_oid" ]
+  assert_local_object "$contents_a_oid" "${#contents_a}"
+  assert_local_object "$contents_b_oid" "${#contents_b}"
+
+  [ "Git LFS fsck OK" = "$(git lfs fsck)" ]
+)
+end_test
+
+begin_test "fsck --fix: reports unrecoverable objects"
+(
+  set -e
+
+  reponame="fsck-fix-unrecoverable"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+  git push origin main
+
+  # This object is never pushed, so the server cannot provide it.
+  contents="unpushed"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add a.dat
+  git commit -m "add a.dat"
+  commit="$(git rev-parse --short HEAD)"
+
+  rm a.dat ".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+
+  git lfs fsck --fix 2>&1 | tee fsck.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fsck --fix' to fail"
+    exit 1
+  fi
+
+  grep "Unrecoverable objects" fsck.log
+  grep "a.dat ($contents_oid)" fsck.log
+  grep "referenced by $commit" fsck.log
+)
+end_test
+
+begin_test "fsck --verify-remote: reports objects missing from the remote"
+(
+  set -e
+
+  reponame="fsck-verify-remote"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "pushed" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  git lfs fsck --verify-remote 2>&1 | tee fsck.log
+  grep "Checking 1 object(s) on origin" fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  # Commit without pushing, so the server does not have the object.
+  contents="unpushed"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  commit="$(git rev-parse --short HEAD)"
+
+  git lfs fsck --verify-remote --remote origin HEAD 2>&1 | tee fsck.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs fsck --verify-remote' to fail"
+    exit 1
+  fi
+  grep "Objects missing from origin" fsck.log
+  grep "b.dat ($contents_oid)" fsck.log
+  grep "introduced by $commit" fsck.log
+  [ "0" -eq "$(grep -c "a.dat" fsck.log)" ]
+
+  # The missing object is excluded, so nothing is missing.
+  git lfs fsck --verify-remote -X "b.dat" 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  # The pushed ref has every object.
+  git lfs fsck --verify-remote origin/main 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+)
+end_test
+
+begin_test "fsck: object store layout"
+(
+  set -e
+
+  reponame="fsck-layout"
+  git init $reponame
+  cd $reponame
+
+  git lfs track "*.dat"
+  printf "%s" "test data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  aOid="$(calc_oid "test data")"
+  aPath=".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/$aOid"
+  rm a.dat
+
+  # Place the object at the wrong fan-out path, and leak other files into
+  # the object store.
+  mkdir -p .git/lfs/objects/zz
+  mv "$aPath" ".git/lfs/objects/zz/$aOid"
+  printf "%s" "partial" > ".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/$aOid-tmp123"
+  emptyOid="0000000000000000000000000000000000000000000000000000000000000000"
+  mkdir -p .git/lfs/objects/00/00
+  touch ".git/lfs/objects/00/00/$emptyOid"
+  find .git/lfs/objects -exec touch -t 200001010000 {} +
+
+  # A file which is still being written is left alone. Give it a modification
+  # time in the future, so that it stays recent however slowly this runs.
+  printf "%s" "in progress" > ".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/incoming"
+  touch -t 209901010000 ".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/incoming"
+
+  set +e
+  git lfs fsck --objects > fsck.log 2>&1
+  res=$?
+  set -e
+
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object $aOid is misplaced at zz/$aOid" fsck.log
+  grep "${aOid:0:2}/${aOid:2:2}/$aOid-tmp123 in the object store is not a Git LFS object" fsck.log
+  grep "Object $emptyOid is empty" fsck.log
+  [ "0" -eq "$(grep -c "incoming" fsck.log)" ]
+  [ -f ".git/lfs/objects/zz/$aOid" ]
+
+  git lfs fsck --objects --fix --dry-run 2>&1 | tee fsck.log
+  grep "Would move" fsck.log
+  [ -f ".git/lfs/objects/zz/$aOid" ]
+
+  # The empty object and the leaked file named after an OID are counted as
+  # objects until they are moved aside.
+  git lfs env --metrics | tee metrics.log
+  grep "ObjectCount=3" metrics.log
+
+  git -c lfs.metricsfile="$TRASHDIR/fsck-layout.json" lfs fsck --objects --fix 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+  grep '"total_cached_bytes":9,"object_count":1' "$TRASHDIR/fsck-layout.json"
+
+  assert_local_object "$aOid" 9
+  [ ! -d .git/lfs/objects/zz ]
+  [ ! -d .git/lfs/objects/00 ]
+  [ -f ".git/lfs/bad/$aOid-tmp123" ]
+  [ -f ".git/lfs/bad/$emptyOid" ]
+  [ -f ".git/lfs/objects/${aOid:0:2}/${aOid:2:2}/incoming" ]
+
+  [ "Git LFS fsck OK" = "$(git lfs fsck --objects)" ]
+)
+end_test
+
+begin_test "fsck: size mismatch with trailing data"
+(
+  set -e
+
+  reponame="fsck-trailing-data"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents="a data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  objPath=".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+  rm a.dat
+  echo >> "$objPath"
+
+  set +e
+  git lfs fsck --objects > fsck.log 2>&1
+  res=$?
+  set -e
+
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object a.dat ($contents_oid) has a size mismatch: it is 7 bytes, but its pointer records 6 bytes" fsck.log
+  grep "Its first 6 bytes match, so it has 1 bytes of trailing data" fsck.log
+  [ "0" -eq "$(grep -c "is corrupt" fsck.log)" ]
+  [ -f ".git/lfs/bad/$contents_oid" ]
+
+  git lfs fsck --objects --fix 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+  assert_local_object "$contents_oid" "${#contents}"
+)
+end_test
+
+begin_test "fsck --incremental"
+(
+  set -e
+
+  reponame="fsck-incremental"
+  git init $reponame
+  cd $reponame
+
+  git config lfs.fsck.samplepercent 0
+
+  git lfs track "*.dat"
+  printf "%s" "a data" > a.dat
+  printf "%s" "b data" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "first commit"
+  aOid="$(calc_oid "a data")"
+  bOid="$(calc_oid "b data")"
+  bPath=".git/lfs/objects/${bOid:0:2}/${bOid:2:2}/$bOid"
+
+  # Without any state, every object is verified.
+  [ "Git LFS fsck OK" = "$(git lfs fsck --objects --incremental)" ]
+  [ -f .git/lfs/fsck-state.json ]
+
+  git lfs fsck --objects --incremental 2>&1 | tee fsck.log
+  grep "Skipped 2 object(s) unchanged since they were last verified" fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  # Damage an object, but restore its modification time, so that only a full
+  # run finds it.
+  touch -r "$bPath" stamp
+  printf "%s" "B DATA" > "$bPath"
+  touch -r stamp "$bPath"
+
+  git lfs fsck --objects --incremental 2>&1 | tee fsck.log
+  grep "Git LFS fsck OK" fsck.log
+
+  set +e
+  git lfs fsck --objects --incremental --full --dry-run > fsck.log 2>&1
+  res=$?
+  set -e
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object b.dat ($bOid) is corrupt" fsck.log
+  [ "0" -eq "$(grep -c "Skipped" fsck.log)" ]
+
+  # Changing the object's modification time makes it be verified again.
+  touch "$bPath"
+  set +e
+  git lfs fsck --objects --incremental > fsck.log 2>&1
+  res=$?
+  set -e
+  cat fsck.log
+  [ "$res" = "1" ]
+  grep "Object b.dat ($bOid) is corrupt" fsck.log
+  grep "Skipped 1 object(s)" fsck.log
+)
+end_test
+
+begin_test "fsck --incremental: invalid state"
+(
+  set -e
+
+  reponame="fsck-incremental-state"
+  git init $reponame
+  cd $reponame
+
+  git config lfs.fsck.samplepercent 0
+
+  git lfs track "*.dat"
+  printf "%s" "a data" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "first commit"
+
+  git lfs fsck --objects
+  git lfs fsck --objects --incremental | grep "Skipped 1 object(s)"
+
+  # Corrupt state degrades to a full run.
+  printf "%s" '{"version":1,"objects":' > .git/lfs/fsck-state.json
+  git lfs fsck --objects --incremental 2>&1 | tee fsck.log
+  grep "The fsck state is invalid, so every object will be verified" fsck.log
+  [ "0" -eq "$(grep -c "Skipped" fsck.log)" ]
+  grep "Git LFS fsck OK" fsck.log
+
+  # The full run recorded a valid state again.
+  git lfs fsck --objects --incremental | grep "Skipped 1 object(s)"
+
+  # State of another version is discarded.
+  sed -e 's/"version":1/"version":99/' .git/lfs/fsck-state.json > state.json
+  mv state.json .git/lfs/fsck-state.json
+  [ "Git LFS fsck OK" = "$(git lfs fsck --objects --incremental)" ]
+)
+end_test
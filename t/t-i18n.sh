@@ -0,0 +1,70 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "i18n: messages are translated"
+(
+  set -e
+
+  reponame="i18n-translated"
+  git init "$reponame"
+  cd "$reponame"
+
+  LC_ALL=de_DE.UTF-8 git lfs track "*.dat" > track.log
+  grep "\"\*.dat\" wird verfolgt" track.log
+
+  LC_ALL=de_DE.UTF-8 git lfs track "*.dat" > track.log
+  grep "\"\*.dat\" wird bereits unterstützt" track.log
+
+  # Messages which have not been translated are printed in English.
+  printf "a" > a.dat
+  git add a.dat
+  git commit -m "add a.dat"
+  LC_ALL=de_DE.UTF-8 git lfs track --verbose "a.dat" > track.log
+  grep "Searching for files matching pattern: a.dat" track.log
+)
+end_test
+
+begin_test "i18n: locale environment variables"
+(
+  set -e
+
+  reponame="i18n-env"
+  git init "$reponame"
+  cd "$reponame"
+
+  env -u LC_ALL LANG=de_DE.UTF-8 git lfs track "*.dat" > track.log
+  grep "wird verfolgt" track.log
+
+  env -u LC_ALL LANG=en_US.UTF-8 LC_MESSAGES=de_DE git lfs track "*.bin" > track.log
+  grep "wird verfolgt" track.log
+
+  LC_ALL=C LANG=de_DE.UTF-8 git lfs track "*.iso" > track.log
+  grep "Tracking \"\*.iso\"" track.log
+
+  LC_ALL=xx_YY.UTF-8 git lfs track "*.zip" > track.log
+  grep "Tracking \"\*.zip\"" track.log
+)
+end_test
+
+begin_test "i18n: machine-readable output is not translated"
+(
+  set -e
+
+  reponame="i18n-machine-readable"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs version --json > version-en.json
+  LC_ALL=de_DE.UTF-8 git lfs version --json > version-de.json
+  diff -u version-en.json version-de.json
+
+  printf "before" > before.dat
+  printf "after" > after.dat
+  git lfs pointer --file=before.dat > before.ptr
+  git lfs pointer --file=after.dat > after.ptr
+  git lfs diff-pointer before.ptr after.ptr > diff-en.json
+  LC_ALL=de_DE.UTF-8 git lfs diff-pointer before.ptr after.ptr > diff-de.json
+  diff -u diff-en.json diff-de.json
+)
+end_test
@@ -88,3 +88,84 @@ begin_test "install with supported expandable core.hooksPath"
   assert_hooks "$HOME/custom_hooks_dir"
 )
 end_test
+
+begin_test "install with relative core.hooksPath in bare repository"
+(
+  set -e
+
+  repo_name="supported-custom-hooks-path-bare"
+  git init --bare "$repo_name"
+  cd "$repo_name"
+
+  git config --local core.hooksPath "custom_hooks_dir"
+
+  git lfs install 2>&1 | tee install.log
+  grep "Updated git hooks" install.log
+
+  assert_hooks "custom_hooks_dir"
+  refute_hooks "hooks"
+)
+end_test
+
+begin_test "update --manual and uninstall with core.hooksPath"
+(
+  set -e
+
+  repo_name="supported-custom-hooks-path-manual"
+  git init "$repo_name"
+  cd "$repo_name"
+
+  hooks_dir="$(cd .. && pwd)/$repo_name-hooks"
+  mkdir -p "$hooks_dir"
+  git config --local core.hooksPath "$hooks_dir"
+
+  git lfs update --manual 2>&1 | tee manual.log
+  grep "Add the following to $hooks_dir/pre-push:" manual.log
+  if grep -q ".git/hooks/pre-push:" manual.log; then
+    exit 1
+  fi
+
+  git lfs install
+  assert_hooks "$hooks_dir"
+
+  git lfs uninstall 2>&1 | tee uninstall.log
+  grep "Removed pre-push hook." uninstall.log
+  refute_hooks "$hooks_dir"
+)
+end_test
+
+begin_test "install with read-only core.hooksPath"
+(
+  set -e
+
+  # Windows lacks POSIX permissions.
+  [ "$IS_WINDOWS" -eq 1 ] && exit 0
+
+  # Root is exempt from permissions.
+  [ "$(id -u)" -eq 0 ] && exit 0
+
+  repo_name="read-only-custom-hooks-path"
+  git init "$repo_name"
+  cd "$repo_name"
+
+  hooks_dir="$(cd .. && pwd)/$repo_name-hooks"
+  mkdir -p "$hooks_dir"
+  chmod 555 "$hooks_dir"
+  git config --local core.hooksPath "$hooks_dir"
+
+  git lfs install >install.log 2>&1
+  cat install.log
+  grep "the hooks directory $hooks_dir is not writable by the current user" install.log
+  grep "Add the following to $hooks_dir/pre-push:" install.log
+  grep "Git LFS initialized." install.log
+  refute_hooks "$hooks_dir"
+
+  # Hooks which an administrator has added are left alone.
+  chmod 755 "$hooks_dir"
+  git lfs install
+  chmod 555 "$hooks_dir"
+  git lfs update 2>&1 | tee update.log
+  [ "Updated git hooks." = "$(cat update.log)" ]
+  chmod 755 "$hooks_dir"
+)
+end_test
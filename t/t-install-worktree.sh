@@ -130,13 +130,53 @@ begin_test "install --worktree without worktreeConfig extension"
   cd "$treename"
 
   set +e
-  git lfs install --worktree >out.log
+  git lfs install --worktree >out.log 2>&1
   res=$?
   set -e
 
   cat out.log
-  grep -E "error running.*git.*config" out.log
+  grep "requires the worktreeConfig extension" out.log
+  grep -- "--enable-worktree-config" out.log
   [ "$res" -eq 2 ]
+  [ -z "$(git config extensions.worktreeConfig)" ]
+)
+end_test
+
+begin_test "install --worktree --enable-worktree-config"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-enable-config"
+  mkdir "$reponame"
+  cd "$reponame"
+  git init
+
+  touch a.txt
+  git add a.txt
+  git commit -m "initial commit"
+
+  git worktree add "../$reponame-wt-skip"
+  git worktree add "../$reponame-wt-smudge"
+
+  cd "../$reponame-wt-skip"
+  git lfs install --worktree --skip-smudge --enable-worktree-config >out.log
+  cat out.log
+  grep "Enabled the worktreeConfig extension" out.log
+  [ "true" = "$(git config extensions.worktreeConfig)" ]
+  [ "1" = "$(git config core.repositoryformatversion)" ]
+
+  cd "../$reponame-wt-smudge"
+  git lfs install --worktree >out.log
+  cat out.log
+  [ "0" -eq "$(grep -c "Enabled the worktreeConfig extension" out.log)" ]
+
+  # each working tree has its own smudge filter
+  [ "git-lfs smudge -- %f" = "$(git config --worktree filter.lfs.smudge)" ]
+  [ "git-lfs smudge --skip -- %f" = "$(cd "../$reponame-wt-skip" && git config --worktree filter.lfs.smudge)" ]
+  [ -z "$(cd "../$reponame" && git config --worktree filter.lfs.smudge)" ]
+
+  git lfs env | grep 'git config filter.lfs.smudge = "git-lfs smudge -- %f" (worktree)'
+  (cd "../$reponame-wt-skip" && git lfs env) | grep 'git config filter.lfs.smudge = "git-lfs smudge --skip -- %f" (worktree)'
 )
 end_test
 
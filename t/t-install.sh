@@ -73,21 +73,10 @@ begin_test "install updates repo hooks"
   cd install-repo-hooks
   git init
 
-  pre_push_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/pre-push.\\n\"; exit 2; }
-git lfs pre-push \"\$@\""
-
-  post_checkout_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-checkout.\\n\"; exit 2; }
-git lfs post-checkout \"\$@\""
-
-  post_commit_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-commit.\\n\"; exit 2; }
-git lfs post-commit \"\$@\""
-
-  post_merge_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-merge.\\n\"; exit 2; }
-git lfs post-merge \"\$@\""
+  pre_push_hook="$(lfs_hook pre-push)"
+  post_checkout_hook="$(lfs_hook post-checkout)"
+  post_commit_hook="$(lfs_hook post-commit)"
+  post_merge_hook="$(lfs_hook post-merge)"
 
   [ "Updated git hooks.
 Git LFS initialized." = "$(git lfs install)" ]
@@ -104,7 +93,14 @@ git lfs push --stdin \$*" > .git/hooks/pre-push
 Git LFS initialized." = "$(git lfs install)" ]
   [ "$pre_push_hook" = "$(cat .git/hooks/pre-push)" ]
 
-  # don't replace unexpected hook
+  # chain unexpected hook
+  echo "test" > .git/hooks/pre-push
+  [ "Updated git hooks.
+Git LFS initialized." = "$(git lfs install)" ]
+  [ "test" = "$(cat .git/hooks/pre-push.git-lfs-chained)" ]
+  grep '"$git_lfs" pre-push' .git/hooks/pre-push
+
+  # don't replace unexpected hook when a hook is already chained
   expected="Hook already exists: pre-push
 
 	test
@@ -354,3 +350,134 @@ begin_test "can install when multiple global values registered"
   git lfs install --force
 )
 end_test
+
+begin_test "install with another tool's filter settings"
+(
+  set -e
+
+  git config --global filter.lfs.clean "other-tool clean %f"
+
+  set +e
+  git lfs install --skip-repo >install.log 2>&1
+  res=$?
+  set -e
+
+  cat install.log
+  [ "$res" -eq 2 ]
+  grep "the \"filter.lfs.clean\" attribute is set to \"other-tool clean %f\" in the global Git config, which does not run Git LFS" install.log
+  grep "git lfs install --force" install.log
+  [ "other-tool clean %f" = "$(git config --global filter.lfs.clean)" ]
+
+  git lfs install --force --skip-repo
+  [ "git-lfs clean -- %f" = "$(git config --global filter.lfs.clean)" ]
+)
+end_test
+
+begin_test "install when git-lfs is not on PATH"
+(
+  set -e
+
+  gitdir="$(dirname "$(command -v git)")"
+  if [ -x "$gitdir/git-lfs" ]; then
+    echo "skip: git-lfs is installed alongside git"
+    exit 0
+  fi
+
+  gitlfs="$(command -v git-lfs)"
+  PATH="$gitdir:/bin:/usr/bin" "$gitlfs" install --skip-repo >install.log 2>&1
+  cat install.log
+  grep "WARNING: Git could not run git-lfs from PATH" install.log
+  grep "Add $(dirname "$gitlfs") to PATH" install.log
+  grep "Git LFS initialized." install.log
+
+  git lfs install --skip-repo >install.log 2>&1
+  [ "0" -eq "$(grep -c "WARNING" install.log)" ]
+)
+end_test
+
+begin_test "install --check"
+(
+  set -e
+
+  reponame="install-check"
+  git init "$reponame"
+  cd "$reponame"
+
+  set +e
+  git lfs install --check >check.json
+  res=$?
+  set -e
+
+  cat check.json
+  [ "$res" -eq 1 ]
+  grep '"ready": false' check.json
+  grep '"configured": true' check.json
+  grep '"scope": "global"' check.json
+  grep '"state": "missing"' check.json
+  [ ! -e .git/hooks/pre-push ]
+
+  git lfs install
+  git lfs track "*.dat"
+  git lfs install --check >check.json
+  cat check.json
+  grep '"ready": true' check.json
+  grep '"supported": true' check.json
+  grep '"process": "git-lfs filter-process"' check.json
+  [ "4" -eq "$(grep -c '"state": "installed"' check.json)" ]
+  grep -A2 '"tracked_patterns"' check.json | grep '"\*.dat"'
+
+  printf '#!/bin/sh\ngit lfs pre-push "$@"\n' > .git/hooks/pre-push
+  printf '#!/bin/sh\necho custom\n' > .git/hooks/post-merge
+  set +e
+  git lfs install --check >check.json
+  res=$?
+  set -e
+
+  cat check.json
+  [ "$res" -eq 1 ]
+  grep -A1 '"type": "pre-push"' check.json | tail -1 | grep "/.git/hooks/pre-push"
+  grep -B2 '"state": "outdated"' check.json | grep '"type": "pre-push"'
+  grep -B2 '"state": "foreign"' check.json | grep '"type": "post-merge"'
+)
+end_test
+
+begin_test "install records lfs.binarypath for the hooks"
+(
+  set -e
+
+  reponame="install-binarypath"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install --local
+  gitlfs="$(git config --local lfs.binarypath)"
+  [ -x "$gitlfs" ]
+  [ "$(git lfs version)" = "$("$gitlfs" version)" ]
+
+  # With a PATH that has git, but not git-lfs, and no global config, the hook
+  # falls back on lfs.binarypath. Run without arguments, `git lfs post-checkout` asks to be
+  # run through the hook.
+  mkdir gitonly
+  printf '#!/bin/sh\nexec "%s" "$@"\n' "$(command -v git)" > gitonly/git
+  chmod +x gitonly/git
+  env -u GIT_LFS_PATH HOME="$(pwd)/gitonly" PATH="$(pwd)/gitonly" GIT_EXEC_PATH="$(pwd)/gitonly" \
+    /bin/sh .git/hooks/post-checkout >out.log 2>&1 || true
+  cat out.log
+  grep "This should be run through Git's post-checkout hook." out.log
+
+  git config --local --unset lfs.binarypath
+  set +e
+  env -u GIT_LFS_PATH HOME="$(pwd)/gitonly" PATH="$(pwd)/gitonly" GIT_EXEC_PATH="$(pwd)/gitonly" \
+    /bin/sh .git/hooks/post-checkout 2>err.log
+  res=$?
+  set -e
+  cat err.log
+  [ "$res" -eq 2 ]
+  grep "git-lfs was not found. Tried:" err.log
+  grep "lfs.binarypath: (none)" err.log
+
+  git lfs install --local
+  git lfs uninstall --local
+  [ -z "$(git config --local lfs.binarypath)" ]
+)
+end_test
@@ -87,6 +87,43 @@ begin_test "create lock with server using client cert"
 )
 end_test
 
+begin_test "create lock with server using lfs.tlscertfile"
+(
+  set -e
+  reponame="lock_create_tls_cert_file"
+  setup_remote_repo_with_file "$reponame" "tls.dat"
+
+  # Use a copy of the global configuration without Git's own client
+  # certificate options.
+  cp "$HOME/.gitconfig" "$TRASHDIR/tls-gitconfig"
+  export HOME="$TRASHDIR/tls-home"
+  mkdir -p "$HOME"
+  mv "$TRASHDIR/tls-gitconfig" "$HOME/.gitconfig"
+  git config --global --unset "http.$LFS_CLIENT_CERT_URL/.sslKey"
+  git config --global --unset "http.$LFS_CLIENT_CERT_URL/.sslCert"
+
+  git config lfs.url "$CLIENTCERTGITSERVER/$reponame.git/info/lfs"
+  set +e
+  git lfs lock --json "tls.dat" 2> lock.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+
+  git config "lfs.$LFS_CLIENT_CERT_URL/.tlscertfile" "$LFS_CLIENT_CERT_FILE"
+  git config "lfs.$LFS_CLIENT_CERT_URL/.tlskeyfile" "$LFS_CLIENT_KEY_FILE"
+  git lfs lock --json "tls.dat" | tee lock.json
+  id=$(assert_lock lock.json tls.dat)
+  assert_server_lock "$reponame" "$id"
+  git lfs unlock --id="$id"
+
+  # The passphrase of an encrypted key comes from git credential.
+  git config "lfs.$LFS_CLIENT_CERT_URL/.tlskeyfile" "$LFS_CLIENT_KEY_FILE_ENCRYPTED"
+  git lfs lock --json "tls.dat" | tee lock.json
+  id=$(assert_lock lock.json tls.dat)
+  assert_server_lock "$reponame" "$id"
+)
+end_test
+
 begin_test "creating a lock (with output)"
 (
   set -e
@@ -294,3 +331,37 @@ begin_test "lock with .gitignore and lfs.lockignoredfiles"
   refute_file_writeable a.txt
 )
 end_test
+
+begin_test "lock breaks lfs.link=hardlink links"
+(
+  set -e
+
+  reponame="lock-breaks-hardlink"
+  setup_remote_repo_with_file "$reponame" "a.txt"
+  clone_repo "$reponame" "$reponame"
+
+  echo "*.txt filter=lfs diff=lfs merge=lfs -text lockable" > .gitattributes
+  git add .gitattributes
+  git commit -m ".gitattributes: mark 'a.txt' as lockable"
+
+  oid="$(git cat-file -p HEAD:a.txt | awk '/^oid/ { print $2 }' | cut -d ":" -f 2)"
+  objPath=".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid"
+  inode () {
+    ls -i "$1" | awk '{ print $1 }'
+  }
+
+  git config lfs.link hardlink
+  rm -f a.txt && git lfs checkout a.txt
+  [ "$(inode a.txt)" = "$(inode "$objPath")" ]
+  refute_file_writeable a.txt
+
+  git lfs lock --json "a.txt" | tee lock.log
+  assert_lock lock.log a.txt
+
+  # The file is made writeable as a copy, leaving the object read-only.
+  assert_file_writeable a.txt
+  [ "$(inode a.txt)" != "$(inode "$objPath")" ]
+  refute_file_writeable "$objPath"
+  [ "a.txt" = "$(cat a.txt)" ]
+)
+end_test
@@ -0,0 +1,90 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# long_dir prints a relative directory path which is more than 260 characters
+# long, beyond Windows' MAX_PATH.
+long_dir () {
+  local dir=""
+  for c in a b c d e f; do
+    dir="$dir$(printf "$c%.0s" $(seq 1 50))/"
+  done
+  printf "%s" "${dir%/}"
+}
+
+begin_test "long paths: clean, checkout and pull with core.longpaths"
+(
+  # Only Windows limits the length of paths to less than the filesystem does.
+  if [[ $(uname) != *"MINGW"* ]]; then
+    echo "Skipping long paths outside Windows"
+    exit 0
+  fi
+
+  set -e
+
+  reponame="long-paths"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+  git config core.longpaths true
+
+  dir="$(long_dir)"
+  mkdir -p "$dir"
+  git lfs track "*.dat"
+
+  contents="long path data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > "$dir/a.dat"
+  [ "${#dir}" -gt 260 ]
+
+  git add .gitattributes "$dir/a.dat"
+  git commit -m "add a file with a long path"
+  assert_local_object "$contents_oid" 14
+  assert_pointer "main" "$dir/a.dat" "$contents_oid" 14
+  git push origin main
+
+  rm "$dir/a.dat"
+  git lfs checkout
+  [ "$contents" = "$(cat "$dir/a.dat")" ]
+
+  # A relative path from a subdirectory, which Go does not extend itself.
+  rm "$dir/a.dat"
+  (cd "${dir%%/*}" && git lfs checkout)
+  [ "$contents" = "$(cat "$dir/a.dat")" ]
+
+  cd ..
+  git -c core.longpaths=true clone "$GITSERVER/$reponame" "$reponame-clone"
+  cd "$reponame-clone"
+  git config core.longpaths true
+  [ "$contents" = "$(cat "$dir/a.dat")" ]
+
+  rm -rf .git/lfs/objects "$dir/a.dat"
+  git checkout -- "$dir/a.dat"
+  git lfs pull
+  [ "$contents" = "$(cat "$dir/a.dat")" ]
+)
+end_test
+
+begin_test "long paths: nested .gitattributes"
+(
+  if [[ $(uname) != *"MINGW"* ]]; then
+    echo "Skipping long paths outside Windows"
+    exit 0
+  fi
+
+  set -e
+
+  reponame="long-paths-attributes"
+  git init "$reponame"
+  cd "$reponame"
+  git config core.longpaths true
+
+  dir="$(long_dir)"
+  mkdir -p "$dir"
+  printf "*.bin filter=lfs diff=lfs merge=lfs -text\n" > "$dir/.gitattributes"
+  printf "%s" "bin data" > "$dir/a.bin"
+  git add "$dir"
+
+  git lfs track | grep "$dir/\*.bin"
+  assert_local_object "$(calc_oid "bin data")" 8
+)
+end_test
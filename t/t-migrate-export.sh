@@ -57,6 +57,29 @@ begin_test "migrate export (default branch)"
 )
 end_test
 
+begin_test "migrate export (shared storage)"
+(
+  set -e
+
+  setup_multiple_local_branches_tracked
+
+  storage="$TRASHDIR/migrate-export-storage"
+  mkdir -p "$storage"
+  cp -R .git/lfs/objects "$storage/"
+  git config lfs.storage "$storage"
+
+  md_oid="$(calc_oid "$(cat a.md)")"
+
+  # The log is kept out of the working copy, which must be clean.
+  git lfs migrate export --include="*.md" 2>&1 | tee "$TRASHDIR/migrate.log"
+  [ "0" -eq "${PIPESTATUS[0]}" ]
+  grep "not pruning $storage, since it may be shared with other repositories" "$TRASHDIR/migrate.log"
+
+  refute_pointer "refs/heads/main" "a.md"
+  [ -f "$storage/objects/${md_oid:0:2}/${md_oid:2:2}/$md_oid" ]
+)
+end_test
+
 begin_test "migrate export (with remote)"
 (
   set -e
@@ -426,6 +449,52 @@ begin_test "migrate export (--remote)"
 )
 end_test
 
+begin_test "migrate export (removes tracking lines)"
+(
+  set -e
+
+  setup_single_local_branch_tracked
+
+  git lfs migrate export --include="*.txt"
+
+  main_attrs="$(git cat-file -p "refs/heads/main:.gitattributes")"
+
+  echo "$main_attrs" | grep -q "*.md filter=lfs diff=lfs merge=lfs -text"
+  echo "$main_attrs" | grep -q "*.txt !text !filter !merge !diff"
+  [ "0" -eq "$(echo "$main_attrs" | grep -c "*.txt filter=lfs")" ]
+)
+end_test
+
+begin_test "migrate export (missing objects)"
+(
+  set -e
+
+  setup_single_local_branch_tracked
+
+  md_oid="$(calc_oid "$(cat a.md)")"
+
+  # Remove the files from the working copy, so that the objects cannot be
+  # recreated by cleaning them again.
+  git rm a.md a.txt
+  git commit -m "remove a.{txt,md}"
+  rm -rf .git/lfs/objects
+
+  original_main="$(git rev-parse refs/heads/main)"
+
+  git lfs migrate export --include="*.md, *.txt" --yes 2>&1 | tee migrate.log
+  if [ ${PIPESTATUS[0]} -eq 0 ]; then
+    echo >&2 "fatal: expected git lfs migrate export to fail, didn't"
+    exit 1
+  fi
+
+  grep "fatal: unable to find 2 Git LFS object(s) locally or on the remote:" migrate.log
+  grep "a.md ($md_oid)" migrate.log
+
+  migrated_main="$(git rev-parse refs/heads/main)"
+  assert_ref_unmoved "refs/heads/main" "$original_main" "$migrated_main"
+)
+end_test
+
 begin_test "migrate export (invalid --remote)"
 (
   set -e
@@ -225,3 +225,85 @@ begin_test "migrate import --no-rewrite (with empty commit message)"
   fi
 )
 end_test
+
+begin_test "migrate import --no-rewrite (with --include)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+  md_oid="$(calc_oid "$(git cat-file -p :a.md)")"
+
+  git lfs migrate import --no-rewrite --yes --include="*.txt" a.txt
+
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+  assert_local_object "$txt_oid" "120"
+  refute_local_object "$md_oid" "140"
+
+  # Ensure the --include pattern was added to .gitattributes
+  git cat-file -p "refs/heads/main:.gitattributes" | grep -q "^\*.txt filter=lfs diff=lfs merge=lfs -text$"
+
+  git fsck
+)
+end_test
+
+begin_test "migrate import --no-rewrite (non-matching --include)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  prev_commit_oid="$(git rev-parse HEAD)"
+
+  git lfs migrate import --no-rewrite --yes --include="*.txt" a.md 2>&1 | tee migrate.log
+  if [ ${PIPESTATUS[0]} -eq 0 ]; then
+    echo >&2 "fatal: expected git lfs migrate import --no-rewrite to fail, didn't"
+    exit 1
+  fi
+
+  grep "a.md did not match any --include arguments" migrate.log
+  [ "$prev_commit_oid" = "$(git rev-parse HEAD)" ]
+)
+end_test
+
+begin_test "migrate import --no-rewrite (already a pointer)"
+(
+  set -e
+
+  setup_local_branch_with_gitattrs
+
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+
+  git lfs migrate import --no-rewrite --yes a.txt
+  prev_commit_oid="$(git rev-parse HEAD)"
+
+  git lfs migrate import --no-rewrite --yes a.txt 2>&1 | tee migrate.log
+
+  grep "a.txt is already a Git LFS pointer, skipping" migrate.log
+  grep "nothing to convert" migrate.log
+
+  # Ensure no new commit was made
+  [ "$prev_commit_oid" = "$(git rev-parse HEAD)" ]
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+)
+end_test
+
+begin_test "migrate import --no-rewrite (with --edit)"
+(
+  set -e
+
+  setup_local_branch_with_gitattrs
+
+  cat > "$TRASHDIR/editor.sh" <<-EOM
+#!/bin/sh
+grep -q "a.txt: convert to Git LFS" "\$1"
+echo "edited commit message" > "\$1"
+EOM
+  chmod +x "$TRASHDIR/editor.sh"
+
+  GIT_EDITOR="$TRASHDIR/editor.sh" git lfs migrate import --no-rewrite --edit --yes a.txt
+
+  [ "edited commit message" = "$(git log -1 --pretty=format:%s)" ]
+)
+end_test
@@ -37,6 +37,128 @@ begin_test "migrate import (default branch)"
 )
 end_test
 
+begin_test "migrate import (already migrated history)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  md_oid="$(calc_oid "$(git cat-file -p :a.md)")"
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+
+  git lfs migrate import --everything --include="*.md,*.txt"
+
+  main="$(git rev-parse refs/heads/main)"
+  feature="$(git rev-parse refs/heads/my-feature)"
+
+  git lfs migrate import --everything --include="*.md,*.txt" --yes 2>&1 | tee migrate.log
+  grep "migrate: 0 commit(s) modified, 2 passed through unchanged" migrate.log
+
+  [ "$main" = "$(git rev-parse refs/heads/main)" ]
+  [ "$feature" = "$(git rev-parse refs/heads/my-feature)" ]
+
+  assert_pointer "refs/heads/main" "a.md" "$md_oid" "140"
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+)
+end_test
+
+begin_test "migrate import (partially migrated history)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  md_oid="$(calc_oid "$(git cat-file -p :a.md)")"
+  txt_oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+
+  git lfs migrate import --everything --include="*.txt"
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+
+  # Pointers written by the first migration are not wrapped in pointers of
+  # their own by the second.
+  git lfs migrate import --everything --include="*.md,*.txt" --yes 2>&1 | tee migrate.log
+  grep "migrate: 2 commit(s) modified, 0 passed through unchanged" migrate.log
+  grep "already a Git LFS pointer" migrate.log && exit 1
+
+  assert_pointer "refs/heads/main" "a.md" "$md_oid" "140"
+  assert_pointer "refs/heads/main" "a.txt" "$txt_oid" "120"
+)
+end_test
+
+begin_test "migrate import (mixed pointers and blobs, verbose)"
+(
+  set -e
+
+  reponame="migrate-import-mixed-pointers"
+  remove_and_create_local_repo "$reponame"
+
+  # The first commit adds a.txt as a Git LFS pointer, and the second changes
+  # it as a plain blob, as in a partially migrated repository.
+  git lfs track "*.txt"
+  printf "pointer contents" > a.txt
+  git add .gitattributes a.txt
+  git commit -m "add a.txt with Git LFS"
+
+  git lfs untrack "*.txt"
+  printf "blob contents" > a.txt
+  git add .gitattributes a.txt
+  git commit -m "change a.txt without Git LFS"
+
+  pointer_oid="$(calc_oid "pointer contents")"
+  blob_oid="$(calc_oid "blob contents")"
+
+  git lfs migrate import --verbose --include="*.txt" --yes 2>&1 | tee migrate.log
+  grep "migrate: a.txt: already a Git LFS pointer, skipping" migrate.log
+
+  assert_pointer "refs/heads/main~1" "a.txt" "$pointer_oid" 16
+  assert_pointer "refs/heads/main" "a.txt" "$blob_oid" 13
+
+  # Neither object is itself a pointer.
+  assert_local_object "$pointer_oid" 16
+  assert_local_object "$blob_oid" 13
+  for oid in "$pointer_oid" "$blob_oid"; do
+    if grep -q "^version https://git-lfs" ".git/lfs/objects/${oid:0:2}/${oid:2:2}/$oid"; then
+      echo >&2 "fatal: object $oid is itself a Git LFS pointer"
+      exit 1
+    fi
+  done
+)
+end_test
+
+begin_test "migrate import (commits sharing a root tree)"
+(
+  set -e
+
+  reponame="migrate-import-shared-root-tree"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "a" > a.txt
+  git add a.txt
+  git commit -m "add a.txt"
+
+  printf "b" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  # This commit has the same root tree as the first, but its rewritten
+  # .gitattributes must also track the extension added in between.
+  git rm b.dat
+  git commit -m "remove b.dat"
+  [ "$(git rev-parse HEAD~2^{tree})" = "$(git rev-parse HEAD^{tree})" ]
+
+  git lfs migrate import --everything --yes
+
+  git cat-file -p HEAD~2:.gitattributes | tee attrs.log
+  grep "^\*.txt filter=lfs diff=lfs merge=lfs -text$" attrs.log
+  [ 0 -eq "$(grep -c "\*.dat" attrs.log)" ]
+
+  git cat-file -p HEAD:.gitattributes | tee attrs.log
+  grep "^\*.txt filter=lfs diff=lfs merge=lfs -text$" attrs.log
+  grep "^\*.dat filter=lfs diff=lfs merge=lfs -text$" attrs.log
+)
+end_test
+
 begin_test "migrate import (bare repository)"
 (
   set -e
@@ -755,6 +877,55 @@ begin_test "migrate import (--object-map)"
 )
 end_test
 
+begin_test "migrate import (ignores checkpoint from different arguments)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  md_main_oid="$(calc_oid "$(git cat-file -p "refs/heads/main:a.md")")"
+  txt_main_oid="$(calc_oid "$(git cat-file -p "refs/heads/main:a.txt")")"
+
+  # Leave behind a checkpoint from an interrupted migration with other
+  # arguments, claiming that HEAD was rewritten as itself.
+  checkpoint=".git/lfs/migrate/checkpoint"
+  mkdir -p "$(dirname "$checkpoint")"
+  printf "git-lfs-migrate-checkpoint other\n%s %s\n" \
+    "$(git rev-parse HEAD)" "$(git rev-parse HEAD)" > "$checkpoint"
+
+  git lfs migrate import --include="*.md,*.txt"
+
+  assert_pointer "refs/heads/main" "a.md" "$md_main_oid" "140"
+  assert_pointer "refs/heads/main" "a.txt" "$txt_main_oid" "120"
+
+  # The checkpoint is removed once the migration completes.
+  [ ! -e "$checkpoint" ]
+)
+end_test
+
+begin_test "migrate import (keeps its checkpoint out of lfs.storage)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  storage="$TRASHDIR/migrate-import-checkpoint-storage"
+  git config lfs.storage "$storage"
+
+  # Other clones sharing the object store may migrate too, so the checkpoint
+  # is kept with the repository, where this one is found and replaced.
+  checkpoint=".git/lfs/migrate/checkpoint"
+  mkdir -p "$(dirname "$checkpoint")"
+  printf "git-lfs-migrate-checkpoint other\n%s %s\n" \
+    "$(git rev-parse HEAD)" "$(git rev-parse HEAD)" > "$checkpoint"
+
+  git lfs migrate import --include="*.md,*.txt"
+
+  [ ! -e "$checkpoint" ]
+  [ ! -e "$storage/migrate" ]
+)
+end_test
+
 begin_test "migrate import (--include with space)"
 (
   set -e
@@ -910,3 +1081,178 @@ begin_test "migrate import (non-standard refs)"
   assert_local_object "$md_feature_oid" "30"
 )
 end_test
+
+begin_test "migrate import (--squash)"
+(
+  set -e
+
+  setup_single_local_branch_with_tags
+
+  oid="$(calc_oid "$(git cat-file -p :a.txt)")"
+
+  [ "2" -eq "$(git rev-list --count main)" ]
+
+  git lfs migrate import --include="*.txt" --squash \
+    --squash-message="import history into Git LFS"
+
+  [ "1" -eq "$(git rev-list --count main)" ]
+  [ "import history into Git LFS" = "$(git log -1 --pretty=format:%s main)" ]
+
+  assert_pointer "refs/heads/main" "a.txt" "$oid" "2"
+  assert_local_object "$oid" "2"
+
+  git cat-file -p "refs/heads/main:.gitattributes" | grep -q "*.txt filter=lfs"
+)
+end_test
+
+begin_test "migrate import (--squash, partial range)"
+(
+  set -e
+
+  setup_multiple_remote_branches
+
+  prev_remote="$(git rev-parse refs/remotes/origin/main)"
+
+  git lfs migrate import --include-ref=my-feature --exclude-ref=refs/remotes/origin/main --squash
+
+  # Commits 'B' and 'C' were squashed onto 'A'
+  [ "2" -eq "$(git rev-list --count my-feature)" ]
+  [ "$prev_remote" = "$(git rev-parse my-feature^)" ]
+)
+end_test
+
+begin_test "migrate import (--squash-message without --squash)"
+(
+  set -e
+
+  setup_single_local_branch_with_tags
+
+  git lfs migrate import --squash-message="msg" 2>&1 | tee migrate.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected git lfs migrate import to fail, didn't"
+    exit 1
+  fi
+
+  grep -q "\-\-squash-message requires \-\-squash" migrate.log
+)
+end_test
+
+begin_test "migrate import (.gitattributes in every rewritten commit)"
+(
+  set -e
+
+  reponame="migrate-import-attrs-every-commit"
+  remove_and_create_local_repo "$reponame"
+
+  printf "first" > a.psd
+  git add a.psd
+  git commit -m "add a.psd"
+
+  printf "# text files\n\n*.txt text\n" > .gitattributes
+  printf "text" > a.txt
+  git add .gitattributes a.txt
+  git commit -m "add .gitattributes"
+
+  printf "second" > a.psd
+  git add a.psd
+  git commit -m "change a.psd"
+
+  git lfs migrate import --yes --include="*.psd"
+
+  for rev in main~2 main~1 main; do
+    [ "1" -eq "$(git cat-file -p "$rev:.gitattributes" | grep -c "^\*.psd filter=lfs diff=lfs merge=lfs -text$")" ]
+  done
+
+  # Unrelated lines, including comments and blank lines, are preserved.
+  diff -u <(printf "# text files\n\n*.txt text\n*.psd filter=lfs diff=lfs merge=lfs -text\n") \
+    <(git cat-file -p "main~1:.gitattributes")
+
+  # Checking out a commit from before .gitattributes existed smudges the
+  # migrated file.
+  git checkout main~2
+  [ "lfs" = "$(git check-attr filter a.psd | awk '{ print $3 }')" ]
+  [ "first" = "$(cat a.psd)" ]
+  assert_pointer "main~2" "a.psd" "$(calc_oid "first")" 5
+)
+end_test
+
+begin_test "migrate import (existing tracking lines are not duplicated)"
+(
+  set -e
+
+  reponame="migrate-import-attrs-dedup"
+  remove_and_create_local_repo "$reponame"
+
+  printf "first" > a.psd
+  git add a.psd
+  git commit -m "add a.psd"
+
+  printf "*.psd filter=lfs -text diff=lfs merge=lfs\n" > .gitattributes
+  git add .gitattributes
+  git commit -m "track *.psd"
+
+  attrs_oid="$(git rev-parse main:.gitattributes)"
+
+  git lfs migrate import --yes --include="*.psd"
+
+  # The commit which already tracked *.psd keeps its .gitattributes as-is.
+  [ "$attrs_oid" = "$(git rev-parse main:.gitattributes)" ]
+  [ "*.psd filter=lfs diff=lfs merge=lfs -text" = "$(git cat-file -p main~1:.gitattributes)" ]
+  assert_pointer "main~1" "a.psd" "$(calc_oid "first")" 5
+)
+end_test
+
+begin_test "migrate import (--max-commit-size)"
+(
+  set -e
+
+  reponame="migrate-import-max-commit-size"
+  remove_and_create_local_repo "$reponame"
+
+  printf "small" > a.txt
+  git add a.txt
+  git commit -m "add a.txt"
+  first="$(git rev-parse HEAD)"
+
+  printf "small" > small.bin
+  printf "a larger file" > "large file.bin"
+  git add small.bin "large file.bin"
+  git commit -m "add small.bin, large file.bin"
+
+  printf "small too" > b.txt
+  git add b.txt
+  git commit -m "add b.txt"
+
+  git lfs migrate import --yes --everything --max-commit-size=10
+
+  # The commit before the large file is not rewritten.
+  [ "$first" = "$(git rev-parse main~2)" ]
+
+  # Only the large file is converted, and it is tracked by its own path.
+  assert_pointer "main~1" "large file.bin" "$(calc_oid "a larger file")" 13
+  [ "small" = "$(git cat-file -p main~1:small.bin)" ]
+  [ "small too" = "$(git cat-file -p main:b.txt)" ]
+  for rev in main~1 main; do
+    [ "/large[[:space:]]file.bin filter=lfs diff=lfs merge=lfs -text" = "$(git cat-file -p "$rev:.gitattributes")" ]
+  done
+
+  [ "lfs" = "$(git check-attr filter "large file.bin" | sed -e "s/.*: //")" ]
+  [ "unspecified" = "$(git check-attr filter small.bin | awk '{ print $3 }')" ]
+)
+end_test
+
+begin_test "migrate import (--max-commit-size with --fixup)"
+(
+  set -e
+
+  setup_single_local_branch_tracked_corrupt
+
+  set +e
+  git lfs migrate import --yes --fixup --max-commit-size=10 2>err.log
+  res=$?
+  set -e
+
+  [ "$res" -ne 0 ]
+  grep "fatal: --fixup and --max-commit-size cannot be combined" err.log
+)
+end_test
@@ -392,6 +392,67 @@ begin_test "migrate info (--everything)"
 )
 end_test
 
+begin_test "migrate info (already tracked)"
+(
+  set -e
+
+  setup_single_local_branch_tracked
+
+  git lfs migrate info 2>/dev/null | tee info.log
+  grep -E "^\*\.md +\s128 B\s1/1 files\(s\)\s100%\s100% in LFS$" info.log
+  grep -E "^\*\.txt +\s128 B\s1/1 files\(s\)\s100%\s100% in LFS$" info.log
+  grep -E "^\*\.gitattributes\s83 B +\s1/1 files\(s\)\s100%\s  0% in LFS$" info.log
+)
+end_test
+
+begin_test "migrate info (partially tracked, by size)"
+(
+  set -e
+
+  reponame="migrate-info-partially-tracked"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "a.dat"
+  printf "0123456789" > a.dat
+  printf "%s" "$(printf 'x%.0s' $(seq 1 30))" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add files"
+
+  # Half of the files are in Git LFS, but only a quarter of their contents.
+  git lfs migrate info 2>/dev/null | tee info.log
+  grep -E "^\*\.dat\s.*\s2/2 files\(s\)\s100%\s25% in LFS$" info.log
+
+  git lfs migrate info --json 2>/dev/null | tee info.json
+  grep '"qualifier":"\*.dat",.*"total_lfs":1,"percent_lfs":25}' info.json
+)
+end_test
+
+begin_test "migrate info (--pathspec)"
+(
+  set -e
+
+  setup_local_branch_with_nested_gitattrs
+
+  git lfs migrate info --pathspec --top=10 2>/dev/null | tee info.log
+  grep -E "^\*\.md +\s140 B\s1/1 files\(s\)\s100%$" info.log
+  grep -E "^b/\*\.md +\s140 B\s1/1 files\(s\)\s100%$" info.log
+  grep -E "^\*\.txt +\s120 B\s1/1 files\(s\)\s100%$" info.log
+)
+end_test
+
+begin_test "migrate info (--json)"
+(
+  set -e
+
+  setup_multiple_local_branches
+
+  git lfs migrate info --json 2>/dev/null | tee info.json
+  grep '"qualifier":"\*.md","bytes_above":140,"total_above":1,"bytes_total":140,"total":1,"bytes_lfs":0,"total_lfs":0,"percent_lfs":0' info.json
+  grep '"qualifier":"\*.txt","bytes_above":120,"total_above":1,"bytes_total":120,"total":1,"bytes_lfs":0,"total_lfs":0,"percent_lfs":0' info.json
+)
+end_test
+
 begin_test "migrate info (ambiguous reference)"
 (
   set -e
@@ -0,0 +1,50 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "object-stats"
+(
+  set -e
+
+  reponame="object-stats"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.psd" "*.png" "*.dat"
+  printf "aaaa" > a.psd
+  printf "bb" > b.psd
+  printf "cccccc" > c.png
+  git add .gitattributes a.psd b.psd c.png
+  git commit -m "add images"
+  git tag v1
+
+  git checkout -b other
+  printf "dddddddd" > d.psd
+  git add d.psd
+  git commit -m "add d.psd"
+  git checkout main
+
+  printf "e" > e.dat
+  git add e.dat
+  git rm --cached e.dat
+  git lfs clean < e.dat > /dev/null
+
+  git lfs object-stats 2>&1 | tee stats.log
+  grep "^extension  *count  *size  *average  *referenced in$" stats.log
+  grep "^\.psd  *3  *14 B  *4 B  *3 ref(s)$" stats.log
+  grep "^\.png  *1  *6 B  *6 B  *3 ref(s)$" stats.log
+  grep "^(unreferenced)  *1  *1 B  *1 B  *0 ref(s)$" stats.log
+  [ ".psd" = "$(sed -n 2p stats.log | awk '{print $1}')" ]
+
+  git lfs object-stats --sort-by=extension 2>&1 | tee stats.log
+  [ "(unreferenced)" = "$(sed -n 2p stats.log | awk '{print $1}')" ]
+  [ ".psd" = "$(sed -n 4p stats.log | awk '{print $1}')" ]
+
+  git lfs object-stats --json --sort-by=count > stats.json
+  cat stats.json
+  grep '{"extensions":\[{"extension":".psd","count":3,"size":14,"average":4,"refs":3},' stats.json
+
+  git lfs object-stats --sort-by=name >stats.log 2>&1 && exit 1
+  grep 'Invalid --sort-by "name"' stats.log
+)
+end_test
@@ -0,0 +1,117 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+ensure_git_version_isnt $VERSION_LOWER "2.29.0"
+
+# setup_partial_clone pushes a history of Git LFS files to a new remote
+# repository named "$1", and makes a blob:none partial clone of it, without
+# Git LFS objects, in "$1-partial".
+setup_partial_clone() {
+  local reponame="$1"
+
+  setup_remote_repo "$reponame"
+  git config uploadpack.allowfilter true
+  git config uploadpack.allowanysha1inwant true
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  for i in 1 2 3 4 5; do
+    printf "a$i" > a.dat
+    printf "b$i" > b.dat
+    git add .gitattributes a.dat b.dat
+    git commit -m "version $i"
+  done
+  git push origin main
+
+  cd "$TRASHDIR"
+  GIT_LFS_SKIP_SMUDGE=1 git clone --filter=blob:none "$GITSERVER/$reponame" "$reponame-partial"
+  cd "$reponame-partial"
+  git config credential.helper lfstest
+}
+
+# count_fetches prints the number of times "git fetch" was run, whether by Git
+# LFS or lazily by Git itself, according to the GIT_TRACE2 output in the file
+# "$1".
+count_fetches() {
+  grep -c "cmd_name fetch (" "$1" || true
+}
+
+begin_test "partial clone: ls-files --all prefetches missing blobs at once"
+(
+  set -e
+
+  reponame="partial-clone-ls-files"
+  setup_partial_clone "$reponame"
+
+  GIT_TRACE=1 GIT_TRACE2="$(pwd)/trace2.log" git lfs ls-files --all >ls-files.log 2>trace.log
+  cat ls-files.log
+  [ "10" -eq "$(wc -l < ls-files.log)" ]
+  grep "prefetch: fetching 8 missing object(s) from \"origin\"" trace.log
+  [ "1" -eq "$(count_fetches trace2.log)" ]
+
+  # Everything is now present, so nothing more is fetched.
+  GIT_TRACE=1 GIT_TRACE2="$(pwd)/trace2-again.log" git lfs ls-files --all >/dev/null 2>trace-again.log
+  grep "prefetch: fetching" trace-again.log && exit 1
+  [ "0" -eq "$(count_fetches trace2-again.log)" ]
+)
+end_test
+
+begin_test "partial clone: fetch --all and pull"
+(
+  set -e
+
+  reponame="partial-clone-fetch"
+  setup_partial_clone "$reponame"
+
+  GIT_TRACE2="$(pwd)/trace2.log" git lfs fetch --all
+  [ "1" -eq "$(count_fetches trace2.log)" ]
+  for i in 1 2 3 4 5; do
+    assert_local_object "$(calc_oid "a$i")" 2
+    assert_local_object "$(calc_oid "b$i")" 2
+  done
+
+  GIT_TRACE2="$(pwd)/trace2-pull.log" git lfs pull
+  [ "0" -eq "$(count_fetches trace2-pull.log)" ]
+  [ "a5" = "$(cat a.dat)" ]
+  [ "b5" = "$(cat b.dat)" ]
+  [ -z "$(git status --porcelain --untracked-files=no)" ]
+)
+end_test
+
+begin_test "partial clone: pull of a ref whose blobs are missing"
+(
+  set -e
+
+  reponame="partial-clone-pull"
+  setup_partial_clone "$reponame"
+
+  # Check out an older commit without its blobs, so that pull must scan a
+  # tree whose pointers are missing.
+  GIT_TRACE=1 GIT_TRACE2="$(pwd)/trace2.log" git lfs ls-files HEAD~3 >ls-files.log 2>trace.log
+  grep "prefetch: fetching 2 missing object(s)" trace.log
+  [ "1" -eq "$(count_fetches trace2.log)" ]
+  grep "a.dat" ls-files.log
+  grep "b.dat" ls-files.log
+)
+end_test
+
+begin_test "partial clone: other repositories are not prefetched"
+(
+  set -e
+
+  reponame="partial-clone-none"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  GIT_TRACE=1 git lfs ls-files --all >ls-files.log 2>trace.log
+  grep "prefetch:" trace.log && exit 1
+  grep "missing=print" trace.log && exit 1
+  grep "a.dat" ls-files.log
+)
+end_test
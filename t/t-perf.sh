@@ -0,0 +1,62 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "perf: timings written to a file"
+(
+  set -e
+
+  reponame="perf-file"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  perflog="$(pwd)/perf.log"
+
+  git lfs track "*.dat"
+  printf "perf" > a.dat
+  GIT_LFS_PERF="$perflog" git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  grep "perf: git-lfs filter-process" "$perflog"
+  grep "^hashing  *1 " "$perflog"
+
+  rm "$perflog"
+  GIT_LFS_PERF="$perflog" git push origin main
+
+  cat "$perflog"
+  grep "perf: git-lfs pre-push origin" "$perflog"
+  grep "^phase  *count  *total  *wall$" "$perflog"
+  grep "^ref scan " "$perflog"
+  grep "^batch request " "$perflog"
+  grep "^transfer  *1 " "$perflog"
+)
+end_test
+
+begin_test "perf: timings written to stderr"
+(
+  set -e
+
+  reponame="perf-stderr"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "perf" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "$reponame" "$reponame-checkout"
+  git lfs fetch
+
+  GIT_LFS_PERF=1 git lfs checkout 2> checkout.log
+  cat checkout.log
+  grep "perf: git-lfs checkout" checkout.log
+  grep "^checkout write  *1 " checkout.log
+  [ "perf" = "$(cat a.dat)" ]
+
+  git lfs checkout 2> checkout.log
+  [ "0" -eq "$(grep -c "^phase " checkout.log)" ]
+)
+end_test
@@ -205,3 +205,56 @@ begin_test "post-checkout with subdirectories"
   [ "$(cat file3.big)" == "file 3 updated in branch2" ]
 )
 end_test
+
+begin_test "post-checkout with lfs.verifyaftercheckout"
+(
+  set -e
+
+  reponame="post-checkout-verify"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "add git attributes"
+
+  git checkout -b other
+  contents="a data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  printf "%s" "b data" > b.dat
+  git add a.dat b.dat
+  git commit -m "add files"
+  git checkout main
+  [ ! -e a.dat ]
+
+  git config lfs.verifyaftercheckout true
+
+  # Damage the cached object without changing its length, so that the smudge
+  # filter writes it out as it is.
+  printf "%s" "z data" > ".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+
+  set +e
+  git checkout other 2>checkout.log
+  res=$?
+  set -e
+  cat checkout.log
+  [ "$res" -ne 0 ]
+  grep "a.dat does not match its Git LFS object $contents_oid after checkout" checkout.log
+  grep "b.dat" checkout.log && exit 1
+
+  # Once the object is repaired, checking out the file again succeeds.
+  printf "%s" "$contents" > ".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+  rm a.dat
+  git checkout a.dat 2>checkout.log
+  [ "$contents" = "$(cat a.dat)" ]
+  grep "does not match" checkout.log && exit 1
+
+  # Files which are still pointers are not reported.
+  git checkout main
+  GIT_LFS_SKIP_SMUDGE=1 git checkout other
+  git lfs pointer --check --file a.dat
+
+  exit 0
+)
+end_test
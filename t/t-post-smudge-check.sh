@@ -0,0 +1,117 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# write_checker writes a lfs.postsmudgecheck command which logs its arguments,
+# and rejects files containing "EVIL".
+write_checker () {
+  cat > "$1" <<'EOC'
+#!/bin/sh
+echo "$1 $2" >> "$(dirname "$0")/checker.log"
+if grep -q EVIL "$1"; then
+  echo "found something EVIL"
+  exit 1
+fi
+EOC
+  chmod +x "$1"
+}
+
+begin_test "post-smudge check: git lfs checkout"
+(
+  set -e
+
+  reponame="post-smudge-check-checkout"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "good data" > good.dat
+  printf "%s" "EVIL data" > evil.dat
+  git add .gitattributes good.dat evil.dat
+  git commit -m "add files"
+
+  evil_oid="$(calc_oid "EVIL data")"
+  evil_pointer="$(git cat-file -p :evil.dat)"
+
+  write_checker "$TRASHDIR/checker-checkout"
+  git config lfs.postsmudgecheck "$TRASHDIR/checker-checkout"
+
+  rm good.dat evil.dat
+  git lfs checkout 2>&1 | tee checkout.log
+
+  [ "good data" = "$(cat good.dat)" ]
+  [ "$evil_pointer" = "$(cat evil.dat)" ]
+  [ "EVIL data" = "$(cat ".git/lfs/quarantine/$evil_oid/evil.dat")" ]
+
+  grep "1 file(s) were rejected by lfs.postsmudgecheck" checkout.log
+  grep "evil.dat ($evil_oid): moved to .*quarantine/$evil_oid/evil.dat" checkout.log
+  grep "found something EVIL" checkout.log
+  grep "good.dat $(calc_oid "good data")" "$TRASHDIR/checker.log"
+)
+end_test
+
+begin_test "post-smudge check: smudge filter"
+(
+  set -e
+
+  reponame="post-smudge-check-filter"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "%s" "good data" > good.dat
+  printf "%s" "EVIL data" > evil.dat
+  git add .gitattributes good.dat evil.dat
+  git commit -m "add files"
+
+  evil_oid="$(calc_oid "EVIL data")"
+  evil_pointer="$(git cat-file -p :evil.dat)"
+
+  rm -f "$TRASHDIR/checker.log"
+  write_checker "$TRASHDIR/checker-filter"
+  git config lfs.postsmudgecheck "$TRASHDIR/checker-filter"
+
+  rm good.dat evil.dat
+  git checkout -- . 2>&1 | tee checkout.log
+
+  [ "good data" = "$(cat good.dat)" ]
+  [ "$evil_pointer" = "$(cat evil.dat)" ]
+  [ "EVIL data" = "$(cat ".git/lfs/quarantine/$evil_oid/evil.dat")" ]
+  grep "evil.dat ($evil_oid): moved to" checkout.log
+
+  # The checker sees a temporary file with the same extension.
+  grep "\.dat $evil_oid$" "$TRASHDIR/checker.log"
+)
+end_test
+
+begin_test "post-smudge check: lfs.postsmudgecheckmaxfailures"
+(
+  set -e
+
+  reponame="post-smudge-check-max"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  for i in 1 2 3; do
+    printf "EVIL data %s" "$i" > "evil$i.dat"
+  done
+  git add .gitattributes *.dat
+  git commit -m "add files"
+
+  write_checker "$TRASHDIR/checker-max"
+  git config lfs.postsmudgecheck "$TRASHDIR/checker-max"
+  git config lfs.postsmudgecheckmaxfailures 2
+
+  rm *.dat
+  set +e
+  git lfs checkout 2>checkout.log
+  res=$?
+  set -e
+  cat checkout.log
+  [ "$res" -ne 0 ]
+  grep "Too many files were rejected by lfs.postsmudgecheck: aborting" checkout.log
+  grep "2 file(s) were rejected by lfs.postsmudgecheck" checkout.log
+  [ "2" -eq "$(ls .git/lfs/quarantine | wc -l)" ]
+)
+end_test
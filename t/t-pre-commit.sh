@@ -0,0 +1,155 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "pre-commit: install --size-guard"
+(
+  set -e
+
+  reponame="pre-commit-install"
+  git init "$reponame"
+  cd "$reponame"
+
+  [ ! -f .git/hooks/pre-commit ]
+
+  git lfs install --size-guard
+
+  [ "true" = "$(git config --local lfs.precommitsizecheck)" ]
+  grep "\"\$git_lfs\" pre-commit" .git/hooks/pre-commit
+
+  git lfs uninstall
+  [ ! -f .git/hooks/pre-commit ]
+)
+end_test
+
+begin_test "pre-commit: rejects large untracked files"
+(
+  set -e
+
+  reponame="pre-commit-rejects"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install --size-guard
+  git config lfs.maxcommitsize 1KB
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  base64 /dev/urandom | head -c 2048 > large.bin
+  echo "small" > small.bin
+  git add large.bin small.bin
+
+  git commit -m "add large file" 2>&1 | tee commit.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected commit to fail"
+    exit 1
+  fi
+
+  grep "large.bin" commit.log
+  [ "0" -eq "$(grep -c "small.bin" commit.log)" ]
+  grep "git lfs track \"\*.bin\"" commit.log
+  grep "git commit --no-verify" commit.log
+
+  [ "initial commit" = "$(git log -1 --format=%s)" ]
+
+  git commit --no-verify -m "add large file anyway"
+  [ "add large file anyway" = "$(git log -1 --format=%s)" ]
+)
+end_test
+
+begin_test "pre-commit: allows large tracked files"
+(
+  set -e
+
+  reponame="pre-commit-tracked"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install --size-guard
+  git config lfs.maxcommitsize 1KB
+
+  git lfs track "*.dat"
+  base64 /dev/urandom | head -c 2048 > large.dat
+  git add .gitattributes large.dat
+  git commit -m "add tracked large file"
+
+  [ "add tracked large file" = "$(git log -1 --format=%s)" ]
+  git lfs ls-files | grep "large.dat"
+)
+end_test
+
+begin_test "pre-commit: does nothing when disabled"
+(
+  set -e
+
+  reponame="pre-commit-disabled"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install --size-guard
+  git config lfs.precommitsizecheck false
+  git config lfs.maxcommitsize 1KB
+
+  base64 /dev/urandom | head -c 2048 > large.bin
+  git add large.bin
+  git commit -m "add large file"
+
+  [ "add large file" = "$(git log -1 --format=%s)" ]
+)
+end_test
+
+begin_test "pre-commit: rejects Git LFS files above lfs.maxobjectsize"
+(
+  set -e
+
+  reponame="pre-commit-max-object-size"
+  git init "$reponame"
+  cd "$reponame"
+
+  git config lfs.maxobjectsize 1KB
+  git lfs install
+  grep "\"\$git_lfs\" pre-commit" .git/hooks/pre-commit
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  base64 /dev/urandom | head -c 2048 > large.dat
+  echo "small" > small.dat
+  base64 /dev/urandom | head -c 2048 > large.bin
+  git add large.dat small.dat large.bin
+
+  git commit -m "add large object" 2>&1 | tee commit.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected commit to fail"
+    exit 1
+  fi
+
+  grep "  large.dat (2.0 KB)" commit.log
+  grep "larger than lfs.maxobjectsize (1.0 KB)" commit.log
+  [ "0" -eq "$(grep -c "small.dat" commit.log)" ]
+  # Untracked files are only checked by lfs.precommitsizecheck.
+  [ "0" -eq "$(grep -c "large.bin" commit.log)" ]
+  [ "initial commit" = "$(git log -1 --format=%s)" ]
+
+  git config lfs.maxobjectsize 4KB
+  git commit -m "add large object"
+  [ "add large object" = "$(git log -1 --format=%s)" ]
+
+  git config lfs.maxobjectsize nonsense
+  echo "more" >> small.dat
+  git add small.dat
+  git commit -m "invalid limit" 2>&1 | tee commit.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected commit to fail"
+    exit 1
+  fi
+  grep "Invalid value for lfs.maxobjectsize: \"nonsense\"" commit.log
+
+  git config --unset lfs.maxobjectsize
+  git lfs uninstall
+  [ ! -f .git/hooks/pre-commit ]
+)
+end_test
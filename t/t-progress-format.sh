@@ -0,0 +1,101 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# assert_json_progress checks that every line of the file "$1" is a JSON
+# progress event, as documented in git-lfs-config(5). Lines written by the
+# test credential helper are ignored.
+assert_json_progress() {
+  local file="$1"
+  local event='^\{"event":"(start|progress|complete|skip|error|done)","direction":"(upload|download|checkout)"'
+  local object='(,"oid":"[0-9a-f]{64}")?(,"path":"[^"]*")?(,"size":[0-9]+)?(,"error":"[^"]*")?'
+  local counts=',"bytes_so_far":[0-9]+,"bytes_total":[0-9]+,"objects_done":[0-9]+,"objects_total":[0-9]+\}$'
+
+  [ -s "$file" ]
+  if grep -v "^CREDS " "$file" | grep -Ev "$event$object$counts" >&2; then
+    echo >&2 "fatal: expected only progress events in $file"
+    exit 1
+  fi
+}
+
+begin_test "progress-format json: push, fetch, and checkout"
+(
+  set -e
+
+  reponame="progress-format-json"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "aaaa" > a.dat
+  printf "bb" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add files"
+
+  GIT_TRACE=0 git lfs push --progress-format=json origin main 2>push.log
+  cat push.log
+  assert_json_progress push.log
+  [ "2" -eq "$(grep -c '"event":"complete","direction":"upload"' push.log)" ]
+  grep '"event":"start","direction":"upload","oid":"'"$(calc_oid "aaaa")"'","path":"a.dat","size":4' push.log
+  grep '"event":"done","direction":"upload","bytes_so_far":6,"bytes_total":6,"objects_done":2,"objects_total":2}' push.log
+  [ "0" -eq "$(grep -c "Uploading LFS objects" push.log)" ]
+
+  # Objects which are already on the server are skipped.
+  GIT_TRACE=0 git lfs push --progress-format=json origin main 2>push.log
+  cat push.log
+  assert_json_progress push.log
+  [ "2" -eq "$(grep -c '"event":"skip"' push.log)" ]
+
+  rm -rf .git/lfs/objects
+  rm a.dat b.dat
+  GIT_TRACE=0 GIT_LFS_PROGRESS_FORMAT=json git lfs fetch 2>fetch.log
+  cat fetch.log
+  assert_json_progress fetch.log
+  [ "2" -eq "$(grep -c '"event":"complete","direction":"download"' fetch.log)" ]
+
+  GIT_TRACE=0 git lfs checkout --progress-format=json 2>checkout.log
+  cat checkout.log
+  assert_json_progress checkout.log
+  [ "2" -eq "$(grep -c '"event":"complete","direction":"checkout"' checkout.log)" ]
+  [ "aaaa" = "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "progress-format json: failed download"
+(
+  set -e
+
+  reponame="progress-format-json-error"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "missing" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  oid="$(calc_oid "missing")"
+  delete_server_object "$reponame" "$oid"
+  rm -rf .git/lfs/objects
+
+  git lfs fetch --progress-format=json >fetch.log 2>&1 && exit 1
+  cat fetch.log
+  grep '"event":"error","direction":"download","oid":"'"$oid"'","path":"a.dat","error":"' fetch.log
+  grep "^{" fetch.log > events.log
+  assert_json_progress events.log
+)
+end_test
+
+begin_test "progress-format: invalid"
+(
+  set -e
+
+  reponame="progress-format-invalid"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs fetch --progress-format=xml >fetch.log 2>&1 && exit 1
+  grep 'Invalid progress format "xml": must be text or json' fetch.log
+)
+end_test
@@ -0,0 +1,35 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "proxy (exits with parent)"
+(
+  set -e
+
+  mkdir proxy-parent
+  cd proxy-parent
+
+  # Start the proxy in the background from a shell which exits shortly
+  # afterwards, as a CI script would.
+  bash -c "git lfs proxy --listen=127.0.0.1:0 >proxy.log 2>&1 & sleep 2"
+
+  for i in $(seq 1 10); do
+    grep -q "Parent process exited" proxy.log && break
+    sleep 1
+  done
+
+  cat proxy.log
+  grep "Proxying at http://127.0.0.1:" proxy.log
+  grep "Parent process exited, stopping proxy" proxy.log
+)
+end_test
+
+begin_test "proxy (invalid address)"
+(
+  set -e
+
+  git lfs proxy --listen=not-an-address >proxy.log 2>&1 && exit 1
+  cat proxy.log
+  grep "Could not listen on not-an-address" proxy.log
+)
+end_test
@@ -594,3 +594,97 @@ begin_test "prune verify large numbers of refs"
 
 )
 end_test
+
+begin_test "prune refuses to prune a shared lfs.storage"
+(
+  set -e
+
+  reponame="prune_shared_storage"
+  setup_remote_repo "remote_$reponame"
+
+  storage="$TRASHDIR/$reponame-storage"
+
+  clone_repo "remote_$reponame" "clone_$reponame"
+  git config lfs.storage "$storage"
+
+  git lfs track "*.dat"
+  content="shared content"
+  oid=$(calc_oid "$content")
+  printf "%s" "$content" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  [ -f "$storage/objects/${oid:0:2}/${oid:2:2}/$oid" ]
+  git lfs env | grep "LfsStorageDir=$storage"
+
+  # A second clone finds the object in the shared store.
+  cd ..
+  GIT_LFS_SKIP_SMUDGE=1 clone_repo "remote_$reponame" "other_$reponame"
+  git config lfs.storage "$storage"
+  git config lfs.url "http://127.0.0.1:1/unreachable"
+  git lfs pull
+  [ "$content" = "$(cat a.dat)" ]
+
+  set +e
+  git lfs prune 2>&1 | tee prune.log
+  prune_exit="${PIPESTATUS[0]}"
+  set -e
+  [ "$prune_exit" -ne 0 ]
+  grep "Not pruning $storage, since it may be shared with other repositories" prune.log
+  grep -- "--force-shared" prune.log
+
+  git lfs prune --dry-run 2>&1 | tee prune.log
+  grep "prune: 1 local object(s), 1 retained" prune.log
+
+  git lfs prune --force-shared 2>&1 | tee prune.log
+  grep "prune: 1 local object(s), 1 retained" prune.log
+  [ -f "$storage/objects/${oid:0:2}/${oid:2:2}/$oid" ]
+)
+end_test
+
+begin_test "prune --not-accessed-in"
+(
+  set -e
+
+  reponame="prune_not_accessed_in"
+  setup_remote_repo "remote_$reponame"
+  clone_repo "remote_$reponame" "clone_$reponame"
+
+  git lfs track "*.dat"
+  content_read="Keep: old, but read recently"
+  content_unread="To delete: old and never read"
+  oid_read=$(calc_oid "$content_read")
+  oid_unread=$(calc_oid "$content_unread")
+  printf "%s" "$content_read" > read.dat
+  printf "%s" "$content_unread" > unread.dat
+  git add .gitattributes read.dat unread.dat
+  git commit -m "add objects"
+  git rm read.dat unread.dat
+  git commit -m "remove objects"
+  git push origin main
+
+  git config lfs.fetchrecentrefsdays 0
+  git config lfs.fetchrecentcommitsdays 0
+  git config lfs.pruneoffsetdays 0
+
+  find .git/lfs/objects -type f -exec touch -t 200001010000 {} +
+  git cat-file -p HEAD~1:read.dat | git lfs smudge read.dat > /dev/null
+  grep "^$oid_read [0-9]*$" .git/lfs/access-index
+  grep "$oid_unread" .git/lfs/access-index && exit 1
+
+  git lfs prune --not-accessed-in 30d --verbose 2>&1 | tee prune.log
+  grep "prune: Deleting objects: 100% (1/1), done." prune.log
+  grep "$oid_unread" prune.log
+  assert_local_object "$oid_read" "${#content_read}"
+  refute_local_object "$oid_unread"
+
+  git lfs prune 2>&1 | tee prune.log
+  refute_local_object "$oid_read"
+  grep "$oid_read" .git/lfs/access-index && exit 1
+
+  git lfs prune --not-accessed-in soon 2>&1 | tee prune.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Invalid --not-accessed-in duration" prune.log
+)
+end_test
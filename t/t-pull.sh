@@ -100,6 +100,24 @@ begin_test "pull"
   [ "A" = "$(cat "á.dat")" ]
   assert_clean_status
 
+  echo "lfs pull with lfs.minfreespace"
+  rm a.dat á.dat
+  rm -rf .git/lfs/objects
+  git config lfs.minfreespace 1000PB
+  git lfs pull 2>&1 | tee pull.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected pull to fail without enough free space"
+    exit 1
+  fi
+  grep "Not enough free space" pull.log
+  refute_local_object "$contents_oid"
+  git lfs pull --force
+  [ "a" = "$(cat a.dat)" ]
+  [ "A" = "$(cat "á.dat")" ]
+  assert_local_object "$contents_oid" 1
+  assert_clean_status
+  git config --unset lfs.minfreespace
+
   echo "lfs pull with include/exclude filters in gitconfig"
   rm -rf .git/lfs/objects
   git config "lfs.fetchinclude" "a*"
@@ -273,7 +291,8 @@ begin_test "pull with invalid insteadof"
   res=$?
 
   set -e
-  [ "$res" = "2" ]
+  # The object is not found at the rewritten href.
+  [ "$res" = "5" ]
 
   # check rewritten href is used to download LFS object.
   grep "LFS: Repository or object not found: $GITSERVER/storage/invalid" pull.log
@@ -26,6 +26,29 @@ begin_test "push with good ref"
 )
 end_test
 
+begin_test "push with lfs.debughttp"
+(
+  set -e
+  push_repo_setup "push-debug-http"
+
+  GIT_LFS_DEBUG_HTTP=1 git lfs push origin main 2>&1 | tee push.log
+  grep "> POST .*/objects/batch" push.log
+  grep "> Content-Type: application/vnd.git-lfs+json" push.log
+  grep "< HTTP/1.1 200 OK" push.log
+  [ "0" -eq "$(grep -c '"operation":"upload"' push.log)" ]
+
+  git config lfs.debugfile "$(pwd)/debug.log"
+  git config lfs.debugbodies true
+  git lfs push origin main 2>&1 | tee push.log
+  [ "0" -eq "$(grep -c "> POST" push.log)" ]
+  [ ! -f debug.log ]
+
+  git -c lfs.debughttp=true lfs push origin main
+  grep "> POST .*/objects/batch" debug.log
+  grep '"operation":"upload"' debug.log
+)
+end_test
+
 begin_test "push with tracked ref"
 (
   set -e
@@ -684,7 +707,8 @@ begin_test "push with invalid pushInsteadof"
   res=$?
 
   set -e
-  [ "$res" = "2" ]
+  # The server refuses the upload to the rewritten href.
+  [ "$res" = "3" ]
 
   # check rewritten href is used to upload LFS object.
   grep "LFS: Authorization error: $GITSERVER/storage/invalid" push.log
@@ -820,3 +844,103 @@ begin_test "push custom reference"
   assert_server_object "$reponame" "$oid"
 )
 end_test
+
+begin_test "push --remote overrides global lfs.url"
+(
+  set -e
+
+  reponame="push-remote-flag"
+  setup_remote_repo "$reponame"
+  setup_remote_repo "$reponame-other"
+  clone_repo "$reponame" "$reponame"
+
+  git remote add other "$GITSERVER/$reponame-other"
+  git config --global lfs.url "$GITSERVER/$reponame.git/info/lfs"
+
+  git lfs track "*.dat"
+  contents="push --remote"
+  oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # Without --remote, lfs.url wins over the remote's URL.
+  git lfs push other main 2>&1 | tee push.log
+  assert_server_object "$reponame" "$oid"
+  refute_server_object "$reponame-other" "$oid"
+
+  git lfs push --remote=other main 2>&1 | tee push.log
+  assert_server_object "$reponame-other" "$oid"
+
+  # remote.<name>.lfsurl is preferred over the remote's own URL.
+  contents2="push --remote lfsurl"
+  oid2="$(calc_oid "$contents2")"
+  printf "%s" "$contents2" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  git config remote.other.lfsurl "$GITSERVER/$reponame.git/info/lfs"
+  git lfs push --remote other --object-id "$oid2" 2>&1 | tee push.log
+  assert_server_object "$reponame" "$oid2"
+  refute_server_object "$reponame-other" "$oid2"
+
+  git config --global --unset lfs.url
+)
+end_test
+
+begin_test "push --validate-only"
+(
+  set -e
+
+  reponame="push-validate-only"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  printf "b" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add a.dat, b.dat"
+  git push origin main
+
+  # An object only in history must be on the remote, too.
+  printf "c" > c.dat
+  git add c.dat
+  git commit -m "add c.dat"
+  git rm c.dat
+  git commit -m "remove c.dat"
+
+  set +e
+  git lfs push --validate-only origin main > validate.log 2>&1
+  res=$?
+  set -e
+
+  cat validate.log
+  [ "$res" = "5" ]
+  grep "LFS objects missing on remote:" validate.log
+  grep "  (missing) c.dat ($(calc_oid "c"))" validate.log
+  grep "1 of 3 object(s) missing on remote." validate.log
+  [ "0" -eq "$(grep -c "a.dat\|b.dat" validate.log)" ]
+
+  # Nothing is uploaded.
+  refute_server_object "$reponame" "$(calc_oid "c")"
+
+  git push origin main
+  git lfs push --validate-only origin main 2>&1 | tee validate.log
+  grep "All 3 object(s) present on remote." validate.log
+
+  # Without a ref, the current branch is checked.
+  delete_server_object "$reponame" "$(calc_oid "a")"
+  set +e
+  git lfs push --validate-only origin > validate.log 2>&1
+  res=$?
+  set -e
+
+  cat validate.log
+  [ "$res" = "5" ]
+  grep "  (missing) a.dat ($(calc_oid "a"))" validate.log
+
+  git lfs push --validate-only --object-id origin "$(calc_oid "a")" 2>&1 | tee validate.log
+  grep "Cannot combine --validate-only with --object-id" validate.log
+)
+end_test
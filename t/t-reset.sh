@@ -0,0 +1,218 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "reset: removes objects at the given paths"
+(
+  set -e
+
+  reponame="reset-paths"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="a"
+  contents_a_oid="$(calc_oid "$contents_a")"
+  contents_b="b"
+  contents_b_oid="$(calc_oid "$contents_b")"
+  printf "%s" "$contents_a" > a.dat
+  printf "%s" "$contents_b" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  assert_local_object "$contents_a_oid" 1
+  assert_local_object "$contents_b_oid" 1
+
+  git lfs reset a.dat | tee reset.log
+  grep "Removed 1 object(s) (1 B) from the local cache" reset.log
+
+  refute_local_object "$contents_a_oid"
+  assert_local_object "$contents_b_oid" 1
+  [ "$contents_a" = "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "reset --hard: downloads and checks out objects again"
+(
+  set -e
+
+  reponame="reset-hard"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents="good data"
+  contents_oid="$(calc_oid "$contents")"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  # Corrupt both the cached object and the working copy.
+  printf "%s" "bad data!" > ".git/lfs/objects/${contents_oid:0:2}/${contents_oid:2:2}/$contents_oid"
+  printf "%s" "bad data!" > a.dat
+
+  git lfs reset --hard a.dat
+
+  assert_local_object "$contents_oid" "${#contents}"
+  [ "$contents" = "$(cat a.dat)" ]
+  [ -z "$(git status --porcelain -- a.dat)" ]
+)
+end_test
+
+begin_test "reset --hard: skips modified files"
+(
+  set -e
+
+  reponame="reset-hard-modified"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="a data"
+  contents_a_oid="$(calc_oid "$contents_a")"
+  contents_b="b data"
+  printf "%s" "$contents_a" > a.dat
+  printf "%s" "$contents_b" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  printf "%s" "my edits" > a.dat
+
+  git lfs reset --hard a.dat b.dat 2>&1 | tee reset.log
+  grep "Skipping \"a.dat\", which has been modified" reset.log
+  [ "my edits" = "$(cat a.dat)" ]
+  [ "$contents_b" = "$(cat b.dat)" ]
+  assert_local_object "$contents_a_oid" "${#contents_a}"
+
+  # A file whose object was not removed is not touched.
+  rm ".git/lfs/objects/${contents_a_oid:0:2}/${contents_a_oid:2:2}/$contents_a_oid"
+  git lfs reset --hard a.dat 2>&1 | tee reset.log
+  grep "Removed 0 object(s)" reset.log
+  [ 0 -eq "$(grep -c "Skipping" reset.log)" ]
+  [ "my edits" = "$(cat a.dat)" ]
+
+  git lfs fetch
+  git lfs reset --hard --force a.dat
+  [ "$contents_a" = "$(cat a.dat)" ]
+  [ -z "$(git status --porcelain -- a.dat)" ]
+)
+end_test
+
+begin_test "reset --all"
+(
+  set -e
+
+  reponame="reset-all"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="a"
+  contents_a_oid="$(calc_oid "$contents_a")"
+  contents_b="b"
+  contents_b_oid="$(calc_oid "$contents_b")"
+  printf "%s" "$contents_a" > a.dat
+  printf "%s" "$contents_b" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  git lfs reset --all | tee reset.log
+  grep "Removed 2 object(s) (2 B) from the local cache" reset.log
+
+  refute_local_object "$contents_a_oid"
+  refute_local_object "$contents_b_oid"
+
+  git lfs reset --all --hard
+
+  assert_local_object "$contents_a_oid" 1
+  assert_local_object "$contents_b_oid" 1
+  [ "$contents_a" = "$(cat a.dat)" ]
+)
+end_test
+
+begin_test "reset: keeps objects which the remote does not have"
+(
+  set -e
+
+  reponame="reset-not-on-remote"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  contents_a="a"
+  contents_a_oid="$(calc_oid "$contents_a")"
+  contents_b="unpushed"
+  contents_b_oid="$(calc_oid "$contents_b")"
+  printf "%s" "$contents_a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  printf "%s" "$contents_b" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  git lfs reset --all 2>&1 | tee reset.log
+  grep "Removed 1 object(s) (1 B) from the local cache" reset.log
+  grep "Keeping 1 object(s) which \"origin\" does not have" reset.log
+  refute_local_object "$contents_a_oid"
+  assert_local_object "$contents_b_oid" "${#contents_b}"
+
+  git lfs reset --force b.dat 2>&1 | tee reset.log
+  grep "Removed 1 object(s)" reset.log
+  refute_local_object "$contents_b_oid"
+)
+end_test
+
+begin_test "reset: refuses to run on shared storage"
+(
+  set -e
+
+  reponame="reset-shared-storage"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+  git config lfs.storage "$TRASHDIR/$reponame-storage"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "initial commit"
+  git push origin main
+
+  git lfs reset --all 2>&1 | tee reset.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs reset --all' to fail"
+    exit 1
+  fi
+  grep "Not resetting $TRASHDIR/$reponame-storage, since it may be shared" reset.log
+  assert_local_object "$(calc_oid "a")" 1
+)
+end_test
+
+begin_test "reset: requires paths or --all"
+(
+  set -e
+
+  reponame="reset-usage"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs reset 2>&1 | tee reset.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs reset' to fail"
+    exit 1
+  fi
+  grep "Usage: git lfs reset" reset.log
+
+  git lfs reset --all missing.dat 2>&1 | tee reset.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs reset --all <path>' to fail"
+    exit 1
+  fi
+  grep "Cannot use --all with paths" reset.log
+)
+end_test
@@ -0,0 +1,114 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# setup_shallow_repo pushes three commits, each changing a.dat, to a new
+# remote repository named "$1", and shallow clones it with depth 1 into "$1-shallow".
+setup_shallow_repo() {
+  local reponame="$1"
+
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  for i in 1 2 3; do
+    printf "v$i" > a.dat
+    git add .gitattributes a.dat
+    git commit -m "v$i"
+  done
+  git push origin main
+
+  cd "$TRASHDIR"
+  git clone --depth=1 "$GITSERVER/$reponame" "$reponame-shallow"
+  cd "$reponame-shallow"
+  git config credential.helper lfstest
+}
+
+begin_test "shallow: scanning stops at the shallow boundary"
+(
+  set -e
+
+  reponame="shallow-scan"
+  setup_shallow_repo "$reponame"
+
+  [ "v3" = "$(cat a.dat)" ]
+
+  git lfs ls-files --all 2>&1 | tee ls-files.log
+  [ "1" -eq "$(grep -c "a.dat" ls-files.log)" ]
+
+  git lfs fetch --all 2>&1 | tee fetch.log
+  git lfs fetch --recent 2>&1 | tee fetch-recent.log
+  assert_local_object "$(calc_oid "v3")" 2
+  refute_local_object "$(calc_oid "v2")"
+
+  git lfs prune --verbose 2>&1 | tee prune.log
+  grep "1 local object(s), 1 retained" prune.log
+  assert_local_object "$(calc_oid "v3")" 2
+
+  git lfs migrate info --everything 2>&1 | tee migrate.log
+  grep "1/1 files" migrate.log
+)
+end_test
+
+begin_test "shallow: push of new commits"
+(
+  set -e
+
+  reponame="shallow-push"
+  setup_shallow_repo "$reponame"
+
+  printf "v4" > a.dat
+  git commit -am "v4"
+
+  # The remote's main is the shallow boundary, so the scan stops there.
+  git push origin main 2>&1 | tee push.log
+  grep "Uploading LFS objects: 100% (1/1), 2 B" push.log
+  assert_server_object "$reponame" "$(calc_oid "v4")"
+)
+end_test
+
+begin_test "shallow: push which needs history beyond the shallow boundary"
+(
+  set -e
+
+  reponame="shallow-push-beyond"
+  setup_shallow_repo "$reponame"
+
+  # A new remote has none of the commits, so whether it has the objects of
+  # those beyond the shallow boundary cannot be known.
+  setup_remote_repo "$reponame-other"
+  cd "$TRASHDIR/$reponame-shallow"
+  git remote add other "$GITSERVER/$reponame-other"
+
+  set +e
+  git lfs push other main >push.log 2>&1
+  res=$?
+  set -e
+  cat push.log
+  [ "$res" -ne 0 ]
+  grep "shallow clone: cannot determine objects, fetch more history or use --all" push.log
+  refute_server_object "$reponame-other" "$(calc_oid "v3")"
+
+  git lfs push --all other main 2>&1 | tee push-all.log
+  assert_server_object "$reponame-other" "$(calc_oid "v3")"
+
+  # Deepening the clone is not enough while history is still cut off...
+  git fetch --deepen=1 origin
+  [ -f "$(dot_git_dir)/shallow" ]
+  set +e
+  git lfs push --dry-run other main >push-deepened.log 2>&1
+  res=$?
+  set -e
+  cat push-deepened.log
+  [ "$res" -ne 0 ]
+  grep "shallow clone: cannot determine objects" push-deepened.log
+
+  # ...but once it is complete, the scan succeeds.
+  git fetch --unshallow origin
+  [ ! -f "$(dot_git_dir)/shallow" ]
+  git lfs fetch --all origin
+  git lfs push other main 2>&1 | tee push-unshallow.log
+  assert_server_object "$reponame-other" "$(calc_oid "v1")"
+  assert_server_object "$reponame-other" "$(calc_oid "v2")"
+)
+end_test
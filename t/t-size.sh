@@ -0,0 +1,63 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "size"
+(
+  set -e
+
+  reponame="size"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "aaaa" > a.dat
+  printf "aaaa" > b.dat
+  git add .gitattributes a.dat b.dat
+  git commit -m "add a.dat and b.dat"
+
+  printf "aaaaaaaa" > a.dat
+  git add a.dat
+  git commit -m "change a.dat"
+
+  # The same object at two paths is counted once, and replaced objects are
+  # still in the history.
+  [ "2 objects, 12 bytes (12 B)" = "$(git lfs size)" ]
+  [ "1 objects, 4 bytes (4 B)" = "$(git lfs size HEAD~1)" ]
+  [ '{"objects":2,"size":12}' = "$(git lfs size --json main)" ]
+
+  git checkout -b feature
+  printf "cccccccccccccccc" > c.dat
+  printf "aaaa" > d.dat
+  git add c.dat d.dat
+  git commit -m "add c.dat and d.dat"
+
+  # d.dat has an object which is already in the history of main.
+  [ "1 objects, 16 bytes (16 B)" = "$(git lfs size --range=main..feature)" ]
+  [ "0 objects, 0 bytes (0 B)" = "$(git lfs size --range=feature..main)" ]
+  [ "3 objects, 28 bytes (28 B)" = "$(git lfs size feature)" ]
+)
+end_test
+
+begin_test "size: invalid arguments"
+(
+  set -e
+
+  reponame="size-invalid"
+  git init "$reponame"
+  cd "$reponame"
+  git commit --allow-empty -m "initial commit"
+
+  for args in "missing" "--range=main" "--range=..main" "--range=main...main" "main --range=main..main"; do
+    set +e
+    git lfs size $args 2> size.log
+    res=$?
+    set -e
+    [ "$res" -ne 0 ]
+  done
+
+  git lfs size missing 2>&1 | grep "Invalid ref argument: missing"
+  git lfs size --range=main 2>&1 | grep "Invalid range \"main\": must be <from>..<to>"
+  git lfs size main --range=main..main 2>&1 | grep "Cannot use both a ref and --range"
+)
+end_test
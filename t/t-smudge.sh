@@ -250,3 +250,34 @@ begin_test "smudge no ref, non-origin"
   [ "smudge a" = "$(cat a.dat)" ]
 )
 end_test
+
+begin_test "smudge --to"
+(
+  set -e
+
+  cd repo
+
+  pointer fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254 9 | git lfs smudge --to=out.dat > smudge.log
+  [ "smudge a" = "$(cat out.dat)" ]
+  [ -z "$(cat smudge.log)" ]
+
+  echo "existing" > out.dat
+  set +e
+  pointer fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254 9 | git lfs smudge --to=out.dat 2> smudge.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "\"out.dat\" already exists: use --force to overwrite it" smudge.log
+  [ "existing" = "$(cat out.dat)" ]
+
+  pointer fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254 9 | git lfs smudge --to=out.dat --force
+  [ "smudge a" = "$(cat out.dat)" ]
+
+  set +e
+  pointer fcf5015df7a9089a7aa7fe74139d4b8f7d62e52d5a34f9a87aeffc8e8c668254 9 | git lfs smudge --to=missing/out.dat 2> smudge.log
+  res=$?
+  set -e
+  [ "$res" -ne 0 ]
+  grep "Could not create \"missing/out.dat\"" smudge.log
+)
+end_test
@@ -593,3 +593,51 @@ Objects not staged for commit:"
   [ "$expected" = "$actual" ]
 )
 end_test
+
+begin_test "status --sizes"
+(
+  set -e
+
+  reponame="status-sizes"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  git add .gitattributes
+  git commit -m "initial commit"
+
+  printf "%s" "$(printf 'x%.0s' $(seq 1 2000))" > a.dat
+  printf "%s" "small" > b.dat
+  echo "not lfs" > c.txt
+  git add a.dat b.dat c.txt
+
+  a_pointer="$(git cat-file -s :a.dat)"
+  b_pointer="$(git cat-file -s :b.dat)"
+  total_pointer="$((a_pointer + b_pointer))"
+
+  git lfs status --sizes | tee status.log
+
+  grep "	a.dat ($a_pointer pointer -> 2000 actual)" status.log
+  grep "	b.dat ($b_pointer pointer -> 5 actual)" status.log
+  grep "	c.txt (Git: " status.log
+  grep "Total: $total_pointer pointer -> 2005 actual" status.log
+
+  git lfs status | tee status.log
+  [ "0" -eq "$(grep -c "pointer" status.log)" ]
+
+  git commit -m "add files"
+  git mv a.dat renamed.dat
+
+  git lfs status --sizes | tee status.log
+  grep "	a.dat -> renamed.dat ($a_pointer pointer -> 2000 actual)" status.log
+  grep "Total: $a_pointer pointer -> 2000 actual" status.log
+
+  git lfs status --sizes --porcelain 2>&1 | tee status.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Cannot combine --sizes with --porcelain or --json" status.log
+
+  git lfs status --sizes --json 2>&1 | tee status.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Cannot combine --sizes with --porcelain or --json" status.log
+)
+end_test
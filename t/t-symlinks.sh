@@ -0,0 +1,117 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+# skip_without_symlinks exits the current test if symbolic links cannot be
+# created, as on Windows without the privilege to, where ln -s copies instead.
+skip_without_symlinks () {
+  if [ "$IS_WINDOWS" -eq 1 ]; then
+    export MSYS=winsymlinks:nativestrict
+  fi
+
+  if ! ln -s . symlink-probe 2>/dev/null || [ ! -L symlink-probe ]; then
+    echo "Skipping: symbolic links cannot be created"
+    exit 0
+  fi
+  rm -f symlink-probe
+}
+
+begin_test "symlinks: directory links are not followed"
+(
+  set -e
+
+  reponame="symlinks-directories"
+  git init "$reponame"
+  cd "$reponame"
+  skip_without_symlinks
+
+  mkdir -p dir
+  printf "dat" > dir/a.dat
+  printf '*.bin filter=lfs diff=lfs merge=lfs -text\n' > dir/.gitattributes
+  ln -s / root
+  ln -s . loop
+  ln -s dir linkdir
+
+  git lfs track "*.dat"
+  git add .gitattributes dir root loop linkdir
+  git commit -m "add links"
+
+  # Attribute discovery must neither hang in the loops nor find the
+  # .gitattributes file again through linkdir.
+  git lfs track > track.log
+  cat track.log
+  [ 1 -eq "$(grep -c '\*.bin' track.log)" ]
+  grep '\*.bin (dir/.gitattributes)' track.log
+
+  git lfs status
+  git lfs ls-files | tee ls-files.log
+  [ 1 -eq "$(wc -l < ls-files.log)" ]
+  grep "dir/a.dat" ls-files.log
+)
+end_test
+
+begin_test "symlinks: track does not touch link targets"
+(
+  set -e
+
+  reponame="symlinks-track-touch"
+  git init "$reponame"
+  cd "$reponame"
+  skip_without_symlinks
+
+  printf "outside" > ../outside.dat
+  touch -t 200001010000 ../outside.dat
+  touch -t 200101010000 ../marker
+
+  printf "real" > real.dat
+  ln -s real.dat link.dat
+  ln -s ../outside.dat out.dat
+  git add real.dat link.dat out.dat
+  git commit -m "add files"
+
+  git lfs track --verbose "*.dat" 2>&1 | tee track.log
+  grep "Git LFS: touching \"real.dat\"" track.log
+  [ 0 -eq "$(grep -c "touching \"link.dat\"" track.log)" ]
+  [ 0 -eq "$(grep -c "touching \"out.dat\"" track.log)" ]
+
+  # The link target outside the repository keeps its times.
+  [ -z "$(find ../outside.dat -newer ../marker)" ]
+
+  [ -L link.dat ]
+  [ -L out.dat ]
+)
+end_test
+
+begin_test "symlinks: links are never checked out as Git LFS files"
+(
+  set -e
+
+  reponame="symlinks-checkout"
+  git init "$reponame"
+  cd "$reponame"
+  skip_without_symlinks
+
+  git lfs track "*.dat"
+  contents="contents"
+  printf "%s" "$contents" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # A link whose target looks like a pointer is stored by Git as that
+  # target, and is not a Git LFS file.
+  git cat-file -p :a.dat > pointer.txt
+  hash="$(git hash-object -w pointer.txt)"
+  git update-index --add --cacheinfo 120000 "$hash" pointer-link.dat
+  git commit -m "add pointer-like link"
+  git lfs ls-files | tee ls-files.log
+  [ 0 -eq "$(grep -c "pointer-link.dat" ls-files.log)" ]
+
+  # A link put in place of a Git LFS file is not written through.
+  git cat-file -p :a.dat > ../outside.dat
+  rm a.dat
+  ln -s ../outside.dat a.dat
+  git lfs checkout a.dat
+  [ -L a.dat ]
+  [ "$(git cat-file -p :a.dat)" = "$(cat ../outside.dat)" ]
+)
+end_test
@@ -0,0 +1,69 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "sync pushes unpushed and fetches missing objects"
+(
+  set -e
+
+  reponame="sync-two-way"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs track "*.dat"
+  printf "a" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+  git push origin main
+
+  # Another clone pushes an object which this one doesn't have.
+  cd ..
+  clone_repo "$reponame" "$reponame-other"
+  printf "c" > c.dat
+  git add c.dat
+  git commit -m "add c.dat"
+  git push origin main
+
+  # This clone commits an object which the remote doesn't have.
+  cd "../$reponame"
+  printf "b" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+  git fetch origin
+
+  refute_server_object "$reponame" "$(calc_oid b)"
+  refute_local_object "$(calc_oid c)"
+
+  git lfs sync 2>&1 | tee sync.log
+  grep "sync: 1 object(s) may need to be pushed" sync.log
+  grep "sync: Pushed 1 object(s), fetched 1 object(s)" sync.log
+  grep "sync: 2 local object(s) before, 3 after" sync.log
+
+  assert_server_object "$reponame" "$(calc_oid b)"
+  assert_local_object "$(calc_oid c)" 1
+
+  # The working tree is left alone.
+  [ ! -e c.dat ]
+
+  git lfs sync 2>&1 | tee sync.log
+  grep "sync: Pushed 0 object(s), fetched 0 object(s)" sync.log
+  grep "sync: 3 local object(s) before, 3 after" sync.log
+)
+end_test
+
+begin_test "sync with invalid remote"
+(
+  set -e
+
+  reponame="sync-invalid-remote"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  git lfs sync --remote not-a-remote 2>&1 | tee sync.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs sync' to fail ..."
+    exit 1
+  fi
+  grep "Invalid remote name" sync.log
+)
+end_test
@@ -710,3 +710,259 @@ begin_test "track: escaped glob pattern with spaces in .gitattributes"
   assert_pointer "main" "$filename" "$contents_oid" 15
 )
 end_test
+
+begin_test "track: --attr writes extra attributes"
+(
+  set -e
+
+  reponame="track-extra-attrs"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track --attr eol=lf --attr merge-driver=ours "*.bin" | grep "Tracking \"\*.bin\""
+  grep -x "\*.bin filter=lfs diff=lfs merge=lfs -text eol=lf merge-driver=ours" .gitattributes
+
+  # The extra attributes do not affect whether the pattern is tracked.
+  git lfs track | grep "\*.bin (.gitattributes)"
+
+  # Tracking again replaces the line rather than adding another.
+  git lfs track --attr eol=crlf "*.bin"
+  grep -x "\*.bin filter=lfs diff=lfs merge=lfs -text eol=crlf" .gitattributes
+  [ "1" -eq "$(grep -c "\*.bin" .gitattributes)" ]
+
+  printf "%s" "data" > a.bin
+  git add .gitattributes a.bin
+  git lfs ls-files | grep "a.bin"
+
+  git lfs track --attr merge=ours "*.dat" 2>&1 | tee track.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs track --attr merge=ours' to fail"
+    exit 1
+  fi
+  grep "Cannot set attribute \"merge\"" track.log
+
+  git lfs track --attr eol "*.dat" 2>&1 | tee track.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "fatal: expected 'git lfs track --attr eol' to fail"
+    exit 1
+  fi
+  grep "Invalid attribute \"eol\"" track.log
+  [ "0" -eq "$(grep -c "\*.dat" .gitattributes)" ]
+)
+end_test
+
+begin_test "track in subdirectory writes to its .gitattributes"
+(
+  set -e
+
+  git init track-subdirectory
+  cd track-subdirectory
+
+  mkdir -p src/assets/icons
+  cd src/assets
+  git lfs track "*.psd" | tee track.log
+  grep "Tracking \"\*.psd\"" track.log
+
+  [ "*.psd filter=lfs diff=lfs merge=lfs -text" = "$(cat .gitattributes)" ]
+  [ ! -f ../../.gitattributes ]
+
+  printf "icon" > icons/a.psd
+  git add icons/a.psd
+  git check-attr filter -- icons/a.psd | grep "filter: lfs"
+
+  out="$(git lfs track "*.psd")"
+  [ "\"*.psd\" already supported" = "$out" ]
+)
+end_test
+
+begin_test "track --root"
+(
+  set -e
+
+  git init track-root
+  cd track-root
+
+  mkdir -p src/assets/icons
+  cd src/assets
+  git lfs track --root "*.psd" "/top.bin" "icons/*.png" | tee track.log
+  grep "Tracking \"src/assets/\*\*/\*.psd\"" track.log
+
+  [ ! -f .gitattributes ]
+  expected="src/assets/**/*.psd filter=lfs diff=lfs merge=lfs -text
+src/assets/top.bin filter=lfs diff=lfs merge=lfs -text
+src/assets/icons/*.png filter=lfs diff=lfs merge=lfs -text"
+  [ "$(printf "%s\n" "$expected" | sort)" = "$(sort ../../.gitattributes)" ]
+
+  printf "icon" > icons/a.psd
+  printf "top" > top.bin
+  printf "png" > icons/b.png
+  git add icons/a.psd top.bin icons/b.png
+  git check-attr filter -- icons/a.psd top.bin icons/b.png | tee attr.log
+  [ "3" -eq "$(grep -c "filter: lfs" attr.log)" ]
+
+  out="$(git lfs track --root "*.psd")"
+  [ "\"src/assets/**/*.psd\" already supported" = "$out" ]
+
+  # Files already in Git are touched, relative to the current directory.
+  printf "dat" > c.dat
+  git add c.dat
+  git lfs track --root --dry-run "*.dat" | tee track.log
+  grep "Git LFS: touching \"c.dat\"" track.log
+)
+end_test
+
+begin_test "track with .gitattributes directory"
+(
+  set -e
+
+  reponame="track-gitattributes-directory"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir .gitattributes
+  set +e
+  git lfs track "*.dat" >track.log 2>&1
+  res=$?
+  set -e
+
+  cat track.log
+  [ "$res" -ne 0 ]
+  grep "Error: .gitattributes is a directory; remove or rename it and try again." track.log
+  [ "0" -eq "$(grep -c "Tracking" track.log)" ]
+  [ -d .gitattributes ]
+
+  # The file written by --root is checked too.
+  mkdir dir
+  cd dir
+  set +e
+  git lfs track --root "*.bin" >track.log 2>&1
+  res=$?
+  set -e
+
+  cat track.log
+  [ "$res" -ne 0 ]
+  grep "Error: .gitattributes is a directory; remove or rename it and try again." track.log
+)
+end_test
+
+begin_test "track with unwritable .gitattributes"
+(
+  set -e
+
+  reponame="track-unwritable-gitattributes"
+  git init "$reponame"
+  cd "$reponame"
+
+  # Root can write to the file regardless of its permissions.
+  [ "$(id -u)" -eq 0 ] && exit 0
+
+  echo "*.bin filter=lfs diff=lfs merge=lfs -text" > .gitattributes
+  chmod 444 .gitattributes
+  set +e
+  git lfs track "*.dat" >track.log 2>&1
+  res=$?
+  set -e
+
+  cat track.log
+  [ "$res" -ne 0 ]
+  grep "Error: cannot write to .gitattributes: permission denied. Check file permissions." track.log
+  [ "*.bin filter=lfs diff=lfs merge=lfs -text" = "$(cat .gitattributes)" ]
+)
+end_test
+
+begin_test "track with brace expansion"
+(
+  set -e
+
+  reponame="track-brace-expansion"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.{jpg,jpeg,png}" | tee track.log
+  grep "Tracking \"\*.jpg\"" track.log
+  grep "Tracking \"\*.jpeg\"" track.log
+  grep "Tracking \"\*.png\"" track.log
+
+  [ 3 -eq "$(wc -l < .gitattributes)" ]
+  grep -x "\*.jpg filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  grep -x "\*.jpeg filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  grep -x "\*.png filter=lfs diff=lfs merge=lfs -text" .gitattributes
+
+  # Nested braces expand too, and patterns already tracked are skipped.
+  git lfs track "*.{png,tif{,f}}" | tee track.log
+  grep "\"\*.png\" already supported" track.log
+  grep -x "\*.tif filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  grep -x "\*.tiff filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  [ 5 -eq "$(wc -l < .gitattributes)" ]
+
+  printf "jpeg" > a.jpeg
+  printf "tiff" > b.tiff
+  git add a.jpeg b.tiff
+  git commit -m "add images"
+  assert_pointer "main" "a.jpeg" "$(calc_oid "jpeg")" 4
+  assert_pointer "main" "b.tiff" "$(calc_oid "tiff")" 4
+
+  # Braces without a comma, and those given with --filename, are literal.
+  git lfs track "{a}.bin"
+  grep -x "{a}.bin filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  git lfs track --filename "{x,y}.dat"
+  grep -x "{x,y}.dat filter=lfs diff=lfs merge=lfs -text" .gitattributes
+  [ 7 -eq "$(wc -l < .gitattributes)" ]
+)
+end_test
+
+begin_test "track with backslash separators"
+(
+  set -e
+
+  reponame="track-backslash-separators"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p assets/models/characters
+  printf "hero" > assets/models/characters/hero.fbx
+  printf "tree" > assets/models/tree.fbx
+  git add assets
+  git commit -m "add models before tracking"
+
+  git lfs track 'assets\models\**\*.fbx' | tee track.log
+  grep "Tracking \"assets/models/\*\*/\*.fbx\"" track.log
+  [ "assets/models/**/*.fbx filter=lfs diff=lfs merge=lfs -text" = "$(cat .gitattributes)" ]
+
+  # Existing files matched by the pattern are found by git ls-files, and
+  # touched so that they are cleaned when added again.
+  git lfs track --dry-run --no-modify-attrs 'assets\models\*.fbx' | tee track.log
+  grep "Git LFS: touching \"assets/models/tree.fbx\"" track.log
+
+  # The pattern matches whichever separator it is given with again.
+  git lfs track 'assets\models\**\*.fbx' | tee track.log
+  grep "\"assets/models/\*\*/\*.fbx\" already supported" track.log
+  git lfs track "assets/models/**/*.fbx" | tee track.log
+  grep "\"assets/models/\*\*/\*.fbx\" already supported" track.log
+  [ 1 -eq "$(wc -l < .gitattributes)" ]
+
+  git add .gitattributes
+  git add --renormalize assets
+  git commit -m "track models"
+  assert_pointer "main" "assets/models/characters/hero.fbx" "$(calc_oid "hero")" 4
+
+  # From a subdirectory, the pattern is compared with the directory joined
+  # to it, with forward slashes.
+  mkdir -p textures/stone
+  cd textures
+  git lfs track 'stone\*.png' | tee track.log
+  grep "Tracking \"stone/\*.png\"" track.log
+  [ "stone/*.png filter=lfs diff=lfs merge=lfs -text" = "$(cat .gitattributes)" ]
+  git lfs track 'stone\*.png' | tee track.log
+  grep "\"stone/\*.png\" already supported" track.log
+
+  printf "moss" > stone/moss.png
+  git add .gitattributes stone/moss.png
+  git commit -m "track textures"
+  assert_pointer "main" "stone/moss.png" "$(calc_oid "moss")" 4
+
+  git lfs untrack 'stone\*.png' | tee untrack.log
+  grep "Untracking \"stone/\*.png\"" untrack.log
+  [ ! -s .gitattributes ]
+)
+end_test
@@ -114,3 +114,54 @@ begin_test "honors core.sharedrepository for directories"
   git config --global --unset-all core.sharedRepository
 )
 end_test
+
+begin_test "core.sharedrepository sets the setgid bit on directories"
+(
+  set -e
+  reponame="shared-repo-setgid"
+  git init "$reponame"
+  cd "$reponame"
+
+  umask 027
+  git config core.sharedRepository group
+  echo "whatever" | git lfs clean
+  assert_dir_perms "drwxrws---"
+
+  git lfs install --local --force
+  [ "$(ls -l .git/hooks/pre-push | awk '{print substr($1, 1, 10)}')" = "-rwxrwx---" ]
+)
+end_test
+
+begin_test "fsck --fix repairs permissions for core.sharedrepository"
+(
+  set -e
+  reponame="shared-repo-fsck"
+  git init "$reponame"
+  cd "$reponame"
+
+  umask 077
+  git lfs track "*.dat"
+  echo "whatever" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  oid="cd293be6cea034bd45a0352775a219ef5dc7825ce55d1f7dae9762d80ce64411"
+  [ "$(perms_for "$oid")" = "-rw-------" ]
+
+  git config core.sharedRepository group
+
+  git lfs fsck >fsck.log 2>&1 && exit 1
+  grep "core.sharedRepository requires" fsck.log
+
+  git lfs fsck --fix --dry-run 2>&1 | tee fsck.log
+  grep "Would change the permissions of" fsck.log
+  [ "$(perms_for "$oid")" = "-rw-------" ]
+
+  git lfs fsck --fix 2>&1 | tee fsck.log
+  grep "Fixed the permissions of" fsck.log
+  [ "$(perms_for "$oid")" = "-rw-rw----" ]
+  assert_dir_perms "drwxrws---"
+
+  git lfs fsck
+)
+end_test
@@ -263,3 +263,86 @@ begin_test "uninstall --local"
   [ "" = "$(git config --local filter.lfs.process)" ]
 )
 end_test
+
+begin_test "uninstall restores chained hooks"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-chained-hook"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p .git/hooks
+  printf '#!/bin/sh\necho policy\n' > .git/hooks/pre-push
+  chmod +x .git/hooks/pre-push
+  git lfs install
+
+  [ -x .git/hooks/pre-push.git-lfs-chained ]
+  grep '"$git_lfs" pre-push' .git/hooks/pre-push
+
+  git lfs uninstall
+
+  [ ! -e .git/hooks/pre-push.git-lfs-chained ]
+  [ -x .git/hooks/pre-push ]
+  [ "$(printf '#!/bin/sh\necho policy')" = "$(cat .git/hooks/pre-push)" ]
+  [ ! -e .git/hooks/post-checkout ]
+)
+end_test
+
+begin_test "uninstall reports what it changed"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-report"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p .git/hooks
+  echo "custom" > .git/hooks/post-merge
+  git lfs install --local
+  echo "not lfs" > .git/hooks/post-commit
+
+  git lfs uninstall --local 2>&1 | tee uninstall.log
+  grep "Removed filter.lfs.clean, filter.lfs.process, filter.lfs.required, filter.lfs.smudge from the local Git config." uninstall.log
+  grep "Removed pre-push hook." uninstall.log
+  grep "Removed post-checkout hook." uninstall.log
+  grep "Kept post-commit hook, which was not installed by Git LFS." uninstall.log
+  grep "Removed post-merge hook, and restored the hook it ran." uninstall.log
+  grep "WARNING: files stored as Git LFS pointers will no longer be replaced" uninstall.log
+
+  [ "custom" = "$(cat .git/hooks/post-merge)" ]
+  [ "not lfs" = "$(cat .git/hooks/post-commit)" ]
+  [ -z "$(git config --local filter.lfs.smudge)" ]
+  [ -n "$(git config --global filter.lfs.smudge)" ]
+
+  # Nothing left to remove.
+  git lfs uninstall --local 2>&1 | tee uninstall.log
+  if grep -q "Removed\|pointers will no longer" uninstall.log; then
+    exit 1
+  fi
+)
+end_test
+
+begin_test "uninstall --global"
+(
+  set -e
+
+  reponame="$(basename "$0" ".sh")-global"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs install
+
+  git lfs uninstall --global --skip-repo 2>&1 | tee uninstall.log
+  grep "from the global Git config." uninstall.log
+  grep "Global Git LFS configuration has been removed." uninstall.log
+  [ -z "$(git config --global filter.lfs.smudge)" ]
+  grep '"$git_lfs" pre-push' .git/hooks/pre-push
+
+  git lfs uninstall --global --local 2>&1 | tee uninstall.log
+  [ "2" -eq "${PIPESTATUS[0]}" ]
+  grep "Only one of --global and --local, --worktree or --system options can be specified." uninstall.log
+
+  git lfs install
+)
+end_test
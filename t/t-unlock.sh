@@ -383,3 +383,38 @@ begin_test "unlocking a lock while untracked"
   refute_server_lock "$reponame" "$id"
 )
 end_test
+
+begin_test "unlocking a lock by oid after a rename"
+(
+  set -e
+
+  reponame="unlock_by_oid"
+  setup_repo "$reponame" "a.dat"
+
+  git lfs lock --json "a.dat" | tee lock.log
+  id=$(assert_lock lock.log a.dat)
+  assert_server_lock "$reponame" "$id"
+
+  oid="$(git cat-file -p HEAD:a.dat | grep "^oid" | cut -d " " -f 2)"
+
+  git mv a.dat b.dat
+  git commit -m "rename a.dat to b.dat"
+  rm *.log *.json # ensure clean git status
+
+  git lfs unlock --oid "$(calc_oid nothing)" 2>&1 | tee unlock.log
+  if [ "0" -eq "${PIPESTATUS[0]}" ]; then
+    echo >&2 "expected \`git lfs unlock --oid\` with an unlocked object to fail, didn't"
+    exit 1
+  fi
+  grep "no lock is held on a file with object" unlock.log
+  assert_server_lock "$reponame" "$id"
+
+  git lfs unlock --oid "$oid" a.dat 2>&1 | tee unlock.log
+  grep "Usage:" unlock.log
+  assert_server_lock "$reponame" "$id"
+
+  git lfs unlock --oid "$oid" 2>&1 | tee unlock.log
+  grep "Unlocked a.dat" unlock.log
+  refute_server_lock "$reponame" "$id"
+)
+end_test
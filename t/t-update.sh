@@ -6,21 +6,10 @@ begin_test "update"
 (
   set -e
 
-  pre_push_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/pre-push.\\n\"; exit 2; }
-git lfs pre-push \"\$@\""
-
-  post_checkout_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-checkout.\\n\"; exit 2; }
-git lfs post-checkout \"\$@\""
-
-  post_commit_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-commit.\\n\"; exit 2; }
-git lfs post-commit \"\$@\""
-
-  post_merge_hook="#!/bin/sh
-command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-merge.\\n\"; exit 2; }
-git lfs post-merge \"\$@\""
+  pre_push_hook="$(lfs_hook pre-push)"
+  post_checkout_hook="$(lfs_hook post-checkout)"
+  post_commit_hook="$(lfs_hook post-commit)"
+  post_merge_hook="$(lfs_hook post-merge)"
 
   mkdir without-pre-push
   cd without-pre-push
@@ -82,7 +71,25 @@ git lfs pre-push \"$@\""
   [ "Updated git hooks." = "$(git lfs update)" ]
   [ "$pre_push_hook" = "$(cat .git/hooks/pre-push)" ]
 
-  # don't replace unexpected hook
+  # chain unexpected hook
+  echo "test" > .git/hooks/pre-push
+  echo "test" > .git/hooks/post-checkout
+  echo "test" > .git/hooks/post-commit
+  echo "test" > .git/hooks/post-merge
+  [ "Updated git hooks." = "$(git lfs update)" ]
+  for hook in pre-push post-checkout post-commit post-merge; do
+    [ "test" = "$(cat ".git/hooks/$hook.git-lfs-chained")" ]
+    grep "$hook.git-lfs-chained" ".git/hooks/$hook"
+    grep "\"\$git_lfs\" $hook" ".git/hooks/$hook"
+  done
+  chained_pre_push_hook="$(cat .git/hooks/pre-push)"
+
+  # run it again
+  [ "Updated git hooks." = "$(git lfs update)" ]
+  [ "$chained_pre_push_hook" = "$(cat .git/hooks/pre-push)" ]
+  [ "test" = "$(cat .git/hooks/pre-push.git-lfs-chained)" ]
+
+  # don't replace unexpected hook when a hook is already chained
   echo "test" > .git/hooks/pre-push
   echo "test" > .git/hooks/post-checkout
   echo "test" > .git/hooks/post-commit
@@ -113,27 +120,19 @@ To resolve this, either:
   # test manual steps
   expected="Add the following to .git/hooks/pre-push:
 
-	#!/bin/sh
-	command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/pre-push.\n\"; exit 2; }
-	git lfs pre-push \"\$@\"
+$(lfs_hook pre-push | sed -e 's/^/	/')
 
 Add the following to .git/hooks/post-checkout:
 
-	#!/bin/sh
-	command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-checkout.\n\"; exit 2; }
-	git lfs post-checkout \"\$@\"
+$(lfs_hook post-checkout | sed -e 's/^/	/')
 
 Add the following to .git/hooks/post-commit:
 
-	#!/bin/sh
-	command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-commit.\n\"; exit 2; }
-	git lfs post-commit \"\$@\"
+$(lfs_hook post-commit | sed -e 's/^/	/')
 
 Add the following to .git/hooks/post-merge:
 
-	#!/bin/sh
-	command -v git-lfs >/dev/null 2>&1 || { echo >&2 \"\nThis repository is configured for Git LFS but 'git-lfs' was not found on your path. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/post-merge.\n\"; exit 2; }
-	git lfs post-merge \"\$@\""
+$(lfs_hook post-merge | sed -e 's/^/	/')"
 
   [ "$expected" = "$(git lfs update --manual 2>&1)" ]
   [ "test" = "$(cat .git/hooks/pre-push)" ]
@@ -238,3 +237,135 @@ begin_test "update: outside git repository"
   grep "Not in a git repository" check.log
 )
 end_test
+
+begin_test "update chains existing hooks"
+(
+  set -e
+
+  reponame="update-chains-hooks"
+  setup_remote_repo "$reponame"
+  clone_repo "$reponame" "$reponame"
+
+  mkdir -p .git/hooks
+  cat > .git/hooks/pre-push <<'HOOK'
+#!/bin/sh
+echo "policy hook: $1 $2" >> policy.log
+cat >> policy.log
+exit 0
+HOOK
+  chmod +x .git/hooks/pre-push
+
+  [ "Updated git hooks." = "$(git lfs update)" ]
+  [ -x .git/hooks/pre-push.git-lfs-chained ]
+
+  git lfs track "*.dat"
+  printf "chained" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git push origin main 2>&1 | tee push.log
+  grep "policy hook: origin" policy.log
+  grep "refs/heads/main $(git rev-parse HEAD) refs/heads/main" policy.log
+  assert_server_object "$reponame" "$(calc_oid "chained")"
+
+  # A failing chained hook fails the push, but LFS objects are still pushed.
+  cat > .git/hooks/pre-push.git-lfs-chained <<'HOOK'
+#!/bin/sh
+echo "rejected by policy" >&2
+exit 3
+HOOK
+  printf "rejected" > b.dat
+  git add b.dat
+  git commit -m "add b.dat"
+
+  git push origin main 2>&1 | tee push.log
+  [ "0" -ne "${PIPESTATUS[0]}" ]
+  grep "rejected by policy" push.log
+  assert_server_object "$reponame" "$(calc_oid "rejected")"
+  [ "$(git rev-parse HEAD^)" = "$(git rev-parse origin/main)" ]
+
+  # A failing LFS hook fails too, even if the chained hook succeeds.
+  printf '#!/bin/sh\nexit 0\n' > .git/hooks/pre-push.git-lfs-chained
+  git push origin main 2>&1 | tee push.log
+  [ "$(git rev-parse HEAD)" = "$(git rev-parse origin/main)" ]
+)
+end_test
+
+begin_test "update upgrades historical hooks of every type"
+(
+  set -e
+
+  reponame="update-historical-hooks"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p .git/hooks
+  for hook in post-checkout post-commit post-merge; do
+    printf '#!/bin/sh\r\ncommand -v git-lfs >/dev/null 2>&1 || { echo >&2 "\\nThis repository has been set up with Git LFS but Git LFS is not installed.\\n"; exit 2; }\r\ngit lfs %s "$@"\r\n' "$hook" > ".git/hooks/$hook"
+  done
+
+  [ "Updated git hooks." = "$(git lfs update)" ]
+  for hook in post-checkout post-commit post-merge; do
+    [ ! -e ".git/hooks/$hook.git-lfs-chained" ]
+    grep "remove this hook by deleting .git/hooks/$hook" ".git/hooks/$hook"
+  done
+)
+end_test
+
+begin_test "other commands silently upgrade historical hooks"
+(
+  set -e
+
+  reponame="update-implicit-upgrade"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p .git/hooks
+  printf '#!/bin/sh\ngit lfs pre-push "$@"\n' > .git/hooks/pre-push
+  printf '#!/bin/sh\necho custom\n' > .git/hooks/post-checkout
+  chmod +x .git/hooks/pre-push .git/hooks/post-checkout
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  [ "0" -eq "$(grep -c "WARNING" track.log)" ]
+
+  # The historical hook is upgraded, but a hook not written by Git LFS is
+  # left alone, rather than chained as `git lfs update` would.
+  grep '"$git_lfs" pre-push' .git/hooks/pre-push
+  grep "remove this hook by deleting .git/hooks/pre-push" .git/hooks/pre-push
+  [ "$(printf '#!/bin/sh\necho custom')" = "$(cat .git/hooks/post-checkout)" ]
+  [ ! -e .git/hooks/post-checkout.git-lfs-chained ]
+)
+end_test
+
+begin_test "other commands warn daily about hooks they cannot upgrade"
+(
+  set -e
+
+  # Root can write to the hooks directory regardless of its permissions.
+  [ "$(id -u)" -eq 0 ] && exit 0
+
+  reponame="update-implicit-upgrade-warning"
+  git init "$reponame"
+  cd "$reponame"
+
+  mkdir -p .git/hooks
+  printf '#!/bin/sh\ngit lfs pre-push "$@"\n' > .git/hooks/pre-push
+  chmod 555 .git/hooks/pre-push
+  chmod 555 .git/hooks
+
+  git lfs track "*.dat" 2>&1 | tee track.log
+  grep "WARNING: the pre-push hook(s) in .* were written by an older version of Git LFS" track.log
+  grep "Run \`git lfs update\` to upgrade them." track.log
+  [ -f .git/lfs/outdated-hooks-warning ]
+
+  git lfs track "*.bin" 2>&1 | tee track.log
+  [ "0" -eq "$(grep -c "WARNING" track.log)" ]
+
+  # Once a day has passed, the warning is given again.
+  touch -t 200001010000 .git/lfs/outdated-hooks-warning
+  git lfs track "*.iso" 2>&1 | tee track.log
+  grep "WARNING: the pre-push hook(s)" track.log
+
+  chmod 755 .git/hooks
+)
+end_test
@@ -0,0 +1,78 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+begin_test "verbosity: --quiet"
+(
+  set -e
+
+  reponame="verbosity-quiet"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track --quiet "*.dat" > track.log
+  [ ! -s track.log ]
+  grep "*.dat filter=lfs diff=lfs merge=lfs" .gitattributes
+
+  printf "quiet" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  # Data the command was asked for is still printed.
+  git lfs ls-files -q > ls-files.log
+  grep "a.dat" ls-files.log
+
+  git lfs untrack -q "*.dat" > untrack.log
+  [ ! -s untrack.log ]
+)
+end_test
+
+begin_test "verbosity: --verbose"
+(
+  set -e
+
+  reponame="verbosity-verbose"
+  git init "$reponame"
+  cd "$reponame"
+
+  printf "verbose" > a.dat
+  git lfs track -v "*.dat" > track.log
+  grep "Tracking \"\*.dat\"" track.log
+  grep "Searching for files matching pattern: \*.dat" track.log
+
+  git lfs count-objects --verbose > count.log
+  grep "incomplete:" count.log
+
+  git lfs track -vv "*.bin" > track-debug.log
+  grep "Tracking \"\*.bin\"" track-debug.log
+)
+end_test
+
+begin_test "verbosity: --quiet with --verbose"
+(
+  set -e
+
+  reponame="verbosity-quiet-verbose"
+  git init "$reponame"
+  cd "$reponame"
+
+  set +e
+  git lfs track -q -v "*.dat" > track.log 2>&1
+  res=$?
+  set -e
+
+  [ "$res" = "2" ]
+  grep "Cannot combine --quiet with --verbose" track.log
+  [ ! -f .gitattributes ]
+)
+end_test
+
+begin_test "verbosity: git lfs -v prints the version"
+(
+  set -e
+
+  git lfs -v > version.log
+  grep "git-lfs/" version.log
+  [ "$(wc -l < version.log | tr -d ' ')" = "1" ]
+)
+end_test
@@ -21,3 +21,64 @@ begin_test "git lfs --version is a synonym of git lfs version"
   fi
 )
 end_test
+
+begin_test "git lfs version --json"
+(
+  set -e
+
+  git lfs version --json > version.json
+  cat version.json
+
+  version="$(git lfs version | sed -e 's|^git-lfs/\([^ ]*\) .*|\1|')"
+  grep "\"version\":\"$version\"" version.json
+  grep "\"user_agent\":\"$(git lfs version)\"" version.json
+  grep "\"os\":\"" version.json
+  grep "\"arch\":\"" version.json
+  grep "\"go_version\":\"" version.json
+  grep "\"git_commit\":" version.json
+
+  git lfs version -v > verbose.log
+  grep "^version: $version$" verbose.log
+  grep "^os/arch: " verbose.log
+)
+end_test
+
+begin_test "lfs.minclientversion"
+(
+  set -e
+
+  reponame="min-client-version"
+  git init "$reponame"
+  cd "$reponame"
+
+  version="$(git lfs version | sed -e 's|^git-lfs/\([^ ]*\) .*|\1|')"
+
+  # Older versions, and pre-releases of this one, are fine.
+  git config -f .lfsconfig lfs.minclientversion "$version-rc.1"
+  git lfs track "*.dat" > track.log 2>&1
+  grep "requires Git LFS" track.log && exit 1
+
+  git config -f .lfsconfig lfs.minclientversion "999.0.0"
+  git lfs track "*.bin" > track.log 2>&1
+  grep "warning: this repository requires Git LFS 999.0.0 or newer, but this is Git LFS $version. Please upgrade." track.log
+  grep "*.bin" .gitattributes
+
+  # The version can always be checked.
+  git lfs version > version.log 2>&1
+  grep "requires Git LFS" version.log && exit 1
+
+  git config -f .lfsconfig lfs.enforceminclientversion true
+  set +e
+  git lfs track "*.iso" > track.log 2>&1
+  res=$?
+  set -e
+  cat track.log
+  [ "$res" = "2" ]
+  grep "error: this repository requires Git LFS 999.0.0 or newer" track.log
+  grep "*.iso" .gitattributes && exit 1
+
+  git config -f .lfsconfig lfs.minclientversion "not-a-version"
+  git lfs track "*.iso" > track.log 2>&1
+  grep "warning: ignoring lfs.minclientversion: invalid version \"not-a-version\"" track.log
+)
+end_test
@@ -0,0 +1,129 @@
+#!/usr/bin/env bash
+
+. "$(dirname "$0")/testlib.sh"
+
+ensure_git_version_isnt $VERSION_LOWER "2.17.0"
+
+begin_test "worktree-prune"
+(
+  set -e
+
+  reponame="worktree-prune"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "main" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git worktree add --detach "../$reponame-wt-kept"
+  git worktree add --detach "../$reponame-wt-removed"
+
+  (cd "../$reponame-wt-kept" &&
+    printf "kept" > b.dat &&
+    git add b.dat &&
+    git commit -m "add b.dat")
+  (cd "../$reponame-wt-removed" &&
+    printf "removed" > c.dat &&
+    git add c.dat &&
+    git commit -m "add c.dat")
+
+  # staged, but not committed
+  printf "staged" > d.dat
+  git add d.dat
+
+  git lfs worktree-prune --dry-run 2>&1 | tee prune.log
+  grep "worktree-prune: 0 object(s) would be pruned" prune.log
+
+  git worktree remove "../$reponame-wt-removed"
+
+  git lfs worktree-prune --dry-run 2>&1 | tee prune.log
+  grep "worktree-prune: 1 object(s) would be pruned (7 B)" prune.log
+  grep " \* $(calc_oid "removed")" prune.log
+  assert_local_object "$(calc_oid "removed")" 7
+
+  git lfs worktree-prune 2>&1 | tee prune.log
+  grep "worktree-prune: pruned 1 object(s) (7 B)" prune.log
+  refute_local_object "$(calc_oid "removed")"
+  assert_local_object "$(calc_oid "main")" 4
+  assert_local_object "$(calc_oid "kept")" 4
+  assert_local_object "$(calc_oid "staged")" 6
+)
+end_test
+
+begin_test "worktree-prune keeps objects staged in other working trees"
+(
+  set -e
+
+  reponame="worktree-prune-staged"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "main" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git worktree add --detach "../$reponame-wt"
+  (cd "../$reponame-wt" &&
+    printf "staged in wt" > b.dat &&
+    git add b.dat)
+
+  printf "staged in main" > c.dat
+  git add c.dat
+
+  git lfs worktree-prune 2>&1 | tee prune.log
+  [ 0 -eq "$(grep -c "pruned" prune.log)" ]
+  assert_local_object "$(calc_oid "staged in wt")" 12
+  assert_local_object "$(calc_oid "staged in main")" 14
+
+  # The index of the main working tree is kept from a linked one too.
+  cd "../$reponame-wt"
+  git lfs worktree-prune --dry-run 2>&1 | tee prune.log
+  grep "worktree-prune: 0 object(s) would be pruned" prune.log
+
+  # Once the working tree is gone, what was only staged there is not.
+  cd "../$reponame"
+  git worktree remove --force "../$reponame-wt"
+  git lfs worktree-prune 2>&1 | tee prune.log
+  grep "worktree-prune: pruned 1 object(s) (12 B)" prune.log
+  refute_local_object "$(calc_oid "staged in wt")"
+  assert_local_object "$(calc_oid "staged in main")" 14
+)
+end_test
+
+begin_test "worktree-prune keeps objects on branches"
+(
+  set -e
+
+  reponame="worktree-prune-branches"
+  git init "$reponame"
+  cd "$reponame"
+
+  git lfs track "*.dat"
+  printf "main" > a.dat
+  git add .gitattributes a.dat
+  git commit -m "add a.dat"
+
+  git worktree add -b topic "../$reponame-wt"
+  (cd "../$reponame-wt" &&
+    printf "old topic" > b.dat &&
+    git add b.dat &&
+    git commit -m "add b.dat" &&
+    printf "new topic" > b.dat &&
+    git commit -am "update b.dat")
+  git worktree remove "../$reponame-wt"
+
+  git lfs worktree-prune 2>&1 | tee prune.log
+  assert_local_object "$(calc_oid "old topic")" 9
+  assert_local_object "$(calc_oid "new topic")" 9
+
+  git branch -D topic
+  git lfs worktree-prune 2>&1 | tee prune.log
+  grep "worktree-prune: pruned 2 object(s)" prune.log
+  refute_local_object "$(calc_oid "old topic")"
+  refute_local_object "$(calc_oid "new topic")"
+  assert_local_object "$(calc_oid "main")" 4
+)
+end_test
@@ -3,9 +3,13 @@
 . "$(dirname "$0")/testlib.sh"
 
 ensure_git_version_isnt $VERSION_LOWER "2.5.0"
-envInitConfig='git config filter.lfs.process = "git-lfs filter-process"
-git config filter.lfs.smudge = "git-lfs smudge -- %f"
-git config filter.lfs.clean = "git-lfs clean -- %f"'
+# Git 2.26.0 and later can report the scope the filter settings come from.
+envInitScope=" (global)"
+compare_version "$(git version | cut -d" " -f3)" "2.26.0" ||
+  [ $? -ne $VERSION_LOWER ] || envInitScope=""
+envInitConfig="git config filter.lfs.process = \"git-lfs filter-process\"$envInitScope
+git config filter.lfs.smudge = \"git-lfs smudge -- %f\"$envInitScope
+git config filter.lfs.clean = \"git-lfs clean -- %f\"$envInitScope"
 
 unset_vars () {
     # If set, these will cause the test to fail.
@@ -268,6 +268,30 @@ assert_hooks() {
   [ -x "$git_root/hooks/pre-push" ]
 }
 
+# lfs_hook prints the contents of the hook of the given type installed by Git
+# LFS.
+#
+#   $ lfs_hook pre-push
+lfs_hook() {
+  sed -e "s/{{Command}}/$1/g" <<'HOOK'
+#!/bin/sh
+tried=
+for source in GIT_LFS_PATH exec-path PATH lfs.binarypath; do
+case $source in
+GIT_LFS_PATH) git_lfs="$GIT_LFS_PATH" ;;
+exec-path) git_lfs="$(git --exec-path)/git-lfs" ;;
+PATH) git_lfs="$(command -v git-lfs)" ;;
+lfs.binarypath) git_lfs="$(git config lfs.binarypath)" ;;
+esac
+[ -n "$git_lfs" ] && { [ -x "$git_lfs" ] || [ -x "$git_lfs.exe" ]; } && break
+tried="$tried  $source: ${git_lfs:-(none)}\n"
+git_lfs=
+done
+[ -n "$git_lfs" ] || { printf >&2 '\nThis repository is configured for Git LFS but git-lfs was not found. Tried:\n%b\nSet GIT_LFS_PATH or lfs.binarypath to the path of git-lfs. If you no longer wish to use Git LFS, remove this hook by deleting .git/hooks/{{Command}}.\n\n' "$tried"; exit 2; }
+"$git_lfs" {{Command}} "$@"
+HOOK
+}
+
 assert_clean_status() {
   status="$(git status)"
   echo "$status" | grep "working tree clean" || {
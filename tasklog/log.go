@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"os"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	isatty "github.com/mattn/go-isatty"
 	"github.com/olekukonko/ts"
@@ -15,6 +15,23 @@ import (
 
 const (
 	DefaultLoggingThrottle = 200 * time.Millisecond
+
+	// DefaultPlainLoggingThrottle is the minimum amount of time between
+	// each progress update of a task logged as a plain line, when progress
+	// is not displayed on a single updating line.
+	DefaultPlainLoggingThrottle = 10 * time.Second
+)
+
+var (
+	// outputMu serializes the progress lines written by every Logger with
+	// the messages written with Write, so that they never interleave.
+	outputMu sync.Mutex
+	// current is the Logger whose progress line is displayed on a
+	// terminal, and which must be cleared before writing anything else, or
+	// nil.
+	current *Logger
+	// currentLine is the progress line displayed by "current".
+	currentLine string
 )
 
 // Logger logs a series of tasks to an io.Writer, processing each task in order
@@ -33,10 +50,18 @@ type Logger struct {
 	// forceProgress forces progress status even when stdout is not a tty
 	forceProgress bool
 
+	// plainProgress logs progress as plain lines, at most once every
+	// plainThrottle, even when stdout is a tty.
+	plainProgress bool
+
 	// throttle is the minimum amount of time that must pass between each
 	// instant data is logged.
 	throttle time.Duration
 
+	// plainThrottle is the minimum amount of time that must pass between
+	// each progress update logged as a plain line.
+	plainThrottle time.Duration
+
 	// queue is the incoming, unbuffered queue of tasks to enqueue.
 	queue chan Task
 	// tasks is the set of tasks to process.
@@ -57,6 +82,15 @@ func ForceProgress(v bool) Option {
 	}
 }
 
+// PlainProgress returns an options function that configures the logger to log
+// progress as periodic plain lines, rather than a single updating line, even
+// when stdout is a terminal.
+func PlainProgress(v bool) Option {
+	return func(l *Logger) {
+		l.plainProgress = v
+	}
+}
+
 // NewLogger returns a new *Logger instance that logs to "sink" and uses the
 // current terminal width as the width of the line. Will log progress status if
 // stdout is a terminal or if forceProgress is true
@@ -66,8 +100,9 @@ func NewLogger(sink io.Writer, options ...Option) *Logger {
 	}
 
 	l := &Logger{
-		sink:     sink,
-		throttle: DefaultLoggingThrottle,
+		sink:          sink,
+		throttle:      DefaultLoggingThrottle,
+		plainThrottle: DefaultPlainLoggingThrottle,
 		widthFn: func() int {
 			size, err := ts.GetSize()
 			if err != nil {
@@ -131,6 +166,15 @@ func (l *Logger) Percentage(msg string, total uint64) *PercentageTask {
 	return t
 }
 
+// PercentageRate creates and enqueues a new *PercentageTask which shows the
+// rate at which elements named by "unit" are completed.
+func (l *Logger) PercentageRate(msg, unit string, total uint64) *PercentageTask {
+	t := NewPercentageRateTask(msg, unit, total)
+	l.Enqueue(t)
+
+	return t
+}
+
 // List creates and enqueues a new *ListTask.
 func (l *Logger) List(msg string) *ListTask {
 	t := NewListTask(msg)
@@ -244,15 +288,30 @@ func (l *Logger) consume() {
 // If the duration if 0, or the task is "durable" (by implementing
 // github.com/git-lfs/git-lfs/tasklog#DurableTask), then all entries will be
 // logged.
+//
+// When the sink is not a terminal and progress is not forced, or progress is
+// configured to be plain, the updates of throttled tasks are instead logged
+// on lines of their own, at most once per `l.plainThrottle`.
 func (l *Logger) logTask(task Task) {
 	defer l.wg.Done()
 
 	logAll := !task.Throttled()
+	quiet := !l.tty && !l.forceProgress
+	plain := !logAll && (quiet || l.plainProgress)
+
 	var last time.Time
+	lastPlain := time.Now()
 
 	var update *Update
 	for update = range task.Updates() {
-		if !tty(os.Stdout) && !l.forceProgress {
+		if plain {
+			if update.At.Sub(lastPlain) >= l.plainThrottle {
+				l.log(update.S + "\n")
+				lastPlain = update.At
+			}
+			continue
+		}
+		if quiet {
 			continue
 		}
 		if logAll || l.throttle == 0 || !update.Throttled(last.Add(l.throttle)) {
@@ -280,14 +339,26 @@ func (l *Logger) logTask(task Task) {
 }
 
 // logLine writes a complete line and moves the cursor to the beginning of the
-// line.
+// line. The line is truncated to fit within the width of the terminal, which
+// is checked on each call, so that it is not wrapped if the terminal shrinks.
 //
 // It returns the number of bytes "n" written to the sink and the error "err",
 // if one was encountered.
 func (l *Logger) logLine(str string) (n int, err error) {
-	padding := strings.Repeat(" ", maxInt(0, l.widthFn()-len(str)))
+	width := l.widthFn()
+	if width > 0 && utf8.RuneCountInString(str) >= width {
+		str = string([]rune(str)[:width-1])
+	}
+	padding := strings.Repeat(" ", maxInt(0, width-utf8.RuneCountInString(str)))
+	line := str + padding + "\r"
 
-	return l.log(str + padding + "\r")
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	if l.tty {
+		current, currentLine = l, line
+	}
+	return fmt.Fprint(l.sink, line)
 }
 
 // log writes a string verbatim to the sink.
@@ -295,9 +366,36 @@ func (l *Logger) logLine(str string) (n int, err error) {
 // It returns the number of bytes "n" written to the sink and the error "err",
 // if one was encountered.
 func (l *Logger) log(str string) (n int, err error) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	if current == l {
+		current, currentLine = nil, ""
+	}
 	return fmt.Fprint(l.sink, str)
 }
 
+// Write writes "p" to "w" without interleaving it with the progress line of
+// any Logger, which is cleared first and, if "p" ends a line, redrawn below
+// it. It is used to write messages while tasks are being logged.
+func Write(w io.Writer, p []byte) (n int, err error) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	if current == nil {
+		return w.Write(p)
+	}
+
+	blank := strings.Repeat(" ", utf8.RuneCountInString(currentLine)-1)
+	fmt.Fprint(current.sink, blank+"\r")
+
+	n, err = w.Write(p)
+	if len(p) > 0 && p[len(p)-1] == '\n' {
+		fmt.Fprint(current.sink, currentLine)
+	}
+	return n, err
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
@@ -207,3 +207,59 @@ func TestLoggerHandlesSilentTasks(t *testing.T) {
 
 	assert.Equal(t, "", buf.String())
 }
+
+func TestLoggerLogsPlainProgressPeriodically(t *testing.T) {
+	var buf bytes.Buffer
+
+	start := time.Now()
+	task := make(chan *Update)
+	go func() {
+		task <- &Update{"first", start, false}
+		task <- &Update{"second", start.Add(3 * time.Second), false}
+		task <- &Update{"third", start.Add(4 * time.Second), false}
+		task <- &Update{"fourth", start.Add(6 * time.Second), false}
+		close(task)
+	}()
+
+	l := NewLogger(&buf, ForceProgress(true), PlainProgress(true))
+	l.plainThrottle = 2 * time.Second
+	l.widthFn = func() int { return 0 }
+	l.Enqueue(ChanTask(task))
+	l.Close()
+
+	assert.Equal(t, "second\nfourth\nfourth, done.\n", buf.String())
+}
+
+func TestLoggerTruncatesLinesToWidth(t *testing.T) {
+	var buf bytes.Buffer
+
+	task := make(chan *Update)
+	go func() {
+		task <- &Update{"0123456789", time.Now(), false}
+		close(task)
+	}()
+
+	l := NewLogger(&buf, ForceProgress(true))
+	l.throttle = 0
+	l.widthFn = func() int { return 6 }
+	l.Enqueue(ChanTask(task))
+	l.Close()
+
+	assert.Equal(t, "01234 \r0123456789, done.\n", buf.String())
+}
+
+func TestWriteClearsAndRedrawsProgressLine(t *testing.T) {
+	var buf, msg bytes.Buffer
+
+	l := NewLogger(&buf)
+	l.tty = true
+	l.widthFn = func() int { return 0 }
+
+	l.logLine("progress")
+	Write(&msg, []byte("message\n"))
+	l.log("progress, done.\n")
+	Write(&msg, []byte("after\n"))
+
+	assert.Equal(t, "progress\r        \rprogress\rprogress, done.\n", buf.String())
+	assert.Equal(t, "message\nafter\n", msg.String())
+}
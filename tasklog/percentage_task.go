@@ -20,15 +20,28 @@ type PercentageTask struct {
 	total uint64
 	// msg is the task message.
 	msg string
+	// unit, if given, is the name of the elements counted by this task,
+	// and causes the rate at which they are completed to be shown.
+	unit string
+	// start is the time at which the task was created.
+	start time.Time
 	// ch is a channel which is written to when the task state changes and
 	// is closed when the task is completed.
 	ch chan *Update
 }
 
 func NewPercentageTask(msg string, total uint64) *PercentageTask {
+	return NewPercentageRateTask(msg, "", total)
+}
+
+// NewPercentageRateTask returns a *PercentageTask which also shows the number
+// of elements, named by "unit", completed per second.
+func NewPercentageRateTask(msg, unit string, total uint64) *PercentageTask {
 	p := &PercentageTask{
 		msg:   msg,
 		total: total,
+		unit:  unit,
+		start: time.Now(),
 		ch:    make(chan *Update, 1),
 	}
 	p.Count(0)
@@ -54,10 +67,18 @@ func (c *PercentageTask) Count(n uint64) (new uint64) {
 		percentage = 100 * float64(new) / float64(c.total)
 	}
 
+	now := time.Now()
+	s := fmt.Sprintf("%s: %3.f%% (%d/%d)",
+		c.msg, math.Floor(percentage), new, c.total)
+	if len(c.unit) > 0 && new > 0 {
+		if elapsed := now.Sub(c.start).Seconds(); elapsed > 0 {
+			s = fmt.Sprintf("%s, %.f %s/s", s, float64(new)/elapsed, c.unit)
+		}
+	}
+
 	c.ch <- &Update{
-		S: fmt.Sprintf("%s: %3.f%% (%d/%d)",
-			c.msg, math.Floor(percentage), new, c.total),
-		At: time.Now(),
+		S:  s,
+		At: now,
 	}
 
 	if new >= c.total {
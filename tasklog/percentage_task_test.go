@@ -2,6 +2,7 @@ package tasklog
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,6 +18,17 @@ func TestPercentageTaskCalculuatesPercentages(t *testing.T) {
 	assert.Equal(t, "example:  30% (3/10)", (<-task.Updates()).S)
 }
 
+func TestPercentageRateTaskShowsRate(t *testing.T) {
+	task := NewPercentageRateTask("example", "commits", 10)
+	task.start = time.Now().Add(-2 * time.Second)
+
+	assert.Equal(t, "example:   0% (0/10)", (<-task.Updates()).S)
+
+	task.Count(4)
+
+	assert.Regexp(t, `^example:  40% \(4/10\), [0-9]+ commits/s$`, (<-task.Updates()).S)
+}
+
 func TestPercentageTaskCalculatesPercentWithoutTotal(t *testing.T) {
 	task := NewPercentageTask("example", 0)
 
@@ -80,12 +80,66 @@ func RenameFileCopyPermissions(srcfile, destfile string) error {
 		}
 	}
 
-	if err := RobustRename(srcfile, destfile); err != nil {
+	if err := RenameAcrossDevices(srcfile, destfile); err != nil {
 		return fmt.Errorf("cannot replace %q with %q: %v", destfile, srcfile, err)
 	}
 	return nil
 }
 
+// RenameAcrossDevices renames oldpath to newpath as RobustRename does. If they
+// are on different filesystems, so that oldpath cannot simply be renamed, it
+// is copied to a temporary file beside newpath, synced to disk and renamed
+// over newpath, so that newpath is still replaced atomically, and is then
+// removed.
+func RenameAcrossDevices(oldpath, newpath string) error {
+	err := RobustRename(oldpath, newpath)
+	if err == nil || !isCrossDeviceError(err) {
+		return err
+	}
+
+	if err := copyAcrossDevices(oldpath, newpath); err != nil {
+		return err
+	}
+	return os.Remove(oldpath)
+}
+
+// copyAcrossDevices copies oldpath to newpath, with its permissions, by way of
+// a temporary file in the same directory as newpath.
+func copyAcrossDevices(oldpath, newpath string) error {
+	src, err := os.Open(oldpath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(newpath), "."+filepath.Base(newpath)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+		return err
+	}
+	return RobustRename(tmp.Name(), newpath)
+}
+
 // CleanPaths splits the given `paths` argument by the delimiter argument, and
 // then "cleans" that path according to the path.Clean function (see
 // https://golang.org/pkg/path#Clean).
@@ -119,15 +173,60 @@ func CleanPaths(paths, delim string) (cleaned []string) {
 // object and can be used to fetch repository permissions.
 type repositoryPermissionFetcher interface {
 	RepositoryPermissions(executable bool) os.FileMode
+	RepositoryShared() bool
 }
 
 // MkdirAll makes a directory and any intervening directories with the
-// permissions specified by the core.sharedRepository setting.
+// permissions specified by the core.sharedRepository setting. If it shares
+// the repository, the directories it creates are given the setgid bit, as Git
+// gives them.
 func MkdirAll(path string, config repositoryPermissionFetcher) error {
-	umask := 0777 & ^config.RepositoryPermissions(true)
-	return doWithUmask(int(umask), func() error {
-		return os.MkdirAll(path, config.RepositoryPermissions(true))
+	var created []string
+	if config.RepositoryShared() {
+		created = missingDirs(path)
+	}
+
+	perms := config.RepositoryPermissions(true)
+	umask := 0777 & ^perms
+	err := doWithUmask(int(umask), func() error {
+		return os.MkdirAll(path, perms)
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range created {
+		if err := setSharedDirPermissions(dir, perms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sharedPermissions returns the permissions which a file or directory in the
+// repository should have, given its current permissions "mode".
+func sharedPermissions(mode os.FileMode, config repositoryPermissionFetcher) os.FileMode {
+	perms := config.RepositoryPermissions(mode.IsDir() || mode&0100 != 0)
+	if mode.IsDir() && config.RepositoryShared() {
+		perms |= os.ModeSetgid
+	}
+	return perms
+}
+
+// missingDirs returns "path" and those of its parents which do not exist, from
+// the outermost in.
+func missingDirs(path string) []string {
+	var missing []string
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || !os.IsNotExist(err) {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return missing
 }
 
 var (
@@ -307,6 +406,7 @@ func fastWalkWithExcludeFiles(rootDir string) *fastWalker {
 		dirFi, err := os.Stat(w.rootDir)
 		if err != nil {
 			w.ch <- fastWalkInfo{Err: err}
+			close(w.ch)
 			return
 		}
 
@@ -418,6 +518,48 @@ func join(paths ...string) string {
 	return strings.Join(ne, "/")
 }
 
+// BreakHardLink replaces the file at "path" with a copy of itself if it has
+// other hard links, so that writing to it leaves the other links unchanged.
+// The copy has the same permissions as the original.
+func BreakHardLink(path string) error {
+	n, err := HardLinkCount(path)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return nil
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), stat.Mode().Perm()); err != nil {
+		return err
+	}
+	return RobustRename(tmp.Name(), path)
+}
+
 // SetFileWriteFlag changes write permissions on a file
 // Used to make a file read-only or not. When writeEnabled = false, the write
 // bit is removed for all roles. When writeEnabled = true, the behaviour is
@@ -455,7 +597,7 @@ func SetFileWriteFlag(path string, writeEnabled bool) error {
 // This function is designed to handle only temporary files that will be renamed
 // into place later somewhere within the Git repository.
 func TempFile(dir, pattern string, cfg repositoryPermissionFetcher) (*os.File, error) {
-	tmp, err := ioutil.TempFile(dir, pattern)
+	tmp, err := ioutil.TempFile(LongPath(dir), pattern)
 	if err != nil {
 		return nil, err
 	}
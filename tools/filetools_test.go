@@ -207,6 +207,22 @@ func TestFastWalkBasic(t *testing.T) {
 	assert.Equal(t, expectedEntries, gotEntries)
 }
 
+func TestFastWalkMissingDir(t *testing.T) {
+	rootDir, err := ioutil.TempDir(os.TempDir(), "GitLfsTestFastWalkMissingDir")
+	if err != nil {
+		assert.FailNow(t, "Unable to get temp dir: %v", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	walker := fastWalkWithExcludeFiles(filepath.Join(rootDir, "missing"))
+	gotEntries, gotErrors := collectFastWalkResults(walker.ch)
+
+	assert.Empty(t, gotEntries)
+	if assert.Len(t, gotErrors, 1) {
+		assert.True(t, os.IsNotExist(gotErrors[0]))
+	}
+}
+
 // Make test data - ensure you've Chdir'ed into a temp dir first
 // Returns list of files/dirs that are created
 // First entry is the parent dir of all others
@@ -283,6 +299,38 @@ func uniq(xs []string) []string {
 	return uniq
 }
 
+func TestBreakHardLink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lfstestbreakhardlink")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	original := filepath.Join(dir, "original")
+	link := filepath.Join(dir, "link")
+	assert.Nil(t, ioutil.WriteFile(original, []byte("contents"), 0444))
+	assert.Nil(t, os.Link(original, link))
+
+	n, err := HardLinkCount(link)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 2, n)
+
+	assert.Nil(t, BreakHardLink(link))
+
+	n, err = HardLinkCount(link)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, n)
+	n, err = HardLinkCount(original)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, n)
+
+	contents, err := ioutil.ReadFile(link)
+	assert.Nil(t, err)
+	assert.Equal(t, "contents", string(contents))
+	assert.EqualValues(t, 0444, getFileMode(link))
+
+	// A file without other links is left alone.
+	assert.Nil(t, BreakHardLink(link))
+}
+
 func TestSetWriteFlag(t *testing.T) {
 	f, err := ioutil.TempFile("", "lfstestwriteflag")
 	assert.Nil(t, err)
@@ -333,3 +381,32 @@ func TestExecutablePermissions(t *testing.T) {
 	assert.EqualValues(t, os.FileMode(0750), ExecutablePermissions(0640))
 	assert.EqualValues(t, os.FileMode(0700), ExecutablePermissions(0600))
 }
+
+func TestCopyAcrossDevicesReplacesTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lfstestcopyacrossdevices")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "dst")
+	assert.Nil(t, ioutil.WriteFile(src, []byte("new"), 0640))
+	assert.Nil(t, ioutil.WriteFile(dst, []byte("old"), 0644))
+
+	assert.Nil(t, copyAcrossDevices(src, dst))
+
+	contents, err := ioutil.ReadFile(dst)
+	assert.Nil(t, err)
+	assert.Equal(t, "new", string(contents))
+	assert.EqualValues(t, 0640, getFileMode(dst))
+
+	// The source is left for the caller to remove, and no temporary files
+	// are left behind.
+	entries, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestIsCrossDeviceError(t *testing.T) {
+	assert.False(t, isCrossDeviceError(nil))
+	assert.False(t, isCrossDeviceError(&os.LinkError{Op: "rename", Err: os.ErrNotExist}))
+}
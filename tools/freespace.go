@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/git-lfs/git-lfs/errors"
+)
+
+// IsNoSpaceError returns whether "err" was caused by the volume being written
+// to being full.
+func IsNoSpaceError(err error) bool {
+	switch cause := errors.Cause(err).(type) {
+	case *os.PathError:
+		err = cause.Err
+	case *os.LinkError:
+		err = cause.Err
+	case *os.SyscallError:
+		err = cause.Err
+	default:
+		err = cause
+	}
+
+	errno, ok := err.(syscall.Errno)
+	if !ok {
+		return false
+	}
+	for _, e := range noSpaceErrnos {
+		if errno == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+// +build !linux,!darwin,!freebsd,!windows
+
+package tools
+
+import "github.com/git-lfs/git-lfs/errors"
+
+// FreeSpace returns the number of bytes available to the current user on the
+// volume containing "path". It is not supported on this platform, and always
+// returns an error.
+func FreeSpace(path string) (uint64, error) {
+	return 0, errors.New("unsupported platform")
+}
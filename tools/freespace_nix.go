@@ -0,0 +1,15 @@
+// +build linux darwin freebsd
+
+package tools
+
+import "golang.org/x/sys/unix"
+
+// FreeSpace returns the number of bytes available to the current user on the
+// volume containing "path".
+func FreeSpace(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreeSpace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freespace")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	free, err := FreeSpace(dir)
+	if err != nil {
+		t.Skipf("free space not supported: %s", err)
+	}
+	assert.True(t, free > 0)
+}
+
+func TestIsNoSpaceError(t *testing.T) {
+	err := &os.PathError{Op: "write", Path: "x", Err: noSpaceErrnos[0]}
+
+	assert.True(t, IsNoSpaceError(err))
+	assert.True(t, IsNoSpaceError(errors.Wrap(err, "cannot write data")))
+	assert.False(t, IsNoSpaceError(&os.PathError{Op: "write", Path: "x", Err: os.ErrPermission}))
+	assert.False(t, IsNoSpaceError(errors.New("no space")))
+	assert.False(t, IsNoSpaceError(nil))
+}
@@ -0,0 +1,20 @@
+// +build !windows
+
+package tools
+
+import (
+	"os"
+	"syscall"
+)
+
+// HardLinkCount returns the number of hard links to the file at "path".
+func HardLinkCount(path string) (uint64, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if sys, ok := stat.Sys().(*syscall.Stat_t); ok {
+		return uint64(sys.Nlink), nil
+	}
+	return 1, nil
+}
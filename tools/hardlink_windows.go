@@ -0,0 +1,23 @@
+// +build windows
+
+package tools
+
+import (
+	"os"
+	"syscall"
+)
+
+// HardLinkCount returns the number of hard links to the file at "path".
+func HardLinkCount(path string) (uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var info syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(f.Fd()), &info); err != nil {
+		return 0, err
+	}
+	return uint64(info.NumberOfLinks), nil
+}
@@ -103,7 +103,7 @@ func FormatBytes(s uint64) string {
 	if s == 0 {
 		e = 0
 	} else {
-		e = math.Floor(log(float64(s), 1000))
+		e = math.Min(math.Floor(log(float64(s), 1000)), float64(len(sizes)-1))
 	}
 
 	unit := uint64(math.Pow(1000, e))
@@ -156,6 +156,8 @@ func TestFormatBytes(t *testing.T) {
 		"format gigabytes exact": {uint64(1.3 * math.Pow(10, 9)), "1.3 GB"},
 		"format petabytes exact": {uint64(1.3 * math.Pow(10, 12)), "1.3 TB"},
 		"format terabytes exact": {uint64(1.3 * math.Pow(10, 15)), "1.3 PB"},
+
+		"format beyond petabytes": {uint64(2 * math.Pow(10, 18)), "2000 PB"},
 	} {
 		t.Run(desc, c.Assert)
 	}
@@ -27,6 +27,13 @@ func CopyWithCallback(writer io.Writer, reader io.Reader, totalSize int64, cb Co
 		}
 		return totalSize, nil
 	}
+	return StreamWithCallback(writer, reader, totalSize, cb)
+}
+
+// StreamWithCallback copies reader to writer while performing a progress
+// callback, as CopyWithCallback does, but always copies the contents, rather
+// than cloning reader into writer when both are files.
+func StreamWithCallback(writer io.Writer, reader io.Reader, totalSize int64, cb CopyCallback) (int64, error) {
 	if cb == nil {
 		return io.Copy(writer, reader)
 	}
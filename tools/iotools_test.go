@@ -74,3 +74,19 @@ type ErrReader struct {
 func (e *ErrReader) Read(p []byte) (n int, err error) {
 	return 0, e.err
 }
+
+func TestStreamWithCallbackCopiesAndReportsProgress(t *testing.T) {
+	var called int64
+	var buf bytes.Buffer
+
+	n, err := tools.StreamWithCallback(&buf, bytes.NewBufferString("streamed"), 8, func(total, read int64, current int) error {
+		assert.EqualValues(t, 8, total)
+		called = read
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 8, n)
+	assert.EqualValues(t, 8, called)
+	assert.Equal(t, "streamed", buf.String())
+}
@@ -0,0 +1,9 @@
+// +build !windows
+
+package tools
+
+// LongPath returns "path" as it is, since only Windows limits the length of
+// paths to less than the filesystem does.
+func LongPath(path string) string {
+	return path
+}
@@ -0,0 +1,36 @@
+// +build windows
+
+package tools
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath is the length beyond which a path must be in the
+// extended-length form. Directories are limited to 248 characters, rather than
+// MAX_PATH's 260, to leave room for an 8.3 filename.
+const maxShortPath = 248
+
+// LongPath returns "path" in the extended-length form, with the "\\?\" prefix,
+// if it is too long for the Windows APIs to accept otherwise. Since that form
+// turns off the normalization Windows would do, the path is made absolute and
+// cleaned first, with backslashes as separators, and a UNC path becomes
+// "\\?\UNC\server\share\...". Go extends long absolute paths itself, but not
+// relative ones. Short paths, and paths which are already extended or are
+// device paths, are returned as they are.
+func LongPath(path string) string {
+	if len(path) == 0 || strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) < maxShortPath {
+		return path
+	}
+
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
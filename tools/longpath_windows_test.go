@@ -0,0 +1,58 @@
+// +build windows
+
+package tools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongPathLeavesShortPaths(t *testing.T) {
+	assert.Equal(t, "", LongPath(""))
+	assert.Equal(t, `a\b.dat`, LongPath(`a\b.dat`))
+	assert.Equal(t, `C:\a\b.dat`, LongPath(`C:\a\b.dat`))
+	assert.Equal(t, `\\?\C:\a\b.dat`, LongPath(`\\?\C:\a\b.dat`))
+}
+
+func TestLongPathExtendsLongPaths(t *testing.T) {
+	long := strings.Repeat("x", 300)
+
+	assert.Equal(t, `\\?\C:\a\`+long+`\b.dat`, LongPath(`C:/a/./`+long+`/c/../b.dat`))
+	assert.Equal(t, `\\?\UNC\server\share\`+long, LongPath(`\\server\share\`+long))
+
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	assert.Equal(t, `\\?\`+filepath.Join(wd, long), LongPath(long))
+}
+
+func TestLongPathWritesBeyondMaxPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "longpath")
+	require.Nil(t, err)
+	defer os.RemoveAll(LongPath(dir))
+
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+	require.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	// A relative path, which Go does not extend itself.
+	var parts []string
+	for i := 0; i < 6; i++ {
+		parts = append(parts, strings.Repeat(string(rune('a'+i)), 50))
+	}
+	path := filepath.Join(append(parts, "file.dat")...)
+	require.True(t, len(path) > 260)
+
+	require.Nil(t, os.MkdirAll(LongPath(filepath.Dir(path)), 0755))
+	require.Nil(t, ioutil.WriteFile(LongPath(path), []byte("data"), 0644))
+
+	data, err := ioutil.ReadFile(LongPath(path))
+	require.Nil(t, err)
+	assert.Equal(t, "data", string(data))
+}
@@ -0,0 +1,8 @@
+// +build !windows
+
+package tools
+
+import "syscall"
+
+// noSpaceErrnos are the errors returned when writing to a full volume.
+var noSpaceErrnos = []syscall.Errno{syscall.ENOSPC, syscall.EDQUOT}
@@ -0,0 +1,16 @@
+// +build windows
+
+package tools
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// noSpaceErrnos are the errors returned when writing to a full volume.
+var noSpaceErrnos = []syscall.Errno{
+	windows.ERROR_DISK_FULL,
+	windows.ERROR_HANDLE_DISK_FULL,
+	syscall.ENOSPC,
+}
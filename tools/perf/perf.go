@@ -0,0 +1,173 @@
+// Package perf times the phases of a Git LFS command, such as scanning refs,
+// making batch requests and transferring objects, so that a slow command can
+// be broken down into where its time went.
+//
+// Timing is enabled by the GIT_LFS_PERF environment variable, which takes the
+// same values as GIT_TRACE:
+//     unset, 0, or "false":   disabled
+//     1, 2, or "true":        the summary is written to stderr
+//     absolute path:          the summary is appended to the file
+//
+// When timing is disabled, Start returns nil and Stop does nothing, so timers
+// may be left in place at no cost.
+package perf
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rubyist/tracerx"
+)
+
+var (
+	// enabled is whether GIT_LFS_PERF is set. It is read once, when the
+	// package is initialized, and never changes afterwards.
+	enabled bool
+	// path is the file to which the summary is appended, or empty if it is
+	// written to stderr.
+	path string
+
+	mu      sync.Mutex
+	phases  []*phase
+	byName  map[string]*phase
+	flushed bool
+)
+
+func init() {
+	configure(os.Getenv("GIT_LFS_PERF"))
+}
+
+// configure enables or disables timing according to "value", a value of
+// GIT_LFS_PERF, and clears any phases timed so far.
+func configure(value string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	enabled, path, flushed = false, "", false
+	phases, byName = nil, make(map[string]*phase)
+
+	if n, err := strconv.Atoi(value); err == nil {
+		enabled = n == 1 || n == 2
+	} else if strings.ToLower(value) == "true" {
+		enabled = true
+	} else if filepath.IsAbs(value) {
+		enabled, path = true, value
+	}
+}
+
+// phase records the timings of every run of a single named phase.
+type phase struct {
+	name  string
+	count int
+	// total is the sum of the time each run took. Phases which run
+	// concurrently, such as transfers, may have a total longer than the
+	// command itself.
+	total time.Duration
+	// first and last are the times at which the earliest run started, and
+	// the latest run ended.
+	first, last time.Time
+}
+
+// Timer times a single run of a phase, from Start until Stop.
+type Timer struct {
+	phase string
+	start time.Time
+}
+
+// Enabled returns whether GIT_LFS_PERF is set.
+func Enabled() bool {
+	return enabled
+}
+
+// Start starts timing a run of the named phase. It returns nil if timing is
+// disabled.
+func Start(phase string) *Timer {
+	if !enabled {
+		return nil
+	}
+	return &Timer{phase: phase, start: time.Now()}
+}
+
+// Stop records the time since the timer was started against its phase. It
+// does nothing if "t" is nil, and so is safe to defer whether or not timing is
+// enabled.
+func (t *Timer) Stop() {
+	if t == nil {
+		return
+	}
+
+	end := time.Now()
+	record(t.phase, t.start, end)
+	tracerx.Printf("perf: %s: %.6fs", t.phase, end.Sub(t.start).Seconds())
+}
+
+func record(name string, start, end time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	p, ok := byName[name]
+	if !ok {
+		p = &phase{name: name, first: start, last: end}
+		byName[name] = p
+		phases = append(phases, p)
+	}
+
+	p.count++
+	p.total += end.Sub(start)
+	if start.Before(p.first) {
+		p.first = start
+	}
+	if end.After(p.last) {
+		p.last = end
+	}
+}
+
+// WriteSummary writes a table of the phases timed so far to "w", in the order
+// in which they were first started, ending each line with "le". For each phase
+// it gives the number of runs, their total time, and the wall time from the
+// start of the first run to the end of the last.
+func WriteSummary(w io.Writer, le string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fmt.Fprintf(w, "%-20s %8s %12s %12s"+le, "phase", "count", "total", "wall")
+	for _, p := range phases {
+		fmt.Fprintf(w, "%-20s %8d %11.6fs %11.6fs"+le, p.name, p.count,
+			p.total.Seconds(), p.last.Sub(p.first).Seconds())
+	}
+}
+
+// Flush writes the summary of the phases timed so far to stderr, or appends it
+// to the file named by GIT_LFS_PERF, if timing is enabled. The command line of
+// the command is written before the table, so that the summaries of several
+// commands appended to the same file can be told apart. Only the first call
+// writes anything.
+func Flush(command string) error {
+	mu.Lock()
+	if !enabled || flushed {
+		mu.Unlock()
+		return nil
+	}
+	flushed = true
+	mu.Unlock()
+
+	var w io.Writer = os.Stderr
+	if len(path) > 0 {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	fmt.Fprintf(w, "perf: %s\n", command)
+	WriteSummary(w, "\n")
+	return nil
+}
@@ -0,0 +1,82 @@
+package perf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	defer configure("")
+
+	for value, expected := range map[string]bool{
+		"":            false,
+		"0":           false,
+		"false":       false,
+		"1":           true,
+		"2":           true,
+		"true":        true,
+		"TRUE":        true,
+		"3":           false,
+		"relative":    false,
+		"/tmp/lfs.pf": true,
+	} {
+		configure(value)
+		assert.Equal(t, expected, Enabled(), "GIT_LFS_PERF=%q", value)
+	}
+}
+
+func TestTimerDisabled(t *testing.T) {
+	configure("")
+
+	timer := Start("phase")
+	assert.Nil(t, timer)
+	timer.Stop()
+
+	var buf bytes.Buffer
+	WriteSummary(&buf, "\n")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestWriteSummary(t *testing.T) {
+	configure("1")
+	defer configure("")
+
+	Start("scan").Stop()
+	Start("transfer").Stop()
+	Start("transfer").Stop()
+
+	var buf bytes.Buffer
+	WriteSummary(&buf, "\n")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"phase", "count", "total", "wall"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"scan", "1"}, strings.Fields(lines[1])[:2])
+	assert.Equal(t, []string{"transfer", "2"}, strings.Fields(lines[2])[:2])
+}
+
+func TestFlushToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "perf")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "perf.log")
+	configure(path)
+	defer configure("")
+
+	Start("hashing").Stop()
+	require.Nil(t, Flush("git-lfs clean"))
+	require.Nil(t, Flush("git-lfs clean"))
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "perf: git-lfs clean\n"))
+	assert.Contains(t, string(data), "hashing")
+}
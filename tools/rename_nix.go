@@ -0,0 +1,17 @@
+// +build !windows
+
+package tools
+
+import (
+	"os"
+	"syscall"
+)
+
+// isCrossDeviceError returns whether "err" is the error returned by renaming a
+// file onto a different filesystem.
+func isCrossDeviceError(err error) bool {
+	if lerr, ok := err.(*os.LinkError); ok {
+		return lerr.Err == syscall.EXDEV
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+// +build windows
+
+package tools
+
+import (
+	"os"
+	"syscall"
+)
+
+// errorNotSameDevice is ERROR_NOT_SAME_DEVICE, which is returned by moving a
+// file onto a different drive.
+const errorNotSameDevice = syscall.Errno(17)
+
+// isCrossDeviceError returns whether "err" is the error returned by renaming a
+// file onto a different filesystem.
+func isCrossDeviceError(err error) bool {
+	if lerr, ok := err.(*os.LinkError); ok {
+		return lerr.Err == errorNotSameDevice
+	}
+	return false
+}
@@ -2,10 +2,29 @@
 
 package tools
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 func doWithUmask(mask int, f func() error) error {
 	mask = syscall.Umask(mask)
 	defer syscall.Umask(mask)
 	return f()
 }
+
+// setSharedDirPermissions gives the directory "path" the permissions "perms"
+// and the setgid bit, so that the files created in it belong to its group.
+func setSharedDirPermissions(path string, perms os.FileMode) error {
+	return os.Chmod(path, perms|os.ModeSetgid)
+}
+
+// MissingSharedPermissions returns the permissions which the file or directory
+// described by "info" should have under core.sharedRepository, and whether it
+// lacks any of them. Permissions beyond those are left alone, as Git leaves
+// them.
+func MissingSharedPermissions(info os.FileInfo, config repositoryPermissionFetcher) (os.FileMode, bool) {
+	have := info.Mode() & (os.ModePerm | os.ModeSetgid)
+	want := sharedPermissions(info.Mode(), config)
+	return have | want, want&^have != 0
+}
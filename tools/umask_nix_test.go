@@ -0,0 +1,83 @@
+// +build !windows
+
+package tools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPermissions struct {
+	perms  os.FileMode
+	shared bool
+}
+
+func (p testPermissions) RepositoryPermissions(executable bool) os.FileMode {
+	if executable {
+		return ExecutablePermissions(p.perms)
+	}
+	return p.perms
+}
+
+func (p testPermissions) RepositoryShared() bool { return p.shared }
+
+func TestMkdirAllSetsSetgidWhenShared(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lfstestmkdirall")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	shared := testPermissions{perms: 0660, shared: true}
+	assert.Nil(t, MkdirAll(filepath.Join(dir, "a", "b"), shared))
+
+	for _, path := range []string{filepath.Join(dir, "a"), filepath.Join(dir, "a", "b")} {
+		info, err := os.Stat(path)
+		assert.Nil(t, err)
+		assert.Equal(t, os.ModeSetgid, info.Mode()&os.ModeSetgid, path)
+		assert.EqualValues(t, 0770, info.Mode().Perm(), path)
+	}
+
+	// The directory which already existed is left alone.
+	info, err := os.Stat(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0), info.Mode()&os.ModeSetgid)
+
+	assert.Nil(t, MkdirAll(filepath.Join(dir, "c"), testPermissions{perms: 0640}))
+	info, err = os.Stat(filepath.Join(dir, "c"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0), info.Mode()&os.ModeSetgid)
+}
+
+func TestMissingSharedPermissions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lfstestsharedperms")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	shared := testPermissions{perms: 0660, shared: true}
+
+	file := filepath.Join(dir, "file")
+	assert.Nil(t, ioutil.WriteFile(file, nil, 0600))
+	assert.Nil(t, os.Chmod(file, 0604))
+	info, err := os.Stat(file)
+	assert.Nil(t, err)
+
+	perms, missing := MissingSharedPermissions(info, shared)
+	assert.True(t, missing)
+	assert.EqualValues(t, 0664, perms)
+
+	assert.Nil(t, os.Chmod(file, perms))
+	info, err = os.Stat(file)
+	assert.Nil(t, err)
+	_, missing = MissingSharedPermissions(info, shared)
+	assert.False(t, missing)
+
+	assert.Nil(t, os.Chmod(dir, 0770))
+	info, err = os.Stat(dir)
+	assert.Nil(t, err)
+	perms, missing = MissingSharedPermissions(info, shared)
+	assert.True(t, missing)
+	assert.Equal(t, os.ModeSetgid|0770, perms)
+}
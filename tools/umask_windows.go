@@ -2,6 +2,20 @@
 
 package tools
 
+import "os"
+
 func doWithUmask(mask int, f func() error) error {
 	return f()
 }
+
+// setSharedDirPermissions does nothing, since Windows has neither groups nor
+// the setgid bit.
+func setSharedDirPermissions(path string, perms os.FileMode) error {
+	return nil
+}
+
+// MissingSharedPermissions never finds permissions missing, since Windows does
+// not have POSIX permissions.
+func MissingSharedPermissions(info os.FileInfo, config repositoryPermissionFetcher) (os.FileMode, bool) {
+	return info.Mode().Perm(), false
+}
@@ -3,12 +3,17 @@ package tq
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 
+	"github.com/git-lfs/git-lfs/errors"
 	"github.com/git-lfs/git-lfs/fs"
 	"github.com/git-lfs/git-lfs/lfsapi"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/perf"
 	"github.com/rubyist/tracerx"
 )
 
@@ -24,7 +29,13 @@ type adapterBase struct {
 	remote       string
 	jobChan      chan *job
 	debugging    bool
-	cb           ProgressCallback
+	// verifyDownloads is whether downloaded objects are read back and
+	// hashed before they are moved into the object store.
+	verifyDownloads bool
+	cb              ProgressCallback
+	// outOfSpace is set once a download fails because the volume is
+	// full, after which no further downloads are attempted.
+	outOfSpace int32
 	// WaitGroup to sync the completion of all workers
 	workerWait sync.WaitGroup
 	// WaitGroup to sync the completion of all in-flight jobs
@@ -77,6 +88,8 @@ func (a *adapterBase) Direction() Direction {
 func (a *adapterBase) Begin(cfg AdapterConfig, cb ProgressCallback) error {
 	a.apiClient = cfg.APIClient()
 	a.remote = cfg.Remote()
+	a.verifyDownloads = cfg.VerifyDownloads()
+	atomic.StoreInt32(&a.outOfSpace, 0)
 	a.cb = cb
 	a.jobChan = make(chan *job, 100)
 	a.debugging = a.apiClient.OSEnv().Bool("GIT_TRANSFER_TRACE", false) ||
@@ -98,6 +111,23 @@ func (a *adapterBase) Begin(cfg AdapterConfig, cb ProgressCallback) error {
 	return nil
 }
 
+// verifyDownload reads back the object downloaded to "path" for the transfer
+// "t" and checks that it hashes to the transfer's OID, if downloads are to be
+// verified. An object which does not is removed, so that it is not moved into
+// the object store.
+func (a *adapterBase) verifyDownload(t *Transfer, path string) error {
+	if !a.verifyDownloads {
+		return nil
+	}
+
+	a.Trace("xfer: verifying %s", t.Oid)
+	if err := tools.VerifyFileHash(t.Oid, path); err != nil {
+		os.Remove(path)
+		return errors.Wrapf(err, "downloaded object %s failed verification", t.Oid)
+	}
+	return nil
+}
+
 type job struct {
 	T *Transfer
 
@@ -178,8 +208,16 @@ func (a *adapterBase) worker(workerNum int, ctx interface{}) {
 		var err error
 		if t.Size < 0 {
 			err = fmt.Errorf("object %q has invalid size (got: %d)", t.Oid, t.Size)
+		} else if a.direction == Download && atomic.LoadInt32(&a.outOfSpace) != 0 {
+			err = errSkippedNoSpace
 		} else {
+			timer := perf.Start("transfer")
 			err = a.transferImpl.DoTransfer(ctx, t, a.cb, authCallback)
+			timer.Stop()
+			if a.direction == Download && tools.IsNoSpaceError(err) &&
+				atomic.CompareAndSwapInt32(&a.outOfSpace, 0, 1) {
+				tracerx.Printf("xfer: out of space downloading %q, not starting any more downloads", t.Oid)
+			}
 		}
 
 		// Mark the job as completed, and alter all listeners
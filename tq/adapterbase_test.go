@@ -0,0 +1,64 @@
+package tq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDownloadedObject(t *testing.T, contents []byte) (*Transfer, string, func()) {
+	dir, err := ioutil.TempDir("", "tq-verify-download")
+	require.Nil(t, err)
+
+	sum := sha256.Sum256(contents)
+	path := filepath.Join(dir, "download")
+	require.Nil(t, ioutil.WriteFile(path, contents, 0644))
+
+	tr := &Transfer{Oid: hex.EncodeToString(sum[:]), Size: int64(len(contents))}
+	return tr, path, func() { os.RemoveAll(dir) }
+}
+
+func TestVerifyDownloadAcceptsIntactObject(t *testing.T) {
+	tr, path, cleanup := writeDownloadedObject(t, []byte("downloaded contents"))
+	defer cleanup()
+
+	a := &adapterBase{verifyDownloads: true}
+	assert.Nil(t, a.verifyDownload(tr, path))
+	assert.FileExists(t, path)
+}
+
+func TestVerifyDownloadDetectsBitFlip(t *testing.T) {
+	contents := []byte("downloaded contents")
+	tr, path, cleanup := writeDownloadedObject(t, contents)
+	defer cleanup()
+
+	// Flip a single bit of the object on disk after it was downloaded.
+	contents[3] ^= 0x01
+	require.Nil(t, ioutil.WriteFile(path, contents, 0644))
+
+	a := &adapterBase{verifyDownloads: true}
+	err := a.verifyDownload(tr, path)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "failed verification")
+		assert.Contains(t, err.Error(), tr.Oid)
+	}
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "expected corrupt download to be removed")
+}
+
+func TestVerifyDownloadDisabled(t *testing.T) {
+	tr, path, cleanup := writeDownloadedObject(t, []byte("downloaded contents"))
+	defer cleanup()
+	require.Nil(t, ioutil.WriteFile(path, []byte("something else"), 0644))
+
+	a := &adapterBase{}
+	assert.Nil(t, a.verifyDownload(tr, path))
+	assert.FileExists(t, path)
+}
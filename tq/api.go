@@ -7,6 +7,7 @@ import (
 	"github.com/git-lfs/git-lfs/git"
 	"github.com/git-lfs/git-lfs/lfsapi"
 	"github.com/git-lfs/git-lfs/lfshttp"
+	"github.com/git-lfs/git-lfs/tools/perf"
 	"github.com/rubyist/tracerx"
 )
 
@@ -63,6 +64,9 @@ func (c *tqClient) Batch(remote string, bReq *batchRequest) (*BatchResponse, err
 	bRes.endpoint = c.Endpoints.Endpoint(bReq.Operation, remote)
 	requestedAt := time.Now()
 
+	timer := perf.Start("batch request")
+	defer timer.Stop()
+
 	req, err := c.NewRequest("POST", bRes.endpoint, "objects/batch", bReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "batch request")
@@ -251,14 +251,22 @@ func (a *basicDownloadAdapter) download(t *Transfer, cb ProgressCallback, authOk
 	}
 
 	if actual := hasher.Hash(); actual != t.Oid {
-		return fmt.Errorf("expected OID %s, got %s after %d bytes written", t.Oid, actual, written)
+		return checksumMismatchError{Oid: t.Oid, Actual: actual, Written: written}
 	}
 
 	if err := dlFile.Close(); err != nil {
 		return fmt.Errorf("can't close tempfile %q: %v", dlfilename, err)
 	}
 
+	if err := a.verifyDownload(t, dlfilename); err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(t.Path)
 	err = tools.RenameFileCopyPermissions(dlfilename, t.Path)
+	if err == nil && os.IsNotExist(statErr) {
+		a.fs.RecordObjectAdded(t.Size)
+	}
 	if _, err2 := os.Stat(t.Path); err2 == nil {
 		// Target file already exists, possibly was downloaded by other git-lfs process
 		return nil
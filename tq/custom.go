@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -317,8 +318,17 @@ func (a *customAdapter) DoTransfer(ctx interface{}, t *Transfer, cb ProgressCall
 					return fmt.Errorf("downloaded file failed checks: %v", err)
 				}
 				// Move file to final location
+				_, statErr := os.Stat(t.Path)
 				if err = tools.RenameFileCopyPermissions(resp.Path, t.Path); err != nil {
-					return fmt.Errorf("failed to copy downloaded file: %v", err)
+					if _, err2 := os.Stat(t.Path); err2 != nil {
+						return fmt.Errorf("failed to copy downloaded file: %v", err)
+					}
+					// Target file already exists, possibly was downloaded by other git-lfs process
+				} else if err = os.Chmod(t.Path, a.fs.RepositoryPermissions(false)); err != nil {
+					// The adapter wrote the file with its own permissions
+					return fmt.Errorf("failed to set permissions of downloaded file: %v", err)
+				} else if os.IsNotExist(statErr) {
+					a.fs.RecordObjectAdded(t.Size)
 				}
 			} else if a.direction == Upload {
 				if err = verifyUpload(a.apiClient, a.remote, t); err != nil {
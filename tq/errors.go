@@ -1,6 +1,12 @@
 package tq
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/git-lfs/git-lfs/errors"
+	"github.com/git-lfs/git-lfs/tools"
+	"github.com/git-lfs/git-lfs/tools/humanize"
+)
 
 type MalformedObjectError struct {
 	Name string
@@ -27,3 +33,40 @@ func (e MalformedObjectError) Error() string {
 	}
 	return fmt.Sprintf("missing object: %s (%s)", e.Name, e.Oid)
 }
+
+// checksumMismatchError is returned for a downloaded object whose contents do
+// not have the OID it was requested by.
+type checksumMismatchError struct {
+	Oid     string
+	Actual  string
+	Written int64
+}
+
+func (e checksumMismatchError) Corrupt() bool { return true }
+
+func (e checksumMismatchError) Error() string {
+	return fmt.Sprintf("expected OID %s, got %s after %d bytes written", e.Oid, e.Actual, e.Written)
+}
+
+// NoSpaceError is returned by a download queue which ran out of space to store
+// the objects it downloaded. The queue stops downloading objects once it does,
+// and Needed is the size of the objects which it did not download.
+type NoSpaceError struct {
+	Objects int
+	Needed  uint64
+}
+
+func (e NoSpaceError) Error() string {
+	return fmt.Sprintf("not enough space to store downloaded objects: %d object(s) not downloaded, %s more space is needed",
+		e.Objects, humanize.FormatBytes(e.Needed))
+}
+
+// errSkippedNoSpace is the error for downloads which are not attempted because
+// an earlier download ran out of space.
+var errSkippedNoSpace = errors.New("not attempted, out of space")
+
+// isNoSpaceError returns whether "err" means that a download could not be
+// stored because the volume was full.
+func isNoSpaceError(err error) bool {
+	return errors.Cause(err) == errSkippedNoSpace || tools.IsNoSpaceError(err)
+}
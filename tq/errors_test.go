@@ -3,6 +3,7 @@ package tq
 import (
 	"testing"
 
+	"github.com/git-lfs/git-lfs/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,3 +22,15 @@ func TestCorruptObjectErrorsAreRecognizable(t *testing.T) {
 	assert.Equal(t, "some-oid", err.Oid)
 	assert.True(t, err.Corrupt())
 }
+
+func TestNoSpaceErrorsAreRecognizable(t *testing.T) {
+	assert.True(t, isNoSpaceError(errSkippedNoSpace))
+	assert.True(t, isNoSpaceError(errors.Wrap(errSkippedNoSpace, "some-oid")))
+	assert.False(t, isNoSpaceError(newObjectMissingError("some-name", "some-oid")))
+}
+
+func TestNoSpaceErrorReportsSpaceNeeded(t *testing.T) {
+	err := NoSpaceError{Objects: 2, Needed: 3 * 1024 * 1024}
+
+	assert.Equal(t, "not enough space to store downloaded objects: 2 object(s) not downloaded, 3.1 MB more space is needed", err.Error())
+}
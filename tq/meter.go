@@ -2,6 +2,7 @@ package tq
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -34,10 +35,16 @@ type Meter struct {
 	fileIndexMutex    *sync.Mutex
 	updates           chan *tasklog.Update
 	cfg               *config.Configuration
+	events            meterEvents
 
 	DryRun    bool
 	Logger    *tools.SyncWriter
 	Direction Direction
+
+	// Events, if not nil, is written with a JSON ProgressEvent on each
+	// line for every change in progress, in place of the meter's usual
+	// updates.
+	Events io.Writer
 }
 
 type env interface {
@@ -129,9 +136,33 @@ func (m *Meter) Skip(size int64) {
 	atomic.AddInt64(&m.currentBytes, size)
 }
 
-// StartTransfer tells the progress meter that a transferring file is being
-// added to the TransferQueue.
-func (m *Meter) StartTransfer(name string) {
+// SkipObject behaves as Skip, for the file "name" with the OID "oid", which
+// is reported as skipped in the progress events.
+func (m *Meter) SkipObject(oid, name string, size int64) {
+	if m == nil {
+		return
+	}
+
+	m.Skip(size)
+	m.setEventOid(name, oid)
+	m.event("skip", name, func(e *ProgressEvent) { e.Size = size })
+}
+
+// FailObject tells the progress meter that the transfer of the file "name"
+// with the OID "oid" failed with "err", which is reported in the progress
+// events. The file must be skipped separately.
+func (m *Meter) FailObject(oid, name string, err error) {
+	if m == nil {
+		return
+	}
+
+	m.setEventOid(name, oid)
+	m.event("error", name, func(e *ProgressEvent) { e.Error = err.Error() })
+}
+
+// StartTransfer tells the progress meter that a transferring file, with the
+// given OID and size, is being added to the TransferQueue.
+func (m *Meter) StartTransfer(oid, name string, size int64) {
 	if m == nil {
 		return
 	}
@@ -141,6 +172,9 @@ func (m *Meter) StartTransfer(name string) {
 	m.fileIndexMutex.Lock()
 	m.fileIndex[name] = idx
 	m.fileIndexMutex.Unlock()
+
+	m.setEventOid(name, oid)
+	m.event("start", name, func(e *ProgressEvent) { e.Size = size })
 }
 
 // TransferBytes increments the number of bytes transferred
@@ -168,6 +202,9 @@ func (m *Meter) TransferBytes(direction, name string, read, total int64, current
 	}
 
 	m.logBytes(direction, name, read, total)
+	if m.Events != nil && m.progressEventDue(read, total) {
+		m.event("progress", name, nil)
+	}
 }
 
 // FinishTransfer increments the finished transfer count
@@ -181,6 +218,8 @@ func (m *Meter) FinishTransfer(name string) {
 	m.fileIndexMutex.Lock()
 	delete(m.fileIndex, name)
 	m.fileIndexMutex.Unlock()
+
+	m.event("complete", name, nil)
 }
 
 // Flush sends the latest progress update, while leaving the meter active.
@@ -199,6 +238,9 @@ func (m *Meter) Finish() {
 	}
 
 	m.update(false)
+	if !m.DryRun {
+		m.event("done", "", nil)
+	}
 	close(m.updates)
 }
 
@@ -227,20 +269,39 @@ func (m *Meter) update(force bool) {
 
 func (m *Meter) skipUpdate() bool {
 	return m.DryRun ||
+		m.Events != nil ||
 		m.estimatedFiles == 0 ||
 		atomic.LoadUint32(&m.paused) == 1
 }
 
 func (m *Meter) str() string {
-	// (Uploading|Downloading) LFS objects: 100% (10/10) 100 MiB | 10 MiB/s
+	// (Uploading|Downloading) LFS objects: 50% (5/10), 50 MiB/100 MiB | 10 MiB/s, ETA 5s
 	percentage := 100 * float64(m.finishedFiles) / float64(m.estimatedFiles)
 
-	return fmt.Sprintf("%s LFS objects: %3.f%% (%d/%d), %s | %s",
+	return fmt.Sprintf("%s LFS objects: %3.f%% (%d/%d), %s/%s | %s%s",
 		m.Direction.Verb(),
 		percentage,
 		m.finishedFiles, m.estimatedFiles,
 		humanize.FormatBytes(clamp(m.currentBytes)),
-		humanize.FormatByteRate(clampf(m.avgBytes), time.Second))
+		humanize.FormatBytes(clamp(m.estimatedBytes)),
+		humanize.FormatByteRate(clampf(m.avgBytes), time.Second),
+		m.eta())
+}
+
+// eta returns the estimated time remaining until every byte is transferred at
+// the average rate so far, as ", ETA <duration>", or the empty string if it
+// cannot be estimated or the transfer is complete.
+func (m *Meter) eta() string {
+	remaining := m.estimatedBytes - m.currentBytes
+	if m.avgBytes <= 0 || remaining <= 0 {
+		return ""
+	}
+
+	secs := float64(remaining) / m.avgBytes
+	if secs > float64(math.MaxInt64/int64(time.Second)) {
+		return ""
+	}
+	return fmt.Sprintf(", ETA %s", (time.Duration(math.Ceil(secs)) * time.Second).String())
 }
 
 // clamp clamps the given "x" within the acceptable domain of the uint64 integer
@@ -0,0 +1,80 @@
+package tq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeterStrIncludesTotalsAndETA(t *testing.T) {
+	m := NewMeter(nil)
+	m.Direction = Download
+	m.estimatedFiles = 10
+	m.estimatedBytes = 100 * 1024 * 1024
+	m.finishedFiles = 5
+	m.currentBytes = 50 * 1024 * 1024
+	m.avgBytes = 10 * 1024 * 1024
+
+	assert.Equal(t, "Downloading LFS objects:  50% (5/10), 52 MB/105 MB | 10 MB/s, ETA 5s", m.str())
+}
+
+func TestMeterStrOmitsETAWhenUnknownOrComplete(t *testing.T) {
+	m := NewMeter(nil)
+	m.Direction = Upload
+	m.estimatedFiles = 1
+	m.estimatedBytes = 1
+
+	assert.Equal(t, "Uploading LFS objects:   0% (0/1), 0 B/1 B | 0 B/s", m.str())
+
+	m.finishedFiles = 1
+	m.currentBytes = 1
+	m.avgBytes = 1
+
+	assert.Equal(t, "Uploading LFS objects: 100% (1/1), 1 B/1 B | 1 B/s", m.str())
+}
+
+func TestMeterWritesProgressEvents(t *testing.T) {
+	var buf bytes.Buffer
+
+	m := NewMeter(nil)
+	m.Direction = Download
+	m.Events = &buf
+
+	m.Add(10)
+	m.Add(5)
+	m.Add(3)
+	m.StartTransfer("oid1", "a.dat", 10)
+	m.TransferBytes("download", "a.dat", 4, 10, 4)
+	m.TransferBytes("download", "a.dat", 10, 10, 6)
+	m.FinishTransfer("a.dat")
+	m.SkipObject("oid2", "b.dat", 5)
+	m.FailObject("oid3", "c.dat", errors.New("boom"))
+	m.Skip(3)
+	m.Finish()
+
+	var events []*ProgressEvent
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var e ProgressEvent
+		require.Nil(t, json.Unmarshal([]byte(line), &e), line)
+		assert.Equal(t, "download", e.Direction)
+		assert.EqualValues(t, 18, e.BytesTotal)
+		assert.EqualValues(t, 3, e.ObjectsTotal)
+		events = append(events, &e)
+	}
+
+	// The first progress event is written, the second is throttled, and
+	// the last is written because it completes the object.
+	require.Len(t, events, 7)
+	assert.Equal(t, &ProgressEvent{Event: "start", Direction: "download", Oid: "oid1", Path: "a.dat", Size: 10, BytesTotal: 18, ObjectsTotal: 3}, events[0])
+	assert.Equal(t, &ProgressEvent{Event: "progress", Direction: "download", Oid: "oid1", Path: "a.dat", BytesSoFar: 4, BytesTotal: 18, ObjectsTotal: 3}, events[1])
+	assert.Equal(t, &ProgressEvent{Event: "progress", Direction: "download", Oid: "oid1", Path: "a.dat", BytesSoFar: 10, BytesTotal: 18, ObjectsTotal: 3}, events[2])
+	assert.Equal(t, &ProgressEvent{Event: "complete", Direction: "download", Oid: "oid1", Path: "a.dat", BytesSoFar: 10, BytesTotal: 18, ObjectsDone: 1, ObjectsTotal: 3}, events[3])
+	assert.Equal(t, &ProgressEvent{Event: "skip", Direction: "download", Oid: "oid2", Path: "b.dat", Size: 5, BytesSoFar: 15, BytesTotal: 18, ObjectsDone: 2, ObjectsTotal: 3}, events[4])
+	assert.Equal(t, &ProgressEvent{Event: "error", Direction: "download", Oid: "oid3", Path: "c.dat", Error: "boom", BytesSoFar: 15, BytesTotal: 18, ObjectsDone: 2, ObjectsTotal: 3}, events[5])
+	assert.Equal(t, &ProgressEvent{Event: "done", Direction: "download", BytesSoFar: 18, BytesTotal: 18, ObjectsDone: 3, ObjectsTotal: 3}, events[6])
+}
@@ -0,0 +1,109 @@
+package tq
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/git-lfs/git-lfs/tasklog"
+)
+
+// ProgressEvent is one line of the JSON progress stream written by a Meter
+// with Events set. The byte and object counts are those of the whole
+// operation so far; the object fields are set for events about one object.
+type ProgressEvent struct {
+	// Event is one of "start", "progress", "complete", "skip", "error",
+	// or "done".
+	Event     string `json:"event"`
+	Direction string `json:"direction"`
+
+	Oid  string `json:"oid,omitempty"`
+	Path string `json:"path,omitempty"`
+	// Size is the size of the object, for "start" and "skip" events.
+	Size int64 `json:"size,omitempty"`
+	// Error is the error message, for "error" events.
+	Error string `json:"error,omitempty"`
+
+	BytesSoFar   int64 `json:"bytes_so_far"`
+	BytesTotal   int64 `json:"bytes_total"`
+	ObjectsDone  int64 `json:"objects_done"`
+	ObjectsTotal int64 `json:"objects_total"`
+}
+
+// progressEventThrottle is the minimum amount of time between "progress"
+// events, other than those for the last bytes of an object.
+const progressEventThrottle = tasklog.DefaultLoggingThrottle
+
+// meterEvents records the state needed to write a Meter's progress events.
+type meterEvents struct {
+	mu sync.Mutex
+	// oids maps the name of each file being transferred to its OID.
+	oids map[string]string
+	// last is when the last "progress" event was written.
+	last time.Time
+}
+
+// event writes a progress event of type "typ" about the file "name", if
+// there is one, to m.Events.
+func (m *Meter) event(typ, name string, fn func(e *ProgressEvent)) {
+	if m.Events == nil {
+		return
+	}
+
+	e := &ProgressEvent{
+		Event:        typ,
+		Direction:    m.Direction.String(),
+		Path:         name,
+		BytesSoFar:   atomic.LoadInt64(&m.currentBytes),
+		BytesTotal:   atomic.LoadInt64(&m.estimatedBytes),
+		ObjectsDone:  atomic.LoadInt64(&m.finishedFiles),
+		ObjectsTotal: int64(atomic.LoadInt32(&m.estimatedFiles)),
+	}
+
+	m.events.mu.Lock()
+	if len(name) > 0 {
+		e.Oid = m.events.oids[name]
+	}
+	m.events.mu.Unlock()
+
+	if fn != nil {
+		fn(e)
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	tasklog.Write(m.Events, append(line, '\n'))
+}
+
+// setEventOid records that the file "name" has the OID "oid", for the events
+// about it.
+func (m *Meter) setEventOid(name, oid string) {
+	if m.Events == nil {
+		return
+	}
+
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	if m.events.oids == nil {
+		m.events.oids = make(map[string]string)
+	}
+	m.events.oids[name] = oid
+}
+
+// progressEventDue returns whether a "progress" event should be written now,
+// which is always the case when an object has been completely transferred.
+func (m *Meter) progressEventDue(read, total int64) bool {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	now := time.Now()
+	if read < total && now.Sub(m.events.last) < progressEventThrottle {
+		return false
+	}
+	m.events.last = now
+	return true
+}
@@ -193,12 +193,16 @@ type AdapterConfig interface {
 	APIClient() *lfsapi.Client
 	ConcurrentTransfers() int
 	Remote() string
+	// VerifyDownloads is whether downloaded objects should be read back
+	// and hashed before they are moved into the object store.
+	VerifyDownloads() bool
 }
 
 type adapterConfig struct {
 	apiClient           *lfsapi.Client
 	concurrentTransfers int
 	remote              string
+	verifyDownloads     bool
 }
 
 func (c *adapterConfig) ConcurrentTransfers() int {
@@ -213,6 +217,10 @@ func (c *adapterConfig) Remote() string {
 	return c.remote
 }
 
+func (c *adapterConfig) VerifyDownloads() bool {
+	return c.verifyDownloads
+}
+
 // Adapter is implemented by types which can upload and/or download LFS
 // file content to a remote store. Each Adapter accepts one or more requests
 // which it may schedule and parallelise in whatever way it chooses, clients of
@@ -187,6 +187,7 @@ type TransferQueue struct {
 	adapterInProgress bool
 	adapterInitMutex  sync.Mutex
 	dryRun            bool
+	verifyDownloads   bool
 	cb                tools.CopyCallback
 	meter             *Meter
 	errors            []error
@@ -210,6 +211,11 @@ type TransferQueue struct {
 	// an HTTP 422 response indicating that their upload destination does
 	// not support Content-Type detection.
 	unsupportedContentType bool
+
+	// noSpace records the downloads which were not stored, or not
+	// attempted, because the volume holding the object store is full.
+	// It is guarded by trMutex.
+	noSpace NoSpaceError
 }
 
 // objects holds a set of objects.
@@ -265,6 +271,15 @@ func DryRun(dryRun bool) Option {
 	}
 }
 
+// WithVerifyDownloads makes download adapters read back each object once it
+// has been transferred, and check that it hashes to its OID before it is moved
+// into the object store.
+func WithVerifyDownloads(verify bool) Option {
+	return func(tq *TransferQueue) {
+		tq.verifyDownloads = verify
+	}
+}
+
 func WithProgress(m *Meter) Option {
 	return func(tq *TransferQueue) {
 		tq.meter = m
@@ -468,6 +483,18 @@ func (q *TransferQueue) collectBatches() {
 			break
 		}
 
+		// If a download has run out of space, don't start any more,
+		// but account for those not downloaded so that the space they
+		// need can be reported.
+		if q.outOfSpace() {
+			q.skipNoSpace(retries, pending, collected)
+			q.wait.Abort()
+			for t := range q.incoming {
+				q.skipNoSpace(batch{t})
+			}
+			break
+		}
+
 		// Ensure the next batch is filled with, in order:
 		//
 		// - retries from the previous batch,
@@ -604,7 +631,9 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 
 	for _, o := range bRes.Objects {
 		if o.Error != nil {
-			q.errorc <- errors.Wrapf(o.Error, "[%v] %v", o.Oid, o.Error.Message)
+			err := errors.Wrapf(o.Error, "[%v] %v", o.Oid, o.Error.Message)
+			q.errorc <- err
+			q.meter.FailObject(o.Oid, q.transferName(o.Oid), err)
 			q.Skip(o.Size)
 			q.wait.Done()
 
@@ -619,7 +648,9 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 			// Transfer object, then we give up on the
 			// transfer by telling the progress meter to
 			// skip the number of bytes in "o".
-			q.errorc <- errors.Errorf("[%v] The server returned an unknown OID.", o.Oid)
+			err := errors.Errorf("[%v] The server returned an unknown OID.", o.Oid)
+			q.errorc <- err
+			q.meter.FailObject(o.Oid, "", err)
 
 			q.Skip(o.Size)
 			q.wait.Done()
@@ -632,16 +663,18 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 				if q.canRetryObject(tr.Oid, err) {
 					enqueueRetry(objects.First(), err, nil)
 				} else {
-					q.errorc <- errors.Errorf("[%v] %v", tr.Name, err)
+					err = errors.Errorf("[%v] %v", tr.Name, err)
+					q.errorc <- err
+					q.meter.FailObject(o.Oid, tr.Name, err)
 
 					q.Skip(o.Size)
 					q.wait.Done()
 				}
 			} else if a == nil && q.manifest.standaloneTransferAgent == "" {
-				q.Skip(o.Size)
+				q.meter.SkipObject(o.Oid, tr.Name, o.Size)
 				q.wait.Done()
 			} else {
-				q.meter.StartTransfer(objects.First().Name)
+				q.meter.StartTransfer(o.Oid, tr.Name, o.Size)
 				toTransfer = append(toTransfer, tr)
 			}
 		}
@@ -655,6 +688,29 @@ func (q *TransferQueue) enqueueAndCollectRetriesFor(batch batch) (batch, error)
 	return next, nil
 }
 
+// outOfSpace returns whether any download has failed because the volume
+// holding the object store is full.
+func (q *TransferQueue) outOfSpace() bool {
+	q.trMutex.Lock()
+	defer q.trMutex.Unlock()
+
+	return q.noSpace.Objects > 0
+}
+
+// skipNoSpace records that the objects in "batches" were not downloaded
+// because the volume holding the object store is full.
+func (q *TransferQueue) skipNoSpace(batches ...batch) {
+	q.trMutex.Lock()
+	defer q.trMutex.Unlock()
+
+	for _, b := range batches {
+		for _, t := range b {
+			q.noSpace.Objects++
+			q.noSpace.Needed += uint64(t.Size)
+		}
+	}
+}
+
 // makeBatch returns a new, empty batch, with a capacity equal to the maximum
 // batch size designated by the `*TransferQueue`.
 func (q *TransferQueue) makeBatch() batch { return make(batch, 0, q.batchSize) }
@@ -673,6 +729,7 @@ func (q *TransferQueue) addToAdapter(e lfshttp.Endpoint, pending []*Transfer) <-
 
 		q.errorc <- err
 		for _, t := range pending {
+			q.meter.FailObject(t.Oid, t.Name, err)
 			q.Skip(t.Size)
 			q.wait.Done()
 		}
@@ -765,7 +822,15 @@ func (q *TransferQueue) handleTransferResult(
 	if res.Error != nil {
 		// If there was an error encountered when processing the
 		// transfer (res.Transfer), handle the error as is appropriate:
-		if readyTime, canRetry := q.canRetryObjectLater(oid, res.Error); canRetry {
+		if q.direction == Download && isNoSpaceError(res.Error) {
+			// If the object could not be stored because the volume
+			// is full, retrying it won't help. It is reported
+			// along with any others by Wait().
+			tracerx.Printf("tq: out of space downloading %q: %s", oid, res.Error)
+			q.skipNoSpace(batch{&objectTuple{Oid: oid, Size: res.Transfer.Size}})
+			q.meter.FailObject(oid, res.Transfer.Name, res.Error)
+			q.wait.Done()
+		} else if readyTime, canRetry := q.canRetryObjectLater(oid, res.Error); canRetry {
 			// If the object can't be retried now, but can be
 			// after a certain period of time, send it to
 			// the retry channel with a time when it's ready.
@@ -807,6 +872,7 @@ func (q *TransferQueue) handleTransferResult(
 			} else {
 				q.errorc <- res.Error
 			}
+			q.meter.FailObject(oid, res.Transfer.Name, res.Error)
 			q.wait.Done()
 		}
 	} else {
@@ -868,6 +934,18 @@ func (q *TransferQueue) BatchSize() int {
 	return q.batchSize
 }
 
+// transferName returns the name of the first file being transferred with the
+// OID "oid", or the empty string if there is none.
+func (q *TransferQueue) transferName(oid string) string {
+	q.trMutex.Lock()
+	defer q.trMutex.Unlock()
+
+	if objects, ok := q.transfers[oid]; ok {
+		return objects.First().Name
+	}
+	return ""
+}
+
 func (q *TransferQueue) Skip(size int64) {
 	q.meter.Skip(size)
 }
@@ -915,6 +993,7 @@ func (q *TransferQueue) toAdapterCfg(e lfshttp.Endpoint) AdapterConfig {
 		concurrentTransfers: concurrency,
 		apiClient:           apiClient,
 		remote:              q.remote,
+		verifyDownloads:     q.verifyDownloads,
 	}
 }
 
@@ -948,6 +1027,10 @@ func (q *TransferQueue) Wait() {
 	q.meter.Flush()
 	q.errorwait.Wait()
 
+	if q.noSpace.Objects > 0 {
+		q.errors = append(q.errors, q.noSpace)
+	}
+
 	if q.unsupportedContentType {
 		for _, line := range contentTypeWarning {
 			fmt.Fprintf(os.Stderr, "info: %s\n", line)
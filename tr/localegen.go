@@ -0,0 +1,71 @@
+// +build ignore
+
+// localegen compiles the message catalogs in the locales directory into
+// locales_gen.go, so that they are built into the git-lfs binary. It is run by
+// 'go generate' in package tr.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	files, err := filepath.Glob(filepath.Join("locales", "*.json"))
+	if err != nil {
+		fail(err)
+	}
+	sort.Strings(files)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by localegen.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package tr\n\n")
+	buf.WriteString("// locales maps each language to its messages, by ID.\n")
+	buf.WriteString("var locales = map[string]map[string]string{\n")
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			fail(err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			fail(fmt.Errorf("%s: %s", file, err))
+		}
+
+		ids := make([]string, 0, len(messages))
+		for id := range messages {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		lang := strings.TrimSuffix(filepath.Base(file), ".json")
+		fmt.Fprintf(&buf, "%q: {\n", lang)
+		for _, id := range ids {
+			fmt.Fprintf(&buf, "%q: %q,\n", id, messages[id])
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		fail(err)
+	}
+	if err := ioutil.WriteFile("locales_gen.go", src, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "localegen: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,427 @@
+// Code generated by localegen.go; DO NOT EDIT.
+
+package tr
+
+// locales maps each language to its messages, by ID.
+var locales = map[string]map[string]string{
+	"de": {
+		"checkout.failed":         "Auschecken fehlgeschlagen",
+		"checkout.not-installed":  "LFS-Objekte können nicht ausgecheckt werden, Git LFS ist nicht installiert.",
+		"common.could-not-scan":   "Suche nach Git-LFS-Dateien fehlgeschlagen",
+		"common.failed-to-fetch":  "Fehler: Einige Objekte konnten nicht von '{{.URL}}' geholt werden",
+		"common.invalid-remote":   "Ungültiger Remote-Name {{quote .Remote}}: {{.Err}}",
+		"common.not-in-repo":      "Kein Git-Repository.",
+		"common.not-in-work-tree": "Dieser Vorgang muss in einem Arbeitsverzeichnis ausgeführt werden.",
+		"fetch.fetching-all":      "fetch: Alle Referenzen werden geholt ...",
+		"fetch.fetching-ref":      "fetch: Referenz {{.Ref}} wird geholt",
+		"lock.failed":             "Sperren fehlgeschlagen: {{.Err}}",
+		"lock.locked":             "{{.Path}} gesperrt",
+		"lock.usage":              "Verwendung: git lfs lock <Pfad>",
+		"push.specify-remote":     "Bitte ein Remote und einen Remote-Branch angeben (`git lfs push origin master`)",
+		"track.already-supported": "{{quote .Pattern}} wird bereits unterstützt",
+		"track.listing-excluded":  "Ausgeschlossene Muster",
+		"track.listing-tracked":   "Verfolgte Muster",
+		"track.not-a-repo":        "Kein Git-Repository.",
+		"track.tracking":          "{{quote .Pattern}} wird verfolgt",
+		"unlock.unlocked":         "{{.Path}} entsperrt",
+		"unlock.usage":            "Verwendung: git lfs unlock (--id meine-sperr-id | --oid sha256:meine-oid | <Pfad>)",
+	},
+	"en": {
+		"audit-log.invalid-format":               "Invalid --format {{quote .Format}}: expected table, json or csv",
+		"audit-log.invalid-since":                "Invalid --since: {{.Err}}",
+		"audit-log.invalid-until":                "Invalid --until: {{.Err}}",
+		"audit-log.malformed-line":               "Skipping malformed line {{.Line}} of {{.Path}}",
+		"audit-log.pager-failed":                 "Could not start pager {{quote .Pager}}: {{.Err}}",
+		"audit-log.read-failed":                  "Could not read the operation log: {{.Err}}",
+		"audit-log.summary-header":               "TYPE\tOPERATIONS\tOBJECTS\tSIZE",
+		"audit-log.table-header":                 "TIME\tUSER\tTYPE\tOBJECTS\tSIZE\tREF",
+		"autoinstall.hooks-failed":               "WARNING: could not install the Git LFS hooks: {{.Err}}",
+		"autoinstall.how-to-set-up":              "Run `git lfs install --local` to set it up, then `git lfs pull` to replace the pointers with their contents.",
+		"autoinstall.not-set-up":                 "WARNING: Git LFS is not set up for this repository, so files tracked by Git LFS are checked out as pointers.",
+		"autoinstall.prompt":                     "Git LFS is not set up for this repository, so files tracked by Git LFS are checked out as pointers.\nSet it up now, as `git lfs install --local` would? [Y/n] ",
+		"autoinstall.set-up":                     "Set up Git LFS for this repository. Run `git lfs pull` to replace any pointers with their contents.",
+		"autoinstall.set-up-later":               "Run `git lfs install --local` to set it up later.",
+		"autoinstall.setup-failed":               "WARNING: could not set up Git LFS for this repository: {{.Err}}",
+		"checkout.copied":                        "Copied {{.Name}}, which could not be linked to its object: {{.Err}}",
+		"checkout.decode-pointer":                "Could not find decoder pointer for object {{quote .Sha}}: {{.Err}}",
+		"checkout.failed":                        "Could not checkout",
+		"checkout.linked":                        "Linked {{.Name}} to its object",
+		"checkout.not-installed":                 "Cannot checkout LFS objects, Git LFS is not installed.",
+		"checkout.not-merging":                   "Could not checkout (are you not in the middle of a merge?): {{.Err}}",
+		"checkout.object-not-found":              "Could not find object {{quote .Sha}}",
+		"checkout.object-scanner":                "Could not create object scanner: {{.Err}}",
+		"checkout.parse-args":                    "Error parsing args: {{.Err}}",
+		"checkout.progress":                      "Checking out LFS objects: {{.Done}}/{{.Total}} ({{.DoneBytes}}/{{.TotalBytes}})",
+		"checkout.to-failed":                     "Error checking out {{.Sha}} to {{quote .Path}}: {{.Err}}",
+		"checkout.to-needs-stage":                "--to and exactly one of --theirs, --ours, and --base must be used together",
+		"clean.files-differ":                     "Files don't match:\n{{.Object}}\n{{.Temp}}",
+		"clean.forbidden-file":                   "Not storing {{.File}} in Git LFS: files starting with {{quote .Prefix}} cannot be tracked",
+		"clean.larger-than-max-object-size":      "Warning: {{.File}} ({{.Size}}) is larger than lfs.maxobjectsize ({{.Max}}), and is likely to be rejected when pushed.",
+		"clean.malformed-on-windows":             "Possibly malformed conversion on Windows, see `git lfs help smudge` for more details.",
+		"clean.media-path":                       "Unable to get local media path.",
+		"clean.move-failed":                      "Unable to move {{.From}} to {{.To}}\n",
+		"clone.chdir-failed":                     "Unable to change directory to clone dir {{quote .Dir}}: {{.Err}}",
+		"clone.dir-not-found":                    "Unable to find clone dir at {{quote .Dir}}",
+		"clone.errors":                           "Error(s) during clone:\n{{.Err}}",
+		"clone.incomplete":                       "\nThe repository was cloned to {{quote .Dir}}, but some Git LFS objects could not be downloaded.\nAny files which need them were left as pointers. To download them, run `{{.Command}}` in that directory.",
+		"clone.shallow-config-failed":            "Unable to set {{.Key}} in shallow clone: {{.Err}}",
+		"clone.submodules-pull-failed":           "Error performing 'git lfs pull' for submodules: {{.Err}}",
+		"clone.working-dir":                      "Unable to derive current working dir: {{.Err}}",
+		"common.access-times-failed":             "Could not read when objects were last accessed: {{.Err}}",
+		"common.cleanup-failed":                  "Error clearing old temp files: {{.Err}}",
+		"common.could-not-scan":                  "Could not scan for Git LFS files",
+		"common.create-failed":                   "Could not create {{.Path}}: {{.Err}}",
+		"common.error":                           "Error: {{.Err}}",
+		"common.errors-logged":                   "\nErrors logged to {{.Path}}\nUse `git lfs logs last` to view the log.",
+		"common.failed-to-fetch":                 "error: failed to fetch some objects from '{{.URL}}'",
+		"common.git-version-error":               "Error getting git version: {{.Err}}",
+		"common.git-version-too-old":             "git version >= {{.Minimum}} is required for Git LFS, your version: {{.Version}}\nUpgrade Git, and make sure that the new version is the first one on your PATH.",
+		"common.invalid-max-object-size":         "Invalid value for lfs.maxobjectsize: {{quote .Value}}",
+		"common.invalid-remote":                  "Invalid remote name {{quote .Remote}}: {{.Err}}",
+		"common.lock-system":                     "Unable to create lock system: {{.Err}}",
+		"common.not-in-repo":                     "Not in a git repository.",
+		"common.not-in-work-tree":                "This operation must be run in a work tree.",
+		"common.open-failed":                     "Could not open {{.Path}}: {{.Err}}",
+		"common.panic-log-failed":                "Unable to log panic to {{.Dir}}: {{.Err}}\n",
+		"common.panic-log-file-failed":           "Unable to log panic to {{.Path}}\n",
+		"common.progress-format":                 "Invalid progress format {{quote .Format}}: must be text or json",
+		"common.quarantined":                     "{{.Count}} file(s) were rejected by lfs.postsmudgecheck, and left as pointers:",
+		"common.quiet-with-verbose":              "Cannot combine --quiet with --verbose",
+		"common.read-failed":                     "Could not read {{.Path}}: {{.Err}}",
+		"common.read-objects-failed":             "Could not read every object: {{.Err}}",
+		"common.scan-tree-failed":                "Could not scan for Git LFS tree: {{.Err}}",
+		"common.scanner-error":                   "Scanner error: {{.Err}}",
+		"common.temp-dir":                        "{{.Err}}\nSet lfs.tmpdir to a writable directory, ideally on the same filesystem as {{.Dir}}.",
+		"common.warning":                         "WARNING: {{.Err}}",
+		"common.write-failed":                    "Could not write {{.Path}}: {{.Err}}",
+		"completion.unknown-shell":               "Unknown shell {{quote .Shell}}: must be bash, zsh, or fish",
+		"completion.usage":                       "Usage: git lfs completion (bash | zsh | fish)",
+		"count-objects.read-failed":              "Could not read {{.Dir}}: {{.Err}}",
+		"dedup.dirty":                            "Working tree is dirty. Please commit or reset your change.",
+		"dedup.disabled":                         "De-duplication is disabled by lfs.dedup.",
+		"dedup.extensions-configured":            "This platform supports file de-duplication, however, Git LFS extensions are configured and therefore de-duplication can not be used.",
+		"dedup.finished":                         "\n\nSuccessfully finished.\n  De-duplicated  size: {{.Size}} bytes\n                count: {{.Count}}",
+		"dedup.ok":                               "OK: This platform and repository support file de-duplication.",
+		"dedup.skipped":                          "Skipped: {{.Name}} (Size: {{.Size}})",
+		"dedup.skipped-error":                    "Skipped: {{.Name}} (Size: {{.Size}})\n          {{.Err}}",
+		"dedup.success":                          "Success: {{.Name}} (Size: {{.Size}})",
+		"dedup.unsupported":                      "This system does not support deduplication.",
+		"dedup.unsupported-reason":               "This system does not support deduplication. {{.Err}}",
+		"diff-pointer.usage":                     "Usage: git lfs diff-pointer <before-pointer-file> <after-pointer-file>",
+		"diff.invalid-max-size":                  "Invalid value for lfs.binarydiffmaxsize: {{quote .Value}}",
+		"diff.too-large":                         "Skipping {{.Name}}: larger than lfs.binarydiffmaxsize ({{.Max}})",
+		"diff.usage":                             "Usage: git lfs diff [options] [<commit> [<commit>]] [-- <path>...]",
+		"du.access-time-failed":                  "Could not determine when {{.Oid}} was last accessed: {{.Err}}",
+		"du.by-directory":                        "\nBy directory:",
+		"du.by-extension":                        "\nBy extension:",
+		"du.by-last-access":                      "\nBy last access:",
+		"du.error":                               "du error: {{.Err}}",
+		"du.invalid-depth":                       "Invalid --by-dir depth {{.Depth}}: must be at least 1",
+		"du.kept-objects-failed":                 "Could not find the objects which prune would keep",
+		"du.size-in-files":                       "{{.Size}} in {{.Count}} file(s)",
+		"exit-codes.auth":                        "The Git LFS server rejected the credentials, or needed some.",
+		"exit-codes.corrupt":                     "An object did not have the contents its OID records.",
+		"exit-codes.failure":                     "A check made by the command, such as git lfs fsck, failed.",
+		"exit-codes.footer":                      "If a command fails for more than one of these reasons, it exits with the code\nfor the first failure it reports.",
+		"exit-codes.header":                      "git-lfs exits with one of the following codes:",
+		"exit-codes.network":                     "The Git LFS server could not be reached.",
+		"exit-codes.not-a-repo":                  "The command must be run in a Git repository, or a work tree.",
+		"exit-codes.not-found":                   "An object, or the repository, was not found.",
+		"exit-codes.success":                     "The command succeeded.",
+		"exit-codes.usage":                       "The command was used incorrectly, or failed for another reason.",
+		"expire-locks.expired":                   "Expired {{.Lock}}",
+		"expire-locks.expired-count":             "Expired {{.Count}} of {{.Total}} lock(s) older than {{.OlderThan}}",
+		"expire-locks.failed":                    "Unable to expire {{.Lock}}: {{.Err}}",
+		"expire-locks.failed-count":              "Failed to expire {{.Count}} lock(s)",
+		"expire-locks.invalid-older-than":        "Invalid --older-than: {{.Err}}",
+		"expire-locks.would-expire":              "Would expire {{.Lock}}",
+		"expire-locks.would-expire-count":        "Would expire {{.Count}} of {{.Total}} lock(s) older than {{.OlderThan}}",
+		"export-objects.all-with-refs":           "Cannot combine --all with refs",
+		"export-objects.exported":                "Exported {{.Count}} object(s), {{.Size}} to {{.Dir}}",
+		"export-objects.failed":                  "Could not export {{.Oid}}: {{.Err}}",
+		"export-objects.failed-count":            "{{.Count}} object(s) could not be exported",
+		"export-objects.invalid-since":           "Invalid --since: {{.Err}}",
+		"export-objects.link-with-compress":      "Cannot combine --link with --compress",
+		"export-objects.manifest-failed":         "Could not write the manifest: {{.Err}}",
+		"export-objects.missing-count":           "{{.Count}} object(s) were missing locally and not exported; run `git lfs fetch` first",
+		"export-objects.missing-object":          "Missing object {{.Oid}} ({{.Name}}), not exported",
+		"export-objects.output-with-archive":     "Cannot combine --output with an archive or refs",
+		"export-objects.requires-output":         "--compress, --since and --link require --output",
+		"export-objects.unsupported-compress":    "Unsupported --compress format {{quote .Format}}; only gzip is supported",
+		"export-objects.usage":                   "Usage: git lfs export-objects [--all] <file.tar> [<ref>...]\n       git lfs export-objects --output=<dir> [--compress[=gzip] | --link] [--since=<date>]",
+		"fetch.all-with-include-exclude":         "Cannot combine --all with --include or --exclude",
+		"fetch.all-with-recent":                  "Cannot combine --all with --recent",
+		"fetch.checkpoint-skipped":               "fetch: Skipped {{.Count}} object(s) already fetched according to {{.Path}}",
+		"fetch.checkpoint-update-error":          "fetch: could not update checkpoint {{.Path}}: {{.Err}}",
+		"fetch.could-not-fetch":                  "Could not fetch",
+		"fetch.could-not-scan-commits":           "Couldn't scan commits at {{.Ref}}: {{.Err}}",
+		"fetch.could-not-scan-previous":          "Could not scan for Git LFS previous versions",
+		"fetch.could-not-scan-recent":            "Could not scan for recent refs",
+		"fetch.fetching-all":                     "fetch: Fetching all references...",
+		"fetch.fetching-recent-branches":         "fetch: Fetching recent branches within {{.Days}} days",
+		"fetch.fetching-recent-changes":          "fetch: Fetching changes within {{.Days}} days of {{.Ref}}",
+		"fetch.fetching-ref":                     "fetch: Fetching reference {{.Ref}}",
+		"fetch.ignoring-global-include-exclude":  "Ignoring global include / exclude paths to fulfil --all",
+		"fetch.invalid-limit-bytes":              "Invalid --limit-bytes {{quote .Value}}: {{.Err}}",
+		"fetch.invalid-ref":                      "Invalid ref argument: {{.Refs}}",
+		"fetch.limit-reached":                    "Fetched {{.Fetched}} of {{.Total}} objects ({{.FetchedBytes}}/{{.TotalBytes}}). Limit reached. Run 'git lfs fetch' again to continue.",
+		"filter-process.debug-file-failed":       "Could not open lfs.filterprotocoldebugfile {{quote .Path}}, logging to standard error instead: {{.Err}}",
+		"filter-process.malformed-on-windows":    "Encountered {{.Count}} file(s) that may not have been copied correctly on Windows:",
+		"filter-process.not-pointers":            "Encountered {{.Count}} file(s) that should have been pointers, but weren't:",
+		"filter-process.see-help-smudge":         "\nSee: `git lfs help smudge` for more details.",
+		"free-space.invalid-min":                 "Invalid value for lfs.minfreespace: {{quote .Value}}",
+		"fsck.bad-permissions":                   "{{.Path}} has permissions {{.Mode}}, but core.sharedRepository requires {{.Required}}",
+		"fsck.check-failed":                      "Error checking Git LFS files",
+		"fsck.check-object-failed":               "Object {{.Name}} ({{.Oid}}) could not be checked: {{.Err}}",
+		"fsck.checking-remote":                   "Checking {{.Count}} object(s) on {{.Remote}}",
+		"fsck.corrupt":                           "Object {{.Name}} ({{.Oid}}) is corrupt",
+		"fsck.corrupt-oid":                       "Object {{.Oid}} is corrupt",
+		"fsck.count-failed":                      "Could not count the objects in the local store: {{.Err}}",
+		"fsck.downloaded":                        "Downloaded {{.Count}} object(s)",
+		"fsck.downloading":                       "Downloading {{.Count}} object(s) from {{.Remote}}",
+		"fsck.empty":                             "Object {{.Oid}} is empty",
+		"fsck.fix-permissions-failed":            "Could not change the permissions of {{.Path}}: {{.Err}}",
+		"fsck.fixed-permissions":                 "Fixed the permissions of {{.Count}} file(s) and directories",
+		"fsck.hard-links":                        "\tIt has {{.Count}} hard links, so it may have been modified through a working tree file linked to it",
+		"fsck.introduced-by":                     "\t\tintroduced by {{.Commit}}",
+		"fsck.misplaced":                         "Object {{.Oid}} is misplaced at {{.Path}}",
+		"fsck.missing":                           "Object {{.Name}} ({{.Oid}}) is missing",
+		"fsck.missing-from-remote":               "\nObjects missing from {{.Remote}}:",
+		"fsck.moved":                             "Moved {{.From}} to {{.To}}",
+		"fsck.moving-corrupt":                    "Moving corrupt objects to {{.Dir}}",
+		"fsck.moving-invalid":                    "Moving invalid files to {{.Dir}}",
+		"fsck.not-a-pointer":                     "File {{.Path}} should have been a pointer, but was not",
+		"fsck.not-an-object":                     "File {{.Path}} in the object store is not a Git LFS object",
+		"fsck.ok":                                "Git LFS fsck OK",
+		"fsck.referenced-by":                     "\t\treferenced by {{.Commits}}",
+		"fsck.save-state-failed":                 "Could not save the fsck state: {{.Err}}",
+		"fsck.size-mismatch":                     "Object {{.Name}} ({{.Oid}}) has a size mismatch: it is {{.Size}} bytes, but its pointer records {{.PointerSize}} bytes",
+		"fsck.skipped":                           "Skipped {{.Count}} object(s) unchanged since they were last verified",
+		"fsck.state-invalid":                     "The fsck state is invalid, so every object will be verified",
+		"fsck.state-unreadable":                  "Could not read the fsck state, so every object will be verified: {{.Err}}",
+		"fsck.trailing-data":                     "\tIts first {{.PointerSize}} bytes match, so it has {{.Extra}} bytes of trailing data",
+		"fsck.unrecoverable":                     "\nUnrecoverable objects, which {{.Remote}} could not provide:",
+		"fsck.verify-remote-usage":               "Usage: git lfs fsck --verify-remote [--remote <name>] [<ref>]",
+		"fsck.would-download":                    "Would download {{.Name}} ({{.Oid}})",
+		"fsck.would-fix-permissions":             "Would change the permissions of {{.Path}} to {{.Mode}}",
+		"fsck.would-move":                        "Would move {{.From}} to {{.To}}",
+		"fsck.wrong-size":                        "\tIts contents match, so its pointer records the wrong size",
+		"hooks.kept":                             "Kept {{.Hook}} hook, which was not installed by Git LFS.",
+		"hooks.outdated":                         "WARNING: the {{.Hooks}} hook(s) in {{.Dir}} were written by an older version of Git LFS and could not be upgraded.\nRun `git lfs update` to upgrade them.",
+		"hooks.post-checkout-usage":              "This should be run through Git's post-checkout hook.  Run `git lfs update` to install it.",
+		"hooks.post-merge-usage":                 "This should be run through Git's post-merge hook.  Run `git lfs update` to install it.",
+		"hooks.pre-push-usage":                   "This should be run through Git's pre-push hook.  Run `git lfs update` to install it.",
+		"hooks.removed":                          "Removed {{.Hook}} hook.",
+		"hooks.removed-chained":                  "Removed {{.Hook}} hook, and restored the hook it ran.",
+		"import-objects.failed":                  "Could not import {{.Oid}}: {{.Err}}",
+		"import-objects.failed-count":            "{{.Count}} object(s) could not be imported",
+		"import-objects.imported":                "Imported {{.Count}} object(s), {{.Size}}; skipped {{.Present}} already present; {{.Invalid}} invalid",
+		"import-objects.invalid":                 "Invalid object {{.Path}}: {{.Err}}",
+		"import-objects.missing-from-archive":    "Object {{.Oid}} is listed in the manifest but missing from the archive",
+		"import-objects.not-named-by-oid":        "Invalid object {{.Path}}: not a file named by its OID",
+		"import-objects.size-mismatch":           "Object {{.Oid}} has size {{.Size}}, expected {{.Expected}} from the manifest; not imported",
+		"import-objects.unexpected-entry":        "Skipping unexpected entry {{quote .Name}}",
+		"import-objects.usage":                   "Usage: git lfs import-objects [--dry-run] <file.tar | directory>",
+		"import-objects.would-import":            "Would import {{.Count}} object(s), {{.Size}}; skipped {{.Present}} already present; {{.Invalid}} invalid",
+		"install.add-to-path":                    "Add {{.Dir}} to PATH, or link git-lfs into a directory which is on it.",
+		"install.global-with-others":             "Only one of --global and --local, --worktree or --system options can be specified.",
+		"install.initialized":                    "Git LFS initialized.",
+		"install.local-with-system":              "Only one of --local and --system options can be specified.",
+		"install.local-with-worktree":            "Only one of --local and --worktree options can be specified.",
+		"install.macos-path":                     "On macOS, applications started from the Dock or Finder do not use the PATH set by your shell, so git-lfs may need to be in a directory such as /usr/local/bin.",
+		"install.not-on-path":                    "WARNING: Git could not run git-lfs from PATH, so the Git LFS filters and hooks will fail.",
+		"install.not-root":                       "WARNING: current user is not root/admin, system install is likely to fail.",
+		"install.use-force":                      "Run `git lfs install --force` to reset git config.",
+		"install.worktree-config-enabled":        "Enabled the worktreeConfig extension for this repository.",
+		"install.worktree-with-system":           "Only one of --worktree and --system options can be specified.",
+		"lock-verify.add-lock-failed":            "WARNING: error adding {{quote .Path}} lock for ref {{quote .Ref}}: {{.Err}}",
+		"lock-verify.auth-error":                 "ERROR: Authentication error: {{.Err}}",
+		"lock-verify.auth-warning":               "WARNING: Authentication error: {{.Err}}",
+		"lock-verify.disable-command":            "  $ git config lfs.{{.Endpoint}}.locksverify false",
+		"lock-verify.enable-command":             "  $ git config lfs.{{.Endpoint}}.locksverify true",
+		"lock-verify.supported":                  "Locking support detected on remote {{quote .Remote}}. Consider enabling it with:",
+		"lock-verify.unsupported":                "Remote {{quote .Remote}} does not support the LFS locking API. Consider disabling it with:",
+		"lock.failed":                            "Lock failed: {{.Err}}",
+		"lock.locked":                            "Locked {{.Path}}",
+		"lock.usage":                             "Usage: git lfs lock <path>",
+		"locks.cached-filters":                   "--cached option can't be combined with filters",
+		"locks.cached-limit":                     "--cached option can't be combined with --limit",
+		"locks.cached-local":                     "--cached option can't be combined with --local",
+		"locks.filters":                          "Error building filters: {{.Err}}",
+		"locks.retrieve-failed":                  "Error while retrieving locks: {{.Err}}",
+		"locks.verify-filters":                   "--verify option can't be combined with filters",
+		"locks.verify-local":                     "--verify option can't be combined with --local",
+		"logs.clear-failed":                      "Error clearing {{.Dir}}",
+		"logs.cleared":                           "Cleared {{.Dir}}",
+		"logs.none":                              "No logs to show",
+		"logs.read-failed":                       "Error reading log: {{.Name}}",
+		"logs.supply-name":                       "Supply a log name.",
+		"ls-files.all-with-ref":                  "fatal: cannot use --all with explicit reference",
+		"ls-files.deleted-with-range":            "fatal: cannot use --deleted with reference range",
+		"ls-files.did-you-mean-all":              "fatal: did you mean \"git lfs ls-files --all --\" ?",
+		"ls-files.examine-failed":                "Could not examine the object of {{.Name}}: {{.Err}}",
+		"ls-files.scan-history-failed":           "Could not scan for Git LFS history: {{.Err}}",
+		"ls-files.scan-index-failed":             "Could not scan for Git LFS index: {{.Err}}",
+		"migrate.already-pointer":                "migrate: {{.File}} is already a Git LFS pointer, skipping",
+		"migrate.dirty":                          "migrate: working copy must not be dirty",
+		"migrate.nothing-to-convert":             "migrate: nothing to convert",
+		"migrate.objects-not-found":              "fatal: unable to find {{.Count}} Git LFS object(s) locally or on the remote:\n{{.Objects}}",
+		"migrate.override-prompt":                "migrate: override changes in your working copy? [Y/n] ",
+		"migrate.overriding":                     "migrate: changes in your working copy will be overridden ...",
+		"object-stats.average":                   "average",
+		"object-stats.count":                     "count",
+		"object-stats.extension":                 "extension",
+		"object-stats.invalid-sort-by":           "Invalid --sort-by {{quote .Value}}: must be one of size, count, or extension",
+		"object-stats.list-refs-failed":          "Could not list local refs: {{.Err}}",
+		"object-stats.referenced-in":             "referenced in",
+		"object-stats.refs":                      "{{.Count}} ref(s)",
+		"object-stats.size":                      "size",
+		"pointer.blob-oid":                       "\nGit blob OID: {{.Oid}}",
+		"pointer.for-file":                       "Git LFS pointer for {{.File}}\n",
+		"pointer.from-file":                      "Pointer from {{.File}}\n",
+		"pointer.mismatch":                       "\nPointers do not match",
+		"pointer.nothing-to-do":                  "Nothing to do!",
+		"post-checkout.diff-failed":              "Warning: post-checkout rev diff {{.Pre}}:{{.Post}} failed: {{.Err}}\nFalling back on full scan.",
+		"post-checkout.locks-failed":             "Warning: post-checkout locked file check failed: {{.Err}}",
+		"post-checkout.mismatch":                 "{{.Name}} does not match its Git LFS object {{.Oid}} after checkout",
+		"post-checkout.some-failed":              "Some files did not check out correctly: run `git lfs fsck` to check the local objects, and check them out again",
+		"post-checkout.verify-failed":            "Could not verify {{.Name}}: {{.Err}}",
+		"post-commit.failed":                     "Warning: post-commit failed: {{.Err}}",
+		"post-commit.locks-failed":               "Warning: post-commit locked file check failed: {{.Err}}",
+		"post-merge.locks-failed":                "Warning: post-merge locked file check failed: {{.Err}}",
+		"pre-commit.invalid-max-commit-size":     "Invalid value for lfs.maxcommitsize: {{quote .Value}}",
+		"pre-commit.larger-than-max-object-size": "\nThe above Git LFS file(s) are larger than lfs.maxobjectsize ({{.Max}}), and are likely to be rejected when pushed.",
+		"pre-commit.or-no-verify":                "or commit them anyway with `git commit --no-verify`.",
+		"pre-commit.remove-or-no-verify":         "Remove them from the commit, or commit them anyway with `git commit --no-verify`.",
+		"pre-commit.too-large":                   "\nThe above file(s) are larger than lfs.maxcommitsize ({{.Max}}) and are not tracked by Git LFS.",
+		"pre-commit.track-example":               "  git lfs track {{quote .Pattern}}\n",
+		"pre-commit.track-them":                  "Track them with Git LFS before committing, for example:\n",
+		"proxy.listen-failed":                    "Could not listen on {{.Address}}: {{.Err}}",
+		"proxy.parent-exited":                    "Parent process exited, stopping proxy",
+		"proxy.proxying":                         "Proxying at http://{{.Address}}",
+		"prune.error":                            "Prune error: {{.Err}}",
+		"prune.failed":                           "Prune failed, see errors above",
+		"prune.invalid-not-accessed-in":          "Invalid --not-accessed-in duration: {{.Err}}",
+		"prune.missing-on-remote":                "Abort: these objects to be pruned are missing on remote:\n{{.Objects}}",
+		"prune.shared-storage":                   "Not pruning {{.Dir}}, since it may be shared with other repositories through lfs.storage.\nRun `git lfs prune --force-shared` to prune it anyway, deleting any objects which only other repositories refer to.",
+		"prune.shared-storage-skipped":           "WARNING: not pruning {{.Dir}}, since it may be shared with other repositories through lfs.storage.\nRun `git lfs prune --force-shared` to prune it anyway.",
+		"prune.sub-tasks-failed":                 "Prune sub-tasks failed, cannot continue",
+		"prune.verify-remote-conflict":           "Cannot specify both --verify-remote and --no-verify-remote",
+		"pull.checkout-error":                    "Checkout error: {{.Err}}",
+		"pull.convert-paths-failed":              "Could not convert file paths",
+		"pull.failed":                            "Could not pull",
+		"pull.not-installed":                     "Skipping object checkout, Git LFS is not installed.",
+		"pull.skipped-not-local":                 "Skipped checkout for {{quote .Name}}, content not local. Use fetch to download.",
+		"pull.update-index-error":                "Error updating the git index:\n{{.Output}}",
+		"pull.update-index-failed":               "Could not update the index",
+		"push.local-refs-error":                  "Error getting local refs.",
+		"push.locked-files":                      "Unable to push locked files:",
+		"push.locked-files-error":                "ERROR: Cannot update locked files.",
+		"push.locked-files-warning":              "WARNING: The above files would have halted this push.",
+		"push.missing-hint":                      "hint: Your push was rejected due to missing or corrupt local objects.\nhint: You can disable this check with: 'git config lfs.allowincompletepush true'",
+		"push.object-id-usage":                   "Usage: git lfs push --object-id <remote> <lfs-object-id> [lfs-object-id] ...",
+		"push.specify-remote":                    "Specify a remote and a remote branch name (`git lfs push origin master`)",
+		"push.unlock-suggestion":                 "Consider unlocking your own locked files: (`git lfs unlock <path>`)",
+		"push.upload-failed":                     "LFS upload failed:",
+		"push.upload-missing-objects":            "LFS upload missing objects:",
+		"push.validate-all-present":              "All {{.Count}} object(s) present on remote.",
+		"push.validate-check-failed":             "error: could not check objects on '{{.Remote}}'",
+		"push.validate-corrupt-object":           "  (corrupt) {{.Name}} ({{.Oid}})",
+		"push.validate-missing":                  "LFS objects missing on remote:",
+		"push.validate-missing-count":            "{{.Missing}} of {{.Count}} object(s) missing on remote.",
+		"push.validate-missing-object":           "  (missing) {{.Name}} ({{.Oid}})",
+		"push.validate-only-with-object-id":      "Cannot combine --validate-only with --object-id",
+		"push.validate-specify-ref":              "Specify a ref to validate (`git lfs push --validate-only origin main`)",
+		"reset.all-with-paths":                   "Cannot use --all with paths.",
+		"reset.modified":                         "Skipping {{quote .Name}}, which has been modified. Use --force to overwrite it.",
+		"reset.no-match":                         "No Git LFS files in HEAD match the given paths.",
+		"reset.not-on-remote":                    "Keeping {{.Count}} object(s) which {{quote .Remote}} does not have, since they could not be downloaded again. Use --force to remove them anyway.",
+		"reset.removed":                          "Removed {{.Count}} object(s) ({{.Size}}) from the local cache",
+		"reset.shared-storage":                   "Not resetting {{.Dir}}, since it may be shared with other repositories through lfs.storage.",
+		"reset.usage":                            "Usage: git lfs reset [--hard [--force]] [--all | <path>...]",
+		"run.crashed":                            "git-lfs has crashed: {{.Err}}",
+		"run.http-stats-failed":                  "Error logging http stats: {{.Err}}",
+		"run.metrics-failed":                     "Error writing metrics to {{.Path}}: {{.Err}}",
+		"run.no-usage":                           "Sorry, no usage text found for {{quote .Command}}",
+		"run.timings-failed":                     "Error writing timings: {{.Err}}",
+		"serve.invalid-storage":                  "Invalid --storage {{quote .Storage}}: {{.Err}}",
+		"serve.read-auth-failed":                 "Could not read --auth file: {{.Err}}",
+		"serve.serving":                          "Serving objects from {{.Dir}} at http://{{.Address}}/<repo>/info/lfs",
+		"size.invalid-range":                     "Invalid range {{quote .Range}}: must be <from>..<to>",
+		"size.ref-with-range":                    "Cannot use both a ref and --range",
+		"size.total":                             "{{.Count}} objects, {{.Size}} bytes ({{.HumanSize}})",
+		"size.usage":                             "Usage: git lfs size [<ref>] [--range=<from>..<to>] [--json]",
+		"smudge.download-failed":                 "Error downloading object: {{.Name}} ({{.Oid}}): {{.Err}}",
+		"smudge.malformed-on-windows":            "Possibly malformed smudge on Windows: see `git lfs help smudge` for more info.",
+		"smudge.write-failed":                    "Could not write {{quote .Path}}: {{.Err}}",
+		"status.not-staged":                      "\nObjects not staged for commit:\n",
+		"status.on-branch":                       "On branch {{.Branch}}",
+		"status.scan-failed":                     "Could not scan for Git LFS objects",
+		"status.sizes-with-format":               "Cannot combine --sizes with --porcelain or --json",
+		"status.to-be-committed":                 "\nObjects to be committed:\n",
+		"status.to-be-pushed":                    "Objects to be pushed to {{.Remote}}:\n",
+		"status.total":                           "\nTotal: {{.Total}}",
+		"sync.checking-push":                     "sync: Checking for objects to push to {{.Remote}}",
+		"sync.fetch-failed":                      "error: failed to fetch some objects from {{.Remote}}",
+		"sync.fetching":                          "sync: Fetching recent objects from {{.Remote}}",
+		"sync.local-objects":                     "sync: {{.Before}} local object(s) before, {{.After}} after",
+		"sync.may-push":                          "sync: {{.Count}} object(s) may need to be pushed",
+		"sync.summary":                           "sync: Pushed {{.Pushed}} object(s), fetched {{.Fetched}} object(s)",
+		"trace.create-failed":                    "Could not create trace log: {{.Err}}",
+		"trace.run-failed":                       "Could not run git {{.Command}}: {{.Err}}",
+		"trace.usage":                            "Usage: git lfs trace -- <git-command> [<args>...]",
+		"trace.write-failed":                     "Could not write trace log {{.Path}}: {{.Err}}",
+		"trace.written":                          "Trace written to {{.Path}}",
+		"track.already-supported":                "{{quote .Pattern}} already supported",
+		"track.attributes-is-dir":                "Error: .gitattributes is a directory; remove or rename it and try again.",
+		"track.attributes-not-writable":          "Error: cannot write to .gitattributes: {{.Err}}. Check file permissions.",
+		"track.attributes-open-error":            "Error opening .gitattributes file",
+		"track.attributes-read-error":            "Error reading .gitattributes file",
+		"track.forbidden-file":                   "Pattern {{.Pattern}} matches forbidden file {{.File}}. If you would like to track {{.File}}, modify .gitattributes manually.",
+		"track.found":                            "Found {{.Count}} files previously added to Git matching pattern: {{.Pattern}}",
+		"track.listing-excluded":                 "Listing excluded patterns",
+		"track.listing-lockable-pattern":         "    {{.Pattern}} [lockable] ({{.Source}})",
+		"track.listing-tracked":                  "Listing tracked patterns",
+		"track.lockable-permissions-error":       "Error changing lockable file permissions: {{.Err}}",
+		"track.mark-modified-error":              "Error marking {{quote .File}} modified: {{.Err}}",
+		"track.not-a-repo":                       "Not a git repository.",
+		"track.outside-work-tree":                "Current directory {{quote .Dir}} outside of git working directory {{quote .WorkTree}}.",
+		"track.searching":                        "Searching for files matching pattern: {{.Pattern}}",
+		"track.touching":                         "Git LFS: touching {{quote .File}}",
+		"track.tracked-files-error":              "Error getting tracked files for {{quote .Pattern}}: {{.Err}}",
+		"track.tracking":                         "Tracking {{quote .Pattern}}",
+		"uninstall.global-removed":               "Global Git LFS configuration has been removed.",
+		"uninstall.hooks-removed":                "Hooks for this repository have been removed.",
+		"uninstall.pointers-warning":             "WARNING: files stored as Git LFS pointers will no longer be replaced with their contents on checkout. Run `git lfs install` to restore this.",
+		"uninstall.removed":                      "Removed {{.Keys}} from the {{.Scope}} Git config.",
+		"uninstall.system-removed":               "System Git LFS configuration has been removed.",
+		"unlock.determine-path":                  "Unable to determine path: {{.Err}}",
+		"unlock.failed":                          "Unable to unlock {{.Path}}: {{.Err}}",
+		"unlock.force-uncommitted":               "Warning: unlocking with uncommitted changes because --force",
+		"unlock.uncommitted":                     "Cannot unlock file with uncommitted changes",
+		"unlock.unlocked":                        "Unlocked {{.Path}}",
+		"unlock.unlocked-id":                     "Unlocked Lock {{.ID}}",
+		"unlock.usage":                           "Usage: git lfs unlock (--id my-lock-id | --oid sha256:my-oid | <path>)",
+		"untrack.attributes-open-error":          "Error opening .gitattributes for writing",
+		"untrack.untracking":                     "Untracking {{quote .Pattern}}",
+		"untrack.usage":                          "git lfs untrack <path> [path]*",
+		"update.access-removed":                  "Removed invalid {{.Key}} access of {{.Value}}.",
+		"update.access-updated":                  "Updated {{.Key}} access from {{.From}} to {{.To}}.",
+		"update.force-with-manual":               "You cannot use --force and --manual options together",
+		"update.how-to-resolve":                  "To resolve this, either:\n  1: run `git lfs update --manual` for instructions on how to merge hooks.\n  2: run `git lfs update --force` to overwrite your hook.",
+		"update.pushes-need-hooks":               "Pushes will not upload Git LFS objects until these hooks are added.\n\n{{.Steps}}",
+		"update.updated":                         "Updated git hooks.",
+		"version.galactus":                       "Nothing may see Gah Lak Tus and survive!",
+		"version.git-commit":                     "git commit: {{.Commit}}",
+		"version.go-version":                     "go version: {{.Version}}",
+		"version.min-version-ignored":            "warning: ignoring lfs.minclientversion: {{.Err}}",
+		"version.os-arch":                        "os/arch: {{.OS}}/{{.Arch}}",
+		"version.too-old-error":                  "error: this repository requires Git LFS {{.Minimum}} or newer, but this is Git LFS {{.Version}}. Please upgrade.",
+		"version.too-old-warning":                "warning: this repository requires Git LFS {{.Minimum}} or newer, but this is Git LFS {{.Version}}. Please upgrade.",
+		"version.vendor":                         "vendor: {{.Vendor}}",
+		"version.version":                        "version: {{.Version}}",
+		"worktree-prune.in-use-failed":           "Could not find every object which is still in use; nothing was pruned",
+		"worktree-prune.pruned":                  "worktree-prune: pruned {{.Count}} object(s) ({{.Size}})",
+		"worktree-prune.would-prune":             "worktree-prune: {{.Count}} object(s) would be pruned ({{.Size}})",
+	},
+}
@@ -0,0 +1,167 @@
+// Package tr translates the messages which Git LFS prints for people to read
+// into their own language.
+//
+// Each message has an ID, such as "track.tracking", and an English text in
+// the catalog in locales/en.json. The text is a Go template, which refers to
+// the arguments of the message by name, such as "Tracking {{quote .Pattern}}".
+// Other files in the locales directory translate some or all of the messages
+// into another language; any message they leave out is printed in English.
+//
+// The locale files are compiled into locales_gen.go by running 'go generate'
+// on this package, which must be done whenever one of them changes.
+//
+// Output which is read by programs, such as JSON, must not be translated, and
+// so must not be printed through this package.
+package tr
+
+//go:generate go run localegen.go
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Args are the arguments of a message, which its text refers to by name.
+type Args map[string]interface{}
+
+// DefaultLanguage is the language of the message catalog, which is used for
+// any message which has not been translated.
+const DefaultLanguage = "en"
+
+var (
+	language string
+
+	templatesMu sync.Mutex
+	templates   = make(map[string]*template.Template)
+
+	funcs = template.FuncMap{
+		// quote quotes a value as the %q verb of the fmt package
+		// does.
+		"quote": func(v interface{}) string {
+			return strconv.Quote(fmt.Sprint(v))
+		},
+	}
+)
+
+func init() {
+	SetLanguage(LanguageFromEnv(os.Getenv))
+}
+
+// LanguageFromEnv returns the language of the messages to print, according to
+// the LC_ALL, LC_MESSAGES and LANG environment variables, in that order, as
+// returned by "getenv". A locale such as "de_DE.UTF-8" selects the "de_DE"
+// translation if there is one, and otherwise the "de" translation. If there
+// is no translation for the locale, DefaultLanguage is returned.
+func LanguageFromEnv(getenv func(string) string) string {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = getenv(name); len(locale) > 0 {
+			break
+		}
+	}
+
+	// Drop the character set and modifier, such as in "de_DE.UTF-8" or
+	// "de_DE@euro".
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	if _, ok := locales[locale]; ok && len(locale) > 0 {
+		return locale
+	}
+	if i := strings.IndexByte(locale, '_'); i > 0 {
+		if _, ok := locales[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+	return DefaultLanguage
+}
+
+// SetLanguage sets the language in which messages are printed. A language
+// with no translation prints every message in English.
+func SetLanguage(lang string) {
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+
+	language = lang
+}
+
+// Has returns whether the catalog has a message with the given ID.
+func Has(id string) bool {
+	_, ok := locales[DefaultLanguage][id]
+	return ok
+}
+
+// Tr returns the message with the given ID in the current language, with its
+// arguments filled in from "args". A message which has not been translated, or
+// whose translation cannot be used, is returned in English. The ID itself is
+// returned if there is no such message, so that it is still clear what went
+// wrong.
+func Tr(id string, args ...Args) string {
+	var merged Args
+	if len(args) > 0 {
+		merged = make(Args)
+		for _, a := range args {
+			for k, v := range a {
+				merged[k] = v
+			}
+		}
+	}
+
+	templatesMu.Lock()
+	lang := language
+	templatesMu.Unlock()
+
+	if lang != DefaultLanguage {
+		if s, ok := execute(lang, id, merged); ok {
+			return s
+		}
+	}
+	if s, ok := execute(DefaultLanguage, id, merged); ok {
+		return s
+	}
+	return id
+}
+
+func execute(lang, id string, args Args) (string, bool) {
+	tmpl, ok := lookup(lang, id)
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// lookup returns the parsed template of the message "id" in the language
+// "lang", parsing and caching it the first time it is used.
+func lookup(lang, id string) (*template.Template, bool) {
+	text, ok := locales[lang][id]
+	if !ok {
+		return nil, false
+	}
+
+	key := lang + "\x00" + id
+
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+
+	if tmpl, ok := templates[key]; ok {
+		return tmpl, tmpl != nil
+	}
+
+	tmpl, err := template.New(id).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		tmpl = nil
+	}
+	templates[key] = tmpl
+	return tmpl, tmpl != nil
+}
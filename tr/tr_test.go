@@ -0,0 +1,152 @@
+package tr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withLanguage(lang string, fn func()) {
+	old := language
+	SetLanguage(lang)
+	defer SetLanguage(old)
+
+	fn()
+}
+
+func TestLanguageFromEnv(t *testing.T) {
+	for desc, c := range map[string]struct {
+		Env      map[string]string
+		Expected string
+	}{
+		"unset":            {map[string]string{}, "en"},
+		"C locale":         {map[string]string{"LANG": "C"}, "en"},
+		"language":         {map[string]string{"LANG": "de"}, "de"},
+		"territory":        {map[string]string{"LANG": "de_DE"}, "de"},
+		"charset":          {map[string]string{"LANG": "de_AT.UTF-8"}, "de"},
+		"modifier":         {map[string]string{"LANG": "de_DE@euro"}, "de"},
+		"untranslated":     {map[string]string{"LANG": "xx_YY.UTF-8"}, "en"},
+		"LC_MESSAGES wins": {map[string]string{"LANG": "en_US", "LC_MESSAGES": "de_DE"}, "de"},
+		"LC_ALL wins":      {map[string]string{"LC_MESSAGES": "de_DE", "LC_ALL": "en_GB"}, "en"},
+	} {
+		lang := LanguageFromEnv(func(name string) string {
+			return c.Env[name]
+		})
+		assert.Equal(t, c.Expected, lang, desc)
+	}
+}
+
+func TestTrDefaultLanguage(t *testing.T) {
+	withLanguage(DefaultLanguage, func() {
+		assert.Equal(t, `Tracking "*.dat"`, Tr("track.tracking", Args{"Pattern": "*.dat"}))
+		assert.Equal(t, "Not in a git repository.", Tr("common.not-in-repo"))
+	})
+}
+
+func TestTrTranslated(t *testing.T) {
+	withLanguage("de", func() {
+		assert.Equal(t, `"*.dat" wird verfolgt`, Tr("track.tracking", Args{"Pattern": "*.dat"}))
+	})
+}
+
+func TestTrFallsBackToEnglish(t *testing.T) {
+	withLanguage("de", func() {
+		assert.Equal(t, "Searching for files matching pattern: *.dat",
+			Tr("track.searching", Args{"Pattern": "*.dat"}))
+	})
+	withLanguage("xx", func() {
+		assert.Equal(t, "Not in a git repository.", Tr("common.not-in-repo"))
+	})
+}
+
+func TestTrMissingArgument(t *testing.T) {
+	// A missing argument is an error in the text, rather than being
+	// printed as "<no value>"; the ID is all that can be returned.
+	assert.Equal(t, "track.tracking", Tr("track.tracking"))
+}
+
+func TestTrUnknownMessage(t *testing.T) {
+	assert.False(t, Has("no.such-message"))
+	assert.Equal(t, "no.such-message", Tr("no.such-message"))
+}
+
+func TestLocalesMatchCatalogs(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("locales", "*.json"))
+	require.Nil(t, err)
+
+	require.Len(t, locales, len(files), "run 'go generate' in package tr")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		require.Nil(t, err)
+
+		var messages map[string]string
+		require.Nil(t, json.Unmarshal(data, &messages), file)
+
+		lang := strings.TrimSuffix(filepath.Base(file), ".json")
+		assert.Equal(t, messages, locales[lang], "%s: run 'go generate' in package tr", file)
+	}
+}
+
+func TestTranslationsMatchCatalog(t *testing.T) {
+	english := locales[DefaultLanguage]
+	require.NotEmpty(t, english)
+
+	for lang, messages := range locales {
+		for id, text := range messages {
+			tmpl, err := template.New(id).Funcs(funcs).Parse(text)
+			if !assert.Nil(t, err, "%s: %s", lang, id) {
+				continue
+			}
+			if lang == DefaultLanguage {
+				continue
+			}
+
+			if !assert.Contains(t, english, id, "%s: %s is not in the catalog", lang, id) {
+				continue
+			}
+			want := template.Must(template.New(id).Funcs(funcs).Parse(english[id]))
+			for name := range argNames(tmpl.Tree.Root) {
+				assert.Contains(t, argNames(want.Tree.Root), name,
+					"%s: %s uses an argument which %s does not", lang, id, DefaultLanguage)
+			}
+		}
+	}
+}
+
+// argNames returns the names of the arguments which the template "node" refers
+// to, such as "Pattern" for "{{quote .Pattern}}".
+func argNames(node parse.Node) map[string]bool {
+	names := make(map[string]bool)
+
+	var walk func(parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.FieldNode:
+			names[n.Ident[0]] = true
+		}
+	}
+	walk(node)
+
+	return names
+}